@@ -20,13 +20,24 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/consolidation"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
 	kg "github.com/nextlevelbuilder/goclaw/internal/knowledgegraph"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/discord"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/email"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/instagram"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/line"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/matrix"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/mattermost"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/msteams"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/pancake"
+	signalchannel "github.com/nextlevelbuilder/goclaw/internal/channels/signal"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/feishu"
 	slackchannel "github.com/nextlevelbuilder/goclaw/internal/channels/slack"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/telegram"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/webchat"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/webhook"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/whatsapp"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/whatsappcloud"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/zalo"
 	zalopersonal "github.com/nextlevelbuilder/goclaw/internal/channels/zalo/personal"
 	"github.com/nextlevelbuilder/goclaw/internal/config"
@@ -119,6 +130,14 @@ func runGateway() {
 
 	// Create provider registry
 	providerRegistry := providers.NewRegistry(store.TenantIDFromContext)
+	if os.Getenv(providers.CassetteRecordEnv) == "true" {
+		if root := os.Getenv(providers.CassetteDirEnv); root != "" {
+			providerRegistry.Use(providers.RecordingMiddleware(root))
+			slog.Warn("provider cassette recording enabled — prompts and responses are written to disk", "dir", root)
+		} else {
+			slog.Warn("provider cassette recording requested but " + providers.CassetteDirEnv + " is not set")
+		}
+	}
 	registerProviders(providerRegistry, cfg, modelReg)
 
 	// Resolve workspace (must be absolute for system prompt + file tool path resolution)
@@ -181,12 +200,28 @@ func runGateway() {
 			slog.Info("system_configs applied to in-memory config", "keys", len(sysConfigs))
 		}
 	}
-	setupMemoryEmbeddings(pgStores, providerRegistry)
+	embProvider, embeddingMigrator := setupMemoryEmbeddings(pgStores, providerRegistry, msgBus)
+	if embeddingMigrator != nil {
+		defer embeddingMigrator.Stop()
+	}
 
 	// Resolve background provider for consolidation + vault enrichment.
 	// Fallback: background.provider → agent.default_provider → first registered provider.
 	bgProvider, bgModel := resolveBackgroundProvider(cfg, providerRegistry)
 
+	// Batch-API execution for background workers (opt-in per tenant via
+	// system config background.batch). Started after workers register handlers.
+	var batchDispatcher *llmbatch.Dispatcher
+	if pgStores.LLMBatches != nil && providerRegistry != nil {
+		batchDispatcher = llmbatch.New(llmbatch.Config{
+			Store:         pgStores.LLMBatches,
+			Registry:      providerRegistry,
+			SystemConfigs: pgStores.SystemConfigs,
+			Tracing:       traceCollector,
+			ModelPricing:  cfg.Telemetry.ModelPricing,
+		})
+	}
+
 	// V3: Wire consolidation pipeline (episodic → semantic → KG → dreaming)
 	if pgStores.Episodic != nil {
 		if bgProvider != nil {
@@ -205,6 +240,7 @@ func runGateway() {
 				Extractor:     kgExtractor,
 				AlertDeps:     bgalert.AlertDeps{SystemConfigs: pgStores.SystemConfigs, MsgBus: msgBus},
 				AgentStore:    pgStores.Agents,
+				Batch:         batchDispatcher,
 			})
 			defer cleanupConsolidation()
 			slog.Info("consolidation pipeline registered", "provider", bgProvider.Name(), "model", bgModel)
@@ -226,12 +262,17 @@ func runGateway() {
 			MsgBus:        msgBus,
 			TeamStore:     pgStores.Teams,
 			AlertDeps:     bgalert.AlertDeps{SystemConfigs: pgStores.SystemConfigs, MsgBus: msgBus},
+			Batch:         batchDispatcher,
 		})
 		enrichProgress = ep
 		enrichWorker = ew
 		defer cleanupVaultEnrich()
 		slog.Info("vault enrichment worker registered (per-tenant provider resolution)")
 	}
+	if batchDispatcher != nil {
+		batchDispatcher.Start()
+		defer batchDispatcher.Stop()
+	}
 
 	loadBootstrapFiles(pgStores, workspace, agentCfg)
 
@@ -256,7 +297,7 @@ func runGateway() {
 		slog.Info("subagent system enabled", "tools", []string{"spawn"})
 	}
 
-	skillsLoader, skillSearchTool, globalSkillsDir, bundledSkillsDir, builtinSkillsDir := setupSkillsSystem(cfg, workspace, dataDir, pgStores, toolsReg, providerRegistry, msgBus)
+	skillsLoader, skillSearchTool, globalSkillsDir, bundledSkillsDir, builtinSkillsDir := setupSkillsSystem(cfg, workspace, dataDir, pgStores, toolsReg, providerRegistry, msgBus, embProvider)
 	_ = skillSearchTool // used via wireExtras → skillsLoader; kept for type clarity
 
 	// Register cron/heartbeat/session/message tools, aliases, allow-paths, store wiring.
@@ -291,7 +332,7 @@ func runGateway() {
 	var mcpPool *mcpbridge.Pool
 	var mediaStore *media.Store
 	var postTurn tools.PostTurnProcessor
-	contextFileInterceptor, mcpPool, mediaStore, postTurn = wireExtras(pgStores, agentRouter, providerRegistry, modelReg, msgBus, pgStores.Sessions, toolsReg, toolPE, skillsLoader, hasMemory, traceCollector, workspace, cfg.Gateway.InjectionAction, cfg, sandboxMgr, redisClient, domainBus)
+	contextFileInterceptor, mcpPool, mediaStore, postTurn = wireExtras(pgStores, agentRouter, providerRegistry, modelReg, msgBus, pgStores.Sessions, toolsReg, toolPE, execApprovalMgr, skillsLoader, hasMemory, traceCollector, workspace, cfg.Gateway.InjectionAction, cfg, sandboxMgr, redisClient, domainBus)
 	if mcpPool != nil {
 		defer mcpPool.Stop()
 	}
@@ -323,6 +364,9 @@ func runGateway() {
 	exportTokenStore := httpapi.InitExportTokenStore()
 	defer exportTokenStore.Stop()
 	agentsH, skillsH, tracesH, mcpH, channelInstancesH, providersH, builtinToolsH, pendingMessagesH, teamEventsH, secureCLIH, secureCLIGrantH, mcpUserCredsH := wireHTTP(pgStores, cfg.Agents.Defaults.Workspace, dataDir, bundledSkillsDir, msgBus, toolsReg, providerRegistry, permPE.IsOwner, gatewayAddr, mcpToolLister)
+	if providersH != nil && embeddingMigrator != nil {
+		providersH.SetEmbeddingMigrator(embeddingMigrator)
+	}
 
 	// Wire dependencies for system prompt preview parity.
 	if agentsH != nil {
@@ -417,6 +461,20 @@ func runGateway() {
 	channelMgr := channels.NewManager(msgBus)
 	deps.channelMgr = channelMgr
 
+	// Durable outbound outbox: retries with per-channel backoff, dead-letter queue.
+	channelMgr.SetOutbox(pgStores.Outbox, cfg.Channels.Outbox)
+
+	// Shared chat commands (/reset, /tasks, tenant custom commands, ...) for every channel.
+	channelMgr.SetCommandRegistry(channels.NewCommandRegistry(channels.CommandDeps{
+		Agents:        pgStores.Agents,
+		ConfigPerms:   pgStores.ConfigPermissions,
+		Teams:         pgStores.Teams,
+		SubagentTasks: pgStores.SubagentTasks,
+		SystemConfigs: pgStores.SystemConfigs,
+		Approvals:     wireChatApprovals(execApprovalMgr, channelMgr, msgBus),
+		OwnerIDs:      cfg.Gateway.OwnerIDs,
+	}))
+
 	// Wire channel sender + tenant checker on message tool (now that channelMgr exists)
 	if t, ok := toolsReg.Get("message"); ok {
 		if cs, ok := t.(tools.ChannelSenderAware); ok {
@@ -439,7 +497,10 @@ func runGateway() {
 		instanceLoader = channels.NewInstanceLoader(pgStores.ChannelInstances, pgStores.Agents, channelMgr, msgBus, pgStores.Pairing)
 		instanceLoader.SetProviderRegistry(providerRegistry)
 		instanceLoader.SetPendingCompactionConfig(cfg.Channels.PendingCompaction)
-		instanceLoader.RegisterFactory(channels.TypeTelegram, telegram.FactoryWithStoresAndAudio(pgStores.Agents, pgStores.ConfigPermissions, pgStores.Teams, pgStores.SubagentTasks, pgStores.PendingMessages, audioMgr))
+		if pgStores.Contacts != nil {
+			instanceLoader.SetContactStore(pgStores.Contacts)
+		}
+		instanceLoader.RegisterFactory(channels.TypeTelegram, telegram.FactoryWithPendingStoreAndAudio(pgStores.PendingMessages, audioMgr))
 		instanceLoader.RegisterFactory(channels.TypeDiscord, discord.FactoryWithStoresAndAudio(pgStores.Agents, pgStores.ConfigPermissions, pgStores.PendingMessages, audioMgr))
 		instanceLoader.RegisterFactory(channels.TypeFeishu, feishu.FactoryWithPendingStoreAndAudio(pgStores.PendingMessages, audioMgr))
 		instanceLoader.RegisterFactory(channels.TypeZaloOA, zalo.Factory)
@@ -448,6 +509,16 @@ func runGateway() {
 		instanceLoader.RegisterFactory(channels.TypeSlack, slackchannel.FactoryWithPendingStore(pgStores.PendingMessages))
 		instanceLoader.RegisterFactory(channels.TypeFacebook, facebook.Factory)
 		instanceLoader.RegisterFactory(channels.TypePancake, pancake.Factory)
+		instanceLoader.RegisterFactory(channels.TypeEmail, email.Factory)
+		instanceLoader.RegisterFactory(channels.TypeMatrix, matrix.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeMattermost, mattermost.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeMSTeams, msteams.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeLine, line.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeSignal, signalchannel.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeWebhook, webhook.FactoryWithPendingStore(pgStores.PendingMessages))
+		instanceLoader.RegisterFactory(channels.TypeWebChat, webchat.Factory)
+		instanceLoader.RegisterFactory(channels.TypeWhatsAppCloud, whatsappcloud.Factory)
+		instanceLoader.RegisterFactory(channels.TypeInstagram, instagram.Factory)
 		if err := instanceLoader.LoadAll(context.Background()); err != nil {
 			slog.Error("failed to load channel instances from DB", "error", err)
 		}
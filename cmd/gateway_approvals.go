@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/hooks"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tools"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// hookApprovalTimeout bounds how long a hook "ask" decision waits in chat.
+const hookApprovalTimeout = 2 * time.Minute
+
+// chatApprovals connects the exec approval manager to chat channels:
+// pending approvals are delivered to their originating chat, and /approve,
+// /deny or button clicks resolve them with an audit entry.
+type chatApprovals struct {
+	mgr        *tools.ExecApprovalManager
+	channelMgr *channels.Manager
+	msgBus     *bus.MessageBus
+}
+
+// wireChatApprovals installs the notifier and returns the command resolver.
+func wireChatApprovals(mgr *tools.ExecApprovalManager, channelMgr *channels.Manager, msgBus *bus.MessageBus) *chatApprovals {
+	a := &chatApprovals{mgr: mgr, channelMgr: channelMgr, msgBus: msgBus}
+	mgr.SetNotifier(a.notify)
+	return a
+}
+
+// notify delivers a new pending approval to the chat it came from.
+func (a *chatApprovals) notify(pa tools.PendingApproval) {
+	ctx := context.Background()
+	if tid, ok := a.channelMgr.ChannelTenantID(pa.Origin.Channel); ok {
+		ctx = store.WithTenantID(ctx, tid)
+	}
+	if err := a.channelMgr.SendApprovalRequest(ctx, toApprovalRequest(pa)); err != nil {
+		slog.Warn("approval request delivery failed",
+			"id", pa.ID, "channel", pa.Origin.Channel, "chat_id", pa.Origin.ChatID, "error", err)
+	}
+}
+
+// PendingApproval implements channels.ApprovalResolver. Requests raised
+// outside a chat (WS clients) are not resolvable from channels.
+func (a *chatApprovals) PendingApproval(code string) (channels.ApprovalRequest, bool) {
+	pa, ok := a.mgr.PendingByCode(code)
+	if !ok || pa.Origin == nil {
+		return channels.ApprovalRequest{}, false
+	}
+	return toApprovalRequest(pa), true
+}
+
+// ResolveApproval implements channels.ApprovalResolver.
+func (a *chatApprovals) ResolveApproval(ctx context.Context, req channels.ApprovalRequest, decision channels.ApprovalDecision, actor string) error {
+	if err := a.mgr.Resolve(req.ID, tools.ApprovalDecision(decision)); err != nil {
+		return err
+	}
+	slog.Info("approval resolved from chat", "id", req.ID, "decision", decision, "actor", actor)
+
+	entityType, action := approvalAuditAction(req.Kind, decision)
+	details, _ := json.Marshal(map[string]string{
+		"channel":  req.Channel,
+		"chat_id":  req.ChatID,
+		"code":     req.Code,
+		"kind":     req.Kind,
+		"decision": string(decision),
+	})
+	a.msgBus.Broadcast(bus.Event{
+		Name: protocol.EventAuditLog,
+		Payload: bus.AuditEventPayload{
+			ActorType:  "user",
+			ActorID:    actor,
+			Action:     action,
+			EntityType: entityType,
+			EntityID:   req.ID,
+			Details:    details,
+			TenantID:   store.TenantIDFromContext(ctx),
+		},
+	})
+	return nil
+}
+
+// approvalAuditAction returns the audit entity type and action for a
+// resolved approval, e.g. ("hook", "hook.denied").
+func approvalAuditAction(kind string, decision channels.ApprovalDecision) (entityType, action string) {
+	entityType = kind
+	if entityType == "" {
+		entityType = tools.ApprovalKindExec
+	}
+	if decision == channels.ApprovalDeny {
+		return entityType, entityType + ".denied"
+	}
+	return entityType, entityType + ".approved"
+}
+
+func toApprovalRequest(pa tools.PendingApproval) channels.ApprovalRequest {
+	return channels.ApprovalRequest{
+		ID:       pa.ID,
+		Code:     pa.Code,
+		Kind:     pa.Kind,
+		Command:  pa.Command,
+		AgentID:  pa.AgentID,
+		Channel:  pa.Origin.Channel,
+		ChatID:   pa.Origin.ChatID,
+		PeerKind: pa.Origin.PeerKind,
+		Metadata: buildAnnounceOutMeta(pa.Origin.LocalKey),
+	}
+}
+
+// hookAskApprover resolves hook "ask" decisions through the exec approval
+// manager, so they reach the originating chat like exec approvals. Runs
+// without a chat (cron, WS) have nobody to ask and are blocked.
+func hookAskApprover(mgr *tools.ExecApprovalManager) hooks.AskApprover {
+	return func(ctx context.Context, cfg hooks.HookConfig, ev hooks.Event, reason string) (bool, error) {
+		if ev.Channel == "" || ev.ChatID == "" {
+			return false, fmt.Errorf("no chat to ask for approval")
+		}
+		origin := &tools.ApprovalOrigin{
+			Channel:  ev.Channel,
+			ChatID:   ev.ChatID,
+			PeerKind: ev.PeerKind,
+			LocalKey: tools.ToolLocalKeyFromCtx(ctx),
+		}
+		decision, err := mgr.RequestApprovalFrom(origin, tools.ApprovalKindHook, hookAskPreview(cfg, ev, reason), ev.AgentID.String(), hookApprovalTimeout)
+		return err == nil && decision != tools.ApprovalDeny, err
+	}
+}
+
+// hookAskPreview describes what the hook wants approved.
+func hookAskPreview(cfg hooks.HookConfig, ev hooks.Event, reason string) string {
+	name := cfg.Name
+	if name == "" {
+		name = string(cfg.Event) + " hook"
+	}
+	preview := name
+	if ev.ToolName != "" {
+		input, _ := json.Marshal(ev.ToolInput)
+		preview += fmt.Sprintf("\nTool: %s %s", ev.ToolName, channels.Truncate(string(input), 300))
+	} else if ev.RawInput != "" {
+		preview += "\nMessage: " + channels.Truncate(ev.RawInput, 300)
+	}
+	if reason != "" {
+		preview += "\nReason: " + reason
+	}
+	return preview
+}
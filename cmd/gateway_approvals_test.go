@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+func TestApprovalAuditAction(t *testing.T) {
+	cases := []struct {
+		kind       string
+		decision   channels.ApprovalDecision
+		entityType string
+		action     string
+	}{
+		{"exec", channels.ApprovalAllowAlways, "exec", "exec.approved"},
+		{"exec", channels.ApprovalDeny, "exec", "exec.denied"},
+		{"hook", channels.ApprovalAllowOnce, "hook", "hook.approved"},
+		{"hook", channels.ApprovalDeny, "hook", "hook.denied"},
+		{"", channels.ApprovalAllowOnce, "exec", "exec.approved"},
+	}
+	for _, tc := range cases {
+		entityType, action := approvalAuditAction(tc.kind, tc.decision)
+		if entityType != tc.entityType || action != tc.action {
+			t.Errorf("approvalAuditAction(%q, %q) = (%q, %q); want (%q, %q)",
+				tc.kind, tc.decision, entityType, action, tc.entityType, tc.action)
+		}
+	}
+}
@@ -144,7 +144,7 @@ func registerConfigChannels(cfg *config.Config, channelMgr *channels.Manager, ms
 	}
 }
 
-// wireChannelRPCMethods registers WS RPC methods for channels, instances, agent links, and teams.
+// wireChannelRPCMethods registers WS RPC methods for channels, instances, dead letters, agent links, and teams.
 func wireChannelRPCMethods(server *gateway.Server, pgStores *store.Stores, channelMgr *channels.Manager, agentRouter *agent.Router, msgBus *bus.MessageBus, dataDir string) {
 	// Register channels RPC methods (after channelMgr is initialized with all channels)
 	methods.NewChannelsMethods(channelMgr).Register(server.Router())
@@ -157,6 +157,11 @@ func wireChannelRPCMethods(server *gateway.Server, pgStores *store.Stores, chann
 		whatsapp.NewQRMethods(pgStores.ChannelInstances, channelMgr).Register(server.Router())
 	}
 
+	// Register outbound dead-letter queue WS RPC methods
+	if pgStores.Outbox != nil {
+		methods.NewOutboxMethods(pgStores.Outbox, msgBus).Register(server.Router())
+	}
+
 	// Register agent links WS RPC methods
 	if pgStores.AgentLinks != nil && pgStores.Agents != nil {
 		methods.NewAgentLinksMethods(pgStores.AgentLinks, pgStores.Agents, agentRouter, msgBus, msgBus).Register(server.Router())
@@ -397,6 +397,13 @@ func handleResetCommand(
 	if agentID == "" {
 		agentID = resolveAgentRoute(deps.Cfg, msg.Channel, msg.ChatID, msg.PeerKind)
 	}
+	ctx := store.WithTenantID(context.Background(), msg.TenantID)
+	// Conversations routed by channel rules live under the routed agent's session.
+	if deps.ChannelMgr != nil {
+		if routed := deps.ChannelMgr.RoutedAgent(ctx, msg); routed != "" {
+			agentID = routed
+		}
+	}
 	peerKind := msg.PeerKind
 	if peerKind == "" {
 		peerKind = string(sessions.PeerDirect)
@@ -409,10 +416,12 @@ func handleResetCommand(
 			sessionKey = sessions.BuildGroupTopicSessionKey(agentID, msg.Channel, msg.ChatID, topicID)
 		}
 	}
-	ctx := store.WithTenantID(context.Background(), msg.TenantID)
 	deps.SessStore.Reset(ctx, sessionKey)
 	deps.SessStore.Save(ctx, sessionKey)
 	providers.ResetCLISession("", sessionKey)
+	if deps.ChannelMgr != nil {
+		deps.ChannelMgr.ForgetRoute(ctx, msg) // next message is routed afresh
+	}
 	slog.Info("inbound: /reset command", "session", sessionKey)
 
 	return true
@@ -433,6 +442,13 @@ func handleStopCommand(
 	if agentID == "" {
 		agentID = resolveAgentRoute(deps.Cfg, msg.Channel, msg.ChatID, msg.PeerKind)
 	}
+	// Conversations routed by channel rules live under the routed agent's session.
+	if deps.ChannelMgr != nil {
+		ctx := store.WithTenantID(context.Background(), msg.TenantID)
+		if routed := deps.ChannelMgr.RoutedAgent(ctx, msg); routed != "" {
+			agentID = routed
+		}
+	}
 	peerKind := msg.PeerKind
 	if peerKind == "" {
 		peerKind = string(sessions.PeerDirect)
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -41,6 +42,20 @@ func processNormalMessage(
 		agentID = resolveAgentRoute(deps.Cfg, msg.Channel, msg.ChatID, msg.PeerKind)
 	}
 
+	// Rule-based routing on the channel instance (config.routing) may hand the
+	// conversation to another agent; the decision is recorded on the trace.
+	var traceTags []string
+	var traceMeta json.RawMessage
+	if deps.ChannelMgr != nil {
+		if d := deps.ChannelMgr.RouteInbound(ctx, msg); d != nil {
+			slog.Info("inbound: routed", "channel", msg.Channel, "chat_id", msg.ChatID,
+				"agent", d.Agent, "reason", d.Reason, "rule", d.Rule, "previous", d.Previous)
+			agentID = d.Agent
+			traceTags = []string{"routed:" + d.Reason}
+			traceMeta, _ = json.Marshal(map[string]any{"routing": d})
+		}
+	}
+
 	agentLoop, err := deps.Agents.Get(ctx, agentID)
 	if err != nil {
 		slog.Warn("inbound: agent not found", "agent", agentID, "channel", msg.Channel)
@@ -391,6 +406,8 @@ func processNormalMessage(
 		ToolAllow:         msg.ToolAllow,
 		ExtraSystemPrompt: extraPrompt,
 		SkillFilter:       skillFilter,
+		TraceTags:         traceTags,
+		TraceMetadata:     traceMeta,
 	}, scheduler.ScheduleOpts{
 		MaxConcurrent: maxConcurrent,
 	})
@@ -89,7 +89,17 @@ func isExternalChannel(channelType string) bool {
 		channels.TypeZaloOA,
 		channels.TypeZaloPersonal,
 		channels.TypePancake,
-		channels.TypeSlack:
+		channels.TypeSlack,
+		channels.TypeEmail,
+		channels.TypeMatrix,
+		channels.TypeMattermost,
+		channels.TypeMSTeams,
+		channels.TypeLine,
+		channels.TypeSignal,
+		channels.TypeWebhook,
+		channels.TypeWebChat,
+		channels.TypeWhatsAppCloud,
+		channels.TypeInstagram:
 		return true
 	}
 	return false
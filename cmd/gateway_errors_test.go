@@ -28,13 +28,17 @@ func TestIsExternalChannel(t *testing.T) {
 		{"zalo_personal", channels.TypeZaloPersonal, true},
 		{"pancake", channels.TypePancake, true},
 		{"slack", channels.TypeSlack, true},
+		{"line", channels.TypeLine, true},
+		{"signal", channels.TypeSignal, true},
+		{"webhook", channels.TypeWebhook, true},
+		{"webchat", channels.TypeWebChat, true},
+		{"whatsapp_cloud", channels.TypeWhatsAppCloud, true},
+		{"instagram", channels.TypeInstagram, true},
 
 		// Internal / unknown channel types — errors must still surface.
 		{"ws", "ws", false},
 		{"empty", "", false},
 		{"unknown", "myplatform", false},
-		// "line" is a Pancake sub-platform, not a top-level channel type.
-		{"line_not_top_level", "line", false},
 		// Legacy short "zalo" string must NOT match — real constants are zalo_oa / zalo_personal.
 		{"zalo_short_form", "zalo", false},
 	}
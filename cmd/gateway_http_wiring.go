@@ -100,6 +100,11 @@ func (d *gatewayDeps) wireHTTPHandlersOnServer(
 		d.server.SetActivityHandler(httpapi.NewActivityHandler(d.pgStores.Activity))
 	}
 
+	// Outbound dead-letter queue API
+	if d.pgStores.Outbox != nil {
+		d.server.SetOutboxHandler(httpapi.NewOutboxHandler(d.pgStores.Outbox, d.msgBus))
+	}
+
 	// System configs API
 	if d.pgStores.SystemConfigs != nil {
 		d.server.SetSystemConfigsHandler(httpapi.NewSystemConfigsHandler(d.pgStores.SystemConfigs, d.msgBus))
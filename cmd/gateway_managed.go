@@ -25,6 +25,7 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/media"
 	memorypkg "github.com/nextlevelbuilder/goclaw/internal/memory"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/rerank"
 	"github.com/nextlevelbuilder/goclaw/internal/sandbox"
 	"github.com/nextlevelbuilder/goclaw/internal/skills"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
@@ -49,6 +50,7 @@ func wireExtras(
 	sessStore store.SessionStore,
 	toolsReg *tools.Registry,
 	toolPE *tools.PolicyEngine,
+	execApprovalMgr *tools.ExecApprovalManager,
 	skillsLoader *skills.Loader,
 	hasMemory bool,
 	traceCollector *tracing.Collector,
@@ -139,10 +141,16 @@ func wireExtras(
 		skillAccessStore = sas
 	}
 
+	// Cross-encoder reranking for memory/vault search (per-tenant system_configs "rerank").
+	var reranker *rerank.Service
+	if stores.SystemConfigs != nil {
+		reranker = rerank.NewService(stores.SystemConfigs, stores.Providers)
+	}
+
 	// V3 auto-inject: create AutoInjector if episodic store is available.
 	var autoInjector memorypkg.AutoInjector
 	if stores.Episodic != nil {
-		autoInjector = memorypkg.NewAutoInjector(stores.Episodic, stores.EvolutionMetrics)
+		autoInjector = memorypkg.NewAutoInjector(stores.Episodic, stores.EvolutionMetrics, reranker)
 	}
 
 	// vaultIntc is set later by wireVault but captured by closure in OnTextUploaded.
@@ -182,6 +190,7 @@ func wireExtras(
 			Store:    hs,
 			Audit:    hooks.NewAuditWriter(hs, ""),
 			Handlers: handlers,
+			Approver: hookAskApprover(execApprovalMgr),
 		}
 		hookDispatcher = hooks.NewStdDispatcher(stdOpts)
 		hooks.SubscribeDelegateEvents(domainBus, hookDispatcher)
@@ -356,6 +365,9 @@ func wireExtras(
 			if ms, ok := searchTool.(tools.MemoryStoreAware); ok {
 				ms.SetMemoryStore(stores.Memory)
 			}
+			if mst, ok := searchTool.(*tools.MemorySearchTool); ok {
+				mst.SetReranker(reranker)
+			}
 		}
 		if getTool, ok := toolsReg.Get("memory_get"); ok {
 			if ms, ok := getTool.(tools.MemoryStoreAware); ok {
@@ -400,7 +412,7 @@ func wireExtras(
 	}
 
 	// Wire vault tools and interceptors (conditional on vault store availability)
-	vaultIntc = wireVault(stores, toolsReg, workspace, domainBus)
+	vaultIntc = wireVault(stores, toolsReg, workspace, domainBus, reranker)
 
 	// Wire delegate tool for inter-agent delegation via agent_links.
 	if stores.AgentLinks != nil && stores.Agents != nil {
@@ -324,6 +324,40 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 			continue
 		}
 
+		// Bedrock may authenticate via the AWS default credential chain (IAM role,
+		// IRSA, env) — an empty api_key is valid, so handle before the key guard.
+		if p.ProviderType == store.ProviderBedrock {
+			var region string
+			if bs := store.ParseBedrockSettings(p.Settings); bs != nil {
+				region = bs.Region
+			}
+			prov, err := providers.NewBedrockProviderFromKey(context.Background(), p.Name, region, p.APIBase, p.APIKey)
+			if err != nil {
+				slog.Warn("bedrock: failed to resolve credentials, skipping", "name", p.Name, "error", err)
+				continue
+			}
+			registry.RegisterForTenant(p.TenantID, prov)
+			slog.Info("registered provider from DB", "name", p.Name)
+			continue
+		}
+
+		// Replay serves recorded cassettes — no API key; api_base is the
+		// cassette directory under GOCLAW_CASSETTE_DIR.
+		if p.ProviderType == store.ProviderReplay {
+			var mode string
+			if rs := store.ParseReplaySettings(p.Settings); rs != nil {
+				mode = rs.Mode
+			}
+			prov, err := providers.NewReplayProviderFromEnv(p.Name, p.APIBase, mode)
+			if err != nil {
+				slog.Warn("replay: failed to load cassette, skipping", "name", p.Name, "error", err)
+				continue
+			}
+			registry.RegisterForTenant(p.TenantID, prov)
+			slog.Info("registered provider from DB", "name", p.Name)
+			continue
+		}
+
 		if p.APIKey == "" {
 			continue
 		}
@@ -347,6 +381,27 @@ func registerProvidersFromDB(registry *providers.Registry, provStore store.Provi
 				providers.WithAnthropicName(p.Name),
 				providers.WithAnthropicBaseURL(p.APIBase),
 				providers.WithAnthropicRegistry(modelReg)))
+		case store.ProviderAzureOpenAI:
+			var opts []providers.AzureOpenAIOption
+			if as := store.ParseAzureOpenAISettings(p.Settings); as != nil {
+				opts = append(opts, providers.WithAzureAPIVersion(as.APIVersion), providers.WithAzureDeployments(as.Deployments))
+				if as.AuthMode == store.AzureAuthEntra {
+					// api_key holds the service principal's client secret in Entra mode.
+					opts = append(opts, providers.WithAzureTokenSource(providers.NewAzureEntraTokenSource(as.TenantID, as.ClientID, p.APIKey)))
+				}
+			}
+			registry.RegisterForTenant(p.TenantID, providers.NewAzureOpenAIProvider(p.Name, p.APIBase, p.APIKey, "", opts...))
+		case store.ProviderVertexAI:
+			var project, location string
+			if vs := store.ParseVertexSettings(p.Settings); vs != nil {
+				project, location = vs.ProjectID, vs.Location
+			}
+			prov, err := providers.NewVertexProviderFromKey(p.Name, project, location, p.APIBase, p.APIKey)
+			if err != nil {
+				slog.Warn("vertex_ai: invalid service account key, skipping", "name", p.Name, "error", err)
+				continue
+			}
+			registry.RegisterForTenant(p.TenantID, prov)
 		case store.ProviderDashScope:
 			registry.RegisterForTenant(p.TenantID, providers.NewDashScopeProvider(p.Name, p.APIKey, p.APIBase, ""))
 		case store.ProviderBailian:
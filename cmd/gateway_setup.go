@@ -14,6 +14,7 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/bootstrap"
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
 	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/embedding"
 	mcpbridge "github.com/nextlevelbuilder/goclaw/internal/mcp"
 	"github.com/nextlevelbuilder/goclaw/internal/permissions"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
@@ -357,12 +358,44 @@ func wireTracingAndCron(
 
 // setupMemoryEmbeddings wires embedding provider to PGMemoryStore and triggers backfill.
 // Resolves embedding provider from DB providers with settings.embedding.enabled.
+// Returns the provider shared by all vector stores (dual-reading while a model
+// migration is pending) and the migrator, both nil when embeddings are off.
 func setupMemoryEmbeddings(
 	pgStores *store.Stores,
 	providerRegistry *providers.Registry,
-) {
+	msgBus *bus.MessageBus,
+) (store.EmbeddingProvider, *embedding.Migrator) {
+	var migrator *embedding.Migrator
+	var shared store.EmbeddingProvider
 	if pgStores.Memory != nil {
-		if embProvider := resolveEmbeddingProvider(pgStores.Providers, providerRegistry, pgStores.SystemConfigs); embProvider != nil {
+		if resolved := resolveEmbeddingProvider(pgStores.Providers, providerRegistry, pgStores.SystemConfigs); resolved != nil {
+			var embProvider store.EmbeddingProvider = resolved
+
+			// Compare with the model stored vectors were built with; on a change,
+			// search dual-reads both models until re-embedding completes.
+			if pgStores.EmbeddingIndex != nil && pgStores.SystemConfigs != nil {
+				migrator = embedding.New(embedding.Config{
+					Index:         pgStores.EmbeddingIndex,
+					SystemConfigs: pgStores.SystemConfigs,
+					Publisher:     msgBus,
+				})
+				rec := store.EmbeddingModelRecord{Provider: resolved.Name(), Model: resolved.Model(), Dims: store.RequiredMemoryEmbeddingDimensions}
+				masterCtx := store.WithTenantID(context.Background(), store.MasterTenantID)
+				previous := func(prev store.EmbeddingModelRecord) store.EmbeddingProvider {
+					if p := resolveEmbeddingFromDB(masterCtx, pgStores.Providers, prev.Provider,
+						&config.MemoryConfig{EmbeddingModel: prev.Model}, providerRegistry); p != nil {
+						return p
+					}
+					return nil
+				}
+				p, err := migrator.Init(context.Background(), resolved, rec, previous)
+				if err != nil {
+					slog.Warn("embedding model check failed", "error", err)
+				}
+				embProvider = p
+			}
+			shared = embProvider
+
 			pgStores.Memory.SetEmbeddingProvider(embProvider)
 			slog.Info("memory embeddings enabled", "provider", embProvider.Name(), "model", embProvider.Model())
 
@@ -421,6 +454,7 @@ func setupMemoryEmbeddings(
 			slog.Warn("memory embeddings disabled (no API key), chunks stored without vectors")
 		}
 	}
+	return shared, migrator
 }
 
 // seedSystemConfigs ensures system_configs has all expected keys for all tenants.
@@ -499,6 +533,7 @@ func setupSkillsSystem(
 	toolsReg *tools.Registry,
 	providerRegistry *providers.Registry,
 	msgBus *bus.MessageBus,
+	embProvider store.EmbeddingProvider, // shared vector-store provider; resolved here when nil
 ) (*skills.Loader, *tools.SkillSearchTool, string, string, string) {
 	var bundledSkillsDir string // resolved later; returned for HTTP handler fallback
 
@@ -580,7 +615,12 @@ func setupSkillsSystem(
 			skillSearchTool.SetSkillAccessStore(sas)
 		}
 		if pgSkills, ok := pgStores.Skills.(*pg.PGSkillStore); ok {
-			if embProvider := resolveEmbeddingProvider(pgStores.Providers, providerRegistry, pgStores.SystemConfigs); embProvider != nil {
+			if embProvider == nil {
+				if resolved := resolveEmbeddingProvider(pgStores.Providers, providerRegistry, pgStores.SystemConfigs); resolved != nil {
+					embProvider = resolved
+				}
+			}
+			if embProvider != nil {
 				pgSkills.SetEmbeddingProvider(embProvider)
 				skillSearchTool.SetEmbeddingSearcher(pgSkills, embProvider)
 				slog.Info("skill embeddings enabled", "provider", embProvider.Name())
@@ -110,6 +110,7 @@ func seedConfigForContext(ctx context.Context, sc store.SystemConfigStore, cfg *
 	// Background workers
 	set("background.provider", cfg.Gateway.BackgroundProvider)
 	set("background.model", cfg.Gateway.BackgroundModel)
+	setBool("background.batch", cfg.Gateway.BackgroundBatch)
 
 	// Tools
 	set("tools.profile", cfg.Tools.Profile)
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/rerank"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 	"github.com/nextlevelbuilder/goclaw/internal/tools"
 	"github.com/nextlevelbuilder/goclaw/internal/vault"
@@ -15,7 +16,7 @@ import (
 // wireVault wires Knowledge Vault tools and interceptors into the tool registry.
 // Returns the shared VaultInterceptor for use by other subsystems (e.g. agent upload hook).
 // Returns nil if stores.Vault is nil.
-func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string, bus eventbus.DomainEventBus) *tools.VaultInterceptor {
+func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string, bus eventbus.DomainEventBus, reranker *rerank.Service) *tools.VaultInterceptor {
 	if stores.Vault == nil {
 		return nil
 	}
@@ -27,6 +28,7 @@ func wireVault(stores *store.Stores, toolsReg *tools.Registry, workspace string,
 	// Build VaultSearchService: fan-out across vault + KG (episodic store pending impl).
 	// EpisodicStore is nil until a PG implementation exists.
 	searchSvc := vault.NewVaultSearchService(stores.Vault, nil, stores.KnowledgeGraph)
+	searchSvc.SetReranker(reranker)
 	vaultSearchTool.SetSearchService(searchSvc)
 
 	// Build shared VaultInterceptor for read/write tool vault registration.
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -32,6 +33,12 @@ Classify the user's intent into exactly ONE of these categories:
 
 Respond with ONLY the category name, nothing else.`
 
+// intentSchema constrains the classifier output to one of the intent categories.
+var intentSchema = map[string]any{
+	"type": "string",
+	"enum": []any{string(IntentStatusQuery), string(IntentCancel), string(IntentSteer), string(IntentNewTask)},
+}
+
 // cancelKeywords for fast-path detection of obvious cancel intents.
 // Only matched on very short messages (≤ 15 runes) to avoid false positives
 // like "làm đơn giản thôi" matching "thôi".
@@ -79,6 +86,25 @@ func quickClassify(msg string) (IntentType, bool) {
 	return "", false
 }
 
+// smallTalkPhrases are greetings and acknowledgements that never need a
+// flagship model. Matched whole-message only, like exactCancelKeywords.
+var smallTalkPhrases = map[string]bool{
+	"hi": true, "hello": true, "hey": true, "yo": true, "thanks": true, "thank you": true,
+	"thx": true, "ok": true, "okay": true, "cool": true, "nice": true, "great": true,
+	"good morning": true, "good night": true, "bye": true, "lol": true, "👍": true,
+	"chào": true, "xin chào": true, "cảm ơn": true, "cám ơn": true, "ok nhé": true,
+	"你好": true, "谢谢": true,
+}
+
+// IsSmallTalk reports whether a message is a bare greeting or acknowledgement
+// (case-insensitive, trailing punctuation ignored). Used by the model router
+// to keep chit-chat on the cheapest tier without an LLM classification call.
+func IsSmallTalk(msg string) bool {
+	lower := strings.ToLower(strings.TrimSpace(msg))
+	lower = strings.TrimRight(lower, "!.?~ ")
+	return smallTalkPhrases[lower]
+}
+
 // containsWholeWord checks if s contains kw as a whole word (not a substring
 // of a larger word). Word boundaries are: start/end of string, spaces, punctuation.
 func containsWholeWord(s, kw string) bool {
@@ -116,18 +142,21 @@ func ClassifyIntent(ctx context.Context, provider providers.Provider, model, use
 	ctx, cancel := context.WithTimeout(ctx, intentClassifyTimeout)
 	defer cancel()
 
-	resp, err := provider.Chat(ctx, providers.ChatRequest{
+	// Structured output pins the answer to the enum; if validation still fails,
+	// the substring match below handles a bare or decorated category name.
+	resp, err := providers.ChatStructured(ctx, provider, providers.ChatRequest{
 		Messages: []providers.Message{
 			{Role: "system", Content: intentSystemPrompt},
 			{Role: "user", Content: userMessage},
 		},
 		Model: model,
 		Options: map[string]any{
-			providers.OptMaxTokens:   20,
+			providers.OptMaxTokens:   64,
 			providers.OptTemperature: 0.0,
 		},
+		ResponseFormat: providers.JSONSchemaFormat("intent", intentSchema),
 	})
-	if err != nil {
+	if err != nil && (resp == nil || !errors.Is(err, providers.ErrStructuredOutput)) {
 		return IntentNewTask
 	}
 
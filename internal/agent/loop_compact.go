@@ -87,7 +87,7 @@ func (l *Loop) compactMessagesInPlace(ctx context.Context, messages []providers.
 	sctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	resp, err := l.provider.Chat(sctx, providers.ChatRequest{
+	resp, err := l.redactedProvider(l.provider, nil).Chat(sctx, providers.ChatRequest{
 		Messages: []providers.Message{{
 			Role:    "user",
 			Content: compactionSummaryPrompt + sb.String(),
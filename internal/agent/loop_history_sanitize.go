@@ -282,7 +282,7 @@ func (l *Loop) maybeSummarize(ctx context.Context, sessionKey string) {
 		}
 		prompt.WriteString(sb.String())
 
-		resp, err := l.provider.Chat(sctx, providers.ChatRequest{
+		resp, err := l.redactedProvider(l.provider, nil).Chat(sctx, providers.ChatRequest{
 			Messages: []providers.Message{{Role: "user", Content: prompt.String()}},
 			Model:    l.model,
 			Options:  map[string]any{"max_tokens": 1024, "temperature": 0.3},
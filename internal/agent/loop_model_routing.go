@@ -0,0 +1,289 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/pipeline"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/tracing"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// RoutingMetadataKey is the span metadata key holding the model router
+// decision. The snapshot worker reads baseline_cost from it to report
+// routing savings in /v1/usage/breakdown.
+const RoutingMetadataKey = "model_routing"
+
+// routeDecision is recorded on every LLM span of a routed run.
+type routeDecision struct {
+	Tier             string   `json:"tier"`
+	Model            string   `json:"model"`
+	Score            int      `json:"score"`
+	Reasons          []string `json:"reasons,omitempty"`
+	Attempt          int      `json:"attempt"`
+	EscalatedFrom    string   `json:"escalated_from,omitempty"`
+	EscalationReason string   `json:"escalation_reason,omitempty"`
+	BaselineProvider string   `json:"baseline_provider"`
+	BaselineModel    string   `json:"baseline_model"`
+	// BaselineCost is what the call would have cost on the agent's own model.
+	// Omitted on the top tier (baseline == actual); 0 once discarded.
+	BaselineCost *float64 `json:"baseline_cost,omitempty"`
+	Discarded    bool     `json:"discarded,omitempty"`
+}
+
+// routedSpan remembers an LLM span so a discarded attempt can be re-tagged.
+type routedSpan struct {
+	spanID   uuid.UUID
+	traceID  uuid.UUID
+	metadata json.RawMessage
+}
+
+// routeAttempt is one run of a turn on one tier. Tentative attempts (a
+// stronger tier exists and escalation is on) hold user-visible output and
+// session writes until the confidence check passes, so an escalated turn
+// leaves no trace of the cheap answer in the chat or the session history.
+// Tool side effects of a discarded attempt are not undone.
+type routeAttempt struct {
+	decision  routeDecision
+	tentative bool
+
+	mu       sync.Mutex
+	events   []AgentEvent
+	deferred []func(ctx context.Context)
+	spans    []routedSpan
+}
+
+type routeAttemptKey struct{}
+
+func withRouteAttempt(ctx context.Context, a *routeAttempt) context.Context {
+	return context.WithValue(ctx, routeAttemptKey{}, a)
+}
+
+func routeAttemptFromContext(ctx context.Context) *routeAttempt {
+	a, _ := ctx.Value(routeAttemptKey{}).(*routeAttempt)
+	return a
+}
+
+// holdEvent buffers streamed output of a tentative attempt. Returns true
+// when the event was held and must not be emitted now.
+func (a *routeAttempt) holdEvent(event AgentEvent) bool {
+	if a == nil || !a.tentative {
+		return false
+	}
+	switch event.Type {
+	case protocol.ChatEventChunk, protocol.ChatEventThinking, protocol.AgentEventBlockReply:
+		a.mu.Lock()
+		a.events = append(a.events, event)
+		a.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+// deferPersistence wraps the session-writing pipeline callbacks so a
+// tentative attempt only persists once it is accepted.
+func (a *routeAttempt) deferPersistence(deps *pipeline.PipelineDeps) {
+	if !a.tentative {
+		return
+	}
+	later := func(fn func(ctx context.Context)) {
+		a.mu.Lock()
+		a.deferred = append(a.deferred, fn)
+		a.mu.Unlock()
+	}
+	if flush := deps.FlushMessages; flush != nil {
+		deps.FlushMessages = func(_ context.Context, sessionKey string, msgs []providers.Message) error {
+			msgs = append([]providers.Message(nil), msgs...)
+			later(func(ctx context.Context) { _ = flush(ctx, sessionKey, msgs) })
+			return nil
+		}
+	}
+	if update := deps.UpdateMetadata; update != nil {
+		deps.UpdateMetadata = func(_ context.Context, sessionKey string, usage providers.Usage) error {
+			later(func(ctx context.Context) { _ = update(ctx, sessionKey, usage) })
+			return nil
+		}
+	}
+	if cleanup := deps.BootstrapCleanup; cleanup != nil {
+		deps.BootstrapCleanup = func(_ context.Context, state *pipeline.RunState) error {
+			later(func(ctx context.Context) { _ = cleanup(ctx, state) })
+			return nil
+		}
+	}
+	if summarize := deps.MaybeSummarize; summarize != nil {
+		deps.MaybeSummarize = func(_ context.Context, sessionKey string) {
+			later(func(ctx context.Context) { summarize(ctx, sessionKey) })
+		}
+	}
+	if completed := deps.EmitSessionCompleted; completed != nil {
+		deps.EmitSessionCompleted = func(_ context.Context, sessionKey string, msgCount, tokensUsed, compactionCount int) {
+			later(func(ctx context.Context) { completed(ctx, sessionKey, msgCount, tokensUsed, compactionCount) })
+		}
+	}
+}
+
+// commit releases held output and session writes of an accepted attempt.
+func (a *routeAttempt) commit(ctx context.Context, emit func(AgentEvent)) {
+	a.mu.Lock()
+	events, deferred := a.events, a.deferred
+	a.events, a.deferred = nil, nil
+	a.mu.Unlock()
+
+	for _, e := range events {
+		emit(e)
+	}
+	detached := context.WithoutCancel(ctx)
+	for _, fn := range deferred {
+		fn(detached)
+	}
+}
+
+// discard drops held output and re-tags the attempt's spans so the usage
+// snapshot counts their cost as routing overhead rather than savings.
+func (a *routeAttempt) discard(ctx context.Context) {
+	a.mu.Lock()
+	spans := a.spans
+	a.events, a.deferred, a.spans = nil, nil, nil
+	a.mu.Unlock()
+
+	collector := tracing.CollectorFromContext(ctx)
+	if collector == nil {
+		return
+	}
+	zero := 0.0
+	for _, s := range spans {
+		payload := map[string]any{}
+		_ = json.Unmarshal(s.metadata, &payload)
+		d := a.decision
+		d.BaselineCost = &zero
+		d.Discarded = true
+		payload[RoutingMetadataKey] = d
+		if data, err := json.Marshal(payload); err == nil {
+			collector.EmitSpanUpdate(s.spanID, s.traceID, map[string]any{"metadata": json.RawMessage(data)})
+		}
+	}
+}
+
+// spanMetadata merges the routing decision into an LLM span's metadata and
+// remembers the span for discard.
+func (a *routeAttempt) spanMetadata(existing json.RawMessage, spanID, traceID uuid.UUID, usage *providers.Usage, pricing map[string]*config.ModelPricing) json.RawMessage {
+	d := a.decision
+	if a.decision.Tier != "primary" && usage != nil {
+		if p := tracing.LookupPricing(pricing, d.BaselineProvider, d.BaselineModel); p != nil {
+			cost := tracing.CalculateCost(p, usage)
+			d.BaselineCost = &cost
+		}
+	}
+	payload := map[string]any{}
+	if len(existing) > 0 {
+		_ = json.Unmarshal(existing, &payload)
+	}
+	payload[RoutingMetadataKey] = d
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return existing
+	}
+	a.mu.Lock()
+	a.spans = append(a.spans, routedSpan{spanID: spanID, traceID: traceID, metadata: data})
+	a.mu.Unlock()
+	return data
+}
+
+// runRouted runs a turn through the model router: the cheapest tier that
+// fits the turn first, then each stronger tier while the answer fails the
+// confidence check.
+func (l *Loop) runRouted(ctx context.Context, req RunRequest) (*RunResult, error) {
+	r := l.modelRouter
+	_, prior := l.routeFailures.Load(req.SessionKey)
+	score, reasons := scoreTurn(routeSignals{
+		Message:      req.Message,
+		MediaCount:   len(req.Media),
+		PriorFailure: prior,
+	})
+
+	primary := r.tiers[r.top()]
+	var overhead providers.Usage
+	var prev *routeAttempt
+	for idx := r.pick(score); ; idx++ {
+		tier := r.tiers[idx]
+		attempt := &routeAttempt{
+			tentative: r.escalate && idx < r.top(),
+			decision: routeDecision{
+				Tier:             tier.name,
+				Model:            tier.model,
+				Score:            score,
+				Reasons:          reasons,
+				Attempt:          1,
+				BaselineProvider: primary.provider.Name(),
+				BaselineModel:    primary.model,
+			},
+		}
+		if prev != nil {
+			attempt.decision.Attempt = prev.decision.Attempt + 1
+			attempt.decision.EscalatedFrom = prev.decision.Tier
+			attempt.decision.EscalationReason = prev.decision.EscalationReason
+		}
+
+		areq := req
+		areq.routeAttempt = attempt
+		if idx < r.top() {
+			areq.ModelOverride = tier.model
+			areq.ProviderOverride = tier.provider
+			if tier.maxToolIterations > 0 && (areq.MaxIterations == 0 || tier.maxToolIterations < areq.MaxIterations) {
+				areq.MaxIterations = tier.maxToolIterations
+			}
+		}
+
+		result, err := l.runPipelineOnce(withRouteAttempt(ctx, attempt), areq)
+
+		reason := ""
+		if attempt.tentative && ctx.Err() == nil {
+			reason = routeAttemptFailure(result, err, l.effectiveMaxIterations(&areq))
+		}
+		if reason == "" {
+			attempt.commit(ctx, l.emit)
+			if err != nil || prev != nil {
+				l.routeFailures.Store(req.SessionKey, true)
+			} else {
+				l.routeFailures.Delete(req.SessionKey)
+			}
+			if result != nil && result.Usage != nil {
+				addUsage(result.Usage, &overhead)
+			}
+			return result, err
+		}
+
+		attempt.decision.EscalationReason = reason
+		attempt.discard(ctx)
+		if result != nil && result.Usage != nil {
+			addUsage(&overhead, result.Usage)
+		}
+		slog.Info("model router: escalating",
+			"agent", l.id, "session", req.SessionKey,
+			"from", tier.name, "to", r.tiers[idx+1].name, "reason", reason)
+		prev = attempt
+	}
+}
+
+// effectiveMaxIterations mirrors the iteration cap applied in buildPipelineDeps.
+func (l *Loop) effectiveMaxIterations(req *RunRequest) int {
+	if req.MaxIterations > 0 && req.MaxIterations < l.maxIterations {
+		return req.MaxIterations
+	}
+	return l.maxIterations
+}
+
+func addUsage(dst, src *providers.Usage) {
+	dst.PromptTokens += src.PromptTokens
+	dst.CompletionTokens += src.CompletionTokens
+	dst.TotalTokens += src.TotalTokens
+	dst.CacheCreationTokens += src.CacheCreationTokens
+	dst.CacheReadTokens += src.CacheReadTokens
+	dst.ThinkingTokens += src.ThinkingTokens
+}
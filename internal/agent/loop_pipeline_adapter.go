@@ -13,12 +13,25 @@ import (
 	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
 )
 
-// runViaPipeline delegates a run to the v3 pipeline.
+// runViaPipeline delegates a run to the v3 pipeline. Agents with a model
+// routing policy go through the cascading router unless the caller already
+// pinned a model/provider for this request.
 func (l *Loop) runViaPipeline(ctx context.Context, req RunRequest) (*RunResult, error) {
+	if l.modelRouter != nil && req.ModelOverride == "" && req.ProviderOverride == nil {
+		return l.runRouted(ctx, req)
+	}
+	return l.runPipelineOnce(ctx, req)
+}
+
+// runPipelineOnce runs the v3 pipeline for a single attempt.
+func (l *Loop) runPipelineOnce(ctx context.Context, req RunRequest) (*RunResult, error) {
 	input := convertRunInput(&req)
 	// Bridge runState shares loop detection state between pipeline and agent.
 	bridgeRS := &runState{}
 	deps := l.buildPipelineDeps(&req, bridgeRS)
+	if req.routeAttempt != nil {
+		req.routeAttempt.deferPersistence(&deps)
+	}
 
 	model := l.model
 	if req.ModelOverride != "" {
@@ -75,7 +88,7 @@ func (l *Loop) buildPipelineDeps(req *RunRequest, bridgeRS *runState) pipeline.P
 			return spec.ContextWindow
 		},
 		EmitEvent: func(event any) {
-			if ae, ok := event.(AgentEvent); ok {
+			if ae, ok := event.(AgentEvent); ok && !req.routeAttempt.holdEvent(ae) {
 				l.emit(ae)
 			}
 		},
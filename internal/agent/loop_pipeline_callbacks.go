@@ -33,6 +33,9 @@ func (l *Loop) pipelineCallbacks(req *RunRequest, bridgeRS *runState) pipelineCa
 		event.ChatID = req.ChatID
 		event.SessionKey = req.SessionKey
 		event.TenantID = l.tenantID
+		if req.routeAttempt.holdEvent(event) {
+			return
+		}
 		l.emit(event)
 	}
 	return pipelineCallbackSet{
@@ -218,6 +221,12 @@ func (l *Loop) makeBuildFilteredTools(req *RunRequest) func(state *pipeline.RunS
 }
 
 func (l *Loop) makeCallLLM(req *RunRequest, emitRun func(AgentEvent)) func(ctx context.Context, state *pipeline.RunState, chatReq providers.ChatRequest) (*providers.ChatResponse, error) {
+	// One PII placeholder mapping per run, so a value keeps its placeholder
+	// across iterations and tool results.
+	var piiVault *providers.RedactionVault
+	if l.piiRedactor != nil {
+		piiVault = providers.NewRedactionVault()
+	}
 	return func(ctx context.Context, state *pipeline.RunState, chatReq providers.ChatRequest) (*providers.ChatResponse, error) {
 		provider := state.Provider
 		model := state.Model
@@ -253,6 +262,18 @@ func (l *Loop) makeCallLLM(req *RunRequest, emitRun func(AgentEvent)) func(ctx c
 			chatReq.Options[providers.OptStripThinking] = true
 		}
 
+		// Structured output requested by the caller (/v1/chat/completions, /v1/responses).
+		// Without native support the schema goes into the prompt and the
+		// final answer is validated below.
+		promptedFormat := false
+		if req.ResponseFormat != nil {
+			chatReq.ResponseFormat = req.ResponseFormat
+			if !providers.SupportsStructuredOutput(provider) {
+				chatReq = providers.StructuredOutputPrompt(chatReq)
+				promptedFormat = true
+			}
+		}
+
 		// Emit LLM span start for tracing.
 		start := time.Now().UTC()
 		var opts []spanOption
@@ -264,10 +285,18 @@ func (l *Loop) makeCallLLM(req *RunRequest, emitRun func(AgentEvent)) func(ctx c
 		}
 		spanID := l.emitLLMSpanStart(ctx, start, state.Iteration+1, chatReq.Messages, opts...)
 
+		// Redaction wraps the call only: reasoning and structured-output
+		// checks above need the concrete provider.
+		callProvider := provider
+		if piiVault != nil {
+			callProvider = l.redactedProvider(provider, piiVault)
+			ctx = providers.WithRedactionObservation(ctx)
+		}
+
 		var resp *providers.ChatResponse
 		var err error
 		if req.Stream {
-			resp, err = provider.ChatStream(ctx, chatReq, func(chunk providers.StreamChunk) {
+			resp, err = callProvider.ChatStream(ctx, chatReq, func(chunk providers.StreamChunk) {
 				if chunk.Thinking != "" {
 					emitRun(AgentEvent{
 						Type:    protocol.ChatEventThinking,
@@ -286,7 +315,10 @@ func (l *Loop) makeCallLLM(req *RunRequest, emitRun func(AgentEvent)) func(ctx c
 				}
 			})
 		} else {
-			resp, err = provider.Chat(ctx, chatReq)
+			resp, err = callProvider.Chat(ctx, chatReq)
+		}
+		if promptedFormat && err == nil {
+			resp, err = providers.ValidateStructuredReply(ctx, callProvider, chatReq, req.ResponseFormat, resp)
 		}
 
 		// Non-streaming: emit content events matching v2 behavior (channels need these).
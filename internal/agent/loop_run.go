@@ -79,6 +79,7 @@ func (l *Loop) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
 			StartTime:    now,
 			CreatedAt:    now,
 			Tags:         req.TraceTags,
+			Metadata:     req.TraceMetadata,
 		}
 		if l.agentUUID != uuid.Nil {
 			trace.AgentID = &l.agentUUID
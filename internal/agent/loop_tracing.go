@@ -203,6 +203,16 @@ func (l *Loop) emitLLMSpanEnd(ctx context.Context, spanID uuid.UUID, start time.
 	if decision := providers.ReasoningDecisionFromContext(ctx); decision != nil {
 		spanMetadata = providers.MergeReasoningMetadata(spanMetadata, *decision)
 	}
+	if observation := providers.RedactionObservationFromContext(ctx); observation != nil {
+		spanMetadata = providers.MergeRedactionMetadata(spanMetadata, observation.Counts())
+	}
+	if attempt := routeAttemptFromContext(ctx); attempt != nil {
+		var usage *providers.Usage
+		if callErr == nil && resp != nil {
+			usage = resp.Usage
+		}
+		spanMetadata = attempt.spanMetadata(spanMetadata, spanID, traceID, usage, l.modelPricing)
+	}
 	if len(spanMetadata) > 0 {
 		updates["metadata"] = spanMetadata
 	}
@@ -256,6 +256,16 @@ type Loop struct {
 	// per session on restart, then steady-state resumes).
 	cacheTouchBySession sync.Map
 
+	// Cascading model router (nil = always use provider/model above).
+	// routeFailures marks sessions whose last turn escalated or failed
+	// (sessionKey → true); the next turn starts one tier higher.
+	modelRouter   *ModelRouter
+	routeFailures sync.Map
+
+	// piiRedactor tokenizes PII before prompts leave the gateway (nil = off).
+	// See redactedProvider.
+	piiRedactor *providers.PIIRedactor
+
 	// hookDispatcher fires lifecycle hook events (Issue #875). Nil-safe: when
 	// nil the pipeline fast-path skips all hook overhead. Populated from
 	// LoopConfig.HookDispatcher during startup wiring.
@@ -439,6 +449,12 @@ type LoopConfig struct {
 
 	// User identity resolver for credential lookups (maps channel contacts → tenant users)
 	UserResolver UserIdentityResolver
+
+	// Cascading cheap-first model router from agent other_config (nil = disabled)
+	ModelRouter *ModelRouter
+
+	// Outbound PII redaction from tenant + agent policy (nil = disabled)
+	PIIRedactor *providers.PIIRedactor
 }
 
 const defaultMaxTokens = config.DefaultMaxTokens
@@ -557,6 +573,8 @@ func NewLoop(cfg LoopConfig) *Loop {
 		delegateTargets:        cfg.DelegateTargets,
 		evolutionMetricsStore:  cfg.EvolutionMetricsStore,
 		userResolver:           cfg.UserResolver,
+		modelRouter:            cfg.ModelRouter,
+		piiRedactor:            cfg.PIIRedactor,
 	}
 }
 
@@ -587,11 +605,16 @@ type RunRequest struct {
 	LinkedTraceID     uuid.UUID          // if set, create new trace with parent_trace_id pointing to this (team task runs)
 	TraceName         string             // override trace name (default: "chat <agentID>")
 	TraceTags         []string           // additional tags for the trace (e.g. "cron")
+	TraceMetadata     json.RawMessage    // initial trace metadata (e.g. channel routing decision)
 	MaxIterations     int                // per-request override (0 = use agent default, must be lower)
 	ModelOverride     string             // per-request model override (heartbeat uses cheaper model)
 	ProviderOverride  providers.Provider // per-request provider override (heartbeat uses different provider)
 	LightContext      bool               // skip loading context files (only inject ExtraSystemPrompt)
 
+	// ResponseFormat constrains the final answer to JSON (HTTP response_format).
+	// Providers without native support get the schema as a system-prompt instruction.
+	ResponseFormat *providers.ResponseFormat
+
 	// Run classification
 	RunKind       string // "delegation", "announce" — empty for user-initiated runs
 	HideInput     bool   // don't persist input message in session history (announce runs)
@@ -620,6 +643,9 @@ type RunRequest struct {
 	// TeamWorkspace overrides the member agent's workspace with the team's workspace
 	// so file operations (read/write/image/audio) use the shared team directory.
 	TeamWorkspace string
+
+	// routeAttempt is set by the model router for each tier attempt (nil = not routed).
+	routeAttempt *routeAttempt
 }
 
 // RunResult is the output of a completed agent run.
@@ -161,9 +161,17 @@ func (l *Loop) InvalidateUserWorkspace(userID string) {
 	l.userSetups.Delete(userID)
 }
 
-// Provider returns the LLM provider for this agent loop.
+// Provider returns the LLM provider for this agent loop, wrapped with the
+// agent's PII redaction policy when one is set.
 // Used by intent classifier to make lightweight LLM calls with the agent's own provider.
-func (l *Loop) Provider() providers.Provider { return l.provider }
+func (l *Loop) Provider() providers.Provider { return l.redactedProvider(l.provider, nil) }
+
+// redactedProvider wraps p with the agent's outbound PII redaction. vault
+// carries a run's placeholder mapping; nil gives the call its own mapping.
+// Returns p unchanged when redaction is off.
+func (l *Loop) redactedProvider(p providers.Provider, vault *providers.RedactionVault) providers.Provider {
+	return providers.NewRedactingProvider(p, l.piiRedactor, vault)
+}
 
 // ProviderName returns the name of this agent's LLM provider (e.g. "anthropic", "openai").
 func (l *Loop) ProviderName() string {
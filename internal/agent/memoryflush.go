@@ -183,10 +183,12 @@ func (l *Loop) runMemoryFlush(ctx context.Context, sessionKey string, settings *
 		toolDefs = l.tools.ProviderDefs()
 	}
 
-	// Run LLM iteration loop (max 5 iterations for flush)
+	// Run LLM iteration loop (max 5 iterations for flush). One redaction
+	// mapping across iterations keeps placeholders stable in tool loops.
+	flushProvider := l.redactedProvider(l.provider, nil)
 	maxFlushIter := 5
 	for range maxFlushIter {
-		resp, err := l.provider.Chat(flushCtx, providers.ChatRequest{
+		resp, err := flushProvider.Chat(flushCtx, providers.ChatRequest{
 			Messages: messages,
 			Tools:    toolDefs,
 			Model:    l.model,
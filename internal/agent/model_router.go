@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Turn scoring weights. A turn's score is the sum of the signals it trips;
+// the router picks the cheapest tier whose max_score is >= the score.
+const (
+	routeScoreMediumMessage = 1 // > routeMediumMessageRunes
+	routeScoreLongMessage   = 2 // > routeLongMessageRunes
+	routeScoreAttachments   = 2
+	routeScoreToolNeed      = 2
+	routeScorePriorFailure  = 3
+
+	routeMediumMessageRunes = 160
+	routeLongMessageRunes   = 600
+)
+
+// Escalation reasons recorded on trace spans.
+const (
+	routeEscalateError         = "error"
+	routeEscalateLoopDetected  = "loop_detected"
+	routeEscalateMaxIterations = "max_tool_iterations"
+	routeEscalateEmptyAnswer   = "empty_answer"
+	routeEscalateLowConfidence = "low_confidence"
+)
+
+// toolHintKeywords suggest the turn needs tools (files, web, exec, scheduling).
+// Cheap models are noticeably worse at multi-step tool use, so these push the
+// turn up a tier rather than relying on escalation after the fact.
+var toolHintKeywords = []string{
+	"```", "http://", "https://", "search", "look up", "lookup", "browse",
+	"file", "folder", "download", "upload", "run ", "execute", "install",
+	"deploy", "schedule", "remind", "cron", "database", "sql", "script",
+	"debug", "refactor", "analyze", "analyse", "compare", "summarize", "summarise",
+	"tìm", "chạy", "tệp", "nhắc",
+}
+
+// lowConfidenceMarkers are hedges that indicate a cheap model gave up.
+var lowConfidenceMarkers = []string{
+	"i'm not sure", "i am not sure", "i don't know", "i do not know",
+	"i'm unable to", "i am unable to", "i can't help with", "i cannot help with",
+	"i don't have enough information", "i do not have enough information",
+}
+
+// routeTier is one resolved step of the cascade.
+type routeTier struct {
+	name              string
+	provider          providers.Provider
+	model             string
+	maxScore          int
+	maxToolIterations int
+}
+
+// ModelRouter routes each turn to the cheapest tier that fits it and
+// escalates to stronger tiers when the cheap answer fails the confidence
+// check. The last tier is always the agent's own provider/model.
+type ModelRouter struct {
+	tiers    []routeTier
+	escalate bool
+}
+
+// NewModelRouter resolves a routing policy against the provider registry.
+// lookup returns nil for unknown providers; tiers that cannot be resolved are
+// dropped. Returns nil when no cheaper tier remains.
+func NewModelRouter(cfg *store.ModelRoutingConfig, primary providers.Provider, primaryModel string, lookup func(name string) providers.Provider) *ModelRouter {
+	if cfg == nil || primary == nil {
+		return nil
+	}
+	r := &ModelRouter{escalate: cfg.EscalationEnabled()}
+	for i, t := range cfg.Tiers {
+		p := primary
+		if t.Provider != "" && t.Provider != primary.Name() {
+			if p = lookup(t.Provider); p == nil {
+				continue
+			}
+		}
+		if p.Name() == primary.Name() && t.Model == primaryModel {
+			continue // same as the top tier
+		}
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("tier%d", i+1)
+		}
+		r.tiers = append(r.tiers, routeTier{
+			name:              name,
+			provider:          p,
+			model:             t.Model,
+			maxScore:          t.MaxScore,
+			maxToolIterations: t.MaxToolIterations,
+		})
+	}
+	if len(r.tiers) == 0 {
+		return nil
+	}
+	r.tiers = append(r.tiers, routeTier{name: "primary", provider: primary, model: primaryModel, maxScore: math.MaxInt})
+	return r
+}
+
+// pick returns the index of the cheapest tier accepting score.
+func (r *ModelRouter) pick(score int) int {
+	for i, t := range r.tiers {
+		if score <= t.maxScore {
+			return i
+		}
+	}
+	return len(r.tiers) - 1
+}
+
+// top returns the index of the agent's own model.
+func (r *ModelRouter) top() int { return len(r.tiers) - 1 }
+
+// routeSignals are the per-turn inputs to scoreTurn.
+type routeSignals struct {
+	Message      string
+	MediaCount   int
+	PriorFailure bool // the previous turn in this session escalated or failed
+}
+
+// scoreTurn rates turn complexity. Returns the score and the signals that
+// contributed, for the trace.
+func scoreTurn(s routeSignals) (int, []string) {
+	// Greetings, acks, status pings and cancels are always cheapest.
+	if s.MediaCount == 0 && !s.PriorFailure {
+		if IsSmallTalk(s.Message) {
+			return 0, []string{"small_talk"}
+		}
+		if intent, ok := quickClassify(s.Message); ok {
+			return 0, []string{"intent:" + string(intent)}
+		}
+	}
+
+	score := 0
+	var reasons []string
+	switch n := utf8.RuneCountInString(s.Message); {
+	case n > routeLongMessageRunes:
+		score += routeScoreLongMessage
+		reasons = append(reasons, "long_message")
+	case n > routeMediumMessageRunes:
+		score += routeScoreMediumMessage
+		reasons = append(reasons, "medium_message")
+	}
+	if s.MediaCount > 0 {
+		score += routeScoreAttachments
+		reasons = append(reasons, "attachments")
+	}
+	lower := strings.ToLower(s.Message)
+	for _, kw := range toolHintKeywords {
+		if strings.Contains(lower, kw) {
+			score += routeScoreToolNeed
+			reasons = append(reasons, "tool_need")
+			break
+		}
+	}
+	if s.PriorFailure {
+		score += routeScorePriorFailure
+		reasons = append(reasons, "prior_failure")
+	}
+	return score, reasons
+}
+
+// routeAttemptFailure is the confidence check for a cheap-tier answer.
+// Returns the escalation reason, or "" when the answer is acceptable.
+// An empty result content is a deliberate NO_REPLY and is accepted.
+func routeAttemptFailure(result *RunResult, err error, maxIter int) string {
+	if err != nil {
+		return routeEscalateError
+	}
+	if result == nil {
+		return routeEscalateEmptyAnswer
+	}
+	if result.LoopKilled {
+		return routeEscalateLoopDetected
+	}
+	if maxIter > 0 && result.Iterations >= maxIter {
+		return routeEscalateMaxIterations
+	}
+	content := strings.TrimSpace(result.Content)
+	if content == "..." {
+		return routeEscalateEmptyAnswer
+	}
+	lower := strings.ToLower(content)
+	for _, m := range lowConfidenceMarkers {
+		if strings.Contains(lower, m) {
+			return routeEscalateLowConfidence
+		}
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/pipeline"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+type namedStubProvider struct {
+	stubProvider
+	name string
+}
+
+func (p *namedStubProvider) Name() string { return p.name }
+
+func testRouter(t *testing.T) *ModelRouter {
+	t.Helper()
+	primary := &namedStubProvider{name: "anthropic"}
+	cheap := &namedStubProvider{name: "openrouter"}
+	cfg := &store.ModelRoutingConfig{
+		Enabled: true,
+		Tiers: []store.ModelRoutingTier{
+			{Name: "nano", Provider: "openrouter", Model: "gpt-4o-mini", MaxScore: 0},
+			{Name: "mid", Model: "claude-haiku", MaxScore: 2, MaxToolIterations: 5},
+			{Name: "gone", Provider: "missing", Model: "x", MaxScore: 3},
+		},
+	}
+	r := NewModelRouter(cfg, primary, "claude-opus", func(name string) providers.Provider {
+		if name == "openrouter" {
+			return cheap
+		}
+		return nil
+	})
+	if r == nil {
+		t.Fatal("NewModelRouter returned nil")
+	}
+	return r
+}
+
+func TestNewModelRouter_Tiers(t *testing.T) {
+	r := testRouter(t)
+	var names []string
+	for _, tier := range r.tiers {
+		names = append(names, tier.name)
+	}
+	if got := strings.Join(names, ","); got != "nano,mid,primary" {
+		t.Fatalf("tiers = %s, want nano,mid,primary (unresolvable provider dropped)", got)
+	}
+	if r.tiers[0].provider.Name() != "openrouter" || r.tiers[1].provider.Name() != "anthropic" {
+		t.Errorf("tier providers = %s, %s", r.tiers[0].provider.Name(), r.tiers[1].provider.Name())
+	}
+	if !r.escalate {
+		t.Error("escalation should default to enabled")
+	}
+
+	onlyPrimary := &store.ModelRoutingConfig{Enabled: true, Tiers: []store.ModelRoutingTier{{Model: "claude-opus"}}}
+	if NewModelRouter(onlyPrimary, &namedStubProvider{name: "anthropic"}, "claude-opus", nil) != nil {
+		t.Error("router with only the primary model should be nil")
+	}
+}
+
+func TestModelRouter_Pick(t *testing.T) {
+	r := testRouter(t)
+	cases := map[int]string{0: "nano", 1: "mid", 2: "mid", 3: "primary", 9: "primary"}
+	for score, want := range cases {
+		if got := r.tiers[r.pick(score)].name; got != want {
+			t.Errorf("pick(%d) = %s, want %s", score, got, want)
+		}
+	}
+}
+
+func TestScoreTurn(t *testing.T) {
+	cases := []struct {
+		name    string
+		signals routeSignals
+		want    int
+	}{
+		{"small talk", routeSignals{Message: "thanks!"}, 0},
+		{"cancel intent", routeSignals{Message: "stop"}, 0},
+		{"short question", routeSignals{Message: "what's the capital of France?"}, 0},
+		{"medium", routeSignals{Message: strings.Repeat("word ", 40)}, routeScoreMediumMessage},
+		{"long", routeSignals{Message: strings.Repeat("word ", 200)}, routeScoreLongMessage},
+		{"attachment", routeSignals{Message: "hi", MediaCount: 1}, routeScoreAttachments},
+		{"tool need", routeSignals{Message: "search the web for goclaw"}, routeScoreToolNeed},
+		{"prior failure", routeSignals{Message: "hello", PriorFailure: true}, routeScorePriorFailure},
+	}
+	for _, c := range cases {
+		if got, reasons := scoreTurn(c.signals); got != c.want {
+			t.Errorf("%s: score = %d (%v), want %d", c.name, got, reasons, c.want)
+		}
+	}
+}
+
+func TestRouteAttemptFailure(t *testing.T) {
+	cases := []struct {
+		name   string
+		result *RunResult
+		want   string
+	}{
+		{"ok", &RunResult{Content: "Paris.", Iterations: 1}, ""},
+		{"silent", &RunResult{Content: "", Iterations: 1}, ""},
+		{"max iterations", &RunResult{Content: "partial", Iterations: 5}, routeEscalateMaxIterations},
+		{"loop", &RunResult{Content: "x", LoopKilled: true}, routeEscalateLoopDetected},
+		{"empty", &RunResult{Content: "..."}, routeEscalateEmptyAnswer},
+		{"hedge", &RunResult{Content: "Sorry, I'm not sure about that."}, routeEscalateLowConfidence},
+	}
+	for _, c := range cases {
+		if got := routeAttemptFailure(c.result, nil, 5); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+	if got := routeAttemptFailure(nil, context.DeadlineExceeded, 5); got != routeEscalateError {
+		t.Errorf("error: got %q", got)
+	}
+}
+
+func TestRouteAttempt_HoldAndDefer(t *testing.T) {
+	var flushed []providers.Message
+	deps := pipeline.PipelineDeps{
+		FlushMessages: func(_ context.Context, _ string, msgs []providers.Message) error {
+			flushed = append(flushed, msgs...)
+			return nil
+		},
+	}
+	a := &routeAttempt{tentative: true}
+	a.deferPersistence(&deps)
+
+	_ = deps.FlushMessages(context.Background(), "s", []providers.Message{{Role: "assistant", Content: "cheap"}})
+	if len(flushed) != 0 {
+		t.Fatal("tentative attempt must not flush before commit")
+	}
+	if !a.holdEvent(AgentEvent{Type: protocol.ChatEventChunk}) {
+		t.Error("chunk should be held")
+	}
+	if a.holdEvent(AgentEvent{Type: protocol.AgentEventToolCall}) {
+		t.Error("tool events pass through")
+	}
+
+	var emitted []AgentEvent
+	a.commit(context.Background(), func(e AgentEvent) { emitted = append(emitted, e) })
+	if len(flushed) != 1 || len(emitted) != 1 {
+		t.Fatalf("after commit: flushed=%d emitted=%d", len(flushed), len(emitted))
+	}
+
+	var nilAttempt *routeAttempt
+	if nilAttempt.holdEvent(AgentEvent{Type: protocol.ChatEventChunk}) {
+		t.Error("nil attempt must not hold")
+	}
+}
@@ -458,6 +458,15 @@ func NewManagedResolver(deps ResolverDeps) ResolverFunc {
 			evoMetricsStore = deps.EvolutionMetricsStore
 		}
 
+		// Outbound PII redaction: tenant policy (system_configs) as a floor plus
+		// agent other_config. Fails closed: an unreadable or invalid policy
+		// blocks the agent rather than sending unredacted prompts. Only an
+		// absent or disabled policy turns redaction off.
+		piiRedactor, err := store.ResolvePIIRedactor(ctx, deps.SystemConfigs, ag)
+		if err != nil {
+			return nil, fmt.Errorf("agent %s: %w", agentKey, err)
+		}
+
 		restrictVal := true // always restrict agents to their workspace
 		loop := NewLoop(LoopConfig{
 			ID:                     ag.AgentKey,
@@ -536,6 +545,11 @@ func NewManagedResolver(deps ResolverDeps) ResolverFunc {
 			DelegateTargets:        delegateTargets,
 			EvolutionMetricsStore:  evoMetricsStore,
 			UserResolver:           newContactResolver(deps.ContactStore),
+			ModelRouter: NewModelRouter(ag.ParseModelRouting(), provider, ag.Model, func(name string) providers.Provider {
+				p, _ := deps.ProviderReg.GetForTenant(ag.TenantID, name)
+				return p
+			}),
+			PIIRedactor: piiRedactor,
 		})
 
 		slog.Info("resolved agent from DB", "agent", agentKey, "model", ag.Model, "provider", ag.Provider)
@@ -20,6 +20,22 @@ type MessageBus struct {
 	// Event subscribers (subscriber ID → handler)
 	subscribers map[string]EventHandler
 	subMu       sync.RWMutex
+
+	// Optional durable journal for outbound messages
+	recorder   OutboundRecorder
+	recorderMu sync.RWMutex
+}
+
+// OutboundRecorder persists outbound messages at publish time, before they
+// are queued, so a message the producer has handed off survives a crash
+// before the dispatcher picks it up.
+type OutboundRecorder interface {
+	// RecordOutbound persists msg and sets msg.OutboxID. Messages it does
+	// not persist keep a nil OutboxID.
+	RecordOutbound(msg *OutboundMessage)
+	// DiscardOutbound drops a recorded message that TryPublishOutbound
+	// could not queue, since the producer is told it was not accepted.
+	DiscardOutbound(msg OutboundMessage)
 }
 
 func New() *MessageBus {
@@ -58,19 +74,42 @@ func (mb *MessageBus) ConsumeInbound(ctx context.Context) (InboundMessage, bool)
 	}
 }
 
-// PublishOutbound queues an outbound message to a channel.
+// SetOutboundRecorder installs (or, with nil, removes) the outbound journal.
+func (mb *MessageBus) SetOutboundRecorder(r OutboundRecorder) {
+	mb.recorderMu.Lock()
+	defer mb.recorderMu.Unlock()
+	mb.recorder = r
+}
+
+func (mb *MessageBus) outboundRecorder() OutboundRecorder {
+	mb.recorderMu.RLock()
+	defer mb.recorderMu.RUnlock()
+	return mb.recorder
+}
+
+// PublishOutbound records and queues an outbound message to a channel.
 // Blocks if the outbound buffer is full.
 func (mb *MessageBus) PublishOutbound(msg OutboundMessage) {
+	if r := mb.outboundRecorder(); r != nil {
+		r.RecordOutbound(&msg)
+	}
 	mb.outbound <- msg
 }
 
-// TryPublishOutbound attempts to queue an outbound message without blocking.
-// Returns false if the outbound buffer is full (message dropped).
+// TryPublishOutbound attempts to record and queue an outbound message without
+// blocking. Returns false if the outbound buffer is full (message dropped).
 func (mb *MessageBus) TryPublishOutbound(msg OutboundMessage) bool {
+	r := mb.outboundRecorder()
+	if r != nil {
+		r.RecordOutbound(&msg)
+	}
 	select {
 	case mb.outbound <- msg:
 		return true
 	default:
+		if r != nil {
+			r.DiscardOutbound(msg)
+		}
 		return false
 	}
 }
@@ -43,6 +43,7 @@ type OutboundMessage struct {
 	TenantID        uuid.UUID         `json:"tenant_id,omitempty"`          // tenant scope for per-tenant TTS
 	AgentID         uuid.UUID         `json:"agent_id,omitempty"`           // agent scope for per-agent TTS voice override
 	AgentOtherConfig []byte           `json:"agent_other_config,omitempty"` // agent's other_config for TTS voice/model
+	OutboxID        uuid.UUID         `json:"-"`                            // outbox row written at publish time (set by OutboundRecorder)
 }
 
 // MediaAttachment represents a media file to be sent with a message.
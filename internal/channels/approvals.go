@@ -0,0 +1,174 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+// Approval requests (exec commands, hook "ask" decisions) are delivered into
+// the chat they came from. Channels with a CommandReplier render the approve
+// and deny actions as buttons; the others get a text prompt answered with
+// "APPROVE 1234" or "DENY 1234". Both resolve through /approve and /deny.
+
+// ApprovalDecision is the answer to an approval request. Values match
+// tools.ApprovalDecision.
+type ApprovalDecision string
+
+const (
+	ApprovalAllowOnce   ApprovalDecision = "allow-once"
+	ApprovalAllowAlways ApprovalDecision = "allow-always"
+	ApprovalDeny        ApprovalDecision = "deny"
+)
+
+// ApprovalKindExec marks requests for exec tool commands, which can be
+// allowed permanently. Other kinds (hook decisions) are approved once.
+const ApprovalKindExec = "exec"
+
+// ApprovalRequest is a pending approval shown in its originating chat.
+type ApprovalRequest struct {
+	ID       string // approval manager ID
+	Code     string // short code typed in text replies
+	Kind     string // "exec" or "hook"
+	Command  string // command or tool call preview
+	AgentID  string
+	Channel  string
+	ChatID   string
+	PeerKind string
+	Metadata map[string]string // routing metadata (thread/topic)
+}
+
+// maxApprovalPreview bounds the command preview shown in chat.
+const maxApprovalPreview = 500
+
+// Text returns the prompt shown above the approve/deny buttons.
+func (r ApprovalRequest) Text() string {
+	title := "⚠️ Approval needed to run a command"
+	if r.Kind != ApprovalKindExec {
+		title = "⚠️ Approval needed by a hook"
+	}
+	return fmt.Sprintf("%s (#%s):\n\n%s", title, r.Code, truncateRunes(r.Command, maxApprovalPreview))
+}
+
+// FallbackText returns the prompt for channels without buttons.
+func (r ApprovalRequest) FallbackText() string {
+	if r.Kind != ApprovalKindExec {
+		return fmt.Sprintf("%s\n\nReply APPROVE %s to continue or DENY %s.", r.Text(), r.Code, r.Code)
+	}
+	return fmt.Sprintf("%s\n\nReply APPROVE %s to run it once, APPROVE ALWAYS %s to always allow it, or DENY %s.",
+		r.Text(), r.Code, r.Code, r.Code)
+}
+
+// Actions returns the approve/deny commands to render as buttons.
+func (r ApprovalRequest) Actions() []CommandAction {
+	actions := []CommandAction{{Label: "✅ Approve", Command: "/approve " + r.Code}}
+	if r.Kind == ApprovalKindExec {
+		actions = append(actions, CommandAction{Label: "♾️ Always allow", Command: "/approve " + r.Code + " always"})
+	}
+	return append(actions, CommandAction{Label: "❌ Deny", Command: "/deny " + r.Code})
+}
+
+// ApprovalResolver looks up and resolves pending approvals. Implementations
+// record an audit entry for each resolution.
+type ApprovalResolver interface {
+	PendingApproval(code string) (ApprovalRequest, bool)
+	// ResolveApproval applies the decision; actor identifies who decided.
+	ResolveApproval(ctx context.Context, req ApprovalRequest, decision ApprovalDecision, actor string) error
+}
+
+// approvalReplyRe matches text replies to approval prompts, e.g. "APPROVE 1234",
+// "approve always 1234" or "DENY 1234".
+var approvalReplyRe = regexp.MustCompile(`(?i)^\s*(approve|deny)(\s+always)?\s+(\d{4})\s*$`)
+
+// ApprovalReplyCommand converts a text reply to an approval prompt into the
+// equivalent command ("APPROVE ALWAYS 1234" → "/approve 1234 always").
+func ApprovalReplyCommand(text string) (string, bool) {
+	m := approvalReplyRe.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+	if strings.EqualFold(m[1], "deny") {
+		return "/deny " + m[3], true
+	}
+	if m[2] != "" {
+		return "/approve " + m[3] + " always", true
+	}
+	return "/approve " + m[3], true
+}
+
+// cmdApproval handles /approve and /deny. Only the chat that raised the
+// request may resolve it, and only gateway owners or senders with a file
+// writer grant for that chat. This applies to direct messages too: the
+// user whose prompt triggered the command must not approve it themselves.
+func (r *CommandRegistry) cmdApproval(allow bool) CommandHandler {
+	return func(ctx context.Context, req *CommandRequest) CommandReply {
+		if r.deps.Approvals == nil {
+			return CommandReply{Text: "Approvals are not available."}
+		}
+		fields := strings.Fields(req.Args)
+		if len(fields) == 0 {
+			return CommandReply{Text: fmt.Sprintf("Usage: /%s <code>", req.Name)}
+		}
+		code := fields[0]
+		pending, ok := r.deps.Approvals.PendingApproval(code)
+		if !ok || pending.Channel != req.Channel || pending.ChatID != req.ChatID {
+			return CommandReply{Text: fmt.Sprintf("Approval #%s not found or already resolved.", code)}
+		}
+		if !r.isOwner(req) && !r.hasWriterGrant(ctx, req) {
+			return CommandReply{Text: "Only owners and file writers can answer approval requests."}
+		}
+
+		decision := ApprovalDeny
+		if allow {
+			decision = ApprovalAllowOnce
+			if len(fields) > 1 && strings.EqualFold(fields[1], "always") && pending.Kind == ApprovalKindExec {
+				decision = ApprovalAllowAlways
+			}
+		}
+		if err := r.deps.Approvals.ResolveApproval(ctx, pending, decision, req.Channel+":"+req.UserID); err != nil {
+			slog.Debug("approval resolve failed", "code", code, "error", err)
+			return CommandReply{Text: fmt.Sprintf("Approval #%s not found or already resolved.", code)}
+		}
+
+		preview := truncateRunes(pending.Command, 100)
+		switch decision {
+		case ApprovalAllowAlways:
+			return CommandReply{Text: fmt.Sprintf("✅ Always allowed (#%s): %s", code, preview)}
+		case ApprovalAllowOnce:
+			return CommandReply{Text: fmt.Sprintf("✅ Approved (#%s): %s", code, preview)}
+		default:
+			return CommandReply{Text: fmt.Sprintf("❌ Denied (#%s): %s", code, preview)}
+		}
+	}
+}
+
+// isOwner reports whether the sender is a gateway owner.
+func (r *CommandRegistry) isOwner(req *CommandRequest) bool {
+	return slices.Contains(r.deps.OwnerIDs, req.UserID) || slices.Contains(r.deps.OwnerIDs, req.SenderID)
+}
+
+// SendApprovalRequest delivers an approval request to the chat: as buttons
+// through the channel's CommandReplier, or as a text prompt.
+func (c *BaseChannel) SendApprovalRequest(ctx context.Context, req ApprovalRequest) error {
+	if c.commandReplier != nil {
+		return c.commandReplier(ctx, &CommandRequest{
+			ChatID:   req.ChatID,
+			PeerKind: req.PeerKind,
+			Metadata: req.Metadata,
+			Channel:  c.name,
+			TenantID: c.tenantID,
+		}, CommandReply{Text: req.Text(), Actions: req.Actions()})
+	}
+	c.bus.PublishOutbound(bus.OutboundMessage{
+		Channel:  c.name,
+		ChatID:   req.ChatID,
+		Content:  req.FallbackText(),
+		Metadata: copyRoutingMeta(req.Metadata),
+	})
+	return nil
+}
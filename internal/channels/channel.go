@@ -71,15 +71,25 @@ const (
 
 // Channel type constants used across channel packages and gateway wiring.
 const (
-	TypeDiscord      = "discord"
-	TypeFacebook     = "facebook"
-	TypeFeishu       = "feishu"
-	TypePancake      = "pancake"
-	TypeSlack        = "slack"
-	TypeTelegram     = "telegram"
-	TypeWhatsApp     = "whatsapp"
-	TypeZaloOA       = "zalo_oa"
-	TypeZaloPersonal = "zalo_personal"
+	TypeDiscord       = "discord"
+	TypeEmail         = "email"
+	TypeFacebook      = "facebook"
+	TypeFeishu        = "feishu"
+	TypeInstagram     = "instagram"
+	TypeLine          = "line"
+	TypeMatrix        = "matrix"
+	TypeMattermost    = "mattermost"
+	TypeMSTeams       = "msteams"
+	TypePancake       = "pancake"
+	TypeSignal        = "signal"
+	TypeSlack         = "slack"
+	TypeTelegram      = "telegram"
+	TypeWebChat       = "webchat"
+	TypeWebhook       = "webhook"
+	TypeWhatsApp      = "whatsapp"
+	TypeWhatsAppCloud = "whatsapp_cloud"
+	TypeZaloOA        = "zalo_oa"
+	TypeZaloPersonal  = "zalo_personal"
 )
 
 // Channel defines the interface that all channel implementations must satisfy.
@@ -179,6 +189,8 @@ type BaseChannel struct {
 	agentID          string                  // for DB instances: routes to specific agent (empty = use resolveAgentRoute)
 	tenantID         uuid.UUID               // for DB instances: tenant scope (zero = master tenant fallback)
 	contactCollector *store.ContactCollector // optional: auto-collect contacts from channel messages
+	commands         *CommandRegistry        // optional: shared chat commands (see HandleCommand)
+	commandReplier   CommandReplier          // optional: native command reply delivery
 
 	// Shared policy + pairing fields (set via setters after construction).
 	pairingService  store.PairingStore
@@ -596,6 +608,16 @@ func (c *BaseChannel) HandleMessage(senderID, chatID, content string, media []st
 		return
 	}
 
+	if c.HandleCommand(store.WithTenantID(context.Background(), c.tenantID), &CommandRequest{
+		SenderID: senderID,
+		ChatID:   chatID,
+		PeerKind: peerKind,
+		Text:     content,
+		Metadata: metadata,
+	}) {
+		return
+	}
+
 	// Derive userID from senderID: strip "|username" suffix if present (legacy Slack compound format).
 	// All channels now pass plain senderID; kept for backward compat with stored compound IDs.
 	userID := senderID
@@ -638,6 +660,29 @@ type GroupMemberProvider interface {
 	ListGroupMembers(ctx context.Context, chatID string) ([]GroupMember, error)
 }
 
+// ApprovalSender is implemented by channels that can deliver approval
+// requests (all channels embedding BaseChannel).
+type ApprovalSender interface {
+	SendApprovalRequest(ctx context.Context, req ApprovalRequest) error
+}
+
+// SendErrorClassifier is optionally implemented by channels that can tell
+// permanent send failures from transient ones and read platform retry-after
+// hints from their SDK errors. Other channels use ClassifySendError.
+type SendErrorClassifier interface {
+	ClassifySendError(err error) SendFailure
+}
+
+// PlaceholderUpdateSkipper is optionally implemented by channels that cannot
+// edit a message once sent. Placeholder updates (tool status and LLM retry
+// notices, marked with placeholder_update metadata) only make sense as edits
+// of the reply placeholder; on these channels each would arrive as a separate
+// message, or spend a single-use reply token, so the Manager does not publish
+// them.
+type PlaceholderUpdateSkipper interface {
+	SkipPlaceholderUpdates() bool
+}
+
 // PendingCompactable is optionally implemented by channels that have a PendingHistory
 // supporting LLM-based compaction. InstanceLoader uses this to wire compaction config
 // after channel creation.
@@ -0,0 +1,63 @@
+// Package channeltest provides fixtures shared by the channel adapter tests:
+// starting a channel for the duration of a test, reading what it publishes
+// to the bus, polling for asynchronous side effects, and a recording HTTP
+// stub for platform APIs.
+//
+// Adapter tests keep only what is specific to their platform (API stubs,
+// webhook signing, payload builders) and use these for the rest.
+package channeltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// Timeout bounds every wait in this package. Adapters dispatch inbound
+// messages asynchronously, so waits are generous to stay stable under -race.
+const Timeout = 3 * time.Second
+
+// Start starts ch and stops it when the test ends.
+func Start(t testing.TB, ch channels.Channel) {
+	t.Helper()
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ch.Stop(context.Background()) })
+}
+
+// Consume returns the next inbound message published to mb, failing the test
+// if none arrives within Timeout.
+func Consume(t testing.TB, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	return msg
+}
+
+// TryConsume returns the next inbound message published to mb within wait.
+// Use it to assert that something was not delivered (dedup, gating, pauses).
+func TryConsume(mb *bus.MessageBus, wait time.Duration) (bus.InboundMessage, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+	return mb.ConsumeInbound(ctx)
+}
+
+// WaitFor polls cond until it holds, failing the test after Timeout.
+func WaitFor(t testing.TB, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(Timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,66 @@
+package channeltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Request is one request recorded by a Server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the request body as a JSON object (nil if it is not one).
+func (r Request) JSON() map[string]any {
+	var body map[string]any
+	json.Unmarshal(r.Body, &body)
+	return body
+}
+
+// Server is a platform API stub that records every request before handing
+// it to the test's handler. It is closed when the test ends.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewServer starts a recording stub; handler answers the requests and can
+// still read their bodies.
+func NewServer(t testing.TB, handler http.Handler) *Server {
+	t.Helper()
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+		s.mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns the recorded requests matching method and path, in
+// arrival order. An empty method or path matches any.
+func (s *Server) Requests(method, path string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Request
+	for _, r := range s.requests {
+		if (method == "" || r.Method == method) && (path == "" || r.Path == path) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,464 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Chat commands ("/reset", "/tasks", ...) are shared by all channels through a
+// CommandRegistry. Channels pass slash-prefixed text to BaseChannel.HandleCommand
+// before publishing to the bus; the registry runs the matching handler and the
+// reply is sent back through the channel.
+
+// CommandPermission restricts who may run a command.
+type CommandPermission string
+
+const (
+	// CommandPermissionAnyone allows every sender that passed channel policy.
+	CommandPermissionAnyone CommandPermission = ""
+	// CommandPermissionWriter restricts the command to the group's file writers.
+	// Direct messages are not restricted.
+	CommandPermissionWriter CommandPermission = "writer"
+)
+
+// CommandArgKind is the value type of a command argument.
+type CommandArgKind string
+
+const (
+	CommandArgString CommandArgKind = "string"
+	CommandArgUser   CommandArgKind = "user" // a platform user ID or mention
+)
+
+// CommandArg describes one command argument. Platforms with native commands
+// (Discord) render it as a typed option.
+type CommandArg struct {
+	Name        string
+	Description string
+	Kind        CommandArgKind
+	Required    bool
+}
+
+// CommandHandler runs a command and returns the reply.
+type CommandHandler func(ctx context.Context, req *CommandRequest) CommandReply
+
+// Command is a chat command available on every channel.
+type Command struct {
+	Name        string // lowercase, without the leading slash
+	Description string
+	Args        []CommandArg
+	Permission  CommandPermission
+	GroupOnly   bool // reply with a hint instead of running in direct messages
+	Hidden      bool // not listed in /help or platform command menus
+	Handler     CommandHandler
+}
+
+// Usage renders the command with its arguments, e.g. "/task_detail <id>".
+func (c *Command) Usage() string {
+	var sb strings.Builder
+	sb.WriteString("/" + c.Name)
+	for _, a := range c.Args {
+		if a.Required {
+			sb.WriteString(" <" + a.Name + ">")
+		} else {
+			sb.WriteString(" [" + a.Name + "]")
+		}
+	}
+	return sb.String()
+}
+
+// CommandUser identifies the person a command acts on (e.g. /addwriter).
+type CommandUser struct {
+	ID          string
+	DisplayName string
+	Username    string
+}
+
+// label returns "@username", the display name or the ID, in that order.
+func (u *CommandUser) label() string {
+	switch {
+	case u.Username != "":
+		return "@" + u.Username
+	case u.DisplayName != "":
+		return u.DisplayName
+	default:
+		return u.ID
+	}
+}
+
+// CommandRequest is one command invocation. Channels fill the sender and chat
+// fields; BaseChannel.HandleCommand fills the channel fields and the registry
+// fills Name and Args.
+type CommandRequest struct {
+	SenderID string
+	UserID   string // defaults to SenderID without a "|username" suffix
+	ChatID   string
+	PeerKind string // "direct" or "group"
+	Text     string // the message as typed, e.g. "/task_detail 1a2b"
+	BotName  string // bot username; commands addressed to another bot are ignored
+	Target   *CommandUser
+	// WriterScope is the config permission scope of the group's file writers.
+	// Defaults to GroupID(); Discord uses a guild-wide scope.
+	WriterScope string
+	// Metadata is the inbound routing metadata (thread, topic, local_key...).
+	// It is copied onto replies and forwarded commands.
+	Metadata map[string]string
+
+	Channel     string
+	ChannelType string
+	TenantID    uuid.UUID
+	AgentKey    string
+
+	Name string
+	Args string
+}
+
+// IsGroup reports whether the command was sent in a group chat.
+func (r *CommandRequest) IsGroup() bool { return r.PeerKind == "group" }
+
+// GroupID returns the scope used for group file writers and team tasks.
+func (r *CommandRequest) GroupID() string {
+	return fmt.Sprintf("group:%s:%s", r.Channel, r.ChatID)
+}
+
+// writerScope returns the scope used for file writer checks and grants.
+func (r *CommandRequest) writerScope() string {
+	if r.WriterScope != "" {
+		return r.WriterScope
+	}
+	return r.GroupID()
+}
+
+// CommandAction is a follow-up the user can pick from a reply. Telegram
+// renders actions as inline buttons; other channels list them as text.
+type CommandAction struct {
+	Label   string
+	Command string // command text run when picked, e.g. "/task_detail 1a2b3c4d"
+}
+
+// CommandReply is the result of a command.
+type CommandReply struct {
+	Text    string
+	Actions []CommandAction
+	// Forward publishes the command to the bus for the gateway consumer,
+	// which owns session state (/reset, /stop, /stopall).
+	Forward bool
+	// Prompt is sent to the agent as if the user had typed it (custom commands).
+	Prompt string
+}
+
+// RenderText returns the reply text followed by its actions as plain text,
+// for channels without buttons.
+func (r CommandReply) RenderText() string {
+	if len(r.Actions) == 0 {
+		return r.Text
+	}
+	var sb strings.Builder
+	sb.WriteString(r.Text)
+	sb.WriteString("\n")
+	for _, a := range r.Actions {
+		sb.WriteString(fmt.Sprintf("\n%s — %s", a.Command, a.Label))
+	}
+	return sb.String()
+}
+
+// CommandReplier delivers a command reply natively (e.g. with buttons).
+// Channels without one get the reply as a plain outbound message.
+type CommandReplier func(ctx context.Context, req *CommandRequest, reply CommandReply) error
+
+// CommandDeps are the stores used by the built-in commands. Any may be nil;
+// commands that need a missing store reply that the feature is unavailable.
+type CommandDeps struct {
+	Agents        store.AgentStore
+	ConfigPerms   store.ConfigPermissionStore
+	Teams         store.TeamStore
+	SubagentTasks store.SubagentTaskStore
+	SystemConfigs store.SystemConfigStore // tenant custom commands
+	Approvals     ApprovalResolver        // /approve and /deny
+	OwnerIDs      []string                // senders allowed to answer approvals in any group
+}
+
+// CommandRegistry holds the chat commands shared by all channels.
+type CommandRegistry struct {
+	deps     CommandDeps
+	mu       sync.RWMutex
+	commands map[string]*Command
+	order    []string
+}
+
+// NewCommandRegistry creates a registry with the built-in commands.
+func NewCommandRegistry(deps CommandDeps) *CommandRegistry {
+	r := &CommandRegistry{
+		deps:     deps,
+		commands: make(map[string]*Command),
+	}
+	r.registerBuiltins()
+	return r
+}
+
+// Register adds a command, replacing any command with the same name.
+func (r *CommandRegistry) Register(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.commands[cmd.Name]; !exists {
+		r.order = append(r.order, cmd.Name)
+	}
+	r.commands[cmd.Name] = &cmd
+}
+
+// Lookup returns the registered command with the given name.
+func (r *CommandRegistry) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// Commands returns the registered commands in registration order.
+func (r *CommandRegistry) Commands() []Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Command, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, *r.commands[name])
+	}
+	return out
+}
+
+// CustomCommands returns the tenant's custom commands, skipping any that
+// shadow a registered command.
+func (r *CommandRegistry) CustomCommands(ctx context.Context, tenantID uuid.UUID) []store.ChatCommand {
+	if r.deps.SystemConfigs == nil {
+		return nil
+	}
+	raw, err := r.deps.SystemConfigs.Get(store.WithTenantID(ctx, tenantID), store.ChatCommandsSystemConfigKey)
+	if err != nil || raw == "" {
+		return nil
+	}
+	var out []store.ChatCommand
+	for _, c := range store.ParseChatCommands(raw) {
+		if _, builtin := r.Lookup(c.Name); !builtin {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// MenuCommands returns the listed registered commands and the tenant's
+// custom commands, for /help and platform command menus.
+func (r *CommandRegistry) MenuCommands(ctx context.Context, tenantID uuid.UUID) []Command {
+	var cmds []Command
+	for _, c := range r.Commands() {
+		if !c.Hidden {
+			cmds = append(cmds, c)
+		}
+	}
+	for _, c := range r.CustomCommands(ctx, tenantID) {
+		desc := c.Description
+		if desc == "" {
+			desc = "Custom command"
+		}
+		cmds = append(cmds, Command{
+			Name:        c.Name,
+			Description: desc,
+			Args:        []CommandArg{{Name: "text", Description: "Text passed to the command", Kind: CommandArgString}},
+		})
+	}
+	return cmds
+}
+
+// ParseCommand splits "/name@bot args" into its parts. The name is lowercased.
+// ok is false when text is not a slash command.
+func ParseCommand(text string) (name, mention, args string, ok bool) {
+	text = strings.TrimSpace(text)
+	if len(text) < 2 || text[0] != '/' {
+		return "", "", "", false
+	}
+	head, rest := text[1:], ""
+	if i := strings.IndexFunc(head, unicode.IsSpace); i >= 0 {
+		head, rest = head[:i], head[i+1:]
+	}
+	head, mention, _ = strings.Cut(head, "@")
+	if head == "" {
+		return "", "", "", false
+	}
+	return strings.ToLower(head), mention, strings.TrimSpace(rest), true
+}
+
+// Dispatch runs the command in req.Text. handled is false when the text is not
+// a known command, so the caller should process it as a regular message.
+func (r *CommandRegistry) Dispatch(ctx context.Context, req *CommandRequest) (reply CommandReply, handled bool) {
+	name, mention, args, ok := ParseCommand(req.Text)
+	if !ok {
+		return CommandReply{}, false
+	}
+	// In groups, ignore commands addressed to other bots (e.g. /help@other_bot).
+	if mention != "" && req.BotName != "" && req.IsGroup() && !strings.EqualFold(mention, req.BotName) {
+		return CommandReply{}, false
+	}
+	req.Name, req.Args = name, args
+	ctx = store.WithTenantID(ctx, req.TenantID)
+
+	if cmd, ok := r.Lookup(name); ok {
+		if cmd.GroupOnly && !req.IsGroup() {
+			return CommandReply{Text: "This command only works in group chats."}, true
+		}
+		if cmd.Permission == CommandPermissionWriter && req.IsGroup() && !r.isGroupWriter(ctx, req) {
+			return CommandReply{Text: fmt.Sprintf("Only file writers can use /%s in this group.", name)}, true
+		}
+		return cmd.Handler(ctx, req), true
+	}
+
+	for _, c := range r.CustomCommands(ctx, req.TenantID) {
+		if c.Name == name {
+			return CommandReply{Prompt: c.Expand(args)}, true
+		}
+	}
+	return CommandReply{}, false
+}
+
+// isGroupWriter reports whether the sender is a file writer of the group.
+// Fails open when the permission store is unavailable or errors, so it only
+// guards harmless commands; approvals use hasWriterGrant.
+func (r *CommandRegistry) isGroupWriter(ctx context.Context, req *CommandRequest) bool {
+	if r.deps.ConfigPerms == nil {
+		return true
+	}
+	agentID, err := r.resolveAgentUUID(ctx, req.AgentKey)
+	if err != nil {
+		return true
+	}
+	isWriter, err := r.deps.ConfigPerms.CheckPermission(ctx, agentID, req.writerScope(), store.ConfigTypeFileWriter, req.UserID)
+	if err != nil {
+		slog.Warn("security.command_writer_check_failed", "error", err, "command", req.Name, "sender", req.UserID)
+		return true
+	}
+	return isWriter
+}
+
+// hasWriterGrant reports whether the sender holds an explicit file writer
+// grant for the chat. Unlike isGroupWriter it fails closed: a missing
+// permission store, an unresolved agent or a lookup error all deny.
+func (r *CommandRegistry) hasWriterGrant(ctx context.Context, req *CommandRequest) bool {
+	if r.deps.ConfigPerms == nil {
+		return false
+	}
+	agentID, err := r.resolveAgentUUID(ctx, req.AgentKey)
+	if err != nil {
+		slog.Warn("security.command_writer_check_failed", "error", err, "command", req.Name, "sender", req.UserID)
+		return false
+	}
+	isWriter, err := r.deps.ConfigPerms.CheckPermission(ctx, agentID, req.writerScope(), store.ConfigTypeFileWriter, req.UserID)
+	if err != nil {
+		slog.Warn("security.command_writer_check_failed", "error", err, "command", req.Name, "sender", req.UserID)
+		return false
+	}
+	return isWriter
+}
+
+// resolveAgentUUID looks up the agent UUID from the channel's agent key.
+func (r *CommandRegistry) resolveAgentUUID(ctx context.Context, key string) (uuid.UUID, error) {
+	if key == "" {
+		return uuid.Nil, fmt.Errorf("no agent key configured")
+	}
+	if id, err := uuid.Parse(key); err == nil {
+		return id, nil
+	}
+	if r.deps.Agents == nil {
+		return uuid.Nil, fmt.Errorf("agent store not configured")
+	}
+	agent, err := r.deps.Agents.GetByKey(ctx, key)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("agent %q not found: %w", key, err)
+	}
+	return agent.ID, nil
+}
+
+// SetCommandRegistry enables shared chat commands on the channel.
+func (c *BaseChannel) SetCommandRegistry(r *CommandRegistry) { c.commands = r }
+
+// CommandRegistry returns the shared chat command registry (nil = commands disabled).
+func (c *BaseChannel) CommandRegistry() *CommandRegistry { return c.commands }
+
+// SetCommandReplier overrides how command replies are delivered.
+func (c *BaseChannel) SetCommandReplier(fn CommandReplier) { c.commandReplier = fn }
+
+// HandleCommand runs req.Text through the shared command registry. Returns
+// true when the text was a command and has been fully handled — the reply
+// sent, the command forwarded to the consumer, or the custom command's
+// prompt published. Returns false for regular messages and unknown commands.
+func (c *BaseChannel) HandleCommand(ctx context.Context, req *CommandRequest) bool {
+	if c.commands == nil {
+		return false
+	}
+	if cmd, ok := ApprovalReplyCommand(req.Text); ok {
+		req.Text = cmd
+	}
+	if !strings.HasPrefix(strings.TrimSpace(req.Text), "/") {
+		return false
+	}
+	req.Channel = c.name
+	req.ChannelType = c.Type()
+	req.TenantID = c.tenantID
+	req.AgentKey = c.agentID
+	if req.UserID == "" {
+		req.UserID, _, _ = strings.Cut(req.SenderID, "|")
+	}
+
+	reply, ok := c.commands.Dispatch(ctx, req)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case reply.Prompt != "":
+		c.publishCommand(req, reply.Prompt, req.Metadata)
+	case reply.Forward:
+		meta := make(map[string]string, len(req.Metadata)+1)
+		for k, v := range req.Metadata {
+			meta[k] = v
+		}
+		meta["command"] = req.Name
+		c.publishCommand(req, "/"+req.Name, meta)
+	}
+
+	if reply.Text == "" && len(reply.Actions) == 0 {
+		return true
+	}
+	if c.commandReplier != nil {
+		if err := c.commandReplier(ctx, req, reply); err != nil {
+			slog.Warn("command reply failed", "channel", c.name, "command", req.Name, "error", err)
+		}
+		return true
+	}
+	c.bus.PublishOutbound(bus.OutboundMessage{
+		Channel:  c.name,
+		ChatID:   req.ChatID,
+		Content:  reply.RenderText(),
+		Metadata: copyRoutingMeta(req.Metadata),
+	})
+	return true
+}
+
+// publishCommand publishes a forwarded command or an expanded prompt.
+func (c *BaseChannel) publishCommand(req *CommandRequest, content string, meta map[string]string) {
+	c.bus.PublishInbound(bus.InboundMessage{
+		Channel:  c.name,
+		SenderID: req.SenderID,
+		ChatID:   req.ChatID,
+		Content:  content,
+		PeerKind: req.PeerKind,
+		UserID:   req.UserID,
+		AgentID:  c.agentID,
+		TenantID: c.tenantID,
+		Metadata: meta,
+	})
+}
@@ -0,0 +1,504 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	maxTasksInList     = 30
+	maxSubagentsInList = 30
+)
+
+// registerBuiltins registers the commands every channel supports.
+func (r *CommandRegistry) registerBuiltins() {
+	r.Register(Command{Name: "help", Description: "Show available commands", Handler: r.cmdHelp})
+	r.Register(Command{Name: "stop", Description: "Stop current running task", Handler: cmdForward("")})
+	r.Register(Command{Name: "stopall", Description: "Stop all running tasks", Handler: cmdForward("")})
+	r.Register(Command{
+		Name:        "reset",
+		Description: "Reset conversation history",
+		Permission:  CommandPermissionWriter,
+		Handler:     cmdForward("Conversation history has been reset."),
+	})
+	r.Register(Command{Name: "status", Description: "Show bot status", Handler: cmdStatus})
+	r.Register(Command{Name: "tasks", Description: "List team tasks", Handler: r.cmdTasks})
+	r.Register(Command{
+		Name:        "task_detail",
+		Description: "View task detail by ID",
+		Args:        []CommandArg{{Name: "id", Description: "Task ID or ID prefix", Kind: CommandArgString, Required: true}},
+		Handler:     r.cmdTaskDetail,
+	})
+	r.Register(Command{Name: "subagents", Description: "List subagent tasks", Handler: r.cmdSubagents})
+	r.Register(Command{
+		Name:        "subagent",
+		Description: "View subagent task detail by ID",
+		Args:        []CommandArg{{Name: "id", Description: "Subagent task ID", Kind: CommandArgString, Required: true}},
+		Handler:     r.cmdSubagentDetail,
+	})
+	r.Register(Command{
+		Name:        "writers",
+		Description: "List file writers for this group",
+		GroupOnly:   true,
+		Handler:     r.cmdListWriters,
+	})
+	r.Register(Command{
+		Name:        "addwriter",
+		Description: "Add a file writer (reply to their message)",
+		Args:        []CommandArg{{Name: "user", Description: "Person to add", Kind: CommandArgUser}},
+		GroupOnly:   true,
+		Handler:     r.cmdWriter("add"),
+	})
+	r.Register(Command{
+		Name:        "removewriter",
+		Description: "Remove a file writer (reply to their message)",
+		Args:        []CommandArg{{Name: "user", Description: "Person to remove", Kind: CommandArgUser}},
+		GroupOnly:   true,
+		Handler:     r.cmdWriter("remove"),
+	})
+	r.Register(Command{
+		Name:        "approve",
+		Description: "Approve a pending request",
+		Args:        []CommandArg{{Name: "code", Description: "Approval code", Kind: CommandArgString, Required: true}},
+		Hidden:      true,
+		Handler:     r.cmdApproval(true),
+	})
+	r.Register(Command{
+		Name:        "deny",
+		Description: "Deny a pending request",
+		Args:        []CommandArg{{Name: "code", Description: "Approval code", Kind: CommandArgString, Required: true}},
+		Hidden:      true,
+		Handler:     r.cmdApproval(false),
+	})
+}
+
+// cmdForward hands the command to the gateway consumer, which owns session
+// state. /stop feedback is sent by the consumer once the cancel result is known.
+func cmdForward(ack string) CommandHandler {
+	return func(_ context.Context, _ *CommandRequest) CommandReply {
+		return CommandReply{Text: ack, Forward: true}
+	}
+}
+
+func (r *CommandRegistry) cmdHelp(ctx context.Context, req *CommandRequest) CommandReply {
+	var sb strings.Builder
+	sb.WriteString("Available commands:\n")
+	for _, c := range r.Commands() {
+		if !c.Hidden {
+			sb.WriteString(fmt.Sprintf("%s — %s\n", c.Usage(), c.Description))
+		}
+	}
+	for _, c := range r.CustomCommands(ctx, req.TenantID) {
+		desc := c.Description
+		if desc == "" {
+			desc = "Custom command"
+		}
+		sb.WriteString(fmt.Sprintf("/%s — %s\n", c.Name, desc))
+	}
+	sb.WriteString("\nJust send a message to chat with the AI.")
+	return CommandReply{Text: sb.String()}
+}
+
+func cmdStatus(_ context.Context, req *CommandRequest) CommandReply {
+	var sb strings.Builder
+	sb.WriteString("Bot status: Running\n")
+	sb.WriteString(fmt.Sprintf("Channel: %s (%s)\n", req.Channel, req.ChannelType))
+	if req.BotName != "" {
+		sb.WriteString(fmt.Sprintf("Bot: @%s\n", req.BotName))
+	}
+	if req.AgentKey != "" {
+		sb.WriteString(fmt.Sprintf("Agent: %s\n", req.AgentKey))
+	}
+	return CommandReply{Text: strings.TrimRight(sb.String(), "\n")}
+}
+
+// --- Team tasks ---
+
+// taskStatusIcon returns a short icon for each task status.
+func taskStatusIcon(status string) string {
+	switch status {
+	case "completed":
+		return "✅"
+	case "in_progress":
+		return "🔄"
+	case "blocked":
+		return "⛔"
+	default: // pending
+		return "⏳"
+	}
+}
+
+// truncateRunes truncates a string to maxLen runes, appending "…" if truncated.
+func truncateRunes(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen]) + "…"
+}
+
+// taskUserID composes the scoped user ID for task filtering.
+// Groups use "group:{channel}:{chatID}", DMs use the chat ID directly.
+func taskUserID(req *CommandRequest) string {
+	if req.IsGroup() {
+		return req.GroupID()
+	}
+	return req.ChatID
+}
+
+// listTeamTasks returns the tasks of the agent's team visible from the chat.
+// On failure the returned reply explains why.
+func (r *CommandRegistry) listTeamTasks(ctx context.Context, req *CommandRequest) (*store.TeamData, []store.TeamTaskData, *CommandReply) {
+	fail := func(text string) (*store.TeamData, []store.TeamTaskData, *CommandReply) {
+		return nil, nil, &CommandReply{Text: text}
+	}
+	if r.deps.Teams == nil {
+		return fail("Team features are not available.")
+	}
+	agentID, err := r.resolveAgentUUID(ctx, req.AgentKey)
+	if err != nil {
+		slog.Debug("tasks command: agent resolve failed", "error", err)
+		return fail("Team features are not available (no agent).")
+	}
+	team, err := r.deps.Teams.GetTeamForAgent(ctx, agentID)
+	if err != nil {
+		slog.Warn("tasks command: GetTeamForAgent failed", "error", err)
+		return fail("Failed to look up team. Please try again.")
+	}
+	if team == nil {
+		return fail("This agent is not part of any team.")
+	}
+	tasks, err := r.deps.Teams.ListTasks(ctx, team.ID, "newest", store.TeamTaskFilterAll, taskUserID(req), "", "", 0, 0)
+	if err != nil {
+		slog.Warn("tasks command: ListTasks failed", "error", err)
+		return fail("Failed to list tasks. Please try again.")
+	}
+	return team, tasks, nil
+}
+
+func (r *CommandRegistry) cmdTasks(ctx context.Context, req *CommandRequest) CommandReply {
+	team, tasks, failed := r.listTeamTasks(ctx, req)
+	if failed != nil {
+		return *failed
+	}
+	if len(tasks) == 0 {
+		return CommandReply{Text: fmt.Sprintf("No tasks for team %q.", team.Name)}
+	}
+
+	total := len(tasks)
+	if total > maxTasksInList {
+		tasks = tasks[:maxTasksInList]
+	}
+
+	var sb strings.Builder
+	if total > maxTasksInList {
+		sb.WriteString(fmt.Sprintf("Tasks for team %q (showing %d of %d):\n\n", team.Name, maxTasksInList, total))
+	} else {
+		sb.WriteString(fmt.Sprintf("Tasks for team %q (%d):\n\n", team.Name, total))
+	}
+	actions := make([]CommandAction, 0, len(tasks))
+	for i, t := range tasks {
+		owner := ""
+		if t.OwnerAgentKey != "" {
+			owner = " — @" + t.OwnerAgentKey
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s %s%s\n", i+1, taskStatusIcon(t.Status), t.Subject, owner))
+		actions = append(actions, CommandAction{
+			Label:   fmt.Sprintf("%d. %s %s", i+1, taskStatusIcon(t.Status), truncateRunes(t.Subject, 35)),
+			Command: "/task_detail " + t.ID.String()[:8],
+		})
+	}
+	return CommandReply{Text: strings.TrimRight(sb.String(), "\n"), Actions: actions}
+}
+
+func (r *CommandRegistry) cmdTaskDetail(ctx context.Context, req *CommandRequest) CommandReply {
+	if req.Args == "" {
+		return CommandReply{Text: "Usage: /task_detail <task_id>"}
+	}
+	_, tasks, failed := r.listTeamTasks(ctx, req)
+	if failed != nil {
+		return *failed
+	}
+
+	// Find task by full UUID or prefix match.
+	taskIDLower := strings.ToLower(req.Args)
+	for i := range tasks {
+		tid := tasks[i].ID.String()
+		if tid == taskIDLower || strings.HasPrefix(tid, taskIDLower) {
+			return CommandReply{Text: formatTaskDetail(&tasks[i])}
+		}
+	}
+	return CommandReply{Text: fmt.Sprintf("Task %q not found. Use /tasks to see available tasks.", req.Args)}
+}
+
+// formatTaskDetail formats a single task for display.
+func formatTaskDetail(t *store.TeamTaskData) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Task: %s\n", t.Subject))
+	sb.WriteString(fmt.Sprintf("ID: %s\n", t.ID.String()))
+	sb.WriteString(fmt.Sprintf("Status: %s %s\n", taskStatusIcon(t.Status), t.Status))
+	if t.OwnerAgentKey != "" {
+		sb.WriteString(fmt.Sprintf("Owner: @%s\n", t.OwnerAgentKey))
+	}
+	sb.WriteString(fmt.Sprintf("Priority: %d\n", t.Priority))
+	if !t.CreatedAt.IsZero() {
+		sb.WriteString(fmt.Sprintf("Created: %s\n", t.CreatedAt.Format("2006-01-02 15:04")))
+	}
+	if t.Description != "" {
+		sb.WriteString(fmt.Sprintf("\nDescription:\n%s\n", t.Description))
+	}
+	if t.Result != nil && *t.Result != "" {
+		sb.WriteString(fmt.Sprintf("\nResult:\n%s\n", *t.Result))
+	}
+	if len(t.BlockedBy) > 0 {
+		ids := make([]string, len(t.BlockedBy))
+		for j, bid := range t.BlockedBy {
+			ids[j] = bid.String()[:8]
+		}
+		sb.WriteString(fmt.Sprintf("\nBlocked by: %s\n", strings.Join(ids, ", ")))
+	}
+	return sb.String()
+}
+
+// --- Subagent tasks ---
+
+// subagentStatusIcon returns an icon for each subagent task status.
+func subagentStatusIcon(status string) string {
+	switch status {
+	case "completed":
+		return "✅"
+	case "failed":
+		return "❌"
+	case "cancelled":
+		return "⏹"
+	default: // running
+		return "🔄"
+	}
+}
+
+// formatTokenCount formats token counts as "1.2k" for readability.
+func formatTokenCount(n int64) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func (r *CommandRegistry) cmdSubagents(ctx context.Context, req *CommandRequest) CommandReply {
+	if r.deps.SubagentTasks == nil {
+		return CommandReply{Text: "Subagent task tracking is not available."}
+	}
+	if req.AgentKey == "" {
+		return CommandReply{Text: "Subagent tasks are not available (no agent configured)."}
+	}
+
+	tasks, err := r.deps.SubagentTasks.ListByParent(ctx, req.AgentKey, "")
+	if err != nil {
+		slog.Warn("subagents command: ListByParent failed", "error", err)
+		return CommandReply{Text: "Failed to list subagent tasks. Please try again."}
+	}
+	if len(tasks) == 0 {
+		return CommandReply{Text: "No subagent tasks found."}
+	}
+
+	total := len(tasks)
+	if total > maxSubagentsInList {
+		tasks = tasks[:maxSubagentsInList]
+	}
+
+	var sb strings.Builder
+	if total > maxSubagentsInList {
+		sb.WriteString(fmt.Sprintf("Subagent tasks (showing %d of %d):\n\n", maxSubagentsInList, total))
+	} else {
+		sb.WriteString(fmt.Sprintf("Subagent tasks (%d):\n\n", total))
+	}
+	actions := make([]CommandAction, 0, len(tasks))
+	for i, t := range tasks {
+		tokens := fmt.Sprintf("%s/%s tokens", formatTokenCount(t.InputTokens), formatTokenCount(t.OutputTokens))
+		if t.Model != nil && *t.Model != "" {
+			sb.WriteString(fmt.Sprintf("%d. %s %s (%s, %s)\n", i+1, subagentStatusIcon(t.Status), truncateRunes(t.Subject, 40), *t.Model, tokens))
+		} else {
+			sb.WriteString(fmt.Sprintf("%d. %s %s (%s)\n", i+1, subagentStatusIcon(t.Status), truncateRunes(t.Subject, 40), tokens))
+		}
+		actions = append(actions, CommandAction{
+			Label:   fmt.Sprintf("%d. %s %s", i+1, subagentStatusIcon(t.Status), truncateRunes(t.Subject, 35)),
+			Command: "/subagent " + t.ID.String(),
+		})
+	}
+	return CommandReply{Text: strings.TrimRight(sb.String(), "\n"), Actions: actions}
+}
+
+func (r *CommandRegistry) cmdSubagentDetail(ctx context.Context, req *CommandRequest) CommandReply {
+	if req.Args == "" {
+		return CommandReply{Text: "Usage: /subagent <task_id>"}
+	}
+	if r.deps.SubagentTasks == nil {
+		return CommandReply{Text: "Subagent task tracking is not available."}
+	}
+
+	taskID, err := uuid.Parse(req.Args)
+	if err != nil {
+		return CommandReply{Text: fmt.Sprintf("Invalid task ID %q. Use /subagents to list tasks.", req.Args)}
+	}
+	task, err := r.deps.SubagentTasks.Get(ctx, taskID)
+	if err != nil {
+		slog.Warn("subagent command: Get failed", "id", req.Args, "error", err)
+		return CommandReply{Text: "Failed to load subagent task. Please try again."}
+	}
+	if task == nil {
+		return CommandReply{Text: fmt.Sprintf("Task %q not found. Use /subagents to see available tasks.", req.Args[:8])}
+	}
+	return CommandReply{Text: formatSubagentDetail(task)}
+}
+
+// formatSubagentDetail formats a single subagent task for display.
+func formatSubagentDetail(t *store.SubagentTaskData) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Subagent: %s\n", t.Subject))
+	sb.WriteString(fmt.Sprintf("ID: %s\n", t.ID.String()))
+	sb.WriteString(fmt.Sprintf("Status: %s %s\n", subagentStatusIcon(t.Status), t.Status))
+	if t.Model != nil && *t.Model != "" {
+		sb.WriteString(fmt.Sprintf("Model: %s\n", *t.Model))
+	}
+	sb.WriteString(fmt.Sprintf("Depth: %d\n", t.Depth))
+	sb.WriteString(fmt.Sprintf("Iterations: %d\n", t.Iterations))
+	sb.WriteString(fmt.Sprintf("Tokens: %s in / %s out\n", formatTokenCount(t.InputTokens), formatTokenCount(t.OutputTokens)))
+	if !t.CreatedAt.IsZero() {
+		sb.WriteString(fmt.Sprintf("Created: %s\n", t.CreatedAt.Format("2006-01-02 15:04")))
+	}
+	if t.Description != "" {
+		sb.WriteString(fmt.Sprintf("\nPrompt:\n%s\n", truncateRunes(t.Description, 500)))
+	}
+	if t.Result != nil && *t.Result != "" {
+		sb.WriteString(fmt.Sprintf("\nResult:\n%s\n", truncateRunes(*t.Result, 1000)))
+	}
+	return sb.String()
+}
+
+// --- Group file writers ---
+
+// commandTarget returns the user a writer command acts on: the author of the
+// replied-to message, or a user ID / mention passed as argument.
+func commandTarget(req *CommandRequest) *CommandUser {
+	if req.Target != nil && req.Target.ID != "" {
+		return req.Target
+	}
+	arg := strings.Fields(req.Args)
+	if len(arg) == 0 {
+		return nil
+	}
+	// Accept "<@123>", "<@!123>", "@123" and "123".
+	id := strings.TrimSuffix(strings.TrimLeft(arg[0], "<@!"), ">")
+	if id == "" {
+		return nil
+	}
+	return &CommandUser{ID: id}
+}
+
+// cmdWriter handles /addwriter and /removewriter. Only existing writers can
+// change the list; when there are none, the first /addwriter bootstraps it.
+func (r *CommandRegistry) cmdWriter(action string) CommandHandler {
+	return func(ctx context.Context, req *CommandRequest) CommandReply {
+		reply := func(text string) CommandReply { return CommandReply{Text: text} }
+
+		if r.deps.ConfigPerms == nil {
+			return reply("File writer management is not available.")
+		}
+		agentID, err := r.resolveAgentUUID(ctx, req.AgentKey)
+		if err != nil {
+			slog.Debug("writer command: agent resolve failed", "error", err)
+			return reply("File writer management is not available (no agent).")
+		}
+		groupID := req.writerScope()
+
+		existingWriters, _ := r.deps.ConfigPerms.ListFileWriters(ctx, agentID, groupID)
+		if len(existingWriters) > 0 {
+			isWriter := false
+			for _, w := range existingWriters {
+				if w.UserID == req.UserID {
+					isWriter = true
+					break
+				}
+			}
+			if !isWriter {
+				return reply("Only existing file writers can manage the writer list.")
+			}
+		} else if action == "remove" {
+			return reply("No file writers configured yet. Use /addwriter to add the first one.")
+		}
+
+		target := commandTarget(req)
+		if target == nil {
+			return reply(fmt.Sprintf("To %s a writer: reply to a message from that person with /%swriter, or mention them after the command.", action, action))
+		}
+
+		switch action {
+		case "add":
+			meta, _ := json.Marshal(map[string]string{"displayName": target.DisplayName, "username": target.Username})
+			if err := r.deps.ConfigPerms.Grant(ctx, &store.ConfigPermission{
+				AgentID:    agentID,
+				Scope:      groupID,
+				ConfigType: store.ConfigTypeFileWriter,
+				UserID:     target.ID,
+				Permission: "allow",
+				Metadata:   meta,
+			}); err != nil {
+				slog.Warn("add writer failed", "error", err, "target", target.ID)
+				return reply("Failed to add writer. Please try again.")
+			}
+			return reply(fmt.Sprintf("Added %s as a file writer.", target.label()))
+
+		default: // "remove"
+			if len(existingWriters) <= 1 {
+				return reply("Cannot remove the last file writer.")
+			}
+			if err := r.deps.ConfigPerms.Revoke(ctx, agentID, groupID, store.ConfigTypeFileWriter, target.ID); err != nil {
+				slog.Warn("remove writer failed", "error", err, "target", target.ID)
+				return reply("Failed to remove writer. Please try again.")
+			}
+			return reply(fmt.Sprintf("Removed %s from file writers.", target.label()))
+		}
+	}
+}
+
+func (r *CommandRegistry) cmdListWriters(ctx context.Context, req *CommandRequest) CommandReply {
+	if r.deps.ConfigPerms == nil {
+		return CommandReply{Text: "File writer management is not available."}
+	}
+	agentID, err := r.resolveAgentUUID(ctx, req.AgentKey)
+	if err != nil {
+		slog.Debug("list writers: agent resolve failed", "error", err)
+		return CommandReply{Text: "File writer management is not available (no agent)."}
+	}
+
+	writers, err := r.deps.ConfigPerms.List(ctx, agentID, store.ConfigTypeFileWriter, req.writerScope())
+	if err != nil {
+		slog.Warn("list writers failed", "error", err)
+		return CommandReply{Text: "Failed to list writers. Please try again."}
+	}
+	if len(writers) == 0 {
+		return CommandReply{Text: "No file writers configured for this group. Use /addwriter to add one."}
+	}
+
+	type fwMeta struct {
+		DisplayName string `json:"displayName"`
+		Username    string `json:"username"`
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("File writers for this group (%d):\n", len(writers)))
+	for i, w := range writers {
+		var meta fwMeta
+		_ = json.Unmarshal(w.Metadata, &meta)
+		label := (&CommandUser{ID: w.UserID, DisplayName: meta.DisplayName, Username: meta.Username}).label()
+		sb.WriteString(fmt.Sprintf("%d. %s (ID: %s)\n", i+1, label, w.UserID))
+	}
+	return CommandReply{Text: strings.TrimRight(sb.String(), "\n")}
+}
@@ -0,0 +1,246 @@
+package channels
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// mockSystemConfigs serves a fixed system_configs map.
+type mockSystemConfigs struct {
+	values map[string]string
+}
+
+func (m *mockSystemConfigs) Get(_ context.Context, key string) (string, error) {
+	return m.values[key], nil
+}
+func (m *mockSystemConfigs) Set(context.Context, string, string) error { return nil }
+func (m *mockSystemConfigs) Delete(context.Context, string) error      { return nil }
+func (m *mockSystemConfigs) List(context.Context) (map[string]string, error) {
+	return m.values, nil
+}
+
+// mockConfigPerms grants file_writer to the listed user IDs.
+type mockConfigPerms struct {
+	writers map[string]bool
+	scopes  []string
+	err     error // returned by CheckPermission when set
+}
+
+func (m *mockConfigPerms) CheckPermission(_ context.Context, _ uuid.UUID, scope, _, userID string) (bool, error) {
+	m.scopes = append(m.scopes, scope)
+	if m.err != nil {
+		return false, m.err
+	}
+	return m.writers[userID], nil
+}
+func (m *mockConfigPerms) Grant(context.Context, *store.ConfigPermission) error { return nil }
+func (m *mockConfigPerms) Revoke(context.Context, uuid.UUID, string, string, string) error {
+	return nil
+}
+func (m *mockConfigPerms) List(context.Context, uuid.UUID, string, string) ([]store.ConfigPermission, error) {
+	return nil, nil
+}
+func (m *mockConfigPerms) ListFileWriters(context.Context, uuid.UUID, string) ([]store.ConfigPermission, error) {
+	return nil, nil
+}
+
+func newCommandChannel(t *testing.T, deps CommandDeps) (*BaseChannel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.New()
+	bc := NewBaseChannel("test", mb, nil)
+	bc.SetType("test")
+	bc.SetAgentID(uuid.NewString())
+	bc.SetCommandRegistry(NewCommandRegistry(deps))
+	return bc, mb
+}
+
+func consumeInbound(t *testing.T, mb *bus.MessageBus) bus.InboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("expected an inbound message")
+	}
+	return msg
+}
+
+func consumeOutbound(t *testing.T, mb *bus.MessageBus) bus.OutboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := mb.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected an outbound message")
+	}
+	return msg
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text                string
+		name, mention, args string
+		ok                  bool
+	}{
+		{"/reset", "reset", "", "", true},
+		{"  /Task_Detail 1a2b  ", "task_detail", "", "1a2b", true},
+		{"/help@my_bot", "help", "my_bot", "", true},
+		{"/summarize@my_bot the last\nweek", "summarize", "my_bot", "the last\nweek", true},
+		{"hello /reset", "", "", "", false},
+		{"/", "", "", "", false},
+		{"/@bot", "", "", "", false},
+	}
+	for _, tt := range tests {
+		name, mention, args, ok := ParseCommand(tt.text)
+		if name != tt.name || mention != tt.mention || args != tt.args || ok != tt.ok {
+			t.Errorf("ParseCommand(%q) = (%q, %q, %q, %v); want (%q, %q, %q, %v)",
+				tt.text, name, mention, args, ok, tt.name, tt.mention, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestHandleCommand_RegularMessageNotHandled(t *testing.T) {
+	bc, _ := newCommandChannel(t, CommandDeps{})
+	for _, text := range []string{"hello", "/start", "/unknown args"} {
+		if bc.HandleCommand(context.Background(), &CommandRequest{SenderID: "u1", ChatID: "c1", PeerKind: "direct", Text: text}) {
+			t.Errorf("HandleCommand(%q) = true; want false", text)
+		}
+	}
+}
+
+func TestHandleCommand_NoRegistry(t *testing.T) {
+	bc := NewBaseChannel("test", bus.New(), nil)
+	if bc.HandleCommand(context.Background(), &CommandRequest{ChatID: "c1", Text: "/help"}) {
+		t.Error("HandleCommand without registry = true; want false")
+	}
+}
+
+func TestHandleCommand_ReplyCopiesRoutingMetadata(t *testing.T) {
+	bc, mb := newCommandChannel(t, CommandDeps{})
+	handled := bc.HandleCommand(context.Background(), &CommandRequest{
+		SenderID: "u1",
+		ChatID:   "c1",
+		PeerKind: "direct",
+		Text:     "/help",
+		Metadata: map[string]string{"message_thread_id": "42", "user_name": "alice"},
+	})
+	if !handled {
+		t.Fatal("HandleCommand(/help) = false; want true")
+	}
+	out := consumeOutbound(t, mb)
+	if out.ChatID != "c1" || !strings.Contains(out.Content, "/task_detail <id>") {
+		t.Errorf("unexpected reply: chat=%q content=%q", out.ChatID, out.Content)
+	}
+	if out.Metadata["message_thread_id"] != "42" {
+		t.Errorf("thread metadata not copied: %v", out.Metadata)
+	}
+	if _, ok := out.Metadata["user_name"]; ok {
+		t.Errorf("non-routing metadata copied: %v", out.Metadata)
+	}
+}
+
+func TestHandleCommand_ForwardsSessionCommands(t *testing.T) {
+	bc, mb := newCommandChannel(t, CommandDeps{})
+	bc.HandleCommand(context.Background(), &CommandRequest{
+		SenderID: "u1|alice",
+		ChatID:   "c1",
+		PeerKind: "direct",
+		Text:     "/reset",
+		Metadata: map[string]string{"local_key": "c1"},
+	})
+
+	in := consumeInbound(t, mb)
+	if in.Metadata["command"] != "reset" || in.Content != "/reset" {
+		t.Errorf("forwarded command = %q (meta %v); want /reset", in.Content, in.Metadata)
+	}
+	if in.UserID != "u1" || in.Metadata["local_key"] != "c1" {
+		t.Errorf("forwarded message lost sender/routing: user=%q meta=%v", in.UserID, in.Metadata)
+	}
+	if out := consumeOutbound(t, mb); out.Content != "Conversation history has been reset." {
+		t.Errorf("reset ack = %q", out.Content)
+	}
+}
+
+func TestHandleCommand_WriterPermission(t *testing.T) {
+	perms := &mockConfigPerms{writers: map[string]bool{"w1": true}}
+	bc, mb := newCommandChannel(t, CommandDeps{ConfigPerms: perms})
+
+	bc.HandleCommand(context.Background(), &CommandRequest{SenderID: "u1", ChatID: "g1", PeerKind: "group", Text: "/reset"})
+	if out := consumeOutbound(t, mb); !strings.Contains(out.Content, "Only file writers") {
+		t.Errorf("non-writer reply = %q; want denial", out.Content)
+	}
+	if perms.scopes[0] != "group:test:g1" {
+		t.Errorf("writer scope = %q; want group:test:g1", perms.scopes[0])
+	}
+
+	bc.HandleCommand(context.Background(), &CommandRequest{SenderID: "w1", ChatID: "g1", PeerKind: "group", Text: "/reset", WriterScope: "guild:G:*"})
+	if in := consumeInbound(t, mb); in.Metadata["command"] != "reset" {
+		t.Errorf("writer /reset not forwarded: %v", in.Metadata)
+	}
+	if perms.scopes[1] != "guild:G:*" {
+		t.Errorf("writer scope = %q; want guild:G:*", perms.scopes[1])
+	}
+}
+
+func TestHandleCommand_GroupOnly(t *testing.T) {
+	bc, mb := newCommandChannel(t, CommandDeps{})
+	bc.HandleCommand(context.Background(), &CommandRequest{SenderID: "u1", ChatID: "c1", PeerKind: "direct", Text: "/writers"})
+	if out := consumeOutbound(t, mb); out.Content != "This command only works in group chats." {
+		t.Errorf("reply = %q", out.Content)
+	}
+}
+
+func TestHandleCommand_IgnoresOtherBots(t *testing.T) {
+	bc, _ := newCommandChannel(t, CommandDeps{})
+	if bc.HandleCommand(context.Background(), &CommandRequest{ChatID: "g1", PeerKind: "group", BotName: "mine", Text: "/help@other"}) {
+		t.Error("command addressed to another bot was handled")
+	}
+}
+
+func TestHandleCommand_CustomCommandPublishesPrompt(t *testing.T) {
+	configs := &mockSystemConfigs{values: map[string]string{
+		store.ChatCommandsSystemConfigKey: `[
+			{"name": "/Standup", "description": "Daily standup", "prompt": "Summarize yesterday's work for {args}."},
+			{"name": "help", "prompt": "shadowed by the builtin"}
+		]`,
+	}}
+	bc, mb := newCommandChannel(t, CommandDeps{SystemConfigs: configs})
+
+	if !bc.HandleCommand(context.Background(), &CommandRequest{SenderID: "u1", ChatID: "c1", PeerKind: "direct", Text: "/standup team A"}) {
+		t.Fatal("custom command not handled")
+	}
+	in := consumeInbound(t, mb)
+	if in.Content != "Summarize yesterday's work for team A." {
+		t.Errorf("prompt = %q", in.Content)
+	}
+	if _, ok := in.Metadata["command"]; ok {
+		t.Error("custom command prompt must reach the agent, not the command path")
+	}
+
+	menu := bc.CommandRegistry().MenuCommands(context.Background(), uuid.Nil)
+	var names []string
+	for _, c := range menu {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, ","); !strings.HasSuffix(got, ",standup") || strings.Count(got, "help") != 1 {
+		t.Errorf("menu = %s", got)
+	}
+}
+
+func TestCommandReply_RenderText(t *testing.T) {
+	reply := CommandReply{
+		Text:    "Tasks (1):",
+		Actions: []CommandAction{{Label: "1. ⏳ Ship it", Command: "/task_detail 1a2b3c4d"}},
+	}
+	want := "Tasks (1):\n\n/task_detail 1a2b3c4d — 1. ⏳ Ship it"
+	if got := reply.RenderText(); got != want {
+		t.Errorf("RenderText() = %q; want %q", got, want)
+	}
+}
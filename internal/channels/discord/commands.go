@@ -0,0 +1,241 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// discordCommandDescMax is Discord's limit for command and option descriptions.
+const discordCommandDescMax = 100
+
+// nativeCommandExcluded lists shared commands not published as application
+// commands: writer management keeps the message flow (!addwriter with a
+// mention or reply) implemented in commands_writers.go.
+var nativeCommandExcluded = map[string]bool{
+	"addwriter":    true,
+	"removewriter": true,
+	"writers":      true,
+}
+
+// syncAppCommands publishes the shared chat commands as global application
+// commands. Discord may take a few minutes to show changes in clients.
+func (c *Channel) syncAppCommands(ctx context.Context, appID string) {
+	reg := c.CommandRegistry()
+	if reg == nil {
+		return
+	}
+	var cmds []*discordgo.ApplicationCommand
+	for _, cmd := range reg.MenuCommands(ctx, c.TenantID()) {
+		if nativeCommandExcluded[cmd.Name] {
+			continue
+		}
+		ac := &discordgo.ApplicationCommand{
+			Name:        cmd.Name,
+			Description: truncateDesc(cmd.Description),
+		}
+		for _, a := range cmd.Args {
+			optType := discordgo.ApplicationCommandOptionString
+			if a.Kind == channels.CommandArgUser {
+				optType = discordgo.ApplicationCommandOptionUser
+			}
+			ac.Options = append(ac.Options, &discordgo.ApplicationCommandOption{
+				Type:        optType,
+				Name:        a.Name,
+				Description: truncateDesc(a.Description),
+				Required:    a.Required,
+			})
+		}
+		cmds = append(cmds, ac)
+	}
+	if _, err := c.session.ApplicationCommandBulkOverwrite(appID, "", cmds); err != nil {
+		slog.Warn("discord: failed to sync application commands", "error", err)
+		return
+	}
+	slog.Info("discord application commands synced", "count", len(cmds))
+}
+
+func truncateDesc(s string) string {
+	if s == "" {
+		return "-"
+	}
+	runes := []rune(s)
+	if len(runes) <= discordCommandDescMax {
+		return s
+	}
+	return string(runes[:discordCommandDescMax-1]) + "…"
+}
+
+// handleInteraction runs application commands and button clicks through the
+// shared command registry. The interaction is acknowledged with the command as typed; the
+// reply follows as a regular channel message.
+func (c *Channel) handleInteraction(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil || user.Bot {
+		return
+	}
+
+	ctx := store.WithTenantID(context.Background(), c.TenantID())
+	isDM := i.GuildID == ""
+	peerKind := "group"
+	allowed := c.IsAllowed(user.ID)
+	if isDM {
+		peerKind = "direct"
+		allowed = allowed && c.checkDMPolicy(ctx, user.ID, i.ChannelID)
+	} else {
+		allowed = allowed && c.checkGroupPolicy(ctx, user.ID, i.ChannelID)
+	}
+	if !allowed {
+		c.respondInteraction(i, "You can't use commands here.", true)
+		return
+	}
+
+	if i.Type == discordgo.InteractionMessageComponent {
+		c.handleComponent(ctx, i, user, peerKind)
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	text := "/" + data.Name
+	echo := text
+	var target *channels.CommandUser
+	for _, opt := range data.Options {
+		if opt.Type == discordgo.ApplicationCommandOptionUser {
+			id := fmt.Sprint(opt.Value)
+			target = &channels.CommandUser{ID: id}
+			if data.Resolved != nil {
+				if u := data.Resolved.Users[id]; u != nil {
+					target.Username = u.Username
+					target.DisplayName = u.GlobalName
+				}
+			}
+			echo += " <@" + id + ">"
+			continue
+		}
+		text += " " + fmt.Sprint(opt.Value)
+		echo += " " + fmt.Sprint(opt.Value)
+	}
+
+	req := &channels.CommandRequest{
+		SenderID: user.ID,
+		ChatID:   i.ChannelID,
+		PeerKind: peerKind,
+		Text:     text,
+		Target:   target,
+	}
+	if !isDM {
+		req.WriterScope = fmt.Sprintf("guild:%s:*", i.GuildID)
+	}
+	c.respondInteraction(i, echo, false)
+	if !c.HandleCommand(ctx, req) {
+		slog.Debug("discord: unknown application command", "name", data.Name)
+	}
+}
+
+// respondInteraction answers an interaction without pinging anyone.
+func (c *Channel) respondInteraction(i *discordgo.InteractionCreate, content string, ephemeral bool) {
+	resp := &discordgo.InteractionResponseData{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if ephemeral {
+		resp.Flags = discordgo.MessageFlagsEphemeral
+	}
+	if err := c.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: resp,
+	}); err != nil {
+		slog.Warn("discord: interaction respond failed", "error", err)
+	}
+}
+
+// componentCommandPrefix marks button custom IDs that carry a command.
+const componentCommandPrefix = "cmd:"
+
+// Discord limits: 100 chars per custom ID, 5 buttons per row, 5 rows.
+const (
+	maxCustomIDLen    = 100
+	maxButtonsPerRow  = 5
+	maxComponentRows  = 5
+	maxButtonLabelLen = 80
+)
+
+// sendCommandReply delivers a command reply, rendering its actions as
+// buttons. Replies with more actions than fit are sent as text.
+func (c *Channel) sendCommandReply(_ context.Context, req *channels.CommandRequest, reply channels.CommandReply) error {
+	if len(reply.Actions) == 0 || len(reply.Actions) > maxButtonsPerRow*maxComponentRows {
+		return c.sendChunked(req.ChatID, reply.RenderText())
+	}
+
+	var rows []discordgo.MessageComponent
+	var row discordgo.ActionsRow
+	for _, a := range reply.Actions {
+		id := componentCommandPrefix + a.Command
+		if len(id) > maxCustomIDLen {
+			continue
+		}
+		row.Components = append(row.Components, discordgo.Button{
+			Label:    channels.Truncate(a.Label, maxButtonLabelLen-3),
+			Style:    discordgo.SecondaryButton,
+			CustomID: id,
+		})
+		if len(row.Components) == maxButtonsPerRow {
+			rows = append(rows, row)
+			row = discordgo.ActionsRow{}
+		}
+	}
+	if len(row.Components) > 0 {
+		rows = append(rows, row)
+	}
+
+	// Buttons go on the last chunk so they stay below the text.
+	chunks := channels.ChunkMarkdown(reply.Text, 2000)
+	for idx, chunk := range chunks {
+		msg := &discordgo.MessageSend{Content: chunk, AllowedMentions: &discordgo.MessageAllowedMentions{}}
+		if idx == len(chunks)-1 {
+			msg.Components = rows
+		}
+		if _, err := c.session.ChannelMessageSendComplex(req.ChatID, msg); err != nil {
+			return fmt.Errorf("send discord message: %w", err)
+		}
+	}
+	return nil
+}
+
+// handleComponent runs the command behind a clicked button. The click is
+// acknowledged without changing the message; the reply follows as a regular
+// channel message.
+func (c *Channel) handleComponent(ctx context.Context, i *discordgo.InteractionCreate, user *discordgo.User, peerKind string) {
+	text, ok := strings.CutPrefix(i.MessageComponentData().CustomID, componentCommandPrefix)
+	if !ok {
+		return
+	}
+	if err := c.session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		slog.Warn("discord: interaction respond failed", "error", err)
+	}
+
+	req := &channels.CommandRequest{
+		SenderID: user.ID,
+		ChatID:   i.ChannelID,
+		PeerKind: peerKind,
+		Text:     text,
+	}
+	if i.GuildID != "" {
+		req.WriterScope = fmt.Sprintf("guild:%s:*", i.GuildID)
+	}
+	c.HandleCommand(ctx, req)
+}
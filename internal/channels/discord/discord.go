@@ -2,8 +2,10 @@ package discord
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -76,6 +78,7 @@ func New(cfg config.DiscordConfig, msgBus *bus.MessageBus, pairingSvc store.Pair
 	ch.SetRequireMention(requireMention)
 	ch.SetPairingService(pairingSvc)
 	ch.SetGroupHistory(channels.MakeHistory(channels.TypeDiscord, pendingStore, base.TenantID()))
+	ch.SetCommandReplier(ch.sendCommandReply)
 	ch.SetHistoryLimit(historyLimit)
 	return ch, nil
 }
@@ -86,6 +89,7 @@ func (c *Channel) Start(_ context.Context) error {
 	slog.Info("starting discord bot")
 
 	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(c.handleInteraction)
 
 	if err := c.session.Open(); err != nil {
 		return fmt.Errorf("open discord session: %w", err)
@@ -102,6 +106,15 @@ func (c *Channel) Start(_ context.Context) error {
 	c.SetRunning(true)
 	slog.Info("discord bot connected", "username", user.Username, "id", user.ID)
 
+	// Publish shared chat commands as slash commands (best-effort).
+	go func() {
+		appID := user.ID
+		if app, err := c.session.Application("@me"); err == nil && app.ID != "" {
+			appID = app.ID
+		}
+		c.syncAppCommands(store.WithTenantID(context.Background(), c.TenantID()), appID)
+	}()
+
 	return nil
 }
 
@@ -278,6 +291,24 @@ func (c *Channel) sendChunked(channelID, content string) error {
 	return nil
 }
 
+// ClassifySendError implements channels.SendErrorClassifier. Rate limits
+// carry Discord's retry_after; 400/403/404 (unknown channel, missing
+// access, cannot DM the user) fail the same way on every retry.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var rl *discordgo.RateLimitError
+	if errors.As(err, &rl) && rl.RateLimit != nil && rl.TooManyRequests != nil {
+		return channels.SendFailure{RetryAfter: rl.RetryAfter, ChannelWide: true}
+	}
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		switch restErr.Response.StatusCode {
+		case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+			return channels.SendFailure{Permanent: true}
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
 // lastIndexByte returns the last index of byte c in s, or -1.
 func lastIndexByte(s string, c byte) int {
 	for i := len(s) - 1; i >= 0; i-- {
@@ -72,6 +72,20 @@ func (c *Channel) handleMessage(_ *discordgo.Session, m *discordgo.MessageCreate
 		return
 	}
 
+	// Shared chat commands (/reset, /tasks, tenant custom commands, ...).
+	cmdReq := &channels.CommandRequest{
+		SenderID: senderID,
+		ChatID:   channelID,
+		PeerKind: peerKind,
+		Text:     m.Content,
+	}
+	if !isDM {
+		cmdReq.WriterScope = fmt.Sprintf("guild:%s:*", m.GuildID)
+	}
+	if c.HandleCommand(ctx, cmdReq) {
+		return
+	}
+
 	// Build content
 	content := m.Content
 
@@ -21,7 +21,7 @@ type WebhookRoute struct {
 }
 
 // dispatchOutbound consumes outbound messages from the bus and routes them
-// to the appropriate channel. Internal channels are silently skipped.
+// to the appropriate channel.
 func (m *Manager) dispatchOutbound(ctx context.Context) {
 	slog.Info("outbound dispatcher started")
 
@@ -35,90 +35,121 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 			if !ok {
 				continue
 			}
+			m.dispatchOne(ctx, msg)
+		}
+	}
+}
 
-			// Skip internal channels
-			if IsInternalChannel(msg.Channel) {
-				continue
-			}
+// dispatchOne delivers one outbound message. Internal channels are silently
+// skipped. Messages the outbox recorded at publish time go through its retry
+// policy; the rest are sent once.
+func (m *Manager) dispatchOne(ctx context.Context, msg bus.OutboundMessage) {
+	if IsInternalChannel(msg.Channel) {
+		return
+	}
+	if m.outbox != nil && msg.OutboxID != uuid.Nil {
+		m.deliverRecorded(ctx, msg)
+		return
+	}
+	m.sendDirect(ctx, msg)
+}
 
-			m.mu.RLock()
-			channel, exists := m.channels[msg.Channel]
-			m.mu.RUnlock()
+// sendDirect delivers a message once, without persistence or retries.
+func (m *Manager) sendDirect(ctx context.Context, msg bus.OutboundMessage) {
+	m.mu.RLock()
+	channel, exists := m.channels[msg.Channel]
+	m.mu.RUnlock()
 
-			if !exists {
-				slog.Warn("unknown channel for outbound message", "channel", msg.Channel)
-				continue
-			}
+	if !exists {
+		slog.Warn("unknown channel for outbound message", "channel", msg.Channel)
+		return
+	}
 
-			// Filter out temp media files that no longer exist (already sent by another dispatch).
-			if len(msg.Media) > 0 {
-				tmpDir := os.TempDir()
-				filtered := msg.Media[:0]
-				for _, media := range msg.Media {
-					if media.URL != "" && strings.HasPrefix(media.URL, tmpDir) {
-						if _, err := os.Stat(media.URL); err != nil {
-							slog.Debug("skipping already-delivered temp media", "path", media.URL)
-							continue
-						}
-					}
-					filtered = append(filtered, media)
-				}
-				msg.Media = filtered
-				// If only media was in this message and all files are gone, skip entirely.
-				if len(msg.Media) == 0 && msg.Content == "" {
-					continue
-				}
-			}
+	msg, ok := filterDeliveredMedia(msg)
+	if !ok {
+		return
+	}
 
-			// Add tenant context for per-tenant TTS auto-apply
-			sendCtx := ctx
-			if msg.TenantID != uuid.Nil {
-				sendCtx = store.WithTenantID(ctx, msg.TenantID)
-			}
+	if err := channel.Send(outboundContext(ctx, msg), msg); err != nil {
+		slog.Error("error sending message to channel",
+			"channel", msg.Channel,
+			"chat_id", msg.ChatID,
+			"content_len", len(msg.Content),
+			"content_preview", Truncate(msg.Content, 160),
+			"error", err,
+		)
+		m.notifySendFailure(ctx, channel, msg, err)
+	}
 
-			// Add agent audio context for per-agent TTS voice override
-			if msg.AgentID != uuid.Nil && len(msg.AgentOtherConfig) > 0 {
-				sendCtx = store.WithAgentAudio(sendCtx, store.AgentAudioSnapshot{
-					AgentID:     msg.AgentID,
-					OtherConfig: msg.AgentOtherConfig,
-				})
-			}
+	cleanupTempMedia(msg)
+}
 
-			if err := channel.Send(sendCtx, msg); err != nil {
-				slog.Error("error sending message to channel",
-					"channel", msg.Channel,
-					"chat_id", msg.ChatID,
-					"content_len", len(msg.Content),
-					"content_preview", Truncate(msg.Content, 160),
-					"error", err,
-				)
-				// Try to send a text-only error notification back to the chat.
-				// Only for media failures — text-only failures likely mean the chat
-				// is inaccessible (kicked, blocked, etc.) so retrying won't help.
-				if len(msg.Media) > 0 {
-					notifyMsg := bus.OutboundMessage{
-						Channel:  msg.Channel,
-						ChatID:   msg.ChatID,
-						Content:  formatChannelSendError(err),
-						Metadata: sendErrorMeta(msg.Metadata),
-						TenantID: msg.TenantID,
-					}
-					if err2 := channel.Send(sendCtx, notifyMsg); err2 != nil {
-						slog.Warn("failed to send error notification",
-							"channel", msg.Channel, "error", err2)
-					}
-				}
+// filterDeliveredMedia drops temp media files that no longer exist (already
+// sent by another dispatch). Returns false when nothing is left to send.
+func filterDeliveredMedia(msg bus.OutboundMessage) (bus.OutboundMessage, bool) {
+	if len(msg.Media) == 0 {
+		return msg, true
+	}
+	tmpDir := os.TempDir()
+	filtered := make([]bus.MediaAttachment, 0, len(msg.Media))
+	for _, media := range msg.Media {
+		if media.URL != "" && strings.HasPrefix(media.URL, tmpDir) {
+			if _, err := os.Stat(media.URL); err != nil {
+				slog.Debug("skipping already-delivered temp media", "path", media.URL)
+				continue
 			}
+		}
+		filtered = append(filtered, media)
+	}
+	msg.Media = filtered
+	// If only media was in this message and all files are gone, skip entirely.
+	return msg, len(msg.Media) > 0 || msg.Content != ""
+}
+
+// outboundContext adds the tenant (per-tenant TTS auto-apply) and the agent
+// audio snapshot (per-agent TTS voice override) to the send context.
+func outboundContext(ctx context.Context, msg bus.OutboundMessage) context.Context {
+	sendCtx := ctx
+	if msg.TenantID != uuid.Nil {
+		sendCtx = store.WithTenantID(ctx, msg.TenantID)
+	}
+	if msg.AgentID != uuid.Nil && len(msg.AgentOtherConfig) > 0 {
+		sendCtx = store.WithAgentAudio(sendCtx, store.AgentAudioSnapshot{
+			AgentID:     msg.AgentID,
+			OtherConfig: msg.AgentOtherConfig,
+		})
+	}
+	return sendCtx
+}
+
+// notifySendFailure tries to send a text-only error notification back to the
+// chat. Only for media failures — text-only failures likely mean the chat
+// is inaccessible (kicked, blocked, etc.) so retrying won't help.
+func (m *Manager) notifySendFailure(ctx context.Context, channel Channel, msg bus.OutboundMessage, err error) {
+	if len(msg.Media) == 0 {
+		return
+	}
+	notifyMsg := bus.OutboundMessage{
+		Channel:  msg.Channel,
+		ChatID:   msg.ChatID,
+		Content:  formatChannelSendError(err),
+		Metadata: sendErrorMeta(msg.Metadata),
+		TenantID: msg.TenantID,
+	}
+	if err2 := channel.Send(outboundContext(ctx, notifyMsg), notifyMsg); err2 != nil {
+		slog.Warn("failed to send error notification",
+			"channel", msg.Channel, "error", err2)
+	}
+}
 
-			// Clean up temp media files only. Workspace-generated files are preserved
-			// so they remain accessible via workspace/web UI after delivery.
-			tmpDir := os.TempDir()
-			for _, media := range msg.Media {
-				if media.URL != "" && strings.HasPrefix(media.URL, tmpDir) {
-					if err := os.Remove(media.URL); err != nil {
-						slog.Debug("failed to clean up media file", "path", media.URL, "error", err)
-					}
-				}
+// cleanupTempMedia removes temp media files only. Workspace-generated files
+// are preserved so they remain accessible via workspace/web UI after delivery.
+func cleanupTempMedia(msg bus.OutboundMessage) {
+	tmpDir := os.TempDir()
+	for _, media := range msg.Media {
+		if media.URL != "" && strings.HasPrefix(media.URL, tmpDir) {
+			if err := os.Remove(media.URL); err != nil {
+				slog.Debug("failed to clean up media file", "path", media.URL, "error", err)
 			}
 		}
 	}
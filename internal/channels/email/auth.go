@@ -0,0 +1,145 @@
+package email
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// The From header is chosen by the sender, so policy (allowlist, pairing)
+// only trusts it once the receiving MTA has verified it. The MTA records its
+// checks in Authentication-Results headers (RFC 8601) tagged with its
+// authserv-id, and removes headers carrying that id from incoming mail, so
+// only results it wrote itself can name a trusted id.
+
+// authResult is one method result of an Authentication-Results header.
+type authResult struct {
+	method string // dkim, dmarc, spf, ...
+	result string // pass, fail, none, ...
+	props  map[string]string
+}
+
+// senderAuthenticated reports whether pe's From address was verified by a
+// trusted MTA: a DMARC pass for the From domain, or a DKIM pass with a
+// signing domain aligned with it (same organizational domain, DMARC relaxed
+// alignment). SPF alone is not enough because it checks the envelope
+// sender, not From.
+func (c *Channel) senderAuthenticated(pe *parsedEmail) bool {
+	if c.config.TrustedRelay {
+		return true
+	}
+	fromDomain := addressDomain(pe.From.Address)
+	if fromDomain == "" {
+		return false
+	}
+	for _, v := range pe.AuthResults {
+		servID, results := parseAuthResults(v)
+		if !c.trustedAuthServ(servID) {
+			continue
+		}
+		for _, r := range results {
+			if r.result != "pass" {
+				continue
+			}
+			switch r.method {
+			case "dmarc":
+				if d := r.props["header.from"]; d == "" || d == fromDomain {
+					return true
+				}
+			case "dkim":
+				d := r.props["header.d"]
+				if d == "" {
+					d = addressDomain(r.props["header.i"])
+				}
+				if aligned(fromDomain, d) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// aligned reports whether two domains share an organizational domain.
+func aligned(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	orgA, errA := publicsuffix.EffectiveTLDPlusOne(a)
+	orgB, errB := publicsuffix.EffectiveTLDPlusOne(b)
+	return errA == nil && errB == nil && orgA == orgB
+}
+
+func (c *Channel) trustedAuthServ(id string) bool {
+	for _, t := range c.config.TrustedAuthServIDs {
+		if id != "" && strings.EqualFold(strings.TrimSpace(t), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAuthResults splits an Authentication-Results value into its
+// authserv-id and method results. Comments are dropped; names and values
+// are lowercased.
+func parseAuthResults(v string) (string, []authResult) {
+	parts := strings.Split(stripComments(v), ";")
+	head := strings.Fields(parts[0]) // authserv-id [version]
+	if len(head) == 0 {
+		return "", nil
+	}
+	var results []authResult
+	for _, part := range parts[1:] {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue // "none" or malformed
+		}
+		method, _, _ = strings.Cut(method, "/") // method version
+		r := authResult{
+			method: strings.ToLower(method),
+			result: strings.ToLower(result),
+			props:  map[string]string{},
+		}
+		for _, f := range fields[1:] {
+			if k, val, ok := strings.Cut(f, "="); ok {
+				r.props[strings.ToLower(k)] = strings.ToLower(strings.Trim(val, `"`))
+			}
+		}
+		results = append(results, r)
+	}
+	return strings.ToLower(head[0]), results
+}
+
+// stripComments removes (possibly nested) RFC 5322 comments.
+func stripComments(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// addressDomain returns the lowercase domain of addr ("" if it has none).
+// A bare "@domain" (DKIM header.i) is accepted.
+func addressDomain(addr string) string {
+	i := strings.LastIndex(addr, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(addr[i+1:])
+}
@@ -0,0 +1,81 @@
+package email
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func authMail(authResults ...string) []byte {
+	var raw string
+	for _, ar := range authResults {
+		raw += "Authentication-Results: " + ar + "\r\n"
+	}
+	return []byte(raw + "From: Boss <boss@example.com>\r\n" +
+		"To: agent@example.com\r\n" +
+		"Subject: Wire transfer\r\n" +
+		"Message-ID: <a1@example.com>\r\n" +
+		"\r\n" +
+		"Please send the report.\r\n")
+}
+
+func TestHandleEmail_SenderAuthentication(t *testing.T) {
+	tests := []struct {
+		name        string
+		authResults []string
+		want        bool
+	}{
+		{"dmarc pass", []string{"mx.example.net; spf=pass; dmarc=pass (p=reject) header.from=example.com"}, true},
+		{"aligned dkim pass", []string{"MX.example.net 1; dkim=pass header.d=mail.example.com header.s=s1"}, true},
+		{"parent domain dkim pass", []string{"mx.example.net; dkim=pass header.i=@example.com"}, true},
+		{"no header", nil, false},
+		{"spf only", []string{"mx.example.net; spf=pass smtp.mailfrom=example.com"}, false},
+		{"dmarc fail", []string{"mx.example.net; dkim=fail header.d=example.com; dmarc=fail header.from=example.com"}, false},
+		{"public suffix dkim domain", []string{"mx.example.net; dkim=pass header.d=com"}, false},
+		{"foreign dkim domain", []string{"mx.example.net; dkim=pass header.d=attacker.test; dmarc=fail"}, false},
+		{"untrusted authserv-id", []string{"attacker.test; dmarc=pass header.from=example.com"}, false},
+		{"forged id in comment", []string{"attacker.test (mx.example.net); dmarc=pass"}, false},
+		{"one trusted header among others", []string{"attacker.test; dmarc=fail", "mx.example.net; dmarc=pass header.from=example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch, mb := newTestChannel(t, emailInstanceConfig{DMPolicy: "allowlist", AllowFrom: []string{"boss@example.com"}})
+			pe, err := parseEmail(authMail(tt.authResults...), 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ch.handleEmail(context.Background(), pe); got != tt.want {
+				t.Fatalf("handled = %v, want %v", got, tt.want)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if _, ok := mb.ConsumeInbound(ctx); ok != tt.want {
+				t.Errorf("published = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleEmail_TrustedRelay(t *testing.T) {
+	ch, mb := newTestChannel(t, emailInstanceConfig{TrustedRelay: true, DMPolicy: "allowlist", AllowFrom: []string{"boss@example.com"}})
+	pe, err := parseEmail(authMail(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ch.handleEmail(context.Background(), pe) {
+		t.Fatal("mail from a trusted relay not handled")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, ok := mb.ConsumeInbound(ctx); !ok {
+		t.Error("no inbound message")
+	}
+}
+
+func TestNew_RequiresSenderAuthentication(t *testing.T) {
+	cfg := emailInstanceConfig{Address: "agent@example.com"}
+	cfg.IMAP.Host, cfg.SMTP.Host = "imap.test", "smtp.test"
+	if _, err := New(cfg, emailCreds{Password: "p"}, nil, nil); err == nil {
+		t.Error("config without trusted_authserv_ids or trusted_relay accepted")
+	}
+}
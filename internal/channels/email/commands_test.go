@@ -0,0 +1,118 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+func commandMail(body string) []byte {
+	return []byte("Authentication-Results: mx.example.net; dmarc=pass header.from=example.com\r\n" +
+		"From: Boss <boss@example.com>\r\n" +
+		"To: agent@example.com\r\n" +
+		"Subject: Re: Agent run\r\n" +
+		"Message-ID: <c2@example.com>\r\n" +
+		"In-Reply-To: <c1@example.com>\r\n" +
+		"\r\n" + body)
+}
+
+func newCommandTestChannel(t *testing.T, deps channels.CommandDeps) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	ch, mb := newTestChannel(t, emailInstanceConfig{DMPolicy: "allowlist", AllowFrom: []string{"boss@example.com"}})
+	ch.SetCommandRegistry(channels.NewCommandRegistry(deps))
+	return ch, mb
+}
+
+func consumeOutbound(t *testing.T, mb *bus.MessageBus) bus.OutboundMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out, ok := mb.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("no outbound reply")
+	}
+	return out
+}
+
+func TestHandleEmail_Command(t *testing.T) {
+	ch, mb := newCommandTestChannel(t, channels.CommandDeps{})
+	pe, err := parseEmail(commandMail("/status\r\n\r\nSent from my phone\r\n\r\nOn Mon, Boss wrote:\r\n> earlier\r\n"), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ch.handleEmail(context.Background(), pe) {
+		t.Fatal("command not handled")
+	}
+
+	out := consumeOutbound(t, mb)
+	if out.ChatID != "boss@example.com" || !strings.HasPrefix(out.Content, "Bot status: Running") {
+		t.Errorf("reply = %+v", out)
+	}
+	if out.Metadata[metaInReplyTo] != "c2@example.com" || out.Metadata[metaSubject] != "Re: Agent run" {
+		t.Errorf("reply is not threaded: %v", out.Metadata)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Errorf("command published to the agent: %+v", msg)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct{ body, want string }{
+		{"\n  /status  \nSent from my phone", "/status"},
+		{"APPROVE 1234\n\nThanks,\nBoss", "APPROVE 1234"},
+		{"Hi,\n/status please", ""},
+		{"Please approve 1234", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := commandLine(tt.body); got != tt.want {
+			t.Errorf("commandLine(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+// approvals serves one pending approval and records resolutions.
+type approvals struct {
+	pending  channels.ApprovalRequest
+	resolved []channels.ApprovalDecision
+}
+
+func (a *approvals) PendingApproval(code string) (channels.ApprovalRequest, bool) {
+	if code != a.pending.Code || len(a.resolved) > 0 {
+		return channels.ApprovalRequest{}, false
+	}
+	return a.pending, true
+}
+
+func (a *approvals) ResolveApproval(_ context.Context, _ channels.ApprovalRequest, decision channels.ApprovalDecision, _ string) error {
+	a.resolved = append(a.resolved, decision)
+	return nil
+}
+
+func TestHandleEmail_ApprovalReply(t *testing.T) {
+	ap := &approvals{pending: channels.ApprovalRequest{
+		ID: "exec-1", Code: "1234", Kind: channels.ApprovalKindExec, Command: "docker ps",
+		Channel: channels.TypeEmail, ChatID: "boss@example.com",
+	}}
+	ch, mb := newCommandTestChannel(t, channels.CommandDeps{Approvals: ap, OwnerIDs: []string{"boss@example.com"}})
+	pe, err := parseEmail(commandMail("APPROVE 1234\r\n\r\nThanks,\r\nBoss\r\n\r\n> Reply APPROVE 1234 or DENY 1234\r\n"), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ch.handleEmail(context.Background(), pe) {
+		t.Fatal("approval reply not handled")
+	}
+
+	if len(ap.resolved) != 1 || ap.resolved[0] != channels.ApprovalAllowOnce {
+		t.Errorf("resolved = %v", ap.resolved)
+	}
+	if out := consumeOutbound(t, mb); !strings.Contains(out.Content, "docker ps") || out.Metadata[metaInReplyTo] != "c2@example.com" {
+		t.Errorf("reply = %+v", out)
+	}
+}
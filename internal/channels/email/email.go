@@ -0,0 +1,630 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Compile-time interface assertions.
+var (
+	_ channels.Channel           = (*Channel)(nil)
+	_ channels.BlockReplyChannel = (*Channel)(nil)
+)
+
+const (
+	defaultMediaMaxMB   = 20
+	defaultPollInterval = 60 // seconds
+	defaultBackfillDays = 1
+	// idleRefresh re-issues IDLE before the 29-minute server timeout (RFC 2177).
+	idleRefresh     = 25 * time.Minute
+	pairingDebounce = 60 * time.Second
+	mediaCleanup    = 5 * time.Minute
+	minBackoff      = 5 * time.Second
+	maxBackoff      = 5 * time.Minute
+)
+
+// Channel implements channels.Channel for email over IMAP and SMTP.
+type Channel struct {
+	*channels.BaseChannel
+	config   emailInstanceConfig
+	creds    emailCreds
+	address  string // lowercase config.Address
+	maxMedia int64  // bytes per attachment
+	sigTmpl  *template.Template
+
+	// seen dedupes messages between fetch and \Seen, and skipped messages
+	// that stay unread. Key: mailbox/uidvalidity/uid.
+	seen sync.Map
+
+	stopCtx context.Context
+	stopFn  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates an email channel from parsed credentials and config.
+func New(cfg emailInstanceConfig, creds emailCreds,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (*Channel, error) {
+
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("email: address is required")
+	}
+	if cfg.IMAP.Host == "" || cfg.SMTP.Host == "" {
+		return nil, fmt.Errorf("email: imap.host and smtp.host are required")
+	}
+	if creds.Password == "" {
+		return nil, fmt.Errorf("email: password is required")
+	}
+	if len(cfg.TrustedAuthServIDs) == 0 && !cfg.TrustedRelay {
+		return nil, fmt.Errorf("email: trusted_authserv_ids or trusted_relay is required to authenticate senders")
+	}
+	for _, sc := range []*serverConfig{&cfg.IMAP, &cfg.SMTP} {
+		switch sc.Security {
+		case "":
+			sc.Security = securityTLS
+		case securityTLS, securityStartTLS, securityNone:
+		default:
+			return nil, fmt.Errorf("email: unknown security mode %q", sc.Security)
+		}
+	}
+	if cfg.IMAP.Port == 0 {
+		cfg.IMAP.Port = map[string]int{securityTLS: 993, securityStartTLS: 143, securityNone: 143}[cfg.IMAP.Security]
+	}
+	if cfg.SMTP.Port == 0 {
+		cfg.SMTP.Port = map[string]int{securityTLS: 465, securityStartTLS: 587, securityNone: 25}[cfg.SMTP.Security]
+	}
+	if len(cfg.Mailboxes) == 0 {
+		cfg.Mailboxes = []string{"INBOX"}
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.BackfillDays <= 0 {
+		cfg.BackfillDays = defaultBackfillDays
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+
+	var sig *template.Template
+	if cfg.Signature != "" {
+		t, err := template.New("signature").Parse(cfg.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("email: parse signature: %w", err)
+		}
+		sig = t
+	}
+
+	// Sender addresses are matched case-insensitively.
+	allow := make([]string, len(cfg.AllowFrom))
+	for i, a := range cfg.AllowFrom {
+		allow[i] = strings.ToLower(strings.TrimSpace(a))
+	}
+
+	base := channels.NewBaseChannel(channels.TypeEmail, msgBus, allow)
+	base.SetPairingService(pairingSvc)
+	stopCtx, stopFn := context.WithCancel(context.Background())
+
+	return &Channel{
+		BaseChannel: base,
+		config:      cfg,
+		creds:       creds,
+		address:     strings.ToLower(cfg.Address),
+		maxMedia:    int64(cfg.MediaMaxMB) * 1024 * 1024,
+		sigTmpl:     sig,
+		stopCtx:     stopCtx,
+		stopFn:      stopFn,
+	}, nil
+}
+
+// Factory creates an email Channel from DB instance data.
+// Implements channels.ChannelFactory.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+
+	var c emailCreds
+	if err := json.Unmarshal(creds, &c); err != nil {
+		return nil, fmt.Errorf("email: decode credentials: %w", err)
+	}
+
+	var ic emailInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("email: decode config: %w", err)
+		}
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
+
+// BlockReplyEnabled defaults to false: one email per agent run rather than
+// one per streamed block.
+func (c *Channel) BlockReplyEnabled() *bool {
+	if c.config.BlockReply != nil {
+		return c.config.BlockReply
+	}
+	off := false
+	return &off
+}
+
+// Start verifies the IMAP login, then watches each configured mailbox.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("connecting to IMAP server")
+
+	client, err := c.connect(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if !isNetError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("imap login failed", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return err
+	}
+	client.logout()
+	client.Close()
+
+	for _, mb := range c.config.Mailboxes {
+		c.wg.Add(1)
+		go c.watch(mb)
+	}
+
+	c.SetRunning(true)
+	c.MarkHealthy("watching " + strings.Join(c.config.Mailboxes, ", "))
+	slog.Info("email channel started", "name", c.Name(), "address", c.address, "mailboxes", c.config.Mailboxes)
+	return nil
+}
+
+// Stop cancels the mailbox watchers and waits for them to exit.
+func (c *Channel) Stop(_ context.Context) error {
+	c.stopFn()
+	c.wg.Wait()
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("email channel stopped", "name", c.Name())
+	return nil
+}
+
+func isNetError(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// connect dials the IMAP server and logs in.
+func (c *Channel) connect(ctx context.Context) (*imapClient, error) {
+	sc := c.config.IMAP
+	addr := net.JoinHostPort(sc.Host, fmt.Sprint(sc.Port))
+	tlsCfg := &tls.Config{ServerName: sc.Host, InsecureSkipVerify: sc.InsecureSkipVerify}
+	// Raw messages carry base64 attachments (~4/3 size) plus headers and body.
+	client, err := dialIMAP(ctx, addr, sc.Security, tlsCfg, c.maxMedia*2+4<<20)
+	if err != nil {
+		return nil, err
+	}
+	user := sc.Username
+	if user == "" {
+		user = c.config.Address
+	}
+	if err := client.login(user, c.creds.Password); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// watch keeps one mailbox session alive, reconnecting with backoff.
+func (c *Channel) watch(mailbox string) {
+	defer c.wg.Done()
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := c.runMailbox(mailbox)
+		if c.stopCtx.Err() != nil {
+			return
+		}
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		slog.Warn("email: mailbox session ended, reconnecting",
+			"name", c.Name(), "mailbox", mailbox, "error", err, "retry_in", backoff)
+		c.MarkDegraded("imap connection lost", fmt.Sprint(err), channels.ChannelFailureKindNetwork, true)
+		select {
+		case <-c.stopCtx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// runMailbox selects mailbox, processes unread mail and waits for new mail
+// via IDLE (or polling) until the connection fails or the channel stops.
+func (c *Channel) runMailbox(mailbox string) error {
+	ctx := c.stopCtx
+	client, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// After cancellation the session may still be in IDLE; just close.
+		if ctx.Err() == nil {
+			client.logout()
+		}
+		client.Close()
+	}()
+
+	uidValidity, err := client.selectMailbox(mailbox)
+	if err != nil {
+		return err
+	}
+	caps, err := client.capabilities()
+	if err != nil {
+		return err
+	}
+	c.MarkHealthy("watching " + strings.Join(c.config.Mailboxes, ", "))
+
+	for {
+		since := time.Now().AddDate(0, 0, -c.config.BackfillDays)
+		uids, err := client.searchUnseen(since)
+		if err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			if err := c.processUID(ctx, client, mailbox, uidValidity, uid); err != nil {
+				return err
+			}
+		}
+
+		if caps["IDLE"] {
+			err := client.idle(ctx, idleRefresh)
+			if err != nil && !errors.Is(err, errIdleTimeout) {
+				return err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(c.config.PollInterval) * time.Second):
+		}
+	}
+}
+
+// processUID fetches and handles one message. Only connection errors are
+// returned; bad messages are logged and skipped.
+func (c *Channel) processUID(ctx context.Context, client *imapClient, mailbox string, uidValidity, uid uint32) error {
+	key := fmt.Sprintf("%s/%d/%d", mailbox, uidValidity, uid)
+	if _, dup := c.seen.LoadOrStore(key, struct{}{}); dup {
+		return nil
+	}
+	raw, err := client.fetchRaw(uid)
+	if errors.Is(err, errTooLarge) {
+		slog.Warn("email: message too large, skipped", "mailbox", mailbox, "uid", uid)
+		return nil
+	}
+	if err != nil {
+		c.seen.Delete(key)
+		return err
+	}
+	pe, err := parseEmail(raw, c.maxMedia)
+	if err != nil {
+		slog.Warn("email: unparseable message skipped", "mailbox", mailbox, "uid", uid, "error", err)
+		return nil
+	}
+	if !c.handleEmail(ctx, pe) {
+		return nil
+	}
+	return client.markSeen(uid)
+}
+
+// handleEmail applies policy and publishes pe to the bus. It reports whether
+// the message was consumed (and should be marked \Seen).
+func (c *Channel) handleEmail(ctx context.Context, pe *parsedEmail) bool {
+	senderID := pe.From.Address
+	switch {
+	case senderID == "" || senderID == c.address:
+		return false
+	case pe.Automated:
+		slog.Debug("email: automated message ignored", "from", senderID, "subject", pe.Subject)
+		return false
+	case !c.senderAuthenticated(pe):
+		slog.Warn("email: unauthenticated sender dropped", "from", senderID, "subject", pe.Subject)
+		return false
+	}
+
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, pe)
+		return true
+	default:
+		slog.Debug("email message rejected by policy", "from", senderID, "policy", c.config.DMPolicy)
+		return false
+	}
+
+	refs := append([]string{}, pe.References...)
+	if len(refs) == 0 && pe.InReplyTo != "" {
+		refs = append(refs, pe.InReplyTo)
+	}
+	metadata := map[string]string{
+		"message_id":   pe.MessageID,
+		"platform":     channels.TypeEmail,
+		"local_key":    threadLocalKey(senderID, pe.threadRoot()),
+		metaInReplyTo:  pe.MessageID,
+		metaReferences: strings.Join(append(refs, pe.MessageID), " "),
+		metaSubject:    pe.Subject,
+	}
+	if pe.From.Name != "" {
+		metadata["user_name"] = pe.From.Name
+	}
+	if pe.ReplyTo != "" && pe.ReplyTo != senderID {
+		metadata[metaReplyTo] = pe.ReplyTo
+	}
+
+	// Shared chat commands (/reset, /tasks, ...) and APPROVE/DENY replies.
+	// Replies go out through the bus with the threading metadata above.
+	if cmd := commandLine(stripQuotedReply(pe.Text)); cmd != "" && c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID: senderID,
+		ChatID:   senderID,
+		PeerKind: "direct",
+		Text:     cmd,
+		Metadata: metadata,
+	}) {
+		return true
+	}
+
+	content := pe.Text
+	if pe.InReplyTo != "" {
+		content = stripQuotedReply(content)
+	} else if pe.Subject != "" {
+		content = "Subject: " + pe.Subject + "\n\n" + content
+	}
+
+	mediaList := c.saveAttachments(pe.Attachments)
+	var mediaFiles []bus.MediaFile
+	var tmpPaths []string
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	for _, m := range mediaList {
+		mediaFiles = append(mediaFiles, bus.MediaFile{Path: m.FilePath, MimeType: m.ContentType, Filename: m.FileName})
+		tmpPaths = append(tmpPaths, m.FilePath)
+	}
+	if content == "" {
+		return true
+	}
+	if pe.From.Name != "" {
+		content = fmt.Sprintf("[From: %s]\n%s", pe.From.Name, content)
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, pe.From.Name, "", "direct", "user", "", "")
+	}
+
+	c.Bus().PublishInbound(bus.InboundMessage{
+		Channel:  c.Name(),
+		SenderID: senderID,
+		ChatID:   senderID,
+		Content:  content,
+		Media:    mediaFiles,
+		PeerKind: "direct",
+		UserID:   senderID,
+		AgentID:  c.AgentID(),
+		TenantID: c.TenantID(),
+		Metadata: metadata,
+	})
+	scheduleMediaCleanup(tmpPaths, mediaCleanup)
+	return true
+}
+
+// threadLocalKey maps a thread root Message-ID to a per-thread session
+// suffix. The "m" prefix keeps numeric thread parsers (Telegram DM threads)
+// from reading the hash as a thread number.
+func threadLocalKey(address, root string) string {
+	if root == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(root))
+	return address + ":thread:m" + hex.EncodeToString(sum[:8])
+}
+
+// saveAttachments writes attachments to temp files for the agent pipeline.
+func (c *Channel) saveAttachments(atts []attachment) []media.MediaInfo {
+	var out []media.MediaInfo
+	for _, a := range atts {
+		ext := filepath.Ext(a.Filename)
+		if ext == "" {
+			if exts, _ := mime.ExtensionsByType(a.ContentType); len(exts) > 0 {
+				ext = exts[0]
+			}
+		}
+		f, err := os.CreateTemp("", "goclaw_email_*"+ext)
+		if err != nil {
+			slog.Warn("email: save attachment failed", "file", a.Filename, "error", err)
+			continue
+		}
+		_, err = f.Write(a.Data)
+		f.Close()
+		if err != nil {
+			os.Remove(f.Name())
+			slog.Warn("email: save attachment failed", "file", a.Filename, "error", err)
+			continue
+		}
+		out = append(out, media.MediaInfo{
+			Type:        media.MediaKindFromMime(a.ContentType),
+			FilePath:    f.Name(),
+			ContentType: a.ContentType,
+			FileName:    a.Filename,
+			FileSize:    int64(len(a.Data)),
+		})
+	}
+	return out
+}
+
+// scheduleMediaCleanup removes temp attachment files after a delay.
+func scheduleMediaCleanup(paths []string, delay time.Duration) {
+	if len(paths) == 0 {
+		return
+	}
+	time.AfterFunc(delay, func() {
+		for _, p := range paths {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				slog.Debug("email: temp media cleanup failed", "path", p, "error", err)
+			}
+		}
+	})
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, pe *parsedEmail) {
+	senderID := pe.From.Address
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), senderID, "default", nil)
+	if err != nil {
+		slog.Debug("email pairing request failed", "from", senderID, "error", err)
+		return
+	}
+	text := fmt.Sprintf(
+		"GoClaw: access not configured.\n\nYour email address: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code,
+	)
+	out := outgoingMail{
+		To:         senderID,
+		Subject:    replySubject(pe.Subject),
+		InReplyTo:  pe.MessageID,
+		References: append(append([]string{}, pe.References...), pe.MessageID),
+		Text:       text,
+	}
+	if out.Subject == "" {
+		out.Subject = "GoClaw pairing"
+	}
+	if err := c.deliver(ctx, out); err != nil {
+		slog.Warn("failed to send email pairing reply", "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+	slog.Info("email pairing reply sent", "from", senderID, "code", code)
+}
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers an outbound message as an email, threaded onto the inbound
+// message when routing metadata is present.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if strings.TrimSpace(msg.Content) == "" && len(msg.Media) == 0 {
+		return nil
+	}
+
+	to := msg.Metadata[metaReplyTo]
+	if to == "" {
+		to = msg.ChatID
+	}
+	if _, err := mail.ParseAddress(to); err != nil {
+		return fmt.Errorf("email: %w %q: %v", errInvalidRecipient, to, err)
+	}
+
+	subject := replySubject(msg.Metadata[metaSubject])
+	if subject == "" {
+		subject = c.config.Subject
+	}
+	if subject == "" {
+		subject = "Message from " + c.fromName()
+	}
+
+	text := msg.Content
+	var attachments []string
+	for _, m := range msg.Media {
+		if strings.HasPrefix(m.URL, "http://") || strings.HasPrefix(m.URL, "https://") {
+			text += "\n\n" + m.URL
+			continue
+		}
+		attachments = append(attachments, m.URL)
+		if m.Caption != "" {
+			text += "\n\n" + m.Caption
+		}
+	}
+	text = strings.TrimSpace(text) + c.signature(subject)
+
+	return c.deliver(ctx, outgoingMail{
+		To:          to,
+		Subject:     subject,
+		InReplyTo:   msg.Metadata[metaInReplyTo],
+		References:  strings.Fields(msg.Metadata[metaReferences]),
+		Text:        text,
+		Attachments: attachments,
+	})
+}
+
+func (c *Channel) fromName() string {
+	if c.config.FromName != "" {
+		return c.config.FromName
+	}
+	return "GoClaw"
+}
+
+// signature renders the configured signature with the RFC 3676 delimiter,
+// or "" when none is set.
+func (c *Channel) signature(subject string) string {
+	if c.sigTmpl == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	err := c.sigTmpl.Execute(&buf, map[string]string{
+		"FromName": c.fromName(),
+		"Address":  c.config.Address,
+		"Subject":  subject,
+		"Date":     time.Now().Format("2006-01-02"),
+	})
+	if err != nil {
+		slog.Warn("email: render signature failed", "name", c.Name(), "error", err)
+		return ""
+	}
+	return "\n\n-- \n" + strings.TrimSpace(buf.String())
+}
+
+// deliver fills in sender fields, encodes m and sends it over SMTP.
+func (c *Channel) deliver(ctx context.Context, m outgoingMail) error {
+	m.From = mail.Address{Name: c.fromName(), Address: c.config.Address}
+	m.MessageID = newMessageID(c.address)
+	raw, err := compose(m)
+	if err != nil {
+		return fmt.Errorf("email: compose: %w", err)
+	}
+	user, pass := c.config.SMTP.Username, c.creds.SMTPPassword
+	if user == "" {
+		user = c.config.Address
+	}
+	if pass == "" {
+		pass = c.creds.Password
+	}
+	if err := sendMail(ctx, c.config.SMTP, user, pass, c.config.Address, []string{m.To}, raw); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package email
+
+import (
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// htmlToText renders an HTML body as plain text: block elements become line
+// breaks, links keep their target, and scripts/styles are dropped.
+func htmlToText(src string) string {
+	doc, err := xhtml.Parse(strings.NewReader(src))
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+	var sb strings.Builder
+	var walk func(n *xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		switch n.Type {
+		case xhtml.TextNode:
+			sb.WriteString(collapseSpace(n.Data))
+			return
+		case xhtml.ElementNode:
+			switch n.Data {
+			case "script", "style", "head", "title":
+				return
+			case "br":
+				sb.WriteString("\n")
+				return
+			case "li":
+				sb.WriteString("\n- ")
+			case "p", "div", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "table", "blockquote", "pre", "hr":
+				sb.WriteString("\n")
+			case "td", "th":
+				sb.WriteString(" ")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == xhtml.ElementNode {
+			switch n.Data {
+			case "p", "div", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "table", "blockquote", "pre":
+				sb.WriteString("\n")
+			case "a":
+				if href := attr(n, "href"); strings.HasPrefix(href, "http") && !strings.Contains(sb.String(), href) {
+					sb.WriteString(" (" + href + ")")
+				}
+			}
+		}
+	}
+	walk(doc)
+	return tidyLines(sb.String())
+}
+
+func attr(n *xhtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+var spaceRe = regexp.MustCompile(`[ \t\r\n\f]+`)
+
+func collapseSpace(s string) string { return spaceRe.ReplaceAllString(s, " ") }
+
+// tidyLines trims each line and collapses runs of blank lines.
+func tidyLines(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		out = append(out, l)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// quoteHeaderRe matches the attribution line mail clients put above a quoted
+// reply, e.g. "On Mon, 1 Jan 2024 at 10:00, Alice <a@x.com> wrote:".
+var quoteHeaderRe = regexp.MustCompile(`(?i)^(on\s.+\swrote:|.+\s(a écrit|schrieb|escribió|ha scritto)\s?:)$`)
+
+// stripQuotedReply removes the quoted previous message and signature from a
+// reply body so the agent only sees the new text. The session already holds
+// the earlier turns.
+func stripQuotedReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	cut := len(lines)
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		joined := t
+		if i+1 < len(lines) {
+			// Attribution lines are often wrapped onto two lines.
+			joined = t + " " + strings.TrimSpace(lines[i+1])
+		}
+		switch {
+		case l == "-- " || t == "--":
+			cut = i
+		case strings.HasPrefix(t, "-----Original Message-----"),
+			strings.HasPrefix(t, "________________________________"),
+			quoteHeaderRe.MatchString(t),
+			strings.HasPrefix(strings.ToLower(t), "on ") && quoteHeaderRe.MatchString(joined),
+			strings.HasPrefix(t, "From: ") && i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "Sent: "):
+			cut = i
+		default:
+			continue
+		}
+		break
+	}
+	kept := make([]string, 0, cut)
+	for _, l := range lines[:cut] {
+		if strings.HasPrefix(strings.TrimSpace(l), ">") {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	out := strings.TrimSpace(strings.Join(kept, "\n"))
+	if out == "" {
+		// Nothing but quotes (e.g. a forward) — keep the original.
+		return strings.TrimSpace(text)
+	}
+	return out
+}
+
+// commandLine returns the first non-empty line of body when it is a chat
+// command or an approval reply. Only that line is used: mail clients append
+// signatures ("Sent from my phone") that are not always behind "-- " and
+// would otherwise be read as command arguments.
+func commandLine(body string) string {
+	for _, l := range strings.Split(body, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		if _, ok := channels.ApprovalReplyCommand(l); ok || strings.HasPrefix(l, "/") {
+			return l
+		}
+		return ""
+	}
+	return ""
+}
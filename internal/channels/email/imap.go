@@ -0,0 +1,326 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imapClient is a minimal IMAP4rev1 client covering what the channel needs:
+// LOGIN, SELECT, UID SEARCH/FETCH/STORE and IDLE (RFC 2177).
+type imapClient struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	tag    int
+	caps   map[string]bool
+	maxLit int64 // largest literal accepted (message size cap)
+}
+
+// imapResponse is one server response line with any literals it carried.
+type imapResponse struct {
+	text     string   // line text with literals replaced by {n}
+	literals [][]byte // literal payloads in order (nil when over the size cap)
+}
+
+// errIdleTimeout is returned by idle when the refresh interval elapsed
+// without mailbox changes.
+var errIdleTimeout = errors.New("imap: idle timeout")
+
+// errTooLarge is returned by fetchRaw for messages over the size cap.
+var errTooLarge = errors.New("imap: message exceeds size limit")
+
+// dialIMAP connects and reads the greeting. security is "tls" (implicit,
+// default), "starttls" or "none".
+func dialIMAP(ctx context.Context, addr, security string, tlsCfg *tls.Config, maxLit int64) (*imapClient, error) {
+	d := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if security == securityTLS {
+		conn, err = (&tls.Dialer{NetDialer: d, Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("imap dial %s: %w", addr, err)
+	}
+	c := newIMAPClient(conn, maxLit)
+	if err := c.greeting(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if security == securityStartTLS {
+		if _, err := c.cmd("STARTTLS"); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, tlsCfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("imap starttls: %w", err)
+		}
+		c.conn, c.r = tlsConn, bufio.NewReader(tlsConn)
+		c.caps = nil // capabilities must be re-read after STARTTLS
+	}
+	return c, nil
+}
+
+func newIMAPClient(conn net.Conn, maxLit int64) *imapClient {
+	return &imapClient{conn: conn, r: bufio.NewReader(conn), maxLit: maxLit}
+}
+
+func (c *imapClient) Close() error { return c.conn.Close() }
+
+func (c *imapClient) greeting() error {
+	c.conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	resp, err := c.readResponse()
+	if err != nil {
+		return fmt.Errorf("imap greeting: %w", err)
+	}
+	if !strings.HasPrefix(resp.text, "* OK") && !strings.HasPrefix(resp.text, "* PREAUTH") {
+		return fmt.Errorf("imap greeting: %s", resp.text)
+	}
+	return nil
+}
+
+// cmd sends a tagged command and collects untagged responses until the
+// tagged completion. A NO/BAD completion is returned as an error.
+func (c *imapClient) cmd(format string, args ...any) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("A%04d", c.tag)
+	line := fmt.Sprintf(format, args...)
+	c.conn.SetDeadline(time.Now().Add(2 * time.Minute))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, line); err != nil {
+		return nil, fmt.Errorf("imap write: %w", err)
+	}
+	var untagged []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, fmt.Errorf("imap %s: %w", commandName(line), err)
+		}
+		if rest, ok := strings.CutPrefix(resp.text, tag+" "); ok {
+			if !strings.HasPrefix(rest, "OK") {
+				return untagged, fmt.Errorf("imap %s: %s", commandName(line), rest)
+			}
+			return untagged, nil
+		}
+		untagged = append(untagged, resp)
+	}
+}
+
+// commandName returns the command verb for error messages (never arguments,
+// which may contain credentials).
+func commandName(line string) string {
+	name, _, _ := strings.Cut(line, " ")
+	if name == "UID" {
+		if f := strings.Fields(line); len(f) > 1 {
+			return "UID " + f[1]
+		}
+	}
+	return name
+}
+
+var literalRe = regexp.MustCompile(`\{(\d+)\}$`)
+
+// readResponse reads one logical response line, consuming literals.
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	var sb strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return resp, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		sb.WriteString(line)
+		m := literalRe.FindStringSubmatch(line)
+		if m == nil {
+			break
+		}
+		n, _ := strconv.ParseInt(m[1], 10, 64)
+		if c.maxLit > 0 && n > c.maxLit {
+			// Drain the literal so the connection stays in sync.
+			if _, err := io.CopyN(io.Discard, c.r, n); err != nil {
+				return resp, err
+			}
+			resp.literals = append(resp.literals, nil)
+			continue
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, buf)
+	}
+	resp.text = sb.String()
+	return resp, nil
+}
+
+func (c *imapClient) capabilities() (map[string]bool, error) {
+	if c.caps != nil {
+		return c.caps, nil
+	}
+	untagged, err := c.cmd("CAPABILITY")
+	if err != nil {
+		return nil, err
+	}
+	c.caps = map[string]bool{}
+	for _, r := range untagged {
+		if rest, ok := strings.CutPrefix(r.text, "* CAPABILITY "); ok {
+			for _, f := range strings.Fields(rest) {
+				c.caps[strings.ToUpper(f)] = true
+			}
+		}
+	}
+	return c.caps, nil
+}
+
+func (c *imapClient) login(user, pass string) error {
+	if _, err := c.cmd("LOGIN %s %s", quoteIMAP(user), quoteIMAP(pass)); err != nil {
+		return err
+	}
+	c.caps = nil // servers may advertise more after auth
+	return nil
+}
+
+// selectMailbox opens a mailbox read-write and returns its UIDVALIDITY.
+func (c *imapClient) selectMailbox(name string) (uint32, error) {
+	untagged, err := c.cmd("SELECT %s", quoteIMAP(name))
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range untagged {
+		if m := uidValidityRe.FindStringSubmatch(r.text); m != nil {
+			v, _ := strconv.ParseUint(m[1], 10, 32)
+			return uint32(v), nil
+		}
+	}
+	return 0, nil
+}
+
+var uidValidityRe = regexp.MustCompile(`\[UIDVALIDITY (\d+)\]`)
+
+// searchUnseen returns UIDs of unseen messages received on or after since.
+func (c *imapClient) searchUnseen(since time.Time) ([]uint32, error) {
+	untagged, err := c.cmd("UID SEARCH UNSEEN SINCE %s", since.Format("2-Jan-2006"))
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, r := range untagged {
+		rest, ok := strings.CutPrefix(r.text, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, f := range strings.Fields(rest) {
+			if v, err := strconv.ParseUint(f, 10, 32); err == nil {
+				uids = append(uids, uint32(v))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// fetchRaw returns the full RFC 5322 source of a message without setting
+// \Seen.
+func (c *imapClient) fetchRaw(uid uint32) ([]byte, error) {
+	untagged, err := c.cmd("UID FETCH %d (UID BODY.PEEK[])", uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range untagged {
+		if strings.Contains(r.text, "FETCH") && len(r.literals) > 0 {
+			if r.literals[0] == nil {
+				return nil, errTooLarge
+			}
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("imap fetch uid %d: message not returned", uid)
+}
+
+func (c *imapClient) markSeen(uid uint32) error {
+	_, err := c.cmd(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid)
+	return err
+}
+
+// idle waits in IDLE until the server reports a mailbox change, timeout
+// elapses (errIdleTimeout) or ctx is cancelled.
+func (c *imapClient) idle(ctx context.Context, timeout time.Duration) error {
+	c.tag++
+	tag := fmt.Sprintf("A%04d", c.tag)
+	c.conn.SetDeadline(time.Now().Add(time.Minute))
+	if _, err := fmt.Fprintf(c.conn, "%s IDLE\r\n", tag); err != nil {
+		return fmt.Errorf("imap write: %w", err)
+	}
+	resp, err := c.readResponse()
+	if err != nil {
+		return fmt.Errorf("imap IDLE: %w", err)
+	}
+	if !strings.HasPrefix(resp.text, "+") {
+		return fmt.Errorf("imap IDLE: %s", resp.text)
+	}
+
+	// Unblock the read on cancellation; the caller reconnects afterwards.
+	stop := context.AfterFunc(ctx, func() { c.conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	changed := false
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	for !changed {
+		resp, err := c.readResponse()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				break
+			}
+			return fmt.Errorf("imap IDLE: %w", err)
+		}
+		if strings.HasSuffix(resp.text, " EXISTS") || strings.HasSuffix(resp.text, " RECENT") {
+			changed = true
+		}
+	}
+
+	c.conn.SetDeadline(time.Now().Add(time.Minute))
+	if _, err := io.WriteString(c.conn, "DONE\r\n"); err != nil {
+		return fmt.Errorf("imap write: %w", err)
+	}
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return fmt.Errorf("imap IDLE: %w", err)
+		}
+		if rest, ok := strings.CutPrefix(resp.text, tag+" "); ok {
+			if !strings.HasPrefix(rest, "OK") {
+				return fmt.Errorf("imap IDLE: %s", rest)
+			}
+			break
+		}
+	}
+	if !changed {
+		return errIdleTimeout
+	}
+	return nil
+}
+
+func (c *imapClient) logout() {
+	c.cmd("LOGOUT")
+}
+
+// quoteIMAP renders s as an IMAP quoted string.
+func quoteIMAP(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,111 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeIMAPServer answers a scripted session on one end of a net.Pipe. For
+// each expected command (without tag) it writes the given untagged lines
+// followed by "<tag> OK".
+type fakeExchange struct {
+	cmd      string
+	untagged []string
+}
+
+func startFakeIMAP(t *testing.T, script []fakeExchange) net.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		r := bufio.NewReader(server)
+		fmt.Fprint(server, "* OK ready\r\n")
+		for _, ex := range script {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			tag, cmd, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+			if cmd != ex.cmd {
+				t.Errorf("command = %q, want %q", cmd, ex.cmd)
+				fmt.Fprintf(server, "%s BAD unexpected\r\n", tag)
+				return
+			}
+			for _, u := range ex.untagged {
+				fmt.Fprint(server, u)
+			}
+			if cmd == "IDLE" {
+				if done, _ := r.ReadString('\n'); done != "DONE\r\n" {
+					t.Errorf("expected DONE, got %q", done)
+				}
+			}
+			fmt.Fprintf(server, "%s OK done\r\n", tag)
+		}
+	}()
+	return client
+}
+
+func TestIMAPClient_Session(t *testing.T) {
+	msg := "Subject: hi\r\n\r\nbody\r\n"
+	conn := startFakeIMAP(t, []fakeExchange{
+		{`LOGIN "a@x.com" "p\"w"`, nil},
+		{`SELECT "INBOX"`, []string{"* 3 EXISTS\r\n", "* OK [UIDVALIDITY 42] UIDs valid\r\n"}},
+		{"CAPABILITY", []string{"* CAPABILITY IMAP4rev1 IDLE\r\n"}},
+		{"UID SEARCH UNSEEN SINCE 2-Jan-2024", []string{"* SEARCH 7 9\r\n"}},
+		{"UID FETCH 7 (UID BODY.PEEK[])", []string{fmt.Sprintf("* 1 FETCH (UID 7 BODY[] {%d}\r\n%s)\r\n", len(msg), msg)}},
+		{`UID STORE 7 +FLAGS.SILENT (\Seen)`, nil},
+		{"IDLE", []string{"+ idling\r\n", "* 4 EXISTS\r\n"}},
+	})
+	c := newIMAPClient(conn, 1<<20)
+	defer c.Close()
+	if err := c.greeting(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.login("a@x.com", `p"w`); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.selectMailbox("INBOX"); err != nil || v != 42 {
+		t.Fatalf("select = %d, %v", v, err)
+	}
+	if caps, err := c.capabilities(); err != nil || !caps["IDLE"] {
+		t.Fatalf("caps = %v, %v", caps, err)
+	}
+	uids, err := c.searchUnseen(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(uids) != 2 || uids[0] != 7 {
+		t.Fatalf("search = %v, %v", uids, err)
+	}
+	raw, err := c.fetchRaw(7)
+	if err != nil || string(raw) != msg {
+		t.Fatalf("fetch = %q, %v", raw, err)
+	}
+	if err := c.markSeen(7); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.idle(context.Background(), 5*time.Second); err != nil {
+		t.Fatalf("idle = %v, want change notification", err)
+	}
+}
+
+func TestIMAPClient_OversizedLiteralKeepsSync(t *testing.T) {
+	big := strings.Repeat("x", 64)
+	conn := startFakeIMAP(t, []fakeExchange{
+		{"UID FETCH 1 (UID BODY.PEEK[])", []string{fmt.Sprintf("* 1 FETCH (UID 1 BODY[] {%d}\r\n%s)\r\n", len(big), big)}},
+		{"NOOP", nil},
+	})
+	c := newIMAPClient(conn, 16)
+	defer c.Close()
+	if err := c.greeting(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.fetchRaw(1); err != errTooLarge {
+		t.Fatalf("fetch err = %v, want errTooLarge", err)
+	}
+	if _, err := c.cmd("NOOP"); err != nil {
+		t.Fatalf("connection out of sync after oversized literal: %v", err)
+	}
+}
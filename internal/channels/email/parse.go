@@ -0,0 +1,218 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// parsedEmail is the part of an inbound message the channel uses.
+type parsedEmail struct {
+	MessageID   string // without angle brackets
+	InReplyTo   string
+	References  []string
+	From        mail.Address
+	ReplyTo     string
+	Subject     string
+	Date        time.Time
+	Text        string // text/plain body, or the HTML body converted to text
+	Attachments []attachment
+	// Automated is set for auto-replies, bounces and list mail (RFC 3834),
+	// which must not be answered.
+	Automated bool
+	// AuthResults holds the raw Authentication-Results header values.
+	AuthResults []string
+}
+
+// attachment is a decoded non-body MIME part.
+type attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+var (
+	wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+	msgIDRe     = regexp.MustCompile(`<([^<>\s]+)>`)
+)
+
+// parseEmail decodes an RFC 5322 message. Attachments larger than
+// maxAttachment bytes are skipped.
+func parseEmail(raw []byte, maxAttachment int64) (*parsedEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	h := msg.Header
+	pe := &parsedEmail{
+		MessageID:  firstMessageID(h.Get("Message-ID")),
+		InReplyTo:  firstMessageID(h.Get("In-Reply-To")),
+		References: messageIDs(h.Get("References")),
+		Subject:    decodeHeader(h.Get("Subject")),
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	if from, err := parser.Parse(h.Get("From")); err == nil {
+		pe.From = *from
+		pe.From.Address = strings.ToLower(from.Address)
+	}
+	if rt, err := parser.Parse(h.Get("Reply-To")); err == nil {
+		pe.ReplyTo = strings.ToLower(rt.Address)
+	}
+	if d, err := h.Date(); err == nil {
+		pe.Date = d
+	}
+	pe.Automated = isAutomated(h)
+	pe.AuthResults = h["Authentication-Results"]
+
+	var text, htmlBody strings.Builder
+	walkPart(textproto.MIMEHeader(h), msg.Body, maxAttachment, pe, &text, &htmlBody, 0)
+	pe.Text = strings.TrimSpace(text.String())
+	if pe.Text == "" && htmlBody.Len() > 0 {
+		pe.Text = htmlToText(htmlBody.String())
+	}
+	return pe, nil
+}
+
+// maxMIMEDepth bounds multipart nesting.
+const maxMIMEDepth = 10
+
+// walkPart collects body text and attachments from one MIME entity.
+func walkPart(h textproto.MIMEHeader, body io.Reader, maxAttachment int64, pe *parsedEmail,
+	text, htmlBody *strings.Builder, depth int) {
+	if depth > maxMIMEDepth {
+		return
+	}
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil || mediaType == "" {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				return
+			}
+			walkPart(p.Header, p, maxAttachment, pe, text, htmlBody, depth+1)
+		}
+	}
+
+	body = decodeTransfer(h.Get("Content-Transfer-Encoding"), body)
+	filename := decodeHeader(dparams["filename"])
+	if filename == "" {
+		filename = decodeHeader(params["name"])
+	}
+
+	isBody := disposition != "attachment" && filename == ""
+	if isBody && (mediaType == "text/plain" || mediaType == "text/html") {
+		r, err := charset.NewReaderLabel(params["charset"], body)
+		if err != nil {
+			r = body
+		}
+		data, _ := io.ReadAll(io.LimitReader(r, maxBodyBytes))
+		target := text
+		if mediaType == "text/html" {
+			target = htmlBody
+		}
+		if target.Len() > 0 {
+			target.WriteString("\n\n")
+		}
+		target.Write(data)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxAttachment+1))
+	if err != nil || int64(len(data)) > maxAttachment || len(data) == 0 {
+		return
+	}
+	if filename == "" {
+		filename = "attachment"
+		if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			filename += exts[0]
+		}
+	}
+	pe.Attachments = append(pe.Attachments, attachment{Filename: filename, ContentType: mediaType, Data: data})
+}
+
+// maxBodyBytes caps one text part.
+const maxBodyBytes = 1 << 20
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	// quoted-printable is decoded by multipart.Reader already; top-level
+	// single-part bodies are handled here.
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
+
+// decodeHeader decodes RFC 2047 encoded-words, returning s unchanged on error.
+func decodeHeader(s string) string {
+	if s == "" {
+		return ""
+	}
+	dec, err := wordDecoder.DecodeHeader(s)
+	if err != nil {
+		return s
+	}
+	return dec
+}
+
+func firstMessageID(s string) string {
+	if ids := messageIDs(s); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+func messageIDs(s string) []string {
+	var ids []string
+	for _, m := range msgIDRe.FindAllStringSubmatch(s, -1) {
+		ids = append(ids, m[1])
+	}
+	return ids
+}
+
+// isAutomated reports auto-generated mail per RFC 3834 and common bulk headers.
+func isAutomated(h mail.Header) bool {
+	if v := strings.ToLower(h.Get("Auto-Submitted")); v != "" && v != "no" {
+		return true
+	}
+	switch strings.ToLower(h.Get("Precedence")) {
+	case "bulk", "junk", "list", "auto_reply":
+		return true
+	}
+	if h.Get("X-Autoreply") != "" || h.Get("X-Autorespond") != "" || h.Get("List-Id") != "" {
+		return true
+	}
+	from := strings.ToLower(h.Get("From"))
+	return strings.Contains(from, "mailer-daemon@") || strings.Contains(from, "postmaster@")
+}
+
+// threadRoot returns the Message-ID identifying the conversation: the first
+// References entry, else In-Reply-To, else the message itself.
+func (pe *parsedEmail) threadRoot() string {
+	switch {
+	case len(pe.References) > 0:
+		return pe.References[0]
+	case pe.InReplyTo != "":
+		return pe.InReplyTo
+	}
+	return pe.MessageID
+}
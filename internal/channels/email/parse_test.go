@@ -0,0 +1,148 @@
+package email
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+)
+
+const replyWithAttachment = "Authentication-Results: mx.example.net; dkim=pass header.d=example.com; dmarc=pass header.from=example.com\r\n" +
+	"From: =?utf-8?q?J=C3=BCrgen?= <Jurgen@Example.COM>\r\n" +
+	"To: agent@example.com\r\n" +
+	"Subject: Re: Invoice\r\n" +
+	"Message-ID: <m3@example.com>\r\n" +
+	"In-Reply-To: <m2@example.com>\r\n" +
+	"References: <m1@example.com> <m2@example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=XX\r\n" +
+	"\r\n" +
+	"--XX\r\n" +
+	"Content-Type: multipart/alternative; boundary=YY\r\n" +
+	"\r\n" +
+	"--YY\r\n" +
+	"Content-Type: text/html; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<p>Gr=FC=DFe, see <a href=3D\"https://x.test/i\">invoice</a></p><script>x()</script>\r\n" +
+	"--YY--\r\n" +
+	"--XX\r\n" +
+	"Content-Type: application/pdf; name=\"inv.pdf\"\r\n" +
+	"Content-Disposition: attachment; filename=\"inv.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0x\r\nLjQK\r\n" +
+	"--XX--\r\n"
+
+func TestParseEmail_MultipartReply(t *testing.T) {
+	pe, err := parseEmail([]byte(replyWithAttachment), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pe.From.Address != "jurgen@example.com" || pe.From.Name != "Jürgen" {
+		t.Errorf("from = %+v", pe.From)
+	}
+	if pe.MessageID != "m3@example.com" || pe.InReplyTo != "m2@example.com" || pe.threadRoot() != "m1@example.com" {
+		t.Errorf("threading ids = %q %q %q", pe.MessageID, pe.InReplyTo, pe.threadRoot())
+	}
+	if pe.Text != "Grüße, see invoice (https://x.test/i)" {
+		t.Errorf("text = %q", pe.Text)
+	}
+	if len(pe.Attachments) != 1 || pe.Attachments[0].Filename != "inv.pdf" || string(pe.Attachments[0].Data) != "%PDF-1.4\n" {
+		t.Errorf("attachments = %+v", pe.Attachments)
+	}
+
+	if pe, _ := parseEmail([]byte(replyWithAttachment), 4); len(pe.Attachments) != 0 {
+		t.Error("attachment over the size cap should be skipped")
+	}
+}
+
+func TestParseEmail_Automated(t *testing.T) {
+	for _, hdr := range []string{"Auto-Submitted: auto-replied", "Precedence: bulk", "List-Id: <l.example.com>"} {
+		raw := "From: a@example.com\r\n" + hdr + "\r\nSubject: x\r\n\r\nbody\r\n"
+		pe, err := parseEmail([]byte(raw), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pe.Automated {
+			t.Errorf("%s: not flagged as automated", hdr)
+		}
+	}
+}
+
+func TestStripQuotedReply(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Thanks!\n\nOn Mon, 1 Jan 2024 at 10:00, Bot <b@x.com>\nwrote:\n> earlier", "Thanks!"},
+		{"Sure\n> quoted\nmore", "Sure\nmore"},
+		{"Yes\n-- \nJane Doe\nCEO", "Yes"},
+		{"Ok\n\n-----Original Message-----\nFrom: x", "Ok"},
+		{"> only a quote", "> only a quote"},
+	}
+	for _, tt := range tests {
+		if got := stripQuotedReply(tt.in); got != tt.want {
+			t.Errorf("stripQuotedReply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func newTestChannel(t *testing.T, cfg emailInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	cfg.Address = "Agent@example.com"
+	cfg.IMAP.Host, cfg.SMTP.Host = "imap.test", "smtp.test"
+	if !cfg.TrustedRelay {
+		cfg.TrustedAuthServIDs = []string{"mx.example.net"}
+	}
+	mb := bus.New()
+	ch, err := New(cfg, emailCreds{Password: "p"}, mb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, mb
+}
+
+func TestHandleEmail_PublishesThreadedInbound(t *testing.T) {
+	ch, mb := newTestChannel(t, emailInstanceConfig{DMPolicy: "allowlist", AllowFrom: []string{"JURGEN@example.com"}})
+	pe, err := parseEmail([]byte(replyWithAttachment), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ch.handleEmail(context.Background(), pe) {
+		t.Fatal("allowed sender not handled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("no inbound message")
+	}
+	t.Cleanup(func() {
+		for _, m := range msg.Media {
+			os.Remove(m.Path)
+		}
+	})
+	if msg.ChatID != "jurgen@example.com" || msg.PeerKind != "direct" {
+		t.Errorf("chat = %q peer = %q", msg.ChatID, msg.PeerKind)
+	}
+	if want := threadLocalKey("jurgen@example.com", "m1@example.com"); msg.Metadata["local_key"] != want {
+		t.Errorf("local_key = %q, want %q", msg.Metadata["local_key"], want)
+	}
+	if msg.Metadata[metaReferences] != "m1@example.com m2@example.com m3@example.com" || msg.Metadata[metaInReplyTo] != "m3@example.com" {
+		t.Errorf("threading metadata = %v", msg.Metadata)
+	}
+	if len(msg.Media) != 1 || msg.Media[0].MimeType != "application/pdf" || !strings.Contains(msg.Content, `<media:document name="inv.pdf">`) {
+		t.Errorf("media = %+v content = %q", msg.Media, msg.Content)
+	}
+
+	pe.From.Address = "other@example.com"
+	if ch.handleEmail(context.Background(), pe) {
+		t.Error("sender outside allowlist should be skipped")
+	}
+	pe.From.Address = "agent@example.com"
+	if ch.handleEmail(context.Background(), pe) {
+		t.Error("own address should be skipped")
+	}
+}
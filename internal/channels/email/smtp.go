@@ -0,0 +1,259 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// outgoingMail is a composed reply before MIME encoding.
+type outgoingMail struct {
+	From        mail.Address
+	To          string
+	Subject     string
+	MessageID   string // without angle brackets
+	InReplyTo   string
+	References  []string
+	Text        string
+	Attachments []string // local file paths
+}
+
+// newMessageID returns a globally unique Message-ID for the sender's domain.
+func newMessageID(address string) string {
+	domain := "goclaw.local"
+	if _, d, ok := strings.Cut(address, "@"); ok && d != "" {
+		domain = d
+	}
+	return uuid.NewString() + "@" + domain
+}
+
+// replySubject prefixes subject with "Re:" unless it already is a reply.
+func replySubject(subject string) string {
+	s := strings.TrimSpace(subject)
+	if s == "" {
+		return ""
+	}
+	if len(s) >= 3 && strings.EqualFold(s[:3], "re:") {
+		return s
+	}
+	return "Re: " + s
+}
+
+// compose encodes m as an RFC 5322 message: multipart/alternative text+HTML,
+// wrapped in multipart/mixed when there are attachments.
+func compose(m outgoingMail) ([]byte, error) {
+	var buf bytes.Buffer
+	h := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+
+	h("From", m.From.String())
+	h("To", (&mail.Address{Address: m.To}).String())
+	h("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	h("Date", time.Now().Format(time.RFC1123Z))
+	h("Message-ID", "<"+m.MessageID+">")
+	if m.InReplyTo != "" {
+		h("In-Reply-To", "<"+m.InReplyTo+">")
+	}
+	if len(m.References) > 0 {
+		refs := make([]string, len(m.References))
+		for i, r := range m.References {
+			refs[i] = "<" + r + ">"
+		}
+		h("References", strings.Join(refs, "\r\n "))
+	}
+	h("MIME-Version", "1.0")
+	// RFC 3834: lets other auto-responders avoid mail loops with the agent.
+	h("Auto-Submitted", "auto-replied")
+
+	alt, altType, err := alternativePart(m.Text)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Attachments) == 0 {
+		h("Content-Type", altType)
+		buf.WriteString("\r\n")
+		buf.Write(alt)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	h("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+	pw, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {altType}})
+	if err != nil {
+		return nil, err
+	}
+	pw.Write(alt)
+	for _, path := range m.Attachments {
+		if err := writeAttachment(mixed, path); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// alternativePart returns a multipart/alternative body (plain text and HTML)
+// and its Content-Type header value.
+func alternativePart(text string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, p := range []struct{ ctype, body string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", htmlEmailBody(text)},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.ctype},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(p.body, "\n", "\r\n"))); err != nil {
+			return nil, "", err
+		}
+		qp.Close()
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "multipart/alternative; boundary=" + w.Boundary(), nil
+}
+
+// htmlEmailBody wraps the rendered Markdown in a styled container.
+func htmlEmailBody(text string) string {
+	return `<div style="font-family:sans-serif;font-size:14px;line-height:1.5">` +
+		channels.MarkdownToHTML(text) + `</div>`
+}
+
+func writeAttachment(w *multipart.Writer, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read attachment: %w", err)
+	}
+	name := filepath.Base(path)
+	pw, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {media.DetectMIMEType(name)},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": name})},
+	})
+	if err != nil {
+		return err
+	}
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		fmt.Fprintf(pw, "%s\r\n", enc[:76])
+		enc = enc[76:]
+	}
+	_, err = fmt.Fprintf(pw, "%s\r\n", enc)
+	return err
+}
+
+// errInvalidRecipient rejects a reply address that cannot be parsed.
+var errInvalidRecipient = errors.New("invalid recipient")
+
+// ClassifySendError implements channels.SendErrorClassifier from SMTP reply
+// codes. 421 (service unavailable) and rejected credentials affect every
+// recipient, so they pause the channel; other 5xx replies (unknown mailbox,
+// policy rejection) are permanent, and 4xx replies are retried for that
+// recipient only.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	if errors.Is(err, errInvalidRecipient) {
+		return channels.SendFailure{Permanent: true}
+	}
+	var te *textproto.Error
+	if errors.As(err, &te) {
+		switch {
+		case te.Code == 421, te.Code == 530, te.Code == 534, te.Code == 535:
+			return channels.SendFailure{ChannelWide: true}
+		case te.Code >= 500:
+			return channels.SendFailure{Permanent: true}
+		case te.Code >= 400:
+			return channels.SendFailure{}
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// sendMail delivers msg over SMTP. Authentication uses PLAIN when the server
+// offers AUTH and a password is set.
+func sendMail(ctx context.Context, sc serverConfig, user, pass, from string, to []string, msg []byte) error {
+	addr := net.JoinHostPort(sc.Host, fmt.Sprint(sc.Port))
+	tlsCfg := &tls.Config{ServerName: sc.Host, InsecureSkipVerify: sc.InsecureSkipVerify}
+	d := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if sc.Security == securityTLS {
+		conn, err = (&tls.Dialer{NetDialer: d, Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(2 * time.Minute))
+	}
+
+	c, err := smtp.NewClient(conn, sc.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if sc.Security == securityStartTLS {
+		if err := c.StartTLS(tlsCfg); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); ok && pass != "" {
+		if err := c.Auth(smtp.PlainAuth("", user, pass, sc.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO: %w", err)
+		}
+	}
+	wc, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := wc.Write(msg); err != nil {
+		wc.Close()
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	return c.Quit()
+}
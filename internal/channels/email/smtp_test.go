@@ -0,0 +1,81 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompose_RoundTrip(t *testing.T) {
+	att := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(att, []byte("a,b\n1,2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := compose(outgoingMail{
+		From:        mail.Address{Name: "Support Bot", Address: "agent@example.com"},
+		To:          "jurgen@example.com",
+		Subject:     replySubject("Rechnung für März"),
+		MessageID:   "out1@example.com",
+		InReplyTo:   "m3@example.com",
+		References:  []string{"m1@example.com", "m3@example.com"},
+		Text:        "**Done** — see attached.",
+		Attachments: []string{att},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "Auto-Submitted: auto-replied\r\n") {
+		t.Error("missing Auto-Submitted header")
+	}
+
+	pe, err := parseEmail(raw, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pe.Subject != "Re: Rechnung für März" || pe.MessageID != "out1@example.com" || pe.InReplyTo != "m3@example.com" {
+		t.Errorf("headers = %q %q %q", pe.Subject, pe.MessageID, pe.InReplyTo)
+	}
+	if len(pe.References) != 2 || pe.threadRoot() != "m1@example.com" {
+		t.Errorf("references = %v", pe.References)
+	}
+	if pe.Text != "**Done** — see attached." {
+		t.Errorf("text = %q", pe.Text)
+	}
+	if len(pe.Attachments) != 1 || pe.Attachments[0].Filename != "report.csv" || string(pe.Attachments[0].Data) != "a,b\n1,2\n" {
+		t.Errorf("attachments = %+v", pe.Attachments)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	ch, _ := newTestChannel(t, emailInstanceConfig{FromName: "Ava", Signature: "{{.FromName}} · {{.Address}}"})
+	if got := ch.signature("Re: x"); got != "\n\n-- \nAva · Agent@example.com" {
+		t.Errorf("signature = %q", got)
+	}
+	if replySubject("RE: x") != "RE: x" || replySubject("x") != "Re: x" {
+		t.Error("replySubject should add Re: once")
+	}
+}
+
+func TestClassifySendError(t *testing.T) {
+	c := &Channel{}
+	tests := []struct {
+		err                    error
+		permanent, channelWide bool
+	}{
+		{fmt.Errorf("email: %w %q: %v", errInvalidRecipient, "x", errors.New("no angle-addr")), true, false},
+		{fmt.Errorf("email: smtp RCPT TO: %w", &textproto.Error{Code: 550, Msg: "no such user"}), true, false},
+		{fmt.Errorf("email: smtp auth: %w", &textproto.Error{Code: 535, Msg: "bad credentials"}), false, true},
+		{fmt.Errorf("email: smtp MAIL FROM: %w", &textproto.Error{Code: 421, Msg: "try later"}), false, true},
+		{fmt.Errorf("email: smtp RCPT TO: %w", &textproto.Error{Code: 452, Msg: "mailbox full"}), false, false},
+	}
+	for _, tt := range tests {
+		if f := c.ClassifySendError(tt.err); f.Permanent != tt.permanent || f.ChannelWide != tt.channelWide {
+			t.Errorf("ClassifySendError(%v) = %+v; want permanent=%v channelWide=%v", tt.err, f, tt.permanent, tt.channelWide)
+		}
+	}
+}
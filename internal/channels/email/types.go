@@ -0,0 +1,72 @@
+// Package email implements the email channel: inbound mail is read from one
+// or more IMAP mailboxes (IDLE push, polling fallback) and replies are sent
+// over SMTP.
+//
+// Conversations are threaded by Message-ID/In-Reply-To/References: the
+// thread root becomes a per-thread session under the sender's address, and
+// replies carry In-Reply-To/References so mail clients keep them threaded.
+// Only DM policies apply — every email is a direct conversation.
+package email
+
+// TLS modes for IMAP and SMTP connections.
+const (
+	securityTLS      = "tls"      // implicit TLS (IMAPS 993, SMTPS 465)
+	securityStartTLS = "starttls" // plain connect, then STARTTLS (IMAP 143, submission 587)
+	securityNone     = "none"     // plaintext (local relays and tests only)
+)
+
+// emailCreds holds encrypted credentials stored in channel_instances.credentials.
+type emailCreds struct {
+	Password     string `json:"password"`                // IMAP password (also SMTP unless smtp_password is set)
+	SMTPPassword string `json:"smtp_password,omitempty"` // separate SMTP password, if any
+}
+
+// serverConfig addresses an IMAP or SMTP server.
+type serverConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"` // default: the channel address
+	Security string `json:"security,omitempty"` // tls (default), starttls, none
+	// InsecureSkipVerify disables certificate verification (self-signed test servers).
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// emailInstanceConfig holds non-secret config from channel_instances.config JSONB.
+type emailInstanceConfig struct {
+	Address  string       `json:"address"`             // mailbox address replies are sent from
+	FromName string       `json:"from_name,omitempty"` // display name on outgoing mail
+	IMAP     serverConfig `json:"imap"`
+	SMTP     serverConfig `json:"smtp"`
+	// Mailboxes lists the IMAP folders watched for new mail (default INBOX).
+	Mailboxes []string `json:"mailboxes,omitempty"`
+	// PollInterval (seconds) is used when the server lacks IDLE (default 60).
+	PollInterval int `json:"poll_interval,omitempty"`
+	// BackfillDays limits unread mail picked up on (re)connect to messages
+	// received within this many days (default 1).
+	BackfillDays int `json:"backfill_days,omitempty"`
+	// Signature is a text/template appended to replies. Fields: .FromName,
+	// .Address, .Subject, .Date.
+	Signature string `json:"signature,omitempty"`
+	// Subject is used for messages that do not reply to an email (default
+	// "Message from <from_name>").
+	Subject    string   `json:"subject,omitempty"`
+	DMPolicy   string   `json:"dm_policy,omitempty"`
+	AllowFrom  []string `json:"allow_from,omitempty"` // sender addresses
+	MediaMaxMB int      `json:"media_max_mb,omitempty"`
+	BlockReply *bool    `json:"block_reply,omitempty"` // default false: one reply email per run
+	// TrustedAuthServIDs lists the authserv-ids of the receiving MTAs whose
+	// Authentication-Results headers are trusted. Mail without a DMARC or
+	// aligned DKIM pass from one of them is dropped before policy checks.
+	TrustedAuthServIDs []string `json:"trusted_authserv_ids,omitempty"`
+	// TrustedRelay skips sender authentication; set it only when the mailbox
+	// is fed by a relay that already rejects unauthenticated mail.
+	TrustedRelay bool `json:"trusted_relay,omitempty"`
+}
+
+// Metadata keys carried from inbound to outbound (see channels.routingMetaKeys).
+const (
+	metaInReplyTo  = "email_in_reply_to"
+	metaReferences = "email_references"
+	metaSubject    = "email_subject"
+	metaReplyTo    = "email_reply_to"
+)
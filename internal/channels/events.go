@@ -105,7 +105,7 @@ func (m *Manager) HandleAgentEvent(eventType, runID string, payload any) {
 			// Streaming channels show tool status via reaction emoji instead —
 			// editing the placeholder would overwrite streamed content.
 			toolName := extractPayloadString(payload, "name")
-			if toolName != "" && rc.ToolStatusEnabled && !rc.Streaming {
+			if toolName != "" && rc.ToolStatusEnabled && !rc.Streaming && !skipsPlaceholderUpdates(ch) {
 				statusText := formatToolStatus(toolName)
 				outMeta := copyRoutingMeta(rc.Metadata)
 				outMeta["placeholder_update"] = "true"
@@ -293,7 +293,7 @@ func (m *Manager) HandleAgentEvent(eventType, runID string, payload any) {
 	}
 
 	// Handle LLM retry: update placeholder to notify user
-	if eventType == protocol.AgentEventRunRetrying {
+	if eventType == protocol.AgentEventRunRetrying && !skipsPlaceholderUpdates(ch) {
 		attempt := extractPayloadString(payload, "attempt")
 		maxAttempts := extractPayloadString(payload, "maxAttempts")
 		retryMsg := fmt.Sprintf("Provider busy, retrying... (%s/%s)", attempt, maxAttempts)
@@ -447,3 +447,10 @@ func resolveToolReactionStatus(toolName string) string {
 		return "tool"
 	}
 }
+
+// skipsPlaceholderUpdates reports whether ch drops placeholder edits
+// (see PlaceholderUpdateSkipper).
+func skipsPlaceholderUpdates(ch Channel) bool {
+	s, ok := ch.(PlaceholderUpdateSkipper)
+	return ok && s.SkipPlaceholderUpdates()
+}
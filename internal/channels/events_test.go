@@ -0,0 +1,45 @@
+package channels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// noEditChannel cannot edit sent messages.
+type noEditChannel struct{ flakyChannel }
+
+func (c *noEditChannel) SkipPlaceholderUpdates() bool { return true }
+
+func TestHandleAgentEvent_RetryNoticeSkipped(t *testing.T) {
+	m := NewManager(bus.New())
+	m.RegisterChannel("edits", &flakyChannel{BaseChannel: NewBaseChannel("edits", nil, nil)})
+	m.RegisterChannel("no-edits", &noEditChannel{flakyChannel{BaseChannel: NewBaseChannel("no-edits", nil, nil)}})
+	retry := map[string]any{"attempt": "1", "maxAttempts": "3"}
+
+	for _, name := range []string{"no-edits", "edits"} {
+		m.RegisterRun("run-"+name, name, "c1", "", nil, uuid.Nil, false, false, true)
+		m.HandleAgentEvent(protocol.AgentEventRunRetrying, "run-"+name, retry)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var got []string
+	for {
+		msg, ok := m.bus.SubscribeOutbound(ctx)
+		if !ok {
+			break
+		}
+		if msg.Metadata["placeholder_update"] != "true" {
+			t.Errorf("unexpected outbound %+v", msg)
+		}
+		got = append(got, msg.Channel)
+	}
+	if len(got) != 1 || got[0] != "edits" {
+		t.Errorf("retry notices published for %v; want only the editing channel", got)
+	}
+}
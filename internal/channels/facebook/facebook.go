@@ -256,6 +256,25 @@ func (ch *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	return nil
 }
 
+// ClassifySendError implements channels.SendErrorClassifier.
+func (ch *Channel) ClassifySendError(err error) channels.SendFailure {
+	return ClassifySendError(err)
+}
+
+// ClassifySendError maps Graph API send failures to the outbox retry policy
+// for the Meta channels. Rate limits and rejected tokens pause the whole
+// channel; invalid requests (100), permission denials (10/200) and closed
+// messaging windows (551) fail the same way on every retry.
+func ClassifySendError(err error) channels.SendFailure {
+	switch code := ErrorCode(err); {
+	case IsRateLimitError(err), IsAuthError(err):
+		return channels.SendFailure{ChannelWide: true}
+	case IsPermissionError(err), code == 100, code == 551:
+		return channels.SendFailure{Permanent: true}
+	}
+	return channels.ClassifySendError(err)
+}
+
 // WebhookHandler returns the shared webhook path and the global router as handler.
 // Only the first facebook instance mounts the route; others return ("", nil).
 func (ch *Channel) WebhookHandler() (string, http.Handler) {
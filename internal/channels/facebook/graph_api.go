@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"regexp"
 	"strconv"
 	"time"
@@ -23,7 +25,22 @@ const (
 
 // graphAPIBase is the Graph API root. Declared as a variable so tests can
 // override it with an httptest.NewServer URL.
-var graphAPIBase = "https://graph.facebook.com"
+var graphAPIBase = defaultGraphAPIBase
+
+const defaultGraphAPIBase = "https://graph.facebook.com"
+
+// SetGraphAPIBaseForTest points all GraphClients at a test server and disables
+// retry backoff. An empty base restores the production defaults.
+// Only for use in tests of packages built on GraphClient.
+func SetGraphAPIBaseForTest(base string) {
+	if base == "" {
+		graphAPIBase = defaultGraphAPIBase
+		graphBackoffBase = 1 * time.Second
+		return
+	}
+	graphAPIBase = base
+	graphBackoffBase = time.Millisecond
+}
 
 // fbIDPattern validates Facebook object IDs: numeric or "{num}_{num}" form (post IDs).
 var fbIDPattern = regexp.MustCompile(`^\d+(_\d+)?$`)
@@ -168,6 +185,26 @@ func (g *GraphClient) SendMessage(ctx context.Context, recipientID, message stri
 	return result.MessageID, nil
 }
 
+// SendPrivateReply sends a private message to the author of a comment (page or
+// Instagram comment). Meta allows one private reply per comment, within 7 days.
+func (g *GraphClient) SendPrivateReply(ctx context.Context, commentID, message string) (string, error) {
+	body := map[string]any{
+		"recipient": map[string]string{"comment_id": commentID},
+		"message":   map[string]string{"text": message},
+	}
+	data, err := g.doRequest(ctx, http.MethodPost, "/me/messages", body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("facebook: parse private reply result: %w", err)
+	}
+	return result.MessageID, nil
+}
+
 // SendTypingOn sends a typing indicator to the recipient (auto-off after 3s).
 func (g *GraphClient) SendTypingOn(ctx context.Context, recipientID string) error {
 	body := map[string]any{
@@ -178,6 +215,106 @@ func (g *GraphClient) SendTypingOn(ctx context.Context, recipientID string) erro
 	return err
 }
 
+// Do executes an arbitrary Graph API call (path relative to the versioned root)
+// with the client's token, retries and error mapping. Used by channels built on
+// other Meta products (e.g. WhatsApp Cloud API) that share this client.
+func (g *GraphClient) Do(ctx context.Context, method, path string, body any) ([]byte, error) {
+	return g.doRequest(ctx, method, path, body)
+}
+
+// Upload posts a multipart form with a single file part to path. Used for
+// media uploads, which the JSON request path cannot carry. Not retried.
+func (g *GraphClient) Upload(ctx context.Context, path string, fields map[string]string,
+	fileField, filename, contentType string, data io.Reader) ([]byte, error) {
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return nil, fmt.Errorf("facebook: build upload: %w", err)
+		}
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, fileField, filename))
+	h.Set("Content-Type", contentType)
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		return nil, fmt.Errorf("facebook: build upload: %w", err)
+	}
+	if _, err := io.Copy(part, data); err != nil {
+		return nil, fmt.Errorf("facebook: build upload: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("facebook: build upload: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/%s%s", graphAPIBase, graphAPIVersion, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("facebook: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+g.pageAccessToken)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := g.uploadClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("facebook: upload request: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("facebook: read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, parseGraphError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+// Download fetches a media URL returned by the Graph API (e.g. a WhatsApp
+// media object's url), which requires the same bearer token. Bodies larger
+// than maxBytes are rejected.
+func (g *GraphClient) Download(ctx context.Context, mediaURL string, maxBytes int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("facebook: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+g.pageAccessToken)
+
+	resp, err := g.uploadClient().Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("facebook: download media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, "", parseGraphError(resp.StatusCode, body)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("facebook: read media: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("facebook: media exceeds %d bytes", maxBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// uploadClient returns a client with a timeout suited to media transfers.
+func (g *GraphClient) uploadClient() *http.Client {
+	return &http.Client{Timeout: 2 * time.Minute, Transport: g.httpClient.Transport}
+}
+
+// parseGraphError maps a failed response to a graphAPIError when the body is
+// a Graph error envelope.
+func parseGraphError(status int, body []byte) error {
+	var apiErr graphErrorBody
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Code != 0 {
+		return &graphAPIError{code: apiErr.Error.Code, msg: apiErr.Error.Message}
+	}
+	return fmt.Errorf("facebook: http %d", status)
+}
+
 // graphBackoffBase is the base unit for exponential retry backoff in doRequest.
 // Production default = 1s, giving 1s, 2s, 4s... per attempt.
 // Tests override to 1ms via newFakeGraph so retry tests don't burn 6s of real
@@ -335,6 +472,15 @@ func (e *graphAPIError) Error() string {
 	return fmt.Sprintf("facebook graph api error %d: %s", e.code, e.msg)
 }
 
+// ErrorCode returns the Graph API error code of err, or 0 if err is not a Graph API error.
+func ErrorCode(err error) int {
+	var ge *graphAPIError
+	if !errors.As(err, &ge) {
+		return 0
+	}
+	return ge.code
+}
+
 // IsAuthError returns true when the error is an expired or invalid token.
 func IsAuthError(err error) bool {
 	var ge *graphAPIError
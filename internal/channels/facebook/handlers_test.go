@@ -1,6 +1,7 @@
 package facebook
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -606,6 +607,46 @@ func TestWebhookRouter_ServeHTTPNoInstances(t *testing.T) {
 	}
 }
 
+// fakeSubscriber records entries routed to it.
+type fakeSubscriber struct {
+	secret, token string
+	entries       []RawEntry
+}
+
+func (f *fakeSubscriber) WebhookSecrets() (string, string) { return f.secret, f.token }
+func (f *fakeSubscriber) HandleWebhookEntry(e RawEntry)    { f.entries = append(f.entries, e) }
+
+// TestWebhookRouter_Subscribers verifies non-page objects reach the
+// subscriber registered for the entry ID only when signed with its own app
+// secret, and that subscriber verify tokens pass the GET handshake.
+func TestWebhookRouter_Subscribers(t *testing.T) {
+	mine := &fakeSubscriber{secret: "waba-secret", token: "waba-vt"}
+	other := &fakeSubscriber{secret: "other-secret", token: "other-vt"}
+	RegisterSubscriber("whatsapp_business_account", "77", mine)
+	RegisterSubscriber("whatsapp_business_account", "88", other)
+	t.Cleanup(func() {
+		UnregisterSubscriber("whatsapp_business_account", "77", mine)
+		UnregisterSubscriber("whatsapp_business_account", "88", other)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/webhook?hub.mode=subscribe&hub.verify_token=waba-vt&hub.challenge=abc", nil)
+	w := httptest.NewRecorder()
+	globalRouter.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "abc" {
+		t.Errorf("handshake = %d %q", w.Code, w.Body.String())
+	}
+
+	body := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"77","changes":[{"field":"messages","value":{"x":1}}]}]}`)
+	for _, secret := range []string{"other-secret", "waba-secret"} {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", signBody(t, body, secret))
+		globalRouter.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(mine.entries) != 1 || mine.entries[0].Changes[0].Field != "messages" || len(other.entries) != 0 {
+		t.Errorf("routed: mine=%+v other=%+v", mine.entries, other.entries)
+	}
+}
+
 // --- PostFetcher ---
 
 // TestNewPostFetcher_Defaults verifies empty TTL string defaults to
@@ -2,6 +2,8 @@
 // Supports: comment auto-reply, Messenger inbox auto-reply, first inbox DM.
 package facebook
 
+import "encoding/json"
+
 // facebookCreds holds encrypted credentials stored in channel_instances.credentials.
 type facebookCreds struct {
 	PageAccessToken string `json:"page_access_token"`
@@ -47,6 +49,22 @@ type WebhookEntry struct {
 	Messaging []MessagingEvent `json:"messaging,omitempty"` // Messenger events
 }
 
+// RawEntry is a webhook entry for a non-page object (e.g. "whatsapp_business_account",
+// "instagram"). Change values and messaging events are left undecoded for the
+// subscriber to parse.
+type RawEntry struct {
+	ID        string            `json:"id"`
+	Time      int64             `json:"time,omitempty"`
+	Changes   []RawChange       `json:"changes,omitempty"`
+	Messaging []json.RawMessage `json:"messaging,omitempty"`
+}
+
+// RawChange is a single change event of a RawEntry.
+type RawChange struct {
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"`
+}
+
 // WebhookChange is a single change event for feed subscriptions.
 type WebhookChange struct {
 	Field string      `json:"field"` // "feed", "mention", etc.
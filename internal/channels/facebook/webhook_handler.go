@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -18,8 +19,12 @@ type WebhookHandler struct {
 	appSecret    string
 	verifyToken  string
 	extraSecrets []string // additional app secrets for multi-Meta-App deployments
+	extraTokens  []string // additional verify tokens (subscribers of other objects)
 	onComment    func(entry WebhookEntry, change ChangeValue)
 	onMessage    func(entry WebhookEntry, event MessagingEvent)
+	// onObject receives entries of non-page objects together with the app
+	// secret that verified the delivery.
+	onObject func(object string, entry RawEntry, appSecret string)
 }
 
 // NewWebhookHandler creates a new WebhookHandler.
@@ -52,7 +57,7 @@ func (wh *WebhookHandler) handleVerification(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "invalid hub.mode", http.StatusForbidden)
 		return
 	}
-	if q.Get("hub.verify_token") != wh.verifyToken {
+	if !wh.validVerifyToken(q.Get("hub.verify_token")) {
 		slog.Warn("security.facebook_webhook_verify_token_mismatch",
 			"remote_addr", r.RemoteAddr)
 		http.Error(w, "invalid verify token", http.StatusForbidden)
@@ -68,6 +73,13 @@ func (wh *WebhookHandler) handleVerification(w http.ResponseWriter, r *http.Requ
 	_, _ = w.Write([]byte(challenge))
 }
 
+func (wh *WebhookHandler) validVerifyToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	return token == wh.verifyToken || slices.Contains(wh.extraTokens, token)
+}
+
 // handleEvent processes a Facebook webhook event delivery.
 // Always returns 200 OK — Facebook retries on non-2xx for 24h.
 func (wh *WebhookHandler) handleEvent(w http.ResponseWriter, r *http.Request) {
@@ -87,11 +99,13 @@ func (wh *WebhookHandler) handleEvent(w http.ResponseWriter, r *http.Request) {
 
 	sig := r.Header.Get("X-Hub-Signature-256")
 	verified := verifySignature(body, sig, wh.appSecret)
+	matchedSecret := wh.appSecret
 	if !verified {
 		// Try extra secrets (multi-Meta-App deployments share one webhook endpoint).
 		for _, s := range wh.extraSecrets {
 			if verifySignature(body, sig, s) {
 				verified = true
+				matchedSecret = s
 				break
 			}
 		}
@@ -102,14 +116,30 @@ func (wh *WebhookHandler) handleEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload WebhookPayload
-	if err := json.Unmarshal(body, &payload); err != nil {
+	var envelope struct {
+		Object string     `json:"object"`
+		Entry  []RawEntry `json:"entry"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
 		slog.Warn("facebook: webhook parse error", "err", err)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	if payload.Object != "page" {
+	if envelope.Object != "page" {
+		// Other Meta products (WhatsApp Business, Instagram) deliver to the same endpoint.
+		if wh.onObject != nil {
+			for _, entry := range envelope.Entry {
+				wh.onObject(envelope.Object, entry, matchedSecret)
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		slog.Warn("facebook: webhook parse error", "err", err)
 		w.WriteHeader(http.StatusOK)
 		return
 	}
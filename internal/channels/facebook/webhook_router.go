@@ -3,6 +3,7 @@ package facebook
 import (
 	"log/slog"
 	"net/http"
+	"slices"
 	"sync"
 )
 
@@ -14,12 +15,57 @@ import (
 // tries all known secrets and accepts the payload if any matches.
 type webhookRouter struct {
 	mu           sync.RWMutex
-	instances    map[string]*Channel // pageID → channel
-	routeHandled bool                // true after first webhookRoute() call
+	instances    map[string]*Channel            // pageID → channel
+	subscribers  map[string][]WebhookSubscriber // "object/entryID" → subscribers
+	routeHandled bool                           // true after first webhookRoute() call
 }
 
 var globalRouter = &webhookRouter{
-	instances: make(map[string]*Channel),
+	instances:   make(map[string]*Channel),
+	subscribers: make(map[string][]WebhookSubscriber),
+}
+
+// WebhookSubscriber receives webhook entries for another Meta object type
+// (e.g. "whatsapp_business_account", "instagram") delivered to the shared endpoint.
+// Meta apps subscribe one callback URL per app, so every product of the app
+// arrives here and is verified with the same app secret.
+type WebhookSubscriber interface {
+	// WebhookSecrets returns the app secret used to verify deliveries and
+	// the verify token accepted for the subscription handshake.
+	WebhookSecrets() (appSecret, verifyToken string)
+	// HandleWebhookEntry handles one entry. Must not block.
+	HandleWebhookEntry(entry RawEntry)
+}
+
+// RegisterSubscriber routes entries of object whose entry ID is entryID
+// (e.g. a WhatsApp Business Account ID) to s.
+func RegisterSubscriber(object, entryID string, s WebhookSubscriber) {
+	globalRouter.mu.Lock()
+	defer globalRouter.mu.Unlock()
+	if globalRouter.subscribers == nil {
+		globalRouter.subscribers = make(map[string][]WebhookSubscriber)
+	}
+	key := object + "/" + entryID
+	globalRouter.subscribers[key] = append(globalRouter.subscribers[key], s)
+}
+
+// UnregisterSubscriber removes a subscriber added by RegisterSubscriber.
+func UnregisterSubscriber(object, entryID string, s WebhookSubscriber) {
+	globalRouter.mu.Lock()
+	defer globalRouter.mu.Unlock()
+	key := object + "/" + entryID
+	subs := slices.DeleteFunc(globalRouter.subscribers[key], func(x WebhookSubscriber) bool { return x == s })
+	if len(subs) == 0 {
+		delete(globalRouter.subscribers, key)
+	} else {
+		globalRouter.subscribers[key] = subs
+	}
+}
+
+// WebhookRoute returns the shared webhook path and handler for the first
+// caller across the facebook channel and its subscribers; ("", nil) afterwards.
+func WebhookRoute() (string, http.Handler) {
+	return globalRouter.webhookRoute()
 }
 
 func (r *webhookRouter) register(ch *Channel) {
@@ -54,18 +100,30 @@ func (r *webhookRouter) webhookRoute() (string, http.Handler) {
 func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.mu.RLock()
 	var primarySecret, verifyToken string
-	var extraSecrets []string
+	var extraSecrets, extraTokens []string
 	seenSecrets := make(map[string]bool)
-	for _, ch := range r.instances {
-		s := ch.webhookH.appSecret
+	addSecrets := func(s, token string) {
 		if primarySecret == "" {
 			primarySecret = s
-			verifyToken = ch.webhookH.verifyToken
+			verifyToken = token
 			seenSecrets[s] = true
-		} else if !seenSecrets[s] {
+			return
+		}
+		if !seenSecrets[s] {
 			extraSecrets = append(extraSecrets, s)
 			seenSecrets[s] = true
 		}
+		if token != verifyToken && !slices.Contains(extraTokens, token) {
+			extraTokens = append(extraTokens, token)
+		}
+	}
+	for _, ch := range r.instances {
+		addSecrets(ch.webhookH.appSecret, ch.webhookH.verifyToken)
+	}
+	for _, subs := range r.subscribers {
+		for _, s := range subs {
+			addSecrets(s.WebhookSecrets())
+		}
 	}
 	r.mu.RUnlock()
 
@@ -85,6 +143,7 @@ func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		appSecret:    primarySecret,
 		verifyToken:  verifyToken,
 		extraSecrets: extraSecrets,
+		extraTokens:  extraTokens,
 	}
 	routingWH.onComment = func(entry WebhookEntry, change ChangeValue) {
 		r.mu.RLock()
@@ -102,5 +161,17 @@ func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			target.handleMessagingEvent(entry, event)
 		}
 	}
+	routingWH.onObject = func(object string, entry RawEntry, appSecret string) {
+		r.mu.RLock()
+		subs := slices.Clone(r.subscribers[object+"/"+entry.ID])
+		r.mu.RUnlock()
+		for _, s := range subs {
+			// Only the subscriber whose own app secret signed the delivery
+			// receives it, so one tenant's app cannot inject into another's account.
+			if secret, _ := s.WebhookSecrets(); secret == appSecret {
+				s.HandleWebhookEntry(entry)
+			}
+		}
+	}
 	routingWH.ServeHTTP(w, req)
 }
@@ -103,6 +103,9 @@ func (c *Channel) handleMessageEvent(ctx context.Context, event *MessageEvent) {
 		if c.maybeHandleWriterCommand(ctx, mc) {
 			return
 		}
+		if c.maybeHandleSharedCommand(ctx, mc) {
+			return
+		}
 
 		// 6. RequireMention check — record to history if not mentioned
 		requireMention := true
@@ -140,6 +143,9 @@ func (c *Channel) handleMessageEvent(ctx context.Context, event *MessageEvent) {
 		if !c.checkDMPolicy(ctx, mc.SenderID, mc.ChatID) {
 			return
 		}
+		if c.maybeHandleSharedCommand(ctx, mc) {
+			return
+		}
 	}
 
 	// 7. Build content (strip bot mention from text)
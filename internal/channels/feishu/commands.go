@@ -0,0 +1,137 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// maybeHandleSharedCommand runs the message through the shared chat command
+// registry (/reset, /tasks, tenant custom commands, ...). Must be called
+// after the DM/group policy gate. Writer commands are handled earlier by
+// maybeHandleWriterCommand, which resolves targets from Lark mentions.
+func (c *Channel) maybeHandleSharedCommand(ctx context.Context, mc *messageContext) bool {
+	if _, approval := channels.ApprovalReplyCommand(mc.Content); !approval && !strings.HasPrefix(strings.TrimSpace(mc.Content), "/") {
+		return false
+	}
+
+	// Topic sessions key commands to the topic like regular messages.
+	chatID := mc.ChatID
+	if mc.RootID != "" && c.cfg.TopicSessionMode == "enabled" {
+		chatID = fmt.Sprintf("%s:topic:%s", mc.ChatID, mc.RootID)
+	}
+	peerKind := "direct"
+	if mc.ChatType == "group" {
+		peerKind = "group"
+	}
+	metadata := map[string]string{}
+	if mc.ThreadID != "" {
+		metadata["feishu_reply_target_id"] = mc.MessageID
+	}
+
+	return c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID:    mc.SenderID,
+		ChatID:      chatID,
+		PeerKind:    peerKind,
+		Text:        mc.Content,
+		Metadata:    metadata,
+		WriterScope: fmt.Sprintf("group:%s:%s", c.Name(), mc.ChatID),
+	})
+}
+
+// cardActionEventType is the event Lark sends when a card button is clicked.
+const cardActionEventType = "card.action.trigger"
+
+// sendCommandCard delivers a command reply as a card, rendering its actions
+// as buttons. Each button carries the command and the originating chat so
+// handleCardAction can run it like a typed command.
+func (c *Channel) sendCommandCard(ctx context.Context, req *channels.CommandRequest, reply channels.CommandReply) error {
+	if len(reply.Actions) == 0 {
+		return c.Send(ctx, bus.OutboundMessage{Channel: c.Name(), ChatID: req.ChatID, Content: reply.Text, Metadata: req.Metadata})
+	}
+
+	// Topic session chat IDs ("oc_x:topic:om_y") address the parent chat.
+	chatID, _, _ := strings.Cut(req.ChatID, ":topic:")
+	elements := []map[string]any{{"tag": "markdown", "content": reply.Text}}
+	for _, a := range reply.Actions {
+		elements = append(elements, map[string]any{
+			"tag":  "button",
+			"text": map[string]any{"tag": "plain_text", "content": a.Label},
+			"behaviors": []map[string]any{{
+				"type": "callback",
+				"value": map[string]string{
+					"command":   a.Command,
+					"chat_id":   req.ChatID,
+					"peer_kind": req.PeerKind,
+				},
+			}},
+		})
+	}
+	card := map[string]any{
+		"schema": "2.0",
+		"config": map[string]any{"wide_screen_mode": true},
+		"body":   map[string]any{"elements": elements},
+	}
+	cardJSON, err := json.Marshal(card)
+	if err != nil {
+		return fmt.Errorf("marshal card: %w", err)
+	}
+	replyTargetID := req.Metadata["feishu_reply_target_id"]
+	if err := c.deliverMessage(ctx, chatID, resolveReceiveIDType(chatID), replyTargetID, "interactive", string(cardJSON)); err != nil {
+		return fmt.Errorf("feishu send card: %w", err)
+	}
+	return nil
+}
+
+// CardActionEvent is the parsed structure of a Feishu card.action.trigger event.
+type CardActionEvent struct {
+	Header struct {
+		EventType string `json:"event_type"`
+		Token     string `json:"token"`
+	} `json:"header"`
+	Event struct {
+		Operator struct {
+			OpenID string `json:"open_id"`
+		} `json:"operator"`
+		Action struct {
+			Value map[string]any `json:"value"`
+		} `json:"action"`
+		Context struct {
+			OpenMessageID string `json:"open_message_id"`
+			OpenChatID    string `json:"open_chat_id"`
+		} `json:"context"`
+	} `json:"event"`
+}
+
+// handleCardAction runs the command behind a clicked card button.
+func (c *Channel) handleCardAction(ctx context.Context, event *CardActionEvent) {
+	value := event.Event.Action.Value
+	command, _ := value["command"].(string)
+	if !strings.HasPrefix(command, "/") || event.Event.Operator.OpenID == "" {
+		return
+	}
+	chatID, _ := value["chat_id"].(string)
+	parentID, _, _ := strings.Cut(chatID, ":topic:")
+	// The button must have been clicked in the chat it was sent to.
+	if parentID == "" || parentID != event.Event.Context.OpenChatID {
+		slog.Debug("feishu card action chat mismatch", "chat_id", chatID, "open_chat_id", event.Event.Context.OpenChatID)
+		return
+	}
+	peerKind, _ := value["peer_kind"].(string)
+	if peerKind != "group" {
+		peerKind = "direct"
+	}
+
+	c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID:    event.Event.Operator.OpenID,
+		ChatID:      chatID,
+		PeerKind:    peerKind,
+		Text:        command,
+		WriterScope: fmt.Sprintf("group:%s:%s", c.Name(), parentID),
+	})
+}
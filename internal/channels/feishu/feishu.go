@@ -124,6 +124,7 @@ func New(cfg config.FeishuConfig, msgBus *bus.MessageBus, pairingSvc store.Pairi
 	ch.SetPairingService(pairingSvc)
 	ch.SetGroupHistory(channels.MakeHistory(channels.TypeFeishu, pendingStore, base.TenantID()))
 	ch.SetHistoryLimit(historyLimit)
+	ch.SetCommandReplier(ch.sendCommandCard)
 	for _, opt := range opts {
 		opt(ch)
 	}
@@ -269,8 +270,15 @@ func (a *wsEventAdapter) HandleEvent(ctx context.Context, payload []byte) error
 		slog.Debug("feishu ws: parse event failed", "error", err)
 		return fmt.Errorf("parse event: %w", err)
 	}
-	if event.Header.EventType == "im.message.receive_v1" {
+	switch event.Header.EventType {
+	case "im.message.receive_v1":
 		a.ch.handleMessageEvent(ctx, &event)
+	case cardActionEventType:
+		var action CardActionEvent
+		if err := json.Unmarshal(payload, &action); err != nil {
+			return fmt.Errorf("parse card action: %w", err)
+		}
+		a.ch.handleCardAction(ctx, &action)
 	}
 	return nil
 }
@@ -309,14 +317,24 @@ func (c *Channel) WebhookHandler() (string, http.Handler) {
 		path = defaultWebhookPath
 	}
 
-	handler := NewWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey, func(event *MessageEvent) {
-		ctx := store.WithTenantID(context.Background(), c.TenantID())
-		c.handleMessageEvent(ctx, event)
-	})
+	handler := c.newWebhookHandler()
 
 	return path, http.HandlerFunc(handler)
 }
 
+// newWebhookHandler returns the webhook handler for messages and card clicks.
+func (c *Channel) newWebhookHandler() http.HandlerFunc {
+	return newEventWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey,
+		func(event *MessageEvent) {
+			ctx := store.WithTenantID(context.Background(), c.TenantID())
+			c.handleMessageEvent(ctx, event)
+		},
+		func(event *CardActionEvent) {
+			ctx := store.WithTenantID(context.Background(), c.TenantID())
+			c.handleCardAction(ctx, event)
+		})
+}
+
 func (c *Channel) startWebhook(ctx context.Context) error {
 	// If webhook_port is 0, the handler is mounted on the main gateway mux
 	// via WebhookHandler() — no separate server needed.
@@ -330,10 +348,7 @@ func (c *Channel) startWebhook(ctx context.Context) error {
 
 	slog.Info("feishu: starting Webhook server", "port", port, "path", path)
 
-	handler := NewWebhookHandler(c.cfg.VerificationToken, c.cfg.EncryptKey, func(event *MessageEvent) {
-		ctx := store.WithTenantID(context.Background(), c.TenantID())
-		c.handleMessageEvent(ctx, event)
-	})
+	handler := c.newWebhookHandler()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc(path, handler)
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"net/url"
 	"strconv"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 )
 
 // --- IM API: Messages ---
@@ -23,6 +25,10 @@ func (c *LarkClient) SendMessage(ctx context.Context, receiveIDType, receiveID,
 		"msg_type":   msgType,
 		"content":    content,
 	}
+	// Lark drops a repeated uuid within an hour, so outbox retries are not shown twice.
+	if key := channels.NextIdempotencyKey(ctx); key != "" {
+		body["uuid"] = key
+	}
 	resp, err := c.doJSON(ctx, "POST", path, body)
 	if err != nil {
 		return nil, err
@@ -59,6 +65,9 @@ func (c *LarkClient) ReplyMessage(ctx context.Context, rootMessageID, msgType, c
 		"content":         content,
 		"reply_in_thread": replyInThread,
 	}
+	if key := channels.NextIdempotencyKey(ctx); key != "" {
+		body["uuid"] = key
+	}
 	resp, err := c.doJSON(ctx, "POST", path, body)
 	if err != nil {
 		return nil, err
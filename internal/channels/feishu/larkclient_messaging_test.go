@@ -8,6 +8,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 )
 
 // TestReplyMessage_InThread verifies the client hits the correct reply endpoint
@@ -88,3 +92,37 @@ func TestReplyMessage_APIError(t *testing.T) {
 		t.Fatal("expected error on non-zero code, got nil")
 	}
 }
+
+// TestSendMessage_IdempotencyKey verifies outbox sends carry a stable uuid so
+// Lark drops a retried message instead of showing it twice.
+func TestSendMessage_IdempotencyKey(t *testing.T) {
+	var uuids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenEndpoint {
+			_, _ = w.Write([]byte(`{"code":0,"msg":"ok","tenant_access_token":"t","expire":7200}`))
+			return
+		}
+		var body map[string]any
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		id, _ := body["uuid"].(string)
+		uuids = append(uuids, id)
+		_, _ = w.Write([]byte(`{"code":0,"msg":"","data":{"message_id":"om_1"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewLarkClient("a", "s", srv.URL)
+	outboxID := uuid.New()
+	for range 2 { // two delivery attempts of the same outbox message
+		ctx := channels.WithIdempotencyKey(context.Background(), outboxID)
+		if _, err := client.SendMessage(ctx, "chat_id", "oc_1", "text", `{"text":"x"}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.SendMessage(context.Background(), "chat_id", "oc_1", "text", `{"text":"x"}`); err != nil {
+		t.Fatal(err)
+	}
+	if len(uuids) != 3 || uuids[0] == "" || uuids[0] != uuids[1] || uuids[2] != "" {
+		t.Errorf("uuids = %q; want the same key for both attempts and none without an outbox", uuids)
+	}
+}
@@ -91,6 +91,12 @@ type webhookEvent struct {
 // NewWebhookHandler creates an http.HandlerFunc that handles Feishu webhook events.
 // Supports: URL verification challenge, event decryption, and message dispatch.
 func NewWebhookHandler(verificationToken, encryptKey string, onMessage func(event *MessageEvent)) http.HandlerFunc {
+	return newEventWebhookHandler(verificationToken, encryptKey, onMessage, nil)
+}
+
+// newEventWebhookHandler is NewWebhookHandler that also dispatches card
+// button clicks (card.action.trigger) to onCardAction when non-nil.
+func newEventWebhookHandler(verificationToken, encryptKey string, onMessage func(event *MessageEvent), onCardAction func(event *CardActionEvent)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -150,9 +156,15 @@ func NewWebhookHandler(verificationToken, encryptKey string, onMessage func(even
 			return
 		}
 
-		// Only handle message events
-		if event.Header.EventType == "im.message.receive_v1" {
+		// Only handle message events and card clicks
+		switch event.Header.EventType {
+		case "im.message.receive_v1":
 			go onMessage(&event)
+		case cardActionEventType:
+			var action CardActionEvent
+			if onCardAction != nil && json.Unmarshal(eventBody, &action) == nil {
+				go onCardAction(&action)
+			}
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -349,3 +349,37 @@ func TestDecryptEvent_TamperedPayload(t *testing.T) {
 	}
 	// If errWrong != nil, the garbage didn't even contain { } — that's an even stronger failure.
 }
+
+func TestWebhookHandler_CardActionDispatches(t *testing.T) {
+	dispatched := make(chan *CardActionEvent, 1)
+	h := newEventWebhookHandler("good-token", "",
+		func(*MessageEvent) { t.Error("card action dispatched as message") },
+		func(e *CardActionEvent) { dispatched <- e })
+
+	env := map[string]any{
+		"schema": "2.0",
+		"header": map[string]any{"event_type": "card.action.trigger", "token": "good-token"},
+		"event": map[string]any{
+			"operator": map[string]any{"open_id": "ou_U00000001"},
+			"action": map[string]any{
+				"tag":   "button",
+				"value": map[string]any{"command": "/approve 1234", "chat_id": "oc_chat_001", "peer_kind": "group"},
+			},
+			"context": map[string]any{"open_message_id": "om_msg001", "open_chat_id": "oc_chat_001"},
+		},
+	}
+	body, _ := json.Marshal(env)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, buildWebhookRequest(string(body)))
+
+	select {
+	case e := <-dispatched:
+		if e.Event.Operator.OpenID != "ou_U00000001" || e.Event.Action.Value["command"] != "/approve 1234" ||
+			e.Event.Context.OpenChatID != "oc_chat_001" {
+			t.Errorf("unexpected card action: %+v", e.Event)
+		}
+	case <-time.After(dispatchWaitTimeout):
+		t.Error("timeout waiting for card action")
+	}
+}
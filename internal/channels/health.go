@@ -27,6 +27,7 @@ const (
 	ChannelFailureKindAuth    ChannelFailureKind = "auth"
 	ChannelFailureKindConfig  ChannelFailureKind = "config"
 	ChannelFailureKindNetwork ChannelFailureKind = "network"
+	ChannelFailureKindQuota   ChannelFailureKind = "quota"
 	ChannelFailureKindUnknown ChannelFailureKind = "unknown"
 )
 
@@ -38,6 +39,7 @@ const (
 	ChannelRemediationCodeOpenCredentials ChannelRemediationCode = "open_credentials"
 	ChannelRemediationCodeOpenAdvanced    ChannelRemediationCode = "open_advanced"
 	ChannelRemediationCodeCheckNetwork    ChannelRemediationCode = "check_network"
+	ChannelRemediationCodeCheckQuota      ChannelRemediationCode = "check_quota"
 )
 
 // ChannelRemediationTarget tells the UI which existing surface can help resolve the issue.
@@ -147,6 +149,13 @@ func ClassifyChannelError(err error) ChannelErrorInfo {
 			Kind:      ChannelFailureKindAuth,
 			Retryable: false,
 		}
+	case strings.Contains(msg, "quota") || strings.Contains(msg, "monthly limit"):
+		return ChannelErrorInfo{
+			Summary:   "Quota exhausted",
+			Detail:    "The upstream service's message quota is used up.",
+			Kind:      ChannelFailureKindQuota,
+			Retryable: true,
+		}
 	case strings.Contains(msg, "invalid proxy"):
 		return ChannelErrorInfo{
 			Summary:   "Configuration is invalid",
@@ -361,6 +370,13 @@ func buildChannelRemediation(snapshot ChannelHealth) *ChannelRemediation {
 			Hint:     "Open advanced settings and correct the invalid channel configuration.",
 			Target:   ChannelRemediationTargetAdvanced,
 		}
+	case ChannelFailureKindQuota:
+		return &ChannelRemediation{
+			Code:     ChannelRemediationCodeCheckQuota,
+			Headline: "Check the platform message quota",
+			Hint:     "The platform's sending quota is used up. Upgrade the plan or wait for the quota to reset; messages that need it are not delivered until then.",
+			Target:   ChannelRemediationTargetDetails,
+		}
 	case ChannelFailureKindNetwork:
 		return &ChannelRemediation{
 			Code:     ChannelRemediationCodeCheckNetwork,
@@ -455,6 +455,19 @@ func TestBuildChannelRemediationUsesRealOperatorTargets(t *testing.T) {
 			wantCode:   ChannelRemediationCodeCheckNetwork,
 			wantTarget: ChannelRemediationTargetDetails,
 		},
+		{
+			name: "exhausted quota checks quota",
+			snapshot: NewChannelHealthForType(
+				TypeLine,
+				ChannelHealthStateDegraded,
+				"Quota exhausted",
+				"You have reached your monthly limit.",
+				ChannelFailureKindQuota,
+				true,
+			),
+			wantCode:   ChannelRemediationCodeCheckQuota,
+			wantTarget: ChannelRemediationTargetDetails,
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,42 @@
+package instagram
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// handleCommentEvent processes a "comments" change on one of the account's posts or reels.
+func (ch *Channel) handleCommentEvent(value commentValue) {
+	// Feature gate.
+	if !ch.config.Features.CommentReply {
+		return
+	}
+
+	// Self-reply prevention: skip comments posted by the account itself
+	// (including our own replies, which are delivered as comments too).
+	if value.ID == "" || value.From.ID == "" || value.From.ID == ch.accountID {
+		return
+	}
+
+	// Dedup: Meta may deliver the same event more than once.
+	if ch.isDup("comment:" + value.ID) {
+		slog.Debug("instagram: duplicate comment event skipped", "comment_id", value.ID)
+		return
+	}
+
+	senderID := value.From.ID
+	// Session key groups all comments by the same user on the same media.
+	chatID := fmt.Sprintf("%s:%s", value.Media.ID, senderID)
+
+	metadata := map[string]string{
+		"ig_mode":             "comment",
+		"comment_id":          value.ID,
+		"media_id":            value.Media.ID,
+		"parent_id":           value.ParentID,
+		"sender_name":         value.From.Username,
+		"sender_id":           senderID,
+		"reply_to_comment_id": value.ID,
+	}
+
+	ch.HandleMessage(senderID, chatID, value.Text, nil, metadata, "direct")
+}
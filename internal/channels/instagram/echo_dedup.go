@@ -0,0 +1,128 @@
+package instagram
+
+import (
+	"strings"
+	"time"
+)
+
+// isDup checks and records a dedup key. Returns true if the key was already seen.
+func (ch *Channel) isDup(key string) bool {
+	_, loaded := ch.dedup.LoadOrStore(key, time.Now())
+	return loaded
+}
+
+// Instagram echoes every message the account sends (is_echo), whether it came
+// from this channel or from a person replying in the Instagram app. Outbound
+// text is fingerprinted before sending so our own echoes can be told apart;
+// any other echo means a human took over the conversation.
+
+func (ch *Channel) forgetOutboundEcho(chatID, content string) {
+	if chatID == "" {
+		return
+	}
+	normalized := normalizeEchoContent(content)
+	if normalized == "" {
+		return
+	}
+	ch.recentOutbound.Delete(chatID + "\x00" + normalized)
+}
+
+func (ch *Channel) rememberOutboundEcho(chatID, content string) {
+	if chatID == "" {
+		return
+	}
+	normalized := normalizeEchoContent(content)
+	if normalized == "" {
+		return
+	}
+	ch.recentOutbound.Store(chatID+"\x00"+normalized, time.Now())
+}
+
+func (ch *Channel) isRecentOutboundEcho(chatID, content string) bool {
+	if chatID == "" {
+		return false
+	}
+	normalized := normalizeEchoContent(content)
+	if normalized == "" {
+		return false
+	}
+	key := chatID + "\x00" + normalized
+	v, ok := ch.recentOutbound.Load(key)
+	if !ok {
+		return false
+	}
+	ts, ok := v.(time.Time)
+	if !ok {
+		ch.recentOutbound.Delete(key)
+		return false
+	}
+	if time.Since(ts) > outboundEchoTTL {
+		ch.recentOutbound.Delete(key)
+		return false
+	}
+	return true
+}
+
+// normalizeEchoContent collapses whitespace so that line ending or spacing
+// changes made by Instagram do not defeat echo matching.
+func normalizeEchoContent(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+	return strings.Join(normalized, "\n")
+}
+
+func (ch *Channel) adminRepliedRecently(chatID string, now time.Time) bool {
+	val, ok := ch.adminReplied.Load(chatID)
+	if !ok {
+		return false
+	}
+	repliedAt, ok := val.(time.Time)
+	if !ok {
+		ch.adminReplied.Delete(chatID)
+		return false
+	}
+	if now.Sub(repliedAt) < adminReplyCooldown {
+		return true
+	}
+	ch.adminReplied.Delete(chatID)
+	return false
+}
+
+// runDedupCleaner evicts stale entries from dedup, recentOutbound and
+// adminReplied every dedupCleanEvery to prevent unbounded memory growth.
+func (ch *Channel) runDedupCleaner() {
+	ticker := time.NewTicker(dedupCleanEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ch.stopCh:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			ch.dedup.Range(func(k, v any) bool {
+				if t, ok := v.(time.Time); ok && now.Sub(t) > dedupTTL {
+					ch.dedup.Delete(k)
+				}
+				return true
+			})
+			ch.recentOutbound.Range(func(k, v any) bool {
+				if t, ok := v.(time.Time); ok && now.Sub(t) > outboundEchoTTL {
+					ch.recentOutbound.Delete(k)
+				}
+				return true
+			})
+			ch.adminReplied.Range(func(k, v any) bool {
+				if t, ok := v.(time.Time); ok && now.Sub(t) > adminReplyCooldown {
+					ch.adminReplied.Delete(k)
+				}
+				return true
+			})
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package instagram
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+)
+
+const (
+	dmMaxBytes      = 1000 // Instagram rejects DM text of 1000 bytes or more
+	commentMaxChars = 2200
+)
+
+// formatOutbound converts agent output to plain text; Instagram renders no markdown.
+func formatOutbound(text string) string {
+	return facebook.FormatForMessenger(text)
+}
+
+// splitMessage splits text into chunks below maxBytes bytes, preferring
+// paragraph, line and sentence boundaries. Instagram measures DM text in
+// bytes, so Vietnamese or emoji-heavy replies split sooner than ASCII.
+func splitMessage(text string, maxBytes int) []string {
+	var parts []string
+	for len(text) >= maxBytes {
+		cut := maxBytes - 1
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		if idx := lastBoundary(text[:cut], cut/2); idx > 0 {
+			cut = idx
+		}
+		if part := strings.TrimSpace(text[:cut]); part != "" {
+			parts = append(parts, part)
+		}
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// lastBoundary returns the end of the last paragraph, line or sentence in s
+// past floor, or -1 if there is none.
+func lastBoundary(s string, floor int) int {
+	if idx := strings.LastIndex(s, "\n\n"); idx > floor {
+		return idx
+	}
+	for _, sep := range []string{"\n", ". ", "! ", "? "} {
+		if idx := strings.LastIndex(s, sep); idx > floor {
+			return idx + 1
+		}
+	}
+	return -1
+}
+
+// truncateBytes shortens s to fewer than maxBytes bytes on a rune boundary.
+func truncateBytes(s string, maxBytes int) string {
+	if len(s) < maxBytes {
+		return s
+	}
+	cut := maxBytes - 1
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(s[:cut])
+}
+
+// truncateRunes truncates s to at most n Unicode code points.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,246 @@
+package instagram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Compile-time interface assertions.
+var (
+	_ channels.Channel           = (*Channel)(nil)
+	_ channels.WebhookChannel    = (*Channel)(nil)
+	_ channels.BlockReplyChannel = (*Channel)(nil)
+	_ facebook.WebhookSubscriber = (*Channel)(nil)
+)
+
+const (
+	// webhookObject is the Meta webhook object type for Instagram accounts.
+	webhookObject      = "instagram"
+	dedupTTL           = 24 * time.Hour  // matches Meta's max retry window
+	dedupCleanEvery    = 5 * time.Minute // how often to evict stale dedup entries
+	adminReplyCooldown = 5 * time.Minute
+	outboundEchoTTL    = 45 * time.Second
+)
+
+// Channel implements channels.Channel and channels.WebhookChannel for an
+// Instagram professional account.
+type Channel struct {
+	*channels.BaseChannel
+	config      instagramInstanceConfig
+	graphClient *facebook.GraphClient
+	appSecret   string
+	verifyToken string
+	pageID      string
+	accountID   string // Instagram professional account ID; set by Start
+
+	// dedup prevents processing duplicate webhook deliveries.
+	// Value is the time.Time the event was first seen; entries are evicted after dedupTTL.
+	dedup sync.Map // eventKey(string) → time.Time
+
+	// recentOutbound suppresses echoes of our own replies.
+	recentOutbound sync.Map // chatID + "\x00" + normalized content → time.Time
+
+	// adminReplied tracks conversations where a person replied from the
+	// Instagram app recently; the bot stays quiet there during the cooldown.
+	adminReplied sync.Map // chatID(string) → time.Time
+
+	stopCh  chan struct{}
+	stopCtx context.Context
+	stopFn  context.CancelFunc
+}
+
+// New creates an Instagram channel from parsed credentials and config.
+func New(cfg instagramInstanceConfig, creds instagramCreds,
+	msgBus *bus.MessageBus, _ store.PairingStore) (*Channel, error) {
+
+	if creds.PageAccessToken == "" {
+		return nil, fmt.Errorf("instagram: page_access_token is required")
+	}
+	if cfg.PageID == "" {
+		return nil, fmt.Errorf("instagram: page_id is required")
+	}
+	if creds.AppSecret == "" {
+		return nil, fmt.Errorf("instagram: app_secret is required")
+	}
+	if creds.VerifyToken == "" {
+		return nil, fmt.Errorf("instagram: verify_token is required")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeInstagram, msgBus, cfg.AllowFrom)
+	stopCtx, stopFn := context.WithCancel(context.Background())
+
+	return &Channel{
+		BaseChannel: base,
+		config:      cfg,
+		graphClient: facebook.NewGraphClient(creds.PageAccessToken, cfg.PageID),
+		appSecret:   creds.AppSecret,
+		verifyToken: creds.VerifyToken,
+		pageID:      cfg.PageID,
+		stopCh:      make(chan struct{}),
+		stopCtx:     stopCtx,
+		stopFn:      stopFn,
+	}, nil
+}
+
+// Factory creates an Instagram Channel from DB instance data.
+// Implements channels.ChannelFactory.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+
+	var c instagramCreds
+	if err := json.Unmarshal(creds, &c); err != nil {
+		return nil, fmt.Errorf("instagram: decode credentials: %w", err)
+	}
+
+	var ic instagramInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("instagram: decode config: %w", err)
+		}
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (ch *Channel) BlockReplyEnabled() *bool { return ch.config.BlockReply }
+
+// Start verifies the page token, resolves the linked Instagram account,
+// subscribes webhooks and marks the channel healthy.
+func (ch *Channel) Start(ctx context.Context) error {
+	ch.MarkStarting("connecting to Instagram account")
+
+	if err := ch.graphClient.VerifyToken(ctx); err != nil {
+		ch.MarkFailed("token invalid", err.Error(), channels.ChannelFailureKindAuth, false)
+		return err
+	}
+
+	username, err := ch.resolveAccount(ctx)
+	if err != nil {
+		ch.MarkFailed("instagram account not found", err.Error(), channels.ChannelFailureKindConfig, false)
+		return err
+	}
+
+	// Best-effort: Instagram messaging webhooks require the app on the page.
+	if err := ch.graphClient.SubscribeApp(ctx); err != nil {
+		slog.Warn("instagram: webhook subscription failed (check app install on page)", "err", err)
+	}
+
+	facebook.RegisterSubscriber(webhookObject, ch.accountID, ch)
+	ch.MarkHealthy("connected as @" + username)
+	ch.SetRunning(true)
+
+	go ch.runDedupCleaner()
+
+	slog.Info("instagram channel started", "account_id", ch.accountID, "username", username, "name", ch.Name())
+	return nil
+}
+
+// resolveAccount looks up the Instagram account linked to the page.
+func (ch *Channel) resolveAccount(ctx context.Context) (string, error) {
+	data, err := ch.graphClient.Do(ctx, http.MethodGet,
+		"/"+ch.pageID+"?fields=instagram_business_account{id,username}", nil)
+	if err != nil {
+		return "", fmt.Errorf("instagram: look up linked account: %w", err)
+	}
+	var page struct {
+		Account *struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"instagram_business_account"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return "", fmt.Errorf("instagram: parse linked account: %w", err)
+	}
+	if page.Account == nil || page.Account.ID == "" {
+		return "", fmt.Errorf("instagram: page %s has no linked Instagram professional account", ch.pageID)
+	}
+	if ch.config.InstagramAccountID != "" && ch.config.InstagramAccountID != page.Account.ID {
+		return "", fmt.Errorf("instagram: page %s is linked to account %s, not %s",
+			ch.pageID, page.Account.ID, ch.config.InstagramAccountID)
+	}
+	ch.accountID = page.Account.ID
+	return page.Account.Username, nil
+}
+
+// Stop gracefully shuts down the channel.
+func (ch *Channel) Stop(_ context.Context) error {
+	if ch.accountID != "" {
+		facebook.UnregisterSubscriber(webhookObject, ch.accountID, ch)
+	}
+	ch.stopFn()
+	close(ch.stopCh)
+	ch.SetRunning(false)
+	ch.MarkStopped("stopped")
+	slog.Info("instagram channel stopped", "account_id", ch.accountID, "name", ch.Name())
+	return nil
+}
+
+// WebhookHandler returns the shared Meta webhook route. Whichever facebook,
+// whatsapp_cloud or instagram instance asks first mounts it; others return ("", nil).
+func (ch *Channel) WebhookHandler() (string, http.Handler) {
+	return facebook.WebhookRoute()
+}
+
+// WebhookSecrets implements facebook.WebhookSubscriber.
+func (ch *Channel) WebhookSecrets() (string, string) {
+	return ch.appSecret, ch.verifyToken
+}
+
+// HandleWebhookEntry implements facebook.WebhookSubscriber.
+func (ch *Channel) HandleWebhookEntry(entry facebook.RawEntry) {
+	if !ch.IsRunning() || entry.ID != ch.accountID {
+		return
+	}
+	for _, raw := range entry.Messaging {
+		var event messagingEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			slog.Warn("instagram: messaging event parse error", "err", err)
+			continue
+		}
+		ch.handleMessagingEvent(event)
+	}
+	for _, change := range entry.Changes {
+		if change.Field != "comments" {
+			continue
+		}
+		var value commentValue
+		if err := json.Unmarshal(change.Value, &value); err != nil {
+			slog.Warn("instagram: comment event parse error", "err", err)
+			continue
+		}
+		ch.handleCommentEvent(value)
+	}
+}
+
+// handleAPIError maps Graph API errors to channel health states.
+func (ch *Channel) handleAPIError(err error) {
+	if err == nil {
+		return
+	}
+	switch {
+	case facebook.IsAuthError(err):
+		ch.MarkFailed("token expired", err.Error(), channels.ChannelFailureKindAuth, false)
+	case facebook.IsPermissionError(err):
+		ch.MarkFailed("permission denied", err.Error(), channels.ChannelFailureKindAuth, false)
+	case facebook.IsRateLimitError(err):
+		ch.MarkDegraded("rate limited", err.Error(), channels.ChannelFailureKindNetwork, true)
+	default:
+		ch.MarkDegraded("api error", err.Error(), channels.ChannelFailureKindUnknown, true)
+	}
+}
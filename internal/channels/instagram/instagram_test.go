@@ -0,0 +1,214 @@
+package instagram
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+)
+
+const (
+	testPageID    = "1001"
+	testAccountID = "17841400000000001"
+	testAppSecret = "app-secret"
+	testUser      = "5550001"
+)
+
+// fakeGraph is a minimal Graph API stub recording requests.
+type fakeGraph struct {
+	*channeltest.Server
+}
+
+func newFakeGraph(t *testing.T) *fakeGraph {
+	t.Helper()
+	g := &fakeGraph{channeltest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/me"):
+			io.WriteString(w, `{"id":"`+testPageID+`","name":"Acme"}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/"+testPageID):
+			io.WriteString(w, `{"instagram_business_account":{"id":"`+testAccountID+`","username":"acme"}}`)
+		case r.Method == http.MethodPost:
+			io.WriteString(w, `{"message_id":"m_1","id":"c_1"}`)
+		default:
+			io.WriteString(w, `{}`)
+		}
+	}))}
+	facebook.SetGraphAPIBaseForTest(g.URL)
+	t.Cleanup(func() { facebook.SetGraphAPIBaseForTest("") })
+	return g
+}
+
+// posts returns the message and reply POSTs (webhook subscription excluded).
+func (g *fakeGraph) posts() []channeltest.Request {
+	var out []channeltest.Request
+	for _, r := range g.Requests(http.MethodPost, "") {
+		if !strings.HasSuffix(r.Path, "/subscribed_apps") {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+var (
+	routeOnce    sync.Once
+	webhookRoute http.Handler
+)
+
+func startChannel(t *testing.T, cfg instagramInstanceConfig) (*Channel, *bus.MessageBus, *fakeGraph) {
+	t.Helper()
+	g := newFakeGraph(t)
+	mb := bus.New()
+	cfg.PageID = testPageID
+	ch, err := New(cfg, instagramCreds{PageAccessToken: "tok", AppSecret: testAppSecret, VerifyToken: "verify-me"}, mb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetName("ig")
+	channeltest.Start(t, ch)
+	routeOnce.Do(func() { _, webhookRoute = ch.WebhookHandler() })
+	return ch, mb, g
+}
+
+// deliver posts a signed instagram webhook with one entry.
+func deliver(t *testing.T, entry map[string]any) {
+	t.Helper()
+	entry["id"] = testAccountID
+	body, _ := json.Marshal(map[string]any{"object": "instagram", "entry": []any{entry}})
+	mac := hmac.New(sha256.New, []byte(testAppSecret))
+	mac.Write(body)
+	req := httptest.NewRequest(http.MethodPost, "/channels/facebook/webhook", bytes.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	webhookRoute.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func dm(from, to, mid, text string, extra map[string]any) map[string]any {
+	msg := map[string]any{"mid": mid, "text": text}
+	for k, v := range extra {
+		msg[k] = v
+	}
+	return map[string]any{"messaging": []any{map[string]any{
+		"sender":    map[string]string{"id": from},
+		"recipient": map[string]string{"id": to},
+		"timestamp": time.Now().UnixMilli(),
+		"message":   msg,
+	}}}
+}
+
+func TestWebhook_DMStoryReplyAndEchoes(t *testing.T) {
+	cfg := instagramInstanceConfig{}
+	cfg.Features.DMAutoReply = true
+	ch, mb, g := startChannel(t, cfg)
+
+	deliver(t, dm(testUser, testAccountID, "mid.1", "love this!", map[string]any{
+		"reply_to": map[string]any{"story": map[string]string{"id": "s1", "url": "https://cdn.example/story.jpg"}},
+	}))
+	in := channeltest.Consume(t, mb)
+	if in.ChatID != testUser || in.Metadata["ig_mode"] != "dm" ||
+		in.Content != "[Replied to your story: https://cdn.example/story.jpg]\nlove this!" {
+		t.Fatalf("inbound = %q %v", in.Content, in.Metadata)
+	}
+
+	// Redelivery is ignored.
+	deliver(t, dm(testUser, testAccountID, "mid.1", "love this!", nil))
+	if _, ok := channeltest.TryConsume(mb, 200*time.Millisecond); ok {
+		t.Fatal("duplicate delivered")
+	}
+
+	// Our own reply echoes back and must not pause the conversation.
+	if err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID: testUser, Content: "**Thanks** a lot!", Metadata: map[string]string{"ig_mode": "dm"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	posts := g.posts()
+	if len(posts) != 1 || !strings.HasSuffix(posts[0].Path, "/me/messages") ||
+		posts[0].JSON()["message"].(map[string]any)["text"] != "Thanks a lot!" {
+		t.Fatalf("posts = %+v", posts)
+	}
+	deliver(t, dm(testAccountID, testUser, "mid.2", "Thanks a lot!", map[string]any{"is_echo": true}))
+	deliver(t, dm(testUser, testAccountID, "mid.3", "one more thing", nil))
+	if _, ok := channeltest.TryConsume(mb, channeltest.Timeout); !ok {
+		t.Fatal("bot echo paused the conversation")
+	}
+
+	// A person answering from the Instagram app takes over.
+	deliver(t, dm(testAccountID, testUser, "mid.4", "Hi, this is Mai from the shop", map[string]any{"is_echo": true}))
+	deliver(t, dm(testUser, testAccountID, "mid.5", "thanks Mai", nil))
+	if _, ok := channeltest.TryConsume(mb, 200*time.Millisecond); ok {
+		t.Fatal("auto-reply not paused after admin reply")
+	}
+}
+
+func TestComment_PublicReplyAndCommentToDM(t *testing.T) {
+	cfg := instagramInstanceConfig{}
+	cfg.Features.CommentReply = true
+	ch, mb, g := startChannel(t, cfg)
+	ctx := context.Background()
+
+	comment := func(id, from, text string) map[string]any {
+		return map[string]any{"changes": []any{map[string]any{"field": "comments", "value": map[string]any{
+			"id": id, "text": text,
+			"from":  map[string]string{"id": from, "username": "ana"},
+			"media": map[string]string{"id": "m9", "media_product_type": "FEED"},
+		}}}}
+	}
+	deliver(t, comment("c1", testUser, "price?"))
+	deliver(t, comment("c2", testAccountID, "our own reply"))
+	in := channeltest.Consume(t, mb)
+	if in.ChatID != "m9:"+testUser || in.Metadata["reply_to_comment_id"] != "c1" || in.Metadata["sender_name"] != "ana" {
+		t.Fatalf("inbound = %+v", in)
+	}
+	if _, ok := channeltest.TryConsume(mb, 200*time.Millisecond); ok {
+		t.Fatal("own comment delivered")
+	}
+
+	out := bus.OutboundMessage{ChatID: in.ChatID, Content: "It's $20", Metadata: in.Metadata}
+	if err := ch.Send(ctx, out); err != nil {
+		t.Fatal(err)
+	}
+	if posts := g.posts(); len(posts) != 1 || !strings.HasSuffix(posts[0].Path, "/c1/replies") {
+		t.Fatalf("public reply posts = %+v", posts)
+	}
+
+	// Comment-to-DM: one private reply per comment.
+	ch.config.Features.CommentToDM = true
+	ch.Send(ctx, out)
+	ch.Send(ctx, out)
+	posts := g.posts()
+	if len(posts) != 2 || posts[1].JSON()["recipient"].(map[string]any)["comment_id"] != "c1" {
+		t.Fatalf("private reply posts = %+v", posts)
+	}
+}
+
+func TestSplitMessage_ByBytes(t *testing.T) {
+	text := strings.Repeat("Xin chào các bạn. ", 150) // multi-byte runes
+	parts := splitMessage(text, dmMaxBytes)
+	if len(parts) < 2 {
+		t.Fatalf("parts = %d", len(parts))
+	}
+	for i, p := range parts {
+		if len(p) >= dmMaxBytes || !utf8.ValidString(p) {
+			t.Errorf("part %d: %d bytes, valid=%v", i, len(p), utf8.ValidString(p))
+		}
+		if i < len(parts)-1 && !strings.HasSuffix(p, ".") {
+			t.Errorf("part %d not split at a sentence: %q", i, p[len(p)-10:])
+		}
+	}
+	if got := strings.Join(parts, " "); got != strings.TrimSpace(text) {
+		t.Error("content lost in split")
+	}
+}
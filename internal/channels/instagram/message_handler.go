@@ -0,0 +1,113 @@
+package instagram
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// attachmentLabels names attachment types in the text passed to the agent.
+var attachmentLabels = map[string]string{
+	"image":         "Image",
+	"video":         "Video",
+	"audio":         "Audio",
+	"file":          "File",
+	"share":         "Shared post",
+	"ig_reel":       "Shared reel",
+	"reel":          "Shared reel",
+	"story_mention": "Mentioned you in their story",
+}
+
+// handleMessagingEvent processes an Instagram DM event.
+func (ch *Channel) handleMessagingEvent(event messagingEvent) {
+	// Feature gate.
+	if !ch.config.Features.DMAutoReply {
+		return
+	}
+
+	// Echo of a message sent by the account: ours, or a person replying from
+	// the Instagram app. The latter pauses auto-reply for the conversation.
+	if event.Sender.ID == ch.accountID {
+		if event.Message == nil || event.Recipient.ID == "" {
+			return
+		}
+		chatID := event.Recipient.ID
+		if ch.isRecentOutboundEcho(chatID, event.Message.Text) {
+			slog.Debug("instagram: bot echo ignored", "chat_id", chatID)
+			return
+		}
+		ch.adminReplied.Store(chatID, time.Now())
+		slog.Debug("instagram: admin reply tracked", "chat_id", chatID)
+		return
+	}
+
+	// Skip read receipts, reactions and other non-content events.
+	if event.Message == nil && event.Postback == nil {
+		return
+	}
+	if event.Message != nil && (event.Message.IsDeleted || event.Message.IsUnsupported) {
+		return
+	}
+
+	var eventKey string
+	switch {
+	case event.Message != nil:
+		eventKey = "msg:" + event.Message.MID
+	case event.Postback.MID != "":
+		eventKey = "postback:" + event.Postback.MID
+	default:
+		eventKey = fmt.Sprintf("postback:%s:%d:%s", event.Sender.ID, event.Timestamp, event.Postback.Payload)
+	}
+	if ch.isDup(eventKey) {
+		slog.Debug("instagram: duplicate messaging event skipped", "key", eventKey)
+		return
+	}
+
+	senderID := event.Sender.ID
+	if ch.adminRepliedRecently(senderID, time.Now()) {
+		slog.Info("instagram: skipping auto-reply (admin replied recently)", "chat_id", senderID)
+		return
+	}
+
+	content := buildDMContent(event)
+	if content == "" {
+		return
+	}
+
+	// DMs are 1:1: chatID = senderID (Instagram-scoped ID).
+	metadata := map[string]string{
+		"ig_mode":    "dm",
+		"message_id": eventKey,
+		"sender_id":  senderID,
+	}
+	if ch.config.DMOptions.SessionTimeout != "" {
+		metadata["session_timeout"] = ch.config.DMOptions.SessionTimeout
+	}
+
+	ch.HandleMessage(senderID, senderID, content, nil, metadata, "direct")
+}
+
+// buildDMContent renders a DM as text: the story it replies to, the text and
+// one line per attachment. Media is passed inline as CDN URLs.
+func buildDMContent(event messagingEvent) string {
+	if event.Postback != nil {
+		return event.Postback.Title
+	}
+	msg := event.Message
+	var parts []string
+	if msg.ReplyTo != nil && msg.ReplyTo.Story != nil {
+		parts = append(parts, fmt.Sprintf("[Replied to your story: %s]", msg.ReplyTo.Story.URL))
+	}
+	if text := strings.TrimSpace(msg.Text); text != "" {
+		parts = append(parts, text)
+	}
+	for _, att := range msg.Attachments {
+		label, ok := attachmentLabels[att.Type]
+		if !ok || att.Payload.URL == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("[%s: %s]", label, att.Payload.URL))
+	}
+	return strings.Join(parts, "\n")
+}
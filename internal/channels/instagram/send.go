@@ -0,0 +1,101 @@
+package instagram
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+)
+
+// Send delivers an outbound message. Dispatches to a DM or a comment reply based on ig_mode metadata.
+func (ch *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	// NO_REPLY / suppressed-error path: nothing user-visible to send.
+	// Instagram only accepts media by public URL, so local attachments are not sent.
+	if msg.Content == "" {
+		return nil
+	}
+
+	switch msg.Metadata["ig_mode"] {
+	case "comment":
+		return ch.sendCommentReply(ctx, msg)
+	default: // "dm"
+		return ch.sendDM(ctx, msg)
+	}
+}
+
+// ClassifySendError implements channels.SendErrorClassifier with the shared
+// Graph API classification.
+func (ch *Channel) ClassifySendError(err error) channels.SendFailure {
+	return facebook.ClassifySendError(err)
+}
+
+// sendDM replies in a DM conversation, split into chunks Instagram accepts.
+func (ch *Channel) sendDM(ctx context.Context, msg bus.OutboundMessage) error {
+	if ch.adminRepliedRecently(msg.ChatID, time.Now()) {
+		slog.Info("instagram: skipping bot reply (admin already responded)", "chat_id", msg.ChatID)
+		return nil
+	}
+
+	// Store echo fingerprints BEFORE sending so that webhook echoes arriving
+	// while the HTTP round-trip is in flight are already recognized as self-sent.
+	parts := splitMessage(formatOutbound(msg.Content), dmMaxBytes)
+	for _, part := range parts {
+		ch.rememberOutboundEcho(msg.ChatID, part)
+	}
+	for i, part := range parts {
+		if _, err := ch.graphClient.SendMessage(ctx, msg.ChatID, part); err != nil {
+			for _, unsent := range parts[i:] {
+				ch.forgetOutboundEcho(msg.ChatID, unsent)
+			}
+			ch.handleAPIError(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// sendCommentReply answers a comment publicly, or privately by DM when
+// comment_to_dm is enabled.
+func (ch *Channel) sendCommentReply(ctx context.Context, msg bus.OutboundMessage) error {
+	commentID := msg.Metadata["reply_to_comment_id"]
+	if commentID == "" {
+		return fmt.Errorf("instagram: reply_to_comment_id missing in outbound metadata")
+	}
+
+	if ch.config.Features.CommentToDM {
+		return ch.sendPrivateReply(ctx, commentID, msg.Metadata["sender_id"], msg.Content)
+	}
+
+	body := map[string]string{"message": truncateRunes(formatOutbound(msg.Content), commentMaxChars)}
+	if _, err := ch.graphClient.Do(ctx, http.MethodPost, "/"+commentID+"/replies", body); err != nil {
+		ch.handleAPIError(err)
+		return err
+	}
+	return nil
+}
+
+// sendPrivateReply sends the reply to a comment as a DM to its author. Meta
+// allows a single private reply per comment, so later replies for the same
+// comment (e.g. block replies) are dropped. The user's answer continues the
+// conversation as a regular DM.
+func (ch *Channel) sendPrivateReply(ctx context.Context, commentID, senderID, content string) error {
+	key := "private_reply:" + commentID
+	if ch.isDup(key) {
+		slog.Info("instagram: comment already answered by private reply", "comment_id", commentID)
+		return nil
+	}
+	text := truncateBytes(formatOutbound(content), dmMaxBytes)
+	ch.rememberOutboundEcho(senderID, text)
+	if _, err := ch.graphClient.SendPrivateReply(ctx, commentID, text); err != nil {
+		ch.dedup.Delete(key) // allow retry
+		ch.forgetOutboundEcho(senderID, text)
+		ch.handleAPIError(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package instagram implements the Instagram Direct Messages channel for GoClaw.
+// Supports: DM auto-reply (including story replies and mentions), comment
+// auto-reply and comment-to-DM private replies for Instagram professional
+// accounts linked to a Facebook Page. Webhooks arrive on the facebook
+// package's shared Meta endpoint; replies go through its Graph client.
+package instagram
+
+// instagramCreds holds encrypted credentials stored in channel_instances.credentials.
+type instagramCreds struct {
+	PageAccessToken string `json:"page_access_token"`
+	AppSecret       string `json:"app_secret"`
+	VerifyToken     string `json:"verify_token"`
+}
+
+// instagramInstanceConfig holds non-secret config from channel_instances.config JSONB.
+type instagramInstanceConfig struct {
+	PageID string `json:"page_id"` // Facebook Page linked to the Instagram account
+	// InstagramAccountID is the Instagram professional account ID (webhook entry ID).
+	// Resolved from the page at startup when empty.
+	InstagramAccountID string `json:"instagram_account_id,omitempty"`
+	Features           struct {
+		DMAutoReply  bool `json:"dm_auto_reply"`
+		CommentReply bool `json:"comment_reply"`
+		// CommentToDM answers comments with a private DM instead of a public reply.
+		CommentToDM bool `json:"comment_to_dm"`
+	} `json:"features"`
+	DMOptions struct {
+		SessionTimeout string `json:"session_timeout"`
+	} `json:"dm_options"`
+	AllowFrom  []string `json:"allow_from,omitempty"`
+	BlockReply *bool    `json:"block_reply,omitempty"`
+}
+
+// --- Webhook payloads ---
+
+// messagingEvent is a single Instagram messaging webhook event.
+type messagingEvent struct {
+	Sender    igUser     `json:"sender"`
+	Recipient igUser     `json:"recipient"`
+	Timestamp int64      `json:"timestamp"`
+	Message   *igMessage `json:"message,omitempty"`
+	Postback  *struct {
+		MID     string `json:"mid"`
+		Title   string `json:"title"`
+		Payload string `json:"payload"`
+	} `json:"postback,omitempty"`
+}
+
+// igMessage holds a DM with text, attachments and/or a story reply.
+type igMessage struct {
+	MID           string         `json:"mid"`
+	Text          string         `json:"text"`
+	IsEcho        bool           `json:"is_echo,omitempty"`
+	IsDeleted     bool           `json:"is_deleted,omitempty"`
+	IsUnsupported bool           `json:"is_unsupported,omitempty"`
+	Attachments   []igAttachment `json:"attachments,omitempty"`
+	ReplyTo       *struct {
+		MID   string `json:"mid,omitempty"`
+		Story *struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"story,omitempty"`
+	} `json:"reply_to,omitempty"`
+}
+
+// igAttachment is a DM attachment. Type is "image", "video", "audio", "file",
+// "share", "story_mention", "ig_reel" or "reel".
+type igAttachment struct {
+	Type    string `json:"type"`
+	Payload struct {
+		URL   string `json:"url"`
+		Title string `json:"title,omitempty"`
+	} `json:"payload"`
+}
+
+// commentValue is the value of a "comments" change.
+type commentValue struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id,omitempty"`
+	Text     string `json:"text"`
+	From     struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Media struct {
+		ID               string `json:"id"`
+		MediaProductType string `json:"media_product_type"` // "FEED", "REELS", "AD"
+	} `json:"media"`
+}
+
+// igUser is a minimal Instagram-scoped user reference.
+type igUser struct {
+	ID string `json:"id"`
+}
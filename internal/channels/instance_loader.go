@@ -43,6 +43,7 @@ type InstanceLoader struct {
 	manager           *Manager
 	msgBus            *bus.MessageBus
 	pairingSvc        store.PairingStore
+	contactStore      store.ContactStore
 	mu                sync.Mutex
 	loaded            map[string]struct{} // channel names managed by this loader
 }
@@ -72,6 +73,12 @@ func (l *InstanceLoader) SetProviderRegistry(reg *providers.Registry) {
 	l.providerReg = reg
 }
 
+// SetContactStore sets the contact store used by routing rules (contact tags).
+// Must be called before LoadAll/Reload.
+func (l *InstanceLoader) SetContactStore(cs store.ContactStore) {
+	l.contactStore = cs
+}
+
 // SetPendingCompactionConfig sets the global pending message compaction thresholds.
 // Must be called before LoadAll/Reload.
 func (l *InstanceLoader) SetPendingCompactionConfig(cfg *config.PendingCompactionConfig) {
@@ -329,6 +336,14 @@ func (l *InstanceLoader) loadInstance(ctx context.Context, inst store.ChannelIns
 				"channel", inst.Name, "agent_id", inst.AgentID, "attempted_provider", attemptedProvider)
 		}
 	}
+
+	// Wire rule-based multi-agent routing (config.routing).
+	router, err := l.buildAgentRouter(instCtx, inst, cfg, ag)
+	if err != nil {
+		l.manager.RecordFailureForType(inst.Name, inst.ChannelType, "", err)
+		return err
+	}
+	l.manager.SetAgentRouter(inst.Name, router)
 	l.manager.RegisterChannel(inst.Name, ch)
 
 	// Start the channel if requested (Reload path). LoadAll defers to StartAll.
@@ -346,6 +361,50 @@ func (l *InstanceLoader) loadInstance(ctx context.Context, inst store.ChannelIns
 	return nil
 }
 
+// buildAgentRouter compiles the instance's routing block. Returns nil when the
+// instance has no routing configured (all messages go to the instance agent).
+func (l *InstanceLoader) buildAgentRouter(ctx context.Context, inst store.ChannelInstanceData, cfg json.RawMessage, ag *store.AgentData) (*AgentRouter, error) {
+	rc, err := ParseRoutingConfig(cfg)
+	if err != nil || rc == nil || ag == nil {
+		return nil, err
+	}
+	resolve := func(ref string) (*store.AgentData, error) {
+		if id, err := uuid.Parse(ref); err == nil {
+			return l.agentStore.GetByID(ctx, id)
+		}
+		return l.agentStore.GetByKey(ctx, ref)
+	}
+	router, err := NewAgentRouter(rc, inst.ChannelType, ag.AgentKey, resolve)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s: %w", inst.Name, err)
+	}
+	if l.contactStore != nil {
+		router.SetContactStore(l.contactStore)
+	}
+
+	// Triage provider: routing config > default agent's provider.
+	if router.HasTriage() && l.providerReg != nil {
+		var p providers.Provider
+		model := rc.Triage.Model
+		if rc.Triage.Provider != "" {
+			p, _ = l.providerReg.Get(ctx, rc.Triage.Provider)
+		} else if ap, err := providerresolve.ResolveConfiguredProvider(l.providerReg, ag); err == nil {
+			p = ap
+			if model == "" {
+				model = ag.Model
+			}
+		}
+		if p != nil {
+			router.SetTriageProvider(p, model)
+		} else {
+			slog.Warn("routing triage disabled: provider unavailable",
+				"channel", inst.Name, "provider", rc.Triage.Provider)
+		}
+	}
+	slog.Info("channel routing configured", "channel", inst.Name, "rules", len(rc.Rules), "triage", router.HasTriage())
+	return router, nil
+}
+
 // startChannelWithTimeout runs ch.Start(ctx) in a goroutine and waits up to
 // reloadStartTimeout for it to return. On timeout we stop the partially-started
 // channel and record a failure so Reload() can move on to the next instance.
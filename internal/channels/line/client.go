@@ -0,0 +1,236 @@
+package line
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const (
+	defaultAPIBase     = "https://api.line.me"
+	defaultDataAPIBase = "https://api-data.line.me"
+)
+
+// apiError is a non-2xx response from the Messaging API.
+type apiError struct {
+	StatusCode int
+	Message    string
+	Details    []struct {
+		Message  string `json:"message"`
+		Property string `json:"property"`
+	}
+	RetryAfter time.Duration `json:"-"` // from the Retry-After header on 429s
+}
+
+func (e *apiError) Error() string {
+	msg := fmt.Sprintf("line API error %d: %s", e.StatusCode, e.Message)
+	for _, d := range e.Details {
+		msg += fmt.Sprintf(" (%s: %s)", d.Property, d.Message)
+	}
+	return msg
+}
+
+func asAPIError(err error) *apiError {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return ae
+	}
+	return nil
+}
+
+func isAuthError(err error) bool {
+	ae := asAPIError(err)
+	return ae != nil && ae.StatusCode == http.StatusUnauthorized
+}
+
+// isQuotaError reports whether a push failed because the monthly free
+// message quota (or the plan's additional-message cap) is exhausted.
+func isQuotaError(err error) bool {
+	ae := asAPIError(err)
+	return ae != nil && ae.StatusCode == http.StatusTooManyRequests &&
+		strings.Contains(strings.ToLower(ae.Message), "monthly limit")
+}
+
+// isInvalidReplyToken reports whether a reply failed because the token expired or was used.
+func isInvalidReplyToken(err error) bool {
+	ae := asAPIError(err)
+	return ae != nil && ae.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(ae.Message), "reply token")
+}
+
+// apiClient is a minimal LINE Messaging API client.
+type apiClient struct {
+	baseURL     string
+	dataBaseURL string
+	token       string
+	http        *http.Client
+}
+
+func newAPIClient(token string) *apiClient {
+	return &apiClient{
+		baseURL:     defaultAPIBase,
+		dataBaseURL: defaultDataAPIBase,
+		token:       token,
+		http:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *apiClient) botInfo(ctx context.Context) (*botInfo, error) {
+	var info botInfo
+	if err := c.do(ctx, http.MethodGet, c.baseURL+"/v2/bot/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// profile returns a user's profile; in groups and rooms the member endpoint
+// works for users who have not added the bot as a friend.
+func (c *apiClient) profile(ctx context.Context, src source) (*profile, error) {
+	var u string
+	switch src.Type {
+	case "group":
+		u = fmt.Sprintf("%s/v2/bot/group/%s/member/%s", c.baseURL, url.PathEscape(src.GroupID), url.PathEscape(src.UserID))
+	case "room":
+		u = fmt.Sprintf("%s/v2/bot/room/%s/member/%s", c.baseURL, url.PathEscape(src.RoomID), url.PathEscape(src.UserID))
+	default:
+		u = c.baseURL + "/v2/bot/profile/" + url.PathEscape(src.UserID)
+	}
+	var p profile
+	if err := c.do(ctx, http.MethodGet, u, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// reply answers an event with up to 5 messages. Replies are free.
+func (c *apiClient) reply(ctx context.Context, replyToken string, msgs []sendMessage) error {
+	return c.do(ctx, http.MethodPost, c.baseURL+"/v2/bot/message/reply",
+		map[string]any{"replyToken": replyToken, "messages": msgs}, nil)
+}
+
+// push sends up to 5 messages at any time. Pushes count against the monthly quota.
+// Outbox sends carry an X-Line-Retry-Key; LINE answers a repeated key with 409,
+// meaning an earlier attempt was already delivered.
+func (c *apiClient) push(ctx context.Context, to string, msgs []sendMessage) error {
+	var header http.Header
+	retryKey := channels.NextIdempotencyKey(ctx)
+	if retryKey != "" {
+		header = http.Header{"X-Line-Retry-Key": {retryKey}}
+	}
+	err := c.doWithHeader(ctx, http.MethodPost, c.baseURL+"/v2/bot/message/push",
+		map[string]any{"to": to, "messages": msgs}, nil, header)
+	if ae := asAPIError(err); ae != nil && ae.StatusCode == http.StatusConflict && retryKey != "" {
+		return nil
+	}
+	return err
+}
+
+// startLoading shows the loading animation in a one-on-one chat.
+func (c *apiClient) startLoading(ctx context.Context, userID string, seconds int) error {
+	return c.do(ctx, http.MethodPost, c.baseURL+"/v2/bot/chat/loading/start",
+		map[string]any{"chatId": userID, "loadingSeconds": seconds}, nil)
+}
+
+// quotaExhausted reports whether this month's push quota is used up.
+func (c *apiClient) quotaExhausted(ctx context.Context) (bool, error) {
+	var quota struct {
+		Type  string `json:"type"` // none (unlimited) or limited
+		Value int64  `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.baseURL+"/v2/bot/message/quota", nil, &quota); err != nil {
+		return false, err
+	}
+	if quota.Type != "limited" {
+		return false, nil
+	}
+	var usage struct {
+		TotalUsage int64 `json:"totalUsage"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.baseURL+"/v2/bot/message/quota/consumption", nil, &usage); err != nil {
+		return false, err
+	}
+	return usage.TotalUsage >= quota.Value, nil
+}
+
+// content downloads the binary content of an image, video, audio or file message.
+func (c *apiClient) content(ctx context.Context, messageID string, maxBytes int64) ([]byte, string, error) {
+	u := c.dataBaseURL + "/v2/bot/message/" + url.PathEscape(messageID) + "/content"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", decodeError(resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("content exceeds %d bytes", maxBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (c *apiClient) do(ctx context.Context, method, u string, body, out any) error {
+	return c.doWithHeader(ctx, method, u, body, out, nil)
+}
+
+func (c *apiClient) doWithHeader(ctx context.Context, method, u string, body, out any, header http.Header) error {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+func decodeError(resp *http.Response) error {
+	ae := &apiError{StatusCode: resp.StatusCode, RetryAfter: channels.RetryAfterHeader(resp.Header)}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(body, ae) != nil || ae.Message == "" {
+		ae.Message = strings.TrimSpace(string(body))
+	}
+	return ae
+}
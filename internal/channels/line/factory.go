@@ -0,0 +1,79 @@
+package line
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// lineCreds maps the credentials JSON from the channel_instances table.
+// Both values come from the LINE Developers console (Messaging API tab).
+type lineCreds struct {
+	ChannelSecret      string `json:"channel_secret"`
+	ChannelAccessToken string `json:"channel_access_token"`
+}
+
+// lineInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type lineInstanceConfig struct {
+	DMPolicy       string   `json:"dm_policy,omitempty"`
+	GroupPolicy    string   `json:"group_policy,omitempty"`
+	AllowFrom      []string `json:"allow_from,omitempty"` // user IDs (U...) or group/room IDs (C.../R...)
+	RequireMention *bool    `json:"require_mention,omitempty"`
+	HistoryLimit   int      `json:"history_limit,omitempty"`
+	RenderMode     string   `json:"render_mode,omitempty"` // auto, raw, flex
+	// QuickReplies are attached to every reply as quick reply buttons.
+	QuickReplies []string `json:"quick_replies,omitempty"`
+	// SuggestQuickReplies turns a trailing list of short options after a
+	// question into quick reply buttons (default true).
+	SuggestQuickReplies *bool `json:"suggest_quick_replies,omitempty"`
+	LoadingAnimation    *bool `json:"loading_animation,omitempty"` // loading indicator in 1:1 chats (default true)
+	MediaMaxMB          int   `json:"media_max_mb,omitempty"`
+	BlockReply          *bool `json:"block_reply,omitempty"`
+}
+
+// Factory creates a LINE channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return buildChannel(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return buildChannel(name, creds, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func buildChannel(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var c lineCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode line credentials: %w", err)
+		}
+	}
+
+	var ic lineInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode line config: %w", err)
+		}
+	}
+
+	// Secure default: DB instances default to "pairing" for groups.
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "pairing"
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
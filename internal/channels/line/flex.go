@@ -0,0 +1,224 @@
+package line
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxAltTextLen      = 400
+	maxQuickReplyItems = 13
+	maxQuickReplyLabel = 20
+)
+
+// shouldUseFlex detects if content benefits from Flex rendering (code blocks, tables, headings).
+func shouldUseFlex(text string) bool {
+	if strings.Contains(text, "```") || strings.Contains(text, "|---") || strings.Contains(text, "| ---") {
+		return true
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		if headingLevel(strings.TrimSpace(line)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFlexMessage renders markdown into a Flex bubble. LINE text has no
+// markdown, so emphasis markers are stripped; headings become bold text,
+// fenced code a shaded box and tables rows of equal-width cells.
+func buildFlexMessage(markdown string) sendMessage {
+	var body []any
+	var para []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(para, "\n")); text != "" {
+			body = append(body, flexText(stripInlineMarkdown(text)))
+		}
+		para = nil
+	}
+
+	lines := strings.Split(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			t := flexText(strings.Join(code, "\n"))
+			t["size"] = "xs"
+			t["color"] = "#333333"
+			body = append(body, map[string]any{
+				"type":            "box",
+				"layout":          "vertical",
+				"backgroundColor": "#F0F0F0",
+				"cornerRadius":    "md",
+				"paddingAll":      "md",
+				"contents":        []any{t},
+			})
+		case headingLevel(trimmed) > 0:
+			flush()
+			t := flexText(stripInlineMarkdown(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+			t["weight"] = "bold"
+			if headingLevel(trimmed) <= 2 {
+				t["size"] = "lg"
+			}
+			body = append(body, t)
+		case isTableRow(trimmed) && i+1 < len(lines) && isTableSeparator(strings.TrimSpace(lines[i+1])):
+			flush()
+			body = append(body, flexTableRow(splitTableRow(trimmed), true))
+			for i += 2; i < len(lines) && isTableRow(strings.TrimSpace(lines[i])); i++ {
+				body = append(body, flexTableRow(splitTableRow(strings.TrimSpace(lines[i])), false))
+			}
+			i--
+		default:
+			para = append(para, lines[i])
+		}
+	}
+	flush()
+
+	return sendMessage{
+		Type:    "flex",
+		AltText: altText(markdown),
+		Contents: map[string]any{
+			"type": "bubble",
+			"size": "giga",
+			"body": map[string]any{
+				"type":     "box",
+				"layout":   "vertical",
+				"spacing":  "md",
+				"contents": body,
+			},
+		},
+	}
+}
+
+func flexText(text string) map[string]any {
+	if text == "" {
+		text = " " // Flex rejects empty text components
+	}
+	return map[string]any{"type": "text", "text": text, "wrap": true, "size": "sm"}
+}
+
+func flexTableRow(cells []string, header bool) map[string]any {
+	contents := make([]any, 0, len(cells))
+	for _, c := range cells {
+		t := flexText(stripInlineMarkdown(c))
+		t["flex"] = 1
+		if header {
+			t["weight"] = "bold"
+		}
+		contents = append(contents, t)
+	}
+	return map[string]any{"type": "box", "layout": "horizontal", "spacing": "sm", "contents": contents}
+}
+
+var (
+	boldRe   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicRe = regexp.MustCompile(`(^|[^*])\*([^*\n]+)\*`)
+	codeRe   = regexp.MustCompile("`([^`\n]+)`")
+	linkRe   = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// stripInlineMarkdown removes emphasis and code markers and expands links
+// to "text (url)" since LINE renders text literally.
+func stripInlineMarkdown(s string) string {
+	s = linkRe.ReplaceAllString(s, "$1 ($2)")
+	s = boldRe.ReplaceAllString(s, "$1$2")
+	s = italicRe.ReplaceAllString(s, "$1$2")
+	return codeRe.ReplaceAllString(s, "$1")
+}
+
+func altText(markdown string) string {
+	s := strings.Join(strings.Fields(stripInlineMarkdown(strings.ReplaceAll(markdown, "```", ""))), " ")
+	return truncateRunes(s, maxAltTextLen)
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
+
+func headingLevel(line string) int {
+	hashes := len(line) - len(strings.TrimLeft(line, "#"))
+	if hashes >= 1 && hashes <= 6 && len(line) > hashes && line[hashes] == ' ' {
+		return hashes
+	}
+	return 0
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(line, "|") && strings.HasSuffix(line, "|") && len(line) > 1
+}
+
+func isTableSeparator(line string) bool {
+	return isTableRow(line) && strings.Trim(line, "|-: ") == ""
+}
+
+func splitTableRow(line string) []string {
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return cells
+}
+
+var optionRe = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s+(.+?)\s*$`)
+
+// suggestedOptions extracts quick reply candidates when a reply ends with a
+// question followed by a short list of options, e.g.
+//
+//	Which region?
+//	1. Tokyo
+//	2. Osaka
+//
+// Every option must fit a quick reply label; otherwise nothing is returned.
+func suggestedOptions(text string) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n "), "\n")
+	var opts []string
+	i := len(lines) - 1
+	for ; i >= 0; i-- {
+		m := optionRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+		opt := stripInlineMarkdown(m[1])
+		if utf8.RuneCountInString(opt) > maxQuickReplyLabel {
+			return nil
+		}
+		opts = append([]string{opt}, opts...)
+	}
+	if len(opts) < 2 || len(opts) > maxQuickReplyItems || i < 0 ||
+		!strings.HasSuffix(strings.TrimSpace(lines[i]), "?") && !strings.HasSuffix(strings.TrimSpace(lines[i]), "？") {
+		return nil
+	}
+	return opts
+}
+
+// buildQuickReply turns option texts into message-action quick reply buttons.
+func buildQuickReply(options []string) *quickReply {
+	if len(options) == 0 {
+		return nil
+	}
+	qr := &quickReply{}
+	for _, o := range options {
+		if len(qr.Items) == maxQuickReplyItems {
+			break
+		}
+		qr.Items = append(qr.Items, quickReplyItem{
+			Type: "action",
+			Action: quickReplyAction{
+				Type:  "message",
+				Label: truncateRunes(o, maxQuickReplyLabel),
+				Text:  o,
+			},
+		})
+	}
+	return qr
+}
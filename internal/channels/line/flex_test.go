@@ -0,0 +1,50 @@
+package line
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildFlexMessage(t *testing.T) {
+	msg := buildFlexMessage("## Result\n\nAll **green**.\n\n| Service | Status |\n|---|---|\n| api | ok |\n\n```\nmake test\n```")
+	if msg.Type != "flex" || msg.AltText != "## Result All green. | Service | Status | |---|---| | api | ok | make test" {
+		t.Fatalf("type/altText = %q / %q", msg.Type, msg.AltText)
+	}
+	raw, _ := json.Marshal(msg.Contents)
+	got := string(raw)
+	for _, want := range []string{
+		`"text":"Result","type":"text","weight":"bold"`,
+		`"text":"All green."`,
+		`"layout":"horizontal"`,
+		`"text":"Service","type":"text","weight":"bold"`,
+		`"backgroundColor":"#F0F0F0"`,
+		`"text":"make test"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("flex contents missing %s\n%s", want, got)
+		}
+	}
+}
+
+func TestSuggestedOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"numbered after question", "Which region?\n1. Tokyo\n2. **Osaka**", []string{"Tokyo", "Osaka"}},
+		{"bullets after question", "Continue?\n- Yes\n- No", []string{"Yes", "No"}},
+		{"no question", "Steps:\n1. Install\n2. Run", nil},
+		{"option too long", "Which?\n1. A very long option that cannot fit\n2. B", nil},
+		{"single option", "Ready?\n1. Go", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestedOptions(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("suggestedOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package line
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// handleEvent processes one webhook event.
+func (c *Channel) handleEvent(ctx context.Context, ev *event) {
+	if c.isDuplicate(ev.WebhookEventID) {
+		return
+	}
+	// In standby mode another channel owns the chat (module channels); stay quiet.
+	if ev.Mode == "standby" {
+		return
+	}
+	switch ev.Type {
+	case "message":
+		if ev.Message != nil {
+			c.handleMessage(ctx, ev)
+		}
+	case "join", "follow":
+		slog.Debug("line: bot added", "event", ev.Type, "chat_id", ev.Source.chatID())
+	}
+}
+
+func (c *Channel) handleMessage(ctx context.Context, ev *event) {
+	src := ev.Source
+	senderID := src.UserID
+	chatID := src.chatID()
+	if senderID == "" || chatID == "" || senderID == c.botUserID {
+		return
+	}
+	isDM := src.Type == "user"
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+
+	// Keep the newest reply token: the reply (or a pairing notice) uses it
+	// for free while it is fresh.
+	if ev.ReplyToken != "" {
+		c.replyTokens.Store(chatID, replyToken{token: ev.ReplyToken, receivedAt: time.Now()})
+	}
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, chatID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, chatID) {
+		return
+	}
+
+	msg := ev.Message
+	displayName := c.displayName(ctx, src)
+	mentioned := c.isMentioned(msg)
+
+	var content string
+	var mediaList []media.MediaInfo
+	switch msg.Type {
+	case "text":
+		content = c.stripSelfMentions(msg)
+	case "image", "video", "audio", "file":
+		if m, err := c.downloadContent(ctx, msg); err != nil {
+			slog.Warn("line: content download failed", "message_id", msg.ID, "type", msg.Type, "error", err)
+		} else {
+			mediaList = append(mediaList, m)
+		}
+	case "location":
+		content = strings.TrimSpace(fmt.Sprintf("[Location] %s %s (%.6f, %.6f)", msg.Title, msg.Address, msg.Latitude, msg.Longitude))
+	case "sticker":
+		content = "[Sticker]"
+		if len(msg.Keywords) > 0 {
+			content = "[Sticker: " + strings.Join(msg.Keywords[:min(len(msg.Keywords), 5)], ", ") + "]"
+		}
+	default:
+		return
+	}
+
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	if !isDM && c.RequireMention() && !mentioned {
+		c.GroupHistory().Record(chatID, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: msg.ID,
+		}, c.HistoryLimit())
+
+		// Collect contact even when bot is not mentioned (cache prevents DB spam).
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		slog.Debug("line group message recorded (no mention)",
+			"chat_id", chatID, "user", displayName)
+		return
+	}
+
+	slog.Debug("line message received",
+		"sender_id", senderID, "chat_id", chatID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	finalContent := content
+	if !isDM {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMediaPaths := c.GroupHistory().CollectMedia(chatID); len(histMediaPaths) > 0 {
+				mediaPaths = append(mediaPaths, histMediaPaths...)
+			}
+			finalContent = c.GroupHistory().BuildContext(chatID, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":   msg.ID,
+		"user_id":      senderID,
+		"display_name": channels.SanitizeDisplayName(displayName),
+		"source_type":  src.Type,
+		"is_dm":        fmt.Sprintf("%t", isDM),
+		"platform":     channels.TypeLine,
+	}
+
+	c.HandleMessage(senderID, chatID, finalContent, mediaPaths, metadata, peerKind)
+
+	if !isDM {
+		c.GroupHistory().Clear(chatID)
+	}
+}
+
+// isMentioned reports whether the message mentions the bot.
+func (c *Channel) isMentioned(msg *message) bool {
+	if msg.Mention == nil {
+		return false
+	}
+	return slices.ContainsFunc(msg.Mention.Mentionees, func(m mentionee) bool {
+		return m.IsSelf || (c.botUserID != "" && m.UserID == c.botUserID)
+	})
+}
+
+// stripSelfMentions removes "@BotName" spans from the text. Mention offsets
+// are in UTF-16 code units.
+func (c *Channel) stripSelfMentions(msg *message) string {
+	if msg.Mention == nil {
+		return strings.TrimSpace(msg.Text)
+	}
+	units := utf16.Encode([]rune(msg.Text))
+	mentions := slices.Clone(msg.Mention.Mentionees)
+	// Remove from the end so earlier offsets stay valid.
+	slices.SortFunc(mentions, func(a, b mentionee) int { return b.Index - a.Index })
+	for _, m := range mentions {
+		if !(m.IsSelf || (c.botUserID != "" && m.UserID == c.botUserID)) {
+			continue
+		}
+		if m.Index < 0 || m.Length <= 0 || m.Index+m.Length > len(units) {
+			continue
+		}
+		units = append(units[:m.Index], units[m.Index+m.Length:]...)
+	}
+	return strings.TrimSpace(string(utf16.Decode(units)))
+}
+
+// displayName returns the sender's LINE display name, cached.
+func (c *Channel) displayName(ctx context.Context, src source) string {
+	if v, ok := c.userNames.Load(src.UserID); ok {
+		return v.(string)
+	}
+	p, err := c.api.profile(ctx, src)
+	if err != nil || p.DisplayName == "" {
+		slog.Debug("line: profile lookup failed", "user_id", src.UserID, "error", err)
+		return src.UserID
+	}
+	c.userNames.Store(src.UserID, p.DisplayName)
+	return p.DisplayName
+}
+
+// downloadContent saves an image, video, audio or file message to a temp file.
+func (c *Channel) downloadContent(ctx context.Context, msg *message) (media.MediaInfo, error) {
+	if msg.ContentProvider != nil && msg.ContentProvider.Type == "external" {
+		return media.MediaInfo{}, fmt.Errorf("externally hosted content is not downloaded")
+	}
+	if msg.FileSize > c.maxMedia {
+		return media.MediaInfo{}, fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	data, contentType, err := c.api.content(ctx, msg.ID, c.maxMedia)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(contentType)
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = media.DetectMIMEType(msg.FileName)
+	}
+	ext := filepath.Ext(msg.FileName)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	tmp, err := os.CreateTemp("", "goclaw_line_*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, err
+	}
+	return media.MediaInfo{
+		Type:        media.MediaKindFromMime(mimeType),
+		FilePath:    tmp.Name(),
+		ContentType: mimeType,
+		FileName:    msg.FileName,
+	}, nil
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, chatID)
+	}
+	return false
+}
+
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, chatID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+chatID, chatID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, chatID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), chatID, "default", nil)
+	if err != nil {
+		slog.Warn("line: failed to request pairing code", "error", err)
+		return
+	}
+
+	var msg string
+	if strings.HasPrefix(senderID, "group:") {
+		msg = fmt.Sprintf("This group is not authorized to use this bot.\n\n"+
+			"An admin can approve via CLI:\n  goclaw pairing approve %s\n\n"+
+			"Or approve via the GoClaw web UI (Pairing section).", code)
+	} else {
+		msg = fmt.Sprintf("GoClaw: access not configured.\n\nYour LINE user ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+			senderID, code, code)
+	}
+	if err := c.deliver(ctx, chatID, []sendMessage{{Type: "text", Text: msg}}); err != nil {
+		slog.Warn("line: failed to send pairing reply", "chat_id", chatID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
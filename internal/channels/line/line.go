@@ -0,0 +1,285 @@
+// Package line implements the LINE Messaging API channel: signed webhook
+// events arrive on the gateway's HTTP mux, replies use the free reply token
+// while it is fresh and fall back to (quota-counted) push messages, and rich
+// replies are rendered as Flex messages with optional quick replies.
+package line
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	webhookPath         = "/channels/line/webhook"
+	maxWebhookBodySize  = 1 << 20
+	pairingDebounceTime = 60 * time.Second
+	defaultMediaMaxMB   = 20
+	eventDedupTTL       = 10 * time.Minute
+	// replyTokenTTL is how long a reply token is trusted. LINE does not
+	// guarantee a lifetime; tokens older than this go straight to push.
+	replyTokenTTL = 50 * time.Second
+)
+
+// Channel receives LINE webhook events over the shared webhook route.
+type Channel struct {
+	*channels.BaseChannel
+	config    lineInstanceConfig
+	secret    string
+	api       *apiClient
+	botUserID string // webhook destination, from /v2/bot/info
+	maxMedia  int64
+	loading   bool
+	suggest   bool
+
+	replyTokens  sync.Map // chat ID -> replyToken
+	seenEvents   sync.Map // webhookEventId -> struct{}
+	lastLoading  sync.Map // user ID -> time.Time
+	userNames    sync.Map // user ID -> display name
+	quotaReached atomic.Bool
+
+	runCtx   context.Context
+	cancelFn context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// replyToken is the most recent unused reply token of a chat.
+type replyToken struct {
+	token      string
+	receivedAt time.Time
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new LINE channel from instance config.
+func New(cfg lineInstanceConfig, creds lineCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if creds.ChannelSecret == "" || creds.ChannelAccessToken == "" {
+		return nil, fmt.Errorf("line channel_secret and channel_access_token are required")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeLine, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+
+	ch := &Channel{
+		BaseChannel: base,
+		config:      cfg,
+		secret:      creds.ChannelSecret,
+		api:         newAPIClient(creds.ChannelAccessToken),
+		maxMedia:    int64(cfg.MediaMaxMB) * 1024 * 1024,
+		loading:     cfg.LoadingAnimation == nil || *cfg.LoadingAnimation,
+		suggest:     cfg.SuggestQuickReplies == nil || *cfg.SuggestQuickReplies,
+	}
+	ch.SetPairingService(pairingSvc)
+	ch.SetRequireMention(requireMention)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeLine, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start verifies the access token, resolves the bot's user ID and starts
+// accepting webhook events addressed to it.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("verifying channel access token")
+
+	info, err := c.api.botInfo(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("line authentication failed", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return fmt.Errorf("line auth: %w", err)
+	}
+	c.botUserID = info.UserID
+
+	c.runCtx, c.cancelFn = context.WithCancel(context.Background())
+	globalRouter.register(c.botUserID, c)
+	c.SetRunning(true)
+
+	if exhausted, err := c.api.quotaExhausted(ctx); err == nil && exhausted {
+		c.markQuotaReached(fmt.Errorf("monthly limit reached"))
+	} else {
+		c.MarkHealthy("connected as " + info.DisplayName)
+	}
+	slog.Info("line channel started", "name", c.Name(), "bot", info.DisplayName, "basic_id", info.BasicID)
+	return nil
+}
+
+// Stop stops accepting events and waits for in-flight handlers.
+func (c *Channel) Stop(_ context.Context) error {
+	globalRouter.unregister(c)
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.wg.Wait()
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("line channel stopped", "name", c.Name())
+	return nil
+}
+
+// WebhookHandler returns the shared webhook path and router.
+// Only the first line instance mounts the route; others return ("", nil).
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.route()
+}
+
+// markQuotaReached surfaces an exhausted push quota on the channel health.
+// Replies still work, so the channel is degraded rather than failed.
+func (c *Channel) markQuotaReached(err error) {
+	if c.quotaReached.Swap(true) {
+		return
+	}
+	slog.Warn("line: monthly push message quota reached", "name", c.Name(), "error", err)
+	c.MarkDegraded("LINE monthly message quota reached",
+		"Push messages are rejected until the quota resets or the plan is upgraded; replies within the reply window still work. "+err.Error(),
+		channels.ChannelFailureKindQuota, true)
+}
+
+// clearQuotaReached restores health after a push succeeds again.
+func (c *Channel) clearQuotaReached() {
+	if c.quotaReached.Swap(false) {
+		c.MarkHealthy("push quota available")
+	}
+}
+
+// verifySignature checks X-Line-Signature: base64(HMAC-SHA256(channel secret, body)).
+func (c *Channel) verifySignature(body []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(c.secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// isDuplicate returns true if the webhook event was already processed (redeliveries).
+func (c *Channel) isDuplicate(eventID string) bool {
+	if eventID == "" {
+		return false
+	}
+	if _, loaded := c.seenEvents.LoadOrStore(eventID, struct{}{}); loaded {
+		return true
+	}
+	time.AfterFunc(eventDedupTTL, func() { c.seenEvents.Delete(eventID) })
+	return false
+}
+
+// --- Router ---
+
+// webhookRouter routes webhook deliveries to the channel instance whose bot
+// is the destination. A single HTTP handler is shared across all line
+// channel instances on the same server.
+type webhookRouter struct {
+	mu           sync.RWMutex
+	bots         map[string]*Channel // bot user ID → channel
+	routeHandled bool                // true after first route() call
+}
+
+var globalRouter = &webhookRouter{
+	bots: make(map[string]*Channel),
+}
+
+func (r *webhookRouter) register(botUserID string, ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bots[botUserID] = ch
+}
+
+func (r *webhookRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bots[ch.botUserID] == ch {
+		delete(r.bots, ch.botUserID)
+	}
+}
+
+func (r *webhookRouter) lookup(botUserID string) *Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.bots[botUserID]
+}
+
+// route returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *webhookRouter) route() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return webhookPath, r
+	}
+	return "", nil
+}
+
+// ServeHTTP verifies the signature and hands events to the channel
+// asynchronously; LINE expects a 200 within a couple of seconds.
+func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "read error", http.StatusBadRequest)
+		return
+	}
+	var payload webhookBody
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	ch := r.lookup(payload.Destination)
+	if ch == nil || !ch.verifySignature(body, req.Header.Get("X-Line-Signature")) {
+		slog.Warn("security.line_webhook_signature_invalid",
+			"destination", payload.Destination, "remote_addr", req.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if len(payload.Events) == 0 {
+		return // console "Verify" request
+	}
+	ch.wg.Add(1)
+	go func() {
+		defer ch.wg.Done()
+		for i := range payload.Events {
+			ch.handleEvent(ch.runCtx, &payload.Events[i])
+		}
+	}()
+}
@@ -0,0 +1,210 @@
+package line
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+// stubAPI is a minimal Messaging API stub. pushStatus and pushBody override
+// the push response.
+type stubAPI struct {
+	*channeltest.Server
+	mu         sync.Mutex
+	pushStatus int
+	pushBody   string
+}
+
+func newStubAPI(t *testing.T) *stubAPI {
+	t.Helper()
+	s := &stubAPI{}
+	s.Server = channeltest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"Authentication failed"}`)
+			return
+		}
+		s.mu.Lock()
+		pushStatus, pushBody := s.pushStatus, s.pushBody
+		s.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/v2/bot/info":
+			io.WriteString(w, `{"userId":"Ubot","basicId":"@claw","displayName":"Claw"}`)
+		case r.URL.Path == "/v2/bot/message/quota":
+			io.WriteString(w, `{"type":"none"}`)
+		case strings.HasPrefix(r.URL.Path, "/v2/bot/group/"):
+			io.WriteString(w, `{"userId":"Ualice","displayName":"Alice"}`)
+		case r.URL.Path == "/v2/bot/message/push" && pushStatus != 0:
+			w.WriteHeader(pushStatus)
+			io.WriteString(w, pushBody)
+		default:
+			io.WriteString(w, `{}`)
+		}
+	}))
+	return s
+}
+
+func startTestChannel(t *testing.T, s *stubAPI, cfg lineInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.New()
+	ch, err := New(cfg, lineCreds{ChannelSecret: "secret", ChannelAccessToken: "tok"}, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.api.baseURL = s.URL
+	ch.api.dataBaseURL = s.URL
+	channeltest.Start(t, ch)
+	return ch, mb
+}
+
+// deliver POSTs events to the shared router, signed with secret.
+func deliver(t *testing.T, secret string, events ...event) int {
+	t.Helper()
+	body, _ := json.Marshal(webhookBody{Destination: "Ubot", Events: events})
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req := httptest.NewRequest(http.MethodPost, webhookPath, bytes.NewReader(body))
+	req.Header.Set("X-Line-Signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	globalRouter.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func textEvent(id string, src source, text string, mentions ...mentionee) event {
+	ev := event{
+		Type:           "message",
+		Mode:           "active",
+		Source:         src,
+		WebhookEventID: "ev-" + id,
+		ReplyToken:     "rt-" + id,
+		Message:        &message{ID: id, Type: "text", Text: text},
+	}
+	if len(mentions) > 0 {
+		ev.Message.Mention = &struct {
+			Mentionees []mentionee `json:"mentionees"`
+		}{Mentionees: mentions}
+	}
+	return ev
+}
+
+func TestStart_InvalidToken(t *testing.T) {
+	s := newStubAPI(t)
+	ch, _ := New(lineInstanceConfig{}, lineCreds{ChannelSecret: "secret", ChannelAccessToken: "bad"}, bus.New(), nil, nil)
+	ch.api.baseURL = s.URL
+	if err := ch.Start(context.Background()); !isAuthError(err) {
+		t.Fatalf("Start() error = %v, want auth error", err)
+	}
+}
+
+func TestWebhook_SignatureAndMentionGating(t *testing.T) {
+	s := newStubAPI(t)
+	_, mb := startTestChannel(t, s, lineInstanceConfig{GroupPolicy: "open"})
+	group := source{Type: "group", GroupID: "Cgroup", UserID: "Ualice"}
+
+	if code := deliver(t, "wrong", textEvent("1", group, "hi")); code != http.StatusUnauthorized {
+		t.Fatalf("bad signature status = %d", code)
+	}
+
+	// One event per delivery, in order: the unmentioned message becomes history.
+	deliver(t, "secret", textEvent("2", group, "deploy is red"))
+	channeltest.WaitFor(t, func() bool { return len(s.Requests("", "/v2/bot/group/Cgroup/member/Ualice")) > 0 })
+	time.Sleep(20 * time.Millisecond)
+
+	// "🔥" is two UTF-16 units, so the mention starts at index 3.
+	deliver(t, "secret", textEvent("3", group, "🔥 @Claw why?", mentionee{Index: 3, Length: 5, Type: "user", IsSelf: true}))
+	// Redelivered events are ignored.
+	deliver(t, "secret", textEvent("3", group, "🔥 @Claw why?", mentionee{Index: 3, Length: 5, Type: "user", IsSelf: true}))
+
+	msg := channeltest.Consume(t, mb)
+	if msg.ChatID != "Cgroup" || msg.PeerKind != "group" || msg.SenderID != "Ualice" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "deploy is red") || !strings.Contains(msg.Content, "[From: Alice]\n🔥  why?") {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if extra, ok := channeltest.TryConsume(mb, 100*time.Millisecond); ok {
+		t.Errorf("duplicate event delivered: %+v", extra)
+	}
+}
+
+func TestSend_ReplyTokenThenPush(t *testing.T) {
+	s := newStubAPI(t)
+	ch, mb := startTestChannel(t, s, lineInstanceConfig{DMPolicy: "open"})
+	dm := source{Type: "user", UserID: "Ubob"}
+	ctx := context.Background()
+
+	deliver(t, "secret", textEvent("1", dm, "hello"))
+	channeltest.Consume(t, mb)
+
+	// The fresh reply token answers the first message for free.
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "Ubob", Content: "Which city?\n1. Tokyo\n2. Osaka"}); err != nil {
+		t.Fatal(err)
+	}
+	replies := s.Requests(http.MethodPost, "/v2/bot/message/reply")
+	if len(replies) != 1 || replies[0].JSON()["replyToken"] != "rt-1" {
+		t.Fatalf("replies = %+v", replies)
+	}
+	msgs := replies[0].JSON()["messages"].([]any)
+	qr, _ := json.Marshal(msgs[0].(map[string]any)["quickReply"])
+	if !strings.Contains(string(qr), `"label":"Tokyo"`) || !strings.Contains(string(qr), `"label":"Osaka"`) {
+		t.Errorf("quick reply = %s", qr)
+	}
+
+	// The token is single-use: the next message is pushed.
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "Ubob", Content: "follow-up"}); err != nil {
+		t.Fatal(err)
+	}
+	// An expired token is never tried.
+	ch.replyTokens.Store("Ubob", replyToken{token: "old", receivedAt: time.Now().Add(-time.Minute)})
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "Ubob", Content: "later"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Requests(http.MethodPost, "/v2/bot/message/reply")); n != 1 {
+		t.Errorf("reply calls = %d, want 1", n)
+	}
+	pushes := s.Requests(http.MethodPost, "/v2/bot/message/push")
+	if len(pushes) != 2 || pushes[0].JSON()["to"] != "Ubob" {
+		t.Fatalf("pushes = %+v", pushes)
+	}
+}
+
+func TestSend_QuotaExhaustedDegradesHealth(t *testing.T) {
+	s := newStubAPI(t)
+	ch, _ := startTestChannel(t, s, lineInstanceConfig{})
+	s.mu.Lock()
+	s.pushStatus, s.pushBody = http.StatusTooManyRequests, `{"message":"You have reached your monthly limit."}`
+	s.mu.Unlock()
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "Ubob", Content: "report"}); err == nil {
+		t.Fatal("expected push error")
+	}
+	h := ch.HealthSnapshot()
+	if h.State != channels.ChannelHealthStateDegraded || h.FailureKind != channels.ChannelFailureKindQuota {
+		t.Fatalf("health = %s/%s, want degraded/quota", h.State, h.FailureKind)
+	}
+
+	s.mu.Lock()
+	s.pushStatus = 0
+	s.mu.Unlock()
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "Ubob", Content: "report"}); err != nil {
+		t.Fatal(err)
+	}
+	if h := ch.HealthSnapshot(); h.State != channels.ChannelHealthStateHealthy {
+		t.Errorf("health after recovery = %s", h.State)
+	}
+}
@@ -0,0 +1,151 @@
+package line
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const (
+	maxTextLen         = 5000 // LINE text message limit
+	maxFlexTextLen     = 8000 // keeps a Flex bubble well under the 30 KB JSON limit
+	maxMessagesPerCall = 5
+	loadingSeconds     = 20
+	loadingRefresh     = 15 * time.Second
+)
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers an outbound message to a LINE chat.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("line channel not running")
+	}
+	chatID := msg.ChatID
+	if chatID == "" {
+		return fmt.Errorf("empty chat ID for line send")
+	}
+	content := msg.Content
+	// Media messages need a public HTTPS URL; local files cannot be sent.
+	for _, m := range msg.Media {
+		content = fmt.Sprintf("%s\n\n[File not delivered: %s]", content, filepath.Base(m.URL))
+	}
+	if content == "" {
+		return nil
+	}
+
+	renderMode := c.config.RenderMode
+	if renderMode == "" {
+		renderMode = "auto"
+	}
+	useFlex := renderMode == "flex" || (renderMode == "auto" && shouldUseFlex(content))
+
+	var msgs []sendMessage
+	if useFlex {
+		for _, chunk := range channels.ChunkMarkdown(content, maxFlexTextLen) {
+			msgs = append(msgs, buildFlexMessage(chunk))
+		}
+	} else {
+		for _, chunk := range channels.ChunkMarkdown(content, maxTextLen) {
+			msgs = append(msgs, sendMessage{Type: "text", Text: chunk})
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	options := c.config.QuickReplies
+	if c.suggest {
+		options = append(suggestedOptions(content), options...)
+	}
+	msgs[len(msgs)-1].QuickReply = buildQuickReply(options)
+
+	return c.deliver(ctx, chatID, msgs)
+}
+
+// deliver sends messages in batches of five. The first batch uses the chat's
+// reply token when it is still fresh; everything else is pushed.
+func (c *Channel) deliver(ctx context.Context, chatID string, msgs []sendMessage) error {
+	for start := 0; start < len(msgs); start += maxMessagesPerCall {
+		batch := msgs[start:min(start+maxMessagesPerCall, len(msgs))]
+
+		if v, ok := c.replyTokens.LoadAndDelete(chatID); ok {
+			rt := v.(replyToken)
+			if time.Since(rt.receivedAt) < replyTokenTTL {
+				err := c.api.reply(ctx, rt.token, batch)
+				if err == nil {
+					continue
+				}
+				if !isInvalidReplyToken(err) {
+					return c.sendError(err)
+				}
+				slog.Debug("line: reply token rejected, falling back to push", "chat_id", chatID)
+			}
+		}
+
+		if err := c.api.push(ctx, chatID, batch); err != nil {
+			return c.sendError(err)
+		}
+		c.clearQuotaReached()
+	}
+	return nil
+}
+
+// sendError maps Messaging API failures to channel health.
+func (c *Channel) sendError(err error) error {
+	switch {
+	case isQuotaError(err):
+		c.markQuotaReached(err)
+	case isAuthError(err):
+		c.MarkFailed("line access token rejected", err.Error(), channels.ChannelFailureKindAuth, false)
+	}
+	return fmt.Errorf("send line message: %w", err)
+}
+
+// ClassifySendError implements channels.SendErrorClassifier. An exhausted
+// monthly quota does not recover within the retry window, so those pushes
+// are dead-lettered for replay; other failures are classified by status.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	if isQuotaError(err) {
+		return channels.SendFailure{Permanent: true}
+	}
+	if ae := asAPIError(err); ae != nil {
+		if f, ok := channels.ClassifyHTTPStatus(ae.StatusCode, ae.RetryAfter); ok {
+			return f
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// OnReactionEvent shows the loading animation in one-on-one chats while the
+// agent works. LINE has no reactions or group typing indicator.
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, _ string, status string) error {
+	if !c.loading || status == "done" || status == "error" || !isUserID(chatID) {
+		return nil
+	}
+	if v, ok := c.lastLoading.Load(chatID); ok && time.Since(v.(time.Time)) < loadingRefresh {
+		return nil
+	}
+	c.lastLoading.Store(chatID, time.Now())
+	if err := c.api.startLoading(ctx, chatID, loadingSeconds); err != nil {
+		slog.Debug("line: loading animation failed", "chat_id", chatID, "error", err)
+	}
+	return nil
+}
+
+// ClearReaction forgets the loading throttle; the animation ends when the reply arrives.
+func (c *Channel) ClearReaction(_ context.Context, chatID string, _ string) error {
+	c.lastLoading.Delete(chatID)
+	return nil
+}
+
+// isUserID reports whether a chat ID is a user (U...) rather than a group (C...) or room (R...).
+func isUserID(chatID string) bool {
+	return len(chatID) > 0 && chatID[0] == 'U'
+}
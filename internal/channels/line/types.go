@@ -0,0 +1,104 @@
+package line
+
+// webhookBody is the payload LINE POSTs to the webhook URL.
+type webhookBody struct {
+	Destination string  `json:"destination"` // bot user ID the events are for
+	Events      []event `json:"events"`
+}
+
+type event struct {
+	Type           string   `json:"type"` // message, follow, join, postback, ...
+	Mode           string   `json:"mode"` // active, standby
+	Timestamp      int64    `json:"timestamp"`
+	Source         source   `json:"source"`
+	WebhookEventID string   `json:"webhookEventId"`
+	ReplyToken     string   `json:"replyToken,omitempty"`
+	Message        *message `json:"message,omitempty"`
+	Postback       *struct {
+		Data string `json:"data"`
+	} `json:"postback,omitempty"`
+	DeliveryContext struct {
+		IsRedelivery bool `json:"isRedelivery"`
+	} `json:"deliveryContext"`
+}
+
+type source struct {
+	Type    string `json:"type"` // user, group, room
+	UserID  string `json:"userId,omitempty"`
+	GroupID string `json:"groupId,omitempty"`
+	RoomID  string `json:"roomId,omitempty"`
+}
+
+// chatID returns the ID replies and pushes are addressed to.
+func (s source) chatID() string {
+	switch s.Type {
+	case "group":
+		return s.GroupID
+	case "room":
+		return s.RoomID
+	}
+	return s.UserID
+}
+
+type message struct {
+	ID              string   `json:"id"`
+	Type            string   `json:"type"` // text, image, video, audio, file, location, sticker
+	Text            string   `json:"text,omitempty"`
+	FileName        string   `json:"fileName,omitempty"`
+	FileSize        int64    `json:"fileSize,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Address         string   `json:"address,omitempty"`
+	Latitude        float64  `json:"latitude,omitempty"`
+	Longitude       float64  `json:"longitude,omitempty"`
+	Keywords        []string `json:"keywords,omitempty"`
+	ContentProvider *struct {
+		Type string `json:"type"` // line, external
+	} `json:"contentProvider,omitempty"`
+	Mention *struct {
+		Mentionees []mentionee `json:"mentionees"`
+	} `json:"mention,omitempty"`
+}
+
+type mentionee struct {
+	Index  int    `json:"index"`  // UTF-16 offset in text
+	Length int    `json:"length"` // UTF-16 length
+	Type   string `json:"type"`   // user, all
+	UserID string `json:"userId,omitempty"`
+	IsSelf bool   `json:"isSelf,omitempty"`
+}
+
+// botInfo is returned by GET /v2/bot/info.
+type botInfo struct {
+	UserID      string `json:"userId"`
+	BasicID     string `json:"basicId"`
+	DisplayName string `json:"displayName"`
+}
+
+type profile struct {
+	UserID      string `json:"userId"`
+	DisplayName string `json:"displayName"`
+}
+
+// sendMessage is an outbound message object (text or flex).
+type sendMessage struct {
+	Type       string      `json:"type"`
+	Text       string      `json:"text,omitempty"`
+	AltText    string      `json:"altText,omitempty"`
+	Contents   any         `json:"contents,omitempty"`
+	QuickReply *quickReply `json:"quickReply,omitempty"`
+}
+
+type quickReply struct {
+	Items []quickReplyItem `json:"items"`
+}
+
+type quickReplyItem struct {
+	Type   string           `json:"type"` // action
+	Action quickReplyAction `json:"action"`
+}
+
+type quickReplyAction struct {
+	Type  string `json:"type"` // message
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
@@ -56,6 +56,9 @@ type Manager struct {
 	dispatchTask     *asyncTask
 	mu               sync.RWMutex
 	contactCollector *store.ContactCollector
+	commands         *CommandRegistry
+	outbox           *outbox                 // nil = direct dispatch without persistence
+	routers          map[string]*AgentRouter // channel name → rule-based agent routing
 }
 
 type asyncTask struct {
@@ -83,6 +86,9 @@ func (m *Manager) StartAll(ctx context.Context) error {
 	dispatchCtx, cancel := context.WithCancel(ctx)
 	m.dispatchTask = &asyncTask{cancel: cancel}
 	go m.dispatchOutbound(dispatchCtx)
+	if m.outbox != nil {
+		go m.runOutbox(dispatchCtx)
+	}
 
 	if len(m.channels) == 0 {
 		slog.Warn("no channels enabled")
@@ -183,6 +189,11 @@ func (m *Manager) RegisterChannel(name string, channel Channel) {
 			bc.SetContactCollector(m.contactCollector)
 		}
 	}
+	if m.commands != nil {
+		if bc, ok := channel.(interface{ SetCommandRegistry(*CommandRegistry) }); ok {
+			bc.SetCommandRegistry(m.commands)
+		}
+	}
 	m.channels[name] = channel
 	if hc, ok := channel.(interface{ MarkRegistered(string) }); ok {
 		hc.MarkRegistered("Configured")
@@ -267,6 +278,18 @@ func (m *Manager) SetContactCollector(cc *store.ContactCollector) {
 	}
 }
 
+// SetCommandRegistry enables shared chat commands on all current and future channels.
+func (m *Manager) SetCommandRegistry(r *CommandRegistry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = r
+	for _, ch := range m.channels {
+		if bc, ok := ch.(interface{ SetCommandRegistry(*CommandRegistry) }); ok {
+			bc.SetCommandRegistry(r)
+		}
+	}
+}
+
 // ChannelTypeForName returns the platform type for a channel instance name.
 // Reads directly from the Channel.Type() method — no separate map needed.
 func (m *Manager) ChannelTypeForName(name string) string {
@@ -309,12 +332,28 @@ func (m *Manager) ListGroupMembers(ctx context.Context, channelName, chatID stri
 	return gmp.ListGroupMembers(ctx, chatID)
 }
 
+// SendApprovalRequest delivers an approval request to its originating chat.
+func (m *Manager) SendApprovalRequest(ctx context.Context, req ApprovalRequest) error {
+	m.mu.RLock()
+	ch, ok := m.channels[req.Channel]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("channel %q not found", req.Channel)
+	}
+	as, ok := ch.(ApprovalSender)
+	if !ok {
+		return fmt.Errorf("channel %q does not support approval requests", req.Channel)
+	}
+	return as.SendApprovalRequest(ctx, req)
+}
+
 // UnregisterChannel removes a channel from the manager.
 func (m *Manager) UnregisterChannel(name string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.channels, name)
 	delete(m.health, name)
+	delete(m.routers, name)
 }
 
 func (m *Manager) recordHealthLocked(name string, snapshot ChannelHealth) {
@@ -0,0 +1,93 @@
+package channels
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdBoldRe   = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	mdItalicRe = regexp.MustCompile(`(^|[^*\w])[*_]([^*_\n]+)[*_]`)
+	mdCodeRe   = regexp.MustCompile("`([^`\n]+)`")
+	mdLinkRe   = regexp.MustCompile(`\[([^\]\n]+)\]\((https?://[^)\s]+)\)`)
+	mdHeadRe   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdListRe   = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.*)$`)
+)
+
+// MarkdownToHTML renders the common Markdown subset agents produce
+// (headings, lists, code fences, emphasis, links) as basic HTML for channels
+// whose clients display HTML bodies (email, Matrix).
+func MarkdownToHTML(md string) string {
+	var sb strings.Builder
+	lines := strings.Split(md, "\n")
+	inCode, inList := false, false
+	var para []string
+	flushPara := func() {
+		if len(para) > 0 {
+			sb.WriteString("<p>" + strings.Join(para, "<br>") + "</p>")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			sb.WriteString("</ul>")
+			inList = false
+		}
+	}
+	for _, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "```") {
+			flushPara()
+			closeList()
+			if inCode {
+				sb.WriteString("</pre>")
+			} else {
+				sb.WriteString("<pre>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			sb.WriteString(html.EscapeString(l) + "\n")
+			continue
+		}
+		if strings.TrimSpace(l) == "" {
+			flushPara()
+			closeList()
+			continue
+		}
+		if m := mdHeadRe.FindStringSubmatch(l); m != nil {
+			flushPara()
+			closeList()
+			tag := "h" + string(rune('0'+min(len(m[1])+1, 6)))
+			sb.WriteString("<" + tag + ">" + inlineMarkdown(m[2]) + "</" + tag + ">")
+			continue
+		}
+		if m := mdListRe.FindStringSubmatch(l); m != nil {
+			flushPara()
+			if !inList {
+				sb.WriteString("<ul>")
+				inList = true
+			}
+			sb.WriteString("<li>" + inlineMarkdown(m[1]) + "</li>")
+			continue
+		}
+		closeList()
+		para = append(para, inlineMarkdown(l))
+	}
+	if inCode {
+		sb.WriteString("</pre>")
+	}
+	flushPara()
+	closeList()
+	return sb.String()
+}
+
+func inlineMarkdown(s string) string {
+	s = html.EscapeString(s)
+	s = mdCodeRe.ReplaceAllString(s, "<code>$1</code>")
+	s = mdLinkRe.ReplaceAllString(s, `<a href="$2">$1</a>`)
+	s = mdBoldRe.ReplaceAllString(s, "<strong>$1</strong>")
+	s = mdItalicRe.ReplaceAllString(s, "$1<em>$2</em>")
+	return s
+}
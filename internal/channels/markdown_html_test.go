@@ -0,0 +1,16 @@
+package channels
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	got := MarkdownToHTML("# Title\n\n**bold** and `x<y`\n- one\n- [two](https://t.test)\n\n```\na<b\n```")
+	for _, want := range []string{"<h2>Title</h2>", "<strong>bold</strong>", "<code>x&lt;y</code>",
+		"<li>one</li>", `<a href="https://t.test">two</a>`, "<pre>a&lt;b\n</pre>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("MarkdownToHTML missing %q in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// syncTimeout is the server-side long-poll duration for /sync.
+const syncTimeout = 30 * time.Second
+
+// apiError is a Matrix error response ({"errcode": ..., "error": ...}).
+type apiError struct {
+	Status       int
+	ErrCode      string `json:"errcode"`
+	Message      string `json:"error"`
+	RetryAfterMS int64  `json:"retry_after_ms"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("matrix API %d %s: %s", e.Status, e.ErrCode, e.Message)
+}
+
+// isAuthError reports an invalid or expired access token.
+func isAuthError(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && (ae.ErrCode == "M_UNKNOWN_TOKEN" || ae.ErrCode == "M_MISSING_TOKEN" || ae.Status == http.StatusUnauthorized)
+}
+
+// apiClient is a minimal Matrix client-server API client.
+type apiClient struct {
+	homeserver string // base URL without trailing slash
+	token      string
+	http       *http.Client
+	txnPrefix  string
+	txn        atomic.Int64
+}
+
+func newAPIClient(homeserver, token string) *apiClient {
+	return &apiClient{
+		homeserver: strings.TrimRight(homeserver, "/"),
+		token:      token,
+		// Longer than syncTimeout so long-polls complete server-side.
+		http:      &http.Client{Timeout: syncTimeout + 30*time.Second},
+		txnPrefix: strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+// nextTxnID returns a transaction ID unique for this access token's session.
+func (a *apiClient) nextTxnID() string {
+	return a.txnPrefix + "." + strconv.FormatInt(a.txn.Add(1), 10)
+}
+
+// do sends a JSON request and decodes the JSON response into out (if non-nil).
+// Rate-limited requests (M_LIMIT_EXCEEDED) are retried up to twice.
+func (a *apiClient) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	u := a.homeserver + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	for attempt := 0; ; attempt++ {
+		var rd io.Reader
+		if payload != nil {
+			rd = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u, rd)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+a.token)
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := a.http.Do(req)
+		if err != nil {
+			return err
+		}
+		err = decodeResponse(resp, out)
+		var ae *apiError
+		if errors.As(err, &ae) && ae.ErrCode == "M_LIMIT_EXCEEDED" && attempt < 2 {
+			wait := min(time.Duration(max(ae.RetryAfterMS, 500))*time.Millisecond, 10*time.Second)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		return err
+	}
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		ae := &apiError{Status: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, ae) != nil || ae.ErrCode == "" {
+			ae.Message = strings.TrimSpace(string(data))
+		}
+		return ae
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func roomPath(roomID string, rest ...string) string {
+	p := "/_matrix/client/v3/rooms/" + url.PathEscape(roomID)
+	for _, r := range rest {
+		p += "/" + url.PathEscape(r)
+	}
+	return p
+}
+
+// whoami returns the user ID the access token belongs to.
+func (a *apiClient) whoami(ctx context.Context) (string, error) {
+	var out struct {
+		UserID string `json:"user_id"`
+	}
+	if err := a.do(ctx, http.MethodGet, "/_matrix/client/v3/account/whoami", nil, nil, &out); err != nil {
+		return "", err
+	}
+	return out.UserID, nil
+}
+
+// displayName returns a user's profile display name ("" if unset).
+func (a *apiClient) displayName(ctx context.Context, userID string) string {
+	var out struct {
+		DisplayName string `json:"displayname"`
+	}
+	_ = a.do(ctx, http.MethodGet, "/_matrix/client/v3/profile/"+url.PathEscape(userID)+"/displayname", nil, nil, &out)
+	return out.DisplayName
+}
+
+// syncFilter keeps sync payloads small: no presence, lazy-loaded members.
+const syncFilter = `{"presence":{"not_types":["*"]},"room":{"timeline":{"limit":50},"state":{"lazy_load_members":true},"ephemeral":{"not_types":["*"]}}}`
+
+// sync long-polls for events after since ("" = initial sync).
+func (a *apiClient) sync(ctx context.Context, since string, timeout time.Duration) (*syncResponse, error) {
+	q := url.Values{"filter": {syncFilter}, "timeout": {strconv.FormatInt(timeout.Milliseconds(), 10)}}
+	if since != "" {
+		q.Set("since", since)
+	}
+	var out syncResponse
+	if err := a.do(ctx, http.MethodGet, "/_matrix/client/v3/sync", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// sendEvent sends a room event and returns its event ID. Outbox sends use
+// their idempotency key as the transaction ID, so the homeserver returns the
+// original event instead of posting a retried message twice.
+func (a *apiClient) sendEvent(ctx context.Context, roomID, eventType string, content any) (string, error) {
+	var out struct {
+		EventID string `json:"event_id"`
+	}
+	txnID := channels.NextIdempotencyKey(ctx)
+	if txnID == "" {
+		txnID = a.nextTxnID()
+	}
+	err := a.do(ctx, http.MethodPut, roomPath(roomID, "send", eventType, txnID), nil, content, &out)
+	return out.EventID, err
+}
+
+// redact removes an event (used for placeholders and stale reactions).
+func (a *apiClient) redact(ctx context.Context, roomID, eventID string) error {
+	return a.do(ctx, http.MethodPut, roomPath(roomID, "redact", eventID, a.nextTxnID()), nil, map[string]string{}, nil)
+}
+
+// setTyping starts (with a timeout) or stops the typing notification.
+func (a *apiClient) setTyping(ctx context.Context, roomID, userID string, typing bool, timeout time.Duration) error {
+	body := map[string]any{"typing": typing}
+	if typing {
+		body["timeout"] = timeout.Milliseconds()
+	}
+	return a.do(ctx, http.MethodPut, roomPath(roomID, "typing", userID), nil, body, nil)
+}
+
+// joinRoom accepts an invite.
+func (a *apiClient) joinRoom(ctx context.Context, roomID string) error {
+	return a.do(ctx, http.MethodPost, "/_matrix/client/v3/join/"+url.PathEscape(roomID), nil, map[string]string{}, nil)
+}
+
+// upload stores data in the media repository and returns its mxc:// URI.
+func (a *apiClient) upload(ctx context.Context, data []byte, contentType, filename string) (string, error) {
+	u := a.homeserver + "/_matrix/media/v3/upload?" + url.Values{"filename": {filename}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Content-Type", contentType)
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		ContentURI string `json:"content_uri"`
+	}
+	if err := decodeResponse(resp, &out); err != nil {
+		return "", err
+	}
+	return out.ContentURI, nil
+}
+
+// download fetches an mxc:// URI, reading at most maxBytes. It uses the
+// authenticated media endpoint and falls back to the legacy one for older
+// homeservers.
+func (a *apiClient) download(ctx context.Context, mxc string, maxBytes int64) ([]byte, error) {
+	rest, ok := strings.CutPrefix(mxc, "mxc://")
+	server, mediaID, ok2 := strings.Cut(rest, "/")
+	if !ok || !ok2 || server == "" || mediaID == "" {
+		return nil, fmt.Errorf("invalid mxc URI %q", mxc)
+	}
+	suffix := url.PathEscape(server) + "/" + url.PathEscape(mediaID)
+	data, err := a.get(ctx, "/_matrix/client/v1/media/download/"+suffix, maxBytes)
+	var ae *apiError
+	if errors.As(err, &ae) && (ae.Status == http.StatusNotFound || ae.ErrCode == "M_UNRECOGNIZED") {
+		data, err = a.get(ctx, "/_matrix/media/v3/download/"+suffix, maxBytes)
+	}
+	return data, err
+}
+
+func (a *apiClient) get(ctx context.Context, path string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.homeserver+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, decodeResponse(resp, nil)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("media exceeds %d bytes", maxBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,381 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// encryptedNotice is sent once per encrypted room.
+const encryptedNotice = "This room is end-to-end encrypted. GoClaw can only read unencrypted rooms — please message me in an unencrypted room."
+
+// handleEvent processes one timeline event from a joined room.
+func (c *Channel) handleEvent(ctx context.Context, roomID string, rs *roomState, ev event) {
+	if ev.Sender == c.userID {
+		return
+	}
+	switch ev.Type {
+	case "m.room.encrypted":
+		c.warnEncrypted(ctx, roomID)
+		return
+	case "m.room.message":
+	default:
+		return
+	}
+
+	var mc messageContent
+	if err := json.Unmarshal(ev.Content, &mc); err != nil {
+		return
+	}
+	// Edits arrive as new events; the agent already answered the original.
+	if mc.RelatesTo != nil && mc.RelatesTo.RelType == "m.replace" {
+		return
+	}
+
+	senderID := ev.Sender
+	isDM := c.isDirect(roomID, rs)
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, roomID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, roomID) {
+		return
+	}
+
+	displayName := c.memberName(rs, senderID)
+	content := messageText(mc)
+
+	threadRoot := ""
+	if mc.RelatesTo != nil && mc.RelatesTo.RelType == "m.thread" {
+		threadRoot = mc.RelatesTo.EventID
+	}
+	localKey := roomID
+	if threadRoot != "" {
+		localKey = roomID + ":thread:" + threadRoot
+	}
+
+	// Shared chat commands (/reset, /tasks, tenant custom commands, ...).
+	cmdMeta := map[string]string{"local_key": localKey}
+	if threadRoot != "" {
+		cmdMeta["message_thread_id"] = threadRoot
+	}
+	if c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID: senderID,
+		ChatID:   roomID,
+		PeerKind: peerKind,
+		Text:     strings.TrimSpace(c.stripMention(content)),
+		Metadata: cmdMeta,
+	}) {
+		return
+	}
+
+	// Downloaded only now so command messages leave no temp files behind.
+	mediaList := c.downloadMedia(ctx, mc)
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+
+	if !isDM && c.RequireMention() && !c.isMentioned(mc) {
+		c.GroupHistory().Record(localKey, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: ev.EventID,
+		}, c.HistoryLimit())
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		return
+	}
+	content = strings.TrimSpace(c.stripMention(content))
+
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	if !isDM {
+		content = fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			mediaPaths = append(mediaPaths, c.GroupHistory().CollectMedia(localKey)...)
+			content = c.GroupHistory().BuildContext(localKey, content, c.HistoryLimit())
+		}
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":      ev.EventID,
+		"user_id":         senderID,
+		"display_name":    channels.SanitizeDisplayName(displayName),
+		"local_key":       localKey,
+		"placeholder_key": localKey,
+		"platform":        channels.TypeMatrix,
+	}
+	if threadRoot != "" {
+		metadata["message_thread_id"] = threadRoot
+	}
+
+	mediaFiles := make([]bus.MediaFile, 0, len(mediaPaths))
+	for _, m := range mediaList {
+		mediaFiles = append(mediaFiles, bus.MediaFile{Path: m.FilePath, MimeType: m.ContentType, Filename: m.FileName})
+	}
+	for _, p := range mediaPaths[len(mediaList):] {
+		mediaFiles = append(mediaFiles, bus.MediaFile{Path: p, Filename: filepath.Base(p)})
+	}
+
+	slog.Debug("matrix message received", "sender_id", senderID, "room_id", roomID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	c.Bus().PublishInbound(bus.InboundMessage{
+		Channel:  c.Name(),
+		SenderID: senderID,
+		ChatID:   roomID,
+		Content:  content,
+		Media:    mediaFiles,
+		PeerKind: peerKind,
+		UserID:   senderID,
+		AgentID:  c.AgentID(),
+		TenantID: c.TenantID(),
+		Metadata: metadata,
+	})
+	if !isDM && c.HistoryLimit() > 0 {
+		c.GroupHistory().Clear(localKey)
+	}
+}
+
+// messageText returns the text of a message: the body of a text message or
+// the caption of an attachment.
+func messageText(mc messageContent) string {
+	switch mc.MsgType {
+	case "m.text", "m.notice", "m.emote":
+		body := mc.Body
+		if mc.RelatesTo != nil && mc.RelatesTo.InReplyTo != nil {
+			body = stripReplyFallback(body)
+		}
+		if mc.MsgType == "m.emote" {
+			body = "/me " + body
+		}
+		return body
+	case "m.image", "m.file", "m.audio", "m.video":
+		_, caption := attachmentName(mc)
+		return caption
+	default:
+		return mc.Body
+	}
+}
+
+// attachmentName returns an attachment's filename and caption. Body is the
+// filename unless a separate filename is set, in which case body is a caption.
+func attachmentName(mc messageContent) (filename, caption string) {
+	if mc.FileName != "" && mc.FileName != mc.Body {
+		return mc.FileName, mc.Body
+	}
+	return mc.Body, ""
+}
+
+// downloadMedia saves the attachment of a media message, if any.
+func (c *Channel) downloadMedia(ctx context.Context, mc messageContent) []media.MediaInfo {
+	switch mc.MsgType {
+	case "m.image", "m.file", "m.audio", "m.video":
+	default:
+		return nil
+	}
+	if mc.URL == "" {
+		// Encrypted attachment (content.file) — not supported.
+		return nil
+	}
+	filename, _ := attachmentName(mc)
+	mimeType := ""
+	if mc.Info != nil {
+		mimeType = mc.Info.MimeType
+		if mc.Info.Size > c.maxMedia {
+			slog.Debug("matrix: attachment over size limit skipped", "file", filename, "size", mc.Info.Size)
+			return nil
+		}
+	}
+	if mimeType == "" {
+		mimeType = media.DetectMIMEType(filename)
+	}
+	path, err := c.saveMedia(ctx, mc.URL, filename, mimeType)
+	if err != nil {
+		slog.Warn("matrix: media download failed", "url", mc.URL, "error", err)
+		return nil
+	}
+	return []media.MediaInfo{{
+		Type:        media.MediaKindFromMime(mimeType),
+		FilePath:    path,
+		ContentType: mimeType,
+		FileName:    filename,
+	}}
+}
+
+func (c *Channel) saveMedia(ctx context.Context, mxc, filename, mimeType string) (string, error) {
+	data, err := c.api.download(ctx, mxc, c.maxMedia)
+	if err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	f, err := os.CreateTemp("", "goclaw_matrix_*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// stripReplyFallback removes the "> <@user> quoted text" block clients
+// prepend to reply bodies.
+func stripReplyFallback(body string) string {
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	if i == 0 {
+		return body
+	}
+	return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
+
+// isMentioned reports whether a group message addresses the bot: an
+// intentional mention (m.mentions), a matrix.to pill, or the bot's user ID or
+// display name in the body.
+func (c *Channel) isMentioned(mc messageContent) bool {
+	if mc.Mentions != nil && slices.Contains(mc.Mentions.UserIDs, c.userID) {
+		return true
+	}
+	if strings.Contains(mc.FormattedBody, "matrix.to/#/"+c.userID) || strings.Contains(mc.Body, c.userID) {
+		return true
+	}
+	return c.nameRe != nil && c.nameRe.MatchString(mc.Body)
+}
+
+// mentionPattern matches name as a whole word, case-insensitively.
+func mentionPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|\W)` + regexp.QuoteMeta(name) + `($|\W)`)
+}
+
+// stripMention removes the bot's user ID and a leading "Name:" address.
+func (c *Channel) stripMention(text string) string {
+	text = strings.ReplaceAll(text, c.userID, "")
+	if n := len(c.botName); n > 0 && len(text) >= n && strings.EqualFold(text[:n], c.botName) {
+		text = strings.TrimLeft(text[n:], ":, ")
+	}
+	return text
+}
+
+func (c *Channel) memberName(rs *roomState, userID string) string {
+	rs.mu.Lock()
+	name := rs.names[userID]
+	rs.mu.Unlock()
+	if name == "" {
+		// @alice:example.com → alice
+		name = strings.TrimPrefix(userID, "@")
+		if i := strings.IndexByte(name, ':'); i > 0 {
+			name = name[:i]
+		}
+	}
+	return name
+}
+
+// warnEncrypted tells an encrypted room once that the bot cannot read it.
+func (c *Channel) warnEncrypted(ctx context.Context, roomID string) {
+	if _, sent := c.encryptWarning.LoadOrStore(roomID, struct{}{}); sent {
+		return
+	}
+	slog.Warn("matrix: ignoring end-to-end encrypted room", "name", c.Name(), "room_id", roomID)
+	if _, err := c.api.sendEvent(ctx, roomID, "m.room.message", messageContent{MsgType: "m.notice", Body: encryptedNotice}); err != nil {
+		slog.Debug("matrix: encrypted-room notice failed", "room_id", roomID, "error", err)
+	}
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, roomID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, roomID)
+	}
+	return false
+}
+
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, roomID string) bool {
+	groupPolicy := c.config.GroupPolicy
+	if groupPolicy == "" {
+		groupPolicy = "open"
+	}
+	// "allowlist" accepts listed senders or listed rooms.
+	if groupPolicy == "allowlist" {
+		return c.HasAllowList() && (c.IsAllowed(senderID) || c.IsAllowed(roomID))
+	}
+	switch c.CheckGroupPolicy(ctx, senderID, roomID, groupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+roomID, roomID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, roomID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounce) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), roomID, "default", nil)
+	if err != nil {
+		slog.Warn("matrix: failed to request pairing code", "error", err)
+		return
+	}
+
+	// Do not expose pairing codes to whole rooms.
+	var msg string
+	if strings.HasPrefix(senderID, "group:") {
+		msg = fmt.Sprintf("This room is not authorized to use this bot.\n\n"+
+			"An admin can approve via CLI:\n  goclaw pairing approve %s\n\n"+
+			"Or approve via the GoClaw web UI (Pairing section).", code)
+	} else {
+		msg = fmt.Sprintf("GoClaw: access not configured.\n\nYour Matrix user ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+			senderID, code, code)
+	}
+	if _, err := c.api.sendEvent(ctx, roomID, "m.room.message", messageContent{MsgType: "m.notice", Body: msg}); err != nil {
+		slog.Warn("matrix: failed to send pairing reply", "room_id", roomID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
@@ -0,0 +1,330 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Compile-time interface assertions.
+var (
+	_ channels.Channel           = (*Channel)(nil)
+	_ channels.StreamingChannel  = (*Channel)(nil)
+	_ channels.ReactionChannel   = (*Channel)(nil)
+	_ channels.BlockReplyChannel = (*Channel)(nil)
+)
+
+const (
+	defaultMediaMaxMB   = 20
+	defaultHistoryLimit = 50
+	pairingDebounce     = 60 * time.Second
+	maxMessageLen       = 16000 // well under the 64 KiB event size limit
+	maxSyncBackoff      = time.Minute
+)
+
+// Channel implements channels.Channel for Matrix.
+type Channel struct {
+	*channels.BaseChannel
+	config   matrixInstanceConfig
+	api      *apiClient
+	userID   string         // bot's own user ID, from whoami
+	botName  string         // bot's display name, for mention detection
+	nameRe   *regexp.Regexp // matches botName in message bodies (nil if unset)
+	maxMedia int64
+
+	rooms          sync.Map // roomID → *roomState
+	directRooms    sync.Map // roomID → struct{} (from m.direct account data)
+	placeholders   sync.Map // local key → event ID of the streamed message Send should edit
+	reactions      sync.Map // local key:eventID → *reactionState
+	encryptWarning sync.Map // roomID → struct{} (notice already sent)
+
+	since   string
+	stopCtx context.Context
+	stopFn  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// roomState is what the channel tracks per joined room from sync state.
+type roomState struct {
+	mu          sync.Mutex
+	encrypted   bool
+	joinedCount int
+	names       map[string]string // userID → display name
+}
+
+// New creates a Matrix channel from parsed credentials and config.
+func New(cfg matrixInstanceConfig, creds matrixCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+
+	if creds.AccessToken == "" {
+		return nil, fmt.Errorf("matrix: access_token is required")
+	}
+	u, err := url.Parse(cfg.Homeserver)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("matrix: homeserver must be an http(s) URL")
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+	if cfg.ReactionLevel == "" {
+		cfg.ReactionLevel = "minimal"
+	}
+
+	base := channels.NewBaseChannel(channels.TypeMatrix, msgBus, cfg.AllowFrom)
+	base.SetPairingService(pairingSvc)
+	requireMention := cfg.RequireMention == nil || *cfg.RequireMention
+	base.SetRequireMention(requireMention)
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = defaultHistoryLimit
+	}
+	base.SetGroupHistory(channels.MakeHistory(channels.TypeMatrix, pendingStore, base.TenantID()))
+	base.SetHistoryLimit(historyLimit)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	stopCtx, stopFn := context.WithCancel(context.Background())
+	return &Channel{
+		BaseChannel: base,
+		config:      cfg,
+		api:         newAPIClient(cfg.Homeserver, creds.AccessToken),
+		maxMedia:    int64(cfg.MediaMaxMB) * 1024 * 1024,
+		stopCtx:     stopCtx,
+		stopFn:      stopFn,
+	}, nil
+}
+
+// Factory creates a Matrix Channel from DB instance data.
+// Implements channels.ChannelFactory.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return buildChannel(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory that persists group history.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return buildChannel(name, creds, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func buildChannel(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore,
+	pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var c matrixCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("matrix: decode credentials: %w", err)
+		}
+	}
+	var ic matrixInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("matrix: decode config: %w", err)
+		}
+	}
+	ch, err := New(ic, c, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start verifies the access token, skips the room backlog with an initial
+// sync and starts the sync loop.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("connecting to " + c.config.Homeserver)
+
+	userID, err := c.api.whoami(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("matrix login failed", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return err
+	}
+	c.userID = userID
+	c.botName = c.api.displayName(ctx, userID)
+	if c.botName != "" {
+		c.nameRe = mentionPattern(c.botName)
+	}
+
+	// Initial sync: learn room state and the sync token without replaying
+	// messages sent while the channel was offline.
+	resp, err := c.api.sync(ctx, "", 0)
+	if err != nil {
+		c.MarkFailed("matrix initial sync failed", err.Error(), channels.ChannelFailureKindNetwork, true)
+		return err
+	}
+	c.processSync(ctx, resp, true)
+	c.since = resp.NextBatch
+
+	c.wg.Add(1)
+	go c.syncLoop()
+
+	c.SetRunning(true)
+	c.MarkHealthy("syncing as " + userID)
+	slog.Info("matrix channel started", "name", c.Name(), "user_id", userID, "homeserver", c.config.Homeserver)
+	return nil
+}
+
+// Stop cancels the sync loop and waits for it to exit.
+func (c *Channel) Stop(_ context.Context) error {
+	c.stopFn()
+	c.wg.Wait()
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("matrix channel stopped", "name", c.Name())
+	return nil
+}
+
+// syncLoop long-polls /sync until the channel stops, backing off on errors.
+func (c *Channel) syncLoop() {
+	defer c.wg.Done()
+	ctx := c.stopCtx
+	backoff := time.Second
+	degraded := false
+	for {
+		resp, err := c.api.sync(ctx, c.since, syncTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			kind := channels.ChannelFailureKindNetwork
+			if isAuthError(err) {
+				kind = channels.ChannelFailureKindAuth
+			}
+			slog.Warn("matrix: sync failed", "name", c.Name(), "error", err, "retry_in", backoff)
+			c.MarkDegraded("matrix sync failing", err.Error(), kind, true)
+			degraded = true
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxSyncBackoff)
+			continue
+		}
+		if degraded {
+			c.MarkHealthy("syncing as " + c.userID)
+			degraded = false
+		}
+		backoff = time.Second
+		c.processSync(ctx, resp, false)
+		c.since = resp.NextBatch
+	}
+}
+
+// processSync applies state and account data, accepts invites and, unless
+// initial, dispatches timeline events.
+func (c *Channel) processSync(ctx context.Context, resp *syncResponse, initial bool) {
+	for _, ev := range resp.AccountData.Events {
+		if ev.Type == "m.direct" {
+			c.applyDirect(ev.Content)
+		}
+	}
+	for roomID, inv := range resp.Rooms.Invite {
+		c.handleInvite(ctx, roomID, inv)
+	}
+	for roomID, room := range resp.Rooms.Join {
+		rs := c.room(roomID)
+		rs.mu.Lock()
+		if n := room.Summary.JoinedMemberCount; n != nil {
+			rs.joinedCount = *n
+		}
+		rs.mu.Unlock()
+		for _, ev := range room.State.Events {
+			c.applyState(rs, ev)
+		}
+		for _, ev := range room.Timeline.Events {
+			if ev.StateKey != nil {
+				c.applyState(rs, ev)
+				continue
+			}
+			if !initial {
+				c.handleEvent(ctx, roomID, rs, ev)
+			}
+		}
+	}
+}
+
+func (c *Channel) room(roomID string) *roomState {
+	v, _ := c.rooms.LoadOrStore(roomID, &roomState{names: map[string]string{}})
+	return v.(*roomState)
+}
+
+func (c *Channel) applyState(rs *roomState, ev event) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	switch ev.Type {
+	case "m.room.encryption":
+		rs.encrypted = true
+	case "m.room.member":
+		var mc memberContent
+		if ev.StateKey == nil || json.Unmarshal(ev.Content, &mc) != nil {
+			return
+		}
+		if mc.Membership == "join" && mc.DisplayName != "" {
+			rs.names[*ev.StateKey] = mc.DisplayName
+		}
+	}
+}
+
+// applyDirect records DM rooms from the m.direct account data event
+// (map of user ID → room IDs).
+func (c *Channel) applyDirect(raw json.RawMessage) {
+	var direct map[string][]string
+	if json.Unmarshal(raw, &direct) != nil {
+		return
+	}
+	for _, rooms := range direct {
+		for _, r := range rooms {
+			c.directRooms.Store(r, struct{}{})
+		}
+	}
+}
+
+// isDirect reports whether roomID is a DM: listed in m.direct, or a room
+// with at most two joined members.
+func (c *Channel) isDirect(roomID string, rs *roomState) bool {
+	if _, ok := c.directRooms.Load(roomID); ok {
+		return true
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.joinedCount > 0 && rs.joinedCount <= 2
+}
+
+// handleInvite joins rooms the bot is invited to when auto_join is on.
+func (c *Channel) handleInvite(ctx context.Context, roomID string, inv invitedRoom) {
+	if c.config.AutoJoin != nil && !*c.config.AutoJoin {
+		return
+	}
+	for _, ev := range inv.InviteState.Events {
+		var mc memberContent
+		if ev.Type == "m.room.member" && ev.StateKey != nil && *ev.StateKey == c.userID &&
+			json.Unmarshal(ev.Content, &mc) == nil && mc.IsDirect {
+			c.directRooms.Store(roomID, struct{}{})
+		}
+	}
+	if err := c.api.joinRoom(ctx, roomID); err != nil {
+		slog.Warn("matrix: join invited room failed", "room_id", roomID, "error", err)
+		return
+	}
+	slog.Info("matrix: joined room", "name", c.Name(), "room_id", roomID)
+}
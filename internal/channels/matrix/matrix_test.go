@@ -0,0 +1,277 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+const botID = "@bot:hs.test"
+
+// stubHomeserver is a minimal client-server API stub: /sync serves queued
+// responses and room sends are recorded.
+type stubHomeserver struct {
+	*channeltest.Server
+	syncs chan string
+
+	mu     sync.Mutex
+	sent   []sentEvent
+	nextID int
+}
+
+type sentEvent struct {
+	RoomID, Type string
+	Content      map[string]any
+}
+
+func newStubHomeserver(t *testing.T) *stubHomeserver {
+	t.Helper()
+	hs := &stubHomeserver{syncs: make(chan string, 8)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_matrix/client/v3/account/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"errcode":"M_UNKNOWN_TOKEN","error":"bad token"}`)
+			return
+		}
+		io.WriteString(w, `{"user_id":"`+botID+`"}`)
+	})
+	mux.HandleFunc("GET /_matrix/client/v3/profile/{user}/displayname", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{"displayname":"Claw"}`)
+	})
+	mux.HandleFunc("GET /_matrix/client/v3/sync", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case body := <-hs.syncs:
+			io.WriteString(w, body)
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("PUT /_matrix/client/v3/rooms/{room}/send/{type}/{txn}", func(w http.ResponseWriter, r *http.Request) {
+		var content map[string]any
+		json.NewDecoder(r.Body).Decode(&content)
+		hs.mu.Lock()
+		hs.nextID++
+		id := "$sent" + strings.Repeat("x", hs.nextID)
+		hs.sent = append(hs.sent, sentEvent{RoomID: r.PathValue("room"), Type: r.PathValue("type"), Content: content})
+		hs.mu.Unlock()
+		io.WriteString(w, `{"event_id":"`+id+`"}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/_matrix/client/v1/media/download/"):
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"errcode":"M_UNRECOGNIZED","error":"unknown endpoint"}`)
+		case strings.HasPrefix(r.URL.Path, "/_matrix/media/v3/download/"):
+			io.WriteString(w, "PNGDATA")
+		case strings.HasPrefix(r.URL.Path, "/_matrix/media/v3/upload"):
+			io.WriteString(w, `{"content_uri":"mxc://hs.test/up1"}`)
+		default:
+			io.WriteString(w, `{}`)
+		}
+	})
+	hs.Server = channeltest.NewServer(t, mux)
+	return hs
+}
+
+func (hs *stubHomeserver) sentEvents() []sentEvent {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return append([]sentEvent(nil), hs.sent...)
+}
+
+// syncBody builds a sync response with one joined room.
+func syncBody(batch, roomID string, members int, events ...string) string {
+	return `{"next_batch":"` + batch + `","rooms":{"join":{"` + roomID + `":{` +
+		`"summary":{"m.joined_member_count":` + strconv.Itoa(members) + `},` +
+		`"timeline":{"events":[` + strings.Join(events, ",") + `]}}}}}`
+}
+
+func textEvent(id, sender, body string) string {
+	return `{"type":"m.room.message","event_id":"` + id + `","sender":"` + sender +
+		`","content":{"msgtype":"m.text","body":"` + body + `"}}`
+}
+
+func startChannel(t *testing.T, hs *stubHomeserver, cfg matrixInstanceConfig, initial string) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	cfg.Homeserver = hs.URL
+	mb := bus.New()
+	ch, err := New(cfg, matrixCreds{AccessToken: "tok"}, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs.syncs <- initial
+	channeltest.Start(t, ch)
+	return ch, mb
+}
+
+func TestStart_RejectsInvalidToken(t *testing.T) {
+	hs := newStubHomeserver(t)
+	ch, err := New(matrixInstanceConfig{Homeserver: hs.URL}, matrixCreds{AccessToken: "wrong"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Start(context.Background()); !isAuthError(err) {
+		t.Fatalf("Start() error = %v, want auth error", err)
+	}
+}
+
+func TestSync_GroupMentionGating(t *testing.T) {
+	hs := newStubHomeserver(t)
+	// The initial sync's backlog must not be dispatched.
+	_, mb := startChannel(t, hs, matrixInstanceConfig{},
+		syncBody("s1", "!g:hs.test", 5, textEvent("$old", "@a:hs.test", "claw, old message")))
+
+	hs.syncs <- syncBody("s2", "!g:hs.test", 5,
+		textEvent("$1", "@a:hs.test", "lunch at noon?"),
+		textEvent("$2", "@b:hs.test", "Claw: what did alice say?"))
+
+	msg := channeltest.Consume(t, mb)
+	if msg.ChatID != "!g:hs.test" || msg.PeerKind != "group" || msg.Metadata["message_id"] != "$2" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "lunch at noon?") || !strings.Contains(msg.Content, "[From: b]\nwhat did alice say?") {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if strings.Contains(msg.Content, "old message") {
+		t.Error("initial sync backlog was dispatched")
+	}
+}
+
+func TestSync_DMWithThreadAndMedia(t *testing.T) {
+	hs := newStubHomeserver(t)
+	_, mb := startChannel(t, hs, matrixInstanceConfig{DMPolicy: "open"}, syncBody("s1", "!dm:hs.test", 2))
+
+	hs.syncs <- syncBody("s2", "!dm:hs.test", 2,
+		`{"type":"m.room.message","event_id":"$img","sender":"@a:hs.test","content":{"msgtype":"m.image",`+
+			`"body":"cat.png","url":"mxc://hs.test/abc","info":{"mimetype":"image/png","size":7},`+
+			`"m.relates_to":{"rel_type":"m.thread","event_id":"$root"}}}`)
+
+	msg := channeltest.Consume(t, mb)
+	for _, m := range msg.Media {
+		t.Cleanup(func() { os.Remove(m.Path) })
+	}
+	if msg.PeerKind != "direct" || msg.Metadata["local_key"] != "!dm:hs.test:thread:$root" || msg.Metadata["message_thread_id"] != "$root" {
+		t.Errorf("routing = %q %v", msg.PeerKind, msg.Metadata)
+	}
+	if len(msg.Media) != 1 || msg.Media[0].MimeType != "image/png" {
+		t.Fatalf("media = %+v", msg.Media)
+	}
+	if data, _ := os.ReadFile(msg.Media[0].Path); string(data) != "PNGDATA" {
+		t.Errorf("downloaded = %q", data)
+	}
+	if len(hs.Requests(http.MethodGet, "/_matrix/media/v3/download/hs.test/abc")) == 0 {
+		t.Error("legacy media endpoint not used after 404")
+	}
+}
+
+func TestSync_CommandCaptionSkipsMediaDownload(t *testing.T) {
+	hs := newStubHomeserver(t)
+	mb := bus.New()
+	ch, err := New(matrixInstanceConfig{Homeserver: hs.URL, DMPolicy: "open"}, matrixCreds{AccessToken: "tok"}, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetCommandRegistry(channels.NewCommandRegistry(channels.CommandDeps{}))
+	hs.syncs <- syncBody("s1", "!dm:hs.test", 2)
+	channeltest.Start(t, ch)
+
+	hs.syncs <- syncBody("s2", "!dm:hs.test", 2,
+		`{"type":"m.room.message","event_id":"$img","sender":"@a:hs.test","content":{"msgtype":"m.image",`+
+			`"body":"/status","filename":"cat.png","url":"mxc://hs.test/abc","info":{"mimetype":"image/png","size":7}}}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), channeltest.Timeout)
+	defer cancel()
+	out, ok := mb.SubscribeOutbound(ctx)
+	if !ok || out.ChatID != "!dm:hs.test" || !strings.HasPrefix(out.Content, "Bot status") {
+		t.Fatalf("command reply = %+v", out)
+	}
+	for _, r := range hs.Requests(http.MethodGet, "") {
+		if strings.Contains(r.Path, "/download/") {
+			t.Errorf("command attachment downloaded: %s", r.Path)
+		}
+	}
+}
+
+func TestSync_EncryptedRoomNoticeOnce(t *testing.T) {
+	hs := newStubHomeserver(t)
+	startChannel(t, hs, matrixInstanceConfig{}, syncBody("s1", "!e:hs.test", 2))
+
+	enc := `{"type":"m.room.encrypted","event_id":"$e1","sender":"@a:hs.test","content":{}}`
+	hs.syncs <- syncBody("s2", "!e:hs.test", 2, enc, enc)
+	hs.syncs <- syncBody("s3", "!e:hs.test", 2)
+
+	channeltest.WaitFor(t, func() bool { return len(hs.sentEvents()) > 0 })
+	time.Sleep(50 * time.Millisecond)
+	sent := hs.sentEvents()
+	if len(sent) != 1 || sent[0].Content["msgtype"] != "m.notice" || sent[0].Content["body"] != encryptedNotice {
+		t.Errorf("sent = %+v", sent)
+	}
+}
+
+func TestSend_StreamThenFinalEdit(t *testing.T) {
+	hs := newStubHomeserver(t)
+	ch, _ := startChannel(t, hs, matrixInstanceConfig{}, syncBody("s1", "!r:hs.test", 2))
+	ctx := context.Background()
+	key := "!r:hs.test:thread:$root"
+
+	stream, _ := ch.CreateStream(ctx, key, true)
+	stream.Update(ctx, "partial")
+	ch.FinalizeStream(ctx, key, stream)
+
+	err := ch.Send(ctx, bus.OutboundMessage{
+		ChatID:   "!r:hs.test",
+		Content:  "**done**",
+		Metadata: map[string]string{"placeholder_key": key, "message_thread_id": "$root"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := hs.sentEvents()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d events, want 2", len(sent))
+	}
+	first := sent[0].Content["m.relates_to"].(map[string]any)
+	if first["rel_type"] != "m.thread" || first["event_id"] != "$root" {
+		t.Errorf("streamed message relation = %v", first)
+	}
+	edit := sent[1].Content
+	rel := edit["m.relates_to"].(map[string]any)
+	nc := edit["m.new_content"].(map[string]any)
+	if rel["rel_type"] != "m.replace" || rel["event_id"] != "$sentx" {
+		t.Errorf("edit relation = %v", rel)
+	}
+	if nc["body"] != "**done**" || nc["formatted_body"] != "<p><strong>done</strong></p>" || nc["format"] != "org.matrix.custom.html" {
+		t.Errorf("new content = %v", nc)
+	}
+}
+
+func TestIsMentioned(t *testing.T) {
+	ch := &Channel{userID: botID, botName: "Claw", nameRe: mentionPattern("Claw")}
+	tests := []struct {
+		mc   messageContent
+		want bool
+	}{
+		{messageContent{Body: "hi", Mentions: &mentions{UserIDs: []string{botID}}}, true},
+		{messageContent{Body: "Claw", FormattedBody: `<a href="https://matrix.to/#/@bot:hs.test">Claw</a>`}, true},
+		{messageContent{Body: "hey claw, ping"}, true},
+		{messageContent{Body: "clawback clause"}, false},
+		{messageContent{Body: "hello all"}, false},
+	}
+	for _, tt := range tests {
+		if got := ch.isMentioned(tt.mc); got != tt.want {
+			t.Errorf("isMentioned(%q) = %v, want %v", tt.mc.Body, got, tt.want)
+		}
+	}
+}
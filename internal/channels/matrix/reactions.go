@@ -0,0 +1,110 @@
+package matrix
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	reactionDebounceInterval = 700 * time.Millisecond
+	typingTimeout            = 30 * time.Second
+)
+
+// statusEmoji maps GoClaw agent status to reaction keys.
+var statusEmoji = map[string]string{
+	"thinking": "👀",
+	"tool":     "🛠️",
+	"web":      "🛠️",
+	"coding":   "🛠️",
+	"done":     "✅",
+	"error":    "❌",
+	"stall":    "⏳",
+}
+
+// reactionState tracks the bot's current reaction on one message.
+type reactionState struct {
+	eventID    string // m.reaction event, redacted when the status changes
+	key        string
+	lastUpdate time.Time
+	mu         sync.Mutex
+}
+
+// OnReactionEvent shows the agent status on the user's message: a typing
+// notification while the agent works, plus an emoji reaction unless
+// reaction_level is "off".
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, messageID string, status string) error {
+	roomID, _ := splitLocalKey(chatID)
+
+	switch status {
+	case "done", "error":
+		c.typing(ctx, roomID, false)
+	case "stall":
+	default:
+		c.typing(ctx, roomID, true)
+	}
+
+	if c.config.ReactionLevel == "off" || messageID == "" {
+		return nil
+	}
+	key, ok := statusEmoji[status]
+	if !ok {
+		return nil
+	}
+	// For "minimal" level, only show thinking and done
+	if c.config.ReactionLevel == "minimal" && status != "thinking" && status != "done" {
+		return nil
+	}
+
+	stateVal, _ := c.reactions.LoadOrStore(chatID+":"+messageID, &reactionState{})
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.key == key || time.Since(st.lastUpdate) < reactionDebounceInterval {
+		return nil
+	}
+	if st.eventID != "" {
+		if err := c.api.redact(ctx, roomID, st.eventID); err != nil {
+			slog.Debug("matrix: remove reaction failed", "key", st.key, "error", err)
+		}
+		st.eventID, st.key = "", ""
+	}
+	id, err := c.api.sendEvent(ctx, roomID, "m.reaction", map[string]any{
+		"m.relates_to": relatesTo{RelType: "m.annotation", EventID: messageID, Key: key},
+	})
+	if err != nil {
+		slog.Debug("matrix: add reaction failed", "key", key, "error", err)
+		return nil
+	}
+	st.eventID, st.key = id, key
+	st.lastUpdate = time.Now()
+	return nil
+}
+
+// ClearReaction removes the status reaction from a message and stops typing.
+func (c *Channel) ClearReaction(ctx context.Context, chatID string, messageID string) error {
+	roomID, _ := splitLocalKey(chatID)
+	c.typing(ctx, roomID, false)
+
+	stateVal, ok := c.reactions.LoadAndDelete(chatID + ":" + messageID)
+	if !ok {
+		return nil
+	}
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.eventID != "" {
+		if err := c.api.redact(ctx, roomID, st.eventID); err != nil {
+			slog.Debug("matrix: clear reaction failed", "key", st.key, "error", err)
+		}
+	}
+	return nil
+}
+
+func (c *Channel) typing(ctx context.Context, roomID string, on bool) {
+	if err := c.api.setTyping(ctx, roomID, c.userID, on, typingTimeout); err != nil {
+		slog.Debug("matrix: typing notification failed", "room_id", roomID, "error", err)
+	}
+}
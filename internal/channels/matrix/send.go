@@ -0,0 +1,195 @@
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// Send delivers an outbound message to a Matrix room.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("matrix channel not running")
+	}
+	roomID := msg.ChatID
+	if roomID == "" {
+		return fmt.Errorf("empty chat ID for matrix send")
+	}
+
+	placeholderKey := roomID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	threadRoot := msg.Metadata["message_thread_id"]
+
+	// Placeholder update (LLM retry notification)
+	if msg.Metadata["placeholder_update"] == "true" {
+		if v, ok := c.placeholders.Load(placeholderKey); ok {
+			if _, err := c.api.sendEvent(ctx, roomID, "m.room.message", editContent(v.(string), msg.Content)); err != nil {
+				slog.Debug("matrix: placeholder update failed", "room_id", roomID, "error", err)
+			}
+		}
+		return nil
+	}
+
+	// NO_REPLY: redact the streamed message, if any.
+	if msg.Content == "" && len(msg.Media) == 0 {
+		if v, ok := c.placeholders.LoadAndDelete(placeholderKey); ok {
+			if err := c.api.redact(ctx, roomID, v.(string)); err != nil {
+				slog.Debug("matrix: placeholder redact failed", "room_id", roomID, "error", err)
+			}
+		}
+		return nil
+	}
+
+	for _, m := range msg.Media {
+		if err := c.sendMedia(ctx, roomID, threadRoot, m); err != nil {
+			slog.Warn("matrix: media upload failed", "file", m.URL, "error", err)
+			if _, err := c.sendText(ctx, roomID, threadRoot, fmt.Sprintf("[File upload failed: %s]", filepath.Base(m.URL))); err != nil {
+				return err
+			}
+		}
+	}
+	if msg.Content == "" {
+		return nil
+	}
+
+	chunks := channels.ChunkMarkdown(msg.Content, maxMessageLen)
+	// Edit the streamed message with the first chunk, send the rest as follow-ups.
+	if v, ok := c.placeholders.LoadAndDelete(placeholderKey); ok && len(chunks) > 0 {
+		if _, err := c.api.sendEvent(ctx, roomID, "m.room.message", editContent(v.(string), chunks[0])); err == nil {
+			chunks = chunks[1:]
+		} else {
+			slog.Warn("matrix: placeholder edit failed, sending new message", "room_id", roomID, "error", err)
+		}
+	}
+	for _, chunk := range chunks {
+		if _, err := c.sendText(ctx, roomID, threadRoot, chunk); err != nil {
+			return fmt.Errorf("send matrix message: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClassifySendError implements channels.SendErrorClassifier. The client has
+// already retried M_LIMIT_EXCEEDED twice, so a rate limit that reaches the
+// outbox pauses the channel for the server's retry_after_ms; bad tokens
+// pause it too. Rooms the bot cannot post to fail the same way on every retry.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		switch {
+		case ae.ErrCode == "M_LIMIT_EXCEEDED":
+			return channels.SendFailure{RetryAfter: time.Duration(ae.RetryAfterMS) * time.Millisecond, ChannelWide: true}
+		case isAuthError(err):
+			return channels.SendFailure{ChannelWide: true}
+		case ae.ErrCode == "M_FORBIDDEN", ae.ErrCode == "M_NOT_FOUND", ae.ErrCode == "M_TOO_LARGE":
+			return channels.SendFailure{Permanent: true}
+		}
+		if f, ok := channels.ClassifyHTTPStatus(ae.Status, 0); ok {
+			return f
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// sendText sends one Markdown chunk as an HTML-formatted m.text message.
+func (c *Channel) sendText(ctx context.Context, roomID, threadRoot, text string) (string, error) {
+	mc := textContent(text)
+	mc.RelatesTo = threadRelation(threadRoot)
+	return c.api.sendEvent(ctx, roomID, "m.room.message", mc)
+}
+
+// sendMedia uploads a local file to the media repository and posts it.
+func (c *Channel) sendMedia(ctx context.Context, roomID, threadRoot string, m bus.MediaAttachment) error {
+	info, err := os.Stat(m.URL)
+	if err != nil {
+		return err
+	}
+	if info.Size() > c.maxMedia {
+		return fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	data, err := os.ReadFile(m.URL)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Base(m.URL)
+	mimeType := m.ContentType
+	if mimeType == "" {
+		mimeType = media.DetectMIMEType(filename)
+	}
+	mxc, err := c.api.upload(ctx, data, mimeType, filename)
+	if err != nil {
+		return err
+	}
+
+	mc := messageContent{
+		MsgType:   mediaMsgType(mimeType),
+		Body:      filename,
+		URL:       mxc,
+		Info:      &fileInfo{MimeType: mimeType, Size: int64(len(data))},
+		RelatesTo: threadRelation(threadRoot),
+	}
+	// With a separate filename, body is rendered as the caption.
+	if m.Caption != "" {
+		mc.FileName = filename
+		mc.Body = m.Caption
+	}
+	_, err = c.api.sendEvent(ctx, roomID, "m.room.message", mc)
+	return err
+}
+
+func mediaMsgType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "m.image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "m.video"
+	case strings.HasPrefix(mimeType, "audio/"):
+		return "m.audio"
+	}
+	return "m.file"
+}
+
+// textContent renders Markdown as an m.text event with an HTML formatted_body.
+func textContent(text string) messageContent {
+	return messageContent{
+		MsgType:       "m.text",
+		Body:          text,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: channels.MarkdownToHTML(text),
+	}
+}
+
+// editContent replaces eventID's content with text (m.replace). The outer
+// body is the fallback shown by clients without edit support.
+func editContent(eventID, text string) messageContent {
+	nc := textContent(text)
+	mc := textContent("* " + text)
+	mc.NewContent = &nc
+	mc.RelatesTo = &relatesTo{RelType: "m.replace", EventID: eventID}
+	return mc
+}
+
+// threadRelation places a message in the thread rooted at root (nil if root
+// is empty). The reply fallback keeps it readable in clients without threads.
+func threadRelation(root string) *relatesTo {
+	if root == "" {
+		return nil
+	}
+	return &relatesTo{
+		RelType:       "m.thread",
+		EventID:       root,
+		IsFallingBack: true,
+		InReplyTo:     &inReplyTo{EventID: root},
+	}
+}
@@ -0,0 +1,100 @@
+package matrix
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const streamThrottleInterval = 1000 * time.Millisecond
+
+// matrixStream implements channels.ChannelStream for Matrix. The first update
+// sends a message; later updates replace it in place with m.replace edits.
+type matrixStream struct {
+	c          *Channel
+	roomID     string
+	threadRoot string
+	eventID    string    // streamed message, set by the first update
+	lastUpdate time.Time // last send or edit
+	mu         sync.Mutex
+}
+
+// Update sends or edits the streamed message, throttled to avoid rate limits.
+func (s *matrixStream) Update(ctx context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastUpdate) < streamThrottleInterval {
+		return
+	}
+	if len(fullText) > maxMessageLen {
+		fullText = fullText[:maxMessageLen] + "..."
+	}
+
+	if s.eventID == "" {
+		id, err := s.c.sendText(ctx, s.roomID, s.threadRoot, fullText)
+		if err != nil {
+			slog.Debug("matrix stream send failed", "error", err)
+			return
+		}
+		s.eventID = id
+	} else if _, err := s.c.api.sendEvent(ctx, s.roomID, "m.room.message", editContent(s.eventID, fullText)); err != nil {
+		slog.Debug("matrix stream edit failed", "error", err)
+		return
+	}
+	s.lastUpdate = time.Now()
+}
+
+// Stop is a no-op: Send() makes the final edit via the placeholder map.
+func (s *matrixStream) Stop(_ context.Context) error {
+	return nil
+}
+
+// MessageID returns 0 — Matrix uses string event IDs.
+func (s *matrixStream) MessageID() int {
+	return 0
+}
+
+// StreamEnabled reports whether streaming is active for DMs or groups.
+func (c *Channel) StreamEnabled(isGroup bool) bool {
+	if isGroup {
+		return c.config.GroupStream != nil && *c.config.GroupStream
+	}
+	return c.config.DMStream != nil && *c.config.DMStream
+}
+
+// ReasoningStreamEnabled returns false — reasoning is not streamed to Matrix.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
+
+// CreateStream creates a per-run streaming handle. chatID is the local key
+// ("roomID" or "roomID:thread:rootEventID").
+// Implements channels.StreamingChannel.
+func (c *Channel) CreateStream(_ context.Context, chatID string, _ bool) (channels.ChannelStream, error) {
+	roomID, threadRoot := splitLocalKey(chatID)
+	return &matrixStream{c: c, roomID: roomID, threadRoot: threadRoot}, nil
+}
+
+// FinalizeStream stores the streamed event ID into c.placeholders so that
+// Send() edits it with the final response.
+// Implements channels.StreamingChannel.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	ms, ok := stream.(*matrixStream)
+	if !ok {
+		return
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.eventID != "" {
+		c.placeholders.Store(chatID, ms.eventID)
+	}
+}
+
+// splitLocalKey returns the room ID and thread root of a local key.
+func splitLocalKey(localKey string) (roomID, threadRoot string) {
+	roomID, threadRoot, _ = strings.Cut(localKey, ":thread:")
+	return roomID, threadRoot
+}
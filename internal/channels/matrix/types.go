@@ -0,0 +1,114 @@
+// Package matrix implements the Matrix channel over the client-server API:
+// a /sync long-poll loop for inbound events, room messages for replies,
+// m.replace edits for streaming, and the media repository for attachments.
+//
+// Only unencrypted rooms are supported. Events in end-to-end encrypted rooms
+// are ignored and the room is told once that the bot cannot read it.
+package matrix
+
+import "encoding/json"
+
+// matrixCreds holds encrypted credentials stored in channel_instances.credentials.
+type matrixCreds struct {
+	AccessToken string `json:"access_token"`
+}
+
+// matrixInstanceConfig holds non-secret config from channel_instances.config JSONB.
+type matrixInstanceConfig struct {
+	Homeserver     string   `json:"homeserver"` // e.g. https://matrix.example.com
+	DMPolicy       string   `json:"dm_policy,omitempty"`
+	GroupPolicy    string   `json:"group_policy,omitempty"`
+	AllowFrom      []string `json:"allow_from,omitempty"` // user IDs (@user:server) or room IDs
+	RequireMention *bool    `json:"require_mention,omitempty"`
+	HistoryLimit   int      `json:"history_limit,omitempty"`
+	// AutoJoin accepts room invites (default true). Policies still apply to
+	// messages in joined rooms.
+	AutoJoin      *bool  `json:"auto_join,omitempty"`
+	DMStream      *bool  `json:"dm_stream,omitempty"`
+	GroupStream   *bool  `json:"group_stream,omitempty"`
+	ReactionLevel string `json:"reaction_level,omitempty"` // off, minimal (default), full
+	MediaMaxMB    int    `json:"media_max_mb,omitempty"`
+	BlockReply    *bool  `json:"block_reply,omitempty"`
+}
+
+// --- Client-server API wire types ---
+
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join   map[string]joinedRoom  `json:"join"`
+		Invite map[string]invitedRoom `json:"invite"`
+	} `json:"rooms"`
+	AccountData struct {
+		Events []event `json:"events"`
+	} `json:"account_data"`
+}
+
+type joinedRoom struct {
+	Summary struct {
+		JoinedMemberCount *int `json:"m.joined_member_count"`
+	} `json:"summary"`
+	State struct {
+		Events []event `json:"events"`
+	} `json:"state"`
+	Timeline struct {
+		Events []event `json:"events"`
+	} `json:"timeline"`
+}
+
+type invitedRoom struct {
+	InviteState struct {
+		Events []event `json:"events"`
+	} `json:"invite_state"`
+}
+
+type event struct {
+	Type     string          `json:"type"`
+	EventID  string          `json:"event_id"`
+	Sender   string          `json:"sender"`
+	StateKey *string         `json:"state_key,omitempty"`
+	Content  json.RawMessage `json:"content"`
+}
+
+// messageContent is the content of m.room.message events, sent and received.
+type messageContent struct {
+	MsgType       string          `json:"msgtype"`
+	Body          string          `json:"body"`
+	Format        string          `json:"format,omitempty"`
+	FormattedBody string          `json:"formatted_body,omitempty"`
+	URL           string          `json:"url,omitempty"`  // mxc:// URI of unencrypted media
+	File          json.RawMessage `json:"file,omitempty"` // encrypted media (unsupported)
+	FileName      string          `json:"filename,omitempty"`
+	Info          *fileInfo       `json:"info,omitempty"`
+	RelatesTo     *relatesTo      `json:"m.relates_to,omitempty"`
+	Mentions      *mentions       `json:"m.mentions,omitempty"`
+	NewContent    *messageContent `json:"m.new_content,omitempty"`
+}
+
+type fileInfo struct {
+	MimeType string `json:"mimetype,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+}
+
+type relatesTo struct {
+	RelType       string     `json:"rel_type,omitempty"` // m.replace, m.thread, m.annotation
+	EventID       string     `json:"event_id,omitempty"`
+	Key           string     `json:"key,omitempty"` // m.annotation (reaction) key
+	InReplyTo     *inReplyTo `json:"m.in_reply_to,omitempty"`
+	IsFallingBack bool       `json:"is_falling_back,omitempty"`
+}
+
+type inReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+type mentions struct {
+	UserIDs []string `json:"user_ids,omitempty"`
+	Room    bool     `json:"room,omitempty"`
+}
+
+type memberContent struct {
+	Membership  string `json:"membership"`
+	DisplayName string `json:"displayname,omitempty"`
+	IsDirect    bool   `json:"is_direct,omitempty"`
+}
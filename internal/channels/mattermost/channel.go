@@ -0,0 +1,188 @@
+// Package mattermost implements the Mattermost channel: the WebSocket event
+// stream for inbound posts, the REST API v4 for replies, post edits for
+// streaming, the files API for attachments, and custom slash commands.
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	pairingDebounceTime = 60 * time.Second
+	maxMessageLen       = 16000 // Mattermost post limit is 16383 characters
+	defaultMediaMaxMB   = 20
+	channelCacheTTL     = time.Hour
+)
+
+// Channel connects to Mattermost over the WebSocket event stream.
+type Channel struct {
+	*channels.BaseChannel
+	api          *apiClient
+	config       mattermostInstanceConfig
+	commandToken string // manually configured slash command token
+	botUserID    string // populated on Start() via /users/me
+	botUsername  string
+	mentionRe    *regexp.Regexp // matches "@botUsername"
+	maxMedia     int64
+	threadTTL    time.Duration // thread participation expiry (0 = disabled)
+
+	placeholders   sync.Map // localKey -> post ID of the streamed reply
+	threadParticip sync.Map // channelID:rootID -> time.Time (auto-reply without @mention)
+	reactions      sync.Map // chatID:postID -> *reactionState
+	channelTypes   sync.Map // channelID -> cachedChannel
+	userNames      sync.Map // userID -> display name
+
+	wg       sync.WaitGroup
+	cancelFn context.CancelFunc
+}
+
+type cachedChannel struct {
+	typ       string
+	fetchedAt time.Time
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.StreamingChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+
+// New creates a new Mattermost channel from instance config.
+func New(cfg mattermostInstanceConfig, creds mattermostCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if creds.Token == "" {
+		return nil, fmt.Errorf("mattermost token is required")
+	}
+	u, err := url.Parse(cfg.ServerURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("mattermost server_url must be an http(s) URL")
+	}
+	cfg.SlashCommand.Trigger = strings.TrimPrefix(strings.TrimSpace(cfg.SlashCommand.Trigger), "/")
+
+	base := channels.NewBaseChannel(channels.TypeMattermost, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+	threadTTL := 24 * time.Hour
+	if cfg.ThreadTTL != nil {
+		threadTTL = time.Duration(max(*cfg.ThreadTTL, 0)) * time.Hour
+	}
+
+	ch := &Channel{
+		BaseChannel:  base,
+		api:          newAPIClient(cfg.ServerURL, creds.Token),
+		config:       cfg,
+		commandToken: creds.CommandToken,
+		maxMedia:     int64(cfg.MediaMaxMB) * 1024 * 1024,
+		threadTTL:    threadTTL,
+	}
+	ch.SetPairingService(pairingSvc)
+	ch.SetRequireMention(requireMention)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeMattermost, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start authenticates, registers slash commands and connects the event stream.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("connecting to " + c.config.ServerURL)
+
+	me, err := c.api.getMe(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("mattermost authentication failed", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return fmt.Errorf("mattermost auth: %w", err)
+	}
+	c.botUserID = me.ID
+	c.botUsername = me.Username
+	c.mentionRe = mentionPattern(me.Username)
+
+	c.registerCommands(ctx)
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancelFn = cancel
+	c.wg.Add(1)
+	go c.runWebSocket(runCtx)
+
+	c.SetRunning(true)
+	c.MarkHealthy("connected as @" + me.Username)
+	slog.Info("mattermost channel started", "name", c.Name(), "bot", me.Username, "server", c.config.ServerURL)
+	return nil
+}
+
+// Stop closes the event stream and unregisters slash command tokens.
+func (c *Channel) Stop(_ context.Context) error {
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.wg.Wait()
+	globalRouter.unregister(c)
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("mattermost channel stopped", "name", c.Name())
+	return nil
+}
+
+// channelType returns the Mattermost channel type (O, P, D, G), cached.
+func (c *Channel) channelType(ctx context.Context, channelID string) string {
+	if v, ok := c.channelTypes.Load(channelID); ok {
+		if cc := v.(cachedChannel); time.Since(cc.fetchedAt) < channelCacheTTL {
+			return cc.typ
+		}
+	}
+	ci, err := c.api.getChannel(ctx, channelID)
+	if err != nil {
+		slog.Debug("mattermost: channel lookup failed", "channel_id", channelID, "error", err)
+		return ""
+	}
+	c.channelTypes.Store(channelID, cachedChannel{typ: ci.Type, fetchedAt: time.Now()})
+	return ci.Type
+}
+
+// displayName returns a user's full name, nickname or username, cached.
+func (c *Channel) displayName(ctx context.Context, userID, fallback string) string {
+	if v, ok := c.userNames.Load(userID); ok {
+		return v.(string)
+	}
+	u, err := c.api.getUser(ctx, userID)
+	if err != nil {
+		return strings.TrimPrefix(fallback, "@")
+	}
+	name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if name == "" {
+		name = u.Nickname
+	}
+	if name == "" {
+		name = u.Username
+	}
+	c.userNames.Store(userID, name)
+	return name
+}
@@ -0,0 +1,158 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+// stubServer is a minimal Mattermost REST + WebSocket stub. Requests are
+// recorded; WebSocket events are pushed over the accepted connection.
+type stubServer struct {
+	*channeltest.Server
+	conns chan *websocket.Conn
+
+	mu       sync.Mutex
+	commands []command
+}
+
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+	s := &stubServer{conns: make(chan *websocket.Conn, 1)}
+	upgrader := websocket.Upgrader{}
+	s.Server = channeltest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"id":"api.context.session_expired.app_error","message":"Invalid or expired session","status_code":401}`)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/v4/websocket":
+			if conn, err := upgrader.Upgrade(w, r, nil); err == nil {
+				s.conns <- conn
+			}
+		case r.URL.Path == "/api/v4/users/me":
+			io.WriteString(w, `{"id":"bot1","username":"claw"}`)
+		case strings.HasPrefix(r.URL.Path, "/api/v4/users/u"):
+			io.WriteString(w, `{"id":"u1","username":"alice","first_name":"Alice"}`)
+		case r.URL.Path == "/api/v4/users/me/teams":
+			io.WriteString(w, `[{"id":"t1","name":"eng"}]`)
+		case r.URL.Path == "/api/v4/channels/dm1":
+			io.WriteString(w, `{"id":"dm1","type":"D"}`)
+		case r.URL.Path == "/api/v4/commands" && r.Method == http.MethodGet:
+			s.mu.Lock()
+			json.NewEncoder(w).Encode(s.commands)
+			s.mu.Unlock()
+		case r.URL.Path == "/api/v4/commands" && r.Method == http.MethodPost:
+			io.WriteString(w, `{"id":"c1","token":"cmdtok","trigger":"ask"}`)
+		case r.URL.Path == "/api/v4/posts":
+			io.WriteString(w, `{"id":"p-new"}`)
+		default:
+			io.WriteString(w, `{}`)
+		}
+	}))
+	return s
+}
+
+func postedEvent(t *testing.T, channelType string, p post, mentions ...string) []byte {
+	t.Helper()
+	pj, _ := json.Marshal(p)
+	mj, _ := json.Marshal(mentions)
+	data, _ := json.Marshal(map[string]any{
+		"event": "posted",
+		"data":  map[string]string{"channel_type": channelType, "post": string(pj), "mentions": string(mj)},
+	})
+	return data
+}
+
+func startTestChannel(t *testing.T, s *stubServer, cfg mattermostInstanceConfig) (*Channel, *bus.MessageBus, *websocket.Conn) {
+	t.Helper()
+	cfg.ServerURL = s.URL
+	mb := bus.New()
+	ch, err := New(cfg, mattermostCreds{Token: "tok"}, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channeltest.Start(t, ch)
+	select {
+	case conn := <-s.conns:
+		return ch, mb, conn
+	case <-time.After(2 * time.Second):
+		t.Fatal("websocket not connected")
+	}
+	return nil, nil, nil
+}
+
+func TestStart_InvalidToken(t *testing.T) {
+	s := newStubServer(t)
+	ch, err := New(mattermostInstanceConfig{ServerURL: s.URL}, mattermostCreds{Token: "bad"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ch.Start(context.Background()); !isAuthError(err) {
+		t.Fatalf("Start() error = %v, want auth error", err)
+	}
+}
+
+func TestWebSocket_MentionGatingAndThreads(t *testing.T) {
+	s := newStubServer(t)
+	_, mb, conn := startTestChannel(t, s, mattermostInstanceConfig{GroupPolicy: "open"})
+
+	conn.WriteMessage(websocket.TextMessage, postedEvent(t, "O", post{ID: "p1", UserID: "u1", ChannelID: "town", Message: "deploy is red"}))
+	conn.WriteMessage(websocket.TextMessage, postedEvent(t, "O", post{ID: "p2", UserID: "u1", ChannelID: "town", Message: "@claw why?"}, "bot1"))
+
+	msg := channeltest.Consume(t, mb)
+	if msg.ChatID != "town" || msg.PeerKind != "group" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if msg.Metadata["local_key"] != "town:thread:p2" || msg.Metadata["message_thread_id"] != "p2" {
+		t.Errorf("thread routing = %v", msg.Metadata)
+	}
+	if !strings.Contains(msg.Content, "deploy is red") || !strings.Contains(msg.Content, "[From: Alice]\nwhy?") {
+		t.Errorf("content = %q", msg.Content)
+	}
+
+	// Follow-ups in a thread the bot joined need no mention.
+	conn.WriteMessage(websocket.TextMessage, postedEvent(t, "O", post{ID: "p3", UserID: "u1", ChannelID: "town", RootID: "p2", Message: "and now?"}))
+	msg = channeltest.Consume(t, mb)
+	if msg.Metadata["local_key"] != "town:thread:p2" || !strings.HasSuffix(msg.Content, "and now?") {
+		t.Errorf("thread follow-up = %q %v", msg.Content, msg.Metadata)
+	}
+}
+
+func TestSend_StreamThenFinalEdit(t *testing.T) {
+	s := newStubServer(t)
+	ch, _, _ := startTestChannel(t, s, mattermostInstanceConfig{})
+	ctx := context.Background()
+
+	stream, _ := ch.CreateStream(ctx, "town:thread:p2", true)
+	stream.Update(ctx, "partial")
+	ch.FinalizeStream(ctx, "town:thread:p2", stream)
+
+	if err := ch.Send(ctx, bus.OutboundMessage{
+		ChatID:   "town",
+		Content:  "final answer",
+		Metadata: map[string]string{"placeholder_key": "town:thread:p2", "message_thread_id": "p2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := s.Requests(http.MethodPost, "/api/v4/posts")
+	if len(posts) != 1 || posts[0].JSON()["root_id"] != "p2" || posts[0].JSON()["message"] != "partial" {
+		t.Fatalf("posts = %+v", posts)
+	}
+	patches := s.Requests(http.MethodPut, "/api/v4/posts/p-new/patch")
+	if len(patches) != 1 || patches[0].JSON()["message"] != "final answer" {
+		t.Errorf("patches = %+v", patches)
+	}
+}
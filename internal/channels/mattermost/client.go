@@ -0,0 +1,302 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// apiError is a Mattermost REST error ({"id": ..., "message": ..., "status_code": ...}).
+type apiError struct {
+	StatusCode int           `json:"status_code"`
+	ID         string        `json:"id"`
+	Message    string        `json:"message"`
+	RetryAfter time.Duration `json:"-"` // from the Retry-After header on 429s
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("mattermost API %d %s: %s", e.StatusCode, e.ID, e.Message)
+}
+
+// isAuthError reports an invalid or revoked token.
+func isAuthError(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && ae.StatusCode == http.StatusUnauthorized
+}
+
+// apiClient is a minimal Mattermost REST API v4 client.
+type apiClient struct {
+	serverURL string // base URL without trailing slash
+	token     string
+	http      *http.Client
+}
+
+func newAPIClient(serverURL, token string) *apiClient {
+	return &apiClient{
+		serverURL: strings.TrimRight(serverURL, "/"),
+		token:     token,
+		http:      &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// --- Wire types ---
+
+type user struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Nickname  string `json:"nickname"`
+}
+
+type team struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type channelInfo struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // O public, P private, D direct, G group DM
+}
+
+type post struct {
+	ID        string   `json:"id,omitempty"`
+	UserID    string   `json:"user_id,omitempty"`
+	ChannelID string   `json:"channel_id"`
+	RootID    string   `json:"root_id,omitempty"`
+	Message   string   `json:"message"`
+	Type      string   `json:"type,omitempty"` // "" for user posts, system_* otherwise
+	FileIDs   []string `json:"file_ids,omitempty"`
+	Metadata  *struct {
+		Files []fileInfo `json:"files,omitempty"`
+	} `json:"metadata,omitempty"`
+}
+
+type fileInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+}
+
+type command struct {
+	ID               string `json:"id,omitempty"`
+	Token            string `json:"token,omitempty"`
+	CreatorID        string `json:"creator_id,omitempty"`
+	TeamID           string `json:"team_id"`
+	Trigger          string `json:"trigger"`
+	Method           string `json:"method"`
+	URL              string `json:"url"`
+	DisplayName      string `json:"display_name,omitempty"`
+	Description      string `json:"description,omitempty"`
+	AutoComplete     bool   `json:"auto_complete"`
+	AutoCompleteDesc string `json:"auto_complete_desc,omitempty"`
+	AutoCompleteHint string `json:"auto_complete_hint,omitempty"`
+}
+
+// do sends a JSON request and decodes the JSON response into out (if non-nil).
+func (a *apiClient) do(ctx context.Context, method, path string, body, out any) error {
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.serverURL+"/api/v4"+path, rd)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return a.send(req, out)
+}
+
+func (a *apiClient) send(req *http.Request, out any) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		ae := &apiError{}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, ae) != nil || ae.Message == "" {
+			ae.Message = strings.TrimSpace(string(data))
+		}
+		ae.StatusCode = resp.StatusCode
+		ae.RetryAfter = channels.RetryAfterHeader(resp.Header)
+		return ae
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (a *apiClient) getMe(ctx context.Context) (*user, error) {
+	var u user
+	if err := a.do(ctx, http.MethodGet, "/users/me", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (a *apiClient) getUser(ctx context.Context, userID string) (*user, error) {
+	var u user
+	if err := a.do(ctx, http.MethodGet, "/users/"+url.PathEscape(userID), nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (a *apiClient) getChannel(ctx context.Context, channelID string) (*channelInfo, error) {
+	var ci channelInfo
+	if err := a.do(ctx, http.MethodGet, "/channels/"+url.PathEscape(channelID), nil, &ci); err != nil {
+		return nil, err
+	}
+	return &ci, nil
+}
+
+func (a *apiClient) createPost(ctx context.Context, p post) (*post, error) {
+	var out post
+	if err := a.do(ctx, http.MethodPost, "/posts", p, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (a *apiClient) patchPost(ctx context.Context, postID, message string) error {
+	return a.do(ctx, http.MethodPut, "/posts/"+url.PathEscape(postID)+"/patch", map[string]string{"message": message}, nil)
+}
+
+func (a *apiClient) deletePost(ctx context.Context, postID string) error {
+	return a.do(ctx, http.MethodDelete, "/posts/"+url.PathEscape(postID), nil, nil)
+}
+
+func (a *apiClient) addReaction(ctx context.Context, userID, postID, emoji string) error {
+	return a.do(ctx, http.MethodPost, "/reactions", map[string]string{
+		"user_id": userID, "post_id": postID, "emoji_name": emoji,
+	}, nil)
+}
+
+func (a *apiClient) removeReaction(ctx context.Context, userID, postID, emoji string) error {
+	return a.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(userID)+"/posts/"+url.PathEscape(postID)+
+		"/reactions/"+url.PathEscape(emoji), nil, nil)
+}
+
+func (a *apiClient) typing(ctx context.Context, userID, channelID, parentID string) error {
+	return a.do(ctx, http.MethodPost, "/users/"+url.PathEscape(userID)+"/typing", map[string]string{
+		"channel_id": channelID, "parent_id": parentID,
+	}, nil)
+}
+
+func (a *apiClient) myTeams(ctx context.Context) ([]team, error) {
+	var out []team
+	err := a.do(ctx, http.MethodGet, "/users/me/teams", nil, &out)
+	return out, err
+}
+
+func (a *apiClient) listCommands(ctx context.Context, teamID string) ([]command, error) {
+	var out []command
+	q := url.Values{"team_id": {teamID}, "custom_only": {"true"}}
+	err := a.do(ctx, http.MethodGet, "/commands?"+q.Encode(), nil, &out)
+	return out, err
+}
+
+func (a *apiClient) createCommand(ctx context.Context, cmd command) (*command, error) {
+	var out command
+	if err := a.do(ctx, http.MethodPost, "/commands", cmd, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (a *apiClient) updateCommand(ctx context.Context, cmd command) (*command, error) {
+	var out command
+	if err := a.do(ctx, http.MethodPut, "/commands/"+url.PathEscape(cmd.ID), cmd, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (a *apiClient) fileInfo(ctx context.Context, fileID string) (*fileInfo, error) {
+	var fi fileInfo
+	if err := a.do(ctx, http.MethodGet, "/files/"+url.PathEscape(fileID)+"/info", nil, &fi); err != nil {
+		return nil, err
+	}
+	return &fi, nil
+}
+
+// downloadFile fetches a file's content, reading at most maxBytes.
+func (a *apiClient) downloadFile(ctx context.Context, fileID string, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.serverURL+"/api/v4/files/"+url.PathEscape(fileID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, &apiError{StatusCode: resp.StatusCode, Message: "file download failed"}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxBytes)
+	}
+	return data, nil
+}
+
+// uploadFile uploads data to a channel and returns the new file ID.
+func (a *apiClient) uploadFile(ctx context.Context, channelID, filename string, data []byte) (string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.WriteField("channel_id", channelID); err != nil {
+		return "", err
+	}
+	fw, err := mw.CreateFormFile("files", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fw.Write(data); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.serverURL+"/api/v4/files", &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	var out struct {
+		FileInfos []fileInfo `json:"file_infos"`
+	}
+	if err := a.send(req, &out); err != nil {
+		return "", err
+	}
+	if len(out.FileInfos) == 0 {
+		return "", fmt.Errorf("mattermost upload returned no file")
+	}
+	return out.FileInfos[0].ID, nil
+}
@@ -0,0 +1,239 @@
+package mattermost
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	commandPath        = "/channels/mattermost/commands"
+	maxCommandBodySize = 64 << 10
+)
+
+// commandRouter routes slash command requests to the channel instance that
+// owns the command token. A single HTTP handler is shared across all
+// mattermost channel instances on the same server.
+type commandRouter struct {
+	mu           sync.RWMutex
+	tokens       map[string]*Channel // command token → channel
+	routeHandled bool                // true after first commandRoute() call
+}
+
+var globalRouter = &commandRouter{
+	tokens: make(map[string]*Channel),
+}
+
+func (r *commandRouter) register(token string, ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token] = ch
+}
+
+func (r *commandRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for token, owner := range r.tokens {
+		if owner == ch {
+			delete(r.tokens, token)
+		}
+	}
+}
+
+// lookup finds the channel for a token using constant-time comparison.
+func (r *commandRouter) lookup(token string) *Channel {
+	if token == "" {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var found *Channel
+	for t, ch := range r.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			found = ch
+		}
+	}
+	return found
+}
+
+// commandRoute returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *commandRouter) commandRoute() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return commandPath, r
+	}
+	return "", nil
+}
+
+// WebhookHandler returns the shared slash command path and router.
+// Only the first mattermost instance mounts the route; others return ("", nil).
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.commandRoute()
+}
+
+// ServeHTTP handles an outgoing slash command request from Mattermost.
+func (r *commandRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	req.Body = http.MaxBytesReader(w, req.Body, maxCommandBodySize)
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	token := req.PostForm.Get("token")
+	if token == "" {
+		// Mattermost also sends the token as "Authorization: Token <token>".
+		token = strings.TrimPrefix(req.Header.Get("Authorization"), "Token ")
+	}
+	ch := r.lookup(token)
+	if ch == nil {
+		slog.Warn("security.mattermost_command_bad_token", "remote", req.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	text, public := ch.handleCommand(req.Context(), slashCommand{
+		ChannelID: req.PostForm.Get("channel_id"),
+		UserID:    req.PostForm.Get("user_id"),
+		UserName:  req.PostForm.Get("user_name"),
+		Command:   req.PostForm.Get("command"),
+		Text:      strings.TrimSpace(req.PostForm.Get("text")),
+	})
+	respType := "ephemeral"
+	if public {
+		respType = "in_channel"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"response_type": respType, "text": text})
+}
+
+type slashCommand struct {
+	ChannelID, UserID, UserName, Command, Text string
+}
+
+// handleCommand routes a slash command into the agent and returns the
+// immediate response. Accepted commands are echoed publicly so the agent's
+// reply has visible context; errors are shown only to the caller.
+func (c *Channel) handleCommand(ctx context.Context, cmd slashCommand) (text string, public bool) {
+	if !c.IsRunning() {
+		return "The assistant is not available right now.", false
+	}
+	if cmd.Text == "" {
+		return fmt.Sprintf("Usage: %s <message>", cmd.Command), false
+	}
+	if cmd.ChannelID == "" || cmd.UserID == "" {
+		return "Invalid command request.", false
+	}
+
+	isDM := c.channelType(ctx, cmd.ChannelID) == "D"
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+	if isDM {
+		if !c.checkDMPolicy(ctx, cmd.UserID, cmd.ChannelID) {
+			return "You are not authorized to use this command.", false
+		}
+	} else if !c.checkGroupPolicy(ctx, cmd.UserID, cmd.ChannelID) {
+		return "This channel is not authorized to use this command.", false
+	}
+
+	displayName := c.displayName(ctx, cmd.UserID, cmd.UserName)
+	content := cmd.Text
+	if !isDM {
+		content = fmt.Sprintf("[From: %s]\n%s", displayName, content)
+	}
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), cmd.UserID, cmd.UserID, displayName, cmd.UserName, peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"user_id":         cmd.UserID,
+		"username":        cmd.UserName,
+		"display_name":    displayName,
+		"channel_id":      cmd.ChannelID,
+		"is_dm":           fmt.Sprintf("%t", isDM),
+		"local_key":       cmd.ChannelID,
+		"placeholder_key": cmd.ChannelID,
+		"platform":        c.Type(),
+		"command":         cmd.Command,
+	}
+	c.HandleMessage(cmd.UserID, cmd.ChannelID, content, nil, metadata, peerKind)
+
+	return fmt.Sprintf("**@%s** %s %s", cmd.UserName, cmd.Command, cmd.Text), true
+}
+
+// registerCommands creates or updates the configured slash command in every
+// team the bot belongs to, and registers command tokens with the router.
+// Failures are logged: the channel still works through mentions and DMs.
+func (c *Channel) registerCommands(ctx context.Context) {
+	if c.commandToken != "" {
+		globalRouter.register(c.commandToken, c)
+	}
+	sc := c.config.SlashCommand
+	if sc.Trigger == "" || sc.CallbackURL == "" {
+		return
+	}
+	teams, err := c.api.myTeams(ctx)
+	if err != nil {
+		slog.Warn("mattermost: list teams failed, slash command not registered", "error", err)
+		return
+	}
+	for _, t := range teams {
+		cmd, err := c.ensureCommand(ctx, t.ID)
+		if err != nil {
+			slog.Warn("mattermost: slash command registration failed",
+				"team", t.Name, "trigger", sc.Trigger, "error", err)
+			continue
+		}
+		globalRouter.register(cmd.Token, c)
+		slog.Info("mattermost: slash command registered", "team", t.Name, "trigger", "/"+sc.Trigger)
+	}
+}
+
+func (c *Channel) ensureCommand(ctx context.Context, teamID string) (*command, error) {
+	sc := c.config.SlashCommand
+	desc := sc.Description
+	if desc == "" {
+		desc = "Ask the assistant"
+	}
+	want := command{
+		TeamID:           teamID,
+		Trigger:          sc.Trigger,
+		Method:           "P",
+		URL:              sc.CallbackURL,
+		DisplayName:      c.botUsername,
+		Description:      desc,
+		AutoComplete:     true,
+		AutoCompleteDesc: desc,
+		AutoCompleteHint: "[message]",
+	}
+
+	existing, err := c.api.listCommands(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, cmd := range existing {
+		if cmd.Trigger != sc.Trigger {
+			continue
+		}
+		if cmd.URL == sc.CallbackURL {
+			return &cmd, nil
+		}
+		if cmd.CreatorID != c.botUserID {
+			return nil, fmt.Errorf("trigger /%s is already used by another integration", sc.Trigger)
+		}
+		want.ID, want.Token, want.CreatorID = cmd.ID, cmd.Token, cmd.CreatorID
+		return c.api.updateCommand(ctx, want)
+	}
+	return c.api.createCommand(ctx, want)
+}
@@ -0,0 +1,67 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+func TestEnsureCommand(t *testing.T) {
+	s := newStubServer(t)
+	ch, _, _ := startTestChannel(t, s, mattermostInstanceConfig{
+		SlashCommand: slashCommandConfig{Trigger: "/ask", CallbackURL: "https://gw.example.com/channels/mattermost/commands"},
+	})
+
+	created := s.Requests(http.MethodPost, "/api/v4/commands")
+	if len(created) != 1 || created[0].JSON()["trigger"] != "ask" || created[0].JSON()["team_id"] != "t1" {
+		t.Fatalf("create command requests = %+v", created)
+	}
+	if globalRouter.lookup("cmdtok") != ch {
+		t.Error("registered command token not routed to channel")
+	}
+
+	// A trigger owned by another integration is left alone.
+	s.mu.Lock()
+	s.commands = []command{{ID: "c9", Trigger: "ask", URL: "https://other", CreatorID: "someone"}}
+	s.mu.Unlock()
+	if _, err := ch.ensureCommand(t.Context(), "t1"); err == nil {
+		t.Error("expected conflict error for foreign trigger")
+	}
+}
+
+func TestCommandRouter(t *testing.T) {
+	s := newStubServer(t)
+	ch, mb, _ := startTestChannel(t, s, mattermostInstanceConfig{DMPolicy: "open"})
+	globalRouter.register("secret", ch)
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, commandPath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		globalRouter.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(url.Values{"token": {"wrong"}, "text": {"hi"}}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad token status = %d", rec.Code)
+	}
+
+	rec := post(url.Values{
+		"token": {"secret"}, "channel_id": {"dm1"}, "user_id": {"u1"},
+		"user_name": {"alice"}, "command": {"/ask"}, "text": {"summarize today"},
+	})
+	var resp map[string]string
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusOK || resp["response_type"] != "in_channel" {
+		t.Fatalf("response = %d %v", rec.Code, resp)
+	}
+	msg := channeltest.Consume(t, mb)
+	if msg.ChatID != "dm1" || msg.PeerKind != "direct" || msg.Content != "summarize today" {
+		t.Errorf("inbound = %+v", msg)
+	}
+}
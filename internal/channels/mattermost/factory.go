@@ -0,0 +1,90 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// mattermostCreds maps the credentials JSON from the channel_instances table.
+type mattermostCreds struct {
+	Token string `json:"token"` // bot or personal access token
+	// CommandToken verifies a slash command created manually in the
+	// Mattermost UI (auto-registered commands need no token here).
+	CommandToken string `json:"command_token,omitempty"`
+}
+
+// mattermostInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type mattermostInstanceConfig struct {
+	ServerURL      string   `json:"server_url"` // e.g. https://chat.example.com
+	DMPolicy       string   `json:"dm_policy,omitempty"`
+	GroupPolicy    string   `json:"group_policy,omitempty"`
+	AllowFrom      []string `json:"allow_from,omitempty"` // user IDs or channel IDs
+	RequireMention *bool    `json:"require_mention,omitempty"`
+	HistoryLimit   int      `json:"history_limit,omitempty"`
+	DMStream       *bool    `json:"dm_stream,omitempty"`
+	GroupStream    *bool    `json:"group_stream,omitempty"`
+	ReactionLevel  string   `json:"reaction_level,omitempty"`
+	MediaMaxMB     int      `json:"media_max_mb,omitempty"`
+	BlockReply     *bool    `json:"block_reply,omitempty"`
+	ThreadTTL      *int     `json:"thread_ttl,omitempty"` // hours; 0 disables thread auto-reply
+
+	SlashCommand slashCommandConfig `json:"slash_command,omitempty"`
+}
+
+// slashCommandConfig controls slash command registration.
+type slashCommandConfig struct {
+	Trigger     string `json:"trigger,omitempty"`      // without the leading slash, e.g. "ask"
+	CallbackURL string `json:"callback_url,omitempty"` // public URL of /channels/mattermost/commands
+	Description string `json:"description,omitempty"`
+}
+
+// Factory creates a Mattermost channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return buildChannel(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return buildChannel(name, creds, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func buildChannel(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var c mattermostCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode mattermost credentials: %w", err)
+		}
+	}
+	if c.Token == "" {
+		return nil, fmt.Errorf("mattermost token is required")
+	}
+
+	var ic mattermostInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode mattermost config: %w", err)
+		}
+	}
+
+	// Secure default: DB instances default to "pairing" for groups.
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "pairing"
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
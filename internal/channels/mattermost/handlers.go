@@ -0,0 +1,287 @@
+package mattermost
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// handlePost processes a "posted" WebSocket event.
+func (c *Channel) handlePost(ctx context.Context, p *post, channelType string, mentionIDs []string) {
+	// Skip own posts and system messages (joins, header changes, ...).
+	if p.UserID == c.botUserID || p.Type != "" {
+		return
+	}
+
+	senderID := p.UserID
+	channelID := p.ChannelID
+	isDM := channelType == "D"
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, channelID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, channelID) {
+		return
+	}
+
+	displayName := c.displayName(ctx, senderID, senderID)
+	content := p.Message
+	mediaList := c.downloadFiles(ctx, p)
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	// Threads map to sessions: a post in a thread uses the thread root; a
+	// top-level channel post starts a thread that the reply goes into.
+	rootID := p.RootID
+	localKey := channelID
+	historyKey := channelID
+	if rootID != "" {
+		localKey = channelID + ":thread:" + rootID
+		historyKey = localKey
+	} else if !isDM {
+		rootID = p.ID
+		localKey = channelID + ":thread:" + rootID
+	}
+
+	if !isDM && c.RequireMention() && !c.isMentioned(p, mentionIDs) && !c.participated(channelID, p.RootID) {
+		c.GroupHistory().Record(historyKey, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: p.ID,
+		}, c.HistoryLimit())
+
+		// Collect contact even when bot is not mentioned (cache prevents DB spam).
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		slog.Debug("mattermost group message recorded (no mention)",
+			"channel_id", channelID, "user", displayName)
+		return
+	}
+
+	content = strings.TrimSpace(c.stripBotMention(content))
+	if content == "" {
+		return
+	}
+
+	slog.Debug("mattermost message received",
+		"sender_id", senderID, "channel_id", channelID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	finalContent := content
+	if !isDM {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMediaPaths := c.GroupHistory().CollectMedia(historyKey); len(histMediaPaths) > 0 {
+				mediaPaths = append(mediaPaths, histMediaPaths...)
+			}
+			finalContent = c.GroupHistory().BuildContext(historyKey, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":      p.ID,
+		"user_id":         senderID,
+		"display_name":    channels.SanitizeDisplayName(displayName),
+		"channel_id":      channelID,
+		"is_dm":           fmt.Sprintf("%t", isDM),
+		"local_key":       localKey,
+		"placeholder_key": localKey,
+		"platform":        channels.TypeMattermost,
+	}
+	if rootID != "" {
+		metadata["message_thread_id"] = rootID
+	}
+
+	c.HandleMessage(senderID, channelID, finalContent, mediaPaths, metadata, peerKind)
+
+	if !isDM {
+		if rootID != "" && c.threadTTL > 0 {
+			c.threadParticip.Store(channelID+":"+rootID, time.Now())
+		}
+		c.GroupHistory().Clear(historyKey)
+	}
+}
+
+// isMentioned reports whether the post @-mentions the bot.
+func (c *Channel) isMentioned(p *post, mentionIDs []string) bool {
+	if slices.Contains(mentionIDs, c.botUserID) {
+		return true
+	}
+	return c.mentionRe != nil && c.mentionRe.MatchString(p.Message)
+}
+
+// participated reports whether the bot replied in this thread within threadTTL.
+func (c *Channel) participated(channelID, rootID string) bool {
+	if rootID == "" || c.threadTTL <= 0 {
+		return false
+	}
+	key := channelID + ":" + rootID
+	v, ok := c.threadParticip.Load(key)
+	if !ok {
+		return false
+	}
+	if time.Since(v.(time.Time)) >= c.threadTTL {
+		c.threadParticip.Delete(key)
+		return false
+	}
+	return true
+}
+
+func mentionPattern(username string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(username) + `\b`)
+}
+
+// stripBotMention removes "@botname" from the message text.
+func (c *Channel) stripBotMention(text string) string {
+	if c.mentionRe == nil {
+		return text
+	}
+	return strings.TrimLeft(c.mentionRe.ReplaceAllString(text, ""), " ,:")
+}
+
+// downloadFiles saves a post's attachments to temp files.
+func (c *Channel) downloadFiles(ctx context.Context, p *post) []media.MediaInfo {
+	var files []fileInfo
+	if p.Metadata != nil && len(p.Metadata.Files) > 0 {
+		files = p.Metadata.Files
+	} else {
+		for _, id := range p.FileIDs {
+			fi, err := c.api.fileInfo(ctx, id)
+			if err != nil {
+				slog.Warn("mattermost: file info failed", "file_id", id, "error", err)
+				continue
+			}
+			files = append(files, *fi)
+		}
+	}
+
+	var items []media.MediaInfo
+	for _, f := range files {
+		if f.Size > c.maxMedia {
+			slog.Warn("mattermost: file too large, skipping", "file", f.Name, "size", f.Size, "max", c.maxMedia)
+			continue
+		}
+		mimeType := f.MimeType
+		if mimeType == "" {
+			mimeType = media.DetectMIMEType(f.Name)
+		}
+		path, err := c.saveFile(ctx, f, mimeType)
+		if err != nil {
+			slog.Warn("mattermost: file download failed", "file", f.Name, "error", err)
+			continue
+		}
+		items = append(items, media.MediaInfo{
+			Type:        media.MediaKindFromMime(mimeType),
+			FilePath:    path,
+			ContentType: mimeType,
+			FileName:    f.Name,
+		})
+	}
+	return items
+}
+
+func (c *Channel) saveFile(ctx context.Context, f fileInfo, mimeType string) (string, error) {
+	data, err := c.api.downloadFile(ctx, f.ID, c.maxMedia)
+	if err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(f.Name)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	tmp, err := os.CreateTemp("", "goclaw_mattermost_*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, channelID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, channelID)
+	}
+	return false
+}
+
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, channelID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, channelID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+channelID, channelID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, channelID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), channelID, "default", nil)
+	if err != nil {
+		slog.Warn("mattermost: failed to request pairing code", "error", err)
+		return
+	}
+
+	// Do not expose pairing codes to whole channels.
+	var msg string
+	if strings.HasPrefix(senderID, "group:") {
+		msg = fmt.Sprintf("This channel is not authorized to use this bot.\n\n"+
+			"An admin can approve via CLI:\n  goclaw pairing approve %s\n\n"+
+			"Or approve via the GoClaw web UI (Pairing section).", code)
+	} else {
+		msg = fmt.Sprintf("GoClaw: access not configured.\n\nYour Mattermost user ID: `%s`\n\nPairing code: `%s`\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+			senderID, code, code)
+	}
+	if _, err := c.api.createPost(ctx, post{ChannelID: channelID, Message: msg}); err != nil {
+		slog.Warn("mattermost: failed to send pairing reply", "channel_id", channelID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
@@ -0,0 +1,93 @@
+package mattermost
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const reactionDebounceInterval = 700 * time.Millisecond
+
+// statusEmoji maps GoClaw agent status to Mattermost emoji names.
+var statusEmoji = map[string]string{
+	"thinking": "thinking_face",
+	"tool":     "hammer_and_wrench",
+	"web":      "hammer_and_wrench",
+	"coding":   "hammer_and_wrench",
+	"done":     "white_check_mark",
+	"error":    "x",
+	"stall":    "hourglass_flowing_sand",
+}
+
+// reactionState tracks per-message reaction state.
+type reactionState struct {
+	currentEmoji string
+	lastUpdate   time.Time
+	mu           sync.Mutex
+}
+
+// OnReactionEvent shows a typing indicator while the agent works and a status
+// emoji reaction on the user's post.
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, messageID string, status string) error {
+	channelID, rootID := splitLocalKey(chatID)
+	if status != "done" && status != "error" {
+		if err := c.api.typing(ctx, c.botUserID, channelID, rootID); err != nil {
+			slog.Debug("mattermost: typing failed", "channel_id", channelID, "error", err)
+		}
+	}
+
+	if c.config.ReactionLevel == "" || c.config.ReactionLevel == "off" || messageID == "" {
+		return nil
+	}
+	emoji, ok := statusEmoji[status]
+	if !ok {
+		return nil
+	}
+	// For "minimal" level, only show thinking and done
+	if c.config.ReactionLevel == "minimal" && status != "thinking" && status != "done" {
+		return nil
+	}
+
+	stateVal, _ := c.reactions.LoadOrStore(chatID+":"+messageID, &reactionState{})
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.currentEmoji == emoji || time.Since(st.lastUpdate) < reactionDebounceInterval {
+		return nil
+	}
+
+	// Remove previous reaction (if different)
+	if st.currentEmoji != "" {
+		if err := c.api.removeReaction(ctx, c.botUserID, messageID, st.currentEmoji); err != nil {
+			slog.Debug("mattermost: remove reaction failed", "emoji", st.currentEmoji, "error", err)
+		}
+	}
+	if err := c.api.addReaction(ctx, c.botUserID, messageID, emoji); err != nil {
+		slog.Debug("mattermost: add reaction failed", "emoji", emoji, "error", err)
+		return nil
+	}
+
+	st.currentEmoji = emoji
+	st.lastUpdate = time.Now()
+	return nil
+}
+
+// ClearReaction removes the current status emoji from a post.
+func (c *Channel) ClearReaction(ctx context.Context, chatID string, messageID string) error {
+	stateVal, ok := c.reactions.LoadAndDelete(chatID + ":" + messageID)
+	if !ok {
+		return nil
+	}
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.currentEmoji != "" {
+		if err := c.api.removeReaction(ctx, c.botUserID, messageID, st.currentEmoji); err != nil {
+			slog.Debug("mattermost: clear reaction failed", "emoji", st.currentEmoji, "error", err)
+		}
+	}
+	return nil
+}
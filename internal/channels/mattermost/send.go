@@ -0,0 +1,117 @@
+package mattermost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// Send delivers an outbound message to Mattermost.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("mattermost channel not running")
+	}
+
+	channelID := msg.ChatID
+	if channelID == "" {
+		return fmt.Errorf("empty chat ID for mattermost send")
+	}
+
+	placeholderKey := channelID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	rootID := msg.Metadata["message_thread_id"]
+
+	// Placeholder update (LLM retry notification)
+	if msg.Metadata["placeholder_update"] == "true" {
+		if postID, ok := c.placeholders.Load(placeholderKey); ok {
+			_ = c.api.patchPost(ctx, postID.(string), msg.Content)
+		}
+		return nil
+	}
+
+	content := msg.Content
+
+	// NO_REPLY: delete the streamed post, return
+	if content == "" && len(msg.Media) == 0 {
+		if postID, ok := c.placeholders.LoadAndDelete(placeholderKey); ok {
+			_ = c.api.deletePost(ctx, postID.(string))
+		}
+		return nil
+	}
+
+	// Upload media and attach to the first post.
+	var fileIDs []string
+	for _, m := range msg.Media {
+		id, err := c.uploadMedia(ctx, channelID, m.URL)
+		if err != nil {
+			slog.Warn("mattermost: file upload failed", "file", m.URL, "error", err)
+			content = fmt.Sprintf("%s\n\n[File upload failed: %s]", content, filepath.Base(m.URL))
+			continue
+		}
+		fileIDs = append(fileIDs, id)
+	}
+
+	chunks := channels.ChunkMarkdown(content, maxMessageLen)
+
+	// Edit the streamed post with the first chunk, send the rest as follow-ups.
+	// Files cannot be added to an existing post, so they go with the next one.
+	if postID, ok := c.placeholders.LoadAndDelete(placeholderKey); ok && len(chunks) > 0 {
+		if err := c.api.patchPost(ctx, postID.(string), chunks[0]); err == nil {
+			chunks = chunks[1:]
+		} else {
+			slog.Warn("mattermost placeholder edit failed, sending new post",
+				"channel_id", channelID, "error", err)
+		}
+	}
+	if len(chunks) == 0 && len(fileIDs) > 0 {
+		chunks = []string{""}
+	}
+
+	for i, chunk := range chunks {
+		p := post{ChannelID: channelID, RootID: rootID, Message: chunk}
+		if i == 0 {
+			p.FileIDs = fileIDs
+		}
+		if _, err := c.api.createPost(ctx, p); err != nil {
+			return fmt.Errorf("send mattermost post: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClassifySendError implements channels.SendErrorClassifier from the REST
+// status: 429 carries Retry-After, and 400/403/404 (deleted channel, bot
+// removed from the team) fail the same way on every retry.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		if f, ok := channels.ClassifyHTTPStatus(ae.StatusCode, ae.RetryAfter); ok {
+			return f
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// uploadMedia uploads a local file to the channel and returns its file ID.
+func (c *Channel) uploadMedia(ctx context.Context, channelID, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > c.maxMedia {
+		return "", fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return c.api.uploadFile(ctx, channelID, filepath.Base(path), data)
+}
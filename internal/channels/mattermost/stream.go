@@ -0,0 +1,100 @@
+package mattermost
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const streamThrottleInterval = 1000 * time.Millisecond
+
+// mattermostStream implements channels.ChannelStream for Mattermost.
+// The first update creates the reply post; later updates edit it in place.
+type mattermostStream struct {
+	api        *apiClient
+	channelID  string
+	rootID     string
+	postID     string    // reply post, set by the first update
+	lastUpdate time.Time // last create or patch call
+	mu         sync.Mutex
+}
+
+// Update creates or edits the reply post with accumulated text, throttled to avoid rate limits.
+func (s *mattermostStream) Update(ctx context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastUpdate) < streamThrottleInterval {
+		return
+	}
+	if len(fullText) > maxMessageLen {
+		fullText = fullText[:maxMessageLen] + "..."
+	}
+
+	if s.postID == "" {
+		p, err := s.api.createPost(ctx, post{ChannelID: s.channelID, RootID: s.rootID, Message: fullText})
+		if err != nil {
+			slog.Debug("mattermost stream post failed", "error", err)
+			return
+		}
+		s.postID = p.ID
+	} else if err := s.api.patchPost(ctx, s.postID, fullText); err != nil {
+		slog.Debug("mattermost stream chunk update failed", "error", err)
+		return
+	}
+	s.lastUpdate = time.Now()
+}
+
+// Stop finalizes the stream. Send() makes the final edit via the placeholder map,
+// so Stop() is a no-op here — FinalizeStream stores the post ID into c.placeholders.
+func (s *mattermostStream) Stop(_ context.Context) error {
+	return nil
+}
+
+// MessageID returns 0 — Mattermost uses string post IDs.
+func (s *mattermostStream) MessageID() int {
+	return 0
+}
+
+// StreamEnabled reports whether streaming is active for DMs or groups.
+func (c *Channel) StreamEnabled(isGroup bool) bool {
+	if isGroup {
+		return c.config.GroupStream != nil && *c.config.GroupStream
+	}
+	return c.config.DMStream != nil && *c.config.DMStream
+}
+
+// ReasoningStreamEnabled returns false — reasoning is not streamed to Mattermost.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
+
+// CreateStream creates a per-run streaming handle for the given local key.
+// Implements channels.StreamingChannel.
+func (c *Channel) CreateStream(_ context.Context, chatID string, _ bool) (channels.ChannelStream, error) {
+	channelID, rootID := splitLocalKey(chatID)
+	return &mattermostStream{api: c.api, channelID: channelID, rootID: rootID}, nil
+}
+
+// FinalizeStream stores the streamed post ID into c.placeholders so that
+// Send() can edit it with the final response.
+// Implements channels.StreamingChannel.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	ms, ok := stream.(*mattermostStream)
+	if !ok {
+		return
+	}
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.postID != "" {
+		c.placeholders.Store(chatID, ms.postID)
+	}
+}
+
+// splitLocalKey returns the channel ID and thread root of a local key.
+func splitLocalKey(localKey string) (channelID, rootID string) {
+	channelID, rootID, _ = strings.Cut(localKey, ":thread:")
+	return channelID, rootID
+}
@@ -0,0 +1,135 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsReadTimeout  = 2 * wsPingInterval
+	maxWSBackoff   = time.Minute
+)
+
+// wsEvent is a Mattermost WebSocket event. Nested objects such as the post
+// are JSON-encoded strings inside data.
+type wsEvent struct {
+	Event string `json:"event"`
+	Data  struct {
+		ChannelType string `json:"channel_type"`
+		Post        string `json:"post"`
+		Mentions    string `json:"mentions"` // JSON array of user IDs
+		SenderName  string `json:"sender_name"`
+	} `json:"data"`
+}
+
+// wsURL converts the server URL to the WebSocket endpoint.
+func wsURL(serverURL string) string {
+	u := strings.TrimRight(serverURL, "/") + "/api/v4/websocket"
+	if rest, ok := strings.CutPrefix(u, "https://"); ok {
+		return "wss://" + rest
+	}
+	return "ws://" + strings.TrimPrefix(u, "http://")
+}
+
+// runWebSocket keeps the event stream connected until ctx is cancelled,
+// reconnecting with exponential backoff.
+func (c *Channel) runWebSocket(ctx context.Context) {
+	defer c.wg.Done()
+	backoff := time.Second
+	for {
+		connected, err := c.streamEvents(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = time.Second
+		}
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		slog.Warn("mattermost: websocket disconnected", "name", c.Name(), "error", err, "retry_in", backoff)
+		c.MarkDegraded("mattermost websocket disconnected", fmt.Sprint(err), kind, true)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxWSBackoff)
+	}
+}
+
+// streamEvents connects once and dispatches events until the connection
+// fails. connected reports whether the handshake succeeded.
+func (c *Channel) streamEvents(ctx context.Context) (connected bool, err error) {
+	header := http.Header{"Authorization": {"Bearer " + c.api.token}}
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL(c.config.ServerURL), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return false, &apiError{StatusCode: resp.StatusCode, Message: "websocket authentication failed"}
+		}
+		return false, err
+	}
+	defer conn.Close()
+	conn.SetReadLimit(4 << 20)
+
+	c.MarkHealthy("connected as @" + c.botUsername)
+
+	// Close the connection on shutdown to unblock ReadMessage.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+
+		var ev wsEvent
+		if json.Unmarshal(data, &ev) != nil || ev.Event != "posted" {
+			continue
+		}
+		var p post
+		if err := json.Unmarshal([]byte(ev.Data.Post), &p); err != nil {
+			slog.Debug("mattermost: malformed post event", "error", err)
+			continue
+		}
+		var mentionIDs []string
+		if ev.Data.Mentions != "" {
+			_ = json.Unmarshal([]byte(ev.Data.Mentions), &mentionIDs)
+		}
+		c.handlePost(ctx, &p, ev.Data.ChannelType, mentionIDs)
+	}
+}
@@ -0,0 +1,346 @@
+package msteams
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	botFrameworkIssuer    = "https://api.botframework.com"
+	botFrameworkOpenIDURL = "https://login.botframework.com/v1/.well-known/openidconfiguration"
+	botFrameworkScope     = "https://api.botframework.com/.default"
+	loginBaseURL          = "https://login.microsoftonline.com"
+	defaultTenant         = "botframework.com" // multi-tenant bot registrations
+
+	jwtClockSkew       = 5 * time.Minute
+	signingKeysTTL     = 24 * time.Hour
+	keyRefreshCooldown = 5 * time.Minute
+	tokenRefreshSkew   = 5 * time.Minute
+)
+
+var errUnauthorized = errors.New("msteams: unauthorized activity")
+
+// jwtValidator verifies the Bearer token the Bot Connector service attaches
+// to every inbound activity: RS256 signature against the published Bot
+// Framework signing keys, issuer, audience (the bot's app ID), lifetime,
+// the serviceurl claim and the key's channel endorsements.
+type jwtValidator struct {
+	appID     string
+	openIDURL string
+	client    *http.Client
+	nowFn     func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]signingKey // kid -> key
+	fetchedAt   time.Time
+	lastRefresh time.Time
+}
+
+type signingKey struct {
+	pub          *rsa.PublicKey
+	endorsements []string
+}
+
+type jwtClaims struct {
+	Issuer     string          `json:"iss"`
+	Audience   json.RawMessage `json:"aud"` // string or array
+	Expiry     int64           `json:"exp"`
+	NotBefore  int64           `json:"nbf"`
+	ServiceURL string          `json:"serviceurl"`
+}
+
+func newJWTValidator(appID string) *jwtValidator {
+	return &jwtValidator{
+		appID:     appID,
+		openIDURL: botFrameworkOpenIDURL,
+		client:    &http.Client{Timeout: 15 * time.Second},
+		nowFn:     time.Now,
+	}
+}
+
+// validate checks the Authorization header of an inbound activity.
+func (v *jwtValidator) validate(ctx context.Context, authHeader string, act *activity) error {
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || token == "" {
+		return fmt.Errorf("%w: missing bearer token", errUnauthorized)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", errUnauthorized)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("%w: header: %v", errUnauthorized, err)
+	}
+	if header.Alg != "RS256" {
+		return fmt.Errorf("%w: unsupported alg %q", errUnauthorized, header.Alg)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: signature encoding", errUnauthorized)
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key.pub, crypto.SHA256, sum[:], sig); err != nil {
+		return fmt.Errorf("%w: bad signature", errUnauthorized)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("%w: claims: %v", errUnauthorized, err)
+	}
+	if claims.Issuer != botFrameworkIssuer {
+		return fmt.Errorf("%w: issuer %q", errUnauthorized, claims.Issuer)
+	}
+	if !audienceContains(claims.Audience, v.appID) {
+		return fmt.Errorf("%w: audience mismatch", errUnauthorized)
+	}
+	now := v.nowFn()
+	if claims.Expiry == 0 || now.After(time.Unix(claims.Expiry, 0).Add(jwtClockSkew)) {
+		return fmt.Errorf("%w: token expired", errUnauthorized)
+	}
+	if claims.NotBefore != 0 && now.Add(jwtClockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return fmt.Errorf("%w: token not yet valid", errUnauthorized)
+	}
+	// The serviceurl claim binds the token to the connector endpoint we will
+	// reply to — without it a replayed token could redirect our bot token.
+	if claims.ServiceURL == "" || !sameServiceURL(claims.ServiceURL, act.ServiceURL) {
+		return fmt.Errorf("%w: serviceUrl mismatch", errUnauthorized)
+	}
+	if len(key.endorsements) > 0 && !slices.Contains(key.endorsements, act.ChannelID) {
+		return fmt.Errorf("%w: key not endorsed for channel %q", errUnauthorized, act.ChannelID)
+	}
+	return nil
+}
+
+// key returns the signing key for kid, refreshing the key set when it is
+// stale or the kid is unknown (rate-limited to survive bogus kids).
+func (v *jwtValidator) key(ctx context.Context, kid string) (signingKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.nowFn()
+	k, ok := v.keys[kid]
+	stale := now.Sub(v.fetchedAt) > signingKeysTTL
+	if ok && !stale {
+		return k, nil
+	}
+	if stale || now.Sub(v.lastRefresh) > keyRefreshCooldown {
+		v.lastRefresh = now
+		keys, err := v.fetchKeys(ctx)
+		if err != nil {
+			if ok {
+				return k, nil // keep serving the cached key on refresh failure
+			}
+			return signingKey{}, fmt.Errorf("msteams: fetch signing keys: %w", err)
+		}
+		v.keys = keys
+		v.fetchedAt = now
+		k, ok = keys[kid]
+	}
+	if !ok {
+		return signingKey{}, fmt.Errorf("%w: unknown signing key %q", errUnauthorized, kid)
+	}
+	return k, nil
+}
+
+func (v *jwtValidator) fetchKeys(ctx context.Context) (map[string]signingKey, error) {
+	var meta struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, v.openIDURL, &meta); err != nil {
+		return nil, err
+	}
+	if meta.JWKSURI == "" {
+		return nil, fmt.Errorf("openid metadata has no jwks_uri")
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty          string   `json:"kty"`
+			Kid          string   `json:"kid"`
+			N            string   `json:"n"`
+			E            string   `json:"e"`
+			Endorsements []string `json:"endorsements"`
+		} `json:"keys"`
+	}
+	if err := v.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]signingKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = signingKey{
+			pub: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			},
+			endorsements: k.Endorsements,
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no RSA keys in %s", meta.JWKSURI)
+	}
+	return keys, nil
+}
+
+func (v *jwtValidator) getJSON(ctx context.Context, u string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: HTTP %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+func decodeSegment(seg string, out any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// unverifiedAudience extracts the first audience of a Bearer token without
+// checking it, so the router can pick the channel instance whose validator
+// then performs the real check.
+func unverifiedAudience(authHeader string) string {
+	token, _ := strings.CutPrefix(authHeader, "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	var claims jwtClaims
+	if decodeSegment(parts[1], &claims) != nil {
+		return ""
+	}
+	var one string
+	if json.Unmarshal(claims.Audience, &one) == nil {
+		return one
+	}
+	var many []string
+	if json.Unmarshal(claims.Audience, &many) == nil && len(many) > 0 {
+		return many[0]
+	}
+	return ""
+}
+
+func audienceContains(raw json.RawMessage, appID string) bool {
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		return one == appID
+	}
+	var many []string
+	return json.Unmarshal(raw, &many) == nil && slices.Contains(many, appID)
+}
+
+func sameServiceURL(a, b string) bool {
+	return strings.TrimSuffix(strings.ToLower(a), "/") == strings.TrimSuffix(strings.ToLower(b), "/")
+}
+
+// tokenSource obtains and caches the bot's outbound access token via the
+// OAuth client-credentials grant.
+type tokenSource struct {
+	appID    string
+	password string
+	tokenURL string
+	client   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newTokenSource(appID, password, tenantID string) *tokenSource {
+	if tenantID == "" {
+		tenantID = defaultTenant
+	}
+	return &tokenSource{
+		appID:    appID,
+		password: password,
+		tokenURL: loginBaseURL + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token",
+		client:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Token returns a cached access token, refreshing it shortly before expiry.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(tokenRefreshSkew).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.appID},
+		"client_secret": {s.password},
+		"scope":         {botFrameworkScope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("msteams: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return "", &apiError{StatusCode: resp.StatusCode, Body: "token: " + string(body), Login: true}
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", fmt.Errorf("msteams: decode token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("msteams: empty access token in response")
+	}
+	s.token = tok.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return s.token, nil
+}
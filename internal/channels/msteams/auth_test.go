@@ -0,0 +1,118 @@
+package msteams
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// signToken builds an RS256 JWT the way the Bot Connector does.
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	input := enc(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + enc(claims)
+	sum := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// newKeyServer serves OpenID metadata and a JWKS containing key under "k1".
+func newKeyServer(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openid":
+			json.NewEncoder(w).Encode(map[string]string{"jwks_uri": srv.URL + "/keys"})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{{
+				"kty":          "RSA",
+				"kid":          "k1",
+				"n":            base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":            base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				"endorsements": []string{"msteams"},
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func validClaims(serviceURL string) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":        botFrameworkIssuer,
+		"aud":        "app1",
+		"exp":        now.Add(time.Hour).Unix(),
+		"nbf":        now.Add(-time.Minute).Unix(),
+		"serviceurl": serviceURL,
+	}
+}
+
+func TestJWTValidator(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	srv := newKeyServer(t, key)
+
+	v := newJWTValidator("app1")
+	v.openIDURL = srv.URL + "/openid"
+	act := &activity{ChannelID: "msteams", ServiceURL: "https://smba.example.com/amer/"}
+
+	with := func(mutate func(map[string]any)) map[string]any {
+		c := validClaims(act.ServiceURL)
+		mutate(c)
+		return c
+	}
+	tests := []struct {
+		name    string
+		token   string
+		channel string
+		wantErr bool
+	}{
+		{"valid", signToken(t, key, "k1", validClaims(act.ServiceURL)), "msteams", false},
+		{"wrong audience", signToken(t, key, "k1", with(func(c map[string]any) { c["aud"] = "app2" })), "msteams", true},
+		{"wrong issuer", signToken(t, key, "k1", with(func(c map[string]any) { c["iss"] = "https://evil" })), "msteams", true},
+		{"expired", signToken(t, key, "k1", with(func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() })), "msteams", true},
+		{"service url mismatch", signToken(t, key, "k1", with(func(c map[string]any) { c["serviceurl"] = "https://evil.example.com" })), "msteams", true},
+		{"foreign key", signToken(t, other, "k1", validClaims(act.ServiceURL)), "msteams", true},
+		{"unknown kid", signToken(t, key, "k9", validClaims(act.ServiceURL)), "msteams", true},
+		{"not endorsed", signToken(t, key, "k1", validClaims(act.ServiceURL)), "webchat", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := *act
+			a.ChannelID = tt.channel
+			err := v.validate(t.Context(), "Bearer "+tt.token, &a)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errUnauthorized) {
+				t.Errorf("error %v is not errUnauthorized", err)
+			}
+		})
+	}
+
+	if err := v.validate(t.Context(), "", act); !errors.Is(err, errUnauthorized) {
+		t.Errorf("missing header error = %v", err)
+	}
+	if got := unverifiedAudience("Bearer " + signToken(t, key, "k1", validClaims(act.ServiceURL))); got != "app1" {
+		t.Errorf("unverifiedAudience() = %q", got)
+	}
+}
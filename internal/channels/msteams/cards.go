@@ -0,0 +1,170 @@
+package msteams
+
+import (
+	"strings"
+)
+
+const adaptiveCardVersion = "1.5"
+
+// shouldUseCard detects if content benefits from card rendering (code blocks, tables, headings).
+func shouldUseCard(text string) bool {
+	if strings.Contains(text, "```") || strings.Contains(text, "|---") || strings.Contains(text, "| ---") {
+		return true
+	}
+	for line := range strings.SplitSeq(text, "\n") {
+		if isHeading(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildAdaptiveCard renders markdown into an Adaptive Card. TextBlocks
+// render the Teams markdown subset (emphasis, lists, links); headings,
+// fenced code and tables get dedicated elements since TextBlock markdown
+// does not support them.
+func buildAdaptiveCard(markdown string) map[string]any {
+	var body []any
+	var para []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(para, "\n")); text != "" {
+			body = append(body, textBlock(text))
+		}
+		para = nil
+	}
+
+	lines := strings.Split(markdown, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			body = append(body, codeBlock(strings.Join(code, "\n")))
+		case isHeading(trimmed):
+			flush()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			size := "Default"
+			switch level {
+			case 1:
+				size = "Large"
+			case 2:
+				size = "Medium"
+			}
+			block := textBlock(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			block["weight"] = "Bolder"
+			block["size"] = size
+			body = append(body, block)
+		case isTableRow(trimmed) && i+1 < len(lines) && isTableSeparator(strings.TrimSpace(lines[i+1])):
+			flush()
+			rows := [][]string{splitTableRow(trimmed)}
+			for i += 2; i < len(lines) && isTableRow(strings.TrimSpace(lines[i])); i++ {
+				rows = append(rows, splitTableRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			body = append(body, table(rows))
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+
+	return map[string]any{
+		"type":    "AdaptiveCard",
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"version": adaptiveCardVersion,
+		"body":    body,
+		"msteams": map[string]any{"width": "Full"},
+	}
+}
+
+func textBlock(text string) map[string]any {
+	return map[string]any{"type": "TextBlock", "text": text, "wrap": true}
+}
+
+// codeBlock uses a RichTextBlock so code is shown literally rather than
+// interpreted as markdown.
+func codeBlock(code string) map[string]any {
+	return map[string]any{
+		"type": "RichTextBlock",
+		"inlines": []any{map[string]any{
+			"type":     "TextRun",
+			"text":     code,
+			"fontType": "Monospace",
+		}},
+	}
+}
+
+func table(rows [][]string) map[string]any {
+	width := 0
+	for _, r := range rows {
+		width = max(width, len(r))
+	}
+	columns := make([]any, width)
+	for i := range columns {
+		columns[i] = map[string]any{"width": 1}
+	}
+	tableRows := make([]any, 0, len(rows))
+	for _, r := range rows {
+		cells := make([]any, width)
+		for i := range cells {
+			text := ""
+			if i < len(r) {
+				text = r[i]
+			}
+			cells[i] = map[string]any{"type": "TableCell", "items": []any{textBlock(text)}}
+		}
+		tableRows = append(tableRows, map[string]any{"type": "TableRow", "cells": cells})
+	}
+	return map[string]any{
+		"type":             "Table",
+		"columns":          columns,
+		"rows":             tableRows,
+		"firstRowAsHeader": true,
+	}
+}
+
+func isHeading(line string) bool {
+	hashes := len(line) - len(strings.TrimLeft(line, "#"))
+	return hashes >= 1 && hashes <= 6 && len(line) > hashes && line[hashes] == ' '
+}
+
+func isTableRow(line string) bool {
+	return strings.HasPrefix(line, "|") && strings.HasSuffix(line, "|") && len(line) > 1
+}
+
+func isTableSeparator(line string) bool {
+	if !isTableRow(line) {
+		return false
+	}
+	return strings.Trim(line, "|-: ") == ""
+}
+
+func splitTableRow(line string) []string {
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return cells
+}
+
+// fileConsentCard asks the user for permission to upload a file to their
+// OneDrive. Teams answers with a fileConsent/invoke activity carrying the
+// context and, on accept, an upload URL.
+func fileConsentCard(name string, size int64, consentID string) attachment {
+	ctx := map[string]any{"consent_id": consentID}
+	return attachment{
+		ContentType: contentTypeFileConsent,
+		Name:        name,
+		Content: map[string]any{
+			"description":    "File from the assistant",
+			"sizeInBytes":    size,
+			"acceptContext":  ctx,
+			"declineContext": ctx,
+		},
+	}
+}
@@ -0,0 +1,149 @@
+package msteams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// apiError is a non-2xx response from the Bot Connector or login service.
+type apiError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from the Retry-After header on 429s
+	Login      bool          // returned by the login service while fetching a token
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("msteams API error %d: %s", e.StatusCode, e.Body)
+}
+
+func isAuthError(err error) bool {
+	var ae *apiError
+	return errors.As(err, &ae) && (ae.StatusCode == http.StatusUnauthorized || ae.StatusCode == http.StatusBadRequest)
+}
+
+// connectorClient calls the Bot Connector REST API (v3) on the serviceUrl of
+// a conversation, authenticating with the bot's client-credentials token.
+type connectorClient struct {
+	tokens *tokenSource
+	http   *http.Client
+}
+
+func newConnectorClient(tokens *tokenSource) *connectorClient {
+	return &connectorClient{
+		tokens: tokens,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func activitiesURL(serviceURL, conversationID string) string {
+	return strings.TrimSuffix(serviceURL, "/") + "/v3/conversations/" + url.PathEscape(conversationID) + "/activities"
+}
+
+// sendActivity posts an activity to a conversation and returns its ID.
+func (c *connectorClient) sendActivity(ctx context.Context, serviceURL, conversationID string, act *activity) (string, error) {
+	var res resourceResponse
+	if err := c.do(ctx, http.MethodPost, activitiesURL(serviceURL, conversationID), act, &res); err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+func (c *connectorClient) do(ctx context.Context, method, u string, body, out any) error {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &apiError{StatusCode: resp.StatusCode, Body: string(msg), RetryAfter: channels.RetryAfterHeader(resp.Header)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// download fetches an attachment. Inline images hosted by the connector
+// require the bot token; pre-authenticated file download URLs do not.
+func (c *connectorClient) download(ctx context.Context, u string, withAuth bool, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if withAuth {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &apiError{StatusCode: resp.StatusCode, Body: "download failed"}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxBytes)
+	}
+	return data, nil
+}
+
+// uploadFile PUTs a file to the OneDrive upload session handed out when a
+// user accepts a file consent card.
+func (c *connectorClient) uploadFile(ctx context.Context, uploadURL string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data)))
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &apiError{StatusCode: resp.StatusCode, Body: string(msg)}
+	}
+	return nil
+}
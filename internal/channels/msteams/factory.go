@@ -0,0 +1,79 @@
+package msteams
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// teamsCreds maps the credentials JSON from the channel_instances table.
+// These come from the Azure Bot registration.
+type teamsCreds struct {
+	AppID       string `json:"app_id"`
+	AppPassword string `json:"app_password"`
+	// TenantID is required for single-tenant bot registrations; empty means multi-tenant.
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// teamsInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type teamsInstanceConfig struct {
+	DMPolicy       string   `json:"dm_policy,omitempty"`
+	GroupPolicy    string   `json:"group_policy,omitempty"`
+	AllowFrom      []string `json:"allow_from,omitempty"` // AAD object IDs or conversation IDs
+	RequireMention *bool    `json:"require_mention,omitempty"`
+	HistoryLimit   int      `json:"history_limit,omitempty"`
+	RenderMode     string   `json:"render_mode,omitempty"` // auto, raw, card
+	Typing         *bool    `json:"typing,omitempty"`      // typing indicator while the agent works (default true)
+	MediaMaxMB     int      `json:"media_max_mb,omitempty"`
+	BlockReply     *bool    `json:"block_reply,omitempty"`
+	// ServiceURL is used for conversations the bot has not heard from since
+	// the last restart (e.g. cron deliveries). Defaults to the global Teams endpoint.
+	ServiceURL string `json:"service_url,omitempty"`
+}
+
+// Factory creates a Microsoft Teams channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return buildChannel(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return buildChannel(name, creds, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func buildChannel(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var c teamsCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode msteams credentials: %w", err)
+		}
+	}
+
+	var ic teamsInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode msteams config: %w", err)
+		}
+	}
+
+	// Secure default: DB instances default to "pairing" for groups.
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "pairing"
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
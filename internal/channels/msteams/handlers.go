@@ -0,0 +1,340 @@
+package msteams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// atTagRe matches Teams mention markup: <at>Display Name</at>.
+var atTagRe = regexp.MustCompile(`<at[^>]*>(.*?)</at>`)
+
+// handleMessage processes a validated message activity.
+func (c *Channel) handleMessage(ctx context.Context, act *activity) {
+	if act.From == nil || act.Conversation == nil || act.Recipient == nil || act.From.ID == act.Recipient.ID {
+		return
+	}
+
+	conversationID := act.Conversation.ID
+	isDM := act.Conversation.ConversationType == "personal" ||
+		(act.Conversation.ConversationType == "" && !act.Conversation.IsGroup)
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+	}
+
+	// AAD object IDs are stable across bots and tenants' conversations;
+	// the 29:... channel account ID is the fallback.
+	senderID := act.From.AADObjectID
+	if senderID == "" {
+		senderID = act.From.ID
+	}
+	// Channel threads carry ";messageid=<root>" — policy applies to the whole conversation.
+	groupID, _, _ := strings.Cut(conversationID, ";")
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, conversationID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, groupID, conversationID) {
+		return
+	}
+
+	displayName := act.From.Name
+	if displayName == "" {
+		displayName = senderID
+	}
+	mentioned := c.isMentioned(act)
+	content := c.cleanText(act)
+
+	mediaList := c.downloadAttachments(ctx, act)
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	if !isDM && c.RequireMention() && !mentioned {
+		c.GroupHistory().Record(conversationID, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: act.ID,
+		}, c.HistoryLimit())
+
+		// Collect contact even when bot is not mentioned (cache prevents DB spam).
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		slog.Debug("msteams group message recorded (no mention)",
+			"conversation_id", conversationID, "user", displayName)
+		return
+	}
+
+	slog.Debug("msteams message received",
+		"sender_id", senderID, "conversation_id", conversationID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	finalContent := content
+	if !isDM {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMediaPaths := c.GroupHistory().CollectMedia(conversationID); len(histMediaPaths) > 0 {
+				mediaPaths = append(mediaPaths, histMediaPaths...)
+			}
+			finalContent = c.GroupHistory().BuildContext(conversationID, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":        act.ID,
+		"user_id":           senderID,
+		"display_name":      channels.SanitizeDisplayName(displayName),
+		"conversation_type": act.Conversation.ConversationType,
+		"is_dm":             fmt.Sprintf("%t", isDM),
+		"platform":          channels.TypeMSTeams,
+	}
+	if act.Conversation.TenantID != "" {
+		metadata["tenant_id"] = act.Conversation.TenantID
+	}
+	var cd teamsChannelData
+	if len(act.ChannelData) > 0 && json.Unmarshal(act.ChannelData, &cd) == nil {
+		if cd.Team != nil {
+			metadata["team_id"] = cd.Team.ID
+		}
+		if cd.Channel != nil {
+			metadata["teams_channel_id"] = cd.Channel.ID
+		}
+	}
+
+	c.HandleMessage(senderID, conversationID, finalContent, mediaPaths, metadata, peerKind)
+
+	if !isDM {
+		c.GroupHistory().Clear(conversationID)
+	}
+}
+
+// isMentioned reports whether the activity carries a mention entity for the bot.
+func (c *Channel) isMentioned(act *activity) bool {
+	for _, e := range act.Entities {
+		if e.Type == "mention" && e.Mentioned != nil && e.Mentioned.ID == act.Recipient.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanText strips the bot's own mention and turns other <at> tags into
+// plain @names. Teams sends text with HTML entities escaped.
+func (c *Channel) cleanText(act *activity) string {
+	text := act.Text
+	for _, e := range act.Entities {
+		if e.Type == "mention" && e.Mentioned != nil && e.Mentioned.ID == act.Recipient.ID && e.Text != "" {
+			text = strings.ReplaceAll(text, e.Text, "")
+		}
+	}
+	text = atTagRe.ReplaceAllString(text, "@$1")
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
+// downloadAttachments saves file and inline image attachments to temp files.
+func (c *Channel) downloadAttachments(ctx context.Context, act *activity) []media.MediaInfo {
+	var items []media.MediaInfo
+	for _, a := range act.Attachments {
+		var (
+			u        string
+			name     = a.Name
+			withAuth bool
+		)
+		switch {
+		case a.ContentType == contentTypeFileDownload:
+			var info fileDownloadInfo
+			raw, _ := json.Marshal(a.Content)
+			if json.Unmarshal(raw, &info) != nil || info.DownloadURL == "" {
+				continue
+			}
+			u = info.DownloadURL // pre-authenticated SharePoint URL
+		case strings.HasPrefix(a.ContentType, "image/") && a.ContentURL != "":
+			u = a.ContentURL
+			// Inline images are served by the connector and need the bot token;
+			// never send it anywhere else.
+			withAuth = strings.HasPrefix(strings.ToLower(u), strings.ToLower(strings.TrimSuffix(act.ServiceURL, "/"))+"/")
+		default:
+			continue // text/html copies of the message, cards, etc.
+		}
+
+		mimeType := media.DetectMIMEType(name)
+		if strings.HasPrefix(a.ContentType, "image/") {
+			mimeType = a.ContentType
+		}
+		path, err := c.saveAttachment(ctx, u, withAuth, name, mimeType)
+		if err != nil {
+			slog.Warn("msteams: attachment download failed", "name", name, "error", err)
+			continue
+		}
+		items = append(items, media.MediaInfo{
+			Type:        media.MediaKindFromMime(mimeType),
+			FilePath:    path,
+			ContentType: mimeType,
+			FileName:    name,
+		})
+	}
+	return items
+}
+
+func (c *Channel) saveAttachment(ctx context.Context, u string, withAuth bool, name, mimeType string) (string, error) {
+	data, err := c.api.download(ctx, u, withAuth, c.maxMedia)
+	if err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(name)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	tmp, err := os.CreateTemp("", "goclaw_msteams_*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// handleInvoke answers invoke activities. Only file consent responses are
+// handled; the upload itself runs in the background.
+func (c *Channel) handleInvoke(act *activity) (int, any) {
+	if act.Name != invokeFileConsent {
+		return http.StatusOK, map[string]any{}
+	}
+	var resp fileConsentResponse
+	if err := json.Unmarshal(act.Value, &resp); err != nil {
+		return http.StatusBadRequest, map[string]any{}
+	}
+	consentID, _ := resp.Context["consent_id"].(string)
+	v, ok := c.pendingFiles.LoadAndDelete(consentID)
+	if !ok {
+		return http.StatusOK, map[string]any{}
+	}
+	pf := v.(pendingFile)
+	if resp.Action != "accept" || time.Now().After(pf.expiresAt) {
+		slog.Debug("msteams: file consent declined or expired", "file", pf.name)
+		return http.StatusOK, map[string]any{}
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.uploadAcceptedFile(c.runCtx, pf, &resp)
+	}()
+	return http.StatusOK, map[string]any{}
+}
+
+// uploadAcceptedFile uploads the file to the user's OneDrive and posts a file info card.
+func (c *Channel) uploadAcceptedFile(ctx context.Context, pf pendingFile, resp *fileConsentResponse) {
+	data, err := os.ReadFile(pf.path)
+	if err == nil {
+		err = c.api.uploadFile(ctx, resp.UploadInfo.UploadURL, data)
+	}
+	if err != nil {
+		slog.Warn("msteams: file upload failed", "file", pf.name, "error", err)
+		return
+	}
+
+	ref := c.ref(pf.conversationID)
+	if _, err := c.api.sendActivity(ctx, ref.ServiceURL, pf.conversationID, &activity{
+		Type: activityMessage,
+		Attachments: []attachment{{
+			ContentType: contentTypeFileInfo,
+			ContentURL:  resp.UploadInfo.ContentURL,
+			Name:        resp.UploadInfo.Name,
+			Content: map[string]any{
+				"uniqueId": resp.UploadInfo.UniqueID,
+				"fileType": resp.UploadInfo.FileType,
+			},
+		}},
+	}); err != nil {
+		slog.Warn("msteams: file info card failed", "file", pf.name, "error", err)
+	}
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, conversationID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, conversationID)
+	}
+	return false
+}
+
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, groupID, conversationID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, groupID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+groupID, conversationID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, conversationID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), conversationID, "default", nil)
+	if err != nil {
+		slog.Warn("msteams: failed to request pairing code", "error", err)
+		return
+	}
+
+	var msg string
+	if strings.HasPrefix(senderID, "group:") {
+		msg = fmt.Sprintf("This conversation is not authorized to use this bot.\n\n"+
+			"An admin can approve via CLI:\n  goclaw pairing approve %s\n\n"+
+			"Or approve via the GoClaw web UI (Pairing section).", code)
+	} else {
+		msg = fmt.Sprintf("GoClaw: access not configured.\n\nYour Teams user ID: `%s`\n\nPairing code: `%s`\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+			senderID, code, code)
+	}
+	ref := c.ref(conversationID)
+	if _, err := c.api.sendActivity(ctx, ref.ServiceURL, conversationID, &activity{
+		Type: activityMessage, Text: msg, TextFormat: "markdown",
+	}); err != nil {
+		slog.Warn("msteams: failed to send pairing reply", "conversation_id", conversationID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
@@ -0,0 +1,296 @@
+// Package msteams implements the Microsoft Teams channel on the Bot
+// Framework: activities arrive on the gateway's HTTP mux (JWT-validated),
+// replies go out through the Bot Connector REST API as Adaptive Cards or
+// markdown text, and files are delivered through file consent cards.
+package msteams
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	webhookPath         = "/channels/msteams/messages"
+	maxActivityBodySize = 1 << 20
+	pairingDebounceTime = 60 * time.Second
+	maxMessageLen       = 12000 // activity payloads are capped at ~28 KB after JSON encoding
+	defaultMediaMaxMB   = 20
+	defaultServiceURL   = "https://smba.trafficmanager.net/teams/"
+	typingInterval      = 3 * time.Second // Teams shows a typing activity for about 3 seconds
+	fileConsentTTL      = time.Hour
+)
+
+// Channel receives Bot Framework activities over the shared webhook route.
+type Channel struct {
+	*channels.BaseChannel
+	config    teamsInstanceConfig
+	appID     string
+	validator *jwtValidator
+	api       *connectorClient
+	maxMedia  int64
+	typing    bool
+
+	refs         sync.Map // conversation ID -> conversationRef
+	lastTyping   sync.Map // conversation ID -> time.Time
+	pendingFiles sync.Map // consent ID -> pendingFile
+
+	runCtx   context.Context
+	cancelFn context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// conversationRef is what the bot needs to message a conversation
+// proactively: the connector endpoint serving it and who the bot is there.
+// References are captured from every validated inbound activity.
+type conversationRef struct {
+	ServiceURL       string
+	ConversationType string
+	TenantID         string
+	BotID            string
+	BotName          string
+}
+
+// pendingFile is an outbound file waiting for the user to accept its consent card.
+type pendingFile struct {
+	path           string
+	name           string
+	conversationID string
+	expiresAt      time.Time
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new Microsoft Teams channel from instance config.
+func New(cfg teamsInstanceConfig, creds teamsCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if creds.AppID == "" || creds.AppPassword == "" {
+		return nil, fmt.Errorf("msteams app_id and app_password are required")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeMSTeams, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+	if cfg.ServiceURL == "" {
+		cfg.ServiceURL = defaultServiceURL
+	}
+
+	ch := &Channel{
+		BaseChannel: base,
+		config:      cfg,
+		appID:       creds.AppID,
+		validator:   newJWTValidator(creds.AppID),
+		api:         newConnectorClient(newTokenSource(creds.AppID, creds.AppPassword, creds.TenantID)),
+		maxMedia:    int64(cfg.MediaMaxMB) * 1024 * 1024,
+		typing:      cfg.Typing == nil || *cfg.Typing,
+	}
+	ch.SetPairingService(pairingSvc)
+	ch.SetRequireMention(requireMention)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeMSTeams, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start verifies the app credentials and starts accepting activities for this app ID.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("authenticating bot " + c.appID)
+
+	if _, err := c.api.tokens.Token(ctx); err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAuthError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("msteams authentication failed", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return fmt.Errorf("msteams auth: %w", err)
+	}
+
+	c.runCtx, c.cancelFn = context.WithCancel(context.Background())
+	globalRouter.register(c.appID, c)
+
+	c.SetRunning(true)
+	c.MarkHealthy("listening on " + webhookPath)
+	slog.Info("msteams channel started", "name", c.Name(), "app_id", c.appID)
+	return nil
+}
+
+// Stop stops accepting activities and waits for in-flight handlers.
+func (c *Channel) Stop(_ context.Context) error {
+	globalRouter.unregister(c)
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.wg.Wait()
+	c.pendingFiles.Range(func(k, v any) bool {
+		c.pendingFiles.Delete(k)
+		os.Remove(v.(pendingFile).path)
+		return true
+	})
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("msteams channel stopped", "name", c.Name())
+	return nil
+}
+
+// WebhookHandler returns the shared activity path and router.
+// Only the first msteams instance mounts the route; others return ("", nil).
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.route()
+}
+
+// rememberRef stores the conversation reference of a validated activity.
+func (c *Channel) rememberRef(act *activity) {
+	if act.Conversation == nil || act.Conversation.ID == "" || act.ServiceURL == "" {
+		return
+	}
+	ref := conversationRef{
+		ServiceURL:       act.ServiceURL,
+		ConversationType: act.Conversation.ConversationType,
+		TenantID:         act.Conversation.TenantID,
+	}
+	if act.Recipient != nil {
+		ref.BotID = act.Recipient.ID
+		ref.BotName = act.Recipient.Name
+	}
+	c.refs.Store(act.Conversation.ID, ref)
+}
+
+// ref returns the stored reference for a conversation, falling back to the
+// configured service URL for conversations not seen since startup.
+func (c *Channel) ref(conversationID string) conversationRef {
+	if v, ok := c.refs.Load(conversationID); ok {
+		return v.(conversationRef)
+	}
+	return conversationRef{ServiceURL: c.config.ServiceURL}
+}
+
+// --- Router ---
+
+// activityRouter routes activities to the channel instance whose app ID is
+// the token audience. A single HTTP handler is shared across all msteams
+// channel instances on the same server.
+type activityRouter struct {
+	mu           sync.RWMutex
+	apps         map[string]*Channel // app ID → channel
+	routeHandled bool                // true after first route() call
+}
+
+var globalRouter = &activityRouter{
+	apps: make(map[string]*Channel),
+}
+
+func (r *activityRouter) register(appID string, ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apps[appID] = ch
+}
+
+func (r *activityRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.apps[ch.appID] == ch {
+		delete(r.apps, ch.appID)
+	}
+}
+
+func (r *activityRouter) lookup(appID string) *Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.apps[appID]
+}
+
+// route returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *activityRouter) route() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return webhookPath, r
+	}
+	return "", nil
+}
+
+// ServeHTTP authenticates an activity and dispatches it. Messages are
+// handled asynchronously so the connector gets its 200 well within its
+// 15-second timeout; invokes are answered inline.
+func (r *activityRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxActivityBodySize))
+	if err != nil {
+		http.Error(w, "read error", http.StatusBadRequest)
+		return
+	}
+	var act activity
+	if err := json.Unmarshal(body, &act); err != nil {
+		http.Error(w, "invalid activity", http.StatusBadRequest)
+		return
+	}
+
+	auth := req.Header.Get("Authorization")
+	ch := r.lookup(unverifiedAudience(auth))
+	if ch == nil {
+		slog.Warn("security.msteams_unknown_app", "remote_addr", req.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := ch.validator.validate(req.Context(), auth, &act); err != nil {
+		if !errors.Is(err, errUnauthorized) {
+			slog.Warn("msteams: token validation unavailable", "error", err)
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		slog.Warn("security.msteams_jwt_invalid", "remote_addr", req.RemoteAddr, "error", err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ch.rememberRef(&act)
+
+	switch act.Type {
+	case activityInvoke:
+		status, resp := ch.handleInvoke(&act)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+		return
+	case activityMessage:
+		ch.wg.Add(1)
+		go func() {
+			defer ch.wg.Done()
+			ch.handleMessage(ch.runCtx, &act)
+		}()
+	}
+	w.WriteHeader(http.StatusOK)
+}
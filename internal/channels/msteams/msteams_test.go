@@ -0,0 +1,231 @@
+package msteams
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+// stubConnector is a minimal login + Bot Connector stub. Requests are recorded.
+type stubConnector struct {
+	*channeltest.Server
+}
+
+func newStubConnector(t *testing.T) *stubConnector {
+	t.Helper()
+	return &stubConnector{channeltest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			r.ParseForm()
+			if r.PostForm.Get("client_secret") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				io.WriteString(w, `{"error":"invalid_client"}`)
+				return
+			}
+			io.WriteString(w, `{"access_token":"bot-token","expires_in":3600}`)
+		case strings.HasPrefix(r.URL.Path, "/v3/conversations/"):
+			if r.Header.Get("Authorization") != "Bearer bot-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			io.WriteString(w, `{"id":"act-new"}`)
+		}
+	}))}
+}
+
+func (s *stubConnector) activities() []activity {
+	var out []activity
+	for _, r := range s.Requests(http.MethodPost, "") {
+		if strings.HasSuffix(r.Path, "/activities") {
+			var a activity
+			json.Unmarshal(r.Body, &a)
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+type testEnv struct {
+	ch   *Channel
+	mb   *bus.MessageBus
+	conn *stubConnector
+	key  *rsa.PrivateKey
+}
+
+func startTestChannel(t *testing.T, cfg teamsInstanceConfig) *testEnv {
+	t.Helper()
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys := newKeyServer(t, key)
+	conn := newStubConnector(t)
+
+	mb := bus.New()
+	ch, err := New(cfg, teamsCreds{AppID: "app1", AppPassword: "secret"}, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.validator.openIDURL = keys.URL + "/openid"
+	ch.api.tokens.tokenURL = conn.URL + "/token"
+	channeltest.Start(t, ch)
+	return &testEnv{ch: ch, mb: mb, conn: conn, key: key}
+}
+
+// post delivers an activity through the shared router with a signed token.
+func (e *testEnv) post(t *testing.T, act activity) *httptest.ResponseRecorder {
+	t.Helper()
+	act.ChannelID = "msteams"
+	act.ServiceURL = e.conn.URL + "/"
+	act.Recipient = &channelAccount{ID: "28:bot", Name: "Claw"}
+	body, _ := json.Marshal(act)
+	req := httptest.NewRequest(http.MethodPost, webhookPath, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+signToken(t, e.key, "k1", validClaims(act.ServiceURL)))
+	rec := httptest.NewRecorder()
+	globalRouter.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStart_InvalidCredentials(t *testing.T) {
+	conn := newStubConnector(t)
+	ch, err := New(teamsInstanceConfig{}, teamsCreds{AppID: "app1", AppPassword: "wrong"}, bus.New(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.api.tokens.tokenURL = conn.URL + "/token"
+	if err := ch.Start(context.Background()); !isAuthError(err) {
+		t.Fatalf("Start() error = %v, want auth error", err)
+	}
+}
+
+func TestRouter_RejectsUnsignedActivity(t *testing.T) {
+	e := startTestChannel(t, teamsInstanceConfig{DMPolicy: "open"})
+	act := activity{Type: activityMessage, ServiceURL: e.conn.URL + "/"}
+	body, _ := json.Marshal(act)
+	req := httptest.NewRequest(http.MethodPost, webhookPath, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+signToken(t, e.key, "k1", validClaims("https://evil.example.com")))
+	rec := httptest.NewRecorder()
+	globalRouter.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestGroupMentionGating(t *testing.T) {
+	e := startTestChannel(t, teamsInstanceConfig{GroupPolicy: "open"})
+	conv := &conversation{ID: "19:abc@thread.tacv2;messageid=100", ConversationType: "channel", IsGroup: true}
+	alice := &channelAccount{ID: "29:alice", Name: "Alice", AADObjectID: "aad-alice"}
+
+	e.post(t, activity{Type: activityMessage, ID: "100", From: alice, Conversation: conv, Text: "deploy is red"})
+	// Messages are handled asynchronously; wait for the unmentioned one to land in history.
+	channeltest.WaitFor(t, func() bool { return len(e.ch.GroupHistory().GetEntries(conv.ID)) > 0 })
+	rec := e.post(t, activity{
+		Type: activityMessage, ID: "101", From: alice, Conversation: conv,
+		Text:     "<at>Claw</at> why &amp; how?",
+		Entities: []entity{{Type: "mention", Text: "<at>Claw</at>", Mentioned: &channelAccount{ID: "28:bot"}}},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	msg := channeltest.Consume(t, e.mb)
+	if msg.ChatID != conv.ID || msg.PeerKind != "group" || msg.SenderID != "aad-alice" {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "deploy is red") || !strings.Contains(msg.Content, "[From: Alice]\nwhy & how?") {
+		t.Errorf("content = %q", msg.Content)
+	}
+
+	// The conversation reference is kept for proactive sends.
+	if ref := e.ch.ref(conv.ID); ref.ServiceURL != e.conn.URL+"/" || ref.BotID != "28:bot" {
+		t.Errorf("ref = %+v", ref)
+	}
+}
+
+func TestSend_AdaptiveCardAndTyping(t *testing.T) {
+	e := startTestChannel(t, teamsInstanceConfig{DMPolicy: "open"})
+	conv := &conversation{ID: "a:dm1", ConversationType: "personal"}
+	e.post(t, activity{Type: activityMessage, ID: "1", From: &channelAccount{ID: "29:bob", Name: "Bob"}, Conversation: conv, Text: "hi"})
+	channeltest.Consume(t, e.mb)
+
+	ctx := context.Background()
+	e.ch.OnReactionEvent(ctx, "a:dm1", "1", "thinking")
+	e.ch.OnReactionEvent(ctx, "a:dm1", "1", "tool") // throttled
+	if err := e.ch.Send(ctx, bus.OutboundMessage{ChatID: "a:dm1", Content: "plain reply"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ch.Send(ctx, bus.OutboundMessage{ChatID: "a:dm1", Content: "## Result\n\n```go\nx := 1\n```"}); err != nil {
+		t.Fatal(err)
+	}
+
+	acts := e.conn.activities()
+	if len(acts) != 3 {
+		t.Fatalf("activities = %+v", acts)
+	}
+	if acts[0].Type != activityTyping {
+		t.Errorf("first activity = %+v, want typing", acts[0])
+	}
+	if acts[1].Text != "plain reply" || acts[1].TextFormat != "markdown" {
+		t.Errorf("text activity = %+v", acts[1])
+	}
+	if len(acts[2].Attachments) != 1 || acts[2].Attachments[0].ContentType != contentTypeAdaptiveCard {
+		t.Fatalf("card activity = %+v", acts[2])
+	}
+	card, _ := json.Marshal(acts[2].Attachments[0].Content)
+	if !strings.Contains(string(card), `"fontType":"Monospace"`) || !strings.Contains(string(card), `"weight":"Bolder"`) {
+		t.Errorf("card = %s", card)
+	}
+}
+
+func TestSend_FileConsentFlow(t *testing.T) {
+	e := startTestChannel(t, teamsInstanceConfig{DMPolicy: "open"})
+	conv := &conversation{ID: "a:dm1", ConversationType: "personal"}
+	from := &channelAccount{ID: "29:bob", Name: "Bob"}
+	e.post(t, activity{Type: activityMessage, ID: "1", From: from, Conversation: conv, Text: "send the report"})
+	channeltest.Consume(t, e.mb)
+
+	path := filepath.Join(t.TempDir(), "report.txt")
+	os.WriteFile(path, []byte("quarterly"), 0o600)
+	ctx := context.Background()
+	if err := e.ch.Send(ctx, bus.OutboundMessage{ChatID: "a:dm1", Media: []bus.MediaAttachment{{URL: path}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	acts := e.conn.activities()
+	if len(acts) != 1 || len(acts[0].Attachments) != 1 || acts[0].Attachments[0].ContentType != contentTypeFileConsent {
+		t.Fatalf("consent activity = %+v", acts)
+	}
+	content := acts[0].Attachments[0].Content.(map[string]any)
+	consentCtx := content["acceptContext"]
+
+	value, _ := json.Marshal(map[string]any{
+		"type": "fileUpload", "action": "accept", "context": consentCtx,
+		"uploadInfo": map[string]any{
+			"name": "report.txt", "uploadUrl": e.conn.URL + "/upload/report.txt",
+			"contentUrl": "https://contoso.sharepoint.com/report.txt", "uniqueId": "u1", "fileType": "txt",
+		},
+	})
+	rec := e.post(t, activity{Type: activityInvoke, Name: invokeFileConsent, From: from, Conversation: conv, Value: value})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("invoke status = %d", rec.Code)
+	}
+
+	channeltest.WaitFor(t, func() bool { return len(e.conn.activities()) >= 2 })
+	uploads := e.conn.Requests(http.MethodPut, "/upload/report.txt")
+	if len(uploads) != 1 || string(uploads[0].Body) != "quarterly" || uploads[0].Header.Get("Content-Range") != "bytes 0-8/9" {
+		t.Fatalf("uploads = %+v", uploads)
+	}
+	acts = e.conn.activities()
+	if len(acts) != 2 || acts[1].Attachments[0].ContentType != contentTypeFileInfo {
+		t.Errorf("file info activity = %+v", acts)
+	}
+}
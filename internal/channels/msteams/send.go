@@ -0,0 +1,173 @@
+package msteams
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers an outbound message to a Teams conversation. Replies and
+// proactive messages take the same path: the stored conversation reference
+// supplies the connector endpoint.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("msteams channel not running")
+	}
+	conversationID := msg.ChatID
+	if conversationID == "" {
+		return fmt.Errorf("empty chat ID for msteams send")
+	}
+	ref := c.ref(conversationID)
+	content := msg.Content
+
+	// Files go through consent cards, which Teams only supports in personal chats.
+	for _, m := range msg.Media {
+		if ref.ConversationType != "personal" {
+			content = fmt.Sprintf("%s\n\n[File not delivered: %s — files can only be sent in personal chats]",
+				content, filepath.Base(m.URL))
+			continue
+		}
+		if err := c.sendFileConsent(ctx, ref, conversationID, m.URL); err != nil {
+			slog.Warn("msteams: file consent card failed", "file", m.URL, "error", err)
+			content = fmt.Sprintf("%s\n\n[File upload failed: %s]", content, filepath.Base(m.URL))
+		}
+	}
+
+	if content == "" {
+		return nil
+	}
+
+	renderMode := c.config.RenderMode
+	if renderMode == "" {
+		renderMode = "auto"
+	}
+	useCard := renderMode == "card" || (renderMode == "auto" && shouldUseCard(content))
+
+	for _, chunk := range channels.ChunkMarkdown(content, maxMessageLen) {
+		act := &activity{Type: activityMessage}
+		if useCard {
+			act.Attachments = []attachment{{ContentType: contentTypeAdaptiveCard, Content: buildAdaptiveCard(chunk)}}
+		} else {
+			act.Text = chunk
+			act.TextFormat = "markdown"
+		}
+		if _, err := c.api.sendActivity(ctx, ref.ServiceURL, conversationID, act); err != nil {
+			return fmt.Errorf("send msteams activity: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClassifySendError implements channels.SendErrorClassifier. A failed token
+// request breaks every conversation, so it pauses the channel; connector
+// responses are classified by status (429 carries Retry-After, 400/403/404
+// mean the conversation or activity is rejected for good).
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		if ae.Login {
+			return channels.SendFailure{ChannelWide: true}
+		}
+		if f, ok := channels.ClassifyHTTPStatus(ae.StatusCode, ae.RetryAfter); ok {
+			return f
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// sendFileConsent copies the file aside and asks the user to accept it.
+// The copy is uploaded by handleInvoke on accept and removed after fileConsentTTL.
+func (c *Channel) sendFileConsent(ctx context.Context, ref conversationRef, conversationID, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > c.maxMedia {
+		return fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	staged, err := stageFile(path)
+	if err != nil {
+		return err
+	}
+
+	idBytes := make([]byte, 12)
+	rand.Read(idBytes)
+	consentID := hex.EncodeToString(idBytes)
+	name := filepath.Base(path)
+	c.pendingFiles.Store(consentID, pendingFile{
+		path:           staged,
+		name:           name,
+		conversationID: conversationID,
+		expiresAt:      time.Now().Add(fileConsentTTL),
+	})
+	time.AfterFunc(fileConsentTTL, func() {
+		c.pendingFiles.Delete(consentID)
+		os.Remove(staged)
+	})
+
+	_, err = c.api.sendActivity(ctx, ref.ServiceURL, conversationID, &activity{
+		Type:        activityMessage,
+		Attachments: []attachment{fileConsentCard(name, info.Size(), consentID)},
+	})
+	if err != nil {
+		c.pendingFiles.Delete(consentID)
+	}
+	return err
+}
+
+// stageFile copies an outbound file to a private temp file so it survives
+// until the user answers the consent card.
+func stageFile(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp("", "goclaw_msteams_out_*"+filepath.Ext(path))
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// OnReactionEvent shows a typing indicator while the agent works. Teams bots
+// cannot react to messages, so typing is the only status signal.
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, _ string, status string) error {
+	if !c.typing || status == "done" || status == "error" {
+		return nil
+	}
+	if v, ok := c.lastTyping.Load(chatID); ok && time.Since(v.(time.Time)) < typingInterval {
+		return nil
+	}
+	c.lastTyping.Store(chatID, time.Now())
+
+	ref := c.ref(chatID)
+	if _, err := c.api.sendActivity(ctx, ref.ServiceURL, chatID, &activity{Type: activityTyping}); err != nil {
+		slog.Debug("msteams: typing failed", "conversation_id", chatID, "error", err)
+	}
+	return nil
+}
+
+// ClearReaction forgets the typing throttle; the indicator expires on its own.
+func (c *Channel) ClearReaction(_ context.Context, chatID string, _ string) error {
+	c.lastTyping.Delete(chatID)
+	return nil
+}
@@ -0,0 +1,103 @@
+package msteams
+
+import "encoding/json"
+
+// Bot Framework activity types and attachment content types used by the channel.
+const (
+	activityMessage            = "message"
+	activityTyping             = "typing"
+	activityInvoke             = "invoke"
+	activityConversationUpdate = "conversationUpdate"
+
+	contentTypeAdaptiveCard = "application/vnd.microsoft.card.adaptive"
+	contentTypeFileConsent  = "application/vnd.microsoft.teams.card.file.consent"
+	contentTypeFileInfo     = "application/vnd.microsoft.teams.card.file.info"
+	contentTypeFileDownload = "application/vnd.microsoft.teams.file.download.info"
+
+	invokeFileConsent = "fileConsent/invoke"
+)
+
+// activity is the subset of the Bot Framework Activity schema the channel reads and writes.
+type activity struct {
+	Type         string          `json:"type"`
+	ID           string          `json:"id,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	ChannelID    string          `json:"channelId,omitempty"`
+	ServiceURL   string          `json:"serviceUrl,omitempty"`
+	From         *channelAccount `json:"from,omitempty"`
+	Recipient    *channelAccount `json:"recipient,omitempty"`
+	Conversation *conversation   `json:"conversation,omitempty"`
+	ReplyToID    string          `json:"replyToId,omitempty"`
+	Text         string          `json:"text,omitempty"`
+	TextFormat   string          `json:"textFormat,omitempty"`
+	Attachments  []attachment    `json:"attachments,omitempty"`
+	Entities     []entity        `json:"entities,omitempty"`
+	ChannelData  json.RawMessage `json:"channelData,omitempty"`
+	Value        json.RawMessage `json:"value,omitempty"`
+}
+
+type channelAccount struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	AADObjectID string `json:"aadObjectId,omitempty"`
+}
+
+type conversation struct {
+	ID               string `json:"id"`
+	ConversationType string `json:"conversationType,omitempty"` // personal, groupChat, channel
+	TenantID         string `json:"tenantId,omitempty"`
+	IsGroup          bool   `json:"isGroup,omitempty"`
+	Name             string `json:"name,omitempty"`
+}
+
+type attachment struct {
+	ContentType string `json:"contentType"`
+	ContentURL  string `json:"contentUrl,omitempty"`
+	Content     any    `json:"content,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+type entity struct {
+	Type      string          `json:"type"`
+	Mentioned *channelAccount `json:"mentioned,omitempty"`
+	Text      string          `json:"text,omitempty"`
+}
+
+// teamsChannelData is the Teams-specific channelData of inbound activities.
+type teamsChannelData struct {
+	Tenant *struct {
+		ID string `json:"id"`
+	} `json:"tenant,omitempty"`
+	Team *struct {
+		ID string `json:"id"`
+	} `json:"team,omitempty"`
+	Channel *struct {
+		ID string `json:"id"`
+	} `json:"channel,omitempty"`
+}
+
+// fileDownloadInfo is the content of a file attachment sent to the bot in a personal chat.
+type fileDownloadInfo struct {
+	DownloadURL string `json:"downloadUrl"`
+	UniqueID    string `json:"uniqueId"`
+	FileType    string `json:"fileType"`
+}
+
+// fileConsentResponse is the value of a fileConsent/invoke activity.
+type fileConsentResponse struct {
+	Type       string         `json:"type"`   // fileUpload
+	Action     string         `json:"action"` // accept, decline
+	Context    map[string]any `json:"context"`
+	UploadInfo struct {
+		Name       string `json:"name"`
+		UploadURL  string `json:"uploadUrl"`
+		ContentURL string `json:"contentUrl"`
+		UniqueID   string `json:"uniqueId"`
+		FileType   string `json:"fileType"`
+	} `json:"uploadInfo"`
+}
+
+// resourceResponse is returned by the connector when an activity is created.
+type resourceResponse struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,508 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// Outbound messages are written to the outbox when they are published to the
+// bus, before the producer moves on, so a failed Channel.Send or a gateway
+// restart does not lose the agent's reply.
+// Failed sends are retried with per-chat exponential backoff that honors
+// platform retry-after hints. Rate limits and network failures affect every
+// chat on the channel, so they pause the whole channel instead. Messages that
+// fail permanently or exhaust their attempts move to the dead-letter table,
+// where they can be replayed.
+//
+// Delivery is at-least-once. Channels whose platform supports idempotent
+// sends derive their keys from the outbox ID with NextIdempotencyKey, so a
+// retry after an ambiguous failure is not shown twice.
+
+const (
+	defaultOutboxMaxAttempts   = 8
+	defaultOutboxMaxBackoff    = 10 * time.Minute
+	defaultDeadLetterRetention = 30 * 24 * time.Hour
+
+	defaultOutboxEnqueueTimeout = 2 * time.Second
+
+	outboxBaseBackoff  = 2 * time.Second
+	outboxLease        = 5 * time.Minute // a send in flight is retried after this if the gateway dies
+	outboxPollInterval = 2 * time.Second
+	outboxClaimBatch   = 100
+	outboxPruneEvery   = time.Hour
+)
+
+// SendFailure describes a failed Channel.Send for the outbox retry policy.
+type SendFailure struct {
+	Permanent   bool          // retrying cannot succeed (chat gone, bot blocked, bad request)
+	RetryAfter  time.Duration // platform retry-after hint; 0 = exponential backoff
+	ChannelWide bool          // rate limit or network failure: back off every chat on the channel
+}
+
+// permanentSendErrors are error fragments that mean the chat cannot be
+// reached until someone changes something on the platform side.
+var permanentSendErrors = []string{
+	"chat not found",
+	"bot was blocked",
+	"bot was kicked",
+	"user is deactivated",
+	"not enough rights",
+	"have no rights",
+}
+
+// ClassifySendError is the default classification for channels that do not
+// implement SendErrorClassifier: known permanent Bot API style failures are
+// dead-lettered at once, network failures pause the channel, and everything
+// else is retried with backoff for that chat only.
+func ClassifySendError(err error) SendFailure {
+	lower := strings.ToLower(err.Error())
+	for _, frag := range permanentSendErrors {
+		if strings.Contains(lower, frag) {
+			return SendFailure{Permanent: true}
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return SendFailure{ChannelWide: true}
+	}
+	return SendFailure{}
+}
+
+// ClassifyHTTPStatus maps a platform API status code to a SendFailure for
+// channels that talk plain HTTP. ok is false for statuses it has no opinion
+// on; callers then fall back to ClassifySendError.
+func ClassifyHTTPStatus(status int, retryAfter time.Duration) (f SendFailure, ok bool) {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return SendFailure{RetryAfter: retryAfter, ChannelWide: true}, true
+	case status == http.StatusUnauthorized:
+		// Bad or expired credentials break every chat until they are fixed.
+		return SendFailure{ChannelWide: true}, true
+	case status == http.StatusBadRequest, status == http.StatusForbidden,
+		status == http.StatusNotFound, status == http.StatusGone,
+		status == http.StatusRequestEntityTooLarge:
+		return SendFailure{Permanent: true}, true
+	case status >= 500:
+		return SendFailure{RetryAfter: retryAfter}, true
+	}
+	return SendFailure{}, false
+}
+
+// RetryAfterHeader parses a Retry-After header given in seconds or as an
+// HTTP date. It returns 0 when the header is absent or malformed.
+func RetryAfterHeader(h http.Header) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+type idempotencyKeyCtxKey struct{}
+
+// idempotencySeq hands out one key per platform request made by a send.
+type idempotencySeq struct {
+	base uuid.UUID
+	n    atomic.Int64
+}
+
+// WithIdempotencyKey scopes platform idempotency keys for one delivery
+// attempt of the outbox message with the given ID.
+func WithIdempotencyKey(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtxKey{}, &idempotencySeq{base: id})
+}
+
+// NextIdempotencyKey returns a UUID for the next platform request made while
+// sending an outbox message, or "" when the send is not outbox-backed. Keys
+// are derived from the outbox ID and the request's position in the send, so
+// a retry that splits the message the same way reuses the same keys.
+func NextIdempotencyKey(ctx context.Context) string {
+	seq, ok := ctx.Value(idempotencyKeyCtxKey{}).(*idempotencySeq)
+	if !ok {
+		return ""
+	}
+	return uuid.NewSHA1(seq.base, []byte(strconv.FormatInt(seq.n.Add(1), 10))).String()
+}
+
+// outbox holds the durable delivery state shared by the dispatcher and the
+// retry loop.
+type outbox struct {
+	store       store.OutboxStore
+	maxAttempts int
+	maxBackoff  time.Duration
+	retention   time.Duration
+	// enqueueTimeout bounds the write made on the publisher's goroutine.
+	enqueueTimeout time.Duration
+
+	mu      sync.Mutex
+	backoff map[string]*channelBackoff // backoffKey → backoff state
+}
+
+// channelBackoff pauses deliveries to a chat, or to a whole channel, after
+// consecutive failures.
+type channelBackoff struct {
+	failures int
+	until    time.Time
+}
+
+// SetOutbox enables durable outbound delivery and installs the Manager as
+// the bus's outbound recorder. Must be called before StartAll. A nil store
+// or a disabled config keeps the direct, non-persistent dispatch.
+func (m *Manager) SetOutbox(s store.OutboxStore, cfg *config.OutboxConfig) {
+	if s == nil || (cfg != nil && cfg.Disabled) {
+		m.outbox = nil
+		m.bus.SetOutboundRecorder(nil)
+		return
+	}
+	o := &outbox{
+		store:       s,
+		maxAttempts: defaultOutboxMaxAttempts,
+		maxBackoff:  defaultOutboxMaxBackoff,
+		retention:   defaultDeadLetterRetention,
+		backoff:     make(map[string]*channelBackoff),
+
+		enqueueTimeout: defaultOutboxEnqueueTimeout,
+	}
+	if cfg != nil {
+		if cfg.MaxAttempts > 0 {
+			o.maxAttempts = cfg.MaxAttempts
+		}
+		if cfg.MaxBackoffSeconds > 0 {
+			o.maxBackoff = time.Duration(cfg.MaxBackoffSeconds) * time.Second
+		}
+		if cfg.DeadLetterRetentionDays > 0 {
+			o.retention = time.Duration(cfg.DeadLetterRetentionDays) * 24 * time.Hour
+		}
+	}
+	m.outbox = o
+	m.bus.SetOutboundRecorder(m)
+}
+
+// backoffKey identifies backoff state: a chat on a channel, or the channel
+// itself when chatID is empty.
+func backoffKey(channel, chatID string) string {
+	if chatID == "" {
+		return channel
+	}
+	return channel + "\x00" + chatID
+}
+
+// pausedUntil returns when the chat's backoff ends, taking a channel-wide
+// pause into account (zero when not paused).
+func (o *outbox) pausedUntil(channel, chatID string, now time.Time) time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var until time.Time
+	for _, key := range []string{backoffKey(channel, ""), backoffKey(channel, chatID)} {
+		if b := o.backoff[key]; b != nil && b.until.After(now) && b.until.After(until) {
+			until = b.until
+		}
+	}
+	return until
+}
+
+// recordSuccess clears the chat's backoff and the channel-wide one: a
+// delivery proves the platform is reachable again.
+func (o *outbox) recordSuccess(channel, chatID string) {
+	o.mu.Lock()
+	delete(o.backoff, backoffKey(channel, chatID))
+	delete(o.backoff, backoffKey(channel, ""))
+	o.mu.Unlock()
+}
+
+// recordFailure extends the chat's backoff, or the channel's for channel-wide
+// failures, and returns when to retry. A platform retry-after hint wins over
+// the exponential delay.
+func (o *outbox) recordFailure(channel, chatID string, f SendFailure, now time.Time) time.Time {
+	key := backoffKey(channel, chatID)
+	if f.ChannelWide {
+		key = backoffKey(channel, "")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	b := o.backoff[key]
+	if b == nil {
+		b = &channelBackoff{}
+		o.backoff[key] = b
+	}
+	b.failures++
+	delay := f.RetryAfter
+	if delay <= 0 {
+		delay = o.backoffDelay(b.failures)
+	}
+	if until := now.Add(delay); until.After(b.until) {
+		b.until = until
+	}
+	return b.until
+}
+
+// backoffDelay is 2s doubled per consecutive failure, capped at maxBackoff,
+// with ±20% jitter so chats recovering together do not retry in lockstep.
+func (o *outbox) backoffDelay(failures int) time.Duration {
+	d := o.maxBackoff
+	if shift := failures - 1; shift < 20 {
+		d = min(outboxBaseBackoff<<shift, o.maxBackoff)
+	}
+	jitter := d / 5
+	return d - jitter + rand.N(2*jitter+1)
+}
+
+// RecordOutbound implements bus.OutboundRecorder: it writes the message to
+// the outbox on the publisher's goroutine. The row is leased while the
+// message waits in the bus, so the retry loop only picks it up if the
+// dispatcher never gets to it. On failure or when the write takes longer
+// than the enqueue timeout, the message keeps a nil OutboxID and is sent
+// directly, so a slow or unavailable database does not stall the agent.
+func (m *Manager) RecordOutbound(msg *bus.OutboundMessage) {
+	if m.outbox == nil || IsInternalChannel(msg.Channel) {
+		return
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("outbox: encode message failed, sending directly", "channel", msg.Channel, "error", err)
+		return
+	}
+	tenantID := msg.TenantID
+	if tenantID == uuid.Nil {
+		tenantID, _ = m.ChannelTenantID(msg.Channel)
+	}
+	row := &store.OutboxMessage{
+		ID:            store.GenNewID(),
+		TenantID:      tenantID,
+		Channel:       msg.Channel,
+		ChatID:        msg.ChatID,
+		Payload:       payload,
+		NextAttemptAt: time.Now().UTC().Add(outboxLease),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.outbox.enqueueTimeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- m.outbox.store.Enqueue(ctx, row) }()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		go m.dropAbandonedRow(done, row.ID)
+	}
+	if err != nil {
+		slog.Error("outbox: enqueue failed, sending directly", "channel", msg.Channel, "chat_id", msg.ChatID, "error", err)
+		return
+	}
+	msg.OutboxID = row.ID
+}
+
+// dropAbandonedRow waits for an enqueue that outlived its timeout and deletes
+// the row if it was written anyway: the message has been sent directly, and
+// the retry loop would otherwise send it again once the lease expires.
+func (m *Manager) dropAbandonedRow(done <-chan error, id uuid.UUID) {
+	if err := <-done; err == nil {
+		m.finishOutboxMessage(context.Background(), id)
+	}
+}
+
+// DiscardOutbound implements bus.OutboundRecorder: it deletes the row of a
+// message the bus refused, since its producer will not count it as sent.
+func (m *Manager) DiscardOutbound(msg bus.OutboundMessage) {
+	if m.outbox == nil || msg.OutboxID == uuid.Nil {
+		return
+	}
+	m.finishOutboxMessage(context.Background(), msg.OutboxID)
+}
+
+// deliverRecorded makes the first delivery attempt for a message recorded at
+// publish time, unless its chat or channel is backing off; then the row is
+// moved to the end of the backoff without spending an attempt.
+func (m *Manager) deliverRecorded(ctx context.Context, msg bus.OutboundMessage) {
+	row := store.OutboxMessage{ID: msg.OutboxID, Channel: msg.Channel, ChatID: msg.ChatID}
+	if until := m.outbox.pausedUntil(msg.Channel, msg.ChatID, time.Now().UTC()); !until.IsZero() {
+		if err := m.outbox.store.Reschedule(ctx, row.ID, 0, until, ""); err != nil {
+			slog.Warn("outbox: defer message failed", "id", row.ID, "error", err)
+		}
+		return
+	}
+	m.deliverOutboxMessage(ctx, row, msg)
+}
+
+// deliverOutboxMessage makes one delivery attempt and records its outcome:
+// delivered rows are deleted, transient failures rescheduled, and permanent
+// or exhausted ones dead-lettered.
+func (m *Manager) deliverOutboxMessage(ctx context.Context, row store.OutboxMessage, msg bus.OutboundMessage) {
+	o := m.outbox
+	m.mu.RLock()
+	channel, exists := m.channels[msg.Channel]
+	m.mu.RUnlock()
+
+	var err error
+	var failure SendFailure
+	if !exists {
+		// The channel may be reloading; retry until attempts run out.
+		err = fmt.Errorf("channel %s not found", msg.Channel)
+	} else {
+		var ok bool
+		if msg, ok = dropMissingMedia(row.ID, msg); !ok {
+			m.finishOutboxMessage(ctx, row.ID)
+			return
+		}
+		sendCtx := WithIdempotencyKey(outboundContext(ctx, msg), row.ID)
+		if err = channel.Send(sendCtx, msg); err != nil {
+			if c, ok := channel.(SendErrorClassifier); ok {
+				failure = c.ClassifySendError(err)
+			} else {
+				failure = ClassifySendError(err)
+			}
+		}
+	}
+
+	if err == nil {
+		o.recordSuccess(msg.Channel, msg.ChatID)
+		m.finishOutboxMessage(ctx, row.ID)
+		cleanupTempMedia(msg)
+		return
+	}
+
+	attempts := row.Attempts + 1
+	if failure.Permanent || attempts >= o.maxAttempts {
+		slog.Error("outbox: message dead-lettered",
+			"id", row.ID, "channel", msg.Channel, "chat_id", msg.ChatID,
+			"attempts", attempts, "permanent", failure.Permanent,
+			"content_preview", Truncate(msg.Content, 160), "error", err)
+		if dlErr := o.store.MoveToDeadLetter(ctx, row.ID, attempts, err.Error()); dlErr != nil {
+			slog.Error("outbox: move to dead letters failed", "id", row.ID, "error", dlErr)
+		}
+		if exists {
+			m.notifySendFailure(ctx, channel, msg, err)
+		}
+		cleanupTempMedia(msg)
+		return
+	}
+
+	next := o.recordFailure(msg.Channel, msg.ChatID, failure, time.Now().UTC())
+	slog.Warn("outbox: send failed, will retry",
+		"id", row.ID, "channel", msg.Channel, "chat_id", msg.ChatID,
+		"attempt", attempts, "max", o.maxAttempts, "retry_at", next, "error", err)
+	if rsErr := o.store.Reschedule(ctx, row.ID, attempts, next, err.Error()); rsErr != nil {
+		slog.Error("outbox: reschedule failed", "id", row.ID, "error", rsErr)
+	}
+}
+
+// dropMissingMedia removes local media files that no longer exist. A
+// recorded message can outlive its files: temp media are gone after a
+// gateway restart, and are removed when a message is dead-lettered. The
+// text is still delivered. Returns false when nothing is left to send.
+func dropMissingMedia(id uuid.UUID, msg bus.OutboundMessage) (bus.OutboundMessage, bool) {
+	if len(msg.Media) == 0 {
+		return msg, true
+	}
+	kept := make([]bus.MediaAttachment, 0, len(msg.Media))
+	for _, media := range msg.Media {
+		if media.URL != "" && !strings.Contains(media.URL, "://") {
+			if _, err := os.Stat(media.URL); err != nil {
+				slog.Warn("outbox: media file missing, sending without it",
+					"id", id, "channel", msg.Channel, "chat_id", msg.ChatID, "path", media.URL, "error", err)
+				continue
+			}
+		}
+		kept = append(kept, media)
+	}
+	msg.Media = kept
+	if len(kept) == 0 && msg.Content == "" {
+		slog.Warn("outbox: message dropped, all its media files are missing",
+			"id", id, "channel", msg.Channel, "chat_id", msg.ChatID)
+		return msg, false
+	}
+	return msg, true
+}
+
+func (m *Manager) finishOutboxMessage(ctx context.Context, id uuid.UUID) {
+	if err := m.outbox.store.Delete(ctx, id); err != nil {
+		slog.Warn("outbox: delete delivered message failed", "id", id, "error", err)
+	}
+}
+
+// runOutbox retries due messages (including ones left by a previous gateway
+// process) and prunes old dead letters until ctx is cancelled.
+func (m *Manager) runOutbox(ctx context.Context) {
+	poll := time.NewTicker(outboxPollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(outboxPruneEvery)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			m.retryDueOutbox(ctx)
+		case <-prune.C:
+			if n, err := m.outbox.store.PruneDeadLetters(ctx, m.outbox.retention); err != nil {
+				slog.Warn("outbox: prune dead letters failed", "error", err)
+			} else if n > 0 {
+				slog.Info("outbox: pruned dead letters", "count", n)
+			}
+		}
+	}
+}
+
+// retryDueOutbox claims due messages and attempts each one. Messages for a
+// chat or channel that is backing off are pushed to the end of its backoff without
+// spending an attempt.
+func (m *Manager) retryDueOutbox(ctx context.Context) {
+	o := m.outbox
+	now := time.Now().UTC()
+	rows, err := o.store.ClaimDue(ctx, now, outboxLease, outboxClaimBatch)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Warn("outbox: claim due messages failed", "error", err)
+		}
+		return
+	}
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return
+		}
+		if until := o.pausedUntil(row.Channel, row.ChatID, time.Now().UTC()); !until.IsZero() {
+			if err := o.store.Reschedule(ctx, row.ID, row.Attempts, until, row.LastError); err != nil {
+				slog.Warn("outbox: defer message failed", "id", row.ID, "error", err)
+			}
+			continue
+		}
+		var msg bus.OutboundMessage
+		if err := json.Unmarshal(row.Payload, &msg); err != nil {
+			slog.Error("outbox: undecodable message dead-lettered", "id", row.ID, "error", err)
+			if dlErr := o.store.MoveToDeadLetter(ctx, row.ID, row.Attempts, "decode payload: "+err.Error()); dlErr != nil {
+				slog.Error("outbox: move to dead letters failed", "id", row.ID, "error", dlErr)
+			}
+			continue
+		}
+		m.deliverOutboxMessage(ctx, row, msg)
+	}
+}
@@ -0,0 +1,418 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// memOutbox is an in-memory store.OutboxStore.
+type memOutbox struct {
+	mu   sync.Mutex
+	rows map[uuid.UUID]*store.OutboxMessage
+	dead map[uuid.UUID]*store.OutboxDeadLetter
+}
+
+func newMemOutbox() *memOutbox {
+	return &memOutbox{rows: map[uuid.UUID]*store.OutboxMessage{}, dead: map[uuid.UUID]*store.OutboxDeadLetter{}}
+}
+
+func (s *memOutbox) Enqueue(_ context.Context, msg *store.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *msg
+	s.rows[msg.ID] = &cp
+	return nil
+}
+
+func (s *memOutbox) ClaimDue(_ context.Context, now time.Time, lease time.Duration, _ int) ([]store.OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []store.OutboxMessage
+	for _, r := range s.rows {
+		if !r.NextAttemptAt.After(now) {
+			r.NextAttemptAt = now.Add(lease)
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+
+func (s *memOutbox) Delete(_ context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rows, id)
+	return nil
+}
+
+func (s *memOutbox) Reschedule(_ context.Context, id uuid.UUID, attempts int, next time.Time, lastErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.rows[id]
+	r.Attempts, r.NextAttemptAt, r.LastError = attempts, next, lastErr
+	return nil
+}
+
+func (s *memOutbox) MoveToDeadLetter(_ context.Context, id uuid.UUID, attempts int, lastErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.rows[id]
+	s.dead[id] = &store.OutboxDeadLetter{ID: id, Channel: r.Channel, ChatID: r.ChatID, Payload: r.Payload, Attempts: attempts, LastError: lastErr}
+	delete(s.rows, id)
+	return nil
+}
+
+func (s *memOutbox) ListDeadLetters(context.Context, uuid.UUID, string, int, int) ([]store.OutboxDeadLetter, error) {
+	return nil, nil
+}
+func (s *memOutbox) GetDeadLetter(context.Context, uuid.UUID) (*store.OutboxDeadLetter, error) {
+	return nil, nil
+}
+func (s *memOutbox) ReplayDeadLetter(context.Context, uuid.UUID) error              { return nil }
+func (s *memOutbox) DeleteDeadLetter(context.Context, uuid.UUID) error              { return nil }
+func (s *memOutbox) PruneDeadLetters(context.Context, time.Duration) (int64, error) { return 0, nil }
+
+func (s *memOutbox) only(t *testing.T) *store.OutboxMessage {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.rows) != 1 {
+		t.Fatalf("outbox rows = %d; want 1", len(s.rows))
+	}
+	for _, r := range s.rows {
+		return r
+	}
+	return nil
+}
+
+// flakyChannel fails the first sends with the queued errors.
+type flakyChannel struct {
+	*BaseChannel
+	errs    []error
+	failure *SendFailure // classification override
+	sent    []bus.OutboundMessage
+	keys    []string
+}
+
+func (c *flakyChannel) Start(context.Context) error { return nil }
+func (c *flakyChannel) Stop(context.Context) error  { return nil }
+
+func (c *flakyChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	c.keys = append(c.keys, NextIdempotencyKey(ctx))
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return err
+	}
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func (c *flakyChannel) ClassifySendError(err error) SendFailure {
+	if c.failure != nil {
+		return *c.failure
+	}
+	return ClassifySendError(err)
+}
+
+func newOutboxManager(t *testing.T, ch *flakyChannel, cfg *config.OutboxConfig) (*Manager, *memOutbox) {
+	t.Helper()
+	m := NewManager(bus.New())
+	m.RegisterChannel("test", ch)
+	s := newMemOutbox()
+	m.SetOutbox(s, cfg)
+	return m, s
+}
+
+// publish hands msg to the bus as a producer would, then runs the
+// dispatcher for it.
+func publish(ctx context.Context, m *Manager, msg bus.OutboundMessage) {
+	m.bus.PublishOutbound(msg)
+	if got, ok := m.bus.SubscribeOutbound(ctx); ok {
+		m.dispatchOne(ctx, got)
+	}
+}
+
+func TestOutbox_RecordsAtPublish(t *testing.T) {
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("test", nil, nil)}
+	m, s := newOutboxManager(t, ch, nil)
+	ctx := context.Background()
+
+	// The row exists as soon as the producer's publish returns.
+	m.bus.PublishOutbound(bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "hello"})
+	row := s.only(t)
+	if time.Until(row.NextAttemptAt) < outboxLease-time.Minute {
+		t.Errorf("row due at %v; want it leased while queued", row.NextAttemptAt)
+	}
+	msg, _ := m.bus.SubscribeOutbound(ctx)
+	if msg.OutboxID != row.ID {
+		t.Fatalf("queued OutboxID = %v; want %v", msg.OutboxID, row.ID)
+	}
+	m.dispatchOne(ctx, msg)
+	if len(ch.sent) != 1 || len(s.rows) != 0 {
+		t.Fatalf("sent = %d, rows left = %d; want 1 and 0", len(ch.sent), len(s.rows))
+	}
+
+	// Internal channels are never recorded.
+	m.bus.PublishOutbound(bus.OutboundMessage{Channel: "system", ChatID: "x", Content: "internal"})
+	if len(s.rows) != 0 {
+		t.Errorf("internal message recorded: %d rows", len(s.rows))
+	}
+	m.bus.SubscribeOutbound(ctx)
+
+	// A message the bus refuses is not left behind for the retry loop.
+	for m.bus.TryPublishOutbound(bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "fill"}) {
+	}
+	queued := len(s.rows)
+	if m.bus.TryPublishOutbound(bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "dropped"}) || len(s.rows) != queued {
+		t.Errorf("rows = %d after refused publish; want %d", len(s.rows), queued)
+	}
+}
+
+// slowOutbox blocks Enqueue until release is closed, ignoring the context.
+type slowOutbox struct {
+	*memOutbox
+	release chan struct{}
+}
+
+func (s *slowOutbox) Enqueue(ctx context.Context, msg *store.OutboxMessage) error {
+	<-s.release
+	return s.memOutbox.Enqueue(ctx, msg)
+}
+
+func TestOutbox_SlowEnqueueSendsDirectly(t *testing.T) {
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("test", nil, nil)}
+	m := NewManager(bus.New())
+	m.RegisterChannel("test", ch)
+	s := &slowOutbox{memOutbox: newMemOutbox(), release: make(chan struct{})}
+	m.SetOutbox(s, nil)
+	m.outbox.enqueueTimeout = 20 * time.Millisecond
+	ctx := context.Background()
+
+	start := time.Now()
+	m.bus.PublishOutbound(bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "hello"})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("publish blocked for %v on a slow store", elapsed)
+	}
+	msg, _ := m.bus.SubscribeOutbound(ctx)
+	if msg.OutboxID != uuid.Nil {
+		t.Fatalf("OutboxID = %v; want the message sent without a row", msg.OutboxID)
+	}
+	m.dispatchOne(ctx, msg)
+	if len(ch.sent) != 1 {
+		t.Fatalf("sent = %d; want 1", len(ch.sent))
+	}
+
+	// The late write is removed so the retry loop does not send it again.
+	close(s.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		n := len(s.rows)
+		s.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("abandoned row left in the outbox")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutbox_ReplaySkipsMissingMedia(t *testing.T) {
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("test", nil, nil)}
+	m, s := newOutboxManager(t, ch, nil)
+	ctx := context.Background()
+
+	// Rows left by a previous process whose temp files are gone.
+	kept := filepath.Join(t.TempDir(), "kept.png")
+	if err := os.WriteFile(kept, []byte("png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(os.TempDir(), "goclaw-outbox-gone-"+uuid.NewString()+".png")
+	for _, msg := range []bus.OutboundMessage{
+		{Channel: "test", ChatID: "c1", Content: "report", Media: []bus.MediaAttachment{{URL: gone}, {URL: kept}, {URL: "https://x.test/a.png"}}},
+		{Channel: "test", ChatID: "c2", Media: []bus.MediaAttachment{{URL: gone}}},
+	} {
+		payload, _ := json.Marshal(msg)
+		s.Enqueue(ctx, &store.OutboxMessage{ID: uuid.New(), Channel: msg.Channel, ChatID: msg.ChatID, Payload: payload, NextAttemptAt: time.Now().Add(-time.Second)})
+	}
+	m.retryDueOutbox(ctx)
+
+	if len(ch.sent) != 1 || ch.sent[0].Content != "report" {
+		t.Fatalf("sent = %+v; want only the text message", ch.sent)
+	}
+	if media := ch.sent[0].Media; len(media) != 2 || media[0].URL != kept || media[1].URL != "https://x.test/a.png" {
+		t.Errorf("media = %+v; want the missing file dropped", media)
+	}
+	if len(s.rows) != 0 || len(s.dead) != 0 {
+		t.Errorf("rows = %d, dead = %d; want both messages finished", len(s.rows), len(s.dead))
+	}
+}
+
+func TestOutbox_RetriesUntilDelivered(t *testing.T) {
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("test", nil, nil), errs: []error{errors.New("connection reset")}}
+	m, s := newOutboxManager(t, ch, nil)
+	ctx := context.Background()
+
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "hello"})
+	row := s.only(t)
+	if row.Attempts != 1 || row.LastError != "connection reset" {
+		t.Fatalf("row after failure = %+v", row)
+	}
+	if delay := time.Until(row.NextAttemptAt); delay < time.Second || delay > 3*time.Second {
+		t.Errorf("first retry in %v; want ~2s", delay)
+	}
+
+	// A new message to the same chat is queued behind its backoff, not sent.
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "queued"})
+	if len(ch.keys) != 1 {
+		t.Fatalf("send attempted while chat backing off: %d attempts", len(ch.keys))
+	}
+
+	// Other chats on the channel are not held back.
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c2", Content: "other chat"})
+	if len(ch.sent) != 1 || ch.sent[0].ChatID != "c2" {
+		t.Fatalf("sent = %+v; want the c2 message delivered at once", ch.sent)
+	}
+
+	// Backoff over: both c1 messages are delivered by the retry loop.
+	m.outbox.recordSuccess("test", "c1")
+	s.mu.Lock()
+	for _, r := range s.rows {
+		r.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+	s.mu.Unlock()
+	m.retryDueOutbox(ctx)
+
+	if len(ch.sent) != 3 || len(s.rows) != 0 {
+		t.Fatalf("sent = %d, rows left = %d; want 3 and 0", len(ch.sent), len(s.rows))
+	}
+	// The retry of the first message reused its idempotency key.
+	if ch.keys[0] == "" || !slices.Contains(ch.keys[2:], ch.keys[0]) {
+		t.Errorf("idempotency keys = %q; retry must reuse %q", ch.keys, ch.keys[0])
+	}
+}
+
+func TestOutbox_DeadLetters(t *testing.T) {
+	ch := &flakyChannel{BaseChannel: NewBaseChannel("test", nil, nil), errs: []error{
+		errors.New(`telego: sendMessage: api: 403 "Forbidden: bot was blocked by the user"`),
+		errors.New("502 bad gateway"),
+		errors.New("502 bad gateway"),
+	}}
+	m, s := newOutboxManager(t, ch, &config.OutboxConfig{MaxAttempts: 2})
+	ctx := context.Background()
+
+	// Permanent failure: dead-lettered on the first attempt.
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "hi"})
+	if len(s.rows) != 0 || len(s.dead) != 1 {
+		t.Fatalf("rows = %d, dead = %d; want 0 and 1", len(s.rows), len(s.dead))
+	}
+
+	// Transient failures: dead-lettered once attempts run out.
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c2", Content: "hi"})
+	row := s.only(t)
+	m.outbox.recordSuccess("test", "c2")
+	row.NextAttemptAt = time.Now().Add(-time.Second)
+	m.retryDueOutbox(ctx)
+	if d := s.dead[row.ID]; d == nil || d.Attempts != 2 || d.LastError != "502 bad gateway" {
+		t.Fatalf("dead letter = %+v", d)
+	}
+}
+
+func TestOutbox_HonorsRetryAfter(t *testing.T) {
+	ch := &flakyChannel{
+		BaseChannel: NewBaseChannel("test", nil, nil),
+		errs:        []error{errors.New("429 too many requests")},
+		failure:     &SendFailure{RetryAfter: 30 * time.Second, ChannelWide: true},
+	}
+	m, s := newOutboxManager(t, ch, nil)
+	ctx := context.Background()
+
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c1", Content: "hi"})
+	if delay := time.Until(s.only(t).NextAttemptAt); delay < 29*time.Second || delay > 30*time.Second {
+		t.Errorf("retry in %v; want the 30s retry-after hint", delay)
+	}
+
+	// A rate limit pauses every chat on the channel.
+	publish(ctx, m, bus.OutboundMessage{Channel: "test", ChatID: "c2", Content: "hi"})
+	if len(ch.keys) != 1 || len(s.rows) != 2 {
+		t.Fatalf("attempts = %d, rows = %d; want the c2 message held back", len(ch.keys), len(s.rows))
+	}
+}
+
+func TestClassifySendError(t *testing.T) {
+	tests := []struct {
+		err       string
+		permanent bool
+	}{
+		{`telego: sendMessage: api: 400 "Bad Request: chat not found"`, true},
+		{"Forbidden: bot was kicked from the group chat", true},
+		{"dial tcp: i/o timeout", false},
+		{"slack rate limit exceeded, retry after 5s", false},
+	}
+	for _, tt := range tests {
+		if got := ClassifySendError(errors.New(tt.err)); got.Permanent != tt.permanent || got.ChannelWide {
+			t.Errorf("ClassifySendError(%q) = %+v; want Permanent=%v", tt.err, got, tt.permanent)
+		}
+	}
+
+	netErr := fmt.Errorf("send: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	if got := ClassifySendError(netErr); !got.ChannelWide || got.Permanent {
+		t.Errorf("ClassifySendError(network) = %+v; want channel-wide retry", got)
+	}
+}
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   SendFailure
+		ok     bool
+	}{
+		{http.StatusTooManyRequests, SendFailure{RetryAfter: 5 * time.Second, ChannelWide: true}, true},
+		{http.StatusUnauthorized, SendFailure{ChannelWide: true}, true},
+		{http.StatusForbidden, SendFailure{Permanent: true}, true},
+		{http.StatusNotFound, SendFailure{Permanent: true}, true},
+		{http.StatusBadGateway, SendFailure{RetryAfter: 5 * time.Second}, true},
+		{http.StatusConflict, SendFailure{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ClassifyHTTPStatus(tt.status, 5*time.Second)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ClassifyHTTPStatus(%d) = %+v, %v; want %+v, %v", tt.status, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	tests := map[string]time.Duration{"": 0, "7": 7 * time.Second, "-1": 0, "soon": 0}
+	for v, want := range tests {
+		h := http.Header{}
+		if v != "" {
+			h.Set("Retry-After", v)
+		}
+		if got := RetryAfterHeader(h); got != want {
+			t.Errorf("RetryAfterHeader(%q) = %v; want %v", v, got, want)
+		}
+	}
+	h := http.Header{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}
+	if got := RetryAfterHeader(h); got < 50*time.Second || got > time.Minute {
+		t.Errorf("RetryAfterHeader(date) = %v; want ~1m", got)
+	}
+}
@@ -0,0 +1,665 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	defaultRouteStickyTTL  = 24 * time.Hour
+	routeStickyMaxEntries  = 10_000
+	routeStickyRefreshDiv  = 4 // a stored route is refreshed after a quarter of the TTL
+	routeTriageTimeout     = 10 * time.Second
+	routeCELCostLimit      = 100_000 // same cap as hook if_expr
+	routeTriageMaxDescLen  = 200
+	routeTriageMaxInputLen = 2000
+)
+
+// Route decision reasons, recorded on the run's trace.
+const (
+	RouteReasonRule    = "rule"
+	RouteReasonSticky  = "sticky"
+	RouteReasonTriage  = "triage"
+	RouteReasonDefault = "default"
+)
+
+// RoutingConfig is the "routing" block of a channel instance config. It lets
+// one bot front several agents: rules are CEL expressions evaluated in order
+// over the inbound message, the first match picks the agent.
+//
+//	"routing": {
+//	  "rules": [
+//	    {"name": "billing", "when": "keywords.exists(k, k in ['invoice', 'refund'])", "agent": "billing"},
+//	    {"name": "vip", "when": "'vip' in tags", "agent": "concierge", "force": true}
+//	  ],
+//	  "default_agent": "support",
+//	  "triage": {"enabled": true, "agents": ["billing", "support", "sales"]}
+//	}
+type RoutingConfig struct {
+	Rules            []RoutingRule        `json:"rules,omitempty"`
+	DefaultAgent     string               `json:"default_agent,omitempty"`      // agent key or UUID; empty = instance agent
+	Triage           *RoutingTriageConfig `json:"triage,omitempty"`             // LLM fallback when no rule matches
+	StickyTTLMinutes int                  `json:"sticky_ttl_minutes,omitempty"` // idle time before a conversation is re-routed (default 1440)
+}
+
+// RoutingRule maps a CEL condition to a target agent.
+type RoutingRule struct {
+	Name  string `json:"name,omitempty"`
+	When  string `json:"when"`            // CEL expression returning bool
+	Agent string `json:"agent"`           // agent key or UUID
+	Force bool   `json:"force,omitempty"` // switch agents even when the conversation is stuck to another one
+}
+
+// RoutingTriageConfig enables an LLM step that picks among candidate agents
+// when no rule matches a new conversation.
+type RoutingTriageConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Agents   []string `json:"agents,omitempty"`   // candidates (default: rule targets + default agent)
+	Provider string   `json:"provider,omitempty"` // default: default agent's provider
+	Model    string   `json:"model,omitempty"`
+}
+
+// ParseRoutingConfig extracts the routing block from a channel instance
+// config. Returns nil when the instance has no routing rules or triage.
+func ParseRoutingConfig(instanceCfg json.RawMessage) (*RoutingConfig, error) {
+	if len(instanceCfg) == 0 {
+		return nil, nil
+	}
+	var wrapper struct {
+		Routing *RoutingConfig `json:"routing"`
+	}
+	if err := json.Unmarshal(instanceCfg, &wrapper); err != nil {
+		return nil, fmt.Errorf("parse routing config: %w", err)
+	}
+	rc := wrapper.Routing
+	if rc == nil || (len(rc.Rules) == 0 && (rc.Triage == nil || !rc.Triage.Enabled)) {
+		return nil, nil
+	}
+	return rc, nil
+}
+
+// ValidateRoutingConfig checks the routing block of a channel instance config:
+// every rule needs an agent and a CEL expression that compiles to bool.
+// Agent references are resolved when the instance is loaded.
+func ValidateRoutingConfig(instanceCfg json.RawMessage) error {
+	rc, err := ParseRoutingConfig(instanceCfg)
+	if err != nil || rc == nil {
+		return err
+	}
+	for i, r := range rc.Rules {
+		if r.Agent == "" {
+			return fmt.Errorf("routing rule %d: agent is required", i+1)
+		}
+		if _, err := compileRouteExpr(r.When); err != nil {
+			return fmt.Errorf("routing rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// RouteDecision records which agent handles an inbound message and why.
+type RouteDecision struct {
+	Agent    string `json:"agent"`
+	Reason   string `json:"reason"` // rule, sticky, triage, default
+	Rule     string `json:"rule,omitempty"`
+	Forced   bool   `json:"forced,omitempty"`
+	Previous string `json:"previous,omitempty"` // sticky agent a forced rule switched away from
+}
+
+// RouteAgentResolver resolves an agent reference (key or UUID) to the agent.
+type RouteAgentResolver func(ref string) (*store.AgentData, error)
+
+type compiledRouteRule struct {
+	name  string
+	agent string // agent key
+	force bool
+	prg   cel.Program
+}
+
+type routeTriage struct {
+	provider   providers.Provider
+	model      string
+	candidates []routeCandidate
+}
+
+type routeCandidate struct {
+	key         string
+	description string
+}
+
+// AgentRouter picks the agent for inbound messages on one channel instance.
+// The chosen agent is sticky per conversation (chat, topic or thread) until
+// it idles out, a forced rule matches, or the session is reset. With a
+// contact store the sticky agent is also kept on the conversation's
+// channel_contacts row, so it survives gateway restarts.
+type AgentRouter struct {
+	channelType  string
+	defaultAgent string
+	rules        []compiledRouteRule
+	triage       *routeTriage
+	contacts     store.ContactStore
+	agents       map[string]bool // every agent the router can pick
+	stickyTTL    time.Duration
+	sticky       *expirable.LRU[string, routeSticky] // conversation key → sticky agent
+}
+
+// routeSticky is a conversation's sticky agent and when it was last stored.
+type routeSticky struct {
+	agent string
+	saved time.Time
+}
+
+// NewAgentRouter compiles rc for a channel instance whose own agent is
+// instanceAgent (agent key). Unknown agents and invalid expressions are errors.
+func NewAgentRouter(rc *RoutingConfig, channelType, instanceAgent string, resolve RouteAgentResolver) (*AgentRouter, error) {
+	r := &AgentRouter{channelType: channelType, defaultAgent: instanceAgent, agents: make(map[string]bool)}
+	if rc.DefaultAgent != "" {
+		ag, err := resolve(rc.DefaultAgent)
+		if err != nil {
+			return nil, fmt.Errorf("routing default agent %q: %w", rc.DefaultAgent, err)
+		}
+		r.defaultAgent = ag.AgentKey
+	}
+	for i, rule := range rc.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", i+1)
+		}
+		ag, err := resolve(rule.Agent)
+		if err != nil {
+			return nil, fmt.Errorf("routing rule %s: agent %q: %w", name, rule.Agent, err)
+		}
+		prg, err := compileRouteExpr(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("routing rule %s: %w", name, err)
+		}
+		r.rules = append(r.rules, compiledRouteRule{name: name, agent: ag.AgentKey, force: rule.Force, prg: prg})
+	}
+	r.stickyTTL = defaultRouteStickyTTL
+	if rc.StickyTTLMinutes > 0 {
+		r.stickyTTL = time.Duration(rc.StickyTTLMinutes) * time.Minute
+	}
+	r.sticky = expirable.NewLRU[string, routeSticky](routeStickyMaxEntries, nil, r.stickyTTL)
+	r.agents[r.defaultAgent] = true
+	for _, rule := range r.rules {
+		r.agents[rule.agent] = true
+	}
+
+	if rc.Triage != nil && rc.Triage.Enabled {
+		refs := rc.Triage.Agents
+		if len(refs) == 0 {
+			refs = append(refs, r.defaultAgent)
+			for _, rule := range r.rules {
+				refs = append(refs, rule.agent)
+			}
+		}
+		seen := make(map[string]bool)
+		var candidates []routeCandidate
+		for _, ref := range refs {
+			ag, err := resolve(ref)
+			if err != nil {
+				return nil, fmt.Errorf("routing triage agent %q: %w", ref, err)
+			}
+			if seen[ag.AgentKey] {
+				continue
+			}
+			seen[ag.AgentKey] = true
+			r.agents[ag.AgentKey] = true
+			candidates = append(candidates, routeCandidate{key: ag.AgentKey, description: agentRouteDescription(ag)})
+		}
+		if len(candidates) > 1 {
+			r.triage = &routeTriage{candidates: candidates}
+		}
+	}
+	return r, nil
+}
+
+// SetTriageProvider sets the LLM used for triage. Without one, triage is skipped.
+func (r *AgentRouter) SetTriageProvider(p providers.Provider, model string) {
+	if r.triage == nil || p == nil {
+		return
+	}
+	if model == "" {
+		model = p.DefaultModel()
+	}
+	r.triage.provider = p
+	r.triage.model = model
+}
+
+// HasTriage reports whether the router has triage candidates configured.
+func (r *AgentRouter) HasTriage() bool { return r.triage != nil }
+
+// SetContactStore enables the `tags` variable (contact tags of the sender)
+// and persistent sticky routing.
+func (r *AgentRouter) SetContactStore(cs store.ContactStore) { r.contacts = cs }
+
+// Route picks the agent for msg. ctx must carry the instance's tenant.
+// A conversation's sticky agent is looked up first: only a forced rule can
+// move it, so the other rules are not evaluated for stuck conversations.
+func (r *AgentRouter) Route(ctx context.Context, msg bus.InboundMessage) RouteDecision {
+	key := routeConversationKey(msg)
+	cur, stuck := r.lookupSticky(ctx, msg, key)
+	matched := r.matchRule(ctx, msg, stuck)
+
+	var d RouteDecision
+	switch {
+	case matched != nil && matched.force:
+		d = RouteDecision{Agent: matched.agent, Reason: RouteReasonRule, Rule: matched.name, Forced: true}
+		if stuck && cur.agent != matched.agent {
+			d.Previous = cur.agent
+		}
+	case stuck:
+		d = RouteDecision{Agent: cur.agent, Reason: RouteReasonSticky}
+	case matched != nil:
+		d = RouteDecision{Agent: matched.agent, Reason: RouteReasonRule, Rule: matched.name}
+	default:
+		d = RouteDecision{Agent: r.defaultAgent, Reason: RouteReasonDefault}
+		if agent := r.runTriage(ctx, msg); agent != "" {
+			d = RouteDecision{Agent: agent, Reason: RouteReasonTriage}
+		}
+	}
+	r.remember(ctx, msg, key, cur, d.Agent)
+	return d
+}
+
+// matchRule returns the first rule matching msg, or nil. With forcedOnly,
+// non-forced rules are skipped.
+func (r *AgentRouter) matchRule(ctx context.Context, msg bus.InboundMessage, forcedOnly bool) *compiledRouteRule {
+	var vars map[string]any
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if forcedOnly && !rule.force {
+			continue
+		}
+		if vars == nil {
+			vars = r.routeVars(ctx, msg)
+		}
+		ok, err := evalRouteExpr(rule.prg, vars)
+		if err != nil {
+			slog.Warn("routing: rule evaluation failed", "channel", msg.Channel, "rule", rule.name, "error", err)
+			continue
+		}
+		if ok {
+			return rule
+		}
+	}
+	return nil
+}
+
+// lookupSticky returns the conversation's sticky agent, loading it from the
+// contact store when the in-memory cache has none (e.g. after a restart).
+// Stored routes past the sticky TTL or to agents no longer configured are
+// ignored.
+func (r *AgentRouter) lookupSticky(ctx context.Context, msg bus.InboundMessage, key string) (routeSticky, bool) {
+	if cur, ok := r.sticky.Get(key); ok {
+		return cur, true
+	}
+	ck, ok := r.routeContactKey(msg)
+	if !ok {
+		return routeSticky{}, false
+	}
+	route, err := r.contacts.GetContactRoute(ctx, ck)
+	if err != nil {
+		slog.Warn("routing: stored route lookup failed", "channel", msg.Channel, "chat_id", msg.ChatID, "error", err)
+		return routeSticky{}, false
+	}
+	if route == nil || !r.agents[route.Agent] || time.Since(route.RoutedAt) >= r.stickyTTL {
+		return routeSticky{}, false
+	}
+	cur := routeSticky{agent: route.Agent, saved: route.RoutedAt}
+	r.sticky.Add(key, cur)
+	return cur, true
+}
+
+// remember makes agent the conversation's sticky agent. The contact store is
+// written when the agent changes, and otherwise only often enough to keep
+// the stored route from idling out while the conversation is active.
+func (r *AgentRouter) remember(ctx context.Context, msg bus.InboundMessage, key string, cur routeSticky, agent string) {
+	now := time.Now().UTC()
+	next := routeSticky{agent: agent, saved: cur.saved}
+	if agent != cur.agent || now.Sub(cur.saved) >= r.stickyTTL/routeStickyRefreshDiv {
+		if ck, ok := r.routeContactKey(msg); ok {
+			if err := r.contacts.SetContactRoute(ctx, ck, &store.ContactRoute{Agent: agent, RoutedAt: now}); err != nil {
+				slog.Warn("routing: store route failed", "channel", msg.Channel, "chat_id", msg.ChatID, "error", err)
+			} else {
+				next.saved = now
+			}
+		}
+	}
+	r.sticky.Add(key, next)
+}
+
+// Sticky returns the agent the conversation of msg is currently routed to.
+func (r *AgentRouter) Sticky(ctx context.Context, msg bus.InboundMessage) (string, bool) {
+	cur, ok := r.lookupSticky(ctx, msg, routeConversationKey(msg))
+	return cur.agent, ok
+}
+
+// Forget drops the sticky agent for the conversation of msg.
+func (r *AgentRouter) Forget(ctx context.Context, msg bus.InboundMessage) {
+	r.sticky.Remove(routeConversationKey(msg))
+	if ck, ok := r.routeContactKey(msg); ok {
+		if err := r.contacts.SetContactRoute(ctx, ck, nil); err != nil {
+			slog.Warn("routing: clear stored route failed", "channel", msg.Channel, "chat_id", msg.ChatID, "error", err)
+		}
+	}
+}
+
+// routeContactKey locates the contact row a conversation's route is stored
+// on: the group (or its topic/thread) for group chats, the sender for direct
+// chats. ok is false without a contact store or an identifiable contact.
+func (r *AgentRouter) routeContactKey(msg bus.InboundMessage) (store.ContactRouteKey, bool) {
+	if r.contacts == nil {
+		return store.ContactRouteKey{}, false
+	}
+	k := store.ContactRouteKey{ChannelType: r.channelType, ChannelInstance: msg.Channel, ContactType: "user", PeerKind: "direct"}
+	// local_key is "<chat>:<kind>:<id>" for forum topics and threads.
+	if rest, ok := strings.CutPrefix(msg.Metadata["local_key"], msg.ChatID+":"); ok {
+		if _, thread, ok := strings.Cut(rest, ":"); ok {
+			k.ThreadID = thread
+		}
+	}
+	if msg.PeerKind == "group" {
+		k.SenderID, k.PeerKind, k.ContactType = msg.ChatID, "group", "group"
+	} else {
+		k.SenderID = routeSenderID(msg.SenderID)
+	}
+	if k.ThreadID != "" {
+		k.ContactType = "topic"
+	}
+	if k.SenderID == "" || bus.IsInternalSender(k.SenderID) {
+		return store.ContactRouteKey{}, false
+	}
+	return k, true
+}
+
+// routeSenderID strips the "|username" suffix some channels append to sender IDs.
+func routeSenderID(sender string) string {
+	if idx := strings.IndexByte(sender, '|'); idx > 0 {
+		return sender[:idx]
+	}
+	return sender
+}
+
+// routeConversationKey identifies the conversation (chat, forum topic or
+// thread) a message belongs to; routing sticks per conversation.
+func routeConversationKey(msg bus.InboundMessage) string {
+	if lk := msg.Metadata["local_key"]; lk != "" {
+		return lk
+	}
+	return msg.ChatID
+}
+
+// routeVars builds the CEL activation for msg.
+func (r *AgentRouter) routeVars(ctx context.Context, msg bus.InboundMessage) map[string]any {
+	sender := routeSenderID(msg.SenderID)
+	attachments := make([]string, 0, len(msg.Media))
+	for _, m := range msg.Media {
+		mt := m.MimeType
+		if mt == "" {
+			name := m.Filename
+			if name == "" {
+				name = m.Path
+			}
+			mt = mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+		}
+		if i := strings.IndexByte(mt, ';'); i > 0 {
+			mt = mt[:i]
+		}
+		if mt == "" {
+			mt = "application/octet-stream"
+		}
+		attachments = append(attachments, mt)
+	}
+	tags := []string{}
+	if r.contacts != nil && sender != "" && !bus.IsInternalSender(sender) {
+		if t, err := r.contacts.GetContactTags(ctx, r.channelType, sender); err != nil {
+			slog.Warn("routing: contact tags lookup failed", "channel", msg.Channel, "sender", sender, "error", err)
+		} else if t != nil {
+			tags = t
+		}
+	}
+	language := DetectLanguage(msg.Content)
+	if language == "" {
+		language, _, _ = strings.Cut(strings.ToLower(msg.Metadata["locale"]), "-")
+	}
+	return map[string]any{
+		"text":        msg.Content,
+		"channel":     msg.Channel,
+		"sender":      sender,
+		"sender_name": firstNonEmpty(msg.Metadata["display_name"], msg.Metadata["username"]),
+		"chat":        msg.ChatID,
+		"group":       msg.PeerKind == "group",
+		"keywords":    routeKeywords(msg.Content),
+		"language":    language,
+		"attachments": attachments,
+		"tags":        tags,
+	}
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// routeKeywords returns the distinct lowercased words of text.
+func routeKeywords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' && r != '-'
+	})
+	seen := make(map[string]bool, len(words))
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.Trim(w, "-_")
+		if w != "" && !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+const routeTriagePrompt = `You route customer messages to the most suitable assistant.
+
+Assistants:
+%s
+Respond with ONLY the key of the assistant that should handle the message.`
+
+// runTriage asks the triage LLM to pick a candidate. Returns "" when triage
+// is disabled, fails, or answers with an unknown key.
+func (r *AgentRouter) runTriage(ctx context.Context, msg bus.InboundMessage) string {
+	t := r.triage
+	if t == nil || t.provider == nil || strings.TrimSpace(msg.Content) == "" {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, routeTriageTimeout)
+	defer cancel()
+
+	var list strings.Builder
+	keys := make([]any, 0, len(t.candidates))
+	for _, c := range t.candidates {
+		fmt.Fprintf(&list, "- %s: %s\n", c.key, c.description)
+		keys = append(keys, c.key)
+	}
+	content := msg.Content
+	if len(content) > routeTriageMaxInputLen {
+		content = strings.ToValidUTF8(content[:routeTriageMaxInputLen], "")
+	}
+	resp, err := providers.ChatStructured(ctx, t.provider, providers.ChatRequest{
+		Messages: []providers.Message{
+			{Role: "system", Content: fmt.Sprintf(routeTriagePrompt, list.String())},
+			{Role: "user", Content: content},
+		},
+		Model: t.model,
+		Options: map[string]any{
+			providers.OptMaxTokens:   64,
+			providers.OptTemperature: 0.0,
+		},
+		ResponseFormat: providers.JSONSchemaFormat("agent", map[string]any{"type": "string", "enum": keys}),
+	})
+	if err != nil && (resp == nil || !errors.Is(err, providers.ErrStructuredOutput)) {
+		slog.Warn("routing: triage failed", "channel", msg.Channel, "error", err)
+		return ""
+	}
+	answer := strings.ToLower(strings.Trim(strings.TrimSpace(resp.Content), "\"'`."))
+	for _, c := range t.candidates {
+		if answer == strings.ToLower(c.key) {
+			return c.key
+		}
+	}
+	// Decorated answers ("Agent: billing"): accept an unambiguous mention.
+	var found string
+	for _, c := range t.candidates {
+		if strings.Contains(answer, strings.ToLower(c.key)) {
+			if found != "" {
+				return ""
+			}
+			found = c.key
+		}
+	}
+	return found
+}
+
+func agentRouteDescription(ag *store.AgentData) string {
+	desc := firstNonEmpty(ag.Frontmatter, ag.AgentDescription, ag.DisplayName, ag.AgentKey)
+	desc = strings.Join(strings.Fields(desc), " ")
+	if len(desc) > routeTriageMaxDescLen {
+		desc = strings.ToValidUTF8(desc[:routeTriageMaxDescLen], "") + "…"
+	}
+	return desc
+}
+
+// ───── CEL ─────
+
+var (
+	routeCELEnv     *cel.Env
+	routeCELEnvErr  error
+	routeCELEnvOnce sync.Once
+)
+
+// routeEnv builds the CEL environment for routing rules. Only message
+// attributes are exposed; no file or network functions are registered.
+func routeEnv() (*cel.Env, error) {
+	routeCELEnvOnce.Do(func() {
+		routeCELEnv, routeCELEnvErr = cel.NewEnv(
+			cel.Variable("text", cel.StringType),
+			cel.Variable("channel", cel.StringType),
+			cel.Variable("sender", cel.StringType),
+			cel.Variable("sender_name", cel.StringType),
+			cel.Variable("chat", cel.StringType),
+			cel.Variable("group", cel.BoolType),
+			cel.Variable("keywords", cel.ListType(cel.StringType)),
+			cel.Variable("language", cel.StringType),
+			cel.Variable("attachments", cel.ListType(cel.StringType)),
+			cel.Variable("tags", cel.ListType(cel.StringType)),
+		)
+	})
+	return routeCELEnv, routeCELEnvErr
+}
+
+func compileRouteExpr(expr string) (cel.Program, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("when expression is required")
+	}
+	env, err := routeEnv()
+	if err != nil {
+		return nil, fmt.Errorf("cel env: %w", err)
+	}
+	ast, iss := env.Compile(expr)
+	if iss != nil && iss.Err() != nil {
+		return nil, fmt.Errorf("cel parse %q: %w", expr, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("cel expression %q must return bool, got %s", expr, ast.OutputType())
+	}
+	prg, err := env.Program(ast, cel.CostLimit(routeCELCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("cel program: %w", err)
+	}
+	return prg, nil
+}
+
+func evalRouteExpr(prg cel.Program, vars map[string]any) (bool, error) {
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("cel eval: %w", err)
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("cel expression must return bool, got %T", out.Value())
+	}
+	return b, nil
+}
+
+// ───── Manager wiring ─────
+
+// SetAgentRouter attaches rule-based agent routing to a channel (nil removes it).
+func (m *Manager) SetAgentRouter(channel string, r *AgentRouter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r == nil {
+		delete(m.routers, channel)
+		return
+	}
+	if m.routers == nil {
+		m.routers = make(map[string]*AgentRouter)
+	}
+	m.routers[channel] = r
+}
+
+func (m *Manager) agentRouter(channel string) *AgentRouter {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.routers[channel]
+}
+
+// RouteInbound applies the channel's routing rules to msg. Returns nil when
+// the channel has no routing configured (msg.AgentID stands).
+func (m *Manager) RouteInbound(ctx context.Context, msg bus.InboundMessage) *RouteDecision {
+	r := m.agentRouter(msg.Channel)
+	if r == nil {
+		return nil
+	}
+	d := r.Route(ctx, msg)
+	return &d
+}
+
+// RoutedAgent returns the agent the conversation of msg is currently routed
+// to, or "" when the channel has no routing or the conversation none yet.
+// ctx must carry the instance's tenant.
+func (m *Manager) RoutedAgent(ctx context.Context, msg bus.InboundMessage) string {
+	if r := m.agentRouter(msg.Channel); r != nil {
+		agent, _ := r.Sticky(ctx, msg)
+		return agent
+	}
+	return ""
+}
+
+// ForgetRoute clears the sticky agent of msg's conversation so the next
+// message is routed afresh (e.g. after /reset). ctx must carry the
+// instance's tenant.
+func (m *Manager) ForgetRoute(ctx context.Context, msg bus.InboundMessage) {
+	if r := m.agentRouter(msg.Channel); r != nil {
+		r.Forget(ctx, msg)
+	}
+}
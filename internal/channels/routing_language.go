@@ -0,0 +1,113 @@
+package channels
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Vietnamese-only letters (beyond the accents it shares with French/Portuguese).
+const vietnameseLetters = "ăâđêôơưạảấầẩẫậắằẳẵặẹẻẽếềểễệỉịọỏốồổỗộớờởỡợụủứừửữựỳỵỷỹ"
+
+// latinStopwords are frequent function words used to tell Latin-script
+// languages apart. Scores are word hits; ties and zero hits yield "".
+var latinStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "to", "of", "my", "it", "what", "how", "please", "can", "with", "for", "this", "have"},
+	"es": {"el", "la", "los", "las", "que", "de", "y", "es", "por", "para", "con", "mi", "una", "cómo", "qué", "hola", "gracias"},
+	"fr": {"le", "la", "les", "est", "et", "je", "vous", "de", "des", "une", "pour", "avec", "mon", "pas", "bonjour", "merci", "comment"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "sie", "mit", "ein", "eine", "für", "mein", "wie", "hallo", "danke", "bitte"},
+	"pt": {"o", "os", "as", "que", "de", "e", "é", "não", "com", "para", "uma", "meu", "você", "olá", "obrigado", "como"},
+	"id": {"yang", "dan", "di", "ini", "itu", "saya", "tidak", "dengan", "untuk", "apa", "bisa", "ada", "terima", "kasih", "tolong"},
+}
+
+// DetectLanguage returns a best-effort ISO 639-1 code for text ("" when
+// unsure). Non-Latin scripts are identified by their Unicode block; Latin
+// text by Vietnamese-specific letters, then by common stopwords.
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["latin"]++
+			if strings.ContainsRune(vietnameseLetters, unicode.ToLower(r)) {
+				counts["vi"]++
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	// Japanese mixes kana with Han; any kana means Japanese.
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestN := "", 0
+	for lang, n := range counts {
+		if lang == "latin" || lang == "vi" {
+			continue
+		}
+		if n > bestN {
+			best, bestN = lang, n
+		}
+	}
+	if bestN*2 >= letters {
+		return best
+	}
+	if counts["latin"]*2 < letters {
+		return ""
+	}
+	if counts["vi"] > 0 {
+		return "vi"
+	}
+	return detectLatinLanguage(text)
+}
+
+func detectLatinLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	best, bestN, tie := "", 0, false
+	for lang, stop := range latinStopwords {
+		n := 0
+		for _, w := range words {
+			for _, s := range stop {
+				if w == s {
+					n++
+					break
+				}
+			}
+		}
+		switch {
+		case n > bestN:
+			best, bestN, tie = lang, n, false
+		case n == bestN && n > 0:
+			tie = true
+		}
+	}
+	if bestN == 0 || tie {
+		return ""
+	}
+	return best
+}
@@ -14,6 +14,11 @@ var routingMetaKeys = []string{
 	"page_id",                // facebook page routing
 	"reply_to_comment_id",    // facebook/pancake comment reply target
 	"pancake_mode",           // pancake inbox vs comment routing
+	"ig_mode",                // instagram dm vs comment routing
+	"email_in_reply_to",      // email Message-ID being answered
+	"email_references",       // email References chain
+	"email_subject",          // email subject the reply is threaded under
+	"email_reply_to",         // email Reply-To address overriding the sender
 }
 
 var finalReplyMetaKeys = append([]string{
@@ -0,0 +1,286 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// tagContacts is a store.ContactStore that only answers tag and route lookups.
+type tagContacts struct {
+	store.ContactStore
+	tags   map[string][]string // sender → tags
+	routes map[store.ContactRouteKey]store.ContactRoute
+}
+
+func (c *tagContacts) GetContactTags(_ context.Context, _, senderID string) ([]string, error) {
+	return c.tags[senderID], nil
+}
+
+func (c *tagContacts) GetContactRoute(_ context.Context, key store.ContactRouteKey) (*store.ContactRoute, error) {
+	if route, ok := c.routes[key]; ok {
+		return &route, nil
+	}
+	return nil, nil
+}
+
+func (c *tagContacts) SetContactRoute(_ context.Context, key store.ContactRouteKey, route *store.ContactRoute) error {
+	if c.routes == nil {
+		c.routes = make(map[store.ContactRouteKey]store.ContactRoute)
+	}
+	if route == nil {
+		delete(c.routes, key)
+	} else {
+		c.routes[key] = *route
+	}
+	return nil
+}
+
+func resolveTestAgent(ref string) (*store.AgentData, error) {
+	switch ref {
+	case "support", "billing", "concierge", "designer":
+		return &store.AgentData{AgentKey: ref}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func newTestRouter(t *testing.T, cfg string) *AgentRouter {
+	t.Helper()
+	rc, err := ParseRoutingConfig(json.RawMessage(cfg))
+	if err != nil || rc == nil {
+		t.Fatalf("ParseRoutingConfig = %v, %v", rc, err)
+	}
+	r, err := NewAgentRouter(rc, "telegram", "support", resolveTestAgent)
+	if err != nil {
+		t.Fatalf("NewAgentRouter: %v", err)
+	}
+	r.SetContactStore(&tagContacts{tags: map[string][]string{"7": {"vip"}}})
+	return r
+}
+
+const testRoutingConfig = `{"routing": {"rules": [
+	{"name": "vip", "when": "'vip' in tags", "agent": "concierge", "force": true},
+	{"name": "billing", "when": "keywords.exists(k, k in ['invoice', 'refund'])", "agent": "billing"},
+	{"name": "images", "when": "attachments.exists(a, a.startsWith('image/')) && !group", "agent": "designer"}
+]}}`
+
+func TestAgentRouter_Rules(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    bus.InboundMessage
+		agent  string
+		reason string
+		rule   string
+	}{
+		{
+			name:  "keyword",
+			msg:   bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "a", Content: "Where is my INVOICE?"},
+			agent: "billing", reason: RouteReasonRule, rule: "billing",
+		},
+		{
+			name:  "contact tag",
+			msg:   bus.InboundMessage{Channel: "tg", SenderID: "7|alice", ChatID: "b", Content: "hello"},
+			agent: "concierge", reason: RouteReasonRule, rule: "vip",
+		},
+		{
+			name:  "attachment by extension",
+			msg:   bus.InboundMessage{Channel: "tg", SenderID: "3", ChatID: "c", Media: []bus.MediaFile{{Path: "/tmp/x.png"}}},
+			agent: "designer", reason: RouteReasonRule, rule: "images",
+		},
+		{
+			name:  "attachment in group does not match",
+			msg:   bus.InboundMessage{Channel: "tg", SenderID: "4", ChatID: "d", PeerKind: "group", Media: []bus.MediaFile{{Path: "/tmp/x", MimeType: "image/jpeg"}}},
+			agent: "support", reason: RouteReasonDefault,
+		},
+		{
+			name:  "default",
+			msg:   bus.InboundMessage{Channel: "tg", SenderID: "5", ChatID: "e", Content: "hi there"},
+			agent: "support", reason: RouteReasonDefault,
+		},
+	}
+	r := newTestRouter(t, testRoutingConfig)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := r.Route(context.Background(), tt.msg)
+			if d.Agent != tt.agent || d.Reason != tt.reason || d.Rule != tt.rule {
+				t.Errorf("Route = %+v; want agent=%s reason=%s rule=%s", d, tt.agent, tt.reason, tt.rule)
+			}
+		})
+	}
+}
+
+func TestAgentRouter_StickyAndForce(t *testing.T) {
+	r := newTestRouter(t, testRoutingConfig)
+	ctx := context.Background()
+	msg := bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "chat", Content: "I need a refund"}
+
+	if d := r.Route(ctx, msg); d.Agent != "billing" {
+		t.Fatalf("first message routed to %s; want billing", d.Agent)
+	}
+	// A non-forced rule does not move a stuck conversation.
+	msg.Content = "here is a picture"
+	msg.Media = []bus.MediaFile{{Path: "/tmp/a.jpg"}}
+	if d := r.Route(ctx, msg); d.Agent != "billing" || d.Reason != RouteReasonSticky {
+		t.Fatalf("follow-up = %+v; want sticky billing", d)
+	}
+	// A forced rule switches and records the previous agent.
+	msg.SenderID = "7"
+	d := r.Route(ctx, msg)
+	if d.Agent != "concierge" || !d.Forced || d.Previous != "billing" {
+		t.Fatalf("forced = %+v; want concierge forced from billing", d)
+	}
+	if got := r.Route(ctx, bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "chat", Content: "thanks"}); got.Agent != "concierge" {
+		t.Errorf("after switch routed to %s; want concierge", got.Agent)
+	}
+
+	// Forum topics stick independently via local_key.
+	topic := bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "chat", Content: "hi", Metadata: map[string]string{"local_key": "chat:topic:3"}}
+	if d := r.Route(ctx, topic); d.Agent != "support" {
+		t.Errorf("topic routed to %s; want support", d.Agent)
+	}
+
+	r.Forget(ctx, msg)
+	if _, ok := r.Sticky(ctx, msg); ok {
+		t.Error("Forget did not clear the sticky agent")
+	}
+}
+
+func TestAgentRouter_StickyPersists(t *testing.T) {
+	ctx := context.Background()
+	contacts := &tagContacts{}
+	newRouter := func() *AgentRouter {
+		r := newTestRouter(t, testRoutingConfig)
+		r.SetContactStore(contacts)
+		return r
+	}
+	group := bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "-100", PeerKind: "group", Content: "refund please",
+		Metadata: map[string]string{"local_key": "-100:topic:5"}}
+	if d := newRouter().Route(ctx, group); d.Agent != "billing" {
+		t.Fatalf("first message routed to %s; want billing", d.Agent)
+	}
+	key := store.ContactRouteKey{ChannelType: "telegram", ChannelInstance: "tg", SenderID: "-100", ThreadID: "5", PeerKind: "group", ContactType: "topic"}
+	if got := contacts.routes[key]; got.Agent != "billing" {
+		t.Fatalf("stored route = %+v; want billing on the topic row", contacts.routes)
+	}
+
+	// A fresh router (gateway restart) keeps the conversation on billing.
+	group.Content = "hello again"
+	if d := newRouter().Route(ctx, group); d.Agent != "billing" || d.Reason != RouteReasonSticky {
+		t.Errorf("after restart = %+v; want sticky billing", d)
+	}
+
+	// Stale routes and routes to agents no longer configured are ignored.
+	contacts.routes[key] = store.ContactRoute{Agent: "billing", RoutedAt: time.Now().Add(-48 * time.Hour)}
+	if d := newRouter().Route(ctx, group); d.Reason == RouteReasonSticky {
+		t.Errorf("expired route = %+v; want re-evaluated", d)
+	}
+	contacts.routes[key] = store.ContactRoute{Agent: "ghost", RoutedAt: time.Now()}
+	if d := newRouter().Route(ctx, group); d.Agent != "support" {
+		t.Errorf("unknown agent route = %+v; want default support", d)
+	}
+
+	r := newRouter()
+	r.Forget(ctx, group)
+	if _, ok := contacts.routes[key]; ok {
+		t.Error("Forget did not clear the stored route")
+	}
+}
+
+func TestAgentRouter_DefaultAgentAndTriageWithoutProvider(t *testing.T) {
+	r := newTestRouter(t, `{"routing": {"default_agent": "billing", "triage": {"enabled": true, "agents": ["billing", "support"]}}}`)
+	if !r.HasTriage() {
+		t.Fatal("expected triage candidates")
+	}
+	d := r.Route(context.Background(), bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "x", Content: "hello"})
+	if d.Agent != "billing" || d.Reason != RouteReasonDefault {
+		t.Errorf("Route = %+v; want default billing when triage has no provider", d)
+	}
+}
+
+// triageProvider answers every chat with a fixed agent key.
+type triageProvider struct{ answer string }
+
+func (p *triageProvider) Chat(context.Context, providers.ChatRequest) (*providers.ChatResponse, error) {
+	return &providers.ChatResponse{Content: p.answer}, nil
+}
+
+func (p *triageProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ func(providers.StreamChunk)) (*providers.ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
+
+func (p *triageProvider) DefaultModel() string { return "test" }
+func (p *triageProvider) Name() string         { return "test" }
+
+func TestAgentRouter_Triage(t *testing.T) {
+	r := newTestRouter(t, `{"routing": {"rules": [{"when": "'refund' in keywords", "agent": "billing"}], "triage": {"enabled": true}}}`)
+	r.SetTriageProvider(&triageProvider{answer: `"billing"`}, "")
+
+	d := r.Route(context.Background(), bus.InboundMessage{Channel: "tg", SenderID: "1", ChatID: "x", Content: "I was charged twice"})
+	if d.Agent != "billing" || d.Reason != RouteReasonTriage {
+		t.Errorf("Route = %+v; want triage billing", d)
+	}
+}
+
+func TestParseRoutingConfig_Empty(t *testing.T) {
+	for _, cfg := range []string{``, `{}`, `{"dm_policy": "open"}`, `{"routing": {"default_agent": "billing"}}`} {
+		if rc, err := ParseRoutingConfig(json.RawMessage(cfg)); err != nil || rc != nil {
+			t.Errorf("ParseRoutingConfig(%q) = %v, %v; want nil", cfg, rc, err)
+		}
+	}
+}
+
+func TestValidateRoutingConfig(t *testing.T) {
+	tests := []struct {
+		cfg     string
+		wantErr string
+	}{
+		{testRoutingConfig, ""},
+		{`{"routing": {"rules": [{"when": "group"}]}}`, "agent is required"},
+		{`{"routing": {"rules": [{"when": "", "agent": "a"}]}}`, "when expression is required"},
+		{`{"routing": {"rules": [{"when": "text +", "agent": "a"}]}}`, "cel parse"},
+		{`{"routing": {"rules": [{"when": "text", "agent": "a"}]}}`, "must return bool"},
+		{`{"routing": {"rules": [{"when": "unknown_var == 1", "agent": "a"}]}}`, "cel parse"},
+	}
+	for _, tt := range tests {
+		err := ValidateRoutingConfig(json.RawMessage(tt.cfg))
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateRoutingConfig(%s) = %v", tt.cfg, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateRoutingConfig(%s) = %v; want %q", tt.cfg, err, tt.wantErr)
+		}
+	}
+
+	if _, err := NewAgentRouter(&RoutingConfig{Rules: []RoutingRule{{When: "group", Agent: "ghost"}}}, "telegram", "support", resolveTestAgent); err == nil {
+		t.Error("NewAgentRouter with unknown agent: want error")
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"What is the status of my order?":                        "en",
+		"Hola, ¿cómo está mi pedido?":                            "es",
+		"Bonjour, je voudrais un remboursement pour ma commande": "fr",
+		"Xin chào, tôi cần hỗ trợ":                               "vi",
+		"Привет, как дела?":                                      "ru",
+		"こんにちは、注文について":                                           "ja",
+		"你好，我的订单在哪里":                                             "zh",
+		"안녕하세요":                                                  "ko",
+		"12345 !!!":                                              "",
+	}
+	for text, want := range tests {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q) = %q; want %q", text, got, want)
+		}
+	}
+}
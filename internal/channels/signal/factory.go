@@ -0,0 +1,64 @@
+package signal
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// signalInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+// signal-cli holds the account keys, so the channel has no credentials.
+type signalInstanceConfig struct {
+	Account        string   `json:"account"`           // bot phone number, E.164 (e.g. +15551234567)
+	Address        string   `json:"address,omitempty"` // host:port (--tcp) or unix:///path (--socket)
+	DMPolicy       string   `json:"dm_policy,omitempty"`
+	GroupPolicy    string   `json:"group_policy,omitempty"`
+	AllowFrom      []string `json:"allow_from,omitempty"` // sender UUIDs or group IDs
+	RequireMention *bool    `json:"require_mention,omitempty"`
+	HistoryLimit   int      `json:"history_limit,omitempty"`
+	ReactionLevel  string   `json:"reaction_level,omitempty"` // off, minimal (default), full
+	Typing         *bool    `json:"typing,omitempty"`         // typing indicator while the agent works (default true)
+	ReadReceipts   *bool    `json:"read_receipts,omitempty"`  // mark handled messages read (default true)
+	MediaMaxMB     int      `json:"media_max_mb,omitempty"`
+	BlockReply     *bool    `json:"block_reply,omitempty"`
+}
+
+// Factory creates a Signal channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return buildChannel(name, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return buildChannel(name, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func buildChannel(name string, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var ic signalInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode signal config: %w", err)
+		}
+	}
+
+	// Secure default: DB instances default to "pairing" for groups.
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "pairing"
+	}
+
+	ch, err := New(ic, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
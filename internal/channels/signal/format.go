@@ -0,0 +1,204 @@
+package signal
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Signal has no markdown; formatting is sent as text style ranges
+// ("start:length:STYLE", UTF-16 offsets) next to the plain text.
+const (
+	styleBold          = "BOLD"
+	styleItalic        = "ITALIC"
+	styleStrikethrough = "STRIKETHROUGH"
+	styleMonospace     = "MONOSPACE"
+)
+
+// styledText accumulates plain text and its style ranges.
+type styledText struct {
+	b      strings.Builder
+	pos    int // current length in UTF-16 code units
+	styles []string
+}
+
+func (s *styledText) write(text string) {
+	s.b.WriteString(text)
+	s.pos += len(utf16.Encode([]rune(text)))
+}
+
+func (s *styledText) style(start int, style string) {
+	if s.pos > start {
+		s.styles = append(s.styles, fmt.Sprintf("%d:%d:%s", start, s.pos-start, style))
+	}
+}
+
+// formatMarkdown converts agent markdown to Signal plain text plus text
+// styles: bold, italic, strikethrough, inline code and code blocks
+// (monospace), headings (bold), bullets and links.
+func formatMarkdown(md string) (string, []string) {
+	var out styledText
+	codeStart := -1 // UTF-16 offset of the open code block, -1 outside one
+	inCode := false
+	for i, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inCode && codeStart >= 0 {
+				out.style(codeStart, styleMonospace)
+			}
+			inCode, codeStart = !inCode, -1
+			continue
+		}
+		if i > 0 && out.pos > 0 {
+			out.write("\n")
+		}
+		switch {
+		case inCode:
+			if codeStart < 0 {
+				codeStart = out.pos
+			}
+			out.write(line)
+		default:
+			if level, rest := heading(line); level > 0 {
+				start := out.pos
+				writeInline(&out, rest)
+				out.style(start, styleBold)
+			} else if indent, rest, ok := bullet(line); ok {
+				out.write(indent + "• ")
+				writeInline(&out, rest)
+			} else {
+				writeInline(&out, line)
+			}
+		}
+	}
+	if inCode && codeStart >= 0 {
+		out.style(codeStart, styleMonospace)
+	}
+	return out.b.String(), out.styles
+}
+
+// heading returns the level and text of an ATX heading line.
+func heading(line string) (int, string) {
+	level := 0
+	for level < len(line) && level < 6 && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level >= len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
+// bullet recognizes "- item" and "* item" list lines.
+func bullet(line string) (indent, rest string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(trimmed) < 2 || (trimmed[0] != '-' && trimmed[0] != '*') || trimmed[1] != ' ' {
+		return "", "", false
+	}
+	return line[:len(line)-len(trimmed)], trimmed[2:], true
+}
+
+// inlineMarkers are tried in order at each position; longer markers first.
+var inlineMarkers = []struct {
+	open, close string
+	style       string
+}{
+	{"**", "**", styleBold},
+	{"__", "__", styleBold},
+	{"~~", "~~", styleStrikethrough},
+	{"*", "*", styleItalic},
+	{"_", "_", styleItalic},
+}
+
+// writeInline writes one line, converting inline markdown.
+func writeInline(out *styledText, s string) {
+	var prev byte // last source byte consumed
+	for len(s) > 0 {
+		// Inline code: content is literal.
+		if s[0] == '`' {
+			if end := strings.IndexByte(s[1:], '`'); end > 0 {
+				start := out.pos
+				out.write(s[1 : end+1])
+				out.style(start, styleMonospace)
+				prev, s = '`', s[end+2:]
+				continue
+			}
+		}
+		// Links: [text](url) -> "text (url)".
+		if s[0] == '[' {
+			if text, url, n, ok := link(s); ok {
+				writeInline(out, text)
+				if url != text {
+					out.write(" (" + url + ")")
+				}
+				prev, s = ')', s[n:]
+				continue
+			}
+		}
+		// Intra-word underscores (snake_case) are literal.
+		if !(s[0] == '_' && isWordByte(prev)) {
+			if n := emphasis(out, s); n > 0 {
+				prev, s = s[n-1], s[n:]
+				continue
+			}
+		}
+		// Copy up to the next potential marker.
+		next := strings.IndexAny(s[1:], "`[*_~")
+		if next < 0 {
+			out.write(s)
+			return
+		}
+		out.write(s[:next+1])
+		prev, s = s[next], s[next+1:]
+	}
+}
+
+// emphasis handles a bold/italic/strikethrough span at the start of s and
+// returns the number of bytes consumed (0 if s does not start one).
+func emphasis(out *styledText, s string) int {
+	for _, m := range inlineMarkers {
+		if !strings.HasPrefix(s, m.open) {
+			continue
+		}
+		body := s[len(m.open):]
+		// The opener must be followed by text, not whitespace ("2 * 3").
+		if body == "" || body[0] == ' ' || strings.HasPrefix(body, m.close) {
+			continue
+		}
+		end := strings.Index(body, m.close)
+		if end <= 0 || body[end-1] == ' ' {
+			continue
+		}
+		// snake_case: an underscore closer followed by a word character is not emphasis.
+		after := end + len(m.close)
+		if m.close[0] == '_' && after < len(body) && isWordByte(body[after]) {
+			continue
+		}
+		start := out.pos
+		writeInline(out, body[:end])
+		out.style(start, m.style)
+		return len(m.open) + after
+	}
+	return 0
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// link parses "[text](url)" at the start of s.
+func link(s string) (text, url string, n int, ok bool) {
+	closeText := strings.Index(s, "](")
+	if closeText < 1 {
+		return "", "", 0, false
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 1 {
+		return "", "", 0, false
+	}
+	text = s[1:closeText]
+	url = s[closeText+2 : closeText+2+closeURL]
+	if strings.ContainsAny(text, "[]") || strings.ContainsAny(url, " \t") {
+		return "", "", 0, false
+	}
+	return text, url, closeText + 3 + closeURL, true
+}
@@ -0,0 +1,34 @@
+package signal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		md         string
+		wantText   string
+		wantStyles []string
+	}{
+		{"plain", "hello", "hello", nil},
+		{"bold and italic", "**Done** in *2s*", "Done in 2s", []string{"0:4:BOLD", "8:2:ITALIC"}},
+		{"strike", "~~old~~ new", "old new", []string{"0:3:STRIKETHROUGH"}},
+		{"snake_case is literal", "set max_tokens_limit", "set max_tokens_limit", nil},
+		{"spaced asterisk", "2 * 3 * 4", "2 * 3 * 4", nil},
+		{"heading and bullets", "## Plan\n- one\n- two", "Plan\n• one\n• two", []string{"0:4:BOLD"}},
+		{"link", "see [docs](https://x.test/a)", "see docs (https://x.test/a)", nil},
+		{"code block", "run:\n```sh\ngo test\n```", "run:\ngo test", []string{"5:7:MONOSPACE"}},
+		{"utf16 offsets", "🔥 **hot**", "🔥 hot", []string{"3:3:BOLD"}},
+		{"nested", "**a `b` c**", "a b c", []string{"2:1:MONOSPACE", "0:5:BOLD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, styles := formatMarkdown(tt.md)
+			if text != tt.wantText || !slices.Equal(styles, tt.wantStyles) {
+				t.Errorf("formatMarkdown(%q) = %q %v, want %q %v", tt.md, text, styles, tt.wantText, tt.wantStyles)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package signal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// objectReplacement marks a mention in the message body.
+const objectReplacement = "￼"
+
+// handleEnvelope processes one received envelope. Receipts, typing and sync
+// messages carry no data message and are ignored.
+func (c *Channel) handleEnvelope(ctx context.Context, env *envelope) {
+	dm := env.DataMessage
+	if dm == nil || c.isSelf(env.SourceUUID, env.SourceNumber) {
+		return
+	}
+	// Reactions to messages are not conversation turns.
+	if dm.Reaction != nil {
+		slog.Debug("signal: reaction received", "sender", env.senderID(), "emoji", dm.Reaction.Emoji)
+		return
+	}
+	if dm.GroupInfo != nil && dm.GroupInfo.Type != "" && dm.GroupInfo.Type != "DELIVER" {
+		return // group updates, joins and leaves
+	}
+
+	senderID := env.senderID()
+	if senderID == "" {
+		return
+	}
+	isDM := dm.GroupInfo == nil
+	chatID := senderID
+	peerKind := "direct"
+	if !isDM {
+		chatID = dm.GroupInfo.GroupID
+		peerKind = "group"
+	}
+	if dm.Timestamp == 0 {
+		dm.Timestamp = env.Timestamp
+	}
+	messageID := strconv.FormatInt(dm.Timestamp, 10)
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, chatID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, chatID) {
+		return
+	}
+
+	displayName := env.SourceName
+	if displayName == "" {
+		displayName = env.SourceNumber
+	}
+	if displayName == "" {
+		displayName = senderID
+	}
+
+	content, mentioned := c.resolveMentions(dm)
+	if dm.Quote != nil && c.isSelf(dm.Quote.AuthorUUID, dm.Quote.AuthorNumber) {
+		mentioned = true // a reply to the bot counts as addressing it
+	}
+	if dm.Sticker != nil && content == "" {
+		content = "[Sticker]"
+	}
+
+	mediaList := c.downloadAttachments(ctx, chatID, dm.Attachments)
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	if !isDM && c.RequireMention() && !mentioned {
+		c.GroupHistory().Record(chatID, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: messageID,
+		}, c.HistoryLimit())
+
+		// Collect contact even when bot is not mentioned (cache prevents DB spam).
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		slog.Debug("signal group message recorded (no mention)",
+			"group_id", chatID, "user", displayName)
+		return
+	}
+
+	slog.Debug("signal message received",
+		"sender_id", senderID, "chat_id", chatID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	c.authors.Store(chatID+":"+messageID, senderID)
+	if c.readReceipts {
+		c.sendReadReceipt(ctx, senderID, dm.Timestamp)
+	}
+
+	finalContent := content
+	if !isDM {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMediaPaths := c.GroupHistory().CollectMedia(chatID); len(histMediaPaths) > 0 {
+				mediaPaths = append(mediaPaths, histMediaPaths...)
+			}
+			finalContent = c.GroupHistory().BuildContext(chatID, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":   messageID,
+		"user_id":      senderID,
+		"display_name": channels.SanitizeDisplayName(displayName),
+		"is_dm":        fmt.Sprintf("%t", isDM),
+		"platform":     channels.TypeSignal,
+	}
+	if !isDM && dm.GroupInfo.GroupName != "" {
+		metadata["group_name"] = dm.GroupInfo.GroupName
+	}
+
+	c.HandleMessage(senderID, chatID, finalContent, mediaPaths, metadata, peerKind)
+
+	if !isDM {
+		c.GroupHistory().Clear(chatID)
+	}
+}
+
+// resolveMentions replaces mention placeholders with "@name", dropping
+// mentions of the bot, and reports whether the bot was mentioned.
+// Mention ranges are in UTF-16 code units.
+func (c *Channel) resolveMentions(dm *dataMessage) (string, bool) {
+	if len(dm.Mentions) == 0 {
+		return strings.TrimSpace(dm.Message), false
+	}
+	units := utf16.Encode([]rune(dm.Message))
+	mentions := slices.Clone(dm.Mentions)
+	// Replace from the end so earlier offsets stay valid.
+	slices.SortFunc(mentions, func(a, b mention) int { return b.Start - a.Start })
+	mentioned := false
+	for _, m := range mentions {
+		if m.Start < 0 || m.Length <= 0 || m.Start+m.Length > len(units) {
+			continue
+		}
+		var repl []uint16
+		if c.isSelf(m.UUID, m.Number) {
+			mentioned = true
+		} else {
+			name := m.Name
+			if name == "" {
+				name = m.Number
+			}
+			if name == "" {
+				name = m.UUID
+			}
+			repl = utf16.Encode([]rune("@" + name))
+		}
+		units = slices.Concat(units[:m.Start], repl, units[m.Start+m.Length:])
+	}
+	text := strings.ReplaceAll(string(utf16.Decode(units)), objectReplacement, "")
+	return strings.TrimSpace(text), mentioned
+}
+
+// downloadAttachments fetches attachments from the daemon into temp files.
+func (c *Channel) downloadAttachments(ctx context.Context, chatID string, atts []attachment) []media.MediaInfo {
+	var items []media.MediaInfo
+	for _, a := range atts {
+		if a.Size > c.maxMedia {
+			slog.Warn("signal: attachment too large, skipping", "file", a.Filename, "size", a.Size, "max", c.maxMedia)
+			continue
+		}
+		m, err := c.downloadAttachment(ctx, chatID, a)
+		if err != nil {
+			slog.Warn("signal: attachment download failed", "id", a.ID, "error", err)
+			continue
+		}
+		items = append(items, m)
+	}
+	return items
+}
+
+func (c *Channel) downloadAttachment(ctx context.Context, chatID string, a attachment) (media.MediaInfo, error) {
+	params := target(chatID)
+	params["id"] = a.ID
+	var res attachmentResult
+	if err := c.call(ctx, "getAttachment", params, &res); err != nil {
+		return media.MediaInfo{}, err
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Data)
+	if err != nil {
+		return media.MediaInfo{}, fmt.Errorf("decode attachment: %w", err)
+	}
+	if int64(len(data)) > c.maxMedia {
+		return media.MediaInfo{}, fmt.Errorf("attachment exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(a.ContentType)
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = media.DetectMIMEType(a.Filename)
+	}
+	ext := filepath.Ext(a.Filename)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	tmp, err := os.CreateTemp("", "goclaw_signal_*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, err
+	}
+	return media.MediaInfo{
+		Type:        media.MediaKindFromMime(mimeType),
+		FilePath:    tmp.Name(),
+		ContentType: mimeType,
+		FileName:    a.Filename,
+	}, nil
+}
+
+// sendReadReceipt marks a message as read for its sender.
+func (c *Channel) sendReadReceipt(ctx context.Context, senderID string, timestamp int64) {
+	err := c.call(ctx, "sendReceipt", map[string]any{
+		"recipient":       senderID,
+		"targetTimestamp": []int64{timestamp},
+		"type":            "read",
+	}, nil)
+	if err != nil {
+		slog.Debug("signal: read receipt failed", "sender_id", senderID, "error", err)
+	}
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, chatID)
+	}
+	return false
+}
+
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, chatID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+chatID, chatID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, chatID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), chatID, "default", nil)
+	if err != nil {
+		slog.Warn("signal: failed to request pairing code", "error", err)
+		return
+	}
+
+	var msg string
+	if strings.HasPrefix(senderID, "group:") {
+		msg = fmt.Sprintf("This group is not authorized to use this bot.\n\n"+
+			"An admin can approve via CLI:\n  goclaw pairing approve %s\n\n"+
+			"Or approve via the GoClaw web UI (Pairing section).", code)
+	} else {
+		msg = fmt.Sprintf("GoClaw: access not configured.\n\nYour Signal ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+			senderID, code, code)
+	}
+	params := target(chatID)
+	params["message"] = msg
+	if err := c.call(ctx, "send", params, nil); err != nil {
+		slog.Warn("signal: failed to send pairing reply", "chat_id", chatID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
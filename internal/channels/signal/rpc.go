@@ -0,0 +1,194 @@
+package signal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const rpcCallTimeout = 30 * time.Second
+
+var errDisconnected = errors.New("signal-cli connection closed")
+
+// rpcClient is a JSON-RPC 2.0 client over one signal-cli daemon connection.
+// Requests may be issued concurrently; responses are matched by ID, and
+// notifications are passed to the handler given to serve.
+type rpcClient struct {
+	conn    net.Conn
+	account string // added to every request for multi-account daemons
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]chan rpcMessage
+	closed  bool
+	nextID  atomic.Uint64
+	done    chan struct{} // closed when serve returns
+}
+
+// dialRPC connects to a daemon started with --tcp (host:port) or --socket
+// (unix:///path or an absolute path).
+func dialRPC(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		return d.DialContext(ctx, "unix", path)
+	}
+	if strings.HasPrefix(address, "/") {
+		return d.DialContext(ctx, "unix", address)
+	}
+	return d.DialContext(ctx, "tcp", strings.TrimPrefix(address, "tcp://"))
+}
+
+func newRPCClient(conn net.Conn, account string) *rpcClient {
+	return &rpcClient{conn: conn, account: account, pending: make(map[string]chan rpcMessage), done: make(chan struct{})}
+}
+
+// serve reads messages until the connection fails, dispatching notifications
+// to onNotify. Pending calls are failed when it returns.
+func (r *rpcClient) serve(onNotify func(method string, params json.RawMessage)) error {
+	defer close(r.done)
+	dec := json.NewDecoder(r.conn)
+	var err error
+	for {
+		var msg rpcMessage
+		if err = dec.Decode(&msg); err != nil {
+			break
+		}
+		if msg.Method != "" {
+			onNotify(msg.Method, msg.Params)
+			continue
+		}
+		r.mu.Lock()
+		ch, ok := r.pending[msg.ID]
+		delete(r.pending, msg.ID)
+		r.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+
+	r.mu.Lock()
+	r.closed = true
+	for id, ch := range r.pending {
+		close(ch)
+		delete(r.pending, id)
+	}
+	r.mu.Unlock()
+	r.conn.Close()
+	return err
+}
+
+// call sends a request and decodes its result into out (if non-nil).
+func (r *rpcClient) call(ctx context.Context, method string, params map[string]any, out any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	if r.account != "" {
+		params["account"] = r.account
+	}
+	id := strconv.FormatUint(r.nextID.Add(1), 10)
+	line, err := json.Marshal(rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan rpcMessage, 1)
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return errDisconnected
+	}
+	r.pending[id] = ch
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, id)
+		r.mu.Unlock()
+	}()
+
+	r.writeMu.Lock()
+	r.conn.SetWriteDeadline(time.Now().Add(rpcCallTimeout))
+	_, err = r.conn.Write(append(line, '\n'))
+	r.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("signal-cli %s: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rpcCallTimeout)
+	defer cancel()
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			return errDisconnected
+		}
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if out != nil && len(msg.Result) > 0 {
+			if err := json.Unmarshal(msg.Result, out); err != nil {
+				return fmt.Errorf("decode signal-cli %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("signal-cli %s: %w", method, ctx.Err())
+	}
+}
+
+func (r *rpcClient) close() { r.conn.Close() }
+
+// target returns the recipient/group parameters for a chat ID. Group IDs are
+// base64; direct chats are keyed by a service ID (UUID) or phone number.
+func target(chatID string) map[string]any {
+	if isGroupID(chatID) {
+		return map[string]any{"groupId": chatID}
+	}
+	return map[string]any{"recipient": []string{chatID}}
+}
+
+// isGroupID reports whether a chat ID is a group rather than a contact.
+func isGroupID(chatID string) bool {
+	if strings.HasPrefix(chatID, "+") {
+		return false
+	}
+	return !isServiceID(chatID)
+}
+
+// isServiceID reports whether s looks like a Signal service ID (ACI UUID).
+func isServiceID(s string) bool {
+	s = strings.TrimPrefix(s, "PNI:")
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isAccountError reports whether the daemon rejected the configured account
+// (unknown or unregistered number).
+func isAccountError(err error) bool {
+	var re *rpcError
+	if !errors.As(err, &re) {
+		return false
+	}
+	msg := strings.ToLower(re.Message)
+	return strings.Contains(msg, "not registered") || strings.Contains(msg, "unknown account") ||
+		strings.Contains(msg, "no account")
+}
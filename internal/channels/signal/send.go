@@ -0,0 +1,269 @@
+package signal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/typing"
+)
+
+const (
+	reactionDebounceInterval = 700 * time.Millisecond
+	// Signal clients hide a typing indicator after 15s without a refresh.
+	typingKeepalive = 10 * time.Second
+	typingMaxTime   = 2 * time.Minute
+)
+
+// statusEmoji maps GoClaw agent status to reaction emoji.
+var statusEmoji = map[string]string{
+	"thinking": "👀",
+	"tool":     "🛠️",
+	"web":      "🛠️",
+	"coding":   "🛠️",
+	"done":     "✅",
+	"error":    "❌",
+	"stall":    "⏳",
+}
+
+// reactionState tracks the bot's current reaction on one message. Signal
+// keeps one reaction per sender, so a new emoji replaces the previous one.
+type reactionState struct {
+	emoji      string
+	lastUpdate time.Time
+	mu         sync.Mutex
+}
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers an outbound message to a Signal contact or group.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("signal channel not running")
+	}
+	chatID := msg.ChatID
+	if chatID == "" {
+		return fmt.Errorf("empty chat ID for signal send")
+	}
+	defer c.stopTyping(chatID)
+
+	content := msg.Content
+	var attachments []string
+	for _, m := range msg.Media {
+		uri, err := c.attachmentURI(m.URL, m.ContentType)
+		if err != nil {
+			slog.Warn("signal: attachment failed", "file", m.URL, "error", err)
+			content = fmt.Sprintf("%s\n\n[File upload failed: %s]", content, filepath.Base(m.URL))
+			continue
+		}
+		attachments = append(attachments, uri)
+	}
+
+	chunks := channels.ChunkMarkdown(content, maxMessageLen)
+	if len(chunks) == 0 && len(attachments) > 0 {
+		chunks = []string{""}
+	}
+	for i, chunk := range chunks {
+		params := target(chatID)
+		text, styles := formatMarkdown(chunk)
+		params["message"] = text
+		if len(styles) > 0 {
+			params["textStyle"] = styles
+		}
+		if i == 0 && len(attachments) > 0 {
+			params["attachments"] = attachments
+		}
+		if err := c.call(ctx, "send", params, &sendResult{}); err != nil {
+			return fmt.Errorf("send signal message: %w", err)
+		}
+	}
+	return nil
+}
+
+// permanentSignalErrors are signal-cli error fragments for recipients or
+// groups that no retry can reach.
+var permanentSignalErrors = []string{
+	"unregistered user", "invalid group id", "group not found", "not a member",
+}
+
+// ClassifySendError implements channels.SendErrorClassifier. A lost daemon
+// connection, a rejected account or a server rate limit stops every chat,
+// so those pause the channel.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	if errors.Is(err, errDisconnected) || isAccountError(err) {
+		return channels.SendFailure{ChannelWide: true}
+	}
+	var re *rpcError
+	if errors.As(err, &re) {
+		msg := strings.ToLower(re.Message)
+		if strings.Contains(msg, "rate limit") {
+			return channels.SendFailure{ChannelWide: true}
+		}
+		for _, frag := range permanentSignalErrors {
+			if strings.Contains(msg, frag) {
+				return channels.SendFailure{Permanent: true}
+			}
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// attachmentURI reads a local file into a data URI, which signal-cli accepts
+// in place of a path so the daemon need not share GoClaw's filesystem.
+func (c *Channel) attachmentURI(path, contentType string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > c.maxMedia {
+		return "", fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if contentType == "" {
+		contentType = media.DetectMIMEType(path)
+	}
+	// ';' and ',' delimit data URI parameters.
+	name := strings.NewReplacer(";", "_", ",", "_").Replace(filepath.Base(path))
+	return fmt.Sprintf("data:%s;filename=%s;base64,%s", contentType, name, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// OnReactionEvent shows the agent status: a typing indicator while the agent
+// works, plus an emoji reaction on the user's message unless reaction_level
+// is "off".
+func (c *Channel) OnReactionEvent(ctx context.Context, chatID string, messageID string, status string) error {
+	switch status {
+	case "done", "error":
+		c.stopTyping(chatID)
+	case "stall":
+	default:
+		c.startTyping(chatID)
+	}
+
+	if c.config.ReactionLevel == "off" || messageID == "" {
+		return nil
+	}
+	emoji, ok := statusEmoji[status]
+	if !ok {
+		return nil
+	}
+	// For "minimal" level, only show thinking and done
+	if c.config.ReactionLevel == "minimal" && status != "thinking" && status != "done" {
+		return nil
+	}
+	author, timestamp, ok := c.reactionTarget(chatID, messageID)
+	if !ok {
+		return nil
+	}
+
+	stateVal, _ := c.reactions.LoadOrStore(chatID+":"+messageID, &reactionState{})
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.emoji == emoji || time.Since(st.lastUpdate) < reactionDebounceInterval {
+		return nil
+	}
+	if err := c.sendReaction(ctx, chatID, author, timestamp, emoji, false); err != nil {
+		slog.Debug("signal: reaction failed", "emoji", emoji, "error", err)
+		return nil
+	}
+	st.emoji = emoji
+	st.lastUpdate = time.Now()
+	return nil
+}
+
+// ClearReaction removes the status reaction from a message and stops typing.
+func (c *Channel) ClearReaction(ctx context.Context, chatID string, messageID string) error {
+	c.stopTyping(chatID)
+	author, timestamp, targetOK := c.reactionTarget(chatID, messageID)
+	c.authors.Delete(chatID + ":" + messageID)
+
+	stateVal, ok := c.reactions.LoadAndDelete(chatID + ":" + messageID)
+	if !ok || !targetOK {
+		return nil
+	}
+	st := stateVal.(*reactionState)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.emoji != "" {
+		if err := c.sendReaction(ctx, chatID, author, timestamp, st.emoji, true); err != nil {
+			slog.Debug("signal: clear reaction failed", "emoji", st.emoji, "error", err)
+		}
+	}
+	return nil
+}
+
+// reactionTarget resolves the author and timestamp identifying a message.
+// In direct chats the chat ID is the author.
+func (c *Channel) reactionTarget(chatID, messageID string) (string, int64, bool) {
+	timestamp, err := strconv.ParseInt(messageID, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	if v, ok := c.authors.Load(chatID + ":" + messageID); ok {
+		return v.(string), timestamp, true
+	}
+	if !isGroupID(chatID) {
+		return chatID, timestamp, true
+	}
+	return "", 0, false
+}
+
+func (c *Channel) sendReaction(ctx context.Context, chatID, author string, timestamp int64, emoji string, remove bool) error {
+	params := target(chatID)
+	params["emoji"] = emoji
+	params["targetAuthor"] = author
+	params["targetTimestamp"] = timestamp
+	if remove {
+		params["remove"] = true
+	}
+	return c.call(ctx, "sendReaction", params, nil)
+}
+
+// startTyping starts (or keeps) the typing indicator for a chat.
+func (c *Channel) startTyping(chatID string) {
+	if !c.typing {
+		return
+	}
+	if _, ok := c.typingCtrls.Load(chatID); ok {
+		return
+	}
+	ctrl := typing.New(typing.Options{
+		MaxDuration:       typingMaxTime,
+		KeepaliveInterval: typingKeepalive,
+		StartFn: func() error {
+			return c.call(context.Background(), "sendTyping", target(chatID), nil)
+		},
+		StopFn: func() error {
+			params := target(chatID)
+			params["stop"] = true
+			return c.call(context.Background(), "sendTyping", params, nil)
+		},
+	})
+	if _, loaded := c.typingCtrls.LoadOrStore(chatID, ctrl); loaded {
+		return
+	}
+	ctrl.Start()
+}
+
+// stopTyping stops the typing indicator for a chat, if any.
+func (c *Channel) stopTyping(chatID string) {
+	if v, ok := c.typingCtrls.LoadAndDelete(chatID); ok {
+		v.(*typing.Controller).Stop()
+	}
+}
@@ -0,0 +1,273 @@
+// Package signal implements the Signal channel on top of a signal-cli daemon
+// reached over its JSON-RPC socket: incoming messages arrive as "receive"
+// notifications, and replies, reactions, typing indicators and read receipts
+// are JSON-RPC calls. No Signal protocol code lives in GoClaw.
+package signal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	defaultAddress      = "127.0.0.1:7583"
+	pairingDebounceTime = 60 * time.Second
+	maxMessageLen       = 4000
+	defaultMediaMaxMB   = 20
+	maxBackoff          = time.Minute
+)
+
+// Channel connects to a signal-cli daemon over JSON-RPC.
+type Channel struct {
+	*channels.BaseChannel
+	config       signalInstanceConfig
+	accountUUID  string // the bot's own ACI, resolved on Start
+	maxMedia     int64
+	typing       bool
+	readReceipts bool
+
+	rpc    atomic.Pointer[rpcClient] // nil while disconnected
+	events chan receiveParams
+
+	reactions   sync.Map // chatID:timestamp -> *reactionState
+	authors     sync.Map // chatID:timestamp -> author service ID (reaction target)
+	typingCtrls sync.Map // chatID -> *typing.Controller
+
+	wg       sync.WaitGroup
+	cancelFn context.CancelFunc
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new Signal channel from instance config.
+func New(cfg signalInstanceConfig, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	cfg.Account = strings.TrimSpace(cfg.Account)
+	if !strings.HasPrefix(cfg.Account, "+") {
+		return nil, fmt.Errorf("signal account must be a phone number in international format (+...)")
+	}
+	if cfg.Address == "" {
+		cfg.Address = defaultAddress
+	}
+	if cfg.ReactionLevel == "" {
+		cfg.ReactionLevel = "minimal"
+	}
+
+	base := channels.NewBaseChannel(channels.TypeSignal, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+
+	ch := &Channel{
+		BaseChannel:  base,
+		config:       cfg,
+		maxMedia:     int64(cfg.MediaMaxMB) * 1024 * 1024,
+		typing:       cfg.Typing == nil || *cfg.Typing,
+		readReceipts: cfg.ReadReceipts == nil || *cfg.ReadReceipts,
+		events:       make(chan receiveParams, 64),
+	}
+	ch.SetPairingService(pairingSvc)
+	ch.SetRequireMention(requireMention)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeSignal, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start connects to the daemon, checks the account and starts receiving.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("connecting to signal-cli at " + c.config.Address)
+
+	client, version, err := c.connect(ctx)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if isAccountError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("signal-cli unavailable", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return fmt.Errorf("signal connect: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancelFn = cancel
+	c.wg.Add(2)
+	go c.processEvents(runCtx)
+	go c.run(runCtx, client)
+
+	c.SetRunning(true)
+	c.MarkHealthy("connected as " + c.config.Account)
+	slog.Info("signal channel started", "name", c.Name(), "account", c.config.Account,
+		"address", c.config.Address, "signal_cli", version)
+	return nil
+}
+
+// Stop closes the daemon connection and waits for in-flight handlers.
+func (c *Channel) Stop(_ context.Context) error {
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	if client := c.rpc.Load(); client != nil {
+		client.close()
+	}
+	c.wg.Wait()
+	c.typingCtrls.Range(func(key, _ any) bool {
+		c.stopTyping(key.(string))
+		return true
+	})
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("signal channel stopped", "name", c.Name())
+	return nil
+}
+
+// connect dials the daemon and verifies the account. The client's read loop
+// is already running; notifications are queued on c.events.
+func (c *Channel) connect(ctx context.Context) (*rpcClient, string, error) {
+	conn, err := dialRPC(ctx, c.config.Address)
+	if err != nil {
+		return nil, "", err
+	}
+	client := newRPCClient(conn, c.config.Account)
+	go client.serve(c.onNotify)
+	fail := func(err error) (*rpcClient, string, error) {
+		client.close()
+		<-client.done
+		return nil, "", err
+	}
+
+	var v versionResult
+	if err := client.call(ctx, "version", nil, &v); err != nil {
+		return fail(err)
+	}
+	var statuses []userStatus
+	if err := client.call(ctx, "getUserStatus", map[string]any{"recipient": []string{c.config.Account}}, &statuses); err != nil {
+		return fail(err)
+	}
+	for _, s := range statuses {
+		if s.UUID != "" {
+			c.accountUUID = s.UUID
+		}
+	}
+
+	c.rpc.Store(client)
+	return client, v.Version, nil
+}
+
+// run watches the connection and reconnects with exponential backoff.
+func (c *Channel) run(ctx context.Context, client *rpcClient) {
+	defer c.wg.Done()
+	backoff := time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-client.done:
+		}
+		c.rpc.CompareAndSwap(client, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("signal: daemon connection lost", "name", c.Name(), "address", c.config.Address)
+		c.MarkDegraded("signal-cli connection lost", "reconnecting to "+c.config.Address,
+			channels.ChannelFailureKindNetwork, true)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			next, _, err := c.connect(ctx)
+			if err == nil {
+				client = next
+				backoff = time.Second
+				c.MarkHealthy("connected as " + c.config.Account)
+				break
+			}
+			slog.Warn("signal: reconnect failed", "name", c.Name(), "error", err, "retry_in", backoff)
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}
+}
+
+// onNotify queues incoming messages. It runs on the read loop, so handling
+// (which issues RPC calls of its own) happens on processEvents.
+func (c *Channel) onNotify(method string, params json.RawMessage) {
+	if method != "receive" {
+		return
+	}
+	var p receiveParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		slog.Debug("signal: malformed receive notification", "error", err)
+		return
+	}
+	if p.Account != "" && p.Account != c.config.Account {
+		return // another account on a multi-account daemon
+	}
+	select {
+	case c.events <- p:
+	default:
+		slog.Warn("signal: event queue full, dropping message", "name", c.Name())
+	}
+}
+
+// processEvents handles queued messages in arrival order.
+func (c *Channel) processEvents(ctx context.Context) {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-c.events:
+			c.handleEnvelope(ctx, &p.Envelope)
+		}
+	}
+}
+
+// client returns the live daemon connection.
+func (c *Channel) client() (*rpcClient, error) {
+	client := c.rpc.Load()
+	if client == nil {
+		return nil, errDisconnected
+	}
+	return client, nil
+}
+
+// call issues an RPC on the live connection.
+func (c *Channel) call(ctx context.Context, method string, params map[string]any, out any) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	return client.call(ctx, method, params, out)
+}
+
+// isSelf reports whether a service ID or number is the bot's own account.
+func (c *Channel) isSelf(uuid, number string) bool {
+	return (uuid != "" && uuid == c.accountUUID) || (number != "" && number == c.config.Account)
+}
@@ -0,0 +1,307 @@
+package signal
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+const (
+	botNumber = "+15550001111"
+	botUUID   = "0b1d2c3e-0000-4000-8000-000000000001"
+	aliceUUID = "a11ce000-0000-4000-8000-000000000002"
+	groupID   = "Z3JvdXAtaWQtZm9yLXRlc3RzLTMyLWJ5dGVzLWxvbmc="
+)
+
+// fakeDaemon is a minimal signal-cli JSON-RPC daemon: it answers requests,
+// records them, and can push "receive" notifications to connected clients.
+type fakeDaemon struct {
+	ln           net.Listener
+	unregistered bool
+
+	mu    sync.Mutex
+	calls []rpcRequest
+	conns []net.Conn
+}
+
+func newFakeDaemon(t *testing.T) *fakeDaemon {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeDaemon{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			d.mu.Lock()
+			d.conns = append(d.conns, conn)
+			d.mu.Unlock()
+			go d.serve(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		d.dropConnections()
+	})
+	return d
+}
+
+func (d *fakeDaemon) serve(conn net.Conn) {
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		var req rpcRequest
+		if json.Unmarshal(sc.Bytes(), &req) != nil {
+			continue
+		}
+		d.mu.Lock()
+		d.calls = append(d.calls, req)
+		unregistered := d.unregistered
+		d.mu.Unlock()
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		switch req.Method {
+		case "version":
+			resp["result"] = map[string]any{"version": "0.13.9"}
+		case "getUserStatus":
+			if unregistered {
+				resp["error"] = map[string]any{"code": -1, "message": "User " + botNumber + " is not registered."}
+			} else {
+				resp["result"] = []map[string]any{{"number": botNumber, "uuid": botUUID, "isRegistered": true}}
+			}
+		case "getAttachment":
+			resp["result"] = map[string]any{"data": base64.StdEncoding.EncodeToString([]byte("PNGDATA"))}
+		case "send":
+			resp["result"] = map[string]any{"timestamp": 1700000009999}
+		default:
+			resp["result"] = map[string]any{}
+		}
+		line, _ := json.Marshal(resp)
+		conn.Write(append(line, '\n'))
+	}
+}
+
+// push sends a receive notification on every open connection.
+func (d *fakeDaemon) push(t *testing.T, env map[string]any) {
+	t.Helper()
+	line, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "receive",
+		"params":  map[string]any{"account": botNumber, "envelope": env},
+	})
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range d.conns {
+		c.Write(append(line, '\n'))
+	}
+}
+
+func (d *fakeDaemon) dropConnections() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, c := range d.conns {
+		c.Close()
+	}
+	d.conns = nil
+}
+
+func (d *fakeDaemon) called(method string) []rpcRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []rpcRequest
+	for _, c := range d.calls {
+		if c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (d *fakeDaemon) waitFor(t *testing.T, method string, n int) []rpcRequest {
+	t.Helper()
+	channeltest.WaitFor(t, func() bool { return len(d.called(method)) >= n })
+	return d.called(method)
+}
+
+func startTestChannel(t *testing.T, d *fakeDaemon, cfg signalInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.New()
+	cfg.Account = botNumber
+	cfg.Address = d.ln.Addr().String()
+	ch, err := New(cfg, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channeltest.Start(t, ch)
+	return ch, mb
+}
+
+func groupMessage(ts int64, text string, mentions ...map[string]any) map[string]any {
+	return map[string]any{
+		"sourceUuid": aliceUUID, "sourceNumber": "+15550002222", "sourceName": "Alice", "timestamp": ts,
+		"dataMessage": map[string]any{
+			"timestamp": ts, "message": text, "mentions": mentions,
+			"groupInfo": map[string]any{"groupId": groupID, "groupName": "Ops", "type": "DELIVER"},
+		},
+	}
+}
+
+func TestStart_UnregisteredAccount(t *testing.T) {
+	d := newFakeDaemon(t)
+	d.unregistered = true
+	ch, _ := New(signalInstanceConfig{Account: botNumber, Address: d.ln.Addr().String()}, bus.New(), nil, nil)
+	if err := ch.Start(context.Background()); err == nil {
+		t.Fatal("expected Start error")
+	}
+	if h := ch.HealthSnapshot(); h.FailureKind != channels.ChannelFailureKindAuth {
+		t.Errorf("failure kind = %s, want auth", h.FailureKind)
+	}
+}
+
+func TestReceive_GroupMentionGatingAndReceipts(t *testing.T) {
+	d := newFakeDaemon(t)
+	_, mb := startTestChannel(t, d, signalInstanceConfig{GroupPolicy: "open"})
+
+	d.push(t, groupMessage(1000, "deploy is red"))
+	// "🔥" is two UTF-16 units, so the placeholder starts at index 3.
+	d.push(t, groupMessage(1001, "🔥 ￼ why?",
+		map[string]any{"uuid": botUUID, "number": botNumber, "name": "Claw", "start": 3, "length": 1}))
+
+	msg := channeltest.Consume(t, mb)
+	if msg.ChatID != groupID || msg.PeerKind != "group" || msg.SenderID != aliceUUID {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "deploy is red") || !strings.Contains(msg.Content, "[From: Alice]\n🔥  why?") {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if msg.Metadata["message_id"] != "1001" || msg.Metadata["group_name"] != "Ops" {
+		t.Errorf("metadata = %v", msg.Metadata)
+	}
+
+	// Only the message handed to the agent is marked read.
+	receipts := d.waitFor(t, "sendReceipt", 1)
+	if receipts[0].Params["recipient"] != aliceUUID || receipts[0].Params["type"] != "read" {
+		t.Errorf("receipt params = %v", receipts[0].Params)
+	}
+	if ts := receipts[0].Params["targetTimestamp"].([]any); len(ts) != 1 || ts[0] != float64(1001) {
+		t.Errorf("receipt timestamps = %v", ts)
+	}
+}
+
+func TestReceive_DMAttachmentAfterReconnect(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, mb := startTestChannel(t, d, signalInstanceConfig{DMPolicy: "open", ReadReceipts: new(bool)})
+
+	d.dropConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(d.called("version")) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(d.called("version")) < 2 {
+		t.Fatal("channel did not reconnect")
+	}
+	for ch.HealthSnapshot().State != channels.ChannelHealthStateHealthy && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	d.push(t, map[string]any{
+		"sourceUuid": aliceUUID, "sourceName": "Alice", "timestamp": 2000,
+		"dataMessage": map[string]any{
+			"timestamp": 2000, "message": "what is this?",
+			"attachments": []map[string]any{{"id": "att1", "contentType": "image/png", "filename": "shot.png", "size": 7}},
+		},
+	})
+	msg := channeltest.Consume(t, mb)
+	if msg.ChatID != aliceUUID || msg.PeerKind != "direct" || len(msg.Media) != 1 {
+		t.Fatalf("inbound = %+v", msg)
+	}
+	defer os.Remove(msg.Media[0].Path)
+	if data, _ := os.ReadFile(msg.Media[0].Path); string(data) != "PNGDATA" {
+		t.Errorf("attachment = %q", data)
+	}
+	if !strings.Contains(msg.Content, "what is this?") {
+		t.Errorf("content = %q", msg.Content)
+	}
+	if got := d.called("getAttachment"); len(got) != 1 || got[0].Params["id"] != "att1" {
+		t.Errorf("getAttachment calls = %+v", got)
+	}
+	if n := len(d.called("sendReceipt")); n != 0 {
+		t.Errorf("read receipts sent with read_receipts=false: %d", n)
+	}
+}
+
+func TestSend_StylesReactionsAndTyping(t *testing.T) {
+	d := newFakeDaemon(t)
+	ch, mb := startTestChannel(t, d, signalInstanceConfig{GroupPolicy: "open", RequireMention: new(bool)})
+	ctx := context.Background()
+
+	d.push(t, groupMessage(3000, "status?"))
+	channeltest.Consume(t, mb)
+
+	ch.OnReactionEvent(ctx, groupID, "3000", "thinking")
+	typingCalls := d.waitFor(t, "sendTyping", 1)
+	if typingCalls[0].Params["groupId"] != groupID {
+		t.Errorf("typing params = %v", typingCalls[0].Params)
+	}
+	reactions := d.waitFor(t, "sendReaction", 1)
+	if p := reactions[0].Params; p["emoji"] != "👀" || p["targetAuthor"] != aliceUUID || p["targetTimestamp"] != float64(3000) {
+		t.Errorf("reaction params = %v", p)
+	}
+
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: groupID, Content: "All **green**, see `make test`"}); err != nil {
+		t.Fatal(err)
+	}
+	sends := d.called("send")
+	if len(sends) != 1 {
+		t.Fatalf("send calls = %d", len(sends))
+	}
+	p := sends[0].Params
+	if p["groupId"] != groupID || p["message"] != "All green, see make test" || p["account"] != botNumber {
+		t.Errorf("send params = %v", p)
+	}
+	var styles []string
+	for _, s := range p["textStyle"].([]any) {
+		styles = append(styles, s.(string))
+	}
+	if !slices.Equal(styles, []string{"4:5:BOLD", "15:9:MONOSPACE"}) {
+		t.Errorf("textStyle = %v", styles)
+	}
+	// Sending stops the typing indicator.
+	stops := d.waitFor(t, "sendTyping", 2)
+	if stops[len(stops)-1].Params["stop"] != true {
+		t.Errorf("last typing call = %v", stops[len(stops)-1].Params)
+	}
+
+	ch.ClearReaction(ctx, groupID, "3000")
+	removed := d.waitFor(t, "sendReaction", 2)
+	if removed[1].Params["remove"] != true || removed[1].Params["emoji"] != "👀" {
+		t.Errorf("clear reaction params = %v", removed[1].Params)
+	}
+}
+
+func TestIsGroupID(t *testing.T) {
+	for id, want := range map[string]bool{
+		aliceUUID:      false,
+		"+15550002222": false,
+		groupID:        true,
+	} {
+		if got := isGroupID(id); got != want {
+			t.Errorf("isGroupID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package signal
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// --- JSON-RPC framing (one JSON object per line) ---
+
+type rpcRequest struct {
+	JSONRPC string         `json:"jsonrpc"`
+	Method  string         `json:"method"`
+	Params  map[string]any `json:"params,omitempty"`
+	ID      string         `json:"id"`
+}
+
+// rpcMessage is a line from the daemon: a response when ID is set, a
+// notification (e.g. "receive") when Method is set.
+type rpcMessage struct {
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("signal-cli error %d: %s", e.Code, e.Message)
+}
+
+// --- Incoming messages ---
+
+// receiveParams is the payload of a "receive" notification.
+type receiveParams struct {
+	Account  string   `json:"account"`
+	Envelope envelope `json:"envelope"`
+}
+
+type envelope struct {
+	Source       string       `json:"source"`
+	SourceNumber string       `json:"sourceNumber"`
+	SourceUUID   string       `json:"sourceUuid"`
+	SourceName   string       `json:"sourceName"`
+	Timestamp    int64        `json:"timestamp"`
+	DataMessage  *dataMessage `json:"dataMessage"`
+}
+
+// senderID returns the sender's service ID (ACI UUID), falling back to the
+// phone number for legacy envelopes.
+func (e *envelope) senderID() string {
+	if e.SourceUUID != "" {
+		return e.SourceUUID
+	}
+	if e.SourceNumber != "" {
+		return e.SourceNumber
+	}
+	return e.Source
+}
+
+type dataMessage struct {
+	Timestamp   int64        `json:"timestamp"`
+	Message     string       `json:"message"`
+	GroupInfo   *groupInfo   `json:"groupInfo"`
+	Attachments []attachment `json:"attachments"`
+	Mentions    []mention    `json:"mentions"`
+	Quote       *quote       `json:"quote"`
+	Reaction    *reaction    `json:"reaction"`
+	Sticker     *struct{}    `json:"sticker"`
+}
+
+type groupInfo struct {
+	GroupID   string `json:"groupId"`
+	GroupName string `json:"groupName"`
+	Type      string `json:"type"` // DELIVER, UPDATE, QUIT, ...
+}
+
+type attachment struct {
+	ID          string `json:"id"`
+	ContentType string `json:"contentType"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+}
+
+// mention replaces one U+FFFC placeholder in the message body. Start and
+// Length are in UTF-16 code units.
+type mention struct {
+	Name   string `json:"name"`
+	Number string `json:"number"`
+	UUID   string `json:"uuid"`
+	Start  int    `json:"start"`
+	Length int    `json:"length"`
+}
+
+type quote struct {
+	ID           int64  `json:"id"` // timestamp of the quoted message
+	Author       string `json:"author"`
+	AuthorNumber string `json:"authorNumber"`
+	AuthorUUID   string `json:"authorUuid"`
+	Text         string `json:"text"`
+}
+
+type reaction struct {
+	Emoji               string `json:"emoji"`
+	TargetAuthorUUID    string `json:"targetAuthorUuid"`
+	TargetSentTimestamp int64  `json:"targetSentTimestamp"`
+	IsRemove            bool   `json:"isRemove"`
+}
+
+// --- Results ---
+
+type versionResult struct {
+	Version string `json:"version"`
+}
+
+type userStatus struct {
+	Number       string `json:"number"`
+	UUID         string `json:"uuid"`
+	IsRegistered bool   `json:"isRegistered"`
+}
+
+type sendResult struct {
+	Timestamp int64 `json:"timestamp"`
+}
+
+type attachmentResult struct {
+	Data string `json:"data"` // base64
+}
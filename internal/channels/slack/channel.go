@@ -126,6 +126,7 @@ func New(cfg config.SlackConfig, msgBus *bus.MessageBus, pairingSvc store.Pairin
 	ch.SetRequireMention(requireMention)
 	ch.SetPairingService(pairingSvc)
 	ch.SetGroupHistory(channels.MakeHistory(channels.TypeSlack, pendingStore, base.TenantID()))
+	ch.SetCommandReplier(ch.sendCommandReply)
 	ch.SetHistoryLimit(historyLimit)
 	return ch, nil
 }
@@ -260,6 +261,10 @@ func (c *Channel) handleEvent(evt socketmode.Event) {
 	switch evt.Type {
 	case socketmode.EventTypeEventsAPI:
 		c.handleEventsAPI(evt)
+	case socketmode.EventTypeSlashCommand:
+		c.handleSlashCommand(evt)
+	case socketmode.EventTypeInteractive:
+		c.handleInteractive(evt)
 	case socketmode.EventTypeDisconnect:
 		slog.Info("slack socket mode disconnecting (will auto-reconnect)")
 	}
@@ -0,0 +1,140 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	slackapi "github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// handleSlashCommand runs a Slack slash command through the shared command
+// registry. Slash commands are declared in the Slack app config (one entry per
+// command, e.g. /reset); Socket Mode delivers them without a request URL.
+// Commands unknown to the registry are passed to the agent as a message.
+func (c *Channel) handleSlashCommand(evt socketmode.Event) {
+	cmd, ok := evt.Data.(slackapi.SlashCommand)
+	if !ok {
+		return
+	}
+
+	// Ack immediately (Slack requires ack within ~3s)
+	c.sm.Ack(*evt.Request)
+
+	ctx := store.WithTenantID(context.Background(), c.TenantID())
+	isDM := strings.HasPrefix(cmd.ChannelID, "D")
+	peerKind := "group"
+	if isDM {
+		peerKind = "direct"
+		if !c.checkDMPolicy(ctx, cmd.UserID, cmd.ChannelID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, cmd.UserID, cmd.ChannelID) {
+		return
+	}
+	if !c.IsAllowed(cmd.UserID) && !c.IsAllowed(cmd.ChannelID) {
+		return
+	}
+
+	text := strings.TrimSpace(cmd.Command + " " + cmd.Text)
+	slog.Debug("slack slash command received", "command", cmd.Command, "user_id", cmd.UserID, "channel_id", cmd.ChannelID)
+
+	if c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID: cmd.UserID,
+		ChatID:   cmd.ChannelID,
+		PeerKind: peerKind,
+		Text:     text,
+	}) {
+		return
+	}
+	c.HandleMessage(cmd.UserID, cmd.ChannelID, text, nil, map[string]string{"username": cmd.UserName}, peerKind)
+}
+
+// maxSectionTextLen is Slack's limit for a section block's text.
+const maxSectionTextLen = 3000
+
+// sendCommandReply delivers a command reply, rendering its actions as Block
+// Kit buttons whose value is the command to run when clicked.
+func (c *Channel) sendCommandReply(_ context.Context, req *channels.CommandRequest, reply channels.CommandReply) error {
+	text := markdownToSlackMrkdwn(reply.Text)
+	threadTS := req.Metadata["message_thread_id"]
+	if len(reply.Actions) == 0 {
+		return c.sendChunked(req.ChatID, text, threadTS)
+	}
+
+	section := text
+	if len(section) > maxSectionTextLen {
+		if err := c.sendChunked(req.ChatID, text, threadTS); err != nil {
+			return err
+		}
+		section = "Choose an action:"
+	}
+	var buttons []slackapi.BlockElement
+	for i, a := range reply.Actions {
+		label := slackapi.NewTextBlockObject(slackapi.PlainTextType, channels.Truncate(a.Label, 70), true, false)
+		buttons = append(buttons, slackapi.NewButtonBlockElement(fmt.Sprintf("cmd_%d", i), a.Command, label))
+	}
+	blocks := []slackapi.Block{
+		slackapi.NewSectionBlock(slackapi.NewTextBlockObject(slackapi.MarkdownType, section, false, false), nil, nil),
+	}
+	// An actions block holds at most 25 elements.
+	for len(buttons) > 0 {
+		n := min(len(buttons), 25)
+		blocks = append(blocks, slackapi.NewActionBlock("", buttons[:n]...))
+		buttons = buttons[n:]
+	}
+
+	opts := []slackapi.MsgOption{slackapi.MsgOptionText(text, false), slackapi.MsgOptionBlocks(blocks...)}
+	if threadTS != "" {
+		opts = append(opts, slackapi.MsgOptionTS(threadTS))
+	}
+	_, _, err := c.api.PostMessage(req.ChatID, opts...)
+	return err
+}
+
+// handleInteractive runs the commands behind clicked Block Kit buttons.
+func (c *Channel) handleInteractive(evt socketmode.Event) {
+	cb, ok := evt.Data.(slackapi.InteractionCallback)
+	if !ok {
+		return
+	}
+	c.sm.Ack(*evt.Request)
+	if cb.Type != slackapi.InteractionTypeBlockActions {
+		return
+	}
+
+	ctx := store.WithTenantID(context.Background(), c.TenantID())
+	channelID := cb.Channel.ID
+	peerKind := "group"
+	if strings.HasPrefix(channelID, "D") {
+		peerKind = "direct"
+		if !c.checkDMPolicy(ctx, cb.User.ID, channelID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, cb.User.ID, channelID) {
+		return
+	}
+
+	metadata := map[string]string{}
+	if cb.Message.ThreadTimestamp != "" {
+		metadata["message_thread_id"] = cb.Message.ThreadTimestamp
+	}
+	for _, action := range cb.ActionCallback.BlockActions {
+		if !strings.HasPrefix(action.Value, "/") {
+			continue
+		}
+		slog.Debug("slack button clicked", "command", action.Value, "user_id", cb.User.ID, "channel_id", channelID)
+		c.HandleCommand(ctx, &channels.CommandRequest{
+			SenderID: cb.User.ID,
+			ChatID:   channelID,
+			PeerKind: peerKind,
+			Text:     action.Value,
+			Metadata: metadata,
+		})
+	}
+}
@@ -210,6 +210,23 @@ func (c *Channel) handleMessage(ev *slackevents.MessageEvent) {
 	content = c.stripBotMention(content)
 	content = strings.TrimSpace(content)
 
+	// Text replies to approval prompts ("APPROVE 1234") resolve the approval.
+	if _, ok := channels.ApprovalReplyCommand(content); ok {
+		cmdMeta := map[string]string{}
+		if threadTS != "" {
+			cmdMeta["message_thread_id"] = threadTS
+		}
+		if c.HandleCommand(ctx, &channels.CommandRequest{
+			SenderID: senderID,
+			ChatID:   channelID,
+			PeerKind: peerKind,
+			Text:     content,
+			Metadata: cmdMeta,
+		}) {
+			return
+		}
+	}
+
 	slog.Debug("slack message received",
 		"sender_id", senderID, "channel_id", channelID,
 		"is_dm", isDM, "preview", channels.Truncate(content, 50))
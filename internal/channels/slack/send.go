@@ -2,8 +2,10 @@ package slack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/nextlevelbuilder/goclaw/internal/channels"
@@ -114,3 +116,23 @@ func splitAtLimit(content string, maxLen int) (chunk, remaining string) {
 	}
 	return chunks[0], strings.Join(chunks[1:], "\n")
 }
+
+// permanentSlackErrors are Web API error codes that no retry can fix.
+var permanentSlackErrors = []string{
+	"channel_not_found", "not_in_channel", "is_archived", "account_inactive",
+	"restricted_action", "cannot_dm_bot", "user_not_found", "msg_too_long",
+}
+
+// ClassifySendError implements channels.SendErrorClassifier: rate limits
+// carry Slack's Retry-After, and the error codes above are permanent.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var rl *slackapi.RateLimitedError
+	if errors.As(err, &rl) {
+		return channels.SendFailure{RetryAfter: rl.RetryAfter, ChannelWide: true}
+	}
+	var apiErr slackapi.SlackErrorResponse
+	if errors.As(err, &apiErr) && slices.Contains(permanentSlackErrors, apiErr.Err) {
+		return channels.SendFailure{Permanent: true}
+	}
+	return channels.ClassifySendError(err)
+}
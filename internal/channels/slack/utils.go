@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -21,6 +22,16 @@ func (c *Channel) HandleMessage(senderID, chatID, content string, mediaPaths []s
 		return
 	}
 
+	if c.HandleCommand(store.WithTenantID(context.Background(), c.TenantID()), &channels.CommandRequest{
+		SenderID: senderID,
+		ChatID:   chatID,
+		PeerKind: peerKind,
+		Text:     content,
+		Metadata: metadata,
+	}) {
+		return
+	}
+
 	userID := senderID
 
 	var mediaFiles []bus.MediaFile
@@ -24,27 +24,23 @@ import (
 // Channel connects to Telegram via the Bot API using long polling.
 type Channel struct {
 	*channels.BaseChannel
-	bot               *telego.Bot
-	config            config.TelegramConfig
-	httpClient        *http.Client
-	transport         *http.Transport
-	ipv4Once          sync.Once // guards enableIPv4Only to prevent data race
-	agentStore        store.AgentStore            // for agent key lookup (nil if not configured)
-	configPermStore   store.ConfigPermissionStore // for group file writer management (nil if not configured)
-	teamStore         store.TeamStore             // for /tasks, /task_detail commands (nil if not configured)
-	subagentTaskStore store.SubagentTaskStore     // for /subagents, /subagent commands (nil if not configured)
-	placeholders      sync.Map                    // localKey string → messageID int
-	stopThinking      sync.Map                    // localKey string → *thinkingCancel
-	typingCtrls       sync.Map                    // localKey string → *typing.Controller
-	reactions         sync.Map                    // localKey string → *StatusReactionController
-	threadIDs         sync.Map                    // localKey string → messageThreadID int (for forum topic routing)
-	mentionMode       string             // "strict" (default) or "yield"
-	pollCancel        context.CancelFunc // cancels the long polling context
-	pollDone          chan struct{}      // closed when polling goroutine exits
-	handlerWg         sync.WaitGroup     // tracks in-flight handler goroutines for graceful shutdown
-	handlerSem        chan struct{}      // bounded semaphore for concurrent handler goroutines
-	pendingDraftID    sync.Map           // localKey string → int (draftID)
-	audioMgr          *audio.Manager    // unified STT via audio.Manager (nil = no STT)
+	bot            *telego.Bot
+	config         config.TelegramConfig
+	httpClient     *http.Client
+	transport      *http.Transport
+	ipv4Once       sync.Once          // guards enableIPv4Only to prevent data race
+	placeholders   sync.Map           // localKey string → messageID int
+	stopThinking   sync.Map           // localKey string → *thinkingCancel
+	typingCtrls    sync.Map           // localKey string → *typing.Controller
+	reactions      sync.Map           // localKey string → *StatusReactionController
+	threadIDs      sync.Map           // localKey string → messageThreadID int (for forum topic routing)
+	mentionMode    string             // "strict" (default) or "yield"
+	pollCancel     context.CancelFunc // cancels the long polling context
+	pollDone       chan struct{}      // closed when polling goroutine exits
+	handlerWg      sync.WaitGroup     // tracks in-flight handler goroutines for graceful shutdown
+	handlerSem     chan struct{}      // bounded semaphore for concurrent handler goroutines
+	pendingDraftID sync.Map           // localKey string → int (draftID)
+	audioMgr       *audio.Manager     // unified STT via audio.Manager (nil = no STT)
 	// pairingService, approvedGroups, pairingDebounce, groupHistory, historyLimit, requireMention
 	// are inherited from channels.BaseChannel.
 }
@@ -62,22 +58,6 @@ func (c *thinkingCancel) Cancel() {
 // Option configures optional dependencies for the Telegram channel.
 type Option func(*Channel)
 
-// WithAgentStore sets the agent store for agent key resolution.
-func WithAgentStore(s store.AgentStore) Option { return func(c *Channel) { c.agentStore = s } }
-
-// WithConfigPermStore sets the config permission store for group file writer management.
-func WithConfigPermStore(s store.ConfigPermissionStore) Option {
-	return func(c *Channel) { c.configPermStore = s }
-}
-
-// WithTeamStore sets the team store for /tasks, /task_detail commands.
-func WithTeamStore(s store.TeamStore) Option { return func(c *Channel) { c.teamStore = s } }
-
-// WithSubagentTaskStore sets the subagent task store for /subagents, /subagent commands.
-func WithSubagentTaskStore(s store.SubagentTaskStore) Option {
-	return func(c *Channel) { c.subagentTaskStore = s }
-}
-
 // WithPendingMessageStore sets the pending message store for group history buffering.
 func WithPendingMessageStore(s store.PendingMessageStore) Option {
 	return func(c *Channel) {
@@ -158,6 +138,7 @@ func New(cfg config.TelegramConfig, msgBus *bus.MessageBus, pairingSvc store.Pai
 		audioMgr:    audioMgr,
 	}
 	ch.SetPairingService(pairingSvc)
+	ch.SetCommandReplier(ch.sendCommandReply)
 	ch.SetGroupHistory(channels.MakeHistory(channels.TypeTelegram, nil, base.TenantID()))
 	ch.SetHistoryLimit(historyLimit)
 	ch.SetRequireMention(requireMention)
@@ -213,9 +194,9 @@ func (c *Channel) Start(ctx context.Context) error {
 
 	// Register bot menu commands with retry.
 	go func() {
-		commands := DefaultMenuCommands()
 		syncCtx, cancel := context.WithTimeout(pollCtx, probeOverallTimeout)
 		defer cancel()
+		commands := c.menuCommands(syncCtx)
 		var lastErr error
 
 		for attempt := 1; attempt <= 3; attempt++ {
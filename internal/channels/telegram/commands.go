@@ -3,243 +3,194 @@ package telegram
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"strings"
 
-	"github.com/google/uuid"
 	"github.com/mymmrac/telego"
 	tu "github.com/mymmrac/telego/telegoutil"
 
-	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
-// resolveAgentUUID looks up the agent UUID from the channel's agent key.
-// Returns uuid.Nil if the agent key is empty or not found.
-func (c *Channel) resolveAgentUUID(ctx context.Context) (uuid.UUID, error) {
-	key := c.AgentID()
-	if key == "" {
-		return uuid.Nil, fmt.Errorf("no agent key configured")
-	}
-
-	// Try direct UUID parse first (future-proofing).
-	if id, err := uuid.Parse(key); err == nil {
-		return id, nil
-	}
+// commandCallbackPrefix marks inline buttons that run a chat command.
+const commandCallbackPrefix = "cmd:"
 
-	// Inject tenant scope so the store can filter by tenant_id.
-	ctx = store.WithTenantID(ctx, c.TenantID())
-	agent, err := c.agentStore.GetByKey(ctx, key)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("agent %q not found: %w", key, err)
-	}
-	return agent.ID, nil
-}
+// maxCallbackDataLen is Telegram's limit for inline button callback data.
+const maxCallbackDataLen = 64
 
-// handleBotCommand checks if the message is a known bot command and handles it.
-// Returns true if the message was handled as a command.
+// handleBotCommand handles Telegram-only commands and hands the rest to the
+// shared command registry. Returns true if the message was handled as a command.
 func (c *Channel) handleBotCommand(ctx context.Context, message *telego.Message, chatID int64, chatIDStr, localKey, text, senderID string, isGroup, isForum bool, messageThreadID int) bool {
-	if len(text) == 0 || text[0] != '/' {
+	// Text replies to approval prompts ("APPROVE 1234") run as /approve.
+	if cmd, ok := channels.ApprovalReplyCommand(text); ok {
+		text = cmd
+	}
+	name, mention, _, ok := channels.ParseCommand(text)
+	if !ok {
 		return false
 	}
 
-	// Extract command (strip @botname suffix if present)
-	cmd := strings.SplitN(text, " ", 2)[0]
-	cmd = strings.ToLower(cmd)
-
 	// In groups, ignore commands addressed to other bots (e.g. /help@other_bot)
-	if isGroup {
-		if parts := strings.SplitN(cmd, "@", 2); len(parts) == 2 {
-			if !strings.EqualFold(parts[1], c.bot.Username()) {
-				return false
-			}
-		}
+	if isGroup && mention != "" && !strings.EqualFold(mention, c.bot.Username()) {
+		return false
 	}
 
-	cmd = strings.SplitN(cmd, "@", 2)[0]
-
 	// Inject tenant scope so all command handlers have tenant_id in context.
 	ctx = store.WithTenantID(ctx, c.TenantID())
 
-	chatIDObj := tu.ID(chatID)
-
-	// Helper: set MessageThreadID on outgoing messages for forum topics.
-	// TS ref: buildTelegramThreadParams() — General topic (1) must be omitted.
-	setThread := func(msg *telego.SendMessageParams) {
-		sendThreadID := resolveThreadIDForSend(messageThreadID)
-		if sendThreadID > 0 {
-			msg.MessageThreadID = sendThreadID
-		}
-	}
-
-	switch cmd {
-	case "/start":
+	switch name {
+	case "start":
 		// Don't intercept /start — let it pass through to agent loop.
 		return false
 
-	case "/help":
-		helpText := "Available commands:\n" +
-			"/start — Start chatting with the bot\n" +
-			"/help — Show this help message\n" +
-			"/stop — Stop current running task\n" +
-			"/stopall — Stop all running tasks\n" +
-			"/reset — Reset conversation history\n" +
-			"/status — Show bot status\n" +
-			"/reactions — Show reaction emoji legend\n" +
-			"/tasks — List team tasks\n" +
-			"/task_detail <id> — View task detail\n" +
-			"/subagents — List subagent tasks\n" +
-			"/subagent <id> — View subagent task detail\n" +
-			"/writers — List file writers for this group\n" +
-			"/addwriter — Add a file writer (reply to their message)\n" +
-			"/removewriter — Remove a file writer (reply to their message)\n" +
-			"\nJust send a message to chat with the AI."
-		msg := tu.Message(chatIDObj, helpText)
-		setThread(msg)
-		c.bot.SendMessage(ctx, msg)
-		return true
-
-	case "/reset":
-		// In group chats, only file writers can reset conversation history.
-		if isGroup && c.configPermStore != nil {
-			agentID, err := c.resolveAgentUUID(ctx)
-			if err == nil {
-				groupID := fmt.Sprintf("group:%s:%s", c.Name(), chatIDStr)
-				senderNumericID := strings.SplitN(senderID, "|", 2)[0]
-				isWriter, err := c.configPermStore.CheckPermission(ctx, agentID, groupID, store.ConfigTypeFileWriter, senderNumericID)
-				if err != nil {
-					slog.Warn("security.reset_writer_check_failed", "error", err, "sender", senderNumericID)
-					// fail-open: allow reset if DB check fails
-				} else if !isWriter {
-					msg := tu.Message(chatIDObj, "Only file writers can reset conversation history in this group.")
-					setThread(msg)
-					c.bot.SendMessage(ctx, msg)
-					return true
-				}
-			}
+	case "reactions":
+		var lines strings.Builder
+		for _, r := range reactionLegend {
+			lines.WriteString(fmt.Sprintf("%s  %s\n", r.Emoji, r.Desc))
 		}
-
-		// Fix: use correct PeerKind so the gateway consumer builds the right session key.
-		peerKind := "direct"
-		if isGroup {
-			peerKind = "group"
+		reactText := fmt.Sprintf("<b>Reaction Emoji Legend</b>\n\n<pre>%s</pre>\nReaction level: <b>%s</b>", lines.String(), c.config.ReactionLevel)
+		msg := tu.Message(tu.ID(chatID), reactText)
+		msg.ParseMode = telego.ModeHTML
+		if sendThreadID := resolveThreadIDForSend(messageThreadID); sendThreadID > 0 {
+			msg.MessageThreadID = sendThreadID
 		}
-		c.Bus().PublishInbound(bus.InboundMessage{
-			Channel:  c.Name(),
-			SenderID: senderID,
-			ChatID:   chatIDStr,
-			Content:  "/reset",
-			PeerKind: peerKind,
-			AgentID:  c.AgentID(),
-			UserID:   strings.SplitN(senderID, "|", 2)[0],
-			TenantID: c.TenantID(),
-			Metadata: map[string]string{
-				"command":           "reset",
-				"local_key":         localKey,
-				"is_forum":          fmt.Sprintf("%t", isForum),
-				"message_thread_id": fmt.Sprintf("%d", messageThreadID),
-			},
-		})
-		msg := tu.Message(chatIDObj, "Conversation history has been reset.")
-		setThread(msg)
 		c.bot.SendMessage(ctx, msg)
 		return true
+	}
 
-	case "/stop":
-		peerKind := "direct"
-		if isGroup {
-			peerKind = "group"
+	peerKind := "direct"
+	if isGroup {
+		peerKind = "group"
+	}
+	req := &channels.CommandRequest{
+		SenderID: senderID,
+		ChatID:   chatIDStr,
+		PeerKind: peerKind,
+		Text:     text,
+		BotName:  c.bot.Username(),
+		Metadata: map[string]string{
+			"local_key":         localKey,
+			"is_forum":          fmt.Sprintf("%t", isForum),
+			"message_thread_id": fmt.Sprintf("%d", messageThreadID),
+		},
+	}
+	// /addwriter and /removewriter target the author of the replied-to message.
+	if reply := message.ReplyToMessage; reply != nil && reply.From != nil {
+		req.Target = &channels.CommandUser{
+			ID:          fmt.Sprintf("%d", reply.From.ID),
+			DisplayName: reply.From.FirstName,
+			Username:    reply.From.Username,
 		}
-		c.Bus().PublishInbound(bus.InboundMessage{
-			Channel:  c.Name(),
-			SenderID: senderID,
-			ChatID:   chatIDStr,
-			Content:  "/stop",
-			PeerKind: peerKind,
-			AgentID:  c.AgentID(),
-			UserID:   strings.SplitN(senderID, "|", 2)[0],
-			TenantID: c.TenantID(),
-			Metadata: map[string]string{
-				"command":           "stop",
-				"local_key":         localKey,
-				"is_forum":          fmt.Sprintf("%t", isForum),
-				"message_thread_id": fmt.Sprintf("%d", messageThreadID),
-			},
-		})
-		// Feedback is sent by the consumer after cancel result is known.
-		return true
+	}
+	return c.HandleCommand(ctx, req)
+}
 
-	case "/stopall":
-		peerKind := "direct"
-		if isGroup {
-			peerKind = "group"
+// sendCommandReply delivers a command reply, rendering its actions as inline buttons.
+func (c *Channel) sendCommandReply(ctx context.Context, req *channels.CommandRequest, reply channels.CommandReply) error {
+	chatID, err := parseRawChatID(req.ChatID)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %w", err)
+	}
+	var threadID int
+	fmt.Sscanf(req.Metadata["message_thread_id"], "%d", &threadID)
+	sendThreadID := resolveThreadIDForSend(threadID)
+
+	var rows [][]telego.InlineKeyboardButton
+	for _, a := range reply.Actions {
+		data := commandCallbackPrefix + a.Command
+		if len(data) > maxCallbackDataLen {
+			continue
 		}
-		c.Bus().PublishInbound(bus.InboundMessage{
-			Channel:  c.Name(),
-			SenderID: senderID,
-			ChatID:   chatIDStr,
-			Content:  "/stopall",
-			PeerKind: peerKind,
-			AgentID:  c.AgentID(),
-			UserID:   strings.SplitN(senderID, "|", 2)[0],
-			TenantID: c.TenantID(),
-			Metadata: map[string]string{
-				"command":           "stopall",
-				"local_key":         localKey,
-				"is_forum":          fmt.Sprintf("%t", isForum),
-				"message_thread_id": fmt.Sprintf("%d", messageThreadID),
-			},
-		})
-		// Feedback is sent by the consumer after cancel result is known.
-		return true
-
-	case "/status":
-		statusText := fmt.Sprintf("Bot status: Running\nChannel: Telegram\nBot: @%s", c.bot.Username())
-		msg := tu.Message(chatIDObj, statusText)
-		setThread(msg)
-		c.bot.SendMessage(ctx, msg)
-		return true
-
-	case "/tasks":
-		c.handleTasksList(ctx, chatID, isGroup, setThread)
-		return true
-
-	case "/task_detail":
-		c.handleTaskDetail(ctx, chatID, text, isGroup, setThread)
-		return true
+		rows = append(rows, []telego.InlineKeyboardButton{{Text: a.Label, CallbackData: data}})
+	}
+	text := reply.Text
+	// Approval prompts explain themselves; list replies get a hint.
+	if len(rows) > 0 && !strings.HasPrefix(reply.Actions[0].Command, "/approve ") {
+		text += "\n\nTap a button below to view details."
+	}
 
-	case "/subagents":
-		c.handleSubagentsList(ctx, chatID, isGroup, setThread)
-		return true
+	chunks := chunkPlainText(text, telegramMaxMessageLen)
+	for i, chunk := range chunks {
+		msg := tu.Message(tu.ID(chatID), chunk)
+		if sendThreadID > 0 {
+			msg.MessageThreadID = sendThreadID
+		}
+		if i == len(chunks)-1 && len(rows) > 0 {
+			msg.ReplyMarkup = &telego.InlineKeyboardMarkup{InlineKeyboard: rows}
+		}
+		if _, err := c.bot.SendMessage(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	case "/subagent":
-		c.handleSubagentDetail(ctx, chatID, text, isGroup, setThread)
-		return true
+// handleCallbackQuery handles inline keyboard button presses.
+func (c *Channel) handleCallbackQuery(ctx context.Context, query *telego.CallbackQuery) {
+	// Always answer to dismiss the loading indicator.
+	// Inject tenant scope (callback queries bypass handleBotCommand).
+	ctx = store.WithTenantID(ctx, c.TenantID())
 
-	case "/addwriter":
-		c.handleWriterCommand(ctx, message, chatID, chatIDStr, senderID, isGroup, setThread, "add")
-		return true
+	c.bot.AnswerCallbackQuery(ctx, &telego.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+	})
+
+	var text string
+	switch {
+	case strings.HasPrefix(query.Data, commandCallbackPrefix):
+		text = strings.TrimPrefix(query.Data, commandCallbackPrefix)
+	// Buttons sent before commands moved to the shared registry.
+	case strings.HasPrefix(query.Data, "td:"):
+		text = "/task_detail " + strings.TrimPrefix(query.Data, "td:")
+	case strings.HasPrefix(query.Data, "sa:"):
+		text = "/subagent " + strings.TrimPrefix(query.Data, "sa:")
+	default:
+		return
+	}
+	if query.Message == nil {
+		return
+	}
 
-	case "/removewriter":
-		c.handleWriterCommand(ctx, message, chatID, chatIDStr, senderID, isGroup, setThread, "remove")
-		return true
+	// Resolve chat, topic and group status from the callback's message.
+	chat := query.Message.GetChat()
+	chatIDStr := fmt.Sprintf("%d", chat.ID)
+	isGroup := chat.Type == "group" || chat.Type == "supergroup"
+	peerKind := "direct"
+	if isGroup {
+		peerKind = "group"
+	}
+	localKey := chatIDStr
+	threadID := 0
+	if m := query.Message.Message(); m != nil && isGroup && chat.IsForum {
+		threadID = m.MessageThreadID
+		if threadID == 0 {
+			threadID = telegramGeneralTopicID
+		}
+		localKey = fmt.Sprintf("%s:topic:%d", chatIDStr, threadID)
+	}
 
-	case "/writers":
-		c.handleListWriters(ctx, chatID, chatIDStr, isGroup, setThread)
-		return true
+	c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID: fmt.Sprintf("%d", query.From.ID),
+		ChatID:   chatIDStr,
+		PeerKind: peerKind,
+		Text:     text,
+		BotName:  c.bot.Username(),
+		Metadata: map[string]string{
+			"local_key":         localKey,
+			"is_forum":          fmt.Sprintf("%t", isGroup && chat.IsForum),
+			"message_thread_id": fmt.Sprintf("%d", threadID),
+		},
+	})
+}
 
-	case "/reactions":
-		var lines strings.Builder
-		for _, r := range reactionLegend {
-			lines.WriteString(fmt.Sprintf("%s  %s\n", r.Emoji, r.Desc))
+// menuCommands returns the bot menu: the shared chat commands plus the
+// Telegram-only /start and /reactions.
+func (c *Channel) menuCommands(ctx context.Context) []telego.BotCommand {
+	commands := []telego.BotCommand{{Command: "start", Description: "Start chatting with the bot"}}
+	if reg := c.CommandRegistry(); reg != nil {
+		for _, cmd := range reg.MenuCommands(ctx, c.TenantID()) {
+			commands = append(commands, telego.BotCommand{Command: cmd.Name, Description: cmd.Description})
 		}
-		reactText := fmt.Sprintf("<b>Reaction Emoji Legend</b>\n\n<pre>%s</pre>\nReaction level: <b>%s</b>", lines.String(), c.config.ReactionLevel)
-		msg := tu.Message(chatIDObj, reactText)
-		msg.ParseMode = telego.ModeHTML
-		setThread(msg)
-		c.bot.SendMessage(ctx, msg)
-		return true
 	}
-
-	return false
+	return append(commands, telego.BotCommand{Command: "reactions", Description: "Show reaction emoji legend"})
 }
@@ -149,23 +149,3 @@ func (c *Channel) SyncMenuCommands(ctx context.Context, commands []telego.BotCom
 		Commands: commands,
 	})
 }
-
-// DefaultMenuCommands returns the default bot menu commands.
-func DefaultMenuCommands() []telego.BotCommand {
-	return []telego.BotCommand{
-		{Command: "start", Description: "Start chatting with the bot"},
-		{Command: "help", Description: "Show available commands"},
-		{Command: "stop", Description: "Stop current running task"},
-		{Command: "stopall", Description: "Stop all running tasks"},
-		{Command: "reset", Description: "Reset conversation history"},
-		{Command: "status", Description: "Show bot status"},
-		{Command: "reactions", Description: "Show reaction emoji legend"},
-		{Command: "tasks", Description: "List team tasks"},
-		{Command: "task_detail", Description: "View task detail by ID"},
-		{Command: "subagents", Description: "List subagent tasks"},
-		{Command: "subagent", Description: "View subagent task detail by ID"},
-		{Command: "writers", Description: "List file writers for this group"},
-		{Command: "addwriter", Description: "Add a file writer (reply to their message)"},
-		{Command: "removewriter", Description: "Remove a file writer (reply to their message)"},
-	}
-}
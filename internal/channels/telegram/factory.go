@@ -46,20 +46,11 @@ func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
 	return buildChannel(name, creds, cfg, msgBus, pairingSvc, nil)
 }
 
-// FactoryWithStores returns a ChannelFactory that includes optional stores via functional options.
-func FactoryWithStores(agentStore store.AgentStore, configPermStore store.ConfigPermissionStore, teamStore store.TeamStore, subagentTaskStore store.SubagentTaskStore, pendingStore store.PendingMessageStore) channels.ChannelFactory {
-	return FactoryWithStoresAndAudio(agentStore, configPermStore, teamStore, subagentTaskStore, pendingStore, nil)
-}
-
-// FactoryWithStoresAndAudio returns a ChannelFactory with all stores and STT support.
-func FactoryWithStoresAndAudio(agentStore store.AgentStore, configPermStore store.ConfigPermissionStore, teamStore store.TeamStore, subagentTaskStore store.SubagentTaskStore, pendingStore store.PendingMessageStore, audioMgr *audio.Manager) channels.ChannelFactory {
+// FactoryWithPendingStoreAndAudio returns a ChannelFactory with group history persistence and STT support.
+func FactoryWithPendingStoreAndAudio(pendingStore store.PendingMessageStore, audioMgr *audio.Manager) channels.ChannelFactory {
 	return func(name string, creds json.RawMessage, cfg json.RawMessage,
 		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
 		return buildChannel(name, creds, cfg, msgBus, pairingSvc, audioMgr,
-			WithAgentStore(agentStore),
-			WithConfigPermStore(configPermStore),
-			WithTeamStore(teamStore),
-			WithSubagentTaskStore(subagentTaskStore),
 			WithPendingMessageStore(pendingStore),
 		)
 	}
@@ -16,6 +16,7 @@ import (
 	tu "github.com/mymmrac/telego/telegoutil"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/channels/typing"
 )
 
@@ -73,6 +74,26 @@ func isRetryableNetworkErr(err error) bool {
 		strings.Contains(s, "lookup") // DNS resolution failure
 }
 
+// ClassifySendError implements channels.SendErrorClassifier. 429s carry
+// Telegram's retry_after; other 400/403 responses (chat not found, bot
+// blocked, no rights) will fail the same way on every retry.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var apiErr *telegoapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode {
+		case 429:
+			f := channels.SendFailure{ChannelWide: true}
+			if apiErr.Parameters != nil && apiErr.Parameters.RetryAfter > 0 {
+				f.RetryAfter = time.Duration(apiErr.Parameters.RetryAfter+1) * time.Second // +1s safety margin
+			}
+			return f
+		case 400, 403:
+			return channels.SendFailure{Permanent: true}
+		}
+	}
+	return channels.ClassifySendError(err)
+}
+
 // isPostConnectNetworkErr checks if the error likely occurred AFTER reaching the server
 // (timeout, connection reset, EOF) vs before (DNS lookup failure, connection refused).
 // Used to decide if a request may have landed despite the error.
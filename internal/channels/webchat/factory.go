@@ -0,0 +1,63 @@
+package webchat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// webchatCreds maps the credentials JSON from the channel_instances table.
+type webchatCreds struct {
+	SessionSecret  string `json:"session_secret"`            // signs visitor session tokens
+	IdentitySecret string `json:"identity_secret,omitempty"` // shared with the site backend to sign user tokens
+}
+
+// webchatInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type webchatInstanceConfig struct {
+	PublicKey       string   `json:"public_key"`      // embedded in the site; scopes the public endpoints
+	AllowedOrigins  []string `json:"allowed_origins"` // e.g. https://example.com, https://*.example.com
+	DMPolicy        string   `json:"dm_policy,omitempty"`
+	AllowFrom       []string `json:"allow_from,omitempty"`
+	RequireIdentity bool     `json:"require_identity,omitempty"` // only visitors with a signed user token may chat
+	RateLimit       int      `json:"rate_limit,omitempty"`       // messages per visitor per minute (default 10)
+	Title           string   `json:"title,omitempty"`
+	Greeting        string   `json:"greeting,omitempty"`
+	PrimaryColor    string   `json:"primary_color,omitempty"`
+	Stream          *bool    `json:"stream,omitempty"` // stream replies as they are written (default true)
+	MediaMaxMB      int      `json:"media_max_mb,omitempty"`
+	BlockReply      *bool    `json:"block_reply,omitempty"`
+}
+
+// Factory creates a webchat channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+
+	var c webchatCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode webchat credentials: %w", err)
+		}
+	}
+
+	var ic webchatInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode webchat config: %w", err)
+		}
+	}
+
+	// The widget is public by design: anyone on an allowed site may chat.
+	if ic.DMPolicy == "" {
+		ic.DMPolicy = "open"
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
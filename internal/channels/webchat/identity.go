@@ -0,0 +1,100 @@
+package webchat
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Tokens are "<base64url(JSON claims)>.<hex(HMAC-SHA256(secret, first part))>".
+// Visitor tokens are minted by the channel with session_secret; user tokens
+// are minted by the site's backend with identity_secret.
+
+// visitorClaims identify a widget visitor. UserID is set once the visitor
+// has been upgraded with a signed user token.
+type visitorClaims struct {
+	VisitorID string `json:"vid"`
+	UserID    string `json:"uid,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// userClaims are asserted by the site for a logged-in user.
+type userClaims struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name,omitempty"`
+	Exp    int64  `json:"exp"` // unix seconds
+}
+
+var errInvalidToken = errors.New("invalid token")
+
+// senderID is the stable identity used for policies, sessions and replies.
+func (v visitorClaims) senderID() string {
+	if v.UserID != "" {
+		return "u_" + v.UserID
+	}
+	return v.VisitorID
+}
+
+func newVisitorID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "v_" + hex.EncodeToString(b)
+}
+
+func signToken(secret string, claims any) string {
+	payload, _ := json.Marshal(claims)
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + tokenMAC(secret, body)
+}
+
+func parseToken(secret, token string, claims any) error {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok || secret == "" || !hmac.Equal([]byte(sig), []byte(tokenMAC(secret, body))) {
+		return errInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return errInvalidToken
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return errInvalidToken
+	}
+	return nil
+}
+
+func tokenMAC(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseVisitorToken verifies a token minted by issueVisitorToken.
+func (c *Channel) parseVisitorToken(token string) (visitorClaims, error) {
+	var v visitorClaims
+	if err := parseToken(c.sessionSecret, token, &v); err != nil || v.VisitorID == "" {
+		return visitorClaims{}, errInvalidToken
+	}
+	return v, nil
+}
+
+func (c *Channel) issueVisitorToken(v visitorClaims) string {
+	return signToken(c.sessionSecret, v)
+}
+
+// parseUserToken verifies a site-signed user token and its expiry.
+func (c *Channel) parseUserToken(token string, now time.Time) (userClaims, error) {
+	var u userClaims
+	if err := parseToken(c.identitySecret, token, &u); err != nil || u.UserID == "" {
+		return userClaims{}, errInvalidToken
+	}
+	if u.Exp == 0 || now.Unix() > u.Exp {
+		return userClaims{}, errors.New("user token expired")
+	}
+	return u, nil
+}
@@ -0,0 +1,265 @@
+package webchat
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// Frame types sent to the widget.
+const (
+	frameReady   = "ready"   // connection accepted; carries the visitor identity
+	frameMessage = "message" // a complete reply (replaces the partial with the same stream_id)
+	framePartial = "partial" // streamed text so far
+	frameRetract = "retract" // the streamed reply was withdrawn
+	frameStatus  = "status"  // agent activity: thinking, tool, idle
+	frameError   = "error"
+)
+
+const (
+	streamThrottleInterval = 250 * time.Millisecond
+	// Replies to a visitor with no open connection are kept briefly so a
+	// page reload or navigation does not lose them.
+	outboxTTL   = 10 * time.Minute
+	maxOutboxed = 20
+)
+
+// outboundFrame is a frame sent to the widget.
+type outboundFrame struct {
+	Type      string       `json:"type"`
+	ID        string       `json:"id,omitempty"`
+	VisitorID string       `json:"visitor_id,omitempty"`
+	UserID    string       `json:"user_id,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	StreamID  string       `json:"stream_id,omitempty"`
+	Media     []frameMedia `json:"media,omitempty"`
+	Status    string       `json:"status,omitempty"`
+	Code      string       `json:"code,omitempty"`
+	Timestamp int64        `json:"timestamp,omitempty"`
+}
+
+// frameMedia is an attachment inlined as a data URI.
+type frameMedia struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url"`
+}
+
+type queuedFrame struct {
+	frame    outboundFrame
+	queuedAt time.Time
+}
+
+func errorFrame(code, text string) outboundFrame {
+	return outboundFrame{Type: frameError, Code: code, Text: text}
+}
+
+// broadcast sends a frame to every open connection of a visitor. With queue
+// set, the frame is kept for the next connection if none is open.
+func (c *Channel) broadcast(senderID string, f outboundFrame, queue bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if set := c.conns[senderID]; len(set) > 0 {
+		for vc := range set {
+			vc.enqueue(f)
+		}
+		return true
+	}
+	if queue {
+		q := append(c.outbox[senderID], queuedFrame{frame: f, queuedAt: time.Now()})
+		if len(q) > maxOutboxed {
+			q = q[len(q)-maxOutboxed:]
+		}
+		c.outbox[senderID] = q
+	}
+	return false
+}
+
+var (
+	errNotRunning  = errors.New("webchat channel not running")
+	errEmptyChatID = errors.New("empty chat ID for webchat send")
+)
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers a reply to the visitor's open widget connections.
+func (c *Channel) Send(_ context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return errNotRunning
+	}
+	chatID := msg.ChatID
+	if chatID == "" {
+		return errEmptyChatID
+	}
+	placeholderKey := chatID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	var streamID string
+	if v, ok := c.streams.LoadAndDelete(placeholderKey); ok {
+		streamID = v.(string)
+	}
+
+	// NO_REPLY: withdraw the streamed text, if any.
+	if msg.Content == "" && len(msg.Media) == 0 {
+		if streamID != "" {
+			c.broadcast(chatID, outboundFrame{Type: frameRetract, StreamID: streamID}, false)
+		}
+		return nil
+	}
+
+	f := outboundFrame{
+		Type:      frameMessage,
+		ID:        uuid.NewString(),
+		Text:      msg.Content,
+		StreamID:  streamID,
+		Timestamp: time.Now().Unix(),
+	}
+	for _, m := range msg.Media {
+		item, err := c.encodeMedia(m.URL, m.ContentType)
+		if err != nil {
+			slog.Warn("webchat: attachment failed", "file", m.URL, "error", err)
+			f.Text = fmt.Sprintf("%s\n\n[File upload failed: %s]", f.Text, filepath.Base(m.URL))
+			continue
+		}
+		f.Media = append(f.Media, item)
+	}
+	if !c.broadcast(chatID, f, true) {
+		slog.Debug("webchat: visitor offline, reply queued", "chat_id", chatID)
+	}
+	return nil
+}
+
+// ClassifySendError implements channels.SendErrorClassifier. Offline
+// visitors never fail a send (their replies are queued in memory), so the
+// only failures are a stopped channel, which holds every chat until the
+// channel is back, and a message with no visitor to address.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	switch {
+	case errors.Is(err, errNotRunning):
+		return channels.SendFailure{ChannelWide: true}
+	case errors.Is(err, errEmptyChatID):
+		return channels.SendFailure{Permanent: true}
+	}
+	return channels.ClassifySendError(err)
+}
+
+// encodeMedia reads a local file into a data URI attachment.
+func (c *Channel) encodeMedia(path, contentType string) (frameMedia, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return frameMedia{}, err
+	}
+	if info.Size() > c.maxMedia {
+		return frameMedia{}, fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return frameMedia{}, err
+	}
+	if contentType == "" {
+		contentType = media.DetectMIMEType(path)
+	}
+	return frameMedia{
+		Filename:    filepath.Base(path),
+		ContentType: contentType,
+		URL:         "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// OnReactionEvent shows agent activity in the widget ("typing" dots).
+func (c *Channel) OnReactionEvent(_ context.Context, chatID string, _ string, status string) error {
+	switch status {
+	case "done", "error":
+		status = "idle"
+	case "stall":
+		return nil
+	}
+	c.broadcast(chatID, outboundFrame{Type: frameStatus, Status: status}, false)
+	return nil
+}
+
+// ClearReaction resets the widget's activity indicator.
+func (c *Channel) ClearReaction(_ context.Context, chatID string, _ string) error {
+	c.broadcast(chatID, outboundFrame{Type: frameStatus, Status: "idle"}, false)
+	return nil
+}
+
+// --- Streaming ---
+
+// webchatStream implements channels.ChannelStream for the widget.
+// Each update is a "partial" frame with the full text so far; the final
+// reply is delivered by Send() with the same stream ID.
+type webchatStream struct {
+	ch         *Channel
+	chatID     string
+	streamID   string
+	sent       bool      // at least one partial was delivered
+	lastUpdate time.Time // last partial frame
+	mu         sync.Mutex
+}
+
+// Update sends the accumulated text as a partial, throttled.
+func (s *webchatStream) Update(_ context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastUpdate) < streamThrottleInterval {
+		return
+	}
+	if s.ch.broadcast(s.chatID, outboundFrame{Type: framePartial, StreamID: s.streamID, Text: fullText}, false) {
+		s.sent = true
+	}
+	s.lastUpdate = time.Now()
+}
+
+// Stop finalizes the stream. Send() delivers the final text, so Stop() is a
+// no-op here — FinalizeStream stores the stream ID into c.streams.
+func (s *webchatStream) Stop(_ context.Context) error {
+	return nil
+}
+
+// MessageID returns 0 — streams are identified by string IDs.
+func (s *webchatStream) MessageID() int {
+	return 0
+}
+
+// StreamEnabled reports whether replies are streamed (visitors are always DMs).
+func (c *Channel) StreamEnabled(_ bool) bool { return c.stream }
+
+// ReasoningStreamEnabled returns false — reasoning is not shown to visitors.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
+
+// CreateStream creates a per-run streaming handle for the given visitor.
+// Implements channels.StreamingChannel.
+func (c *Channel) CreateStream(_ context.Context, chatID string, _ bool) (channels.ChannelStream, error) {
+	return &webchatStream{ch: c, chatID: chatID, streamID: uuid.NewString()}, nil
+}
+
+// FinalizeStream stores the stream ID into c.streams so that Send() can tie
+// the final reply to the partial text already shown.
+// Implements channels.StreamingChannel.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	ws, ok := stream.(*webchatStream)
+	if !ok {
+		return
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.sent {
+		c.streams.Store(chatID, ws.streamID)
+	}
+}
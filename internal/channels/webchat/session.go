@@ -0,0 +1,390 @@
+package webchat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+const (
+	maxSessionBodySize = 16 * 1024
+	// maxFrameSize bounds visitor frames; files go through the upload endpoint.
+	maxFrameSize   = 64 * 1024
+	maxMessageLen  = 4000
+	maxConnsPerID  = 5
+	pongWait       = 60 * time.Second
+	pingInterval   = 30 * time.Second
+	writeWait      = 10 * time.Second
+	sendBufferSize = 64
+)
+
+// sessionRequest is POSTed by the widget when it loads.
+type sessionRequest struct {
+	Token     string `json:"token,omitempty"`      // visitor token from a previous session
+	UserToken string `json:"user_token,omitempty"` // site-signed user token, when logged in
+}
+
+// sessionResponse identifies the visitor and carries the widget settings.
+type sessionResponse struct {
+	Token        string `json:"token"`
+	VisitorID    string `json:"visitor_id"`
+	UserID       string `json:"user_id,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Greeting     string `json:"greeting,omitempty"`
+	PrimaryColor string `json:"primary_color,omitempty"`
+	Uploads      bool   `json:"uploads"`
+}
+
+// visitorFrame is a frame sent by the widget.
+type visitorFrame struct {
+	Type    string   `json:"type"` // "message"
+	ID      string   `json:"id,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Uploads []string `json:"uploads,omitempty"` // IDs returned by the upload endpoint
+}
+
+// handleSession creates or resumes a visitor identity. A valid user token
+// upgrades the visitor to that user; without one, a previously upgraded
+// token falls back to a new anonymous visitor (the user logged out).
+func (c *Channel) handleSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !c.sessionLimiter.Allow(clientIP(r)) {
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many requests"})
+		return
+	}
+	var req sessionRequest
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxSessionBodySize))
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+			return
+		}
+	}
+
+	visitor, err := c.parseVisitorToken(req.Token)
+	if err != nil || (visitor.UserID != "" && req.UserToken == "") {
+		visitor = visitorClaims{VisitorID: newVisitorID()}
+	}
+	if req.UserToken != "" {
+		user, err := c.parseUserToken(req.UserToken, time.Now())
+		if err != nil {
+			slog.Warn("security.webchat_user_token_invalid", "channel", c.Name(), "remote_addr", clientIP(r), "error", err)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid user token"})
+			return
+		}
+		visitor.UserID, visitor.Name = user.UserID, user.Name
+	}
+	if c.config.RequireIdentity && visitor.UserID == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "sign in to chat"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sessionResponse{
+		Token:        c.issueVisitorToken(visitor),
+		VisitorID:    visitor.VisitorID,
+		UserID:       visitor.UserID,
+		Title:        c.config.Title,
+		Greeting:     c.config.Greeting,
+		PrimaryColor: c.config.PrimaryColor,
+		Uploads:      true,
+	})
+}
+
+// visitorFromRequest authenticates a visitor token passed as a bearer token
+// or "token" query parameter (browsers cannot set WebSocket headers).
+func (c *Channel) visitorFromRequest(r *http.Request) (visitorClaims, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	v, err := c.parseVisitorToken(token)
+	if err != nil || (c.config.RequireIdentity && v.UserID == "") {
+		return visitorClaims{}, false
+	}
+	return v, true
+}
+
+func (c *Channel) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	visitor, ok := c.visitorFromRequest(r)
+	if !ok {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	conn, err := c.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade already wrote the error response
+	}
+	vc := &visitorConn{
+		ch:      c,
+		conn:    conn,
+		visitor: visitor,
+		send:    make(chan []byte, sendBufferSize),
+		done:    make(chan struct{}),
+	}
+	if !c.addConn(vc) {
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too many connections"))
+		conn.Close()
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer c.removeConn(vc)
+		go vc.writePump()
+		vc.readPump()
+	}()
+}
+
+// addConn registers a connection, announces the session and flushes queued frames.
+func (c *Channel) addConn(vc *visitorConn) bool {
+	id := vc.visitor.senderID()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.conns[id]) >= maxConnsPerID {
+		return false
+	}
+	if c.conns[id] == nil {
+		c.conns[id] = make(map[*visitorConn]struct{})
+	}
+	c.conns[id][vc] = struct{}{}
+
+	vc.enqueue(outboundFrame{Type: frameReady, VisitorID: vc.visitor.VisitorID, UserID: vc.visitor.UserID})
+	for _, f := range c.outbox[id] {
+		vc.enqueue(f.frame)
+	}
+	delete(c.outbox, id)
+	return true
+}
+
+func (c *Channel) removeConn(vc *visitorConn) {
+	id := vc.visitor.senderID()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns[id], vc)
+	if len(c.conns[id]) == 0 {
+		delete(c.conns, id)
+	}
+}
+
+// --- Connection ---
+
+// visitorConn is one open widget connection.
+type visitorConn struct {
+	ch        *Channel
+	conn      *websocket.Conn
+	visitor   visitorClaims
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (vc *visitorConn) close() {
+	vc.closeOnce.Do(func() { close(vc.done) })
+}
+
+// enqueue queues a frame without blocking; a visitor that stops reading
+// is disconnected rather than stalling replies to everyone else.
+func (vc *visitorConn) enqueue(f outboundFrame) {
+	data, _ := json.Marshal(f)
+	select {
+	case vc.send <- data:
+	default:
+		vc.close()
+	}
+}
+
+// readPump reads visitor frames until the connection closes.
+func (vc *visitorConn) readPump() {
+	defer func() {
+		vc.close()
+		vc.conn.Close()
+	}()
+
+	vc.conn.SetReadLimit(maxFrameSize)
+	vc.conn.SetReadDeadline(time.Now().Add(pongWait))
+	vc.conn.SetPongHandler(func(string) error {
+		vc.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		_, data, err := vc.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Debug("webchat read error", "visitor", vc.visitor.VisitorID, "error", err)
+			}
+			return
+		}
+		vc.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		var f visitorFrame
+		if err := json.Unmarshal(data, &f); err != nil || f.Type != "message" {
+			vc.enqueue(errorFrame("invalid_frame", "expected a message frame"))
+			continue
+		}
+		vc.ch.handleVisitorMessage(context.Background(), vc, &f)
+	}
+}
+
+// writePump writes queued frames and pings to the connection.
+func (vc *visitorConn) writePump() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		vc.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-vc.done:
+			vc.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			vc.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case msg := <-vc.send:
+			vc.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := vc.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			vc.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := vc.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// --- Inbound ---
+
+// visitorLimiter is a per-visitor token bucket.
+type visitorLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// allow reports whether the visitor may send another message or upload.
+func (c *Channel) allow(senderID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.limiters[senderID]
+	if !ok {
+		l = &visitorLimiter{limiter: rate.NewLimiter(rate.Limit(float64(c.config.RateLimit)/60.0), rateLimitBurst)}
+		c.limiters[senderID] = l
+	}
+	l.lastSeen = time.Now()
+	return l.limiter.Allow()
+}
+
+// handleVisitorMessage runs a visitor message through rate limiting and the
+// DM policy before handing it to the agent.
+func (c *Channel) handleVisitorMessage(ctx context.Context, vc *visitorConn, f *visitorFrame) {
+	v := vc.visitor
+	senderID := v.senderID()
+	if !c.allow(senderID) {
+		vc.enqueue(errorFrame("rate_limited", "You're sending messages too quickly. Please wait a moment."))
+		return
+	}
+	if !c.checkDMPolicy(ctx, senderID) {
+		return
+	}
+
+	content := strings.TrimSpace(f.Text)
+	if len(content) > maxMessageLen {
+		content = content[:maxMessageLen]
+	}
+	mediaList := c.claimUploads(senderID, f.Uploads)
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	displayName := v.Name
+	if displayName == "" {
+		displayName = "Visitor"
+	}
+	messageID := f.ID
+	if messageID == "" {
+		messageID = uuid.NewString()
+	}
+
+	slog.Debug("webchat message received",
+		"sender_id", senderID, "identified", v.UserID != "",
+		"preview", channels.Truncate(content, 50))
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "direct", "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":         messageID,
+		"user_id":            senderID,
+		"display_name":       channels.SanitizeDisplayName(displayName),
+		"is_dm":              "true",
+		"platform":           channels.TypeWebChat,
+		"webchat_visitor_id": v.VisitorID,
+	}
+	if v.UserID != "" {
+		metadata["webchat_user_id"] = v.UserID
+	}
+
+	c.HandleMessage(senderID, senderID, content, mediaPaths, metadata, "direct")
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID)
+	default:
+		c.broadcast(senderID, errorFrame("not_allowed", "This chat is not available."), false)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), senderID, "default", nil)
+	if err != nil {
+		slog.Warn("webchat: failed to request pairing code", "error", err)
+		return
+	}
+	msg := fmt.Sprintf("GoClaw: access not configured.\n\nYour visitor ID: %s\n\nPairing code: %s\n\nAsk the site owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code)
+	c.broadcast(senderID, outboundFrame{Type: frameMessage, ID: uuid.NewString(), Text: msg, Timestamp: time.Now().Unix()}, true)
+	c.MarkPairingNotifSent(senderID)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
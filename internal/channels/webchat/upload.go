@@ -0,0 +1,123 @@
+package webchat
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// uploadTTL is how long an uploaded file waits to be attached to a message.
+const uploadTTL = time.Hour
+
+// upload is a visitor file awaiting its message. Files are referenced by an
+// opaque ID so visitors never see or choose server paths.
+type upload struct {
+	owner    string // sender ID of the uploader
+	path     string
+	filename string
+	mimeType string
+}
+
+// handleUpload stores a multipart "file" like the gateway's media upload
+// endpoint, scoped to the visitor and limited by media_max_mb.
+func (c *Channel) handleUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	visitor, ok := c.visitorFromRequest(r)
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+	senderID := visitor.senderID()
+	if !c.allow(senderID) {
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many requests"})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, c.maxMedia+64*1024)
+	if err := r.ParseMultipartForm(c.maxMedia); err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "file too large"})
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing file field"})
+		return
+	}
+	defer file.Close()
+	if header.Size > c.maxMedia {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "file too large"})
+		return
+	}
+
+	// Sanitize filename: strip path, prevent traversal.
+	origName := filepath.Base(header.Filename)
+	if origName == "." || origName == "/" || strings.Contains(origName, "..") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid filename"})
+		return
+	}
+	ext := filepath.Ext(origName)
+	if ext == "" {
+		ext = ".bin"
+	}
+
+	out, err := os.CreateTemp("", "webchat_upload_*"+ext)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to store file"})
+		return
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, file); err != nil {
+		os.Remove(out.Name())
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to store file"})
+		return
+	}
+
+	id := uuid.NewString()
+	mimeType := media.DetectMIMEType(origName)
+	c.uploads.Store(id, &upload{owner: senderID, path: out.Name(), filename: origName, mimeType: mimeType})
+	time.AfterFunc(uploadTTL, func() {
+		if v, ok := c.uploads.LoadAndDelete(id); ok {
+			os.Remove(v.(*upload).path)
+		}
+	})
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":        id,
+		"filename":  origName,
+		"mime_type": mimeType,
+	})
+}
+
+// claimUploads resolves upload IDs sent with a message. IDs that are unknown,
+// expired or owned by another visitor are ignored.
+func (c *Channel) claimUploads(senderID string, ids []string) []media.MediaInfo {
+	var items []media.MediaInfo
+	for _, id := range ids {
+		v, ok := c.uploads.Load(id)
+		if !ok || v.(*upload).owner != senderID {
+			slog.Debug("webchat: ignoring unknown upload", "id", id, "sender_id", senderID)
+			continue
+		}
+		c.uploads.Delete(id)
+		u := v.(*upload)
+		items = append(items, media.MediaInfo{
+			Type:        media.MediaKindFromMime(u.mimeType),
+			FilePath:    u.path,
+			ContentType: u.mimeType,
+			FileName:    u.filename,
+		})
+	}
+	return items
+}
@@ -0,0 +1,359 @@
+// Package webchat implements an embeddable website chat widget. The gateway
+// serves the widget script and public endpoints scoped by each instance's
+// public key: visitors get an anonymous signed identity (upgraded when the
+// site passes a signed user token), chat over a WebSocket, upload files, and
+// receive streamed replies. Unlike the internal "ws" channel, no gateway
+// credentials are involved; access is limited by an origin allowlist and
+// per-visitor rate limits.
+package webchat
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	// pathPrefix is followed by "widget.js" or "<public key>/<endpoint>".
+	pathPrefix          = "/channels/webchat/"
+	pairingDebounceTime = 60 * time.Second
+	defaultMediaMaxMB   = 10
+	defaultRateLimit    = 10 // messages per visitor per minute
+	rateLimitBurst      = 5
+	limiterIdleTTL      = 10 * time.Minute
+)
+
+//go:embed widget.js
+var widgetJS []byte
+
+// Channel serves one embeddable chat widget.
+type Channel struct {
+	*channels.BaseChannel
+	config         webchatInstanceConfig
+	sessionSecret  string
+	identitySecret string
+	maxMedia       int64
+	stream         bool
+	upgrader       websocket.Upgrader
+
+	mu       sync.Mutex
+	conns    map[string]map[*visitorConn]struct{} // sender ID -> open connections
+	outbox   map[string][]queuedFrame             // sender ID -> frames awaiting a connection
+	limiters map[string]*visitorLimiter           // sender ID -> message rate limiter
+
+	sessionLimiter *channels.WebhookRateLimiter // session creation per client IP
+	uploads        sync.Map                     // upload ID -> *upload
+	streams        sync.Map                     // chat ID -> stream ID of the finished stream
+
+	cancelFn context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.StreamingChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new webchat channel from instance config.
+func New(cfg webchatInstanceConfig, creds webchatCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore) (*Channel, error) {
+	if cfg.PublicKey == "" || strings.ContainsAny(cfg.PublicKey, "/?#") {
+		return nil, fmt.Errorf("webchat public_key is required and must not contain '/', '?' or '#'")
+	}
+	if creds.SessionSecret == "" {
+		return nil, fmt.Errorf("webchat session_secret is required")
+	}
+	if len(cfg.AllowedOrigins) == 0 {
+		return nil, fmt.Errorf("webchat allowed_origins is required")
+	}
+	if cfg.RequireIdentity && creds.IdentitySecret == "" {
+		return nil, fmt.Errorf("webchat require_identity needs identity_secret")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeWebChat, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, "")
+
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+	if cfg.RateLimit <= 0 {
+		cfg.RateLimit = defaultRateLimit
+	}
+
+	ch := &Channel{
+		BaseChannel:    base,
+		config:         cfg,
+		sessionSecret:  creds.SessionSecret,
+		identitySecret: creds.IdentitySecret,
+		maxMedia:       int64(cfg.MediaMaxMB) * 1024 * 1024,
+		stream:         cfg.Stream == nil || *cfg.Stream,
+		conns:          make(map[string]map[*visitorConn]struct{}),
+		outbox:         make(map[string][]queuedFrame),
+		limiters:       make(map[string]*visitorLimiter),
+		sessionLimiter: channels.NewWebhookRateLimiter(),
+	}
+	ch.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     func(r *http.Request) bool { return ch.originAllowed(r.Header.Get("Origin")) },
+	}
+	ch.SetPairingService(pairingSvc)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start begins serving the widget's public endpoints.
+func (c *Channel) Start(_ context.Context) error {
+	if err := globalRouter.register(c); err != nil {
+		c.MarkFailed("public key already in use", err.Error(), channels.ChannelFailureKindConfig, false)
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelFn = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.pruneLoop(ctx)
+	}()
+	c.SetRunning(true)
+	c.MarkHealthy("serving widget for " + strings.Join(c.config.AllowedOrigins, ", "))
+	slog.Info("webchat channel started", "name", c.Name(), "origins", c.config.AllowedOrigins)
+	return nil
+}
+
+// Stop stops serving and closes visitor connections.
+func (c *Channel) Stop(_ context.Context) error {
+	globalRouter.unregister(c)
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.mu.Lock()
+	for _, set := range c.conns {
+		for vc := range set {
+			vc.close()
+		}
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("webchat channel stopped", "name", c.Name())
+	return nil
+}
+
+// WebhookHandler returns the shared path prefix and router.
+// Only the first webchat instance mounts the route; others return ("", nil).
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.route()
+}
+
+// pruneLoop drops idle rate limiters and expired queued frames.
+func (c *Channel) pruneLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			c.prune(now)
+		}
+	}
+}
+
+func (c *Channel) prune(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, l := range c.limiters {
+		if now.Sub(l.lastSeen) > limiterIdleTTL {
+			delete(c.limiters, id)
+		}
+	}
+	for id, frames := range c.outbox {
+		kept := frames[:0]
+		for _, f := range frames {
+			if now.Sub(f.queuedAt) < outboxTTL {
+				kept = append(kept, f)
+			}
+		}
+		if len(kept) == 0 {
+			delete(c.outbox, id)
+		} else {
+			c.outbox[id] = kept
+		}
+	}
+}
+
+// originAllowed matches an Origin header against allowed_origins. Entries
+// are exact origins, "https://*.example.com" for subdomains, or "*".
+// Requests without an Origin (non-browser clients) are allowed.
+func (c *Channel) originAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, allowed := range c.config.AllowedOrigins {
+		allowed = strings.TrimSuffix(strings.TrimSpace(allowed), "/")
+		switch {
+		case allowed == "*":
+			return true
+		case strings.EqualFold(allowed, origin):
+			return true
+		case strings.Contains(allowed, "://*."):
+			scheme, domain, _ := strings.Cut(allowed, "://*.")
+			if strings.EqualFold(u.Scheme, scheme) && strings.HasSuffix(strings.ToLower(u.Host), "."+strings.ToLower(domain)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setCORS allows the widget on an allowed site to call the JSON endpoints.
+func (c *Channel) setCORS(w http.ResponseWriter, origin string) {
+	if origin == "" {
+		return
+	}
+	h := w.Header()
+	h.Set("Access-Control-Allow-Origin", origin)
+	h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	h.Set("Access-Control-Max-Age", "600")
+	h.Add("Vary", "Origin")
+}
+
+// clientIP extracts the client IP from the request, checking proxy headers first.
+func clientIP(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		if i := strings.IndexByte(fwd, ','); i > 0 {
+			return strings.TrimSpace(fwd[:i])
+		}
+		return strings.TrimSpace(fwd)
+	}
+	host, _, _ := net.SplitHostPort(r.RemoteAddr)
+	return host
+}
+
+// --- Router ---
+
+// webchatRouter routes widget requests to the instance owning the public
+// key in the path. A single HTTP handler is shared across all webchat
+// channel instances on the same server.
+type webchatRouter struct {
+	mu           sync.RWMutex
+	instances    map[string]*Channel // public key → channel
+	routeHandled bool                // true after first route() call
+}
+
+var globalRouter = &webchatRouter{
+	instances: make(map[string]*Channel),
+}
+
+func (r *webchatRouter) register(ch *Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if other, ok := r.instances[ch.config.PublicKey]; ok && other != ch {
+		return fmt.Errorf("webchat public_key is already used by channel %q", other.Name())
+	}
+	r.instances[ch.config.PublicKey] = ch
+	return nil
+}
+
+func (r *webchatRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.instances[ch.config.PublicKey] == ch {
+		delete(r.instances, ch.config.PublicKey)
+	}
+}
+
+func (r *webchatRouter) lookup(publicKey string) *Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.instances[publicKey]
+}
+
+// route returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *webchatRouter) route() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return pathPrefix, r
+	}
+	return "", nil
+}
+
+// ServeHTTP serves the widget script and dispatches
+// /channels/webchat/<public key>/{session,ws,upload}.
+func (r *webchatRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	rest := strings.TrimPrefix(req.URL.Path, pathPrefix)
+	if rest == "widget.js" {
+		serveWidget(w, req)
+		return
+	}
+	key, endpoint, _ := strings.Cut(rest, "/")
+	ch := r.lookup(key)
+	if ch == nil {
+		http.Error(w, "unknown widget", http.StatusNotFound)
+		return
+	}
+
+	origin := req.Header.Get("Origin")
+	if !ch.originAllowed(origin) {
+		slog.Warn("security.webchat_origin_rejected", "channel", ch.Name(), "origin", origin, "remote_addr", clientIP(req))
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if endpoint != "ws" {
+		ch.setCORS(w, origin)
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	switch endpoint {
+	case "session":
+		ch.handleSession(w, req)
+	case "ws":
+		ch.handleWebSocket(w, req)
+	case "upload":
+		ch.handleUpload(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func serveWidget(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(widgetJS)
+}
@@ -0,0 +1,216 @@
+package webchat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+)
+
+const (
+	testOrigin         = "https://www.example.com"
+	testIdentitySecret = "site-identity-secret"
+)
+
+func startTestChannel(t *testing.T, cfg webchatInstanceConfig) (*Channel, *bus.MessageBus, *httptest.Server) {
+	t.Helper()
+	mb := bus.New()
+	cfg.AllowedOrigins = []string{"https://*.example.com"}
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	ch, err := New(cfg, webchatCreds{SessionSecret: "session-secret", IdentitySecret: testIdentitySecret}, mb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetName("site-" + cfg.PublicKey)
+	channeltest.Start(t, ch)
+	srv := httptest.NewServer(globalRouter)
+	t.Cleanup(srv.Close)
+	return ch, mb, srv
+}
+
+func newSession(t *testing.T, srv *httptest.Server, key, origin string, req sessionRequest) (*http.Response, sessionResponse) {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	r, _ := http.NewRequest(http.MethodPost, srv.URL+pathPrefix+key+"/session", bytes.NewReader(body))
+	r.Header.Set("Origin", origin)
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var s sessionResponse
+	json.NewDecoder(resp.Body).Decode(&s)
+	return resp, s
+}
+
+func dial(t *testing.T, srv *httptest.Server, key, token string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + pathPrefix + key + "/ws?token=" + token
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {testOrigin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readFrame(t *testing.T, conn *websocket.Conn) outboundFrame {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	var f outboundFrame
+	if err := conn.ReadJSON(&f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestSession_OriginAndIdentityUpgrade(t *testing.T) {
+	_, _, srv := startTestChannel(t, webchatInstanceConfig{PublicKey: "pk_session", Title: "Support"})
+
+	if resp, _ := newSession(t, srv, "pk_session", "https://evil.test", sessionRequest{}); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("foreign origin: status %d", resp.StatusCode)
+	}
+
+	resp, anon := newSession(t, srv, "pk_session", testOrigin, sessionRequest{})
+	if resp.StatusCode != http.StatusOK || anon.VisitorID == "" || anon.UserID != "" || anon.Title != "Support" {
+		t.Fatalf("anonymous session: %d %+v", resp.StatusCode, anon)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != testOrigin {
+		t.Errorf("CORS origin = %q", got)
+	}
+
+	// Resuming keeps the visitor; a signed user token upgrades it.
+	userToken := signToken(testIdentitySecret, userClaims{UserID: "42", Name: "Ann", Exp: time.Now().Add(time.Hour).Unix()})
+	_, upgraded := newSession(t, srv, "pk_session", testOrigin, sessionRequest{Token: anon.Token, UserToken: userToken})
+	if upgraded.VisitorID != anon.VisitorID || upgraded.UserID != "42" {
+		t.Errorf("upgraded session = %+v", upgraded)
+	}
+
+	// Without the user token (logged out), the identified token is not reused.
+	_, after := newSession(t, srv, "pk_session", testOrigin, sessionRequest{Token: upgraded.Token})
+	if after.UserID != "" || after.VisitorID == anon.VisitorID {
+		t.Errorf("session after logout = %+v", after)
+	}
+
+	expired := signToken(testIdentitySecret, userClaims{UserID: "42", Exp: time.Now().Add(-time.Minute).Unix()})
+	forged := signToken("wrong-secret", userClaims{UserID: "1", Exp: time.Now().Add(time.Hour).Unix()})
+	for name, tok := range map[string]string{"expired": expired, "forged": forged} {
+		if resp, _ := newSession(t, srv, "pk_session", testOrigin, sessionRequest{UserToken: tok}); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s user token: status %d", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestWebSocket_UploadMessageAndStreamedReply(t *testing.T) {
+	ch, mb, srv := startTestChannel(t, webchatInstanceConfig{PublicKey: "pk_ws"})
+	_, s := newSession(t, srv, "pk_ws", testOrigin, sessionRequest{})
+
+	// Upload a file, then reference it from a message.
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	fw, _ := mw.CreateFormFile("file", "invoice.pdf")
+	fw.Write([]byte("%PDF-1.4"))
+	mw.Close()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+pathPrefix+"pk_ws/upload", &form)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var up struct{ ID string }
+	json.NewDecoder(resp.Body).Decode(&up)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || up.ID == "" {
+		t.Fatalf("upload: status %d", resp.StatusCode)
+	}
+
+	conn := dial(t, srv, "pk_ws", s.Token)
+	if f := readFrame(t, conn); f.Type != frameReady || f.VisitorID != s.VisitorID {
+		t.Fatalf("first frame = %+v", f)
+	}
+	conn.WriteJSON(visitorFrame{Type: "message", ID: "c1", Text: "Why was I charged twice?", Uploads: []string{up.ID, "not-mine"}})
+
+	in := channeltest.Consume(t, mb)
+	if in.SenderID != s.VisitorID || in.ChatID != s.VisitorID || in.PeerKind != "direct" {
+		t.Fatalf("inbound = %+v", in)
+	}
+	if !strings.Contains(in.Content, "Why was I charged twice?") || len(in.Media) != 1 {
+		t.Fatalf("inbound content = %q media = %+v", in.Content, in.Media)
+	}
+	defer os.Remove(in.Media[0].Path)
+	if in.Metadata["message_id"] != "c1" || in.Metadata["webchat_visitor_id"] != s.VisitorID {
+		t.Errorf("metadata = %v", in.Metadata)
+	}
+
+	ctx := context.Background()
+	ch.OnReactionEvent(ctx, s.VisitorID, "c1", "thinking")
+	if f := readFrame(t, conn); f.Type != frameStatus || f.Status != "thinking" {
+		t.Errorf("status frame = %+v", f)
+	}
+	stream, _ := ch.CreateStream(ctx, s.VisitorID, true)
+	stream.Update(ctx, "Let me")
+	ch.FinalizeStream(ctx, s.VisitorID, stream)
+	ch.Send(ctx, bus.OutboundMessage{ChatID: s.VisitorID, Content: "Let me check: it was refunded."})
+
+	partial, final := readFrame(t, conn), readFrame(t, conn)
+	if partial.Type != framePartial || partial.Text != "Let me" || partial.StreamID == "" {
+		t.Errorf("partial = %+v", partial)
+	}
+	if final.Type != frameMessage || final.StreamID != partial.StreamID || final.Text != "Let me check: it was refunded." {
+		t.Errorf("final = %+v", final)
+	}
+}
+
+func TestWebSocket_RateLimitAndOfflineQueue(t *testing.T) {
+	ch, mb, srv := startTestChannel(t, webchatInstanceConfig{PublicKey: "pk_limit", RateLimit: 1})
+	_, s := newSession(t, srv, "pk_limit", testOrigin, sessionRequest{})
+
+	// A reply sent while the visitor is away is delivered on reconnect.
+	ch.Send(context.Background(), bus.OutboundMessage{ChatID: s.VisitorID, Content: "Are you still there?"})
+	conn := dial(t, srv, "pk_limit", s.Token)
+	readFrame(t, conn) // ready
+	if f := readFrame(t, conn); f.Type != frameMessage || f.Text != "Are you still there?" {
+		t.Fatalf("queued frame = %+v", f)
+	}
+
+	for i := range rateLimitBurst + 1 {
+		conn.WriteJSON(visitorFrame{Type: "message", Text: "spam " + string(rune('a'+i))})
+	}
+	if f := readFrame(t, conn); f.Type != frameError || f.Code != "rate_limited" {
+		t.Errorf("frame after burst = %+v", f)
+	}
+	for range rateLimitBurst {
+		channeltest.Consume(t, mb)
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	ch := &Channel{config: webchatInstanceConfig{AllowedOrigins: []string{"https://shop.test", "https://*.example.com/"}}}
+	for origin, want := range map[string]bool{
+		"":                          true,
+		"https://shop.test":         true,
+		"https://help.example.com":  true,
+		"https://example.com":       false,
+		"http://help.example.com":   false,
+		"https://example.com.evil":  false,
+		"https://shop.test.evil.io": false,
+	} {
+		if got := ch.originAllowed(origin); got != want {
+			t.Errorf("originAllowed(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+ * GoClaw website chat widget.
+ *
+ *   <script src="https://GATEWAY/channels/webchat/widget.js" data-key="PUBLIC_KEY" async></script>
+ *
+ * Optional: data-user-token="..." (or window.GoClawChat = { userToken: "..." })
+ * identifies a logged-in user with a token signed by the site's backend.
+ */
+(function () {
+  "use strict";
+  var script = document.currentScript;
+  if (!script || !script.dataset.key) {
+    console.error("goclaw webchat: data-key attribute is required");
+    return;
+  }
+  var opts = window.GoClawChat || {};
+  var key = script.dataset.key;
+  var base = new URL(script.src).origin + "/channels/webchat/" + encodeURIComponent(key);
+  var storageKey = "goclaw_webchat_" + key;
+  var userToken = opts.userToken || script.dataset.userToken || "";
+
+  var session = null;
+  var ws = null;
+  var retryDelay = 1000;
+  var pendingUploads = [];
+
+  // --- DOM (shadow root keeps site CSS out) ---
+  var host = document.createElement("div");
+  document.body.appendChild(host);
+  var root = host.attachShadow({ mode: "open" });
+  root.innerHTML =
+    "<style>" +
+    ":host{all:initial}*{box-sizing:border-box;font-family:system-ui,-apple-system,sans-serif;font-size:14px}" +
+    ".bubble{position:fixed;right:20px;bottom:20px;width:56px;height:56px;border-radius:50%;border:0;cursor:pointer;" +
+    "background:var(--c);color:#fff;box-shadow:0 4px 12px rgba(0,0,0,.2);z-index:2147483646}" +
+    ".panel{position:fixed;right:20px;bottom:88px;width:360px;max-width:calc(100vw - 40px);height:520px;max-height:calc(100vh - 120px);" +
+    "display:none;flex-direction:column;background:#fff;border-radius:12px;box-shadow:0 8px 30px rgba(0,0,0,.25);overflow:hidden;z-index:2147483647}" +
+    ".panel.open{display:flex}.head{background:var(--c);color:#fff;padding:12px 16px;font-weight:600}" +
+    ".log{flex:1;overflow-y:auto;padding:12px;display:flex;flex-direction:column;gap:8px;background:#f7f7f8}" +
+    ".msg{max-width:85%;padding:8px 12px;border-radius:12px;line-height:1.4;white-space:normal;word-wrap:break-word}" +
+    ".bot{background:#fff;border:1px solid #e5e5e5;align-self:flex-start}.me{background:var(--c);color:#fff;align-self:flex-end}" +
+    ".note{align-self:center;color:#888;font-size:12px}.msg img{max-width:100%;border-radius:8px;display:block;margin-top:6px}" +
+    ".msg code{background:rgba(0,0,0,.06);padding:0 3px;border-radius:3px;font-family:ui-monospace,monospace}" +
+    ".typing{display:none;padding:0 12px 6px;color:#888;font-size:12px;background:#f7f7f8}.typing.on{display:block}" +
+    "form{display:flex;gap:6px;padding:8px;border-top:1px solid #eee;align-items:flex-end}" +
+    "textarea{flex:1;resize:none;border:1px solid #ddd;border-radius:8px;padding:8px;height:38px;max-height:120px}" +
+    "button.icon{border:0;background:none;cursor:pointer;font-size:18px;padding:6px}" +
+    ".chips{padding:0 8px;font-size:12px;color:#555}" +
+    "</style>" +
+    '<button class="bubble" aria-label="Chat">&#128172;</button>' +
+    '<div class="panel" role="dialog"><div class="head"></div><div class="log"></div>' +
+    '<div class="typing">Typing…</div><div class="chips"></div>' +
+    '<form><button type="button" class="icon attach" aria-label="Attach file">&#128206;</button>' +
+    '<input type="file" hidden><textarea placeholder="Type a message…" rows="1"></textarea>' +
+    '<button type="submit" class="icon" aria-label="Send">&#10148;</button></form></div>';
+
+  var $ = function (sel) { return root.querySelector(sel); };
+  var panel = $(".panel"), log = $(".log"), typing = $(".typing"), chips = $(".chips");
+  var input = $("textarea"), fileInput = $("input[type=file]");
+  host.style.setProperty("--c", "#2563eb");
+
+  $(".bubble").addEventListener("click", function () {
+    panel.classList.toggle("open");
+    if (panel.classList.contains("open")) {
+      input.focus();
+      if (!session) start();
+    }
+  });
+
+  // --- Rendering ---
+  function escapeHTML(s) {
+    return s.replace(/[&<>"']/g, function (c) {
+      return { "&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;" }[c];
+    });
+  }
+
+  function renderText(text) {
+    return escapeHTML(text)
+      .replace(/`([^`\n]+)`/g, "<code>$1</code>")
+      .replace(/\*\*([^*\n]+)\*\*/g, "<strong>$1</strong>")
+      .replace(/\[([^\]\n]+)\]\((https?:\/\/[^)\s]+)\)/g, '<a href="$2" target="_blank" rel="noopener noreferrer">$1</a>')
+      .replace(/\n/g, "<br>");
+  }
+
+  function addMessage(cls, text, media) {
+    var el = document.createElement("div");
+    el.className = "msg " + cls;
+    setContent(el, text, media);
+    log.appendChild(el);
+    log.scrollTop = log.scrollHeight;
+    return el;
+  }
+
+  function setContent(el, text, media) {
+    el.innerHTML = renderText(text || "");
+    (media || []).forEach(function (m) {
+      if (m.url.indexOf("data:") !== 0) return;
+      if (m.content_type.indexOf("image/") === 0) {
+        var img = document.createElement("img");
+        img.src = m.url;
+        img.alt = m.filename;
+        el.appendChild(img);
+      } else {
+        var a = document.createElement("a");
+        a.href = m.url;
+        a.download = m.filename;
+        a.textContent = "\u{1F4CE} " + m.filename;
+        el.appendChild(document.createElement("br"));
+        el.appendChild(a);
+      }
+    });
+  }
+
+  function streamEl(id) {
+    return id ? log.querySelector('[data-stream="' + CSS.escape(id) + '"]') : null;
+  }
+
+  function note(text) {
+    var el = addMessage("note", text);
+    el.classList.remove("msg");
+  }
+
+  // --- Session & connection ---
+  function start() {
+    fetch(base + "/session", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ token: localStorage.getItem(storageKey) || "", user_token: userToken }),
+    })
+      .then(function (r) {
+        return r.json().then(function (body) {
+          if (!r.ok) throw new Error(body.error || "unavailable");
+          return body;
+        });
+      })
+      .then(function (s) {
+        session = s;
+        localStorage.setItem(storageKey, s.token);
+        $(".head").textContent = s.title || "Chat with us";
+        if (s.primary_color) host.style.setProperty("--c", s.primary_color);
+        $(".attach").style.display = s.uploads ? "" : "none";
+        if (s.greeting && !log.children.length) addMessage("bot", s.greeting);
+        connect();
+      })
+      .catch(function (err) {
+        note("Chat is unavailable: " + err.message);
+      });
+  }
+
+  function connect() {
+    var url = base.replace(/^http/, "ws") + "/ws?token=" + encodeURIComponent(session.token);
+    ws = new WebSocket(url);
+    ws.onopen = function () { retryDelay = 1000; };
+    ws.onmessage = function (ev) { handleFrame(JSON.parse(ev.data)); };
+    ws.onclose = function () {
+      ws = null;
+      setTimeout(connect, retryDelay);
+      retryDelay = Math.min(retryDelay * 2, 30000);
+    };
+  }
+
+  function handleFrame(f) {
+    var el;
+    switch (f.type) {
+      case "partial":
+        el = streamEl(f.stream_id);
+        if (!el) {
+          el = addMessage("bot", "");
+          el.dataset.stream = f.stream_id;
+        }
+        setContent(el, f.text);
+        log.scrollTop = log.scrollHeight;
+        break;
+      case "message":
+        typing.classList.remove("on");
+        el = streamEl(f.stream_id);
+        if (el) setContent(el, f.text, f.media);
+        else addMessage("bot", f.text, f.media);
+        log.scrollTop = log.scrollHeight;
+        break;
+      case "retract":
+        el = streamEl(f.stream_id);
+        if (el) el.remove();
+        break;
+      case "status":
+        typing.classList.toggle("on", f.status !== "idle");
+        break;
+      case "error":
+        note(f.text || f.code);
+        break;
+    }
+  }
+
+  // --- Sending ---
+  $("form").addEventListener("submit", function (ev) {
+    ev.preventDefault();
+    var text = input.value.trim();
+    if ((!text && !pendingUploads.length) || !ws || ws.readyState !== WebSocket.OPEN) return;
+    ws.send(JSON.stringify({ type: "message", id: String(Date.now()), text: text, uploads: pendingUploads.map(function (u) { return u.id; }) }));
+    addMessage("me", text || pendingUploads.map(function (u) { return u.filename; }).join(", "));
+    input.value = "";
+    pendingUploads = [];
+    chips.textContent = "";
+  });
+
+  input.addEventListener("keydown", function (ev) {
+    if (ev.key === "Enter" && !ev.shiftKey) {
+      ev.preventDefault();
+      $("form").requestSubmit();
+    }
+  });
+
+  $(".attach").addEventListener("click", function () { fileInput.click(); });
+  fileInput.addEventListener("change", function () {
+    var file = fileInput.files[0];
+    fileInput.value = "";
+    if (!file || !session) return;
+    var form = new FormData();
+    form.append("file", file);
+    chips.textContent = "Uploading " + file.name + "…";
+    fetch(base + "/upload", { method: "POST", headers: { Authorization: "Bearer " + session.token }, body: form })
+      .then(function (r) {
+        return r.json().then(function (body) {
+          if (!r.ok) throw new Error(body.error || "upload failed");
+          return body;
+        });
+      })
+      .then(function (u) {
+        pendingUploads.push(u);
+        chips.textContent = "\u{1F4CE} " + pendingUploads.map(function (p) { return p.filename; }).join(", ");
+      })
+      .catch(function (err) {
+        chips.textContent = "";
+        note(file.name + ": " + err.message);
+      });
+  });
+})();
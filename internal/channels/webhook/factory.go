@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// webhookCreds maps the credentials JSON from the channel_instances table.
+type webhookCreds struct {
+	Secret         string `json:"secret"`                    // verifies inbound signatures
+	CallbackSecret string `json:"callback_secret,omitempty"` // signs callbacks (default: secret)
+}
+
+// webhookInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type webhookInstanceConfig struct {
+	CallbackURL    string   `json:"callback_url"`
+	DMPolicy       string   `json:"dm_policy,omitempty"`
+	GroupPolicy    string   `json:"group_policy,omitempty"`
+	AllowFrom      []string `json:"allow_from,omitempty"`
+	RequireMention *bool    `json:"require_mention,omitempty"`
+	HistoryLimit   int      `json:"history_limit,omitempty"`
+	Stream         *bool    `json:"stream,omitempty"`          // deliver partial replies while the agent writes
+	MaxRetries     *int     `json:"max_retries,omitempty"`     // callback retries after the first attempt (default 3)
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"` // per-attempt callback timeout (default 10)
+	MediaMaxMB     int      `json:"media_max_mb,omitempty"`
+	BlockReply     *bool    `json:"block_reply,omitempty"`
+}
+
+// Factory creates a webhook channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+	return buildChannel(name, creds, cfg, msgBus, pairingSvc, nil)
+}
+
+// FactoryWithPendingStore returns a ChannelFactory with persistent history support.
+func FactoryWithPendingStore(pendingStore store.PendingMessageStore) channels.ChannelFactory {
+	return func(name string, creds json.RawMessage, cfg json.RawMessage,
+		msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+		return buildChannel(name, creds, cfg, msgBus, pairingSvc, pendingStore)
+	}
+}
+
+func buildChannel(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (channels.Channel, error) {
+
+	var c webhookCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode webhook credentials: %w", err)
+		}
+	}
+
+	var ic webhookInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode webhook config: %w", err)
+		}
+	}
+
+	// Every inbound request is signed by the application, which is expected
+	// to authenticate its own users, so policies default to "open".
+	if ic.DMPolicy == "" {
+		ic.DMPolicy = "open"
+	}
+	if ic.GroupPolicy == "" {
+		ic.GroupPolicy = "open"
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc, pendingStore)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
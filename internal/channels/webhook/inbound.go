@@ -0,0 +1,348 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/security"
+)
+
+// inboundMessage is the JSON body an application POSTs to the channel.
+type inboundMessage struct {
+	ID         string         `json:"id,omitempty"` // client message id; repeats are acknowledged but not processed
+	SenderID   string         `json:"sender_id"`
+	SenderName string         `json:"sender_name,omitempty"`
+	ChatID     string         `json:"chat_id,omitempty"`   // defaults to sender_id
+	ChatType   string         `json:"chat_type,omitempty"` // "direct" (default) or "group"
+	Text       string         `json:"text,omitempty"`
+	Mentioned  bool           `json:"mentioned,omitempty"` // group message addressed to the bot
+	Media      []inboundMedia `json:"media,omitempty"`
+}
+
+// inboundMedia is an attachment, either fetched from url or inlined as base64 data.
+type inboundMedia struct {
+	URL         string `json:"url,omitempty"`
+	Data        string `json:"data,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+func (m *inboundMessage) validate() error {
+	if m.SenderID == "" {
+		return errors.New("sender_id is required")
+	}
+	if m.ChatType != "" && m.ChatType != "direct" && m.ChatType != "group" {
+		return errors.New(`chat_type must be "direct" or "group"`)
+	}
+	if strings.TrimSpace(m.Text) == "" && len(m.Media) == 0 {
+		return errors.New("text or media is required")
+	}
+	for _, a := range m.Media {
+		if (a.URL == "") == (a.Data == "") {
+			return errors.New("each media item needs exactly one of url or data")
+		}
+	}
+	return nil
+}
+
+// readBody reads the request body, bounded by the largest media allowance of
+// the candidate instances (inline media is base64, a third larger).
+func readBody(req *http.Request, candidates []*Channel) ([]byte, error) {
+	var limit int64
+	for _, c := range candidates {
+		limit = max(limit, c.maxMedia*4/3)
+	}
+	limit += 1 << 20
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read error")
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("body too large")
+	}
+	return body, nil
+}
+
+// serveInbound decodes an authenticated request and acknowledges it before
+// processing.
+func (c *Channel) serveInbound(w http.ResponseWriter, body []byte) {
+	var msg inboundMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON body"})
+		return
+	}
+	if err := msg.validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if c.isDuplicate(msg.ID) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "duplicate"})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.handleMessage(c.runCtx, &msg)
+	}()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleMessage runs an accepted message through policies, mention gating and
+// group history before handing it to the agent.
+func (c *Channel) handleMessage(ctx context.Context, msg *inboundMessage) {
+	senderID := msg.SenderID
+	chatID := msg.ChatID
+	if chatID == "" {
+		chatID = senderID
+	}
+	isDM := msg.ChatType != "group"
+	peerKind := "direct"
+	if !isDM {
+		peerKind = "group"
+	}
+
+	if isDM {
+		if !c.checkDMPolicy(ctx, senderID, chatID) {
+			return
+		}
+	} else if !c.checkGroupPolicy(ctx, senderID, chatID) {
+		return
+	}
+
+	displayName := msg.SenderName
+	if displayName == "" {
+		displayName = senderID
+	}
+
+	content := strings.TrimSpace(msg.Text)
+	mediaList := c.resolveMedia(ctx, msg.Media)
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	if !isDM && c.RequireMention() && !msg.Mentioned {
+		c.GroupHistory().Record(chatID, channels.HistoryEntry{
+			Sender:    displayName,
+			SenderID:  senderID,
+			Body:      content,
+			Media:     mediaPaths,
+			Timestamp: time.Now(),
+			MessageID: msg.ID,
+		}, c.HistoryLimit())
+
+		// Collect contact even when bot is not mentioned (cache prevents DB spam).
+		if cc := c.ContactCollector(); cc != nil {
+			cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "group", "user", "", "")
+		}
+		slog.Debug("webhook group message recorded (no mention)",
+			"chat_id", chatID, "user", displayName)
+		return
+	}
+
+	slog.Debug("webhook message received",
+		"sender_id", senderID, "chat_id", chatID,
+		"is_dm", isDM, "preview", channels.Truncate(content, 50))
+
+	finalContent := content
+	if !isDM {
+		annotated := fmt.Sprintf("[From: %s]\n%s", displayName, content)
+		if c.HistoryLimit() > 0 {
+			if histMediaPaths := c.GroupHistory().CollectMedia(chatID); len(histMediaPaths) > 0 {
+				mediaPaths = append(mediaPaths, histMediaPaths...)
+			}
+			finalContent = c.GroupHistory().BuildContext(chatID, annotated, c.HistoryLimit())
+		} else {
+			finalContent = annotated
+		}
+	}
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", peerKind, "user", "", "")
+	}
+
+	metadata := map[string]string{
+		"message_id":   msg.ID,
+		"user_id":      senderID,
+		"display_name": channels.SanitizeDisplayName(displayName),
+		"is_dm":        fmt.Sprintf("%t", isDM),
+		"platform":     channels.TypeWebhook,
+	}
+
+	c.HandleMessage(senderID, chatID, finalContent, mediaPaths, metadata, peerKind)
+
+	if !isDM {
+		c.GroupHistory().Clear(chatID)
+	}
+}
+
+// resolveMedia stores attachments in temp files, skipping any that fail.
+func (c *Channel) resolveMedia(ctx context.Context, items []inboundMedia) []media.MediaInfo {
+	var out []media.MediaInfo
+	for _, item := range items {
+		m, err := c.resolveMediaItem(ctx, item)
+		if err != nil {
+			slog.Warn("webhook: media failed", "file", item.Filename, "error", err)
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func (c *Channel) resolveMediaItem(ctx context.Context, item inboundMedia) (media.MediaInfo, error) {
+	var data []byte
+	var err error
+	contentType := item.ContentType
+	filename := item.Filename
+	if item.Data != "" {
+		if data, err = base64.StdEncoding.DecodeString(item.Data); err != nil {
+			return media.MediaInfo{}, fmt.Errorf("decode media data: %w", err)
+		}
+	} else {
+		var fetchedType string
+		if data, fetchedType, err = c.fetchMedia(ctx, item.URL); err != nil {
+			return media.MediaInfo{}, err
+		}
+		if contentType == "" {
+			contentType = fetchedType
+		}
+		if filename == "" {
+			if u, err := url.Parse(item.URL); err == nil {
+				filename = path.Base(u.Path)
+			}
+		}
+	}
+	if int64(len(data)) > c.maxMedia {
+		return media.MediaInfo{}, fmt.Errorf("media exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+
+	mimeType, _, _ := mime.ParseMediaType(contentType)
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = media.DetectMIMEType(filename)
+	}
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	tmp, err := os.CreateTemp("", "goclaw_webhook_*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, err
+	}
+	return media.MediaInfo{
+		Type:        media.MediaKindFromMime(mimeType),
+		FilePath:    tmp.Name(),
+		ContentType: mimeType,
+		FileName:    filename,
+	}, nil
+}
+
+// fetchMedia downloads an attachment URL supplied by the application. The
+// URL is untrusted input, so the fetch uses the SSRF-safe client.
+func (c *Channel) fetchMedia(ctx context.Context, rawURL string) ([]byte, string, error) {
+	_, pinnedIP, err := security.Validate(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(security.WithPinnedIP(ctx, pinnedIP), http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch media: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxMedia+1))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID, chatID)
+	}
+	return false
+}
+
+func (c *Channel) checkGroupPolicy(ctx context.Context, senderID, chatID string) bool {
+	switch c.CheckGroupPolicy(ctx, senderID, chatID, c.config.GroupPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, "group:"+chatID, chatID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID, chatID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), chatID, "default", nil)
+	if err != nil {
+		slog.Warn("webhook: failed to request pairing code", "error", err)
+		return
+	}
+
+	var msg string
+	if strings.HasPrefix(senderID, "group:") {
+		msg = fmt.Sprintf("This group is not authorized to use this bot.\n\n"+
+			"An admin can approve via CLI:\n  goclaw pairing approve %s\n\n"+
+			"Or approve via the GoClaw web UI (Pairing section).", code)
+	} else {
+		msg = fmt.Sprintf("GoClaw: access not configured.\n\nYour user ID: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+			senderID, code, code)
+	}
+	if err := c.deliver(ctx, newEvent(eventMessage, chatID, msg), true); err != nil {
+		slog.Warn("webhook: failed to send pairing reply", "chat_id", chatID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
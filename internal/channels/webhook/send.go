@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/security"
+)
+
+// Callback event types.
+const (
+	eventMessage = "message" // a complete reply
+	eventPartial = "partial" // streamed text so far; superseded by later events with the same stream_id
+	eventRetract = "retract" // the streamed reply was withdrawn (the agent chose not to answer)
+)
+
+const (
+	maxRetryBackoff      = 30 * time.Second
+	maxCallbackRespBytes = 1 << 20
+)
+
+// outboundEvent is the JSON body POSTed to the callback URL.
+type outboundEvent struct {
+	ID        string          `json:"id"` // also sent as Idempotency-Key; stable across retries
+	Type      string          `json:"type"`
+	ChatID    string          `json:"chat_id"`
+	Content   string          `json:"content,omitempty"`
+	StreamID  string          `json:"stream_id,omitempty"`
+	Media     []outboundMedia `json:"media,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// outboundMedia is an attachment inlined as base64.
+type outboundMedia struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        string `json:"data"`
+}
+
+func newEvent(eventType, chatID, content string) *outboundEvent {
+	return &outboundEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		ChatID:    chatID,
+		Content:   content,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers an outbound message to the callback URL. A reply that
+// follows a stream carries the stream's ID so the application can replace
+// the partial text.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("webhook channel not running")
+	}
+	chatID := msg.ChatID
+	if chatID == "" {
+		return fmt.Errorf("empty chat ID for webhook send")
+	}
+
+	placeholderKey := chatID
+	if pk := msg.Metadata["placeholder_key"]; pk != "" {
+		placeholderKey = pk
+	}
+	var streamID string
+	if v, ok := c.streams.LoadAndDelete(placeholderKey); ok {
+		streamID = v.(string)
+	}
+
+	// NO_REPLY: withdraw the streamed text, if any.
+	if msg.Content == "" && len(msg.Media) == 0 {
+		if streamID == "" {
+			return nil
+		}
+		ev := newEvent(eventRetract, chatID, "")
+		ev.StreamID = streamID
+		return c.deliver(ctx, ev, true)
+	}
+
+	ev := newEvent(eventMessage, chatID, msg.Content)
+	ev.StreamID = streamID
+	// Outbox retries reuse the event ID so the application can deduplicate them.
+	if key := channels.NextIdempotencyKey(ctx); key != "" {
+		ev.ID = key
+	}
+	for _, m := range msg.Media {
+		item, err := c.encodeMedia(m.URL, m.ContentType)
+		if err != nil {
+			slog.Warn("webhook: attachment failed", "file", m.URL, "error", err)
+			ev.Content = fmt.Sprintf("%s\n\n[File upload failed: %s]", ev.Content, filepath.Base(m.URL))
+			continue
+		}
+		ev.Media = append(ev.Media, item)
+	}
+	if err := c.deliver(ctx, ev, true); err != nil {
+		return fmt.Errorf("send webhook callback: %w", err)
+	}
+	return nil
+}
+
+// encodeMedia reads a local file into an inline attachment.
+func (c *Channel) encodeMedia(path, contentType string) (outboundMedia, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return outboundMedia{}, err
+	}
+	if info.Size() > c.maxMedia {
+		return outboundMedia{}, fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return outboundMedia{}, err
+	}
+	if contentType == "" {
+		contentType = media.DetectMIMEType(path)
+	}
+	return outboundMedia{
+		Filename:    filepath.Base(path),
+		ContentType: contentType,
+		Data:        base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// deliver POSTs a signed event to the callback URL. With retry set, network
+// errors, 429 and 5xx responses are retried with exponential backoff; other
+// 4xx responses are final. Every attempt carries the same Idempotency-Key.
+func (c *Channel) deliver(ctx context.Context, ev *outboundEvent, retry bool) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	attempts := 1
+	if retry {
+		attempts += c.maxRetries
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 1; ; attempt++ {
+		wait, err := c.post(ctx, ev.ID, body)
+		if err == nil {
+			c.clearDeliveryFailing()
+			return nil
+		}
+		if wait < 0 || attempt >= attempts {
+			if retry {
+				c.markDeliveryFailing(err)
+			}
+			return err
+		}
+		if wait == 0 {
+			wait = backoff
+			backoff = min(backoff*2, maxRetryBackoff)
+		}
+		slog.Debug("webhook: callback failed, retrying",
+			"event_id", ev.ID, "attempt", attempt, "wait", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// post makes one callback attempt. On failure it returns how long to wait
+// before retrying: -1 for a permanent failure, 0 for the default backoff,
+// or the server's Retry-After.
+func (c *Channel) post(ctx context.Context, eventID string, body []byte) (time.Duration, error) {
+	// Re-validated per attempt: the pinned IP must match the current DNS answer.
+	_, pinnedIP, err := security.Validate(c.config.CallbackURL)
+	if err != nil {
+		return -1, err
+	}
+	req, err := http.NewRequestWithContext(security.WithPinnedIP(ctx, pinnedIP),
+		http.MethodPost, c.config.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerTimestamp, timestamp)
+	req.Header.Set(headerSignature, sign(c.callbackSecret, timestamp, body))
+	req.Header.Set(headerIdempotencyKey, eventID)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxCallbackRespBytes))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		wait := retryAfter(resp.Header.Get("Retry-After"))
+		return wait, &callbackError{StatusCode: resp.StatusCode, RetryAfter: wait}
+	case resp.StatusCode >= 500:
+		return 0, &callbackError{StatusCode: resp.StatusCode}
+	default:
+		return -1, &callbackError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(respBody))}
+	}
+}
+
+// callbackError is a non-2xx response from the callback URL.
+type callbackError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *callbackError) Error() string {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		return "callback rate limited: HTTP 429"
+	case e.Body != "":
+		return fmt.Sprintf("callback HTTP %d: %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("callback HTTP %d", e.StatusCode)
+}
+
+// ClassifySendError implements channels.SendErrorClassifier for the failure
+// left after deliver's own retries, by callback status.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	var ce *callbackError
+	if errors.As(err, &ce) {
+		if f, ok := channels.ClassifyHTTPStatus(ce.StatusCode, ce.RetryAfter); ok {
+			return f
+		}
+		return channels.SendFailure{Permanent: true} // other 4xx are final, as in deliver
+	}
+	return channels.ClassifySendError(err)
+}
+
+// retryAfter parses a Retry-After value in seconds, capped at maxRetryBackoff.
+// Unparseable values fall back to the default backoff.
+func retryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, maxRetryBackoff)
+}
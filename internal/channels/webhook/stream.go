@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+)
+
+const streamThrottleInterval = 1000 * time.Millisecond
+
+// webhookStream implements channels.ChannelStream for the webhook channel.
+// Each update is a "partial" callback carrying the full text so far; the
+// final reply is delivered by Send() with the same stream ID.
+type webhookStream struct {
+	ch         *Channel
+	chatID     string
+	streamID   string
+	sent       bool      // at least one partial was delivered
+	lastUpdate time.Time // last partial callback
+	mu         sync.Mutex
+}
+
+// Update delivers the accumulated text as a partial, throttled. Partials are
+// best-effort: a failed one is not retried, since the next supersedes it.
+func (s *webhookStream) Update(ctx context.Context, fullText string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastUpdate) < streamThrottleInterval {
+		return
+	}
+	ev := newEvent(eventPartial, s.chatID, fullText)
+	ev.StreamID = s.streamID
+	if err := s.ch.deliver(ctx, ev, false); err != nil {
+		slog.Debug("webhook stream partial failed", "error", err)
+		return
+	}
+	s.sent = true
+	s.lastUpdate = time.Now()
+}
+
+// Stop finalizes the stream. Send() delivers the final text, so Stop() is a
+// no-op here — FinalizeStream stores the stream ID into c.streams.
+func (s *webhookStream) Stop(_ context.Context) error {
+	return nil
+}
+
+// MessageID returns 0 — streams are identified by string IDs.
+func (s *webhookStream) MessageID() int {
+	return 0
+}
+
+// StreamEnabled reports whether partial replies are delivered.
+func (c *Channel) StreamEnabled(_ bool) bool {
+	return c.config.Stream != nil && *c.config.Stream
+}
+
+// ReasoningStreamEnabled returns false — reasoning is not sent to the application.
+func (c *Channel) ReasoningStreamEnabled() bool { return false }
+
+// CreateStream creates a per-run streaming handle for the given chat.
+// Implements channels.StreamingChannel.
+func (c *Channel) CreateStream(_ context.Context, chatID string, _ bool) (channels.ChannelStream, error) {
+	return &webhookStream{ch: c, chatID: chatID, streamID: uuid.NewString()}, nil
+}
+
+// FinalizeStream stores the stream ID into c.streams so that Send() can tie
+// the final reply to the partials already delivered.
+// Implements channels.StreamingChannel.
+func (c *Channel) FinalizeStream(_ context.Context, chatID string, stream channels.ChannelStream) {
+	ws, ok := stream.(*webhookStream)
+	if !ok {
+		return
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.sent {
+		c.streams.Store(chatID, ws.streamID)
+	}
+}
@@ -0,0 +1,322 @@
+// Package webhook implements a generic channel for custom applications:
+// inbound messages arrive as HMAC-signed JSON POSTs on the gateway's HTTP
+// mux, and replies (including streamed partials and media) are POSTed to the
+// application's callback URL, signed the same way, with retries and a stable
+// idempotency key per event.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/security"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	// webhookPathPrefix is followed by the channel instance name.
+	webhookPathPrefix   = "/channels/webhook/"
+	pairingDebounceTime = 60 * time.Second
+	defaultMediaMaxMB   = 20
+	defaultMaxRetries   = 3
+	defaultTimeout      = 10 * time.Second
+	messageDedupTTL     = 10 * time.Minute
+	// maxClockSkew bounds the age of a signed request, limiting replays.
+	maxClockSkew = 5 * time.Minute
+
+	headerTimestamp      = "X-Webhook-Timestamp"
+	headerSignature      = "X-Webhook-Signature"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// Channel accepts signed messages from a custom application and delivers
+// replies to its callback URL.
+type Channel struct {
+	*channels.BaseChannel
+	config         webhookInstanceConfig
+	secret         string
+	callbackSecret string
+	client         *http.Client
+	maxRetries     int
+	maxMedia       int64
+	retryBackoff   time.Duration // first retry delay, doubled per attempt
+
+	seenMessages    sync.Map // inbound message id -> struct{}
+	streams         sync.Map // chat ID -> stream ID of the finished stream
+	deliveryFailing atomic.Bool
+
+	runCtx   context.Context
+	cancelFn context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.StreamingChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+
+// New creates a new webhook channel from instance config.
+func New(cfg webhookInstanceConfig, creds webhookCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore, pendingStore store.PendingMessageStore) (*Channel, error) {
+	if creds.Secret == "" {
+		return nil, fmt.Errorf("webhook secret is required")
+	}
+	if cfg.CallbackURL == "" {
+		return nil, fmt.Errorf("webhook callback_url is required")
+	}
+	// Address checks happen per delivery; DNS answers can change.
+	if u, err := url.Parse(cfg.CallbackURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook callback_url must be an http(s) URL")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeWebhook, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, cfg.GroupPolicy)
+
+	requireMention := true
+	if cfg.RequireMention != nil {
+		requireMention = *cfg.RequireMention
+	}
+	historyLimit := cfg.HistoryLimit
+	if historyLimit == 0 {
+		historyLimit = channels.DefaultGroupHistoryLimit
+	}
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+	maxRetries := defaultMaxRetries
+	if cfg.MaxRetries != nil && *cfg.MaxRetries >= 0 {
+		maxRetries = *cfg.MaxRetries
+	}
+	timeout := defaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	callbackSecret := creds.CallbackSecret
+	if callbackSecret == "" {
+		callbackSecret = creds.Secret
+	}
+
+	ch := &Channel{
+		BaseChannel:    base,
+		config:         cfg,
+		secret:         creds.Secret,
+		callbackSecret: callbackSecret,
+		client:         security.NewSafeClient(timeout),
+		maxRetries:     maxRetries,
+		maxMedia:       int64(cfg.MediaMaxMB) * 1024 * 1024,
+		retryBackoff:   time.Second,
+	}
+	ch.SetPairingService(pairingSvc)
+	ch.SetRequireMention(requireMention)
+	ch.SetGroupHistory(channels.MakeHistory(channels.TypeWebhook, pendingStore, base.TenantID()))
+	ch.SetHistoryLimit(historyLimit)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start begins accepting signed requests on /channels/webhook/<name>.
+func (c *Channel) Start(_ context.Context) error {
+	c.runCtx, c.cancelFn = context.WithCancel(context.Background())
+	globalRouter.register(c)
+	c.SetRunning(true)
+	c.MarkHealthy("accepting requests on " + webhookPathPrefix + c.Name())
+	slog.Info("webhook channel started", "name", c.Name(), "callback", redactURL(c.config.CallbackURL))
+	return nil
+}
+
+// Stop stops accepting requests and waits for in-flight handlers.
+func (c *Channel) Stop(_ context.Context) error {
+	globalRouter.unregister(c)
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.wg.Wait()
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("webhook channel stopped", "name", c.Name())
+	return nil
+}
+
+// WebhookHandler returns the shared path prefix and router.
+// Only the first webhook instance mounts the route; others return ("", nil).
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return globalRouter.route()
+}
+
+// markDeliveryFailing surfaces callbacks that failed after all retries.
+// Inbound requests are still accepted, so the channel is degraded.
+func (c *Channel) markDeliveryFailing(err error) {
+	if c.deliveryFailing.Swap(true) {
+		return
+	}
+	slog.Warn("webhook: callback delivery failing", "name", c.Name(), "error", err)
+	c.MarkDegraded("callback delivery failing",
+		"Replies could not be delivered to the callback URL after retries. "+err.Error(),
+		channels.ChannelFailureKindNetwork, true)
+}
+
+// clearDeliveryFailing restores health after a callback succeeds again.
+func (c *Channel) clearDeliveryFailing() {
+	if c.deliveryFailing.Swap(false) {
+		c.MarkHealthy("callback delivery recovered")
+	}
+}
+
+// isDuplicate returns true if the message id was already accepted (client retries).
+func (c *Channel) isDuplicate(id string) bool {
+	if id == "" {
+		return false
+	}
+	if _, loaded := c.seenMessages.LoadOrStore(id, struct{}{}); loaded {
+		return true
+	}
+	time.AfterFunc(messageDedupTTL, func() { c.seenMessages.Delete(id) })
+	return false
+}
+
+// --- Signing ---
+
+// sign returns the signature header value:
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature checks the signature and rejects timestamps outside maxClockSkew.
+func verifySignature(secret, timestamp, signature string, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(sign(secret, timestamp, body)))
+}
+
+// redactURL drops the query string and user info, which may carry tokens.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid url>"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// --- Router ---
+
+// webhookRouter routes requests to channel instances by the name in the
+// path. A single HTTP handler is shared across all webhook channel
+// instances on the same server. Instances in different tenants may share a
+// name; the one whose secret verifies the signature receives the request.
+type webhookRouter struct {
+	mu           sync.RWMutex
+	instances    map[string][]*Channel // instance name → channels
+	routeHandled bool                  // true after first route() call
+}
+
+var globalRouter = &webhookRouter{
+	instances: make(map[string][]*Channel),
+}
+
+func (r *webhookRouter) register(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances[ch.Name()] = append(r.instances[ch.Name()], ch)
+}
+
+func (r *webhookRouter) unregister(ch *Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.instances[ch.Name()]
+	for i, c := range list {
+		if c == ch {
+			list = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(r.instances, ch.Name())
+	} else {
+		r.instances[ch.Name()] = list
+	}
+}
+
+func (r *webhookRouter) lookup(name string) []*Channel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*Channel(nil), r.instances[name]...)
+}
+
+// route returns the path+handler for the first call; ("", nil) for subsequent calls.
+func (r *webhookRouter) route() (string, http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.routeHandled {
+		r.routeHandled = true
+		return webhookPathPrefix, r
+	}
+	return "", nil
+}
+
+// ServeHTTP authenticates the request against the named instance and hands
+// the message to it asynchronously, so slow media downloads never hold the
+// application's request open.
+func (r *webhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.Trim(strings.TrimPrefix(req.URL.Path, webhookPathPrefix), "/")
+	candidates := r.lookup(name)
+	if len(candidates) == 0 {
+		http.Error(w, "unknown channel", http.StatusNotFound)
+		return
+	}
+
+	body, err := readBody(req, candidates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	timestamp := req.Header.Get(headerTimestamp)
+	signature := req.Header.Get(headerSignature)
+	var ch *Channel
+	for _, c := range candidates {
+		if verifySignature(c.secret, timestamp, signature, body, time.Now()) {
+			ch = c
+			break
+		}
+	}
+	if ch == nil {
+		slog.Warn("security.webhook_channel_signature_invalid",
+			"channel", name, "remote_addr", req.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	ch.serveInbound(w, body)
+}
@@ -0,0 +1,258 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+	"github.com/nextlevelbuilder/goclaw/internal/security"
+)
+
+const (
+	testSecret         = "inbound-secret"
+	testCallbackSecret = "callback-secret"
+)
+
+// callbackServer records callback deliveries and replies with queued statuses
+// (200 once the queue is empty).
+type callbackServer struct {
+	*channeltest.Server
+	mu       sync.Mutex
+	statuses []int
+}
+
+func newCallbackServer(t *testing.T, statuses ...int) *callbackServer {
+	t.Helper()
+	security.SetAllowLoopbackForTest(true)
+	t.Cleanup(func() { security.SetAllowLoopbackForTest(false) })
+
+	s := &callbackServer{statuses: statuses}
+	s.Server = channeltest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+// callbackEvent decodes the event a recorded callback carried.
+func callbackEvent(r channeltest.Request) outboundEvent {
+	var ev outboundEvent
+	json.Unmarshal(r.Body, &ev)
+	return ev
+}
+
+func startTestChannel(t *testing.T, name string, cb *callbackServer, cfg webhookInstanceConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	mb := bus.New()
+	cfg.CallbackURL = cb.URL + "/hook?token=abc"
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	if cfg.GroupPolicy == "" {
+		cfg.GroupPolicy = "open"
+	}
+	ch, err := New(cfg, webhookCreds{Secret: testSecret, CallbackSecret: testCallbackSecret}, mb, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetName(name)
+	ch.retryBackoff = time.Millisecond
+	channeltest.Start(t, ch)
+	return ch, mb
+}
+
+// post delivers a signed request to the shared router.
+func post(name, secret string, ts time.Time, payload any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, webhookPathPrefix+name, bytes.NewReader(body))
+	req.Header.Set(headerTimestamp, timestamp)
+	req.Header.Set(headerSignature, sign(secret, timestamp, body))
+	rec := httptest.NewRecorder()
+	globalRouter.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestInbound_SignatureAndDedup(t *testing.T) {
+	cb := newCallbackServer(t)
+	_, mb := startTestChannel(t, "support-app", cb, webhookInstanceConfig{})
+	msg := map[string]any{"id": "m1", "sender_id": "u42", "sender_name": "Ann", "text": "hello"}
+
+	if rec := post("support-app", "wrong", time.Now(), msg); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d", rec.Code)
+	}
+	if rec := post("support-app", testSecret, time.Now().Add(-10*time.Minute), msg); rec.Code != http.StatusUnauthorized {
+		t.Errorf("stale timestamp: status %d", rec.Code)
+	}
+	if rec := post("other-app", testSecret, time.Now(), msg); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown instance: status %d", rec.Code)
+	}
+	if rec := post("support-app", testSecret, time.Now(), map[string]any{"text": "no sender"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing sender_id: status %d", rec.Code)
+	}
+
+	if rec := post("support-app", testSecret, time.Now(), msg); rec.Code != http.StatusAccepted {
+		t.Fatalf("valid request: status %d %s", rec.Code, rec.Body)
+	}
+	if rec := post("support-app", testSecret, time.Now(), msg); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "duplicate") {
+		t.Errorf("duplicate: status %d %s", rec.Code, rec.Body)
+	}
+
+	in := channeltest.Consume(t, mb)
+	if in.SenderID != "u42" || in.ChatID != "u42" || in.PeerKind != "direct" || in.Content != "hello" {
+		t.Errorf("inbound = %+v", in)
+	}
+	if in.Metadata["message_id"] != "m1" || in.Metadata["display_name"] != "Ann" {
+		t.Errorf("metadata = %v", in.Metadata)
+	}
+}
+
+func TestInbound_GroupMentionGatingAndInlineMedia(t *testing.T) {
+	cb := newCallbackServer(t)
+	ch, mb := startTestChannel(t, "team-app", cb, webhookInstanceConfig{})
+
+	post("team-app", testSecret, time.Now(), map[string]any{
+		"sender_id": "u1", "sender_name": "Bo", "chat_id": "room-7", "chat_type": "group", "text": "the build is red",
+	})
+	// Requests are processed asynchronously; wait for the unmentioned one to land in history.
+	channeltest.WaitFor(t, func() bool { return len(ch.GroupHistory().GetEntries("room-7")) > 0 })
+	post("team-app", testSecret, time.Now(), map[string]any{
+		"sender_id": "u2", "sender_name": "Cy", "chat_id": "room-7", "chat_type": "group", "text": "can you look?", "mentioned": true,
+		"media": []map[string]any{{"data": base64.StdEncoding.EncodeToString([]byte("log line")), "filename": "ci.txt", "content_type": "text/plain"}},
+	})
+
+	in := channeltest.Consume(t, mb)
+	if in.ChatID != "room-7" || in.PeerKind != "group" || in.SenderID != "u2" {
+		t.Fatalf("inbound = %+v", in)
+	}
+	if !strings.Contains(in.Content, "the build is red") || !strings.Contains(in.Content, "[From: Cy]") {
+		t.Errorf("content = %q", in.Content)
+	}
+	if len(in.Media) != 1 {
+		t.Fatalf("media = %+v", in.Media)
+	}
+	defer os.Remove(in.Media[0].Path)
+	if data, _ := os.ReadFile(in.Media[0].Path); string(data) != "log line" {
+		t.Errorf("media content = %q", data)
+	}
+}
+
+func TestSend_RetriesWithStableIdempotencyKey(t *testing.T) {
+	cb := newCallbackServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	ch, _ := startTestChannel(t, "retry-app", cb, webhookInstanceConfig{})
+
+	file := filepath.Join(t.TempDir(), "report.csv")
+	os.WriteFile(file, []byte("a,b\n1,2\n"), 0o644)
+
+	err := ch.Send(context.Background(), bus.OutboundMessage{
+		ChatID: "u42", Content: "Here you go",
+		Media: []bus.MediaAttachment{{URL: file, ContentType: "text/csv"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := cb.Requests(http.MethodPost, "/hook")
+	if len(reqs) != 3 {
+		t.Fatalf("callback attempts = %d, want 3", len(reqs))
+	}
+	key := reqs[0].Header.Get(headerIdempotencyKey)
+	for _, r := range reqs {
+		if r.Header.Get(headerIdempotencyKey) != key || callbackEvent(r).ID != key {
+			t.Errorf("idempotency key changed across retries: %q vs %q", r.Header.Get(headerIdempotencyKey), key)
+		}
+		ts := r.Header.Get(headerTimestamp)
+		if !verifySignature(testCallbackSecret, ts, r.Header.Get(headerSignature), r.Body, time.Now()) {
+			t.Error("callback signature does not verify with callback_secret")
+		}
+	}
+	ev := callbackEvent(reqs[2])
+	if ev.Type != eventMessage || ev.ChatID != "u42" || ev.Content != "Here you go" || len(ev.Media) != 1 {
+		t.Fatalf("event = %+v", ev)
+	}
+	if data, _ := base64.StdEncoding.DecodeString(ev.Media[0].Data); string(data) != "a,b\n1,2\n" || ev.Media[0].Filename != "report.csv" {
+		t.Errorf("media = %+v", ev.Media[0])
+	}
+}
+
+func TestSend_ClientErrorIsNotRetried(t *testing.T) {
+	cb := newCallbackServer(t, http.StatusBadRequest)
+	ch, _ := startTestChannel(t, "reject-app", cb, webhookInstanceConfig{})
+
+	err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "u1", Content: "hi"})
+	if err == nil {
+		t.Fatal("expected error on 400")
+	}
+	if f := ch.ClassifySendError(err); !f.Permanent {
+		t.Errorf("ClassifySendError(400) = %+v; want permanent", f)
+	}
+	if n := len(cb.Requests(http.MethodPost, "/hook")); n != 1 {
+		t.Errorf("callback attempts = %d, want 1", n)
+	}
+	if h := ch.HealthSnapshot(); h.Summary != "callback delivery failing" {
+		t.Errorf("health = %+v", h)
+	}
+
+	if err := ch.Send(context.Background(), bus.OutboundMessage{ChatID: "u1", Content: "hi again"}); err != nil {
+		t.Fatal(err)
+	}
+	if h := ch.HealthSnapshot(); h.Summary != "callback delivery recovered" {
+		t.Errorf("health after recovery = %+v", h)
+	}
+}
+
+func TestStream_PartialsThenFinal(t *testing.T) {
+	cb := newCallbackServer(t)
+	ch, _ := startTestChannel(t, "stream-app", cb, webhookInstanceConfig{Stream: new(true)})
+	ctx := context.Background()
+
+	if !ch.StreamEnabled(false) {
+		t.Fatal("stream not enabled")
+	}
+	stream, _ := ch.CreateStream(ctx, "u7", true)
+	stream.Update(ctx, "Think")
+	stream.Update(ctx, "Thinking about") // throttled
+	stream.Stop(ctx)
+	ch.FinalizeStream(ctx, "u7", stream)
+
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "u7", Content: "Thinking about it: yes."}); err != nil {
+		t.Fatal(err)
+	}
+	reqs := cb.Requests(http.MethodPost, "/hook")
+	if len(reqs) != 2 {
+		t.Fatalf("callbacks = %d, want 2", len(reqs))
+	}
+	partial, final := callbackEvent(reqs[0]), callbackEvent(reqs[1])
+	if partial.Type != eventPartial || partial.Content != "Think" || partial.StreamID == "" {
+		t.Errorf("partial = %+v", partial)
+	}
+	if final.Type != eventMessage || final.StreamID != partial.StreamID || partial.ID == final.ID {
+		t.Errorf("final = %+v", final)
+	}
+}
+
+func TestNew_RequiresSecretAndCallbackURL(t *testing.T) {
+	mb := bus.New()
+	if _, err := New(webhookInstanceConfig{CallbackURL: "https://app.example.com/hook"}, webhookCreds{}, mb, nil, nil); err == nil {
+		t.Error("expected error without secret")
+	}
+	if _, err := New(webhookInstanceConfig{CallbackURL: "ftp://app.example.com"}, webhookCreds{Secret: "s"}, mb, nil, nil); err == nil {
+		t.Error("expected error for non-http callback_url")
+	}
+}
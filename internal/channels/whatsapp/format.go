@@ -6,6 +6,12 @@ import (
 	"strings"
 )
 
+// FormatMarkdown converts Markdown to WhatsApp formatting. Exported for the
+// WhatsApp Cloud API channel, which renders the same way.
+func FormatMarkdown(text string) string {
+	return markdownToWhatsApp(text)
+}
+
 // markdownToWhatsApp converts Markdown-formatted LLM output to WhatsApp's native
 // formatting syntax. WhatsApp supports: *bold*, _italic_, ~strikethrough~, ```code```.
 // Unsupported features are simplified: headers → bold, links → "text url", tables → plain.
@@ -68,6 +68,17 @@ func (c *Channel) handleIncomingMessage(evt *events.Message) {
 
 	content := extractTextContent(evt.Message)
 
+	// Shared chat commands (/reset, /tasks, tenant custom commands, ...).
+	if c.HandleCommand(ctx, &channels.CommandRequest{
+		SenderID: senderID,
+		ChatID:   chatID,
+		PeerKind: peerKind,
+		Text:     content,
+		Metadata: map[string]string{"message_id": string(evt.Info.ID)},
+	}) {
+		return
+	}
+
 	var mediaList []media.MediaInfo
 	mediaList = c.downloadMedia(evt)
 
@@ -0,0 +1,67 @@
+package whatsappcloud
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// cloudCreds maps the credentials JSON from the channel_instances table.
+// All values come from the Meta App dashboard (WhatsApp > API Setup and
+// App settings > Basic); access_token should be a System User token.
+type cloudCreds struct {
+	AccessToken string `json:"access_token"`
+	AppSecret   string `json:"app_secret"`
+	VerifyToken string `json:"verify_token"`
+}
+
+// cloudInstanceConfig maps the non-secret config JSONB from the channel_instances table.
+type cloudInstanceConfig struct {
+	PhoneNumberID string   `json:"phone_number_id"`
+	WABAID        string   `json:"waba_id"` // WhatsApp Business Account ID (webhook entry ID)
+	DMPolicy      string   `json:"dm_policy,omitempty"`
+	AllowFrom     []string `json:"allow_from,omitempty"` // phone numbers (wa_id, digits only)
+	// Interactive turns a trailing list of short options after a question
+	// into reply buttons (up to 3) or a list message (up to 10) (default true).
+	Interactive *bool `json:"interactive,omitempty"`
+	// MarkRead sends read receipts and the typing indicator (default true).
+	MarkRead *bool `json:"mark_read,omitempty"`
+	// Template sent when a reply falls outside the 24-hour customer service
+	// window; the reply itself is delivered once the user answers.
+	// TemplateParams fill the body's {{n}} variables; "{name}" and
+	// "{preview}" expand to the contact name and the start of the reply.
+	TemplateName     string   `json:"template_name,omitempty"`
+	TemplateLanguage string   `json:"template_language,omitempty"`
+	TemplateParams   []string `json:"template_params,omitempty"`
+	MediaMaxMB       int      `json:"media_max_mb,omitempty"`
+	BlockReply       *bool    `json:"block_reply,omitempty"`
+}
+
+// Factory creates a WhatsApp Cloud API channel from DB instance data.
+func Factory(name string, creds json.RawMessage, cfg json.RawMessage,
+	msgBus *bus.MessageBus, pairingSvc store.PairingStore) (channels.Channel, error) {
+
+	var c cloudCreds
+	if len(creds) > 0 {
+		if err := json.Unmarshal(creds, &c); err != nil {
+			return nil, fmt.Errorf("decode whatsapp_cloud credentials: %w", err)
+		}
+	}
+
+	var ic cloudInstanceConfig
+	if len(cfg) > 0 {
+		if err := json.Unmarshal(cfg, &ic); err != nil {
+			return nil, fmt.Errorf("decode whatsapp_cloud config: %w", err)
+		}
+	}
+
+	ch, err := New(ic, c, msgBus, pairingSvc)
+	if err != nil {
+		return nil, err
+	}
+	ch.SetName(name)
+	return ch, nil
+}
@@ -0,0 +1,221 @@
+package whatsappcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+)
+
+// handleMessage processes one inbound user message. Cloud API chats are
+// always one-to-one: the chat ID is the user's wa_id (phone number).
+func (c *Channel) handleMessage(ctx context.Context, msg *message) {
+	senderID := msg.From
+	if senderID == "" || msg.Type == "reaction" || msg.Type == "unsupported" || msg.Type == "system" {
+		return
+	}
+
+	// Every user message (re)opens the service window.
+	c.lastInbound.Store(senderID, time.Now())
+	c.flushDeferred(ctx, senderID)
+
+	if !c.checkDMPolicy(ctx, senderID) {
+		return
+	}
+
+	displayName := senderID
+	if v, ok := c.names.Load(senderID); ok {
+		displayName = v.(string)
+	}
+
+	metadata := map[string]string{
+		"message_id":   msg.ID,
+		"user_id":      senderID,
+		"display_name": channels.SanitizeDisplayName(displayName),
+		"is_dm":        "true",
+		"platform":     channels.TypeWhatsAppCloud,
+	}
+	if msg.Context != nil && msg.Context.ID != "" {
+		metadata["reply_to_message_id"] = msg.Context.ID
+	}
+
+	var content string
+	var mediaObj *mediaObject
+	switch msg.Type {
+	case "text":
+		if msg.Text != nil {
+			content = msg.Text.Body
+		}
+	case "interactive":
+		// A tap on a button or list row we sent: the title is what the user "said".
+		if r := msg.Interactive; r != nil {
+			item := r.ButtonReply
+			if item == nil {
+				item = r.ListReply
+			}
+			if item != nil {
+				content = item.Title
+				metadata["wa_reply_id"] = item.ID
+			}
+		}
+	case "button":
+		if msg.Button != nil {
+			content = msg.Button.Text
+			metadata["wa_reply_id"] = msg.Button.Payload
+		}
+	case "image":
+		mediaObj = msg.Image
+	case "audio":
+		mediaObj = msg.Audio
+	case "video":
+		mediaObj = msg.Video
+	case "document":
+		mediaObj = msg.Document
+	case "sticker":
+		mediaObj = msg.Sticker
+		content = "[Sticker]"
+	case "location":
+		if l := msg.Location; l != nil {
+			content = strings.TrimSpace(fmt.Sprintf("[Location] %s %s (%.6f, %.6f)", l.Name, l.Address, l.Latitude, l.Longitude))
+		}
+	case "contacts":
+		content = "[Contact card]"
+	default:
+		slog.Debug("whatsapp_cloud: unhandled message type", "type", msg.Type)
+		return
+	}
+
+	var mediaList []media.MediaInfo
+	if mediaObj != nil {
+		if mediaObj.Caption != "" {
+			content = mediaObj.Caption
+		}
+		if m, err := c.downloadMedia(ctx, msg.Type, mediaObj); err != nil {
+			slog.Warn("whatsapp_cloud: media download failed", "message_id", msg.ID, "type", msg.Type, "error", err)
+		} else {
+			mediaList = append(mediaList, m)
+		}
+	}
+
+	var mediaPaths []string
+	for _, m := range mediaList {
+		mediaPaths = append(mediaPaths, m.FilePath)
+	}
+	if tags := media.BuildMediaTags(mediaList); tags != "" {
+		content = strings.TrimSpace(tags + "\n\n" + content)
+	}
+	if content == "" {
+		return
+	}
+
+	slog.Debug("whatsapp_cloud message received",
+		"sender_id", senderID, "type", msg.Type, "preview", channels.Truncate(content, 50))
+
+	if cc := c.ContactCollector(); cc != nil {
+		cc.EnsureContact(ctx, c.Type(), c.Name(), senderID, senderID, displayName, "", "direct", "user", "", "")
+	}
+
+	c.HandleMessage(senderID, senderID, content, mediaPaths, metadata, "direct")
+}
+
+// downloadMedia resolves a media ID to its (short-lived) URL and saves the
+// file to a temp file.
+func (c *Channel) downloadMedia(ctx context.Context, kind string, obj *mediaObject) (media.MediaInfo, error) {
+	if obj.ID == "" {
+		return media.MediaInfo{}, fmt.Errorf("missing media id")
+	}
+	data, err := c.graph.Do(ctx, http.MethodGet, "/"+obj.ID+"?phone_number_id="+c.phoneNumberID, nil)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+	var meta struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+		FileSize int64  `json:"file_size"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return media.MediaInfo{}, fmt.Errorf("parse media url: %w", err)
+	}
+	if meta.URL == "" {
+		return media.MediaInfo{}, fmt.Errorf("media url missing")
+	}
+	if meta.FileSize > c.maxMedia {
+		return media.MediaInfo{}, fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	body, _, err := c.graph.Download(ctx, meta.URL, c.maxMedia)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+
+	mimeType := obj.MimeType
+	if mimeType == "" {
+		mimeType = meta.MimeType
+	}
+	mimeType, _, _ = mime.ParseMediaType(mimeType) // drop "; codecs=opus"
+	ext := filepath.Ext(obj.Filename)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	tmp, err := os.CreateTemp("", "goclaw_wacloud_*"+ext)
+	if err != nil {
+		return media.MediaInfo{}, err
+	}
+	defer tmp.Close()
+	if _, err := tmp.Write(body); err != nil {
+		os.Remove(tmp.Name())
+		return media.MediaInfo{}, err
+	}
+
+	mediaType := media.MediaKindFromMime(mimeType)
+	if kind == "audio" && obj.Voice {
+		mediaType = media.TypeVoice
+	}
+	return media.MediaInfo{
+		Type:        mediaType,
+		FilePath:    tmp.Name(),
+		ContentType: mimeType,
+		FileName:    obj.Filename,
+	}, nil
+}
+
+// --- Policy checks ---
+
+func (c *Channel) checkDMPolicy(ctx context.Context, senderID string) bool {
+	switch c.CheckDMPolicy(ctx, senderID, c.config.DMPolicy) {
+	case channels.PolicyAllow:
+		return true
+	case channels.PolicyNeedsPairing:
+		c.sendPairingReply(ctx, senderID)
+	}
+	return false
+}
+
+func (c *Channel) sendPairingReply(ctx context.Context, senderID string) {
+	ps := c.PairingService()
+	if ps == nil || !c.CanSendPairingNotif(senderID, pairingDebounceTime) {
+		return
+	}
+	code, err := ps.RequestPairing(ctx, senderID, c.Name(), senderID, "default", nil)
+	if err != nil {
+		slog.Warn("whatsapp_cloud: failed to request pairing code", "error", err)
+		return
+	}
+	msg := fmt.Sprintf("GoClaw: access not configured.\n\nYour WhatsApp number: %s\n\nPairing code: %s\n\nAsk the bot owner to approve with:\n  goclaw pairing approve %s",
+		senderID, code, code)
+	if _, err := c.post(ctx, textRequest(senderID, msg)); err != nil {
+		slog.Warn("whatsapp_cloud: failed to send pairing reply", "chat_id", senderID, "error", err)
+		return
+	}
+	c.MarkPairingNotifSent(senderID)
+}
@@ -0,0 +1,100 @@
+package whatsappcloud
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nextlevelbuilder/goclaw/internal/channels/whatsapp"
+)
+
+// Interactive message limits.
+const (
+	maxButtons        = 3
+	maxButtonTitle    = 20
+	maxListRows       = 10
+	maxListRowTitle   = 24
+	maxInteractiveLen = 1024 // body text
+	listMenuLabel     = "Choose"
+)
+
+var (
+	optionRe     = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s+(.+?)\s*$`)
+	inlineMarkRe = regexp.MustCompile("\\*\\*|__|`")
+)
+
+// splitOptions separates a trailing list of options after a question, e.g.
+//
+//	Which plan?
+//	1. Basic
+//	2. Pro
+//
+// returns ("Which plan?", ["Basic", "Pro"]). ok is false when the text does
+// not end that way or an option is too long to be a list row.
+func splitOptions(text string) (body string, opts []string, ok bool) {
+	lines := strings.Split(strings.TrimRight(text, "\n "), "\n")
+	i := len(lines) - 1
+	for ; i >= 0; i-- {
+		m := optionRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			break
+		}
+		opt := strings.TrimSpace(inlineMarkRe.ReplaceAllString(m[1], ""))
+		if opt == "" || utf8.RuneCountInString(opt) > maxListRowTitle {
+			return "", nil, false
+		}
+		opts = append([]string{opt}, opts...)
+	}
+	if len(opts) < 2 || len(opts) > maxListRows || i < 0 {
+		return "", nil, false
+	}
+	question := strings.TrimSpace(lines[i])
+	if !strings.HasSuffix(question, "?") && !strings.HasSuffix(question, "？") {
+		return "", nil, false
+	}
+	return strings.TrimSpace(strings.Join(lines[:i+1], "\n")), opts, true
+}
+
+// buildInteractive renders a reply ending in options as reply buttons (up to
+// three short options) or a list message. ok is false when the reply does
+// not fit an interactive message; it is then sent as text.
+func buildInteractive(chatID, content string) (sendRequest, bool) {
+	body, opts, ok := splitOptions(content)
+	if !ok {
+		return sendRequest{}, false
+	}
+	body = whatsapp.FormatMarkdown(body)
+	if utf8.RuneCountInString(body) > maxInteractiveLen {
+		return sendRequest{}, false
+	}
+
+	ia := &interactive{Body: textOnly{Text: body}}
+	if len(opts) <= maxButtons && allShorter(opts, maxButtonTitle) {
+		ia.Type = "button"
+		for i, o := range opts {
+			ia.Action.Buttons = append(ia.Action.Buttons, replyButton{
+				Type:  "reply",
+				Reply: replyItem{ID: fmt.Sprintf("opt_%d", i+1), Title: o},
+			})
+		}
+	} else {
+		ia.Type = "list"
+		ia.Action.Button = listMenuLabel
+		section := listSection{}
+		for i, o := range opts {
+			section.Rows = append(section.Rows, listRow{ID: fmt.Sprintf("opt_%d", i+1), Title: o})
+		}
+		ia.Action.Sections = []listSection{section}
+	}
+	return sendRequest{MessagingProduct: "whatsapp", To: chatID, Type: "interactive", Interactive: ia}, true
+}
+
+func allShorter(opts []string, n int) bool {
+	for _, o := range opts {
+		if utf8.RuneCountInString(o) > n {
+			return false
+		}
+	}
+	return true
+}
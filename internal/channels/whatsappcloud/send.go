@@ -0,0 +1,329 @@
+package whatsappcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/media"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/whatsapp"
+)
+
+const (
+	maxTextLen      = 4096 // text message body limit
+	maxCaptionLen   = 1024
+	maxTemplateText = 60 // "{preview}" length in template parameters
+	typingRefresh   = 20 * time.Second
+)
+
+// SkipPlaceholderUpdates implements channels.PlaceholderUpdateSkipper.
+func (c *Channel) SkipPlaceholderUpdates() bool { return true }
+
+// Send delivers an outbound message. Replies are held back (and announced
+// with the configured template) when the user's service window is closed.
+func (c *Channel) Send(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("whatsapp_cloud channel not running")
+	}
+	chatID := msg.ChatID
+	if chatID == "" {
+		return fmt.Errorf("empty chat ID for whatsapp_cloud send")
+	}
+	content := msg.Content
+	var reqs []sendRequest
+	for _, m := range msg.Media {
+		req, err := c.uploadMedia(ctx, chatID, m)
+		if err != nil {
+			slog.Warn("whatsapp_cloud: media upload failed", "file", m.URL, "error", err)
+			content = fmt.Sprintf("%s\n\n[File upload failed: %s]", content, filepath.Base(m.URL))
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	reqs = append(reqs, c.textRequests(chatID, content)...)
+	if len(reqs) == 0 {
+		return nil // NO_REPLY
+	}
+
+	if !c.windowOpen(chatID) {
+		return c.deferReplies(ctx, chatID, reqs)
+	}
+	return c.deliver(ctx, chatID, reqs)
+}
+
+// textRequests renders reply text as text messages, or as a single
+// interactive message when it ends with a question and short options.
+func (c *Channel) textRequests(chatID, content string) []sendRequest {
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+	if c.interactive {
+		if req, ok := buildInteractive(chatID, content); ok {
+			return []sendRequest{req}
+		}
+	}
+	var reqs []sendRequest
+	for _, chunk := range channels.ChunkMarkdown(whatsapp.FormatMarkdown(content), maxTextLen) {
+		reqs = append(reqs, textRequest(chatID, chunk))
+	}
+	return reqs
+}
+
+func textRequest(to, body string) sendRequest {
+	return sendRequest{
+		MessagingProduct: "whatsapp",
+		To:               to,
+		Type:             "text",
+		Text:             &textBody{Body: body, PreviewURL: strings.Contains(body, "https://")},
+	}
+}
+
+// deliver sends messages in order. If Meta reports the window closed part
+// way through, the remaining messages are deferred.
+func (c *Channel) deliver(ctx context.Context, chatID string, reqs []sendRequest) error {
+	for i, req := range reqs {
+		id, err := c.post(ctx, req)
+		if err != nil {
+			if facebook.ErrorCode(err) == errCodeReengagement {
+				c.lastInbound.Store(chatID, time.Now().Add(-serviceWindow))
+				return c.deferReplies(ctx, chatID, reqs[i:])
+			}
+			c.handleAPIError(err)
+			return fmt.Errorf("send whatsapp_cloud message: %w", err)
+		}
+		c.trackSent(id, chatID, req)
+	}
+	return nil
+}
+
+// ClassifySendError implements channels.SendErrorClassifier. Throughput and
+// quality limits apply to the phone number, so they pause the channel, while
+// the pair rate limit only backs off the one recipient. Permission errors
+// mean the app lost access to the number. Other Graph errors follow
+// facebook.ClassifySendError.
+func (c *Channel) ClassifySendError(err error) channels.SendFailure {
+	switch facebook.ErrorCode(err) {
+	case errCodeThroughput, errCodeSpamLimit:
+		return channels.SendFailure{ChannelWide: true}
+	case errCodePairLimit:
+		return channels.SendFailure{}
+	case errCodeUndeliverable, errCodeNotAllowed, errCodeUnsupported:
+		return channels.SendFailure{Permanent: true}
+	}
+	if facebook.IsPermissionError(err) {
+		return channels.SendFailure{ChannelWide: true}
+	}
+	return facebook.ClassifySendError(err)
+}
+
+// post sends one message and returns its ID.
+func (c *Channel) post(ctx context.Context, req sendRequest) (string, error) {
+	data, err := c.graph.Do(ctx, http.MethodPost, "/"+c.phoneNumberID+"/messages", req)
+	if err != nil {
+		return "", err
+	}
+	var resp sendResponse
+	if err := json.Unmarshal(data, &resp); err != nil || len(resp.Messages) == 0 {
+		return "", nil
+	}
+	return resp.Messages[0].ID, nil
+}
+
+// trackSent remembers a sent message so a later "failed" status can still
+// defer it (Meta often reports window errors asynchronously).
+func (c *Channel) trackSent(id, chatID string, req sendRequest) {
+	if id == "" {
+		return
+	}
+	c.sent.Store(id, sentMessage{chatID: chatID, req: req})
+	time.AfterFunc(sentTrackTTL, func() { c.sent.Delete(id) })
+}
+
+// --- Customer service window ---
+
+// windowOpen reports whether free-form messages can be sent. Users not seen
+// since startup are assumed open; Meta's error then settles it.
+func (c *Channel) windowOpen(chatID string) bool {
+	v, ok := c.lastInbound.Load(chatID)
+	return !ok || time.Since(v.(time.Time)) < serviceWindow
+}
+
+// deferReplies queues messages until the user writes again and sends the
+// re-engagement template once per closed window.
+func (c *Channel) deferReplies(ctx context.Context, chatID string, reqs []sendRequest) error {
+	if c.config.TemplateName == "" {
+		return fmt.Errorf("whatsapp_cloud: %s is outside the 24h customer service window and no template_name is configured", chatID)
+	}
+	c.deferredMu.Lock()
+	first := len(c.deferred[chatID]) == 0
+	q := append(c.deferred[chatID], reqs...)
+	if len(q) > maxDeferred {
+		q = q[len(q)-maxDeferred:]
+	}
+	c.deferred[chatID] = q
+	c.deferredMu.Unlock()
+
+	if !first {
+		return nil
+	}
+	id, err := c.post(ctx, c.templateRequest(chatID, previewText(reqs)))
+	if err != nil {
+		c.handleAPIError(err)
+		return fmt.Errorf("send whatsapp_cloud template: %w", err)
+	}
+	slog.Info("whatsapp_cloud: reply deferred, template sent", "chat_id", chatID, "template", c.config.TemplateName)
+	c.trackSent(id, chatID, sendRequest{})
+	return nil
+}
+
+// flushDeferred delivers replies held for a user who reopened the window.
+func (c *Channel) flushDeferred(ctx context.Context, chatID string) {
+	c.deferredMu.Lock()
+	q := c.deferred[chatID]
+	delete(c.deferred, chatID)
+	c.deferredMu.Unlock()
+	if len(q) == 0 {
+		return
+	}
+	if err := c.deliver(ctx, chatID, q); err != nil {
+		slog.Warn("whatsapp_cloud: deferred reply delivery failed", "chat_id", chatID, "error", err)
+	}
+}
+
+func (c *Channel) templateRequest(chatID, preview string) sendRequest {
+	t := &templateBody{Name: c.config.TemplateName}
+	t.Language.Code = c.config.TemplateLanguage
+	if len(c.config.TemplateParams) > 0 {
+		name := chatID
+		if v, ok := c.names.Load(chatID); ok {
+			name = v.(string)
+		}
+		comp := templateComponent{Type: "body"}
+		for _, p := range c.config.TemplateParams {
+			p = strings.ReplaceAll(p, "{name}", name)
+			p = strings.ReplaceAll(p, "{preview}", preview)
+			comp.Parameters = append(comp.Parameters, templateParam{Type: "text", Text: p})
+		}
+		t.Components = []templateComponent{comp}
+	}
+	return sendRequest{MessagingProduct: "whatsapp", To: chatID, Type: "template", Template: t}
+}
+
+// previewText is the start of the first text in reqs, on one line
+// (template parameters may not contain newlines).
+func previewText(reqs []sendRequest) string {
+	for _, r := range reqs {
+		var s string
+		switch {
+		case r.Text != nil:
+			s = r.Text.Body
+		case r.Interactive != nil:
+			s = r.Interactive.Body.Text
+		default:
+			continue
+		}
+		s = strings.Join(strings.Fields(s), " ")
+		if r := []rune(s); len(r) > maxTemplateText {
+			s = string(r[:maxTemplateText-1]) + "…"
+		}
+		return s
+	}
+	return ""
+}
+
+// --- Media ---
+
+// uploadMedia uploads a local file and returns the message that sends it.
+func (c *Channel) uploadMedia(ctx context.Context, chatID string, m bus.MediaAttachment) (sendRequest, error) {
+	f, err := os.Open(m.URL)
+	if err != nil {
+		return sendRequest{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return sendRequest{}, err
+	}
+	if info.Size() > c.maxMedia {
+		return sendRequest{}, fmt.Errorf("file exceeds %d MB limit", c.config.MediaMaxMB)
+	}
+	contentType := m.ContentType
+	if contentType == "" {
+		contentType = media.DetectMIMEType(m.URL)
+	}
+	filename := filepath.Base(m.URL)
+
+	data, err := c.graph.Upload(ctx, "/"+c.phoneNumberID+"/media",
+		map[string]string{"messaging_product": "whatsapp", "type": contentType},
+		"file", filename, contentType, f)
+	if err != nil {
+		c.handleAPIError(err)
+		return sendRequest{}, err
+	}
+	var up struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &up); err != nil || up.ID == "" {
+		return sendRequest{}, fmt.Errorf("upload returned no media id")
+	}
+
+	caption := m.Caption
+	if r := []rune(caption); len(r) > maxCaptionLen {
+		caption = string(r[:maxCaptionLen])
+	}
+	req := sendRequest{MessagingProduct: "whatsapp", To: chatID}
+	ref := &mediaRef{ID: up.ID, Caption: caption}
+	switch {
+	case contentType == "image/jpeg" || contentType == "image/png":
+		req.Type, req.Image = "image", ref
+	case contentType == "video/mp4" || contentType == "video/3gpp":
+		req.Type, req.Video = "video", ref
+	case strings.HasPrefix(contentType, "audio/"):
+		ref.Caption = "" // audio messages have no caption
+		req.Type, req.Audio = "audio", ref
+	default:
+		ref.Filename = filename
+		req.Type, req.Document = "document", ref
+	}
+	return req, nil
+}
+
+// --- Read receipts & typing indicator ---
+
+// OnReactionEvent marks the user's message as read and shows the typing
+// indicator while the agent works. WhatsApp dismisses it after 25 seconds
+// or when the reply arrives.
+func (c *Channel) OnReactionEvent(ctx context.Context, _ string, messageID string, status string) error {
+	if !c.markRead || messageID == "" || status == "done" || status == "error" {
+		return nil
+	}
+	if v, ok := c.typingAt.Load(messageID); ok && time.Since(v.(time.Time)) < typingRefresh {
+		return nil
+	}
+	c.typingAt.Store(messageID, time.Now())
+	body := map[string]any{
+		"messaging_product": "whatsapp",
+		"status":            "read",
+		"message_id":        messageID,
+		"typing_indicator":  map[string]string{"type": "text"},
+	}
+	if _, err := c.graph.Do(ctx, http.MethodPost, "/"+c.phoneNumberID+"/messages", body); err != nil {
+		slog.Debug("whatsapp_cloud: typing indicator failed", "message_id", messageID, "error", err)
+	}
+	return nil
+}
+
+// ClearReaction forgets the typing throttle; the indicator ends with the reply.
+func (c *Channel) ClearReaction(_ context.Context, _ string, messageID string) error {
+	c.typingAt.Delete(messageID)
+	return nil
+}
@@ -0,0 +1,55 @@
+package whatsappcloud
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// handleStatus processes a delivery status callback (sent, delivered, read,
+// failed) for a message this channel sent. Each status is broadcast as a
+// channel.message.status event; failures caused by a closed service window
+// defer the message until the user writes again.
+func (c *Channel) handleStatus(ctx context.Context, st *messageStatus) {
+	ev := MessageStatus{
+		Channel:   c.Name(),
+		ChatID:    st.RecipientID,
+		MessageID: st.ID,
+		Status:    st.Status,
+	}
+	ev.Timestamp, _ = strconv.ParseInt(st.Timestamp, 10, 64)
+	if len(st.Errors) > 0 {
+		ev.ErrorCode = st.Errors[0].Code
+		ev.Error = st.Errors[0].Title
+		if d := st.Errors[0].ErrorData.Details; d != "" {
+			ev.Error += ": " + d
+		}
+	}
+	if b := c.Bus(); b != nil {
+		b.Broadcast(bus.Event{Name: protocol.EventChannelMessageStatus, Payload: ev, TenantID: c.TenantID()})
+	}
+
+	switch st.Status {
+	case "read":
+		c.sent.Delete(st.ID)
+	case "failed":
+		v, tracked := c.sent.LoadAndDelete(st.ID)
+		slog.Warn("whatsapp_cloud: message delivery failed",
+			"message_id", st.ID, "chat_id", st.RecipientID, "code", ev.ErrorCode, "error", ev.Error)
+		if !tracked || ev.ErrorCode != errCodeReengagement {
+			return
+		}
+		sm := v.(sentMessage)
+		c.lastInbound.Store(sm.chatID, time.Now().Add(-serviceWindow))
+		if sm.req.Type == "" || sm.req.Type == "template" {
+			return // the template itself failed; nothing to retry
+		}
+		if err := c.deferReplies(ctx, sm.chatID, []sendRequest{sm.req}); err != nil {
+			slog.Warn("whatsapp_cloud: could not defer reply", "chat_id", sm.chatID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package whatsappcloud
+
+// --- Webhook payloads (field "messages" of a whatsapp_business_account entry) ---
+
+// changeValue is the value of a "messages" change.
+type changeValue struct {
+	MessagingProduct string `json:"messaging_product"`
+	Metadata         struct {
+		DisplayPhoneNumber string `json:"display_phone_number"`
+		PhoneNumberID      string `json:"phone_number_id"`
+	} `json:"metadata"`
+	Contacts []contact       `json:"contacts,omitempty"`
+	Messages []message       `json:"messages,omitempty"`
+	Statuses []messageStatus `json:"statuses,omitempty"`
+}
+
+type contact struct {
+	Profile struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	WaID string `json:"wa_id"`
+}
+
+// message is an inbound user message.
+type message struct {
+	From        string             `json:"from"`
+	ID          string             `json:"id"`
+	Timestamp   string             `json:"timestamp"`
+	Type        string             `json:"type"` // text, image, audio, video, document, sticker, location, contacts, interactive, button, reaction, ...
+	Context     *messageContext    `json:"context,omitempty"`
+	Text        *textBody          `json:"text,omitempty"`
+	Image       *mediaObject       `json:"image,omitempty"`
+	Audio       *mediaObject       `json:"audio,omitempty"`
+	Video       *mediaObject       `json:"video,omitempty"`
+	Document    *mediaObject       `json:"document,omitempty"`
+	Sticker     *mediaObject       `json:"sticker,omitempty"`
+	Location    *location          `json:"location,omitempty"`
+	Interactive *interactiveReply  `json:"interactive,omitempty"`
+	Button      *templateButtonHit `json:"button,omitempty"`
+}
+
+// messageContext is set when the user replies to (or taps a button on) an earlier message.
+type messageContext struct {
+	From string `json:"from"`
+	ID   string `json:"id"`
+}
+
+type mediaObject struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Voice    bool   `json:"voice,omitempty"`
+}
+
+type location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// interactiveReply is a tap on a reply button or list row we sent.
+type interactiveReply struct {
+	Type        string     `json:"type"` // button_reply, list_reply
+	ButtonReply *replyItem `json:"button_reply,omitempty"`
+	ListReply   *replyItem `json:"list_reply,omitempty"`
+}
+
+type replyItem struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// templateButtonHit is a tap on a quick reply button of a template message.
+type templateButtonHit struct {
+	Text    string `json:"text"`
+	Payload string `json:"payload"`
+}
+
+// messageStatus is a delivery status update for a message we sent.
+type messageStatus struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"` // sent, delivered, read, failed
+	Timestamp   string     `json:"timestamp"`
+	RecipientID string     `json:"recipient_id"`
+	Errors      []apiError `json:"errors,omitempty"`
+}
+
+type apiError struct {
+	Code      int    `json:"code"`
+	Title     string `json:"title"`
+	Message   string `json:"message,omitempty"`
+	ErrorData struct {
+		Details string `json:"details"`
+	} `json:"error_data"`
+}
+
+// --- Send payloads (POST /{phone_number_id}/messages) ---
+
+type sendRequest struct {
+	MessagingProduct string        `json:"messaging_product"` // always "whatsapp"
+	To               string        `json:"to"`
+	Type             string        `json:"type"`
+	Text             *textBody     `json:"text,omitempty"`
+	Image            *mediaRef     `json:"image,omitempty"`
+	Audio            *mediaRef     `json:"audio,omitempty"`
+	Video            *mediaRef     `json:"video,omitempty"`
+	Document         *mediaRef     `json:"document,omitempty"`
+	Interactive      *interactive  `json:"interactive,omitempty"`
+	Template         *templateBody `json:"template,omitempty"`
+}
+
+type textBody struct {
+	Body       string `json:"body"`
+	PreviewURL bool   `json:"preview_url,omitempty"`
+}
+
+type mediaRef struct {
+	ID       string `json:"id"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type interactive struct {
+	Type   string            `json:"type"` // button, list
+	Body   textOnly          `json:"body"`
+	Action interactiveAction `json:"action"`
+}
+
+type textOnly struct {
+	Text string `json:"text"`
+}
+
+type interactiveAction struct {
+	Buttons  []replyButton `json:"buttons,omitempty"`
+	Button   string        `json:"button,omitempty"` // list menu label
+	Sections []listSection `json:"sections,omitempty"`
+}
+
+type replyButton struct {
+	Type  string    `json:"type"` // "reply"
+	Reply replyItem `json:"reply"`
+}
+
+type listSection struct {
+	Rows []listRow `json:"rows"`
+}
+
+type listRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+type templateBody struct {
+	Name     string `json:"name"`
+	Language struct {
+		Code string `json:"code"`
+	} `json:"language"`
+	Components []templateComponent `json:"components,omitempty"`
+}
+
+type templateComponent struct {
+	Type       string          `json:"type"` // "body"
+	Parameters []templateParam `json:"parameters"`
+}
+
+type templateParam struct {
+	Type string `json:"type"` // "text"
+	Text string `json:"text"`
+}
+
+type sendResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+// MessageStatus is the payload of protocol.EventChannelMessageStatus: a
+// delivery status callback for a message sent by a WhatsApp Cloud channel.
+type MessageStatus struct {
+	Channel   string `json:"channel"`
+	ChatID    string `json:"chatId"`
+	MessageID string `json:"messageId"`
+	Status    string `json:"status"` // sent, delivered, read, failed
+	Timestamp int64  `json:"timestamp"`
+	ErrorCode int    `json:"errorCode,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,265 @@
+// Package whatsappcloud implements the WhatsApp Cloud API (WhatsApp Business
+// Platform) channel. Webhook deliveries arrive on the facebook package's
+// shared Meta webhook endpoint and replies go through its Graph client.
+// Replies outside the 24-hour customer service window are announced with an
+// approved template and delivered once the user answers.
+package whatsappcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	// webhookObject is the Meta webhook object type for WhatsApp Business accounts.
+	webhookObject       = "whatsapp_business_account"
+	pairingDebounceTime = 60 * time.Second
+	defaultMediaMaxMB   = 16
+	eventDedupTTL       = 24 * time.Hour // Meta retries failed deliveries for up to a day
+	// serviceWindow is the customer service window: free-form messages are
+	// only accepted within 24 hours of the user's last message.
+	serviceWindow = 24 * time.Hour
+	// sentTrackTTL is how long sent messages are remembered for status callbacks.
+	sentTrackTTL = time.Hour
+	maxDeferred  = 10
+
+	// Graph API error codes.
+	errCodeReengagement  = 131047 // more than 24 hours since the user's last message
+	errCodeThroughput    = 130429 // phone number's messages-per-second limit
+	errCodeSpamLimit     = 131048 // number restricted for low quality
+	errCodePairLimit     = 131056 // too many messages to one recipient
+	errCodeUndeliverable = 131026 // number not on WhatsApp or has not accepted the terms
+	errCodeNotAllowed    = 131030 // recipient not on the test number's allow list
+	errCodeUnsupported   = 131051 // message type not supported
+)
+
+// Channel receives WhatsApp Cloud API webhooks and sends via the Graph API.
+type Channel struct {
+	*channels.BaseChannel
+	config        cloudInstanceConfig
+	graph         *facebook.GraphClient
+	appSecret     string
+	verifyToken   string
+	phoneNumberID string
+	wabaID        string
+	maxMedia      int64
+	interactive   bool
+	markRead      bool
+
+	seen        sync.Map // message ID -> struct{}
+	lastInbound sync.Map // wa_id -> time.Time
+	names       sync.Map // wa_id -> profile name
+	sent        sync.Map // sent message ID -> sentMessage
+	typingAt    sync.Map // inbound message ID -> time.Time of last typing indicator
+
+	// deferred holds replies waiting for the user to reopen the service window.
+	deferredMu sync.Mutex
+	deferred   map[string][]sendRequest // wa_id -> queued messages
+
+	runCtx   context.Context
+	cancelFn context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// sentMessage is a sent message kept for its delivery status callbacks.
+type sentMessage struct {
+	chatID string
+	req    sendRequest
+}
+
+// Compile-time interface assertions.
+var _ channels.Channel = (*Channel)(nil)
+var _ channels.WebhookChannel = (*Channel)(nil)
+var _ channels.ReactionChannel = (*Channel)(nil)
+var _ channels.BlockReplyChannel = (*Channel)(nil)
+var _ facebook.WebhookSubscriber = (*Channel)(nil)
+
+// New creates a new WhatsApp Cloud API channel from instance config.
+func New(cfg cloudInstanceConfig, creds cloudCreds, msgBus *bus.MessageBus,
+	pairingSvc store.PairingStore) (*Channel, error) {
+
+	if creds.AccessToken == "" {
+		return nil, fmt.Errorf("whatsapp_cloud: access_token is required")
+	}
+	if creds.AppSecret == "" || creds.VerifyToken == "" {
+		return nil, fmt.Errorf("whatsapp_cloud: app_secret and verify_token are required")
+	}
+	if cfg.PhoneNumberID == "" || cfg.WABAID == "" {
+		return nil, fmt.Errorf("whatsapp_cloud: phone_number_id and waba_id are required")
+	}
+
+	base := channels.NewBaseChannel(channels.TypeWhatsAppCloud, msgBus, cfg.AllowFrom)
+	base.ValidatePolicy(cfg.DMPolicy, "")
+
+	if cfg.MediaMaxMB <= 0 {
+		cfg.MediaMaxMB = defaultMediaMaxMB
+	}
+	if cfg.TemplateName != "" && cfg.TemplateLanguage == "" {
+		cfg.TemplateLanguage = "en_US"
+	}
+
+	ch := &Channel{
+		BaseChannel:   base,
+		config:        cfg,
+		graph:         facebook.NewGraphClient(creds.AccessToken, cfg.PhoneNumberID),
+		appSecret:     creds.AppSecret,
+		verifyToken:   creds.VerifyToken,
+		phoneNumberID: cfg.PhoneNumberID,
+		wabaID:        cfg.WABAID,
+		maxMedia:      int64(cfg.MediaMaxMB) * 1024 * 1024,
+		interactive:   cfg.Interactive == nil || *cfg.Interactive,
+		markRead:      cfg.MarkRead == nil || *cfg.MarkRead,
+		deferred:      make(map[string][]sendRequest),
+	}
+	ch.SetPairingService(pairingSvc)
+	return ch, nil
+}
+
+// BlockReplyEnabled returns the per-channel block_reply override (nil = inherit gateway default).
+func (c *Channel) BlockReplyEnabled() *bool { return c.config.BlockReply }
+
+// Start verifies the access token against the phone number, subscribes the
+// app to the business account's webhooks and starts accepting deliveries.
+func (c *Channel) Start(ctx context.Context) error {
+	c.MarkStarting("verifying WhatsApp phone number")
+
+	data, err := c.graph.Do(ctx, http.MethodGet, "/"+c.phoneNumberID+"?fields=display_phone_number,verified_name,quality_rating", nil)
+	if err != nil {
+		kind := channels.ChannelFailureKindNetwork
+		if facebook.IsAuthError(err) || facebook.IsPermissionError(err) {
+			kind = channels.ChannelFailureKindAuth
+		}
+		c.MarkFailed("whatsapp cloud authentication failed", err.Error(), kind, kind == channels.ChannelFailureKindNetwork)
+		return fmt.Errorf("whatsapp_cloud: verify phone number: %w", err)
+	}
+	var info struct {
+		DisplayPhoneNumber string `json:"display_phone_number"`
+		VerifiedName       string `json:"verified_name"`
+		QualityRating      string `json:"quality_rating"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return fmt.Errorf("whatsapp_cloud: parse phone number: %w", err)
+	}
+
+	// Best-effort: the app may already be subscribed from the dashboard.
+	if _, err := c.graph.Do(ctx, http.MethodPost, "/"+c.wabaID+"/subscribed_apps", nil); err != nil {
+		slog.Warn("whatsapp_cloud: webhook subscription failed (check app permissions on the WABA)", "err", err)
+	}
+
+	c.runCtx, c.cancelFn = context.WithCancel(context.Background())
+	facebook.RegisterSubscriber(webhookObject, c.wabaID, c)
+	c.SetRunning(true)
+
+	if info.QualityRating == "RED" {
+		c.MarkDegraded("low phone number quality rating",
+			"Meta rates this number's quality RED; messaging limits may be lowered.",
+			channels.ChannelFailureKindQuota, true)
+	} else {
+		c.MarkHealthy("connected as " + info.VerifiedName + " (" + info.DisplayPhoneNumber + ")")
+	}
+	slog.Info("whatsapp_cloud channel started", "name", c.Name(), "phone", info.DisplayPhoneNumber, "verified_name", info.VerifiedName)
+	return nil
+}
+
+// Stop stops accepting webhook deliveries and waits for in-flight handlers.
+func (c *Channel) Stop(_ context.Context) error {
+	facebook.UnregisterSubscriber(webhookObject, c.wabaID, c)
+	if c.cancelFn != nil {
+		c.cancelFn()
+	}
+	c.wg.Wait()
+	c.SetRunning(false)
+	c.MarkStopped("stopped")
+	slog.Info("whatsapp_cloud channel stopped", "name", c.Name())
+	return nil
+}
+
+// WebhookHandler returns the shared Meta webhook route. Whichever facebook or
+// whatsapp_cloud instance asks first mounts it; others return ("", nil).
+func (c *Channel) WebhookHandler() (string, http.Handler) {
+	return facebook.WebhookRoute()
+}
+
+// WebhookSecrets implements facebook.WebhookSubscriber.
+func (c *Channel) WebhookSecrets() (string, string) {
+	return c.appSecret, c.verifyToken
+}
+
+// HandleWebhookEntry implements facebook.WebhookSubscriber. Events are
+// processed asynchronously; Meta expects a quick 200.
+func (c *Channel) HandleWebhookEntry(entry facebook.RawEntry) {
+	if !c.IsRunning() {
+		return
+	}
+	for _, change := range entry.Changes {
+		if change.Field != "messages" {
+			continue
+		}
+		var v changeValue
+		if err := json.Unmarshal(change.Value, &v); err != nil {
+			slog.Warn("whatsapp_cloud: webhook parse error", "err", err)
+			continue
+		}
+		// One business account can hold several numbers; each is its own channel.
+		if v.Metadata.PhoneNumberID != c.phoneNumberID {
+			continue
+		}
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.handleValue(c.runCtx, &v)
+		}()
+	}
+}
+
+// handleValue processes the contacts, messages and statuses of one change.
+func (c *Channel) handleValue(ctx context.Context, v *changeValue) {
+	for _, ct := range v.Contacts {
+		if ct.WaID != "" && ct.Profile.Name != "" {
+			c.names.Store(ct.WaID, ct.Profile.Name)
+		}
+	}
+	for i := range v.Statuses {
+		c.handleStatus(ctx, &v.Statuses[i])
+	}
+	for i := range v.Messages {
+		if c.isDuplicate(v.Messages[i].ID) {
+			continue
+		}
+		c.handleMessage(ctx, &v.Messages[i])
+	}
+}
+
+// isDuplicate returns true if the message was already processed (redeliveries).
+func (c *Channel) isDuplicate(id string) bool {
+	if id == "" {
+		return false
+	}
+	if _, loaded := c.seen.LoadOrStore(id, struct{}{}); loaded {
+		return true
+	}
+	time.AfterFunc(eventDedupTTL, func() { c.seen.Delete(id) })
+	return false
+}
+
+// handleAPIError maps Graph API errors to channel health states.
+func (c *Channel) handleAPIError(err error) {
+	switch {
+	case facebook.IsAuthError(err):
+		c.MarkFailed("access token expired", err.Error(), channels.ChannelFailureKindAuth, false)
+	case facebook.IsPermissionError(err):
+		c.MarkFailed("permission denied", err.Error(), channels.ChannelFailureKindAuth, false)
+	case facebook.IsRateLimitError(err):
+		c.MarkDegraded("rate limited", err.Error(), channels.ChannelFailureKindQuota, true)
+	}
+}
@@ -0,0 +1,306 @@
+package whatsappcloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/channeltest"
+	"github.com/nextlevelbuilder/goclaw/internal/channels/facebook"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const (
+	testPhoneID   = "1055"
+	testWABA      = "2077"
+	testAppSecret = "app-secret"
+)
+
+// fakeGraph is a minimal Graph API stub recording message sends.
+// Sends to closedUser fail with the re-engagement error.
+type fakeGraph struct {
+	*channeltest.Server
+	mu    sync.Mutex
+	sends []sendRequest
+	seq   int
+}
+
+const closedUser = "15550000000"
+
+func newFakeGraph(t *testing.T) *fakeGraph {
+	t.Helper()
+	g := &fakeGraph{}
+	g.Server = channeltest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":{"message":"Invalid OAuth access token","code":190}}`)
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/"+testPhoneID) && r.Method == http.MethodGet:
+			io.WriteString(w, `{"display_phone_number":"+1 555 0100","verified_name":"Acme","quality_rating":"GREEN"}`)
+		case strings.HasSuffix(r.URL.Path, "/"+testPhoneID+"/messages"):
+			var req sendRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if req.To == "" { // read receipt
+				io.WriteString(w, `{"success":true}`)
+				return
+			}
+			if req.To == closedUser && req.Type != "template" {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, `{"error":{"message":"Re-engagement message","code":131047}}`)
+				return
+			}
+			g.sends = append(g.sends, req)
+			g.seq++
+			fmt.Fprintf(w, `{"messages":[{"id":"wamid.%d"}]}`, g.seq)
+		case strings.HasSuffix(r.URL.Path, "/media-1"):
+			fmt.Fprintf(w, `{"url":%q,"mime_type":"image/jpeg","file_size":4}`, g.URL+"/download/media-1")
+		case r.URL.Path == "/download/media-1":
+			w.Write([]byte{0xff, 0xd8, 0xff, 0xe0})
+		default:
+			io.WriteString(w, `{"success":true}`)
+		}
+	}))
+	facebook.SetGraphAPIBaseForTest(g.URL)
+	t.Cleanup(func() { facebook.SetGraphAPIBaseForTest("") })
+	return g
+}
+
+func (g *fakeGraph) sent() []sendRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]sendRequest(nil), g.sends...)
+}
+
+var (
+	routeOnce    sync.Once
+	webhookRoute http.Handler
+)
+
+func startChannel(t *testing.T, cfg cloudInstanceConfig) (*Channel, *bus.MessageBus, *fakeGraph) {
+	t.Helper()
+	g := newFakeGraph(t)
+	mb := bus.New()
+	cfg.PhoneNumberID, cfg.WABAID = testPhoneID, testWABA
+	if cfg.DMPolicy == "" {
+		cfg.DMPolicy = "open"
+	}
+	ch, err := New(cfg, cloudCreds{AccessToken: "tok", AppSecret: testAppSecret, VerifyToken: "verify-me"}, mb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch.SetName("wa-cloud")
+	channeltest.Start(t, ch)
+	routeOnce.Do(func() { _, webhookRoute = ch.WebhookHandler() })
+	return ch, mb, g
+}
+
+// deliver posts a signed whatsapp_business_account webhook with one change value.
+func deliver(t *testing.T, value map[string]any, secret string) int {
+	t.Helper()
+	value["messaging_product"] = "whatsapp"
+	value["metadata"] = map[string]string{"phone_number_id": testPhoneID}
+	body, _ := json.Marshal(map[string]any{
+		"object": "whatsapp_business_account",
+		"entry": []any{map[string]any{
+			"id":      testWABA,
+			"changes": []any{map[string]any{"field": "messages", "value": value}},
+		}},
+	})
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	req := httptest.NewRequest(http.MethodPost, "/channels/facebook/webhook", bytes.NewReader(body))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	webhookRoute.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func textMessage(from, id, text string) map[string]any {
+	return map[string]any{
+		"contacts": []any{map[string]any{"wa_id": from, "profile": map[string]string{"name": "Ana"}}},
+		"messages": []any{map[string]any{"from": from, "id": id, "type": "text", "text": map[string]string{"body": text}}},
+	}
+}
+
+func TestWebhook_InboundTextMediaAndInteractiveReply(t *testing.T) {
+	_, mb, _ := startChannel(t, cloudInstanceConfig{})
+
+	deliver(t, textMessage("4917600000", "wamid.in1", "Hi there"), testAppSecret)
+	in := channeltest.Consume(t, mb)
+	if in.SenderID != "4917600000" || in.ChatID != "4917600000" || in.PeerKind != "direct" || in.Content != "Hi there" {
+		t.Fatalf("inbound = %+v", in)
+	}
+	if in.Metadata["message_id"] != "wamid.in1" || in.Metadata["display_name"] != "Ana" {
+		t.Errorf("metadata = %v", in.Metadata)
+	}
+
+	// Redelivery is ignored; a forged signature is dropped.
+	deliver(t, textMessage("4917600000", "wamid.in1", "Hi there"), testAppSecret)
+	deliver(t, textMessage("4917600000", "wamid.forged", "pwned"), "other-secret")
+
+	deliver(t, map[string]any{"messages": []any{map[string]any{
+		"from": "4917600000", "id": "wamid.in2", "type": "image",
+		"image": map[string]string{"id": "media-1", "mime_type": "image/jpeg", "caption": "my receipt"},
+	}}}, testAppSecret)
+	in = channeltest.Consume(t, mb)
+	if len(in.Media) != 1 || !strings.Contains(in.Content, "my receipt") {
+		t.Fatalf("media inbound = %q %+v", in.Content, in.Media)
+	}
+	os.Remove(in.Media[0].Path)
+
+	deliver(t, map[string]any{"messages": []any{map[string]any{
+		"from": "4917600000", "id": "wamid.in3", "type": "interactive",
+		"interactive": map[string]any{"type": "button_reply", "button_reply": map[string]string{"id": "opt_2", "title": "Pro"}},
+	}}}, testAppSecret)
+	in = channeltest.Consume(t, mb)
+	if in.Content != "Pro" || in.Metadata["wa_reply_id"] != "opt_2" {
+		t.Errorf("button reply = %q %v", in.Content, in.Metadata)
+	}
+}
+
+func TestSend_InteractiveButtonsAndList(t *testing.T) {
+	ch, _, g := startChannel(t, cloudInstanceConfig{})
+	ctx := context.Background()
+
+	ch.Send(ctx, bus.OutboundMessage{ChatID: "4917600000", Content: "Which plan?\n1. Basic\n2. **Pro**"})
+	ch.Send(ctx, bus.OutboundMessage{ChatID: "4917600000", Content: "Pick a city?\n- Berlin\n- Hamburg\n- Munich\n- Cologne"})
+	ch.Send(ctx, bus.OutboundMessage{ChatID: "4917600000", Content: "**Done**, see you."})
+
+	sends := g.sent()
+	if len(sends) != 3 {
+		t.Fatalf("sends = %d", len(sends))
+	}
+	if ia := sends[0].Interactive; ia == nil || ia.Type != "button" || len(ia.Action.Buttons) != 2 ||
+		ia.Action.Buttons[1].Reply.Title != "Pro" || ia.Body.Text != "Which plan?" {
+		t.Errorf("buttons = %+v", sends[0].Interactive)
+	}
+	if ia := sends[1].Interactive; ia == nil || ia.Type != "list" || len(ia.Action.Sections[0].Rows) != 4 {
+		t.Errorf("list = %+v", sends[1].Interactive)
+	}
+	if sends[2].Type != "text" || sends[2].Text.Body != "*Done*, see you." {
+		t.Errorf("text = %+v", sends[2].Text)
+	}
+}
+
+func TestSend_OutsideWindowSendsTemplateAndDefersReply(t *testing.T) {
+	ch, _, g := startChannel(t, cloudInstanceConfig{
+		TemplateName:   "follow_up",
+		TemplateParams: []string{"{name}", "{preview}"},
+	})
+	ctx := context.Background()
+
+	// The user wrote over a day ago: free-form sends would be rejected.
+	ch.names.Store(closedUser, "Bo")
+	ch.lastInbound.Store(closedUser, time.Now().Add(-25*time.Hour))
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: closedUser, Content: "Your order shipped."}); err != nil {
+		t.Fatal(err)
+	}
+	sends := g.sent()
+	if len(sends) != 1 || sends[0].Type != "template" || sends[0].Template.Name != "follow_up" ||
+		sends[0].Template.Language.Code != "en_US" {
+		t.Fatalf("sends = %+v", sends)
+	}
+	if p := sends[0].Template.Components[0].Parameters; p[0].Text != "Bo" || p[1].Text != "Your order shipped." {
+		t.Errorf("template params = %+v", p)
+	}
+
+	// A window-closed error from Meta (user unknown since restart) also defers.
+	ch.lastInbound.Delete(closedUser)
+	ch.Send(ctx, bus.OutboundMessage{ChatID: closedUser, Content: "Tracking: 123"})
+	if n := len(g.sent()); n != 1 {
+		t.Fatalf("second deferral sent another template: %d sends", n)
+	}
+
+	ch.deferredMu.Lock()
+	queued := len(ch.deferred[closedUser])
+	ch.deferredMu.Unlock()
+	if queued != 2 {
+		t.Fatalf("queued = %d", queued)
+	}
+
+	// Without a template the send fails loudly.
+	ch.config.TemplateName = ""
+	ch.lastInbound.Store("4410000000", time.Now().Add(-48*time.Hour))
+	if err := ch.Send(ctx, bus.OutboundMessage{ChatID: "4410000000", Content: "hello"}); err == nil {
+		t.Error("expected error without template")
+	}
+}
+
+func TestStatus_BroadcastAndFailedDeferral(t *testing.T) {
+	ch, mb, g := startChannel(t, cloudInstanceConfig{TemplateName: "follow_up"})
+	events := make(chan MessageStatus, 4)
+	mb.Subscribe("test", func(ev bus.Event) {
+		if ev.Name == protocol.EventChannelMessageStatus {
+			events <- ev.Payload.(MessageStatus)
+		}
+	})
+
+	ch.Send(context.Background(), bus.OutboundMessage{ChatID: "4917600000", Content: "Here you go."})
+	id := "wamid.1"
+
+	deliver(t, map[string]any{"statuses": []any{map[string]any{
+		"id": id, "status": "failed", "timestamp": "1760000000", "recipient_id": "4917600000",
+		"errors": []any{map[string]any{"code": 131047, "title": "Re-engagement message"}},
+	}}}, testAppSecret)
+
+	select {
+	case ev := <-events:
+		if ev.MessageID != id || ev.Status != "failed" || ev.ErrorCode != 131047 || ev.Timestamp != 1760000000 {
+			t.Errorf("status event = %+v", ev)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no status event")
+	}
+
+	// The failed reply is held and the template sent instead.
+	channeltest.WaitFor(t, func() bool {
+		s := g.sent()
+		return len(s) == 2 && s[1].Type == "template"
+	})
+	ch.deferredMu.Lock()
+	q := ch.deferred["4917600000"]
+	ch.deferredMu.Unlock()
+	if len(q) != 1 || q[0].Text.Body != "Here you go." {
+		t.Fatalf("deferred = %+v", q)
+	}
+
+	// The user's next message reopens the window and releases the reply.
+	deliver(t, textMessage("4917600000", "wamid.in9", "hello?"), testAppSecret)
+	channeltest.Consume(t, mb)
+	s := g.sent()
+	if len(s) != 3 || s[2].Text == nil || s[2].Text.Body != "Here you go." {
+		t.Errorf("sends after reopen = %+v", s)
+	}
+}
+
+func TestSplitOptions(t *testing.T) {
+	for text, want := range map[string]int{
+		"Which one?\n1. A\n2. B": 2,
+		"Which one?\n- A":        0, // one option
+		"Pick:\n1. A\n2. B":      0, // no question
+		"Which one?\n1. A\n2. An option far too long for a list row": 0,
+		"Intro line.\nWhich one?\n• A\n• B\n• C":                     3,
+	} {
+		_, opts, _ := splitOptions(text)
+		if len(opts) != want {
+			t.Errorf("splitOptions(%q) = %v, want %d options", text, opts, want)
+		}
+	}
+}
@@ -348,6 +348,10 @@ type ModelPricing struct {
 	// reasoning tokens fall back to OutputPerMillion (providers typically charge
 	// reasoning at the same rate as output tokens).
 	ReasoningPerMillion float64 `json:"reasoning_per_million,omitempty"`
+	// BatchMultiplier scales the cost of requests run through a provider batch
+	// API (Anthropic Message Batches, OpenAI Batch). Zero uses the providers'
+	// standard batch discount (0.5).
+	BatchMultiplier float64 `json:"batch_multiplier,omitempty"`
 }
 
 // TelemetryConfig configures OpenTelemetry export for traces and spans.
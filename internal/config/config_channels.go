@@ -11,6 +11,16 @@ type PendingCompactionConfig struct {
 	Model      string `json:"model,omitempty"`       // model for summarization; empty = use agent's model
 }
 
+// OutboxConfig configures durable delivery of outbound channel messages.
+// Replies are persisted before sending and retried with backoff; messages that
+// fail permanently or exhaust MaxAttempts move to the dead-letter queue.
+type OutboxConfig struct {
+	Disabled                bool `json:"disabled,omitempty"`                   // send directly without persisting (no retries)
+	MaxAttempts             int  `json:"max_attempts,omitempty"`               // delivery attempts before dead-lettering (default 8)
+	MaxBackoffSeconds       int  `json:"max_backoff_seconds,omitempty"`        // cap for exponential retry backoff (default 600)
+	DeadLetterRetentionDays int  `json:"dead_letter_retention_days,omitempty"` // prune dead letters older than this (default 30)
+}
+
 // ChannelsConfig contains per-channel configuration.
 type ChannelsConfig struct {
 	Telegram          TelegramConfig           `json:"telegram"`
@@ -21,6 +31,7 @@ type ChannelsConfig struct {
 	ZaloPersonal      ZaloPersonalConfig       `json:"zalo_personal"`
 	Feishu            FeishuConfig             `json:"feishu"`
 	PendingCompaction *PendingCompactionConfig `json:"pending_compaction,omitempty"` // global pending message compaction settings
+	Outbox            *OutboxConfig            `json:"outbox,omitempty"`             // durable outbound delivery settings
 }
 
 type TelegramConfig struct {
@@ -361,6 +372,7 @@ type GatewayConfig struct {
 	TaskRecoveryIntervalSec int          `json:"task_recovery_interval_sec,omitempty"` // team task recovery ticker interval in seconds (default 300 = 5min)
 	BackgroundProvider      string       `json:"background_provider,omitempty"`        // LLM provider for background workers (vault enrichment, consolidation)
 	BackgroundModel         string       `json:"background_model,omitempty"`           // LLM model for background workers
+	BackgroundBatch         *bool        `json:"background_batch,omitempty"`           // route background LLM calls through provider batch APIs (default false)
 }
 
 // ToolsConfig controls tool availability, policy, and web search.
@@ -56,6 +56,7 @@ func (c *Config) ApplySystemConfigs(configs map[string]string) {
 	// Background workers (vault enrichment, consolidation)
 	str("background.provider", &c.Gateway.BackgroundProvider)
 	str("background.model", &c.Gateway.BackgroundModel)
+	boolean("background.batch", &c.Gateway.BackgroundBatch)
 
 	// Tools
 	str("tools.profile", &c.Tools.Profile)
@@ -2,6 +2,7 @@ package consolidation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/bgalert"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
@@ -21,8 +23,18 @@ const (
 	dreamingDefaultDebounce  = 10 * time.Minute
 	dreamingFetchLimit       = 10
 	dreamingMaxTokens        = 4096
+
+	// dreamingBatchKind is the llmbatch handler key for synthesis.
+	dreamingBatchKind = "dreaming.synthesize"
 )
 
+// dreamingBatchPayload is the llmbatch payload for a queued synthesis.
+type dreamingBatchPayload struct {
+	AgentID  string   `json:"agent_id"`
+	UserID   string   `json:"user_id"`
+	EntryIDs []string `json:"entry_ids"`
+}
+
 // dreamingWorker consolidates unpromoted episodic summaries into long-term memory.
 // Subscribes to episodic.created events; debounces per agent/user pair.
 type dreamingWorker struct {
@@ -31,6 +43,8 @@ type dreamingWorker struct {
 	systemConfigs store.SystemConfigStore // per-tenant provider config
 	registry      *providers.Registry     // provider resolution
 	alertDeps     bgalert.AlertDeps
+	batch         *llmbatch.Dispatcher // optional: batch-API synthesis
+	redactor      piiRedactorResolver  // optional: owning agent's PII policy
 
 	// threshold/debounce are the global defaults. Per-agent overrides come
 	// from resolveConfig which reads the agent's MemoryConfig.Dreaming JSONB.
@@ -39,6 +53,7 @@ type dreamingWorker struct {
 	resolveConfig DreamingConfigResolver
 
 	lastRun sync.Map // key: "agentID:userID" → time.Time
+	pending sync.Map // key: "agentID:userID" → struct{}; synthesis queued on the batch API
 }
 
 // resolveProvider delegates to shared background provider resolution.
@@ -111,6 +126,10 @@ func (w *dreamingWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 
 	// Debounce: skip if ran recently for this pair.
 	key := agentID + ":" + userID
+	if _, ok := w.pending.Load(key); ok {
+		logSkip(cfg.VerboseLog, "dreaming: batch synthesis pending", "agent", agentID, "user", userID)
+		return nil
+	}
 	if v, ok := w.lastRun.Load(key); ok {
 		if last, ok := v.(time.Time); ok && time.Since(last) < cfg.Debounce {
 			logSkip(cfg.VerboseLog, "dreaming: debounce skip", "agent", agentID, "user", userID)
@@ -158,6 +177,25 @@ func (w *dreamingWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 		slog.Warn("dreaming: no provider available", "tenant", event.TenantID, "agent", agentID)
 		return nil
 	}
+	provider, err = redactProvider(ctx, w.redactor, tenantUUID, agentID, provider)
+	if err != nil {
+		slog.Warn("dreaming: skipping synthesis", "err", err, "agent", agentID)
+		return nil
+	}
+
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID.String()
+	}
+
+	// Batch mode: the synthesis result is stored by handleBatchSynthesis.
+	payload := dreamingBatchPayload{AgentID: agentID, UserID: userID, EntryIDs: ids}
+	if w.batch.TryEnqueue(ctx, tenantUUID, dreamingBatchKind, provider, synthesisRequest(model, entries), payload) {
+		w.pending.Store(key, struct{}{})
+		w.lastRun.Store(key, time.Now())
+		slog.Info("dreaming: synthesis queued for batch", "agent", agentID, "user", userID, "entries", len(entries))
+		return nil
+	}
 
 	// Build LLM prompt and call provider.
 	synthesis, err := w.synthesize(ctx, provider, model, entries)
@@ -166,46 +204,70 @@ func (w *dreamingWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 		slog.Warn("dreaming: LLM synthesis failed", "err", err, "agent", agentID)
 		return nil
 	}
+	w.storeSynthesis(ctx, agentID, userID, ids, synthesis)
+	return nil
+}
 
-	// Store result in memory under a dated path and index for search.
+// storeSynthesis stores the synthesis in memory under a dated path, indexes
+// it for search and marks the source entries promoted.
+func (w *dreamingWorker) storeSynthesis(ctx context.Context, agentID, userID string, ids []string, synthesis string) {
 	path := fmt.Sprintf("_system/dreaming/%s-consolidated.md", time.Now().UTC().Format("20060102"))
 	if err := w.memoryStore.PutDocument(ctx, agentID, userID, path, synthesis); err != nil {
 		slog.Warn("dreaming: store document failed", "err", err, "path", path, "agent", agentID)
-		return nil
+		return
 	}
 	if err := w.memoryStore.IndexDocument(ctx, agentID, userID, path); err != nil {
 		slog.Warn("dreaming: index document failed", "err", err, "path", path, "agent", agentID)
 	}
 
 	// Mark entries as promoted.
-	ids := make([]string, len(entries))
-	for i, e := range entries {
-		ids[i] = e.ID.String()
-	}
 	if err := w.episodicStore.MarkPromoted(ctx, ids); err != nil {
 		slog.Warn("dreaming: mark promoted failed", "err", err, "agent", agentID)
-		return nil
+		return
 	}
 
 	// Update debounce tracker.
-	w.lastRun.Store(key, time.Now())
+	w.lastRun.Store(agentID+":"+userID, time.Now())
 
 	slog.Info("dreaming: consolidated", "agent", agentID, "user", userID,
-		"entries", len(entries), "path", path)
+		"entries", len(ids), "path", path)
+}
+
+// handleBatchSynthesis is the llmbatch handler for dreamingBatchKind.
+func (w *dreamingWorker) handleBatchSynthesis(ctx context.Context, raw json.RawMessage, resp *providers.ChatResponse) error {
+	var p dreamingBatchPayload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return fmt.Errorf("dreaming: decode batch payload: %w", err)
+	}
+	w.pending.Delete(p.AgentID + ":" + p.UserID)
+	if resp.Content == "" {
+		slog.Warn("dreaming: empty batch synthesis, skipping", "agent", p.AgentID, "user", p.UserID)
+		return nil
+	}
+	w.storeSynthesis(ctx, p.AgentID, p.UserID, p.EntryIDs, resp.Content)
 	return nil
 }
 
 // synthesize calls the LLM to extract long-term facts from session summaries.
-// Each entry is annotated with its recall metadata so the LLM can weight
-// frequently-recalled memories higher during synthesis.
 func (w *dreamingWorker) synthesize(ctx context.Context, provider providers.Provider, model string, entries []store.EpisodicSummary) (string, error) {
+	resp, err := provider.Chat(ctx, synthesisRequest(model, entries))
+	if err != nil {
+		return "", fmt.Errorf("dreaming chat: %w", err)
+	}
+	return resp.Content, nil
+}
+
+// synthesisRequest builds the synthesis prompt. Each entry is annotated with
+// its recall metadata so the LLM can weight frequently-recalled memories
+// higher during synthesis.
+func synthesisRequest(model string, entries []store.EpisodicSummary) providers.ChatRequest {
 	summaries := make([]string, len(entries))
 	for i, e := range entries {
 		summaries[i] = formatEntryForSynthesis(e)
 	}
 	body := strings.Join(summaries, "\n---\n")
 
-	resp, err := provider.Chat(ctx, providers.ChatRequest{
+	return providers.ChatRequest{
 		Messages: []providers.Message{
 			{Role: "system", Content: dreamingSystemPrompt},
 			{Role: "user", Content: "Session summaries:\n---\n" + body + "\n---"},
@@ -214,11 +276,7 @@ func (w *dreamingWorker) synthesize(ctx context.Context, provider providers.Prov
 		Options: map[string]any{
 			providers.OptMaxTokens: dreamingMaxTokens,
 		},
-	})
-	if err != nil {
-		return "", fmt.Errorf("dreaming chat: %w", err)
 	}
-	return resp.Content, nil
 }
 
 // dreamingSystemPrompt instructs the LLM to extract long-term facts.
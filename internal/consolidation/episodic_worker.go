@@ -2,6 +2,7 @@ package consolidation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/bgalert"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
@@ -23,6 +25,23 @@ type episodicWorker struct {
 	registry      *providers.Registry         // provider resolution
 	eventBus      eventbus.DomainEventBus
 	alertDeps     bgalert.AlertDeps
+	batch         *llmbatch.Dispatcher // optional: batch-API summarization
+	redactor      piiRedactorResolver  // optional: owning agent's PII policy
+}
+
+// episodicBatchKind is the llmbatch handler key for session summarization.
+const episodicBatchKind = "episodic.summarize"
+
+// episodicSource identifies the session an episodic summary is created for.
+// It is also the llmbatch payload for batched summarization.
+type episodicSource struct {
+	TenantID   string `json:"tenant_id"`
+	AgentID    string `json:"agent_id"`
+	UserID     string `json:"user_id"`
+	SourceID   string `json:"source_id"`
+	SessionKey string `json:"session_key"`
+	TurnCount  int    `json:"turn_count"`
+	TokenCount int    `json:"token_count"`
 }
 
 // resolveProvider delegates to shared background provider resolution.
@@ -49,8 +68,7 @@ func (w *episodicWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	}
 	// Inject tenant context so store queries and bgalert scope correctly.
 	ctx = store.WithTenantID(ctx, tenantUUID)
-	agentUUID, err := uuid.Parse(event.AgentID)
-	if err != nil {
+	if _, err := uuid.Parse(event.AgentID); err != nil {
 		return fmt.Errorf("episodic: invalid agent_id %q: %w", event.AgentID, err)
 	}
 
@@ -64,12 +82,28 @@ func (w *episodicWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 		slog.Debug("episodic: skipping duplicate", "source_id", sourceID)
 		return nil
 	}
+	src := episodicSource{
+		TenantID:   event.TenantID,
+		AgentID:    event.AgentID,
+		UserID:     event.UserID,
+		SourceID:   sourceID,
+		SessionKey: payload.SessionKey,
+		TurnCount:  payload.MessageCount,
+		TokenCount: payload.TokensUsed,
+	}
 
 	// Use compaction summary if available, else call LLM
 	summary := payload.Summary
 	if summary == "" {
 		provider, model := w.resolveProvider(ctx, tenantUUID)
+		if provider, err = redactProvider(ctx, w.redactor, tenantUUID, event.AgentID, provider); err != nil {
+			return fmt.Errorf("episodic: %w", err)
+		}
 		if provider != nil {
+			if w.enqueueSummary(ctx, tenantUUID, provider, model, src) {
+				slog.Debug("episodic: summarization queued for batch", "session", payload.SessionKey)
+				return nil
+			}
 			summary, err = w.summarizeSession(ctx, provider, model, payload)
 			if err != nil {
 				bgalert.ReportProviderError(ctx, w.alertDeps, "episodic", err)
@@ -83,7 +117,21 @@ func (w *episodicWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 			"compaction_summary_empty", payload.Summary == "", "provider_nil", provider == nil)
 		return nil
 	}
-	slog.Debug("episodic: creating summary", "session", payload.SessionKey, "summary_len", len(summary))
+	return w.createSummary(ctx, src, summary)
+}
+
+// createSummary stores the episodic summary and publishes episodic.created.
+func (w *episodicWorker) createSummary(ctx context.Context, src episodicSource, summary string) error {
+	slog.Debug("episodic: creating summary", "session", src.SessionKey, "summary_len", len(summary))
+
+	tenantUUID, err := uuid.Parse(src.TenantID)
+	if err != nil {
+		return fmt.Errorf("episodic: invalid tenant_id %q: %w", src.TenantID, err)
+	}
+	agentUUID, err := uuid.Parse(src.AgentID)
+	if err != nil {
+		return fmt.Errorf("episodic: invalid agent_id %q: %w", src.AgentID, err)
+	}
 
 	// Create episodic summary
 	l0 := generateL0Abstract(summary)
@@ -93,14 +141,14 @@ func (w *episodicWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	ep := &store.EpisodicSummary{
 		TenantID:   tenantUUID,
 		AgentID:    agentUUID,
-		UserID:     event.UserID,
-		SessionKey: payload.SessionKey,
+		UserID:     src.UserID,
+		SessionKey: src.SessionKey,
 		Summary:    summary,
 		KeyTopics:  entities,
-		TurnCount:  payload.MessageCount,
-		TokenCount: payload.TokensUsed,
+		TurnCount:  src.TurnCount,
+		TokenCount: src.TokenCount,
 		L0Abstract: l0,
-		SourceID:   sourceID,
+		SourceID:   src.SourceID,
 		SourceType: "session",
 		ExpiresAt:  &expiresAt,
 	}
@@ -112,21 +160,55 @@ func (w *episodicWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	w.eventBus.Publish(eventbus.DomainEvent{
 		Type:     eventbus.EventEpisodicCreated,
 		SourceID: ep.ID.String(),
-		TenantID: event.TenantID,
-		AgentID:  event.AgentID,
-		UserID:   event.UserID,
+		TenantID: src.TenantID,
+		AgentID:  src.AgentID,
+		UserID:   src.UserID,
 		Payload: &eventbus.EpisodicCreatedPayload{
 			EpisodicID:  ep.ID.String(),
-			SessionKey:  payload.SessionKey,
+			SessionKey:  src.SessionKey,
 			Summary:     summary,
 			KeyEntities: entities,
 		},
 	})
 
-	slog.Info("episodic: created summary", "session", payload.SessionKey, "l0_len", len(l0))
+	slog.Info("episodic: created summary", "session", src.SessionKey, "l0_len", len(l0))
 	return nil
 }
 
+// enqueueSummary queues session summarization on the batch API when the
+// tenant opted in. Returns false when the caller should summarize inline.
+func (w *episodicWorker) enqueueSummary(ctx context.Context, tenantID uuid.UUID, provider providers.Provider, model string, src episodicSource) bool {
+	if w.batch == nil || w.sessions == nil {
+		return false
+	}
+	messages := w.sessions.GetHistory(ctx, src.SessionKey)
+	if len(messages) == 0 {
+		return false
+	}
+	return w.batch.TryEnqueue(ctx, tenantID, episodicBatchKind, provider, summarizationRequest(model, messages), src)
+}
+
+// handleBatchSummary is the llmbatch handler for episodicBatchKind.
+func (w *episodicWorker) handleBatchSummary(ctx context.Context, payload json.RawMessage, resp *providers.ChatResponse) error {
+	var src episodicSource
+	if err := json.Unmarshal(payload, &src); err != nil {
+		return fmt.Errorf("episodic: decode batch payload: %w", err)
+	}
+	if resp.Content == "" {
+		slog.Warn("episodic: empty batch summary, skipping", "session", src.SessionKey)
+		return nil
+	}
+	// Another path may have summarized the session while the batch ran.
+	exists, err := w.store.ExistsBySourceID(ctx, src.AgentID, src.UserID, src.SourceID)
+	if err != nil {
+		return fmt.Errorf("episodic: check source_id: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	return w.createSummary(ctx, src, resp.Content)
+}
+
 // summarizeSession reads actual session messages and calls LLM to summarize.
 func (w *episodicWorker) summarizeSession(ctx context.Context, provider providers.Provider, model string, payload *eventbus.SessionCompletedPayload) (string, error) {
 	// Try reading session messages for a real summary.
@@ -145,6 +227,19 @@ func (w *episodicWorker) summarizeSession(ctx context.Context, provider provider
 
 // summarizeFromMessages builds a conversation excerpt and calls LLM.
 func (w *episodicWorker) summarizeFromMessages(ctx context.Context, provider providers.Provider, model string, messages []providers.Message) (string, error) {
+	sctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := provider.Chat(sctx, summarizationRequest(model, messages))
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+// summarizationRequest builds the session summarization request from a
+// truncated conversation excerpt.
+func summarizationRequest(model string, messages []providers.Message) providers.ChatRequest {
 	var sb strings.Builder
 	for _, m := range messages {
 		if m.Role == "system" {
@@ -165,19 +260,12 @@ func (w *episodicWorker) summarizeFromMessages(ctx context.Context, provider pro
 		}
 	}
 
-	sctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	resp, err := provider.Chat(sctx, providers.ChatRequest{
+	return providers.ChatRequest{
 		Messages: []providers.Message{
 			{Role: "system", Content: summarizationPrompt},
 			{Role: "user", Content: sb.String()},
 		},
 		Model:   model,
 		Options: map[string]any{"max_tokens": 1024, "temperature": 0.3},
-	})
-	if err != nil {
-		return "", err
 	}
-	return resp.Content, nil
 }
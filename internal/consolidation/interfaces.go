@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/nextlevelbuilder/goclaw/internal/knowledgegraph"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 )
 
 // EntityExtractor extracts knowledge graph entities from text.
@@ -11,3 +12,14 @@ import (
 type EntityExtractor interface {
 	Extract(ctx context.Context, text string) (*knowledgegraph.ExtractionResult, error)
 }
+
+// ProviderEntityExtractor is an EntityExtractor that exposes its LLM call, so
+// the semantic worker can redact it and queue it on a provider batch API.
+// Implemented by *knowledgegraph.Extractor.
+type ProviderEntityExtractor interface {
+	EntityExtractor
+	Provider() providers.Provider
+	ExtractWith(ctx context.Context, p providers.Provider, text string) (*knowledgegraph.ExtractionResult, error)
+	Request(text string) (providers.ChatRequest, bool)
+	ParseResponse(resp *providers.ChatResponse) (*knowledgegraph.ExtractionResult, error)
+}
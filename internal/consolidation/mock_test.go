@@ -2,10 +2,12 @@ package consolidation
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/knowledgegraph"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
 // testRegistry creates a Registry with the given provider registered under MasterTenantID.
@@ -32,9 +34,11 @@ func (m *mockExtractor) Extract(_ context.Context, _ string) (*knowledgegraph.Ex
 type mockProvider struct {
 	chatResp *providers.ChatResponse
 	chatErr  error
+	lastReq  providers.ChatRequest
 }
 
-func (m *mockProvider) Chat(_ context.Context, _ providers.ChatRequest) (*providers.ChatResponse, error) {
+func (m *mockProvider) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	m.lastReq = req
 	return m.chatResp, m.chatErr
 }
 
@@ -44,3 +48,36 @@ func (m *mockProvider) ChatStream(_ context.Context, _ providers.ChatRequest, _
 
 func (m *mockProvider) Name() string         { return "mock" }
 func (m *mockProvider) DefaultModel() string  { return "mock-model" }
+
+// mockBatchProvider is a mockProvider that advertises a batch API. Batches are
+// never submitted in these tests; items are delivered by calling handlers.
+type mockBatchProvider struct{ mockProvider }
+
+func (m *mockBatchProvider) Capabilities() providers.ProviderCapabilities {
+	return providers.ProviderCapabilities{Batch: true}
+}
+
+func (m *mockBatchProvider) SubmitBatch(context.Context, []providers.BatchRequest) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *mockBatchProvider) PollBatch(context.Context, string) (*providers.BatchPoll, error) {
+	return nil, errors.New("not implemented")
+}
+
+// mockBatchStore records enqueued llmbatch items.
+type mockBatchStore struct {
+	store.LLMBatchStore
+	items []store.LLMBatchItem
+}
+
+func (m *mockBatchStore) EnqueueItem(_ context.Context, it *store.LLMBatchItem) error {
+	it.ID = uuid.New()
+	m.items = append(m.items, *it)
+	return nil
+}
+
+// batchOptIn is a SystemConfigStore with background.batch enabled.
+type batchOptIn struct{ store.SystemConfigStore }
+
+func (batchOptIn) Get(context.Context, string) (string, error) { return "true", nil }
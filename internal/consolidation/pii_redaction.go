@@ -0,0 +1,48 @@
+package consolidation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// piiRedactorResolver returns the PII redactor of the agent that owns the
+// data being consolidated, or nil when redaction is off for it.
+type piiRedactorResolver func(ctx context.Context, tenantID uuid.UUID, agentID string) (*providers.PIIRedactor, error)
+
+// newPIIRedactorResolver resolves redactors like the agent loop does: tenant
+// policy merged with the agent's own. Without an agent store only the tenant
+// policy applies.
+func newPIIRedactorResolver(agents store.AgentCRUDStore, sysConfigs store.SystemConfigStore) piiRedactorResolver {
+	return func(ctx context.Context, tenantID uuid.UUID, agentID string) (*providers.PIIRedactor, error) {
+		ag := &store.AgentData{TenantID: tenantID}
+		if agents != nil {
+			id, err := uuid.Parse(agentID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid agent_id %q: %w", agentID, err)
+			}
+			if ag, err = agents.GetByIDUnscoped(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		return store.ResolvePIIRedactor(ctx, sysConfigs, ag)
+	}
+}
+
+// redactProvider wraps p with the owning agent's redactor. Summaries and
+// syntheses carry session transcripts, so they go out under the same policy
+// as the conversation itself; a policy that cannot be loaded fails the call.
+func redactProvider(ctx context.Context, resolve piiRedactorResolver, tenantID uuid.UUID, agentID string, p providers.Provider) (providers.Provider, error) {
+	if resolve == nil || p == nil {
+		return p, nil
+	}
+	redactor, err := resolve(ctx, tenantID, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("pii redaction: %w", err)
+	}
+	return providers.NewRedactingProvider(p, redactor, nil), nil
+}
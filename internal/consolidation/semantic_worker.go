@@ -2,6 +2,7 @@ package consolidation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -9,15 +10,32 @@ import (
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/bgalert"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/knowledgegraph"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
+// semanticBatchKind is the llmbatch handler key for KG extraction.
+const semanticBatchKind = "semantic.extract"
+
+// semanticSource identifies the episodic summary facts are extracted from.
+// It is also the llmbatch payload for batched extraction.
+type semanticSource struct {
+	TenantID   string `json:"tenant_id"`
+	AgentID    string `json:"agent_id"`
+	UserID     string `json:"user_id"`
+	EpisodicID string `json:"episodic_id"`
+}
+
 // semanticWorker handles episodic.created events → extracts KG facts from summaries.
 type semanticWorker struct {
 	kgStore   store.KnowledgeGraphStore
 	extractor EntityExtractor
 	eventBus  eventbus.DomainEventBus
 	alertDeps bgalert.AlertDeps
+	batch     *llmbatch.Dispatcher // optional: batch-API extraction
+	redactor  piiRedactorResolver  // optional: owning agent's PII policy
 }
 
 // Handle extracts entities and relations from an episodic summary.
@@ -28,8 +46,10 @@ func (w *semanticWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	}
 
 	// Inject tenant context so bgalert scopes correctly.
+	var tenantID uuid.UUID
 	if event.TenantID != "" {
 		if tid, err := uuid.Parse(event.TenantID); err == nil {
+			tenantID = tid
 			ctx = store.WithTenantID(ctx, tid)
 		}
 	}
@@ -37,14 +57,59 @@ func (w *semanticWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	if w.extractor == nil || payload.Summary == "" {
 		return nil
 	}
+	src := semanticSource{
+		TenantID:   event.TenantID,
+		AgentID:    event.AgentID,
+		UserID:     event.UserID,
+		EpisodicID: payload.EpisodicID,
+	}
 
 	// Extract entities/relations from summary (much cheaper than full session)
-	result, err := w.extractor.Extract(ctx, payload.Summary)
+	var result *knowledgegraph.ExtractionResult
+	var err error
+	if pe, ok := w.extractor.(ProviderEntityExtractor); ok {
+		provider, rerr := redactProvider(ctx, w.redactor, tenantID, event.AgentID, pe.Provider())
+		if rerr != nil {
+			slog.Warn("semantic: skipping extraction", "episodic_id", payload.EpisodicID, "err", rerr)
+			return nil
+		}
+		if req, single := pe.Request(payload.Summary); single &&
+			w.batch.TryEnqueue(ctx, tenantID, semanticBatchKind, provider, req, src) {
+			slog.Debug("semantic: extraction queued for batch", "episodic_id", payload.EpisodicID)
+			return nil
+		}
+		result, err = pe.ExtractWith(ctx, provider, payload.Summary)
+	} else {
+		result, err = w.extractor.Extract(ctx, payload.Summary)
+	}
 	if err != nil {
 		bgalert.ReportProviderError(ctx, w.alertDeps, "kg_extraction", err)
 		slog.Warn("semantic: extraction failed", "episodic_id", payload.EpisodicID, "err", err)
 		return nil // non-fatal: extraction failure doesn't block pipeline
 	}
+	return w.ingest(ctx, src, result)
+}
+
+// handleBatchExtraction is the llmbatch Handler for queued extractions.
+func (w *semanticWorker) handleBatchExtraction(ctx context.Context, raw json.RawMessage, resp *providers.ChatResponse) error {
+	var src semanticSource
+	if err := json.Unmarshal(raw, &src); err != nil {
+		return fmt.Errorf("semantic: decode batch payload: %w", err)
+	}
+	pe, ok := w.extractor.(ProviderEntityExtractor)
+	if !ok {
+		return fmt.Errorf("semantic: extractor cannot parse batch results")
+	}
+	result, err := pe.ParseResponse(resp)
+	if err != nil {
+		slog.Warn("semantic: batch extraction unusable", "episodic_id", src.EpisodicID, "err", err)
+		return err
+	}
+	return w.ingest(ctx, src, result)
+}
+
+// ingest stores extracted entities and relations and publishes entity.upserted.
+func (w *semanticWorker) ingest(ctx context.Context, src semanticSource, result *knowledgegraph.ExtractionResult) error {
 	if len(result.Entities) == 0 && len(result.Relations) == 0 {
 		return nil
 	}
@@ -52,18 +117,18 @@ func (w *semanticWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	// Set temporal fields + scoping on extracted entities
 	now := time.Now().UTC()
 	for i := range result.Entities {
-		result.Entities[i].AgentID = event.AgentID
-		result.Entities[i].UserID = event.UserID
+		result.Entities[i].AgentID = src.AgentID
+		result.Entities[i].UserID = src.UserID
 		result.Entities[i].ValidFrom = &now
 	}
 	for i := range result.Relations {
-		result.Relations[i].AgentID = event.AgentID
-		result.Relations[i].UserID = event.UserID
+		result.Relations[i].AgentID = src.AgentID
+		result.Relations[i].UserID = src.UserID
 		result.Relations[i].ValidFrom = &now
 	}
 
 	// Ingest into KG store
-	entityIDs, err := w.kgStore.IngestExtraction(ctx, event.AgentID, event.UserID,
+	entityIDs, err := w.kgStore.IngestExtraction(ctx, src.AgentID, src.UserID,
 		result.Entities, result.Relations)
 	if err != nil {
 		return fmt.Errorf("semantic: ingest: %w", err)
@@ -73,15 +138,15 @@ func (w *semanticWorker) Handle(ctx context.Context, event eventbus.DomainEvent)
 	if len(entityIDs) > 0 {
 		w.eventBus.Publish(eventbus.DomainEvent{
 			Type:     eventbus.EventEntityUpserted,
-			SourceID: payload.EpisodicID,
-			TenantID: event.TenantID,
-			AgentID:  event.AgentID,
-			UserID:   event.UserID,
+			SourceID: src.EpisodicID,
+			TenantID: src.TenantID,
+			AgentID:  src.AgentID,
+			UserID:   src.UserID,
 			Payload:  &eventbus.EntityUpsertedPayload{EntityIDs: entityIDs},
 		})
 	}
 
 	slog.Info("semantic: extracted", "entities", len(result.Entities),
-		"relations", len(result.Relations), "episodic_id", payload.EpisodicID)
+		"relations", len(result.Relations), "episodic_id", src.EpisodicID)
 	return nil
 }
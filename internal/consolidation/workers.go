@@ -11,6 +11,7 @@ import (
 
 	"github.com/nextlevelbuilder/goclaw/internal/bgalert"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
@@ -27,14 +28,20 @@ type ConsolidationDeps struct {
 	Extractor     EntityExtractor
 	AlertDeps     bgalert.AlertDeps // for reporting non-retryable LLM errors
 	// AgentStore is optional: when present, the dreaming worker reads
-	// per-agent overrides from MemoryConfig.Dreaming. If nil, the worker
-	// uses its built-in defaults for every agent.
+	// per-agent overrides from MemoryConfig.Dreaming and LLM calls use the
+	// agent's PII redaction policy. If nil, the worker uses its built-in
+	// defaults and only the tenant redaction policy applies.
 	AgentStore store.AgentCRUDStore
+	// Batch is optional: when set, episodic summarization, semantic
+	// extraction and dreaming synthesis run through provider batch APIs for
+	// tenants that opted in (system config "background.batch").
+	Batch *llmbatch.Dispatcher
 }
 
 // Register wires all consolidation workers to the event bus.
 // Returns a cleanup function that unsubscribes all handlers.
 func Register(deps ConsolidationDeps) func() {
+	redactor := newPIIRedactorResolver(deps.AgentStore, deps.SystemConfigs)
 	episodic := &episodicWorker{
 		store:         deps.EpisodicStore,
 		sessions:      deps.SessionStore,
@@ -42,12 +49,16 @@ func Register(deps ConsolidationDeps) func() {
 		registry:      deps.Registry,
 		eventBus:      deps.EventBus,
 		alertDeps:     deps.AlertDeps,
+		batch:         deps.Batch,
+		redactor:      redactor,
 	}
 	semantic := &semanticWorker{
 		kgStore:   deps.KGStore,
 		extractor: deps.Extractor,
 		eventBus:  deps.EventBus,
 		alertDeps: deps.AlertDeps,
+		batch:     deps.Batch,
+		redactor:  redactor,
 	}
 	dedup := &dedupWorker{
 		kgStore: deps.KGStore,
@@ -62,6 +73,13 @@ func Register(deps ConsolidationDeps) func() {
 		threshold:     dreamingDefaultThreshold,
 		debounce:      dreamingDefaultDebounce,
 		resolveConfig: newAgentStoreResolver(deps.AgentStore),
+		batch:         deps.Batch,
+		redactor:      redactor,
+	}
+	if deps.Batch != nil {
+		deps.Batch.Register(episodicBatchKind, episodic.handleBatchSummary)
+		deps.Batch.Register(dreamingBatchKind, dreaming.handleBatchSynthesis)
+		deps.Batch.Register(semanticBatchKind, semantic.handleBatchExtraction)
 	}
 
 	unsub1 := deps.EventBus.Subscribe(eventbus.EventSessionCompleted, episodic.Handle)
@@ -2,6 +2,7 @@ package consolidation
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
 	"github.com/nextlevelbuilder/goclaw/internal/knowledgegraph"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
@@ -500,6 +502,56 @@ func TestSemanticWorkerHandle_WithValidExtraction(t *testing.T) {
 	}
 }
 
+func TestSemanticWorkerHandle_Batch(t *testing.T) {
+	mockKG := &mockKGStore{}
+	mockEventBus := newMockDomainEventBus()
+	bp := &mockBatchProvider{}
+	bs := &mockBatchStore{}
+	worker := &semanticWorker{
+		kgStore:   mockKG,
+		extractor: knowledgegraph.NewExtractor(bp, "kg-model", 0),
+		eventBus:  mockEventBus,
+		batch:     llmbatch.New(llmbatch.Config{Store: bs, SystemConfigs: batchOptIn{}}),
+	}
+	worker.batch.Register(semanticBatchKind, worker.handleBatchExtraction)
+
+	event := eventbus.DomainEvent{
+		Type:     eventbus.EventEpisodicCreated,
+		TenantID: uuid.New().String(),
+		AgentID:  uuid.New().String(),
+		UserID:   "test-user",
+		Payload:  &eventbus.EpisodicCreatedPayload{EpisodicID: "ep-1", Summary: "Alice works at TechCorp"},
+	}
+	if err := worker.Handle(context.Background(), event); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if len(bs.items) != 1 || bs.items[0].Kind != semanticBatchKind || bs.items[0].Model != "kg-model" {
+		t.Fatalf("queued items = %+v, want one %s item", bs.items, semanticBatchKind)
+	}
+	if len(bp.lastReq.Messages) != 0 || len(mockKG.ingestedEntities) != 0 {
+		t.Fatal("batched extraction must not run synchronously")
+	}
+
+	resp := &providers.ChatResponse{
+		Content:      `{"entities":[{"external_id":"alice","name":"Alice","entity_type":"person","confidence":0.9}],"relations":[]}`,
+		FinishReason: "stop",
+	}
+	if err := worker.handleBatchExtraction(context.Background(), bs.items[0].Payload, resp); err != nil {
+		t.Fatalf("handleBatchExtraction: %v", err)
+	}
+	if len(mockKG.ingestedEntities) != 1 || mockKG.ingestedEntities[0].AgentID != event.AgentID {
+		t.Errorf("ingested = %+v, want Alice scoped to the agent", mockKG.ingestedEntities)
+	}
+	if len(mockEventBus.published) != 1 || mockEventBus.published[0].SourceID != "ep-1" {
+		t.Errorf("published = %+v, want one entity.upserted for ep-1", mockEventBus.published)
+	}
+
+	truncated := &providers.ChatResponse{Content: `{"entities":[`, FinishReason: "length"}
+	if err := worker.handleBatchExtraction(context.Background(), bs.items[0].Payload, truncated); err == nil {
+		t.Error("truncated batch result must be reported")
+	}
+}
+
 func TestSemanticWorkerHandle_NilExtractor(t *testing.T) {
 	worker := &semanticWorker{
 		extractor: nil,
@@ -728,6 +780,63 @@ func TestDreamingWorkerHandle_MeetsThreshold(t *testing.T) {
 	}
 }
 
+func TestDreamingWorkerHandle_RedactsPII(t *testing.T) {
+	redactor, err := providers.NewPIIRedactor([]string{providers.PIIEmail}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := eventbus.DomainEvent{
+		Type:     eventbus.EventEpisodicCreated,
+		TenantID: providers.MasterTenantID.String(),
+		AgentID:  "agent-123",
+		UserID:   "user-123",
+		Payload:  &eventbus.EpisodicCreatedPayload{},
+	}
+	newWorker := func(p *mockProvider, resolve piiRedactorResolver) (*dreamingWorker, *mockMemoryStore) {
+		summaries := make([]store.EpisodicSummary, 5)
+		for i := range summaries {
+			summaries[i] = store.EpisodicSummary{ID: uuid.New(), Summary: "Reach the user at alice@example.com"}
+		}
+		mem := newMockMemoryStore()
+		return &dreamingWorker{
+			episodicStore: &mockEpisodicStore{countResult: 5, unpromoted: summaries, promoted: map[string]bool{}},
+			memoryStore:   mem,
+			registry:      testRegistry(p),
+			threshold:     5,
+			debounce:      time.Second,
+			redactor:      resolve,
+		}, mem
+	}
+
+	p := &mockProvider{chatResp: &providers.ChatResponse{Content: "Contact: [PII_EMAIL_1]"}}
+	worker, mem := newWorker(p, func(context.Context, uuid.UUID, string) (*providers.PIIRedactor, error) {
+		return redactor, nil
+	})
+	if err := worker.Handle(context.Background(), event); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if body := p.lastReq.Messages[len(p.lastReq.Messages)-1].Content; strings.Contains(body, "alice@example.com") || !strings.Contains(body, "[PII_EMAIL_1]") {
+		t.Errorf("synthesis request not redacted: %q", body)
+	}
+	for _, doc := range mem.docs {
+		if doc != "Contact: alice@example.com" {
+			t.Errorf("stored synthesis = %q, want restored address", doc)
+		}
+	}
+
+	// A policy that cannot be loaded skips the call instead of sending raw PII.
+	p = &mockProvider{chatResp: &providers.ChatResponse{Content: "x"}}
+	worker, mem = newWorker(p, func(context.Context, uuid.UUID, string) (*providers.PIIRedactor, error) {
+		return nil, errors.New("db down")
+	})
+	if err := worker.Handle(context.Background(), event); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if len(p.lastReq.Messages) != 0 || len(mem.docs) != 0 {
+		t.Errorf("provider called with unresolved policy: %d messages, %d docs", len(p.lastReq.Messages), len(mem.docs))
+	}
+}
+
 func TestDreamingWorkerHandle_DebounceSkip(t *testing.T) {
 	summaries := []store.EpisodicSummary{
 		{ID: uuid.New(), Summary: "Session 1"},
@@ -841,3 +950,40 @@ func TestRegister_WiresAllWorkers(t *testing.T) {
 
 	cleanup()
 }
+
+func TestEpisodicWorkerHandleBatchSummary(t *testing.T) {
+	mockStore := &mockEpisodicStore{existsByID: make(map[string]bool)}
+	mockEventBus := newMockDomainEventBus()
+	worker := &episodicWorker{store: mockStore, eventBus: mockEventBus}
+
+	src := episodicSource{
+		TenantID:   providers.MasterTenantID.String(),
+		AgentID:    uuid.New().String(),
+		UserID:     "test-user",
+		SourceID:   "session-123:0",
+		SessionKey: "session-123",
+		TurnCount:  4,
+	}
+	payload, _ := json.Marshal(src)
+	resp := &providers.ChatResponse{Content: "Batched summary"}
+
+	if err := worker.handleBatchSummary(context.Background(), payload, resp); err != nil {
+		t.Fatalf("handleBatchSummary: %v", err)
+	}
+	if len(mockStore.created) != 1 || mockStore.created[0].Summary != "Batched summary" ||
+		mockStore.created[0].SourceID != src.SourceID || mockStore.created[0].TurnCount != 4 {
+		t.Fatalf("created = %+v", mockStore.created)
+	}
+	if len(mockEventBus.published) != 1 {
+		t.Errorf("published = %d, want 1", len(mockEventBus.published))
+	}
+
+	// Summarized by another path while the batch ran: skip.
+	mockStore.existsByID[src.SourceID] = true
+	if err := worker.handleBatchSummary(context.Background(), payload, resp); err != nil {
+		t.Fatalf("handleBatchSummary (dup): %v", err)
+	}
+	if len(mockStore.created) != 1 {
+		t.Errorf("duplicate batch result created a second summary")
+	}
+}
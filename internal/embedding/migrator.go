@@ -0,0 +1,325 @@
+// Package embedding tracks which embedding model produced the stored vectors
+// and re-embeds them in the background when the configured model changes.
+//
+// Every vector is stored with an embedding_model tag ("model@dims"). At startup
+// Init compares the configured model with the recorded active model. On a
+// mismatch the stores get a store.MigratingEmbeddingProvider, which keeps
+// search working over both models (dual-read), and a re-embedding run can be
+// started. Runs walk store.EmbeddingIndexStore tables in batches; progress is
+// persisted in system_configs so a restarted gateway resumes where it stopped.
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+const (
+	defaultBatchSize = 100
+	maxBatchRetries  = 3
+)
+
+// ErrNoMigration is returned by Start when stored vectors already match the
+// active embedding model.
+var ErrNoMigration = errors.New("embedding: no model migration pending")
+
+// Config configures a Migrator.
+type Config struct {
+	Index         store.EmbeddingIndexStore
+	SystemConfigs store.SystemConfigStore
+	Publisher     bus.EventPublisher // optional: progress events for the UI
+	BatchSize     int                // rows re-embedded per provider call (default 100)
+	RetryBackoff  time.Duration      // first retry delay after a failed batch (default 2s, doubles)
+}
+
+// Status is the migration view served by GET /v1/embedding/status.
+type Status struct {
+	ActiveModel string                         `json:"active_model,omitempty"`
+	Migrating   bool                           `json:"migrating"`
+	Migration   *store.EmbeddingMigrationState `json:"migration,omitempty"`
+}
+
+// Migrator detects embedding model changes and runs re-embedding migrations.
+type Migrator struct {
+	cfg Config
+
+	current   store.EmbeddingProvider
+	record    store.EmbeddingModelRecord
+	migrating *store.MigratingEmbeddingProvider // nil when vectors match the active model
+
+	mu      sync.Mutex
+	state   *store.EmbeddingMigrationState
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a Migrator. Call Init before use.
+func New(cfg Config) *Migrator {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 2 * time.Second
+	}
+	return &Migrator{cfg: cfg}
+}
+
+// masterCtx scopes system_configs access to the master tenant.
+func masterCtx(ctx context.Context) context.Context {
+	return store.WithTenantID(ctx, store.MasterTenantID)
+}
+
+// Init compares the configured embedding model with the recorded one and
+// returns the provider the stores should use. On first run the recorded model
+// is initialised and untagged vectors adopted. On a mismatch the returned
+// provider dual-reads through previous (built by the caller from the recorded
+// model; may return nil) and a run left in progress is resumed.
+func (m *Migrator) Init(ctx context.Context, current store.EmbeddingProvider, rec store.EmbeddingModelRecord,
+	previous func(store.EmbeddingModelRecord) store.EmbeddingProvider) (store.EmbeddingProvider, error) {
+	m.current, m.record = current, rec
+	mctx := masterCtx(ctx)
+
+	var active store.EmbeddingModelRecord
+	raw, _ := m.cfg.SystemConfigs.Get(mctx, store.EmbeddingActiveModelKey)
+	if raw == "" || json.Unmarshal([]byte(raw), &active) != nil || active.Model == "" {
+		// Vectors written before model tracking belong to the configured model.
+		n, err := m.cfg.Index.AdoptUntagged(ctx, rec.Tag())
+		if err != nil {
+			return current, err
+		}
+		if n > 0 {
+			slog.Info("embedding: tagged existing vectors", "model", rec.Tag(), "rows", n)
+		}
+		return current, m.saveActive(mctx, rec)
+	}
+	if active.Tag() == rec.Tag() {
+		return current, nil
+	}
+
+	if _, err := m.cfg.Index.AdoptUntagged(ctx, active.Tag()); err != nil {
+		return current, err
+	}
+	var prev store.EmbeddingProvider
+	if previous != nil {
+		prev = previous(active)
+	}
+	if prev == nil {
+		slog.Warn("embedding: previous model unavailable, unmigrated vectors are excluded from search until re-embedded",
+			"previous", active.Tag())
+	}
+	m.migrating = store.NewMigratingEmbeddingProvider(current, prev, active.Tag())
+
+	st := m.loadState(mctx)
+	if st == nil || st.To != rec.Tag() || st.State == store.EmbeddingMigrationCompleted {
+		stats, err := m.cfg.Index.Stats(ctx, rec.Tag())
+		if err != nil {
+			return m.migrating, err
+		}
+		st = &store.EmbeddingMigrationState{State: store.EmbeddingMigrationPending, Tables: stats}
+		for _, s := range stats {
+			st.Total += s.Stale
+		}
+	}
+	st.From, st.To = active.Tag(), rec.Tag()
+	m.state = st
+	slog.Warn("embedding: model changed, stored vectors need re-embedding",
+		"from", st.From, "to", st.To, "stale", st.Total, "state", st.State)
+	if st.State == store.EmbeddingMigrationRunning {
+		m.startLocked()
+	} else {
+		m.persist(mctx)
+	}
+	return m.migrating, nil
+}
+
+// Start begins (or restarts after a failure) the re-embedding run.
+func (m *Migrator) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.migrating == nil || m.state == nil || m.state.State == store.EmbeddingMigrationCompleted {
+		return ErrNoMigration
+	}
+	if m.running {
+		return nil
+	}
+	m.state.State = store.EmbeddingMigrationRunning
+	m.state.Error = ""
+	if m.state.StartedAt == 0 {
+		m.state.StartedAt = time.Now().UnixMilli()
+	}
+	m.startLocked()
+	return nil
+}
+
+func (m *Migrator) startLocked() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.running, m.cancel = true, cancel
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.run(ctx)
+	}()
+}
+
+// Stop interrupts a running migration. Progress is kept and resumed on the
+// next Init.
+func (m *Migrator) Stop() {
+	m.mu.Lock()
+	cancel := m.cancel
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	m.wg.Wait()
+}
+
+// Status returns the current model and migration progress.
+func (m *Migrator) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := Status{ActiveModel: m.record.Tag(), Migrating: m.migrating != nil && m.migrating.Migrating()}
+	if m.record.Model == "" {
+		st.ActiveModel = ""
+	}
+	if m.state != nil {
+		cp := *m.state
+		cp.Tables = append([]store.EmbeddingIndexStats(nil), m.state.Tables...)
+		st.Migration = &cp
+	}
+	return st
+}
+
+func (m *Migrator) run(ctx context.Context) {
+	mctx := masterCtx(ctx)
+	m.mu.Lock()
+	st := m.state
+	m.persist(mctx)
+	m.mu.Unlock()
+	slog.Info("embedding: re-embedding started", "from", st.From, "to", st.To, "done", st.Done, "total", st.Total)
+
+	tables := m.cfg.Index.Tables()
+	start := 0
+	for i, t := range tables {
+		if t == st.Table {
+			start = i
+		}
+	}
+	for _, table := range tables[start:] {
+		m.mu.Lock()
+		if st.Table != table {
+			st.Table, st.Cursor = table, ""
+		}
+		m.mu.Unlock()
+		for {
+			n, next, err := m.reembedBatch(ctx, table, st.Cursor)
+			if err != nil {
+				m.finish(mctx, err)
+				return
+			}
+			m.mu.Lock()
+			st.Done += n
+			st.Cursor = next
+			st.UpdatedAt = time.Now().UnixMilli()
+			for i := range st.Tables {
+				if st.Tables[i].Table == table {
+					st.Tables[i].Current += n
+					st.Tables[i].Stale = max(st.Tables[i].Stale-n, 0)
+				}
+			}
+			m.persist(mctx)
+			m.mu.Unlock()
+			if next == "" {
+				break
+			}
+		}
+	}
+	m.finish(mctx, nil)
+}
+
+// reembedBatch re-embeds one batch, retrying with backoff.
+func (m *Migrator) reembedBatch(ctx context.Context, table, cursor string) (int, string, error) {
+	backoff := m.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		n, next, err := m.cfg.Index.ReembedBatch(ctx, table, cursor, m.current, m.cfg.BatchSize)
+		if err == nil || ctx.Err() != nil || attempt >= maxBatchRetries {
+			return n, next, err
+		}
+		slog.Warn("embedding: re-embed batch failed, retrying", "table", table, "attempt", attempt+1, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return 0, "", ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// finish records the outcome of a run. A cancelled run stays "running" so the
+// next Init resumes it.
+func (m *Migrator) finish(ctx context.Context, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running, m.cancel = false, nil
+	st := m.state
+	st.UpdatedAt = time.Now().UnixMilli()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		slog.Info("embedding: re-embedding interrupted", "done", st.Done, "total", st.Total)
+		return
+	case err != nil:
+		st.State, st.Error = store.EmbeddingMigrationFailed, err.Error()
+		slog.Error("embedding: re-embedding failed", "table", st.Table, "error", err)
+	default:
+		st.State, st.Table, st.Cursor = store.EmbeddingMigrationCompleted, "", ""
+		if saveErr := m.saveActive(ctx, m.record); saveErr != nil {
+			st.State, st.Error = store.EmbeddingMigrationFailed, saveErr.Error()
+			break
+		}
+		m.migrating.Finish()
+		slog.Info("embedding: re-embedding complete", "model", st.To, "rows", st.Done)
+	}
+	m.persist(ctx)
+}
+
+func (m *Migrator) saveActive(ctx context.Context, rec store.EmbeddingModelRecord) error {
+	raw, _ := json.Marshal(rec)
+	if err := m.cfg.SystemConfigs.Set(ctx, store.EmbeddingActiveModelKey, string(raw)); err != nil {
+		return fmt.Errorf("embedding: save active model: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) loadState(ctx context.Context) *store.EmbeddingMigrationState {
+	raw, _ := m.cfg.SystemConfigs.Get(ctx, store.EmbeddingMigrationKey)
+	if raw == "" {
+		return nil
+	}
+	var st store.EmbeddingMigrationState
+	if json.Unmarshal([]byte(raw), &st) != nil {
+		return nil
+	}
+	return &st
+}
+
+// persist saves and broadcasts the state. Caller holds m.mu.
+func (m *Migrator) persist(ctx context.Context) {
+	raw, _ := json.Marshal(m.state)
+	if err := m.cfg.SystemConfigs.Set(context.WithoutCancel(ctx), store.EmbeddingMigrationKey, string(raw)); err != nil {
+		slog.Warn("embedding: save migration state failed", "error", err)
+	}
+	if m.cfg.Publisher != nil {
+		cp := *m.state
+		cp.Tables = append([]store.EmbeddingIndexStats(nil), m.state.Tables...)
+		m.cfg.Publisher.Broadcast(bus.Event{Name: protocol.EventEmbeddingMigration, Payload: cp})
+	}
+}
@@ -0,0 +1,240 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+type memConfigs struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func (c *memConfigs) Get(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.m[key], nil
+}
+
+func (c *memConfigs) Set(_ context.Context, key, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = value
+	return nil
+}
+
+func (c *memConfigs) Delete(_ context.Context, key string) error { return nil }
+func (c *memConfigs) List(context.Context) (map[string]string, error) {
+	return nil, nil
+}
+
+// memIndex keeps one model tag per row; IDs sort lexically.
+type memIndex struct {
+	mu     sync.Mutex
+	rows   map[string]map[string]string // table → id → tag ("" = untagged)
+	fail   int                          // next ReembedBatch calls to fail
+	stopAt int                          // call number that runs onStop instead
+	onStop func()
+	calls  int
+}
+
+func (x *memIndex) Tables() []string { return []string{"skills", "memory_chunks"} }
+
+func (x *memIndex) Stats(_ context.Context, tag string) ([]store.EmbeddingIndexStats, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var out []store.EmbeddingIndexStats
+	for _, t := range x.Tables() {
+		st := store.EmbeddingIndexStats{Table: t}
+		for _, v := range x.rows[t] {
+			st.Embedded++
+			if v == tag {
+				st.Current++
+			} else {
+				st.Stale++
+			}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (x *memIndex) AdoptUntagged(_ context.Context, tag string) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	n := 0
+	for _, rows := range x.rows {
+		for id, v := range rows {
+			if v == "" {
+				rows[id] = tag
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func (x *memIndex) ReembedBatch(ctx context.Context, table, cursor string, p store.EmbeddingProvider, limit int) (int, string, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.calls++
+	if x.fail > 0 {
+		x.fail--
+		return 0, "", errors.New("provider down")
+	}
+	if x.onStop != nil && x.calls == x.stopAt {
+		x.onStop()
+		return 0, "", ctx.Err()
+	}
+	tag := store.EmbeddingModelTag(p.Model(), store.RequiredMemoryEmbeddingDimensions)
+	var ids []string
+	for id, v := range x.rows[table] {
+		if id > cursor && v != tag {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	for _, id := range ids {
+		x.rows[table][id] = tag
+	}
+	if len(ids) < limit {
+		return len(ids), "", nil
+	}
+	return len(ids), ids[len(ids)-1], nil
+}
+
+type stubEmbedder struct{ model string }
+
+func (s stubEmbedder) Name() string  { return "stub" }
+func (s stubEmbedder) Model() string { return s.model }
+func (s stubEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	return make([][]float32, len(texts)), nil
+}
+
+func record(model string) store.EmbeddingModelRecord {
+	return store.EmbeddingModelRecord{Provider: "stub", Model: model, Dims: store.RequiredMemoryEmbeddingDimensions}
+}
+
+func waitState(t *testing.T, m *Migrator, want string) Status {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if st := m.Status(); st.Migration != nil && st.Migration.State == want {
+			return st
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("migration never reached %q: %+v", want, m.Status().Migration)
+	return Status{}
+}
+
+func TestMigrator_FirstRunAdoptsUntagged(t *testing.T) {
+	idx := &memIndex{rows: map[string]map[string]string{"skills": {"a": ""}, "memory_chunks": {"b": ""}}}
+	cfgs := &memConfigs{m: map[string]string{}}
+	m := New(Config{Index: idx, SystemConfigs: cfgs})
+
+	p, err := m.Init(context.Background(), stubEmbedder{"small"}, record("small"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.(*store.MigratingEmbeddingProvider); ok {
+		t.Error("first run should not start a migration")
+	}
+	if idx.rows["skills"]["a"] != "small@1536" || cfgs.m[store.EmbeddingActiveModelKey] == "" {
+		t.Errorf("untagged rows not adopted: %v, active=%q", idx.rows, cfgs.m[store.EmbeddingActiveModelKey])
+	}
+	if err := m.Start(); !errors.Is(err, ErrNoMigration) {
+		t.Errorf("Start without mismatch = %v", err)
+	}
+}
+
+func TestMigrator_ReembedsAllTablesAndFinishesDualRead(t *testing.T) {
+	idx := &memIndex{rows: map[string]map[string]string{
+		"skills":        {"s1": "", "s2": "old@1536"},
+		"memory_chunks": {"c1": "old@1536", "c2": "old@1536", "c3": "old@1536", "c4": "new@1536"},
+	}, fail: 1}
+	cfgs := &memConfigs{m: map[string]string{store.EmbeddingActiveModelKey: `{"provider":"stub","model":"old","dims":1536}`}}
+	m := New(Config{Index: idx, SystemConfigs: cfgs, BatchSize: 2, RetryBackoff: time.Millisecond})
+
+	var prevAsked store.EmbeddingModelRecord
+	p, err := m.Init(context.Background(), stubEmbedder{"new"}, record("new"), func(r store.EmbeddingModelRecord) store.EmbeddingProvider {
+		prevAsked = r
+		return stubEmbedder{r.Model}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp, ok := p.(*store.MigratingEmbeddingProvider)
+	if !ok || !mp.Migrating() || prevAsked.Model != "old" {
+		t.Fatalf("provider = %T (prev %+v), want migrating from old", p, prevAsked)
+	}
+	if prev, tag := mp.Previous(); prev == nil || tag != "old@1536" {
+		t.Errorf("Previous = %v, %q", prev, tag)
+	}
+	st := m.Status()
+	if st.Migration.State != store.EmbeddingMigrationPending || st.Migration.Total != 5 {
+		t.Fatalf("pending state = %+v", st.Migration)
+	}
+
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	st = waitState(t, m, store.EmbeddingMigrationCompleted)
+	if st.Migration.Done != 5 || st.Migrating || mp.Migrating() {
+		t.Errorf("completed status = %+v", st)
+	}
+	for table, rows := range idx.rows {
+		for id, tag := range rows {
+			if tag != "new@1536" {
+				t.Errorf("%s/%s still tagged %q", table, id, tag)
+			}
+		}
+	}
+	if cfgs.m[store.EmbeddingActiveModelKey] != `{"provider":"stub","model":"new","dims":1536}` {
+		t.Errorf("active model = %s", cfgs.m[store.EmbeddingActiveModelKey])
+	}
+}
+
+func TestMigrator_ResumesInterruptedRun(t *testing.T) {
+	idx := &memIndex{rows: map[string]map[string]string{
+		"skills":        {},
+		"memory_chunks": {"c1": "old@1536", "c2": "old@1536", "c3": "old@1536"},
+	}}
+	cfgs := &memConfigs{m: map[string]string{store.EmbeddingActiveModelKey: `{"provider":"stub","model":"old","dims":1536}`}}
+
+	m := New(Config{Index: idx, SystemConfigs: cfgs, BatchSize: 1})
+	if _, err := m.Init(context.Background(), stubEmbedder{"new"}, record("new"), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Shut down during the second memory_chunks batch.
+	idx.stopAt = 3
+	idx.onStop = func() {
+		m.mu.Lock()
+		m.cancel()
+		m.mu.Unlock()
+	}
+	_ = m.Start()
+	m.wg.Wait()
+	if st := m.Status().Migration; st.State != store.EmbeddingMigrationRunning || st.Cursor != "c1" || st.Done != 1 {
+		t.Fatalf("interrupted state = %+v", st)
+	}
+
+	// A new gateway picks up the persisted run and finishes it.
+	idx.onStop = nil
+	m2 := New(Config{Index: idx, SystemConfigs: cfgs, BatchSize: 1})
+	if _, err := m2.Init(context.Background(), stubEmbedder{"new"}, record("new"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if st := waitState(t, m2, store.EmbeddingMigrationCompleted); st.Migration.Done != 3 {
+		t.Errorf("resumed run done = %d, want 3", st.Migration.Done)
+	}
+}
@@ -153,7 +153,8 @@ func isAdminOnlyEvent(name string) bool {
 		protocol.EventDevicePairReq, protocol.EventDevicePairRes,
 		protocol.EventAgentLinkCreated, protocol.EventAgentLinkUpdated, protocol.EventAgentLinkDeleted,
 		protocol.EventWorkspaceFileChanged,
-		protocol.EventBackgroundError:
+		protocol.EventBackgroundError, protocol.EventEmbeddingMigration,
+		protocol.EventChannelMessageStatus:
 		return true
 	}
 	return false
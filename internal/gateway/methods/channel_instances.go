@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/channels"
 	"github.com/nextlevelbuilder/goclaw/internal/gateway"
 	"github.com/nextlevelbuilder/goclaw/internal/i18n"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
@@ -134,6 +135,11 @@ func (m *ChannelInstancesMethods) handleCreate(ctx context.Context, client *gate
 		return
 	}
 
+	if err := channels.ValidateRoutingConfig(params.Config); err != nil {
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidRequest, err.Error())))
+		return
+	}
+
 	enabled := true
 	if params.Enabled != nil {
 		enabled = *params.Enabled
@@ -192,6 +198,14 @@ func (m *ChannelInstancesMethods) handleUpdate(ctx context.Context, client *gate
 		}
 	}
 
+	if cfg, ok := updates["config"].(map[string]any); ok {
+		cfgJSON, _ := json.Marshal(cfg)
+		if err := channels.ValidateRoutingConfig(cfgJSON); err != nil {
+			client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidRequest, err.Error())))
+			return
+		}
+	}
+
 	if err := m.store.Update(ctx, id, updates); err != nil {
 		slog.Error("channels.instances.update", "error", err)
 		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInternal, i18n.T(locale, i18n.MsgFailedToUpdate, "instance", err.Error())))
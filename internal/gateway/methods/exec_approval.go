@@ -38,18 +38,22 @@ func (m *ExecApprovalMethods) handleList(_ context.Context, client *gateway.Clie
 	pending := m.manager.ListPending()
 
 	type pendingInfo struct {
-		ID        string `json:"id"`
-		Command   string `json:"command"`
-		AgentID   string `json:"agentId"`
-		CreatedAt int64  `json:"createdAt"`
+		ID        string                `json:"id"`
+		Kind      string                `json:"kind"`
+		Command   string                `json:"command"`
+		AgentID   string                `json:"agentId"`
+		Origin    *tools.ApprovalOrigin `json:"origin,omitempty"`
+		CreatedAt int64                 `json:"createdAt"`
 	}
 
 	items := make([]pendingInfo, 0, len(pending))
 	for _, pa := range pending {
 		items = append(items, pendingInfo{
 			ID:        pa.ID,
+			Kind:      pa.Kind,
 			Command:   pa.Command,
 			AgentID:   pa.AgentID,
+			Origin:    pa.Origin,
 			CreatedAt: pa.CreatedAt.UnixMilli(),
 		})
 	}
@@ -0,0 +1,137 @@
+package methods
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/gateway"
+	"github.com/nextlevelbuilder/goclaw/internal/i18n"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// OutboxMethods handles outbox.dead.list, outbox.dead.replay, outbox.dead.delete.
+type OutboxMethods struct {
+	outbox   store.OutboxStore
+	eventBus bus.EventPublisher
+}
+
+// NewOutboxMethods creates a new dead-letter queue method handler.
+func NewOutboxMethods(outbox store.OutboxStore, eventBus bus.EventPublisher) *OutboxMethods {
+	return &OutboxMethods{outbox: outbox, eventBus: eventBus}
+}
+
+// Register registers dead-letter queue RPC methods.
+func (m *OutboxMethods) Register(router *gateway.MethodRouter) {
+	router.Register(protocol.MethodOutboxDeadList, m.handleList)
+	router.Register(protocol.MethodOutboxDeadReplay, m.handleReplay)
+	router.Register(protocol.MethodOutboxDeadDelete, m.handleDelete)
+}
+
+// tenantFilter returns uuid.Nil (all tenants) for master scope, else the caller's tenant.
+func (m *OutboxMethods) tenantFilter(ctx context.Context) uuid.UUID {
+	if store.IsMasterScope(ctx) {
+		return uuid.Nil
+	}
+	return store.TenantIDFromContext(ctx)
+}
+
+func (m *OutboxMethods) handleList(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame) {
+	locale := store.LocaleFromContext(ctx)
+	var params struct {
+		Channel string `json:"channel"`
+		Limit   int    `json:"limit"`
+		Offset  int    `json:"offset"`
+	}
+	if req.Params != nil {
+		json.Unmarshal(req.Params, &params)
+	}
+	if params.Limit <= 0 || params.Limit > 200 {
+		params.Limit = 50
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	dead, err := m.outbox.ListDeadLetters(ctx, m.tenantFilter(ctx), params.Channel, params.Limit, params.Offset)
+	if err != nil {
+		slog.Error("outbox.dead.list failed", "error", err)
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInternal, i18n.T(locale, i18n.MsgFailedToList, "dead letters")))
+		return
+	}
+	if dead == nil {
+		dead = []store.OutboxDeadLetter{}
+	}
+	client.SendResponse(protocol.NewOKResponse(req.ID, map[string]any{
+		"deadLetters": dead,
+	}))
+}
+
+func (m *OutboxMethods) handleReplay(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame) {
+	dl, ok := m.load(ctx, client, req)
+	if !ok {
+		return
+	}
+	if err := m.outbox.ReplayDeadLetter(ctx, dl.ID); err != nil {
+		m.sendStoreError(ctx, client, req, dl.ID, err)
+		return
+	}
+	emitAudit(m.eventBus, client, "outbox.replayed", "outbox_message", dl.ID.String())
+	client.SendResponse(protocol.NewOKResponse(req.ID, map[string]any{"status": "queued"}))
+}
+
+func (m *OutboxMethods) handleDelete(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame) {
+	dl, ok := m.load(ctx, client, req)
+	if !ok {
+		return
+	}
+	if err := m.outbox.DeleteDeadLetter(ctx, dl.ID); err != nil {
+		m.sendStoreError(ctx, client, req, dl.ID, err)
+		return
+	}
+	emitAudit(m.eventBus, client, "outbox.deleted", "outbox_message", dl.ID.String())
+	client.SendResponse(protocol.NewOKResponse(req.ID, map[string]any{"status": "deleted"}))
+}
+
+// load resolves params.id to a dead letter visible to the caller's tenant.
+func (m *OutboxMethods) load(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame) (*store.OutboxDeadLetter, bool) {
+	locale := store.LocaleFromContext(ctx)
+	var params struct {
+		ID string `json:"id"`
+	}
+	if req.Params != nil {
+		json.Unmarshal(req.Params, &params)
+	}
+	id, err := uuid.Parse(params.ID)
+	if err != nil {
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidID, "dead letter")))
+		return nil, false
+	}
+	dl, err := m.outbox.GetDeadLetter(ctx, id)
+	if err == nil {
+		if tid := m.tenantFilter(ctx); tid != uuid.Nil && dl.TenantID != tid {
+			err = sql.ErrNoRows
+		}
+	}
+	if err != nil {
+		m.sendStoreError(ctx, client, req, id, err)
+		return nil, false
+	}
+	return dl, true
+}
+
+func (m *OutboxMethods) sendStoreError(ctx context.Context, client *gateway.Client, req *protocol.RequestFrame, id uuid.UUID, err error) {
+	locale := store.LocaleFromContext(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrNotFound, i18n.T(locale, i18n.MsgNotFound, "dead letter", id.String())))
+		return
+	}
+	slog.Error("outbox dead letter operation failed", "id", id, "error", err)
+	client.SendResponse(protocol.NewErrorResponse(req.ID, protocol.ErrInternal, i18n.T(locale, i18n.MsgInternalError, err.Error())))
+}
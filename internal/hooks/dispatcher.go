@@ -86,8 +86,15 @@ type StdDispatcherOpts struct {
 
 	// Now is injectable for tests that need deterministic circuit-breaker timing.
 	Now func() time.Time
+
+	// Approver resolves "ask" decisions. Nil = ask blocks.
+	Approver AskApprover
 }
 
+// AskApprover asks a human whether a hook's "ask" decision may proceed.
+// reason is the hook's explanation, if any. Blocks until decided.
+type AskApprover func(ctx context.Context, cfg HookConfig, ev Event, reason string) (bool, error)
+
 // NewStdDispatcher returns the production Dispatcher with circuit-breaker,
 // per-hook timeouts, and audit writing wired up.
 func NewStdDispatcher(opts StdDispatcherOpts) Dispatcher {
@@ -116,6 +123,7 @@ func NewStdDispatcher(opts StdDispatcherOpts) Dispatcher {
 		perTimeout:  opts.PerHookTimeout,
 		chainBudget: opts.ChainBudget,
 		now:         opts.Now,
+		approver:    opts.Approver,
 		cb: &circuitBreaker{
 			threshold: opts.CircuitThreshold,
 			window:    opts.CircuitWindow,
@@ -146,6 +154,7 @@ type stdDispatcher struct {
 	perTimeout  time.Duration
 	chainBudget time.Duration
 	now         func() time.Time
+	approver    AskApprover
 	cb          *circuitBreaker
 }
 
@@ -189,7 +198,7 @@ func (d *stdDispatcher) Fire(ctx context.Context, ev Event) (FireResult, error)
 // dropped + logged — source tier enforced at the dispatcher.
 func (d *stdDispatcher) runSync(ctx context.Context, ev Event, chain []HookConfig) (FireResult, error) {
 	chainCtx, cancel := context.WithTimeout(ctx, d.chainBudget)
-	defer cancel()
+	defer func() { cancel() }()
 
 	evMut := ev
 	if ev.ToolInput != nil {
@@ -216,6 +225,20 @@ func (d *stdDispatcher) runSync(ctx context.Context, ev Event, chain []HookConfi
 		hctx := WithScriptResult(chainCtx, scriptRes)
 
 		dec, execErr, duration := d.runOne(hctx, cfg, evMut)
+		if dec == DecisionAsk {
+			approved := d.ask(ctx, cfg, evMut, scriptRes.Reason)
+			// Time spent waiting for a human doesn't count against the chain budget.
+			cancel()
+			var renewed context.CancelFunc
+			chainCtx, renewed = context.WithTimeout(ctx, d.chainBudget)
+			cancel = renewed
+			if !approved {
+				// Not a hook failure: skip the circuit breaker.
+				d.writeExec(ctx, cfg, evMut, DecisionBlock, duration, "ask: not approved")
+				return FireResult{Decision: DecisionBlock}, nil
+			}
+			dec = DecisionAllow
+		}
 		errMsg := ""
 		if execErr != nil {
 			errMsg = execErr.Error()
@@ -271,6 +294,21 @@ func (d *stdDispatcher) runSync(ctx context.Context, ev Event, chain []HookConfi
 	return result, nil
 }
 
+// ask resolves a hook's "ask" decision through the approver. Fails closed
+// when no approver is configured or the request errors (e.g. times out).
+func (d *stdDispatcher) ask(ctx context.Context, cfg HookConfig, ev Event, reason string) bool {
+	if d.approver == nil {
+		slog.Warn("hooks.ask_no_approver", "hook_id", cfg.ID, "event_id", ev.EventID)
+		return false
+	}
+	approved, err := d.approver(ctx, cfg, ev, reason)
+	if err != nil {
+		slog.Warn("hooks.ask_failed", "hook_id", cfg.ID, "event_id", ev.EventID, "err", err)
+		return false
+	}
+	return approved
+}
+
 // cloneMap returns a shallow copy of m. Used so runSync's mutations on the
 // local evMut don't leak back to the caller's event when a downstream hook
 // blocks or the chain aborts.
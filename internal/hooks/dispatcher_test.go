@@ -333,6 +333,54 @@ func TestDispatcher_AllAllow_ReturnsAllow(t *testing.T) {
 	}
 }
 
+func TestDispatcher_Ask_ResolvedByApprover(t *testing.T) {
+	cases := []struct {
+		name     string
+		approver hooks.AskApprover
+		want     hooks.Decision
+	}{
+		{"no approver blocks", nil, hooks.DecisionBlock},
+		{"denied blocks", func(context.Context, hooks.HookConfig, hooks.Event, string) (bool, error) {
+			return false, nil
+		}, hooks.DecisionBlock},
+		{"approver error blocks", func(context.Context, hooks.HookConfig, hooks.Event, string) (bool, error) {
+			return true, errors.New("approval timed out")
+		}, hooks.DecisionBlock},
+		// Waiting longer than the chain budget must not fail the chain closed.
+		{"approved after chain budget allows", func(context.Context, hooks.HookConfig, hooks.Event, string) (bool, error) {
+			time.Sleep(150 * time.Millisecond)
+			return true, nil
+		}, hooks.DecisionAllow},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ask := newBaseHook(hooks.HandlerHTTP, hooks.EventPreToolUse)
+			fs := &fakeStore{hooks: []hooks.HookConfig{ask}}
+			d := hooks.NewStdDispatcher(hooks.StdDispatcherOpts{
+				Store:       fs,
+				Audit:       hooks.NewAuditWriter(fs, ""),
+				Handlers:    map[hooks.HandlerType]hooks.Handler{hooks.HandlerHTTP: &fakeHandler{decision: hooks.DecisionAsk}},
+				ChainBudget: 50 * time.Millisecond,
+				Approver:    tc.approver,
+			})
+			r, _ := d.Fire(context.Background(), hooks.Event{
+				EventID:   "e-ask",
+				HookEvent: hooks.EventPreToolUse,
+			})
+			if r.Decision != tc.want {
+				t.Errorf("decision=%q, want %q", r.Decision, tc.want)
+			}
+			execs := fs.snapshotExecs()
+			if len(execs) != 1 || execs[0].Decision != tc.want {
+				t.Errorf("exec rows=%+v, want one %q row", execs, tc.want)
+			}
+			if len(fs.snapshotUpdates()) != 0 {
+				t.Error("ask outcome must not feed the circuit breaker")
+			}
+		})
+	}
+}
+
 func TestDispatcher_ResolveError_FailsClosed(t *testing.T) {
 	fs := &fakeStore{resolveE: errors.New("db down")}
 	d := hooks.NewStdDispatcher(hooks.StdDispatcherOpts{
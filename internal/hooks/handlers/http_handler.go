@@ -139,6 +139,8 @@ func (h *HTTPHandler) doRequest(ctx context.Context, client *http.Client, urlStr
 		switch hooks.Decision(*hr.Decision) {
 		case hooks.DecisionBlock:
 			return hooks.DecisionBlock, nil
+		case hooks.DecisionAsk:
+			return hooks.DecisionAsk, nil
 		default:
 			return hooks.DecisionAllow, nil
 		}
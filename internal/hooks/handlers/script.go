@@ -192,9 +192,10 @@ func (h *ScriptHandler) Execute(ctx context.Context, cfg hooks.HookConfig, ev ho
 		return hooks.DecisionError, parseErr
 	}
 
-	// Wave 1 reserves ask/defer but does not implement them. Treat as block +
-	// warn so operators can see that a hook wants human/external arbitration.
-	if dec == hooks.DecisionAsk || dec == hooks.DecisionDefer {
+	// "ask" goes to the dispatcher, which asks a human. Defer is reserved but
+	// not implemented: treat as block + warn so operators can see that a hook
+	// wants external arbitration.
+	if dec == hooks.DecisionDefer {
 		slog.Warn("hooks.decision_not_yet_implemented",
 			"hook_id", cfg.ID, "decision", string(dec))
 		dec = hooks.DecisionBlock
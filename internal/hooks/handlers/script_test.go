@@ -155,23 +155,24 @@ func TestPanicInsideHandle(t *testing.T) {
 	}
 }
 
-// TestAskDecisionBlocksInWave1 verifies ask/defer map to block.
-func TestAskDecisionBlocksInWave1(t *testing.T) {
+// TestAskDecisionPassesThrough verifies ask reaches the dispatcher, which
+// arbitrates it through its approver.
+func TestAskDecisionPassesThrough(t *testing.T) {
 	src := `function handle(event) { return {decision: "ask", reason: "pls"}; }`
 	h := newTestHandler()
 	dec, err, res := runWithResult(t, h, mkCfg(src), mkEvent(), 500*time.Millisecond)
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
-	if dec != hooks.DecisionBlock {
-		t.Fatalf("decision: got %v, want block (ask→block in Wave 1)", dec)
+	if dec != hooks.DecisionAsk {
+		t.Fatalf("decision: got %v, want ask", dec)
 	}
 	if res.Reason != "pls" {
 		t.Fatalf("reason lost: %q", res.Reason)
 	}
 }
 
-// TestDeferDecisionBlocksInWave1 mirror of the ask case.
+// TestDeferDecisionBlocksInWave1 verifies defer maps to block.
 func TestDeferDecisionBlocksInWave1(t *testing.T) {
 	src := `function handle(event) { return {decision: "defer"}; }`
 	h := newTestHandler()
@@ -122,7 +122,8 @@ const (
 	DecisionError Decision = "error"
 	// DecisionTimeout indicates the hook did not respond within the time budget.
 	DecisionTimeout Decision = "timeout"
-	// DecisionAsk requests human approval before proceeding. Wave 1: treated as block + warn.
+	// DecisionAsk requests human approval before proceeding. The dispatcher
+	// asks its AskApprover; without one (or when denied) it blocks.
 	DecisionAsk Decision = "ask"
 	// DecisionDefer defers the decision to an external system. Wave 1: treated as block + warn.
 	DecisionDefer Decision = "defer"
@@ -219,4 +220,9 @@ type Event struct {
 	Depth     int
 	// HookEvent is the lifecycle event type.
 	HookEvent HookEvent
+	// Channel, ChatID and PeerKind identify the chat the run came from.
+	// Used to deliver "ask" decisions for approval; empty outside channels.
+	Channel  string
+	ChatID   string
+	PeerKind string
 }
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/i18n"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+const (
+	maxContactTags   = 32
+	maxContactTagLen = 64
+)
+
+// handleGetContactTags returns a contact's tags (used by channel routing rules).
+// GET /v1/contacts/{id}/tags
+func (h *ChannelInstancesHandler) handleGetContactTags(w http.ResponseWriter, r *http.Request) {
+	locale := store.LocaleFromContext(r.Context())
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidID, "contact")})
+		return
+	}
+	c, err := h.contactStore.GetContactByID(r.Context(), id)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "contact", id.String())})
+		return
+	}
+	tags, err := h.contactStore.GetContactTags(r.Context(), c.ChannelType, c.SenderID)
+	if err != nil {
+		slog.Error("contacts.tags.get", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": i18n.T(locale, i18n.MsgFailedToList, "contact tags")})
+		return
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// handleSetContactTags replaces a contact's tags.
+// PUT /v1/contacts/{id}/tags
+func (h *ChannelInstancesHandler) handleSetContactTags(w http.ResponseWriter, r *http.Request) {
+	locale := store.LocaleFromContext(r.Context())
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidID, "contact")})
+		return
+	}
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgInvalidJSON)})
+		return
+	}
+	tags := normalizeContactTags(body.Tags)
+
+	if err := h.contactStore.SetContactTags(r.Context(), id, tags); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": i18n.T(locale, i18n.MsgNotFound, "contact", id.String())})
+			return
+		}
+		slog.Error("contacts.tags.set", "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": i18n.T(locale, i18n.MsgFailedToUpdate, "contact tags", "internal error")})
+		return
+	}
+	emitAudit(h.msgBus, r, "contact.tags_updated", "contact", id.String())
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+// normalizeContactTags lowercases, trims and dedupes tags, dropping empty
+// and over-long ones.
+func normalizeContactTags(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, t := range in {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || len(t) > maxContactTagLen || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+		if len(out) == maxContactTags {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/bus"
+	"github.com/nextlevelbuilder/goclaw/internal/i18n"
+	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/pkg/protocol"
+)
+
+// OutboxHandler serves the outbound dead-letter queue: channel messages that
+// failed permanently or exhausted their retries. Admins can inspect, replay
+// or discard them. Tenant admins only see their own tenant's messages.
+type OutboxHandler struct {
+	outbox store.OutboxStore
+	msgBus *bus.MessageBus // for audit events
+}
+
+// NewOutboxHandler creates a handler for dead-letter queue endpoints.
+func NewOutboxHandler(outbox store.OutboxStore, msgBus *bus.MessageBus) *OutboxHandler {
+	return &OutboxHandler{outbox: outbox, msgBus: msgBus}
+}
+
+// RegisterRoutes registers dead-letter queue routes on the given mux.
+func (h *OutboxHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/outbox/dead", h.adminAuth(h.handleList))
+	mux.HandleFunc("GET /v1/outbox/dead/{id}", h.adminAuth(h.handleGet))
+	mux.HandleFunc("POST /v1/outbox/dead/{id}/replay", h.adminAuth(h.handleReplay))
+	mux.HandleFunc("DELETE /v1/outbox/dead/{id}", h.adminAuth(h.handleDelete))
+}
+
+func (h *OutboxHandler) adminAuth(next http.HandlerFunc) http.HandlerFunc {
+	return requireAuth(permissions.RoleAdmin, next)
+}
+
+// tenantFilter returns the tenant whose dead letters the caller may see;
+// uuid.Nil (all tenants) for master scope.
+func (h *OutboxHandler) tenantFilter(r *http.Request) uuid.UUID {
+	if store.IsMasterScope(r.Context()) {
+		return uuid.Nil
+	}
+	return store.TenantIDFromContext(r.Context())
+}
+
+func (h *OutboxHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	locale := extractLocale(r)
+	limit, offset := 50, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	dead, err := h.outbox.ListDeadLetters(r.Context(), h.tenantFilter(r), r.URL.Query().Get("channel"), limit, offset)
+	if err != nil {
+		slog.Error("outbox.list_dead failed", "error", err)
+		writeError(w, http.StatusInternalServerError, protocol.ErrInternal, i18n.T(locale, i18n.MsgFailedToList, "dead letters"))
+		return
+	}
+	if dead == nil {
+		dead = []store.OutboxDeadLetter{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"dead_letters": dead,
+		"limit":        limit,
+		"offset":       offset,
+	})
+}
+
+func (h *OutboxHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	if dl, ok := h.load(w, r); ok {
+		writeJSON(w, http.StatusOK, dl)
+	}
+}
+
+func (h *OutboxHandler) handleReplay(w http.ResponseWriter, r *http.Request) {
+	dl, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := h.outbox.ReplayDeadLetter(r.Context(), dl.ID); err != nil {
+		h.writeStoreError(w, r, dl.ID, err)
+		return
+	}
+	emitAudit(h.msgBus, r, "outbox.replayed", "outbox_message", dl.ID.String())
+	writeJSON(w, http.StatusOK, map[string]string{"status": "queued"})
+}
+
+func (h *OutboxHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	dl, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := h.outbox.DeleteDeadLetter(r.Context(), dl.ID); err != nil {
+		h.writeStoreError(w, r, dl.ID, err)
+		return
+	}
+	emitAudit(h.msgBus, r, "outbox.deleted", "outbox_message", dl.ID.String())
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// load resolves the {id} dead letter, answering 404 for other tenants' messages.
+func (h *OutboxHandler) load(w http.ResponseWriter, r *http.Request) (*store.OutboxDeadLetter, bool) {
+	locale := extractLocale(r)
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, protocol.ErrInvalidRequest, i18n.T(locale, i18n.MsgInvalidID, "dead letter"))
+		return nil, false
+	}
+	dl, err := h.outbox.GetDeadLetter(r.Context(), id)
+	if err == nil {
+		if tid := h.tenantFilter(r); tid != uuid.Nil && dl.TenantID != tid {
+			err = sql.ErrNoRows
+		}
+	}
+	if err != nil {
+		h.writeStoreError(w, r, id, err)
+		return nil, false
+	}
+	return dl, true
+}
+
+func (h *OutboxHandler) writeStoreError(w http.ResponseWriter, r *http.Request, id uuid.UUID, err error) {
+	locale := extractLocale(r)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, protocol.ErrNotFound, i18n.T(locale, i18n.MsgNotFound, "dead letter", id.String()))
+		return
+	}
+	slog.Error("outbox dead letter operation failed", "id", id, "error", err)
+	writeError(w, http.StatusInternalServerError, protocol.ErrInternal, i18n.T(locale, i18n.MsgInternalError, err.Error()))
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// handleProviderHealth returns the load balancer's view of every candidate
+// used by load-balanced agents: rolling p50/p95 latency, error rate,
+// rate-limit headroom and effective weight. Master scope sees all tenants.
+//
+//	GET /v1/providers/health
+//	Response: {"candidates": [{"provider": "openrouter", "model": "...", "p50_ms": 820, ...}]}
+func (h *ProvidersHandler) handleProviderHealth(w http.ResponseWriter, r *http.Request) {
+	candidates := []providers.CandidateHealth{}
+	if h.providerReg != nil {
+		scope := ""
+		if !store.IsMasterScope(r.Context()) {
+			scope = store.TenantIDFromContext(r.Context()).String()
+		}
+		candidates = h.providerReg.Balancer().Snapshot(scope)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"candidates": candidates})
+}
@@ -86,6 +86,29 @@ func (h *ProvidersHandler) handleListProviderModels(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// Bedrock lists foundation models via the control plane (separate SigV4 scope);
+	// return the common Converse-capable catalog instead.
+	if p.ProviderType == store.ProviderBedrock {
+		respond(withReasoningCapabilities(bedrockModels()))
+		return
+	}
+
+	// Replay lists the models seen in its cassette.
+	if p.ProviderType == store.ProviderReplay {
+		models := []ModelInfo{}
+		if h.providerReg != nil {
+			if prov, err := h.providerReg.GetForTenant(p.TenantID, p.Name); err == nil {
+				if rp, ok := prov.(*providers.ReplayProvider); ok {
+					for _, m := range rp.Models() {
+						models = append(models, ModelInfo{ID: m})
+					}
+				}
+			}
+		}
+		respond(models)
+		return
+	}
+
 	if p.APIKey == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": i18n.T(locale, i18n.MsgRequired, "API key")})
 		return
@@ -107,6 +130,10 @@ func (h *ProvidersHandler) handleListProviderModels(w http.ResponseWriter, r *ht
 		models = dashScopeModels()
 	case "minimax_native":
 		models = minimaxModels()
+	case store.ProviderAzureOpenAI:
+		models = azureOpenAIModels(store.ParseAzureOpenAISettings(p.Settings))
+	case store.ProviderVertexAI:
+		models = vertexModels()
 	default:
 		// All other types use OpenAI-compatible /models endpoint
 		apiBase := strings.TrimRight(h.resolveAPIBase(p), "/")
@@ -1,5 +1,12 @@
 package http
 
+import (
+	"maps"
+	"slices"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
 // bailianModels returns a hardcoded list of models available on the
 // Bailian Coding platform (coding-intl.dashscope.aliyuncs.com).
 // The platform does not expose a /v1/models endpoint.
@@ -63,6 +70,64 @@ func dashScopeModels() []ModelInfo {
 	}
 }
 
+// bedrockModels returns common Converse-capable Bedrock model IDs.
+// Cross-region inference profiles (us./eu./apac. prefixes) are also accepted.
+func bedrockModels() []ModelInfo {
+	return []ModelInfo{
+		{ID: "anthropic.claude-opus-4-1-20250805-v1:0", Name: "Claude Opus 4.1"},
+		{ID: "anthropic.claude-sonnet-4-5-20250929-v1:0", Name: "Claude Sonnet 4.5"},
+		{ID: "anthropic.claude-sonnet-4-20250514-v1:0", Name: "Claude Sonnet 4"},
+		{ID: "anthropic.claude-haiku-4-5-20251001-v1:0", Name: "Claude Haiku 4.5"},
+		{ID: "amazon.nova-pro-v1:0", Name: "Amazon Nova Pro"},
+		{ID: "amazon.nova-lite-v1:0", Name: "Amazon Nova Lite"},
+		{ID: "meta.llama3-3-70b-instruct-v1:0", Name: "Llama 3.3 70B Instruct"},
+		{ID: "meta.llama4-maverick-17b-instruct-v1:0", Name: "Llama 4 Maverick 17B"},
+		{ID: "mistral.mistral-large-2407-v1:0", Name: "Mistral Large (24.07)"},
+	}
+}
+
+// vertexModels returns Gemini models served by Vertex AI publisher endpoints.
+// The publisher model list requires a separate Model Garden API, so it is hardcoded.
+func vertexModels() []ModelInfo {
+	return []ModelInfo{
+		{ID: "gemini-2.5-pro", Name: "Gemini 2.5 Pro"},
+		{ID: "gemini-2.5-flash", Name: "Gemini 2.5 Flash"},
+		{ID: "gemini-2.5-flash-lite", Name: "Gemini 2.5 Flash-Lite"},
+		{ID: "gemini-2.0-flash", Name: "Gemini 2.0 Flash"},
+		{ID: "gemini-2.0-flash-lite", Name: "Gemini 2.0 Flash-Lite"},
+	}
+}
+
+// azureOpenAIModels returns the models mapped to deployments in provider settings,
+// followed by common Azure OpenAI models (usable when the deployment shares the model name).
+// Listing actual deployments requires the ARM management plane, not the data-plane key.
+func azureOpenAIModels(settings *store.AzureOpenAISettings) []ModelInfo {
+	common := []ModelInfo{
+		{ID: "gpt-4.1", Name: "GPT-4.1"},
+		{ID: "gpt-4.1-mini", Name: "GPT-4.1 Mini"},
+		{ID: "gpt-4o", Name: "GPT-4o"},
+		{ID: "gpt-4o-mini", Name: "GPT-4o Mini"},
+		{ID: "o4-mini", Name: "o4-mini"},
+		{ID: "o3", Name: "o3"},
+		{ID: "o3-mini", Name: "o3-mini"},
+	}
+	if settings == nil || len(settings.Deployments) == 0 {
+		return common
+	}
+	models := make([]ModelInfo, 0, len(settings.Deployments)+len(common))
+	seen := make(map[string]bool, len(settings.Deployments))
+	for _, model := range slices.Sorted(maps.Keys(settings.Deployments)) {
+		seen[model] = true
+		models = append(models, ModelInfo{ID: model, Name: model + " (" + settings.Deployments[model] + ")"})
+	}
+	for _, m := range common {
+		if !seen[m.ID] {
+			models = append(models, m)
+		}
+	}
+	return models
+}
+
 // claudeCLIModels returns the model aliases accepted by the Claude CLI.
 func claudeCLIModels() []ModelInfo {
 	return []ModelInfo{
@@ -96,10 +96,12 @@ func (h *ProvidersHandler) handleVerifyEmbedding(w http.ResponseWriter, r *http.
 	writeJSON(w, http.StatusOK, result)
 }
 
-// handleEmbeddingStatus returns the current embedding system configuration.
+// handleEmbeddingStatus returns the current embedding system configuration,
+// plus the stored-vector migration state when a migrator is wired.
 //
 //	GET /v1/embedding/status
-//	Response: {"configured": true, "provider": "openai", "model": "text-embedding-3-small"}
+//	Response: {"configured": true, "provider": "openai", "model": "text-embedding-3-small",
+//	           "migration": {"active_model": "...", "migrating": false, ...}}
 //	     or: {"configured": false}
 func (h *ProvidersHandler) handleEmbeddingStatus(w http.ResponseWriter, r *http.Request) {
 	// Primary: check system_configs for embedding.provider/model
@@ -110,7 +112,7 @@ func (h *ProvidersHandler) handleEmbeddingStatus(w http.ResponseWriter, r *http.
 			if model == "" {
 				model = "text-embedding-3-small"
 			}
-			writeJSON(w, http.StatusOK, map[string]any{
+			h.writeEmbeddingStatus(w, map[string]any{
 				"configured":    true,
 				"provider":      provName,
 				"provider_name": provName,
@@ -123,7 +125,7 @@ func (h *ProvidersHandler) handleEmbeddingStatus(w http.ResponseWriter, r *http.
 	// Fallback: check provider-level embedding settings
 	providerList, err := h.store.ListProviders(r.Context())
 	if err != nil {
-		writeJSON(w, http.StatusOK, map[string]any{"configured": false})
+		h.writeEmbeddingStatus(w, map[string]any{"configured": false})
 		return
 	}
 	for _, p := range providerList {
@@ -136,7 +138,7 @@ func (h *ProvidersHandler) handleEmbeddingStatus(w http.ResponseWriter, r *http.
 			if model == "" {
 				model = "text-embedding-3-small"
 			}
-			writeJSON(w, http.StatusOK, map[string]any{
+			h.writeEmbeddingStatus(w, map[string]any{
 				"configured":    true,
 				"provider":      p.DisplayName,
 				"provider_name": p.Name,
@@ -146,5 +148,35 @@ func (h *ProvidersHandler) handleEmbeddingStatus(w http.ResponseWriter, r *http.
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"configured": false})
+	h.writeEmbeddingStatus(w, map[string]any{"configured": false})
+}
+
+// writeEmbeddingStatus adds the migration state to an embedding status response.
+func (h *ProvidersHandler) writeEmbeddingStatus(w http.ResponseWriter, resp map[string]any) {
+	if h.embMigrator != nil {
+		resp["migration"] = h.embMigrator.Status()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleEmbeddingReembed starts re-embedding stored vectors with the active
+// embedding model, or restarts a failed run. Progress is reported by
+// GET /v1/embedding/status and the embedding.migration.progress WS event.
+//
+//	POST /v1/embedding/reembed
+//	Response: 202 {"active_model": "...", "migrating": true, "migration": {...}}
+//	     or: 409 {"error": "..."} when stored vectors already match
+func (h *ProvidersHandler) handleEmbeddingReembed(w http.ResponseWriter, r *http.Request) {
+	if !requireMasterScope(w, r) {
+		return
+	}
+	if h.embMigrator == nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "no embedding provider configured"})
+		return
+	}
+	if err := h.embMigrator.Start(); err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, h.embMigrator.Status())
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
 	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/embedding"
 	"github.com/nextlevelbuilder/goclaw/internal/i18n"
 	"github.com/nextlevelbuilder/goclaw/internal/oauth"
 	"github.com/nextlevelbuilder/goclaw/internal/permissions"
@@ -35,6 +37,7 @@ type ProvidersHandler struct {
 	sysConfigStore  store.SystemConfigStore
 	tracingStore    store.TracingStore   // optional: for provider-scoped pool activity
 	agents          store.AgentCRUDStore // optional: for provider pool activity agent lookup
+	embMigrator     *embedding.Migrator  // optional: embedding model migration status + trigger
 }
 
 // NewProvidersHandler creates a handler for provider management endpoints.
@@ -53,6 +56,11 @@ func (h *ProvidersHandler) SetSystemConfigStore(s store.SystemConfigStore) {
 	h.sysConfigStore = s
 }
 
+// SetEmbeddingMigrator sets the embedding model migrator for status and re-embedding.
+func (h *ProvidersHandler) SetEmbeddingMigrator(m *embedding.Migrator) {
+	h.embMigrator = m
+}
+
 // SetMCPServerLookup sets the per-agent MCP server lookup for Claude CLI providers.
 // Must be called before serving requests (not thread-safe).
 func (h *ProvidersHandler) SetMCPServerLookup(lookup providers.MCPServerLookup) {
@@ -127,8 +135,12 @@ func (h *ProvidersHandler) RegisterRoutes(mux *http.ServeMux) {
 	// Provider-scoped Codex pool activity monitor
 	mux.HandleFunc("GET /v1/providers/{id}/codex-pool-activity", h.auth(h.handleProviderCodexPoolActivity))
 
+	// Load balancer candidate health (latency, error rate, rate-limit headroom)
+	mux.HandleFunc("GET /v1/providers/health", h.auth(h.handleProviderHealth))
+
 	// Embedding system status
 	mux.HandleFunc("GET /v1/embedding/status", h.auth(h.handleEmbeddingStatus))
+	mux.HandleFunc("POST /v1/embedding/reembed", h.auth(h.handleEmbeddingReembed))
 
 	// Claude CLI auth status (global — not per-provider)
 	mux.HandleFunc("GET /v1/providers/claude-cli/auth-status", h.auth(h.handleClaudeCLIAuthStatus))
@@ -183,6 +195,34 @@ func (h *ProvidersHandler) registerInMemory(p *store.LLMProviderData) {
 		h.providerReg.RegisterForTenant(p.TenantID, providers.NewOpenAIProvider(p.Name, "ollama", config.DockerLocalhost(host), "llama3.3"))
 		return
 	}
+	// Bedrock may use the AWS default credential chain — no API key required.
+	if p.ProviderType == store.ProviderBedrock {
+		var region string
+		if bs := store.ParseBedrockSettings(p.Settings); bs != nil {
+			region = bs.Region
+		}
+		prov, err := providers.NewBedrockProviderFromKey(context.Background(), p.Name, region, h.resolveAPIBase(p), p.APIKey)
+		if err != nil {
+			slog.Warn("providers.bedrock: failed to resolve credentials", "name", p.Name, "error", err)
+			return
+		}
+		h.providerReg.RegisterForTenant(p.TenantID, prov)
+		return
+	}
+	// Replay serves recorded cassettes — no API key required.
+	if p.ProviderType == store.ProviderReplay {
+		var mode string
+		if rs := store.ParseReplaySettings(p.Settings); rs != nil {
+			mode = rs.Mode
+		}
+		prov, err := providers.NewReplayProviderFromEnv(p.Name, p.APIBase, mode)
+		if err != nil {
+			slog.Warn("providers.replay: failed to load cassette", "name", p.Name, "error", err)
+			return
+		}
+		h.providerReg.RegisterForTenant(p.TenantID, prov)
+		return
+	}
 	if p.APIKey == "" {
 		return
 	}
@@ -198,6 +238,27 @@ func (h *ProvidersHandler) registerInMemory(p *store.LLMProviderData) {
 	case store.ProviderAnthropicNative:
 		h.providerReg.RegisterForTenant(p.TenantID, providers.NewAnthropicProvider(p.APIKey,
 			providers.WithAnthropicBaseURL(apiBase)))
+	case store.ProviderAzureOpenAI:
+		var opts []providers.AzureOpenAIOption
+		if as := store.ParseAzureOpenAISettings(p.Settings); as != nil {
+			opts = append(opts, providers.WithAzureAPIVersion(as.APIVersion), providers.WithAzureDeployments(as.Deployments))
+			if as.AuthMode == store.AzureAuthEntra {
+				// api_key holds the service principal's client secret in Entra mode.
+				opts = append(opts, providers.WithAzureTokenSource(providers.NewAzureEntraTokenSource(as.TenantID, as.ClientID, p.APIKey)))
+			}
+		}
+		h.providerReg.RegisterForTenant(p.TenantID, providers.NewAzureOpenAIProvider(p.Name, apiBase, p.APIKey, "", opts...))
+	case store.ProviderVertexAI:
+		var project, location string
+		if vs := store.ParseVertexSettings(p.Settings); vs != nil {
+			project, location = vs.ProjectID, vs.Location
+		}
+		prov, err := providers.NewVertexProviderFromKey(p.Name, project, location, apiBase, p.APIKey)
+		if err != nil {
+			slog.Warn("providers.vertex_ai: invalid service account key, skipping", "name", p.Name, "error", err)
+			return
+		}
+		h.providerReg.RegisterForTenant(p.TenantID, prov)
 	case store.ProviderDashScope:
 		h.providerReg.RegisterForTenant(p.TenantID, providers.NewDashScopeProvider(p.Name, p.APIKey, apiBase, ""))
 	case store.ProviderBailian:
@@ -243,6 +304,7 @@ var localProviderTypes = map[string]bool{
 	store.ProviderOllama:    true,
 	store.ProviderClaudeCLI: true,
 	store.ProviderACP:       true,
+	store.ProviderReplay:    true, // api_base is a cassette directory, not a URL
 }
 
 // validateProviderURL rejects provider base URLs pointing to internal/private networks.
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"fmt"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+)
+
+// chatResponseFormat is the OpenAI Chat Completions response_format object:
+//
+//	{"type":"json_schema","json_schema":{"name":"...","schema":{...},"strict":true}}
+type chatResponseFormat struct {
+	Type       string `json:"type"`
+	JSONSchema *struct {
+		Name   string         `json:"name"`
+		Schema map[string]any `json:"schema"`
+		Strict bool           `json:"strict,omitempty"`
+	} `json:"json_schema,omitempty"`
+}
+
+// responsesTextFormat is the Responses API text.format object, where the
+// json_schema fields are flattened: {"type":"json_schema","name":...,"schema":...}.
+type responsesTextFormat struct {
+	Type   string         `json:"type"`
+	Name   string         `json:"name,omitempty"`
+	Schema map[string]any `json:"schema,omitempty"`
+	Strict bool           `json:"strict,omitempty"`
+}
+
+// toProvider validates the request format and converts it for agent.RunRequest.
+// Returns nil for an absent or "text" format.
+func (f *chatResponseFormat) toProvider() (*providers.ResponseFormat, error) {
+	if f == nil {
+		return nil, nil
+	}
+	tf := responsesTextFormat{Type: f.Type}
+	if f.JSONSchema != nil {
+		tf.Name, tf.Schema, tf.Strict = f.JSONSchema.Name, f.JSONSchema.Schema, f.JSONSchema.Strict
+	}
+	return tf.toProvider()
+}
+
+func (f *responsesTextFormat) toProvider() (*providers.ResponseFormat, error) {
+	if f == nil {
+		return nil, nil
+	}
+	switch f.Type {
+	case "", providers.ResponseFormatText:
+		return nil, nil
+	case providers.ResponseFormatJSONObject:
+		return &providers.ResponseFormat{Type: providers.ResponseFormatJSONObject}, nil
+	case providers.ResponseFormatJSONSchema:
+		if len(f.Schema) == 0 {
+			return nil, fmt.Errorf("response_format.json_schema.schema is required")
+		}
+		return &providers.ResponseFormat{
+			Type:   providers.ResponseFormatJSONSchema,
+			Name:   f.Name,
+			Schema: f.Schema,
+			Strict: f.Strict,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported response_format type %q", f.Type)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/nextlevelbuilder/goclaw/internal/agent"
 	"github.com/nextlevelbuilder/goclaw/internal/permissions"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/sessions"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 	"github.com/nextlevelbuilder/goclaw/internal/tools"
@@ -41,6 +42,24 @@ type responsesRequest struct {
 	Messages  []chatMessage `json:"messages"`
 	Stream    bool          `json:"stream"`
 	MaxTokens int           `json:"max_tokens,omitempty"`
+
+	// Structured output: either the Chat Completions response_format shape or
+	// the Responses API text.format shape (response_format wins if both are set).
+	ResponseFormat *chatResponseFormat `json:"response_format,omitempty"`
+	Text           *struct {
+		Format *responsesTextFormat `json:"format,omitempty"`
+	} `json:"text,omitempty"`
+}
+
+// responseFormat resolves the structured-output format from either request shape.
+func (r *responsesRequest) responseFormat() (*providers.ResponseFormat, error) {
+	if r.ResponseFormat != nil {
+		return r.ResponseFormat.toProvider()
+	}
+	if r.Text != nil {
+		return r.Text.Format.toProvider()
+	}
+	return nil, nil
 }
 
 func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -77,6 +96,11 @@ func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"messages is required"}`, http.StatusBadRequest)
 		return
 	}
+	respFormat, err := req.responseFormat()
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 
 	agentID := extractAgentID(r, req.Model)
 	userID := store.UserIDFromContext(r.Context()) // resolved by enrichContext (respects API key owner binding)
@@ -110,13 +134,13 @@ func (h *ResponsesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	slog.Info("responses request", "agent", agentID, "stream", req.Stream, "user", userID)
 
 	if req.Stream {
-		h.handleStream(w, r, loop, runID, responseID, sessionKey, lastMessage, userID)
+		h.handleStream(w, r, loop, runID, responseID, sessionKey, lastMessage, userID, respFormat)
 	} else {
-		h.handleNonStream(w, r, loop, runID, responseID, sessionKey, lastMessage, userID)
+		h.handleNonStream(w, r, loop, runID, responseID, sessionKey, lastMessage, userID, respFormat)
 	}
 }
 
-func (h *ResponsesHandler) handleNonStream(w http.ResponseWriter, r *http.Request, loop agent.Agent, runID, responseID, sessionKey, message, userID string) {
+func (h *ResponsesHandler) handleNonStream(w http.ResponseWriter, r *http.Request, loop agent.Agent, runID, responseID, sessionKey, message, userID string, respFormat *providers.ResponseFormat) {
 	ctx, drainTeamDispatch := tools.InjectTeamDispatch(r.Context(), h.postTurn)
 	defer drainTeamDispatch()
 
@@ -128,6 +152,8 @@ func (h *ResponsesHandler) handleNonStream(w http.ResponseWriter, r *http.Reques
 		RunID:      runID,
 		UserID:     userID,
 		Stream:     false,
+
+		ResponseFormat: respFormat,
 	})
 
 	if err != nil {
@@ -157,7 +183,7 @@ func (h *ResponsesHandler) handleNonStream(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(resp)
 }
 
-func (h *ResponsesHandler) handleStream(w http.ResponseWriter, r *http.Request, loop agent.Agent, runID, responseID, sessionKey, message, userID string) {
+func (h *ResponsesHandler) handleStream(w http.ResponseWriter, r *http.Request, loop agent.Agent, runID, responseID, sessionKey, message, userID string, respFormat *providers.ResponseFormat) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
@@ -190,6 +216,8 @@ func (h *ResponsesHandler) handleStream(w http.ResponseWriter, r *http.Request,
 		RunID:      runID,
 		UserID:     userID,
 		Stream:     true,
+
+		ResponseFormat: respFormat,
 	})
 
 	if err != nil {
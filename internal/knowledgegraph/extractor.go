@@ -3,6 +3,7 @@ package knowledgegraph
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -58,9 +59,26 @@ func (e *Extractor) Extract(ctx context.Context, text string) (*ExtractionResult
 	return merged, nil
 }
 
-// extractChunk extracts entities from a single chunk of text.
-func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionResult, error) {
-	req := providers.ChatRequest{
+// Provider returns the provider extraction calls go to.
+func (e *Extractor) Provider() providers.Provider { return e.provider }
+
+// ExtractWith is Extract with calls going to p instead, e.g. a redacting
+// wrapper around Provider.
+func (e *Extractor) ExtractWith(ctx context.Context, p providers.Provider, text string) (*ExtractionResult, error) {
+	cp := *e
+	cp.provider = p
+	return cp.Extract(ctx, text)
+}
+
+// Request returns the single LLM request that extracts text, for callers that
+// run it elsewhere (batch APIs) and hand the response to ParseResponse.
+// Returns false when text is long enough to need several requests.
+func (e *Extractor) Request(text string) (providers.ChatRequest, bool) {
+	return e.chunkRequest(text), len(text) <= maxChunkChars
+}
+
+func (e *Extractor) chunkRequest(text string) providers.ChatRequest {
+	return providers.ChatRequest{
 		Messages: []providers.Message{
 			{Role: "system", Content: extractionSystemPrompt},
 			{Role: "user", Content: text},
@@ -70,9 +88,18 @@ func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionR
 			"max_tokens":  8192,
 			"temperature": 0.2,
 		},
+		ResponseFormat: providers.JSONSchemaFormat("knowledge_graph_extraction", extractionSchema),
 	}
+}
+
+// extractChunk extracts entities from a single chunk of text.
+func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionResult, error) {
+	req := e.chunkRequest(text)
 
-	resp, err := e.provider.Chat(ctx, req)
+	// ChatStructured validates against the schema; on persistent validation
+	// failure it still returns the last response, which the lenient parse below
+	// (code-fence strip + sanitizeJSON) gets a final chance at.
+	resp, err := e.chat(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("kg extraction LLM call: %w", err)
 	}
@@ -85,7 +112,7 @@ func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionR
 			text = text[:retryMaxChars] + "\n\n[...truncated]"
 		}
 		req.Messages[1].Content = text
-		resp, err = e.provider.Chat(ctx, req)
+		resp, err = e.chat(ctx, req)
 		if err != nil {
 			return nil, fmt.Errorf("kg extraction LLM retry: %w", err)
 		}
@@ -93,6 +120,15 @@ func (e *Extractor) extractChunk(ctx context.Context, text string) (*ExtractionR
 			return nil, fmt.Errorf("kg extraction: response still truncated after retry")
 		}
 	}
+	return e.ParseResponse(resp)
+}
+
+// ParseResponse decodes an extraction response and drops entities and
+// relations below the confidence threshold. A truncated response is an error.
+func (e *Extractor) ParseResponse(resp *providers.ChatResponse) (*ExtractionResult, error) {
+	if resp.FinishReason == "length" {
+		return nil, fmt.Errorf("kg extraction: response truncated")
+	}
 
 	// Parse JSON response
 	var result ExtractionResult
@@ -210,6 +246,16 @@ func isDigit(c byte) bool {
 	return c >= '0' && c <= '9'
 }
 
+// chat runs a structured-output request, tolerating schema-validation failures.
+func (e *Extractor) chat(ctx context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	resp, err := providers.ChatStructured(ctx, e.provider, req)
+	if errors.Is(err, providers.ErrStructuredOutput) {
+		slog.Debug("kg extraction: structured output failed validation", "error", err)
+		return resp, nil
+	}
+	return resp, err
+}
+
 // splitChunks splits text into chunks at paragraph boundaries (\n\n).
 func splitChunks(text string, maxChars int) []string {
 	if len(text) <= maxChars {
@@ -91,3 +91,43 @@ Output:
     {"source_entity_id": "migration-guide", "relation_type": "references", "target_entity_id": "goclaw-migration", "confidence": 1.0}
   ]
 }`
+
+// extractionSchema is the structured-output schema for extraction responses.
+// Entity and relation types are left as free strings (normalized after parsing)
+// so a slightly off-vocabulary label degrades gracefully instead of failing validation.
+var extractionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"entities": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"external_id": map[string]any{"type": "string"},
+					"name":        map[string]any{"type": "string"},
+					"entity_type": map[string]any{"type": "string"},
+					"description": map[string]any{"type": "string"},
+					"confidence":  map[string]any{"type": "number"},
+				},
+				"required":             []any{"external_id", "name", "entity_type", "description", "confidence"},
+				"additionalProperties": false,
+			},
+		},
+		"relations": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"source_entity_id": map[string]any{"type": "string"},
+					"relation_type":    map[string]any{"type": "string"},
+					"target_entity_id": map[string]any{"type": "string"},
+					"confidence":       map[string]any{"type": "number"},
+				},
+				"required":             []any{"source_entity_id", "relation_type", "target_entity_id", "confidence"},
+				"additionalProperties": false,
+			},
+		},
+	},
+	"required":             []any{"entities", "relations"},
+	"additionalProperties": false,
+}
@@ -0,0 +1,526 @@
+// Package llmbatch runs background-worker LLM requests through provider batch
+// APIs (Anthropic Message Batches, OpenAI Batch) at a discount.
+//
+// Workers opt in per request with TryEnqueue. Queued items are grouped per
+// tenant and provider, submitted as one provider batch, polled, and the
+// results are handed back to the worker's registered Handler. Items and jobs
+// are persisted in store.LLMBatchStore so in-flight batches survive restarts.
+package llmbatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/config"
+	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/safego"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tracing"
+)
+
+const (
+	defaultPollInterval = time.Minute
+	defaultMaxWait      = 10 * time.Minute
+	defaultMaxItems     = 500
+	fallbackTimeout     = 2 * time.Minute
+	pruneInterval       = time.Hour
+	pruneAge            = 7 * 24 * time.Hour
+
+	markSubmittedAttempts = 3
+	staleSubmitAfter      = 30 * time.Minute // submitting jobs older than this are failed over
+)
+
+// markSubmittedBackoff is the base delay between attempts to record a
+// provider batch ID (var for tests).
+var markSubmittedBackoff = time.Second
+
+// Handler resumes a worker's processing with the result of a batched request.
+// It runs with the item's tenant in ctx. Structured-output responses are
+// already decoded (resp.Content is the schema-shaped JSON). A returned error
+// is recorded on the item; the item is not retried.
+type Handler func(ctx context.Context, payload json.RawMessage, resp *providers.ChatResponse) error
+
+// Config configures a Dispatcher.
+type Config struct {
+	Store         store.LLMBatchStore
+	Registry      *providers.Registry
+	SystemConfigs store.SystemConfigStore
+	Tracing       *tracing.Collector              // optional: records batch cost traces
+	ModelPricing  map[string]*config.ModelPricing // optional: for batch-discounted span cost
+
+	PollInterval time.Duration // how often queued items, jobs and results are processed (default 1m)
+	MaxWait      time.Duration // max time an item waits in the queue before submission (default 10m)
+	MaxItems     int           // max requests per provider batch; a full group is submitted early (default 500)
+}
+
+// Dispatcher queues, submits, polls and delivers batched background requests.
+type Dispatcher struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	lastPrune time.Time
+}
+
+// New creates a Dispatcher. Call Register for each worker kind, then Start.
+func New(cfg Config) *Dispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = defaultMaxWait
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = defaultMaxItems
+	}
+	return &Dispatcher{
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Register sets the handler that receives results for items of the given kind.
+// Items are only enqueued for kinds with a registered handler.
+func (d *Dispatcher) Register(kind string, h Handler) {
+	d.mu.Lock()
+	d.handlers[kind] = h
+	d.mu.Unlock()
+}
+
+func (d *Dispatcher) handler(kind string) Handler {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.handlers[kind]
+}
+
+// TryEnqueue queues req for batch execution when the tenant opted in
+// (system config "background.batch"), the provider supports a batch API and
+// kind has a handler. payload is JSON-encoded and handed back to the handler
+// with the response. Returns false when the caller should run req
+// synchronously instead. Safe to call on a nil Dispatcher.
+//
+// When p is a providers.RedactingProvider, req is redacted here: the stored
+// request and the provider batch only see placeholders, and the item keeps
+// the vault that restores the response before the handler runs.
+func (d *Dispatcher) TryEnqueue(ctx context.Context, tenantID uuid.UUID, kind string, p providers.Provider, req providers.ChatRequest, payload any) bool {
+	if d == nil || p == nil || d.handler(kind) == nil {
+		return false
+	}
+	rp, redacting := p.(*providers.RedactingProvider)
+	if redacting {
+		p = rp.Unwrap()
+	}
+	if !providers.SupportsBatch(p) || !providerresolve.BackgroundBatchEnabled(ctx, tenantID, d.cfg.SystemConfigs) {
+		return false
+	}
+
+	var vaultJSON json.RawMessage
+	if redacting {
+		var vault *providers.RedactionVault
+		req, vault = rp.RedactBatchRequest(ctx, req)
+		var err error
+		if vaultJSON, err = json.Marshal(vault); err != nil {
+			slog.Warn("llmbatch: marshal redaction vault failed", "kind", kind, "error", err)
+			return false
+		}
+	}
+	reqJSON, err := json.Marshal(req)
+	if err != nil {
+		slog.Warn("llmbatch: marshal request failed", "kind", kind, "error", err)
+		return false
+	}
+	var payloadJSON json.RawMessage
+	if payload != nil {
+		if payloadJSON, err = json.Marshal(payload); err != nil {
+			slog.Warn("llmbatch: marshal payload failed", "kind", kind, "error", err)
+			return false
+		}
+	}
+	item := &store.LLMBatchItem{
+		TenantID: tenantID,
+		Kind:     kind,
+		Provider: p.Name(),
+		Model:    req.Model,
+		Request:  reqJSON,
+		Payload:  payloadJSON,
+		PIIVault: vaultJSON,
+	}
+	if err := d.cfg.Store.EnqueueItem(ctx, item); err != nil {
+		slog.Warn("llmbatch: enqueue failed, running synchronously", "kind", kind, "error", err)
+		return false
+	}
+	slog.Debug("llmbatch: queued", "kind", kind, "provider", item.Provider, "tenant", tenantID, "item", item.ID)
+	return true
+}
+
+// Start begins the dispatch loop. Results of batches submitted before a
+// restart are picked up on the first tick.
+func (d *Dispatcher) Start() {
+	d.wg.Add(1)
+	go d.loop()
+	slog.Info("llmbatch dispatcher started", "poll_interval", d.cfg.PollInterval, "max_wait", d.cfg.MaxWait)
+}
+
+// Stop stops the dispatch loop. In-flight provider batches keep running and
+// are resumed on the next Start.
+func (d *Dispatcher) Stop() {
+	close(d.stopCh)
+	d.wg.Wait()
+	slog.Info("llmbatch dispatcher stopped")
+}
+
+func (d *Dispatcher) loop() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	d.runTick()
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case <-ticker.C:
+			d.runTick()
+		}
+	}
+}
+
+func (d *Dispatcher) runTick() {
+	defer safego.Recover(nil, "component", "llmbatch")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-d.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	d.tick(ctx)
+}
+
+// tick runs one dispatch cycle: poll in-flight jobs, deliver finished items,
+// then submit queued items whose group is due.
+func (d *Dispatcher) tick(ctx context.Context) {
+	d.pollJobs(ctx)
+	d.deliver(ctx)
+	d.submitQueued(ctx, time.Now())
+
+	if time.Since(d.lastPrune) >= pruneInterval {
+		d.lastPrune = time.Now()
+		if n, err := d.cfg.Store.PruneFinished(ctx, pruneAge); err != nil {
+			slog.Warn("llmbatch: prune failed", "error", err)
+		} else if n > 0 {
+			slog.Debug("llmbatch: pruned delivered items", "count", n)
+		}
+	}
+}
+
+// groupKey identifies the items that can share one provider batch.
+type groupKey struct {
+	tenantID uuid.UUID
+	provider string
+}
+
+// submitQueued submits queued items grouped per tenant+provider once the
+// oldest item waited MaxWait or the group holds MaxItems.
+func (d *Dispatcher) submitQueued(ctx context.Context, now time.Time) {
+	queued, err := d.cfg.Store.ListItemsByStatus(ctx, store.LLMBatchItemQueued, 0)
+	if err != nil {
+		slog.Warn("llmbatch: list queued failed", "error", err)
+		return
+	}
+	groups := make(map[groupKey][]store.LLMBatchItem)
+	var order []groupKey
+	for _, it := range queued {
+		k := groupKey{it.TenantID, it.Provider}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], it)
+	}
+
+	for _, k := range order {
+		items := groups[k]
+		// Items are listed oldest first.
+		if len(items) < d.cfg.MaxItems && now.Sub(items[0].CreatedAt) < d.cfg.MaxWait {
+			continue
+		}
+		for start := 0; start < len(items); start += d.cfg.MaxItems {
+			end := min(start+d.cfg.MaxItems, len(items))
+			d.submitGroup(ctx, k, items[start:end])
+		}
+	}
+}
+
+// submitGroup submits one group as a provider batch. The job is recorded as
+// submitting before the provider call, so a batch the provider accepted is
+// never resubmitted (and paid for twice) when recording its ID fails.
+func (d *Dispatcher) submitGroup(ctx context.Context, k groupKey, items []store.LLMBatchItem) {
+	bc, err := d.batchProvider(k.tenantID, k.provider)
+	if err != nil {
+		// Provider removed or reconfigured since enqueue: fall back to sync calls.
+		d.failItems(ctx, items, err.Error())
+		return
+	}
+
+	reqs := make([]providers.BatchRequest, 0, len(items))
+	ids := make([]uuid.UUID, 0, len(items))
+	submitted := make([]store.LLMBatchItem, 0, len(items))
+	for _, it := range items {
+		var req providers.ChatRequest
+		if err := json.Unmarshal(it.Request, &req); err != nil {
+			d.failItems(ctx, []store.LLMBatchItem{it}, "decode request: "+err.Error())
+			continue
+		}
+		reqs = append(reqs, providers.BatchRequest{CustomID: it.ID.String(), Request: req})
+		ids = append(ids, it.ID)
+		submitted = append(submitted, it)
+	}
+	if len(reqs) == 0 {
+		return
+	}
+
+	job := &store.LLMBatchJob{TenantID: k.tenantID, Provider: k.provider, Status: store.LLMBatchJobSubmitting}
+	if err := d.cfg.Store.CreateJob(ctx, job, ids); err != nil {
+		// Nothing was sent yet; the items stay queued for the next tick.
+		slog.Warn("llmbatch: record job failed", "provider", k.provider, "tenant", k.tenantID, "error", err)
+		return
+	}
+
+	batchID, err := bc.SubmitBatch(ctx, reqs)
+	if err != nil {
+		slog.Warn("llmbatch: submit failed, falling back to synchronous calls",
+			"provider", k.provider, "tenant", k.tenantID, "items", len(reqs), "error", err)
+		msg := "submit: " + err.Error()
+		d.failItems(ctx, submitted, msg)
+		if err := d.cfg.Store.FinishJob(ctx, job.ID, store.LLMBatchJobFailed, msg); err != nil {
+			slog.Warn("llmbatch: finish job failed", "job", job.ID, "error", err)
+		}
+		return
+	}
+	for attempt := 1; ; attempt++ {
+		err = d.cfg.Store.MarkJobSubmitted(ctx, job.ID, batchID)
+		if err == nil || attempt == markSubmittedAttempts || ctx.Err() != nil {
+			break
+		}
+		time.Sleep(time.Duration(attempt) * markSubmittedBackoff)
+	}
+	if err != nil {
+		// The job stays submitting and is failed over to synchronous calls by
+		// pollJob once stale; the provider batch's results are discarded.
+		slog.Error("llmbatch: record batch id failed", "provider", k.provider, "job", job.ID, "batch_id", batchID, "error", err)
+		return
+	}
+	slog.Info("llmbatch: submitted", "provider", k.provider, "tenant", k.tenantID, "batch_id", batchID, "items", len(ids))
+}
+
+// pollJobs polls in-flight jobs and stores results of finished ones.
+func (d *Dispatcher) pollJobs(ctx context.Context) {
+	jobs, err := d.cfg.Store.ListActiveJobs(ctx)
+	if err != nil {
+		slog.Warn("llmbatch: list jobs failed", "error", err)
+		return
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		d.pollJob(ctx, job)
+	}
+}
+
+func (d *Dispatcher) pollJob(ctx context.Context, job store.LLMBatchJob) {
+	items, err := d.cfg.Store.ListJobItems(ctx, job.ID)
+	if err != nil {
+		slog.Warn("llmbatch: list job items failed", "job", job.ID, "error", err)
+		return
+	}
+
+	if job.Status == store.LLMBatchJobSubmitting {
+		// Submission crashed or could not record the provider batch ID.
+		if time.Since(job.SubmittedAt) >= staleSubmitAfter {
+			d.finishFailed(ctx, job, items, "provider batch id was never recorded")
+		}
+		return
+	}
+
+	bc, err := d.batchProvider(job.TenantID, job.Provider)
+	if err != nil {
+		d.finishFailed(ctx, job, items, err.Error())
+		return
+	}
+	poll, err := bc.PollBatch(ctx, job.ProviderBatchID)
+	if err != nil {
+		slog.Warn("llmbatch: poll failed", "provider", job.Provider, "batch_id", job.ProviderBatchID, "error", err)
+		return
+	}
+	switch poll.Status {
+	case providers.BatchStatusInProgress:
+		return
+	case providers.BatchStatusFailed:
+		d.finishFailed(ctx, job, items, poll.Error)
+		return
+	}
+
+	results := make(map[string]providers.BatchResult, len(poll.Results))
+	for _, r := range poll.Results {
+		results[r.CustomID] = r
+	}
+	var spans []itemSpan
+	for _, it := range items {
+		r, ok := results[it.ID.String()]
+		switch {
+		case !ok:
+			d.updateItem(ctx, it.ID, store.LLMBatchItemFailed, nil, "missing from batch results")
+		case r.Response == nil:
+			d.updateItem(ctx, it.ID, store.LLMBatchItemFailed, nil, r.Error)
+		default:
+			data, err := json.Marshal(r.Response)
+			if err != nil {
+				d.updateItem(ctx, it.ID, store.LLMBatchItemFailed, nil, "encode response: "+err.Error())
+				continue
+			}
+			d.updateItem(ctx, it.ID, store.LLMBatchItemCompleted, data, "")
+			spans = append(spans, itemSpan{item: it, resp: r.Response})
+		}
+	}
+	if err := d.cfg.Store.FinishJob(ctx, job.ID, store.LLMBatchJobEnded, ""); err != nil {
+		slog.Warn("llmbatch: finish job failed", "job", job.ID, "error", err)
+	}
+	d.recordTrace(ctx, job, spans)
+	slog.Info("llmbatch: batch ended", "provider", job.Provider, "batch_id", job.ProviderBatchID,
+		"items", len(items), "succeeded", len(spans))
+}
+
+func (d *Dispatcher) finishFailed(ctx context.Context, job store.LLMBatchJob, items []store.LLMBatchItem, msg string) {
+	slog.Warn("llmbatch: batch failed, falling back to synchronous calls",
+		"provider", job.Provider, "batch_id", job.ProviderBatchID, "error", msg)
+	d.failItems(ctx, items, msg)
+	if err := d.cfg.Store.FinishJob(ctx, job.ID, store.LLMBatchJobFailed, msg); err != nil {
+		slog.Warn("llmbatch: finish job failed", "job", job.ID, "error", err)
+	}
+}
+
+// deliver hands completed items to their handlers. Failed items are retried
+// once synchronously so a batch outage never drops background work.
+func (d *Dispatcher) deliver(ctx context.Context) {
+	for _, status := range []string{store.LLMBatchItemCompleted, store.LLMBatchItemFailed} {
+		items, err := d.cfg.Store.ListItemsByStatus(ctx, status, 0)
+		if err != nil {
+			slog.Warn("llmbatch: list items failed", "status", status, "error", err)
+			continue
+		}
+		for _, it := range items {
+			if ctx.Err() != nil {
+				return
+			}
+			d.deliverItem(ctx, it)
+		}
+	}
+}
+
+func (d *Dispatcher) deliverItem(ctx context.Context, it store.LLMBatchItem) {
+	h := d.handler(it.Kind)
+	if h == nil {
+		// Worker not registered in this process (e.g. feature disabled); keep the item.
+		return
+	}
+	tctx := store.WithTenantID(ctx, it.TenantID)
+
+	var req providers.ChatRequest
+	if err := json.Unmarshal(it.Request, &req); err != nil {
+		d.updateItem(ctx, it.ID, store.LLMBatchItemDelivered, nil, "decode request: "+err.Error())
+		return
+	}
+
+	var resp *providers.ChatResponse
+	if it.Status == store.LLMBatchItemCompleted {
+		resp = new(providers.ChatResponse)
+		err := json.Unmarshal(it.Response, resp)
+		if err == nil {
+			err = providers.DecodeStructuredResponse(req.ResponseFormat, resp)
+		}
+		if err != nil {
+			slog.Warn("llmbatch: batch result unusable, retrying synchronously", "kind", it.Kind, "item", it.ID, "error", err)
+			resp = nil
+		}
+	}
+	if resp == nil {
+		var err error
+		if resp, err = d.chatSync(tctx, it, req); err != nil {
+			slog.Warn("llmbatch: synchronous fallback failed", "kind", it.Kind, "item", it.ID, "error", err)
+			d.updateItem(ctx, it.ID, store.LLMBatchItemDelivered, nil, err.Error())
+			return
+		}
+	}
+
+	if len(it.PIIVault) > 0 {
+		vault := providers.NewRedactionVault()
+		if err := json.Unmarshal(it.PIIVault, vault); err != nil {
+			d.updateItem(ctx, it.ID, store.LLMBatchItemDelivered, nil, "decode pii vault: "+err.Error())
+			return
+		}
+		vault.RestoreResponse(resp)
+	}
+
+	errMsg := ""
+	if err := h(tctx, it.Payload, resp); err != nil {
+		slog.Warn("llmbatch: handler failed", "kind", it.Kind, "item", it.ID, "error", err)
+		errMsg = err.Error()
+	}
+	d.updateItem(ctx, it.ID, store.LLMBatchItemDelivered, nil, errMsg)
+}
+
+// chatSync runs a failed item's request through the normal synchronous path.
+// A redacted request is sent as stored, in placeholder form.
+func (d *Dispatcher) chatSync(ctx context.Context, it store.LLMBatchItem, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	if d.cfg.Registry == nil {
+		return nil, fmt.Errorf("provider registry unavailable")
+	}
+	p, err := d.cfg.Registry.GetForTenant(it.TenantID, it.Provider)
+	if err != nil {
+		return nil, err
+	}
+	cctx, cancel := context.WithTimeout(ctx, fallbackTimeout)
+	defer cancel()
+	return providers.ChatStructured(cctx, p, req)
+}
+
+// batchProvider resolves a tenant's provider and checks it can still batch.
+func (d *Dispatcher) batchProvider(tenantID uuid.UUID, name string) (providers.BatchCapable, error) {
+	if d.cfg.Registry == nil {
+		return nil, fmt.Errorf("provider registry unavailable")
+	}
+	p, err := d.cfg.Registry.GetForTenant(tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+	if !providers.SupportsBatch(p) {
+		return nil, fmt.Errorf("provider %s does not support batch", name)
+	}
+	return p.(providers.BatchCapable), nil
+}
+
+func (d *Dispatcher) failItems(ctx context.Context, items []store.LLMBatchItem, msg string) {
+	for _, it := range items {
+		d.updateItem(ctx, it.ID, store.LLMBatchItemFailed, nil, msg)
+	}
+}
+
+func (d *Dispatcher) updateItem(ctx context.Context, id uuid.UUID, status string, resp json.RawMessage, errMsg string) {
+	if err := d.cfg.Store.UpdateItem(ctx, id, status, resp, errMsg); err != nil {
+		slog.Warn("llmbatch: update item failed", "item", id, "status", status, "error", err)
+	}
+}
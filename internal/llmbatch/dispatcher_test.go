@@ -0,0 +1,431 @@
+package llmbatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// memStore is an in-memory store.LLMBatchStore.
+type memStore struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]*store.LLMBatchItem
+	jobs  map[uuid.UUID]*store.LLMBatchJob
+
+	createErr error             // returned by CreateJob
+	markErrs  int               // MarkJobSubmitted fails this many times
+	updates   map[uuid.UUID]int // UpdateItem calls per item
+}
+
+func newMemStore() *memStore {
+	return &memStore{items: map[uuid.UUID]*store.LLMBatchItem{}, jobs: map[uuid.UUID]*store.LLMBatchJob{}}
+}
+
+func (s *memStore) EnqueueItem(_ context.Context, it *store.LLMBatchItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it.ID = uuid.New()
+	it.Status = store.LLMBatchItemQueued
+	it.CreatedAt = time.Now()
+	cp := *it
+	s.items[it.ID] = &cp
+	return nil
+}
+
+func (s *memStore) ListItemsByStatus(_ context.Context, status string, _ int) ([]store.LLMBatchItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []store.LLMBatchItem
+	for _, it := range s.items {
+		if it.Status == status {
+			out = append(out, *it)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *memStore) CreateJob(_ context.Context, job *store.LLMBatchJob, ids []uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.createErr != nil {
+		return s.createErr
+	}
+	job.ID = uuid.New()
+	if job.Status == "" {
+		job.Status = store.LLMBatchJobInProgress
+	}
+	job.SubmittedAt = time.Now()
+	job.ItemCount = len(ids)
+	cp := *job
+	s.jobs[job.ID] = &cp
+	for _, id := range ids {
+		s.items[id].JobID = &cp.ID
+		s.items[id].Status = store.LLMBatchItemSubmitted
+	}
+	return nil
+}
+
+func (s *memStore) MarkJobSubmitted(_ context.Context, jobID uuid.UUID, batchID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.markErrs > 0 {
+		s.markErrs--
+		return errors.New("db down")
+	}
+	s.jobs[jobID].ProviderBatchID = batchID
+	s.jobs[jobID].Status = store.LLMBatchJobInProgress
+	return nil
+}
+
+func (s *memStore) ListActiveJobs(_ context.Context) ([]store.LLMBatchJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []store.LLMBatchJob
+	for _, j := range s.jobs {
+		if j.Status == store.LLMBatchJobSubmitting || j.Status == store.LLMBatchJobInProgress {
+			out = append(out, *j)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) ListJobItems(_ context.Context, jobID uuid.UUID) ([]store.LLMBatchItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []store.LLMBatchItem
+	for _, it := range s.items {
+		if it.JobID != nil && *it.JobID == jobID {
+			out = append(out, *it)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) FinishJob(_ context.Context, jobID uuid.UUID, status, errMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[jobID].Status = status
+	s.jobs[jobID].Error = errMsg
+	return nil
+}
+
+func (s *memStore) UpdateItem(_ context.Context, id uuid.UUID, status string, resp json.RawMessage, errMsg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updates == nil {
+		s.updates = map[uuid.UUID]int{}
+	}
+	s.updates[id]++
+	it := s.items[id]
+	it.Status = status
+	if resp != nil {
+		it.Response = resp
+	}
+	it.Error = errMsg
+	return nil
+}
+
+func (s *memStore) PruneFinished(context.Context, time.Duration) (int64, error) { return 0, nil }
+
+func (s *memStore) statuses() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]int{}
+	for _, it := range s.items {
+		out[it.Status]++
+	}
+	return out
+}
+
+// fakeBatchProvider answers batches with the request's last message echoed
+// back, failing requests whose content is "fail". Chat is the sync fallback.
+type fakeBatchProvider struct {
+	mu        sync.Mutex
+	submitted map[string][]providers.BatchRequest
+	submitErr error
+	pending   bool
+	syncCalls int
+}
+
+func (p *fakeBatchProvider) Chat(_ context.Context, req providers.ChatRequest) (*providers.ChatResponse, error) {
+	p.mu.Lock()
+	p.syncCalls++
+	p.mu.Unlock()
+	return &providers.ChatResponse{Content: "sync:" + req.Messages[0].Content, FinishReason: "stop"}, nil
+}
+
+func (p *fakeBatchProvider) ChatStream(ctx context.Context, req providers.ChatRequest, _ func(providers.StreamChunk)) (*providers.ChatResponse, error) {
+	return p.Chat(ctx, req)
+}
+
+func (p *fakeBatchProvider) Name() string         { return "fake" }
+func (p *fakeBatchProvider) DefaultModel() string { return "fake-model" }
+func (p *fakeBatchProvider) Capabilities() providers.ProviderCapabilities {
+	return providers.ProviderCapabilities{Batch: true}
+}
+
+func (p *fakeBatchProvider) SubmitBatch(_ context.Context, reqs []providers.BatchRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.submitErr != nil {
+		return "", p.submitErr
+	}
+	id := "batch-" + uuid.NewString()
+	p.submitted[id] = reqs
+	return id, nil
+}
+
+func (p *fakeBatchProvider) PollBatch(_ context.Context, id string) (*providers.BatchPoll, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pending {
+		return &providers.BatchPoll{Status: providers.BatchStatusInProgress}, nil
+	}
+	reqs, ok := p.submitted[id]
+	if !ok {
+		return nil, errors.New("unknown batch")
+	}
+	poll := &providers.BatchPoll{Status: providers.BatchStatusEnded}
+	for _, r := range reqs {
+		content := r.Request.Messages[0].Content
+		if content == "fail" {
+			poll.Results = append(poll.Results, providers.BatchResult{CustomID: r.CustomID, Error: "errored"})
+			continue
+		}
+		poll.Results = append(poll.Results, providers.BatchResult{
+			CustomID: r.CustomID,
+			Response: &providers.ChatResponse{Content: "batch:" + content, FinishReason: "stop",
+				Usage: &providers.Usage{PromptTokens: 10, CompletionTokens: 2}},
+		})
+	}
+	return poll, nil
+}
+
+type staticConfigs map[string]string
+
+func (c staticConfigs) Get(_ context.Context, key string) (string, error) {
+	if v, ok := c[key]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+func (c staticConfigs) Set(context.Context, string, string) error       { return nil }
+func (c staticConfigs) Delete(context.Context, string) error            { return nil }
+func (c staticConfigs) List(context.Context) (map[string]string, error) { return c, nil }
+
+func chatReq(content string) providers.ChatRequest {
+	return providers.ChatRequest{Model: "fake-model", Messages: []providers.Message{{Role: "user", Content: content}}}
+}
+
+func TestDispatcherBatchLifecycle(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	fp := &fakeBatchProvider{submitted: map[string][]providers.BatchRequest{}, pending: true}
+	reg := providers.NewRegistry(nil)
+	reg.RegisterForTenant(tenantID, fp)
+	ms := newMemStore()
+	d := New(Config{Store: ms, Registry: reg, SystemConfigs: staticConfigs{"background.batch": "true"}, MaxItems: 10})
+
+	type payload struct{ N int }
+	var mu sync.Mutex
+	got := map[int]string{}
+	d.Register("test.kind", func(_ context.Context, raw json.RawMessage, resp *providers.ChatResponse) error {
+		var p payload
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return err
+		}
+		mu.Lock()
+		got[p.N] = resp.Content
+		mu.Unlock()
+		return nil
+	})
+
+	if d.TryEnqueue(ctx, tenantID, "unregistered", fp, chatReq("x"), nil) {
+		t.Fatal("enqueue must fail for unregistered kind")
+	}
+	if !d.TryEnqueue(ctx, tenantID, "test.kind", fp, chatReq("one"), payload{1}) ||
+		!d.TryEnqueue(ctx, tenantID, "test.kind", fp, chatReq("fail"), payload{2}) {
+		t.Fatal("TryEnqueue returned false")
+	}
+
+	// Not due yet: neither MaxWait elapsed nor MaxItems reached.
+	d.tick(ctx)
+	if n := ms.statuses()[store.LLMBatchItemQueued]; n != 2 {
+		t.Fatalf("queued = %d, want 2", n)
+	}
+
+	// Due: submitted as one batch, still in progress.
+	d.submitQueued(ctx, time.Now().Add(time.Hour))
+	d.tick(ctx)
+	if len(fp.submitted) != 1 || ms.statuses()[store.LLMBatchItemSubmitted] != 2 {
+		t.Fatalf("submitted = %d batches, statuses %v", len(fp.submitted), ms.statuses())
+	}
+
+	// Batch ends: success delivered from batch, failure via sync fallback.
+	fp.pending = false
+	d.tick(ctx)
+	if st := ms.statuses(); st[store.LLMBatchItemDelivered] != 2 {
+		t.Fatalf("statuses = %v", st)
+	}
+	if got[1] != "batch:one" || got[2] != "sync:fail" || fp.syncCalls != 1 {
+		t.Errorf("got = %v, syncCalls = %d", got, fp.syncCalls)
+	}
+}
+
+func TestDispatcherTryEnqueueOptIn(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	fp := &fakeBatchProvider{submitted: map[string][]providers.BatchRequest{}}
+	ms := newMemStore()
+	d := New(Config{Store: ms, SystemConfigs: staticConfigs{}})
+	d.Register("k", func(context.Context, json.RawMessage, *providers.ChatResponse) error { return nil })
+
+	if d.TryEnqueue(ctx, tenantID, "k", fp, chatReq("x"), nil) {
+		t.Error("tenant without background.batch must not enqueue")
+	}
+	var nilD *Dispatcher
+	if nilD.TryEnqueue(ctx, tenantID, "k", fp, chatReq("x"), nil) {
+		t.Error("nil dispatcher must not enqueue")
+	}
+	if len(ms.items) != 0 {
+		t.Errorf("items = %d, want 0", len(ms.items))
+	}
+}
+
+func TestDispatcherRedactsQueuedRequests(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	fp := &fakeBatchProvider{submitted: map[string][]providers.BatchRequest{}}
+	reg := providers.NewRegistry(nil)
+	reg.RegisterForTenant(tenantID, fp)
+	ms := newMemStore()
+	d := New(Config{Store: ms, Registry: reg, SystemConfigs: staticConfigs{"background.batch": "true"}})
+
+	var got string
+	d.Register("k", func(_ context.Context, _ json.RawMessage, resp *providers.ChatResponse) error {
+		got = resp.Content
+		return nil
+	})
+
+	redactor, err := providers.NewPIIRedactor([]string{providers.PIIEmail}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp := providers.NewRedactingProvider(fp, redactor, nil)
+	if !d.TryEnqueue(ctx, tenantID, "k", rp, chatReq("mail alice@example.com"), nil) {
+		t.Fatal("TryEnqueue returned false for a redacting batch provider")
+	}
+	for _, it := range ms.items {
+		if strings.Contains(string(it.Request), "alice@example.com") {
+			t.Errorf("stored request holds the raw address: %s", it.Request)
+		}
+	}
+
+	d.submitQueued(ctx, time.Now().Add(time.Hour))
+	if len(fp.submitted) != 1 {
+		t.Fatalf("submitted = %d batches, want 1", len(fp.submitted))
+	}
+	for _, reqs := range fp.submitted {
+		if body := reqs[0].Request.Messages[0].Content; body != "mail [PII_EMAIL_1]" {
+			t.Errorf("batch request body = %q, want placeholder", body)
+		}
+	}
+
+	d.tick(ctx)
+	if got != "batch:mail alice@example.com" {
+		t.Errorf("handler got %q, want restored content", got)
+	}
+}
+
+func TestDispatcherSubmitFailures(t *testing.T) {
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer func(b time.Duration) { markSubmittedBackoff = b }(markSubmittedBackoff)
+	markSubmittedBackoff = 0
+	setup := func() (*Dispatcher, *memStore, *fakeBatchProvider, uuid.UUID) {
+		fp := &fakeBatchProvider{submitted: map[string][]providers.BatchRequest{}, pending: true}
+		reg := providers.NewRegistry(nil)
+		reg.RegisterForTenant(tenantID, fp)
+		ms := newMemStore()
+		d := New(Config{Store: ms, Registry: reg, SystemConfigs: staticConfigs{"background.batch": "true"}})
+		d.Register("k", func(context.Context, json.RawMessage, *providers.ChatResponse) error { return nil })
+		if !d.TryEnqueue(ctx, tenantID, "k", fp, chatReq("ok"), nil) {
+			t.Fatal("TryEnqueue returned false")
+		}
+		bad := &store.LLMBatchItem{TenantID: tenantID, Kind: "k", Provider: fp.Name(), Request: json.RawMessage(`"not a request"`)}
+		_ = ms.EnqueueItem(ctx, bad)
+		return d, ms, fp, bad.ID
+	}
+
+	t.Run("record job fails before submit", func(t *testing.T) {
+		d, ms, fp, _ := setup()
+		ms.createErr = errors.New("db down")
+		d.submitQueued(ctx, time.Now().Add(time.Hour))
+		if len(fp.submitted) != 0 {
+			t.Fatal("batch submitted although the job could not be recorded")
+		}
+		if n := ms.statuses()[store.LLMBatchItemQueued]; n != 1 {
+			t.Errorf("queued = %d, want the decodable item kept queued", n)
+		}
+	})
+
+	t.Run("submit fails", func(t *testing.T) {
+		d, ms, fp, badID := setup()
+		fp.submitErr = errors.New("overloaded")
+		d.submitQueued(ctx, time.Now().Add(time.Hour))
+		if st := ms.statuses(); st[store.LLMBatchItemFailed] != 2 {
+			t.Fatalf("statuses = %v, want both items failed", st)
+		}
+		if n := ms.updates[badID]; n != 1 {
+			t.Errorf("undecodable item updated %d times, want 1", n)
+		}
+		for _, j := range ms.jobs {
+			if j.Status != store.LLMBatchJobFailed {
+				t.Errorf("job status = %s, want failed", j.Status)
+			}
+		}
+	})
+
+	t.Run("batch id recorded after retry", func(t *testing.T) {
+		d, ms, fp, _ := setup()
+		ms.markErrs = markSubmittedAttempts - 1
+		d.submitQueued(ctx, time.Now().Add(time.Hour))
+		d.submitQueued(ctx, time.Now().Add(time.Hour))
+		if len(fp.submitted) != 1 {
+			t.Fatalf("submitted = %d batches, want exactly 1", len(fp.submitted))
+		}
+		for _, j := range ms.jobs {
+			if j.Status != store.LLMBatchJobInProgress || fp.submitted[j.ProviderBatchID] == nil {
+				t.Errorf("job = %+v, want in_progress with the provider batch id", j)
+			}
+		}
+	})
+
+	t.Run("stale submitting job falls back", func(t *testing.T) {
+		d, ms, fp, _ := setup()
+		ms.markErrs = markSubmittedAttempts
+		d.submitQueued(ctx, time.Now().Add(time.Hour))
+		d.pollJobs(ctx)
+		if n := ms.statuses()[store.LLMBatchItemSubmitted]; n != 1 {
+			t.Fatalf("fresh submitting job must be left alone, statuses %v", ms.statuses())
+		}
+		for _, j := range ms.jobs {
+			j.SubmittedAt = time.Now().Add(-staleSubmitAfter)
+		}
+		d.pollJobs(ctx)
+		if n := ms.statuses()[store.LLMBatchItemFailed]; n != 2 || len(fp.submitted) != 1 {
+			t.Errorf("statuses = %v, submitted = %d; want failover without resubmission", ms.statuses(), len(fp.submitted))
+		}
+	})
+}
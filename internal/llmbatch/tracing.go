@@ -0,0 +1,76 @@
+package llmbatch
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+	"github.com/nextlevelbuilder/goclaw/internal/tracing"
+)
+
+// itemSpan pairs a completed item with its provider response for tracing.
+type itemSpan struct {
+	item store.LLMBatchItem
+	resp *providers.ChatResponse
+}
+
+// recordTrace writes one trace per ended batch with an llm_call span per
+// succeeded item, priced at the batch discount so cost reports reflect what
+// the provider actually bills.
+func (d *Dispatcher) recordTrace(ctx context.Context, job store.LLMBatchJob, spans []itemSpan) {
+	if d.cfg.Tracing == nil || len(spans) == 0 {
+		return
+	}
+	tctx := store.WithTenantID(ctx, job.TenantID)
+	now := time.Now().UTC()
+	traceID := store.GenNewID()
+	trace := &store.TraceData{
+		ID:           traceID,
+		Name:         "llm_batch",
+		Channel:      "background",
+		InputPreview: job.Provider + " batch " + job.ProviderBatchID,
+		Status:       store.TraceStatusRunning,
+		StartTime:    job.SubmittedAt,
+		CreatedAt:    now,
+		Tags:         []string{"batch"},
+	}
+	if err := d.cfg.Tracing.CreateTrace(tctx, trace); err != nil {
+		slog.Warn("llmbatch: create trace failed", "batch_id", job.ProviderBatchID, "error", err)
+		return
+	}
+
+	meta, _ := json.Marshal(map[string]any{"batch": true, "batch_id": job.ProviderBatchID})
+	durationMS := int(now.Sub(job.SubmittedAt).Milliseconds())
+	for _, s := range spans {
+		end := now
+		span := store.SpanData{
+			TraceID:      traceID,
+			SpanType:     store.SpanTypeLLMCall,
+			Name:         s.item.Kind,
+			StartTime:    job.SubmittedAt,
+			EndTime:      &end,
+			DurationMS:   durationMS,
+			Status:       store.SpanStatusCompleted,
+			Level:        store.SpanLevelDefault,
+			Model:        s.item.Model,
+			Provider:     job.Provider,
+			FinishReason: s.resp.FinishReason,
+			Metadata:     meta,
+			TenantID:     job.TenantID,
+		}
+		if u := s.resp.Usage; u != nil {
+			span.InputTokens = u.PromptTokens
+			span.OutputTokens = u.CompletionTokens
+			if pricing := tracing.LookupPricing(d.cfg.ModelPricing, job.Provider, s.item.Model); pricing != nil {
+				if cost := tracing.CalculateBatchCost(pricing, u); cost > 0 {
+					span.TotalCost = &cost
+				}
+			}
+		}
+		d.cfg.Tracing.EmitSpan(span)
+	}
+	d.cfg.Tracing.FinishTrace(tctx, traceID, store.TraceStatusCompleted, "", "")
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/rerank"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -16,11 +17,13 @@ import (
 type pgAutoInjector struct {
 	episodicStore store.EpisodicStore
 	metricsStore  store.EvolutionMetricsStore // nil = metrics disabled
+	reranker      *rerank.Service             // nil = retrieval order
 }
 
 // NewAutoInjector creates an AutoInjector backed by episodic store search.
-func NewAutoInjector(es store.EpisodicStore, ms store.EvolutionMetricsStore) AutoInjector {
-	return &pgAutoInjector{episodicStore: es, metricsStore: ms}
+// rr (optional) reranks candidates before the entry budget is applied.
+func NewAutoInjector(es store.EpisodicStore, ms store.EvolutionMetricsStore, rr *rerank.Service) AutoInjector {
+	return &pgAutoInjector{episodicStore: es, metricsStore: ms, reranker: rr}
 }
 
 // Inject searches episodic memory for relevant L0 abstracts and formats a prompt section.
@@ -51,7 +54,7 @@ func (a *pgAutoInjector) Inject(ctx context.Context, params InjectParams) (*Inje
 	// Search with FTS bias (faster than vector for auto-inject)
 	results, err := a.episodicStore.Search(ctx, searchQuery, params.AgentID, params.UserID,
 		store.EpisodicSearchOptions{
-			MaxResults:   maxEntries * 2, // fetch more, filter by threshold
+			MaxResults:   a.reranker.Candidates(ctx, maxEntries*2), // fetch more, filter by threshold
 			MinScore:     threshold,
 			VectorWeight: 0.3,
 			TextWeight:   0.7,
@@ -59,6 +62,9 @@ func (a *pgAutoInjector) Inject(ctx context.Context, params InjectParams) (*Inje
 	if err != nil {
 		return nil, fmt.Errorf("auto-inject search: %w", err)
 	}
+	results = rerank.Rerank(ctx, a.reranker, searchQuery, results, maxEntries*2,
+		func(r store.EpisodicSearchResult) string { return r.L0Abstract },
+		func(r *store.EpisodicSearchResult, score float64) { r.Score = score })
 	if len(results) == 0 {
 		return &InjectResult{}, nil
 	}
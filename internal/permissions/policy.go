@@ -188,6 +188,9 @@ func isAdminMethod(method string) bool {
 		protocol.MethodAPIKeysCreate,
 		protocol.MethodAPIKeysRevoke,
 		protocol.MethodSkillsUpdate,
+		protocol.MethodOutboxDeadList,
+		protocol.MethodOutboxDeadReplay,
+		protocol.MethodOutboxDeadDelete,
 	}
 	return slices.Contains(adminMethods, method)
 }
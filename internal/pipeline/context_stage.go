@@ -58,6 +58,9 @@ func (s *ContextStage) Execute(ctx context.Context, state *RunState) error {
 		AgentID:   store.AgentIDFromContext(ctx),
 		RawInput:  state.Input.Message,
 		HookEvent: hooks.EventUserPromptSubmit,
+		Channel:   state.Input.Channel,
+		ChatID:    state.Input.ChatID,
+		PeerKind:  state.Input.PeerKind,
 	}); r.Decision == hooks.DecisionBlock {
 		return fmt.Errorf("hook blocked user_prompt_submit")
 	} else if r.UpdatedRawInput != nil {
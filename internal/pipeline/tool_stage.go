@@ -59,6 +59,9 @@ func (s *ToolStage) Execute(ctx context.Context, state *RunState) error {
 			ToolName:  tc.Name,
 			ToolInput: tc.Arguments,
 			HookEvent: hooks.EventPreToolUse,
+			Channel:   state.Input.Channel,
+			ChatID:    state.Input.ChatID,
+			PeerKind:  state.Input.PeerKind,
 		}); r.Decision == hooks.DecisionBlock {
 			// Inject synthetic blocked tool message and skip actual execution.
 			state.Messages.AppendPending(providers.Message{
@@ -2,13 +2,14 @@ package providerresolve
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
 // ResolveConfiguredProvider resolves the provider an agent should actually use.
-// It applies ChatGPT OAuth routing from agent other_config when present.
+// It applies ChatGPT OAuth routing and load balancing from agent config when present.
 func ResolveConfiguredProvider(registry *providers.Registry, agent *store.AgentData) (providers.Provider, error) {
 	if registry == nil || agent == nil {
 		return nil, fmt.Errorf("provider registry unavailable")
@@ -17,6 +18,9 @@ func ResolveConfiguredProvider(registry *providers.Registry, agent *store.AgentD
 	baseProvider, baseErr := registry.GetForTenant(agent.TenantID, agent.Provider)
 	if baseErr == nil {
 		if _, ok := baseProvider.(*providers.CodexProvider); !ok {
+			if lb := agent.ParseLoadBalancing(); lb != nil {
+				return newBalancedProvider(registry, agent, lb), nil
+			}
 			return baseProvider, nil
 		}
 	}
@@ -50,3 +54,18 @@ func ResolveConfiguredProvider(registry *providers.Registry, agent *store.AgentD
 	}
 	return nil, baseErr
 }
+
+// newBalancedProvider builds the balancer wrapper with the agent's own
+// provider/model as the first candidate.
+func newBalancedProvider(registry *providers.Registry, agent *store.AgentData, lb *store.LoadBalancingConfig) *providers.BalancedProvider {
+	candidates := []providers.ModelCandidate{{Provider: agent.Provider, Model: agent.Model, Weight: lb.PrimaryWeight}}
+	for _, c := range lb.Candidates {
+		model := c.Model
+		if model == "" {
+			model = agent.Model
+		}
+		candidates = append(candidates, providers.ModelCandidate{Provider: c.Provider, Model: model, Weight: c.Weight})
+	}
+	hedgeAfter := time.Duration(lb.HedgeAfterMs) * time.Millisecond
+	return providers.NewBalancedProvider(agent.TenantID, registry, agent.Provider, candidates, hedgeAfter)
+}
@@ -82,3 +82,17 @@ func ResolveBackgroundProvider(
 		"agent.default_provider", configs["agent.default_provider"])
 	return p, p.DefaultModel()
 }
+
+// BackgroundBatchEnabled reports whether the tenant opted background workers
+// into provider batch APIs (system config "background.batch"). Batch results
+// arrive minutes to hours later at a discount, so it is off by default.
+func BackgroundBatchEnabled(ctx context.Context, tenantID uuid.UUID, systemConfigs store.SystemConfigStore) bool {
+	if systemConfigs == nil {
+		return false
+	}
+	v, err := systemConfigs.Get(store.WithTenantID(ctx, tenantID), "background.batch")
+	if err != nil {
+		return false
+	}
+	return v == "true" || v == "1"
+}
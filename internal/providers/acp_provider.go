@@ -94,6 +94,7 @@ func (p *ACPProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           false,
 		CacheControl:     false,
+		StructuredOutput: false,
 		MaxContextWindow: 200_000,
 		TokenizerID:      "cl100k_base",
 	}
@@ -0,0 +1,54 @@
+package providers
+
+import (
+	"net/http"
+)
+
+// AzureOpenAIAdapter implements ProviderAdapter for Azure OpenAI deployments.
+// Same Chat Completions wire format as OpenAI; auth headers come from the wrapped
+// provider (api-key or Entra ID bearer). The request URL is deployment-scoped,
+// see AzureOpenAIProvider.Deployment.
+type AzureOpenAIAdapter struct {
+	inner    *OpenAIAdapter
+	provider *AzureOpenAIProvider
+}
+
+// NewAzureOpenAIAdapter creates an adapter from ProviderConfig.
+// Optional ExtraOpts: "api_version" (string), "deployments" (map[string]string),
+// "token_source" (TokenSource).
+func NewAzureOpenAIAdapter(cfg ProviderConfig) (ProviderAdapter, error) {
+	var opts []AzureOpenAIOption
+	if v, ok := cfg.ExtraOpts["api_version"].(string); ok {
+		opts = append(opts, WithAzureAPIVersion(v))
+	}
+	if m, ok := cfg.ExtraOpts["deployments"].(map[string]string); ok {
+		opts = append(opts, WithAzureDeployments(m))
+	}
+	if ts, ok := cfg.ExtraOpts["token_source"].(TokenSource); ok {
+		opts = append(opts, WithAzureTokenSource(ts))
+	}
+	p := NewAzureOpenAIProvider(cfg.Name, cfg.BaseURL, cfg.APIKey, cfg.Model, opts...)
+	return &AzureOpenAIAdapter{inner: &OpenAIAdapter{provider: p.OpenAIProvider}, provider: p}, nil
+}
+
+func (a *AzureOpenAIAdapter) Name() string { return "azure_openai" }
+
+// Capabilities delegates to the wrapped provider.
+func (a *AzureOpenAIAdapter) Capabilities() ProviderCapabilities {
+	return a.provider.Capabilities()
+}
+
+// ToRequest delegates to the OpenAI adapter (same Chat Completions wire format).
+func (a *AzureOpenAIAdapter) ToRequest(req ChatRequest) ([]byte, http.Header, error) {
+	return a.inner.ToRequest(req)
+}
+
+// FromResponse delegates to the OpenAI adapter.
+func (a *AzureOpenAIAdapter) FromResponse(data []byte) (*ChatResponse, error) {
+	return a.inner.FromResponse(data)
+}
+
+// FromStreamChunk delegates to the OpenAI adapter (same SSE format).
+func (a *AzureOpenAIAdapter) FromStreamChunk(data []byte) (*StreamChunk, error) {
+	return a.inner.FromStreamChunk(data)
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BedrockAdapter implements ProviderAdapter for the Bedrock Converse API.
+// Delegates to BedrockProvider's buildRequestBody/parseResponse for DRY.
+//
+// Note: ToRequest only sets the bearer Authorization header for Bedrock API keys.
+// SigV4 signatures cover the final URL and payload hash, so callers using IAM
+// credentials must sign at the transport (BedrockProvider.authorize does this).
+// FromStreamChunk takes the decoded payload of one event-stream frame.
+type BedrockAdapter struct {
+	provider *BedrockProvider
+}
+
+// NewBedrockAdapter creates an adapter from ProviderConfig.
+// Region is read from ExtraOpts["region"], falling back to the BaseURL host.
+func NewBedrockAdapter(cfg ProviderConfig) (ProviderAdapter, error) {
+	region, _ := cfg.ExtraOpts["region"].(string)
+	p, err := NewBedrockProviderFromKey(context.Background(), cfg.Name, region, cfg.BaseURL, cfg.APIKey,
+		WithBedrockModel(cfg.Model))
+	if err != nil {
+		return nil, err
+	}
+	return &BedrockAdapter{provider: p}, nil
+}
+
+func (a *BedrockAdapter) Name() string { return "bedrock" }
+
+// Capabilities delegates to the wrapped provider for single source of truth.
+func (a *BedrockAdapter) Capabilities() ProviderCapabilities {
+	return a.provider.Capabilities()
+}
+
+// ToRequest converts ChatRequest to a Converse request body + headers.
+func (a *BedrockAdapter) ToRequest(req ChatRequest) ([]byte, http.Header, error) {
+	model := a.provider.resolveModel(req.Model)
+	body := a.provider.buildRequestBody(model, req)
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bedrock adapter: marshal: %w", err)
+	}
+
+	h := make(http.Header)
+	h.Set("Content-Type", "application/json")
+	if a.provider.bearerToken != "" {
+		h.Set("Authorization", "Bearer "+a.provider.bearerToken)
+	}
+	return data, h, nil
+}
+
+// FromResponse parses a Converse response JSON into ChatResponse.
+func (a *BedrockAdapter) FromResponse(data []byte) (*ChatResponse, error) {
+	var resp bedrockConverseResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("bedrock adapter: decode: %w", err)
+	}
+	return a.provider.parseResponse(&resp), nil
+}
+
+// FromStreamChunk parses one ConverseStream event payload.
+// The event type lives in the frame headers, so payloads are dispatched by shape:
+// "delta" → text/reasoning delta, "stopReason" → Done. Tool input deltas are
+// stateful and left to the caller, as with the Anthropic adapter.
+func (a *BedrockAdapter) FromStreamChunk(data []byte) (*StreamChunk, error) {
+	var probe struct {
+		Delta      json.RawMessage `json:"delta"`
+		StopReason string          `json:"stopReason"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, nil
+	}
+	if probe.StopReason != "" {
+		return &StreamChunk{Done: true}, nil
+	}
+	if len(probe.Delta) == 0 {
+		return nil, nil
+	}
+
+	var ev bedrockContentBlockDeltaEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, nil
+	}
+	switch {
+	case ev.Delta.Text != nil:
+		return &StreamChunk{Content: *ev.Delta.Text}, nil
+	case ev.Delta.ReasoningContent != nil && ev.Delta.ReasoningContent.Text != nil:
+		return &StreamChunk{Thinking: *ev.Delta.ReasoningContent.Text}, nil
+	}
+	return nil, nil
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 )
 
 // OpenAIAdapter implements ProviderAdapter for OpenAI Chat Completions API.
@@ -44,15 +43,9 @@ func (a *OpenAIAdapter) ToRequest(req ChatRequest) ([]byte, http.Header, error)
 	h := make(http.Header)
 	h.Set("Content-Type", "application/json")
 
-	// Azure uses api-key header; others use Bearer token
-	if strings.Contains(strings.ToLower(a.provider.apiBase), "azure.com") {
-		h.Set("api-key", a.provider.apiKey)
-	} else {
-		prefix := a.provider.authPrefix
-		if prefix == "" {
-			prefix = "Bearer "
-		}
-		h.Set("Authorization", prefix+a.provider.apiKey)
+	// Azure uses api-key header (or Entra ID bearer); others use Bearer token
+	if err := a.provider.setAuthHeader(h); err != nil {
+		return nil, nil, err
 	}
 
 	if a.provider.siteURL != "" {
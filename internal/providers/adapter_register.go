@@ -8,5 +8,8 @@ func DefaultAdapterRegistry() *AdapterRegistry {
 	r.Register("openai", NewOpenAIAdapter)
 	r.Register("dashscope", NewDashScopeAdapter)
 	r.Register("codex", NewCodexAdapter)
+	r.Register("bedrock", NewBedrockAdapter)
+	r.Register("azure_openai", NewAzureOpenAIAdapter)
+	r.Register("vertex_ai", NewVertexAdapter)
 	return r
 }
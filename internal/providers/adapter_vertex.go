@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// VertexAdapter implements ProviderAdapter for Vertex AI generateContent.
+// Delegates to VertexProvider's buildRequestBody/parseResponse for DRY.
+// FromStreamChunk takes one SSE data payload from streamGenerateContent?alt=sse.
+type VertexAdapter struct {
+	provider *VertexProvider
+}
+
+// NewVertexAdapter creates an adapter from ProviderConfig.
+// APIKey holds the service-account JSON key; ExtraOpts may set "project_id" and "location".
+func NewVertexAdapter(cfg ProviderConfig) (ProviderAdapter, error) {
+	project, _ := cfg.ExtraOpts["project_id"].(string)
+	location, _ := cfg.ExtraOpts["location"].(string)
+	p, err := NewVertexProviderFromKey(cfg.Name, project, location, cfg.BaseURL, cfg.APIKey,
+		WithVertexModel(cfg.Model))
+	if err != nil {
+		return nil, err
+	}
+	return &VertexAdapter{provider: p}, nil
+}
+
+func (a *VertexAdapter) Name() string { return "vertex_ai" }
+
+// Capabilities delegates to the wrapped provider for single source of truth.
+func (a *VertexAdapter) Capabilities() ProviderCapabilities {
+	return a.provider.Capabilities()
+}
+
+// ToRequest converts ChatRequest to a generateContent body + bearer auth header.
+func (a *VertexAdapter) ToRequest(req ChatRequest) ([]byte, http.Header, error) {
+	model := a.provider.resolveModel(req.Model)
+	body := a.provider.buildRequestBody(model, req)
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("vertex adapter: marshal: %w", err)
+	}
+
+	token, err := a.provider.tokenSource.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	h := make(http.Header)
+	h.Set("Content-Type", "application/json")
+	h.Set("Authorization", "Bearer "+token)
+	return data, h, nil
+}
+
+// FromResponse parses a generateContent response JSON into ChatResponse.
+func (a *VertexAdapter) FromResponse(data []byte) (*ChatResponse, error) {
+	var resp vertexResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("vertex adapter: decode: %w", err)
+	}
+	return a.provider.parseResponse(&resp), nil
+}
+
+// FromStreamChunk parses one streamGenerateContent event into a text/thinking delta.
+// A candidate finishReason marks Done.
+func (a *VertexAdapter) FromStreamChunk(data []byte) (*StreamChunk, error) {
+	var resp vertexResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, nil
+	}
+	if len(resp.Candidates) == 0 {
+		return nil, nil
+	}
+	cand := resp.Candidates[0]
+	chunk := &StreamChunk{Done: cand.FinishReason != ""}
+	for _, part := range cand.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+		case part.Thought:
+			chunk.Thinking += part.Text
+		default:
+			chunk.Content += part.Text
+		}
+	}
+	if chunk.Content == "" && chunk.Thinking == "" && !chunk.Done {
+		return nil, nil
+	}
+	return chunk, nil
+}
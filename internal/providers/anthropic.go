@@ -115,6 +115,8 @@ func (p *AnthropicProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           true,
 		CacheControl:     true,
+		StructuredOutput: true,
+		Batch:            true,
 		MaxContextWindow: 200_000,
 		TokenizerID:      "cl100k_base",
 	}
@@ -159,6 +161,7 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRes
 		if strip, _ := req.Options[OptStripThinking].(bool); strip {
 			resp.Thinking = ""
 		}
+		takeStructuredToolCall(req.ResponseFormat, resp)
 	}
 	return resp, err
 }
@@ -189,6 +192,7 @@ func (p *AnthropicProvider) doRequest(ctx context.Context, body any) (io.ReadClo
 	if err != nil {
 		return nil, fmt.Errorf("anthropic: request failed: %w", err)
 	}
+	observeRateLimitHeaders(ctx, resp.Header)
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -0,0 +1,152 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Anthropic Message Batches API: POST /messages/batches, GET /messages/batches/{id},
+// then GET results_url (JSONL, one line per request in arbitrary order).
+
+type anthropicBatchResponse struct {
+	ID               string `json:"id"`
+	ProcessingStatus string `json:"processing_status"` // "in_progress", "canceling", "ended"
+	ResultsURL       string `json:"results_url"`
+}
+
+type anthropicBatchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string             `json:"type"` // "succeeded", "errored", "canceled", "expired"
+		Message *anthropicResponse `json:"message,omitempty"`
+		Error   json.RawMessage    `json:"error,omitempty"`
+	} `json:"result"`
+}
+
+// SubmitBatch implements BatchCapable via the Message Batches API.
+func (p *AnthropicProvider) SubmitBatch(ctx context.Context, reqs []BatchRequest) (string, error) {
+	items := make([]map[string]any, 0, len(reqs))
+	for _, r := range reqs {
+		req := batchWireRequest(r.Request)
+		model := resolveAnthropicModel(req.Model, p.defaultModel, p.registry)
+		params := p.buildRequestBody(model, req, false)
+		params = ApplyMiddlewares(params, p.middlewares, p.middlewareConfig(model, req))
+		items = append(items, map[string]any{"custom_id": r.CustomID, "params": params})
+	}
+
+	var out anthropicBatchResponse
+	err := p.batchCall(ctx, http.MethodPost, p.baseURL+"/messages/batches", map[string]any{"requests": items}, &out)
+	if err != nil {
+		return "", err
+	}
+	if out.ID == "" {
+		return "", fmt.Errorf("anthropic: batch submit returned no id")
+	}
+	return out.ID, nil
+}
+
+// PollBatch implements BatchCapable.
+func (p *AnthropicProvider) PollBatch(ctx context.Context, batchID string) (*BatchPoll, error) {
+	var batch anthropicBatchResponse
+	if err := p.batchCall(ctx, http.MethodGet, p.baseURL+"/messages/batches/"+batchID, nil, &batch); err != nil {
+		return nil, err
+	}
+	if batch.ProcessingStatus != "ended" {
+		return &BatchPoll{Status: BatchStatusInProgress}, nil
+	}
+	if batch.ResultsURL == "" {
+		return &BatchPoll{Status: BatchStatusFailed, Error: "batch ended without results"}, nil
+	}
+
+	var raw json.RawMessage
+	if err := p.batchCall(ctx, http.MethodGet, batch.ResultsURL, nil, &raw); err != nil {
+		return nil, err
+	}
+	poll := &BatchPoll{Status: BatchStatusEnded}
+	err := parseJSONLines(raw, func(line []byte) error {
+		var rl anthropicBatchResultLine
+		if err := json.Unmarshal(line, &rl); err != nil {
+			return err
+		}
+		res := BatchResult{CustomID: rl.CustomID}
+		switch {
+		case rl.Result.Type == "succeeded" && rl.Result.Message != nil:
+			res.Response = p.parseResponse(rl.Result.Message)
+			// Background batch requests carry no real tools, so a lone
+			// structured_output call is always the forced-schema answer.
+			takeStructuredToolCall(&ResponseFormat{Type: ResponseFormatJSONObject}, res.Response)
+		case len(rl.Result.Error) > 0:
+			res.Error = fmt.Sprintf("%s: %s", rl.Result.Type, rl.Result.Error)
+		default:
+			res.Error = rl.Result.Type
+		}
+		poll.Results = append(poll.Results, res)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("anthropic: parse batch results: %w", err)
+	}
+	return poll, nil
+}
+
+// batchCall performs a Message Batches request and decodes the JSON response
+// into out. out may be a *json.RawMessage to receive the raw body (used for the
+// JSONL results file).
+func (p *AnthropicProvider) batchCall(ctx context.Context, method, url string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("anthropic: marshal batch request: %w", err)
+		}
+	}
+
+	data, err := RetryDo(ctx, p.retryConfig, func() ([]byte, error) {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: create batch request: %w", err)
+		}
+		if payload != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		httpReq.Header.Set("x-api-key", p.apiKey)
+		httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+		resp, err := p.client.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: batch request failed: %w", err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: read batch response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, &HTTPError{
+				Status:     resp.StatusCode,
+				Body:       fmt.Sprintf("anthropic: %s", string(data)),
+				RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	if raw, ok := out.(*json.RawMessage); ok {
+		*raw = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("anthropic: decode batch response: %w", err)
+	}
+	return nil
+}
@@ -208,6 +208,27 @@ func (p *AnthropicProvider) buildRequestBody(model string, req ChatRequest, stre
 		body["tools"] = tools
 	}
 
+	// Structured output: Anthropic has no JSON mode, so the schema becomes a
+	// synthetic tool the model is forced to call. Chat/ChatStream move its input
+	// into Content. Forced tool_choice is incompatible with extended thinking.
+	forceStructured := req.ResponseFormat.wantsJSON()
+	if forceStructured {
+		tools, _ := body["tools"].([]map[string]any)
+		body["tools"] = append(tools, map[string]any{
+			"name":         structuredOutputToolName,
+			"description":  structuredOutputToolDescription,
+			"input_schema": CleanSchemaForProvider("anthropic", req.ResponseFormat.objectSchema()),
+		})
+		if len(req.Tools) == 0 {
+			body["tool_choice"] = map[string]any{"type": "tool", "name": structuredOutputToolName}
+		} else {
+			// Real tools stay callable; the model must finish via structured_output.
+			// A structured_output call made alongside real tools is dropped
+			// before dispatch (takeStructuredToolCall).
+			body["tool_choice"] = map[string]any{"type": "any"}
+		}
+	}
+
 	// Merge options
 	if v, ok := req.Options[OptMaxTokens]; ok {
 		body["max_tokens"] = v
@@ -217,7 +238,7 @@ func (p *AnthropicProvider) buildRequestBody(model string, req ChatRequest, stre
 	}
 
 	// Enable extended thinking if thinking_level is set
-	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" && level != "off" {
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" && level != "off" && !forceStructured {
 		budget := anthropicThinkingBudget(level)
 		body["thinking"] = map[string]any{
 			"type":          "enabled",
@@ -181,6 +181,12 @@ func (p *AnthropicProvider) ChatStream(ctx context.Context, req ChatRequest, onC
 
 	result.ThinkingSignature = thinkingSignature.String()
 
+	// The forced structured-output tool streams as input_json_delta; surface the
+	// final object as content once it is complete.
+	if text := takeStructuredToolCall(req.ResponseFormat, result); text != "" && onChunk != nil {
+		onChunk(StreamChunk{Content: text})
+	}
+
 	if onChunk != nil {
 		onChunk(StreamChunk{Done: true})
 	}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AzureCognitiveServicesScope is the Entra ID scope for Azure OpenAI data-plane calls.
+	AzureCognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+
+	azureEntraAuthority    = "https://login.microsoftonline.com"
+	tokenRefreshSkew       = 5 * time.Minute // refresh cached OAuth tokens this long before expiry
+	azureTokenFetchTimeout = 30 * time.Second
+)
+
+// AzureEntraTokenSource fetches Entra ID (Azure AD) access tokens with the
+// OAuth2 client-credentials grant and caches them until shortly before expiry.
+// Implements TokenSource. Thread-safe.
+type AzureEntraTokenSource struct {
+	tenantID     string
+	clientID     string
+	clientSecret string
+	scope        string
+	tokenURL     string
+	client       *http.Client
+	nowFn        func() time.Time
+
+	mu          sync.Mutex
+	cachedToken string
+	expiresAt   time.Time
+}
+
+// NewAzureEntraTokenSource creates a client-credentials token source for a service principal.
+func NewAzureEntraTokenSource(tenantID, clientID, clientSecret string) *AzureEntraTokenSource {
+	return &AzureEntraTokenSource{
+		tenantID:     tenantID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scope:        AzureCognitiveServicesScope,
+		tokenURL:     azureEntraAuthority + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token",
+		client:       &http.Client{Timeout: azureTokenFetchTimeout},
+		nowFn:        time.Now,
+	}
+}
+
+// Token returns a cached access token, refreshing it when it is within
+// 5 minutes of expiry.
+func (s *AzureEntraTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cachedToken != "" && s.nowFn().Add(tokenRefreshSkew).Before(s.expiresAt) {
+		return s.cachedToken, nil
+	}
+	if s.tenantID == "" || s.clientID == "" || s.clientSecret == "" {
+		return "", fmt.Errorf("azure entra: tenant_id, client_id and client secret are required")
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+		"scope":         {s.scope},
+	}
+	resp, err := s.client.Post(s.tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("azure entra: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		// Surface as HTTPError so the failover classifier maps 400/401 to auth errors.
+		status := resp.StatusCode
+		if status == http.StatusBadRequest {
+			status = http.StatusUnauthorized // invalid_client / invalid_grant
+		}
+		return "", &HTTPError{Status: status, Body: "azure entra: " + string(body)}
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", fmt.Errorf("azure entra: decode token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("azure entra: empty access token in response")
+	}
+
+	s.cachedToken = tok.AccessToken
+	s.expiresAt = s.nowFn().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return s.cachedToken, nil
+}
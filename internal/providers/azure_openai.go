@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	azureOpenAIDefaultAPIVersion = "2024-10-21"
+	azureOpenAIDefaultModel      = "gpt-4o"
+)
+
+// AzureOpenAIProvider wraps OpenAIProvider for Azure OpenAI / AI Foundry resources.
+// Requests are routed per deployment:
+//
+//	{endpoint}/openai/deployments/{deployment}/chat/completions?api-version=...
+//
+// The logical model name (used for capability checks, reasoning params, registry
+// lookups) maps to a deployment name; unmapped models use the model name as-is,
+// which matches the common "deployment named after the model" setup.
+// Auth is either the resource api-key header or an Entra ID bearer token.
+type AzureOpenAIProvider struct {
+	*OpenAIProvider
+	endpoint    string
+	apiVersion  string
+	deployments map[string]string // model → deployment name
+}
+
+type AzureOpenAIOption func(*AzureOpenAIProvider)
+
+// WithAzureAPIVersion overrides the api-version query parameter (default: 2024-10-21).
+func WithAzureAPIVersion(v string) AzureOpenAIOption {
+	return func(p *AzureOpenAIProvider) {
+		if v != "" {
+			p.apiVersion = v
+		}
+	}
+}
+
+// WithAzureDeployments sets the model → deployment name mapping.
+func WithAzureDeployments(m map[string]string) AzureOpenAIOption {
+	return func(p *AzureOpenAIProvider) {
+		for model, dep := range m {
+			if model != "" && dep != "" {
+				p.deployments[model] = dep
+			}
+		}
+	}
+}
+
+// WithAzureTokenSource authenticates with Entra ID bearer tokens instead of the api-key header.
+func WithAzureTokenSource(ts TokenSource) AzureOpenAIOption {
+	return func(p *AzureOpenAIProvider) {
+		if ts != nil {
+			p.OpenAIProvider.WithTokenSource(ts)
+		}
+	}
+}
+
+// NewAzureOpenAIProvider creates an Azure OpenAI provider for a resource endpoint
+// (e.g. https://my-resource.openai.azure.com). apiKey may be empty when a token
+// source is supplied.
+func NewAzureOpenAIProvider(name, endpoint, apiKey, defaultModel string, opts ...AzureOpenAIOption) *AzureOpenAIProvider {
+	endpoint = AzureOpenAIEndpoint(endpoint)
+	if defaultModel == "" {
+		defaultModel = azureOpenAIDefaultModel
+	}
+	p := &AzureOpenAIProvider{
+		OpenAIProvider: NewOpenAIProvider(name, apiKey, endpoint, defaultModel).WithProviderType("azure_openai"),
+		endpoint:       endpoint,
+		apiVersion:     azureOpenAIDefaultAPIVersion,
+		deployments:    make(map[string]string),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	p.OpenAIProvider.WithEndpointResolver(p.chatURL)
+	return p
+}
+
+// AzureOpenAIEndpoint normalizes a user-supplied resource URL to its origin,
+// dropping any "/openai/..." suffix copied from the Azure portal.
+func AzureOpenAIEndpoint(raw string) string {
+	raw = strings.TrimRight(strings.TrimSpace(raw), "/")
+	if i := strings.Index(strings.ToLower(raw), "/openai"); i >= 0 {
+		raw = raw[:i]
+	}
+	return raw
+}
+
+// Deployment returns the deployment name for a logical model.
+func (p *AzureOpenAIProvider) Deployment(model string) string {
+	if dep, ok := p.deployments[model]; ok {
+		return dep
+	}
+	return model
+}
+
+// chatURL builds the deployment-scoped chat completions URL for a model.
+func (p *AzureOpenAIProvider) chatURL(model string) string {
+	if model == "" {
+		model = p.defaultModel
+	}
+	return p.endpoint + "/openai/deployments/" + url.PathEscape(p.Deployment(model)) +
+		"/chat/completions?api-version=" + url.QueryEscape(p.apiVersion)
+}
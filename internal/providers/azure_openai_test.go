@@ -0,0 +1,186 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const azureChatOK = `{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`
+
+func TestAzureOpenAIDeploymentRouting(t *testing.T) {
+	var gotPath, gotVersion, gotKey, gotAuth, gotModel string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get("api-key")
+		gotAuth = r.Header.Get("Authorization")
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		gotModel, _ = body["model"].(string)
+		w.Write([]byte(azureChatOK))
+	}))
+	defer srv.Close()
+
+	p := NewAzureOpenAIProvider("azure", srv.URL+"/openai/deployments", "secret", "gpt-4o",
+		WithAzureDeployments(map[string]string{"gpt-4o": "prod-4o"}),
+		WithAzureAPIVersion("2025-01-01-preview"))
+
+	resp, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Content != "hi" {
+		t.Errorf("content = %q, want hi", resp.Content)
+	}
+	if gotPath != "/openai/deployments/prod-4o/chat/completions" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotVersion != "2025-01-01-preview" {
+		t.Errorf("api-version = %q", gotVersion)
+	}
+	if gotKey != "secret" || gotAuth != "" {
+		t.Errorf("expected api-key auth only, got api-key=%q Authorization=%q", gotKey, gotAuth)
+	}
+	if gotModel != "gpt-4o" {
+		t.Errorf("body model = %q, want logical model name", gotModel)
+	}
+
+	// Unmapped model → deployment named after the model.
+	if _, err := p.Chat(context.Background(), ChatRequest{Model: "o4-mini", Messages: []Message{{Role: "user", Content: "x"}}}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if gotPath != "/openai/deployments/o4-mini/chat/completions" || gotVersion != "2025-01-01-preview" {
+		t.Errorf("unmapped model routed to %q (api-version %q)", gotPath, gotVersion)
+	}
+}
+
+func TestAzureOpenAITokenSourceAuth(t *testing.T) {
+	var gotKey, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("api-key")
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(azureChatOK))
+	}))
+	defer srv.Close()
+
+	p := NewAzureOpenAIProvider("azure", srv.URL, "client-secret", "gpt-4o", WithAzureTokenSource(&staticTokenSource{token: "tok"}))
+	if _, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hello"}}}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if gotAuth != "Bearer tok" || gotKey != "" {
+		t.Errorf("expected bearer auth only, got api-key=%q Authorization=%q", gotKey, gotAuth)
+	}
+}
+
+func TestAzureOpenAIEndpointNormalize(t *testing.T) {
+	tests := map[string]string{
+		"https://res.openai.azure.com":  "https://res.openai.azure.com",
+		"https://res.openai.azure.com/": "https://res.openai.azure.com",
+		"https://res.openai.azure.com/openai/deployments/x/chat/completions?api-version=1": "https://res.openai.azure.com",
+		" https://res.cognitiveservices.azure.com/OpenAI/v1 ":                              "https://res.cognitiveservices.azure.com",
+	}
+	for in, want := range tests {
+		if got := AzureOpenAIEndpoint(in); got != want {
+			t.Errorf("AzureOpenAIEndpoint(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAzureEntraTokenSourceCachesAndRefreshes(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != AzureCognitiveServicesScope {
+			t.Errorf("unexpected form: %v", r.Form)
+		}
+		if r.Form.Get("client_id") != "cid" || r.Form.Get("client_secret") != "csecret" {
+			t.Errorf("unexpected client credentials: %v", r.Form)
+		}
+		w.Write([]byte(`{"token_type":"Bearer","expires_in":3600,"access_token":"tok-` + string(rune('0'+calls.Load())) + `"}`))
+	}))
+	defer srv.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	ts := NewAzureEntraTokenSource("tenant", "cid", "csecret")
+	ts.tokenURL = srv.URL
+	ts.nowFn = func() time.Time { return now }
+
+	tok, err := ts.Token()
+	if err != nil || tok != "tok-1" {
+		t.Fatalf("first Token() = %q, %v", tok, err)
+	}
+	if tok, _ = ts.Token(); tok != "tok-1" || calls.Load() != 1 {
+		t.Fatalf("expected cached token, got %q after %d calls", tok, calls.Load())
+	}
+
+	// Within the refresh margin → fetch a new token.
+	now = now.Add(56 * time.Minute)
+	if tok, _ = ts.Token(); tok != "tok-2" || calls.Load() != 2 {
+		t.Fatalf("expected refreshed token, got %q after %d calls", tok, calls.Load())
+	}
+}
+
+func TestAzureEntraTokenSourceErrorClassifiesAsAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_client","error_description":"AADSTS7000215: Invalid client secret provided."}`))
+	}))
+	defer srv.Close()
+
+	ts := NewAzureEntraTokenSource("tenant", "cid", "bad")
+	ts.tokenURL = srv.URL
+	_, err := ts.Token()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got := ClassifyHTTPError(NewDefaultClassifier(), err); got.Reason != FailoverAuth {
+		t.Errorf("reason = %s, want %s", got.Reason, FailoverAuth)
+	}
+}
+
+func TestClassifyAzureContentFilter(t *testing.T) {
+	body := `azure: {"error":{"message":"The response was filtered due to the prompt triggering Azure OpenAI's content management policy.","type":null,"param":"prompt","code":"content_filter","status":400,"innererror":{"code":"ResponsibleAIPolicyViolation"}}}`
+	result := NewDefaultClassifier().Classify(nil, 400, body)
+	if result.Reason != FailoverContentFilter {
+		t.Errorf("expected FailoverContentFilter, got %s", result.Reason)
+	}
+}
+
+func TestRunWithFailoverContentFilterFallsBackWithoutCooldown(t *testing.T) {
+	tracker := NewCooldownTracker(0)
+	cfg := FailoverConfig{
+		Candidates: []ModelCandidate{
+			{Provider: "azure", Model: "gpt-4o", ProfileID: "key1"},
+			{Provider: "azure", Model: "gpt-4o", ProfileID: "key2"},
+			{Provider: "anthropic", Model: "claude", ProfileID: "key1"},
+		},
+		Classifier: NewDefaultClassifier(),
+		Tracker:    tracker,
+	}
+
+	var tried []string
+	runFn := func(ctx context.Context, c ModelCandidate) (string, error) {
+		tried = append(tried, c.Provider+"/"+c.ProfileID)
+		if c.Provider == "azure" {
+			return "", &HTTPError{Status: 400, Body: `{"error":{"code":"content_filter"}}`}
+		}
+		return "ok", nil
+	}
+
+	result, _, err := RunWithFailover(context.Background(), cfg, runFn)
+	if err != nil || result != "ok" {
+		t.Fatalf("result = %q, err = %v", result, err)
+	}
+	if len(tried) != 2 || tried[1] != "anthropic/key1" {
+		t.Errorf("expected skip to next model, tried %v", tried)
+	}
+	if !tracker.IsAvailable(CooldownKey("azure", "gpt-4o")) {
+		t.Error("content filter must not put the model in cooldown")
+	}
+}
@@ -0,0 +1,290 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BalancedProvider spreads an agent's requests across equivalent candidates
+// (the same model served by several providers or keys). The Balancer picks
+// the order per request; RunWithFailover walks it with the shared cooldown
+// tracker. With hedgeAfter set, a second candidate is started when the first
+// has not produced a token in time, and whichever answers first wins.
+//
+// Instances are created per request by providerresolve; health state lives in
+// Registry.Balancer().
+type BalancedProvider struct {
+	tenantID   uuid.UUID
+	registry   *Registry
+	name       string // primary provider: identity, defaults and capabilities
+	candidates []ModelCandidate
+	hedgeAfter time.Duration
+}
+
+// NewBalancedProvider creates a balanced provider over candidates. A candidate
+// with an empty Model uses the request's model. hedgeAfter <= 0 disables hedging.
+func NewBalancedProvider(tenantID uuid.UUID, registry *Registry, primaryName string, candidates []ModelCandidate, hedgeAfter time.Duration) *BalancedProvider {
+	return &BalancedProvider{
+		tenantID:   tenantID,
+		registry:   registry,
+		name:       primaryName,
+		candidates: candidates,
+		hedgeAfter: hedgeAfter,
+	}
+}
+
+func (p *BalancedProvider) primary() Provider {
+	provider, err := p.registry.GetForTenant(p.tenantID, p.name)
+	if err != nil {
+		return nil
+	}
+	return provider
+}
+
+func (p *BalancedProvider) Name() string { return p.name }
+
+func (p *BalancedProvider) DefaultModel() string {
+	if primary := p.primary(); primary != nil {
+		return primary.DefaultModel()
+	}
+	return ""
+}
+
+// SupportsThinking, Capabilities and PromptContribution report the primary
+// provider's; candidates are expected to be equivalent.
+func (p *BalancedProvider) SupportsThinking() bool {
+	tc, ok := p.primary().(ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+func (p *BalancedProvider) Capabilities() ProviderCapabilities {
+	caps := ProviderCapabilities{Streaming: true, ToolCalling: true}
+	if ca, ok := p.primary().(CapabilitiesAware); ok {
+		caps = ca.Capabilities()
+	}
+	caps.Batch = false // batch jobs go to one provider, not a balanced pool
+	return caps
+}
+
+func (p *BalancedProvider) PromptContribution() *PromptContribution {
+	if pc, ok := p.primary().(PromptContributor); ok {
+		return pc.PromptContribution()
+	}
+	return nil
+}
+
+// Candidates returns the configured candidates.
+func (p *BalancedProvider) Candidates() []ModelCandidate { return p.candidates }
+
+func (p *BalancedProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return p.call(ctx, req, nil)
+}
+
+func (p *BalancedProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	if onChunk == nil {
+		onChunk = func(StreamChunk) {}
+	}
+	return p.call(ctx, req, onChunk)
+}
+
+// call runs the request over the balanced order. onChunk == nil means Chat.
+func (p *BalancedProvider) call(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	scope := p.tenantID.String()
+	balancer := p.registry.Balancer()
+	ordered := balancer.Order(scope, p.candidates)
+
+	// Once a candidate has streamed to the caller, failing over would repeat
+	// output; stop the failover loop and surface that candidate's error.
+	failCtx, stopFailover := context.WithCancel(ctx)
+	defer stopFailover()
+	var committedErr error
+
+	resp, attempts, err := RunWithFailover(failCtx, FailoverConfig{
+		Candidates: ordered,
+		Tracker:    balancer.Tracker(scope),
+	}, func(ctx context.Context, c ModelCandidate) (*ChatResponse, error) {
+		resp, streamed, err := p.attempt(ctx, scope, c, p.hedgeFor(scope, ordered, c), req, onChunk)
+		if err != nil && streamed {
+			committedErr = err
+			stopFailover()
+		}
+		return resp, err
+	})
+	if committedErr != nil {
+		return nil, committedErr
+	}
+	if err == nil && len(attempts) > 0 {
+		slog.Info("balanced provider failover",
+			"provider", p.name, "attempts", len(attempts), "error", attempts[len(attempts)-1].Err)
+	}
+	return resp, err
+}
+
+// hedgeFor returns the next available candidate after c in ordered, or nil.
+func (p *BalancedProvider) hedgeFor(scope string, ordered []ModelCandidate, c ModelCandidate) *ModelCandidate {
+	if p.hedgeAfter <= 0 {
+		return nil
+	}
+	tracker := p.registry.Balancer().Tracker(scope)
+	for i, cand := range ordered {
+		if cand != c {
+			continue
+		}
+		for _, next := range ordered[i+1:] {
+			if tracker.IsAvailable(CooldownKey(next.Provider, next.Model)) {
+				return &next
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+type balancedResult struct {
+	idx  int
+	resp *ChatResponse
+	err  error
+}
+
+// attempt runs c, racing it against hedge when c is slow to produce its first
+// token. streamed reports whether the returned candidate already delivered
+// chunks to the caller.
+func (p *BalancedProvider) attempt(ctx context.Context, scope string, c ModelCandidate, hedge *ModelCandidate, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, bool, error) {
+	// winner is the index of the candidate whose output the caller receives:
+	// claimed by the first streamed chunk or the first successful response.
+	var winner atomic.Int32
+	winner.Store(-1)
+	claim := func(idx int) bool {
+		return winner.CompareAndSwap(-1, int32(idx)) || winner.Load() == int32(idx)
+	}
+
+	cands := []ModelCandidate{c}
+	if hedge != nil {
+		cands = append(cands, *hedge)
+	}
+	var cancelMu sync.Mutex
+	cancels := make([]context.CancelFunc, len(cands))
+	results := make(chan balancedResult, len(cands))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	cancelOthers := func(keep int) {
+		cancelMu.Lock()
+		defer cancelMu.Unlock()
+		for i, cancel := range cancels {
+			if i != keep && cancel != nil {
+				cancel()
+			}
+		}
+	}
+	defer cancelOthers(-1)
+
+	launch := func(idx int) {
+		runCtx, cancel := context.WithCancel(ctx)
+		cancelMu.Lock()
+		cancels[idx] = cancel
+		cancelMu.Unlock()
+		var sink func(StreamChunk)
+		if onChunk != nil {
+			sink = func(ch StreamChunk) {
+				if claim(idx) {
+					cancelOthers(idx)
+					onChunk(ch)
+				}
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := p.runOne(runCtx, ctx, scope, cands[idx], req, sink)
+			results <- balancedResult{idx: idx, resp: resp, err: err}
+		}()
+	}
+
+	launch(0)
+	var hedgeTimer <-chan time.Time
+	if hedge != nil {
+		timer := time.NewTimer(p.hedgeAfter)
+		defer timer.Stop()
+		hedgeTimer = timer.C
+	}
+
+	running := 1
+	var firstErr error
+	for {
+		select {
+		case <-hedgeTimer:
+			hedgeTimer = nil
+			if winner.Load() == -1 {
+				p.registry.Balancer().RecordHedge(scope, *hedge)
+				slog.Debug("balanced provider hedging",
+					"provider", c.Provider, "hedge", hedge.Provider, "after", p.hedgeAfter)
+				launch(1)
+				running++
+			}
+		case r := <-results:
+			running--
+			if r.err == nil && claim(r.idx) {
+				return r.resp, onChunk != nil, nil
+			}
+			if r.err != nil && winner.Load() == int32(r.idx) {
+				return nil, true, r.err
+			}
+			if r.err != nil && firstErr == nil {
+				firstErr = r.err
+			}
+			if running == 0 {
+				// Everything launched failed. A primary that fails before the
+				// hedge fires goes straight to failover instead of waiting.
+				return nil, false, firstErr
+			}
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// runOne calls a single candidate and records its outcome. parent is the
+// caller's context: a cancellation of runCtx alone means the candidate lost a
+// hedge race, which is not held against it.
+func (p *BalancedProvider) runOne(runCtx, parent context.Context, scope string, c ModelCandidate, req ChatRequest, sink func(StreamChunk)) (*ChatResponse, error) {
+	balancer := p.registry.Balancer()
+	provider, err := p.registry.GetForTenant(p.tenantID, c.Provider)
+	if err != nil {
+		balancer.Record(scope, c, 0, err)
+		return nil, err
+	}
+	if c.Model != "" {
+		req.Model = c.Model
+	}
+	runCtx = withRateLimitObserver(runCtx, func(s RateLimitState) {
+		balancer.RecordRateLimit(scope, c, s)
+	})
+
+	start := time.Now()
+	var ttft time.Duration
+	var resp *ChatResponse
+	if sink == nil {
+		resp, err = provider.Chat(runCtx, req)
+	} else {
+		var once sync.Once
+		resp, err = provider.ChatStream(runCtx, req, func(ch StreamChunk) {
+			once.Do(func() { ttft = time.Since(start) })
+			sink(ch)
+		})
+	}
+	if err != nil && errors.Is(err, context.Canceled) && parent.Err() == nil {
+		return nil, err // hedge loser
+	}
+	if ttft == 0 {
+		ttft = time.Since(start)
+	}
+	balancer.Record(scope, c, ttft, err)
+	return resp, err
+}
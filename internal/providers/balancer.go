@@ -0,0 +1,310 @@
+package providers
+
+import (
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Balancer spreads requests across equivalent candidates (the same model on
+// several providers or keys) by weight, adjusted by rolling latency, error
+// rate and the rate-limit headroom providers report in response headers.
+// It only orders candidates; RunWithFailover still does the failover and the
+// per-scope CooldownTracker still gates unhealthy candidates.
+//
+// Stats are in-memory and keyed by scope (tenant) + provider + model, so two
+// tenants with a provider of the same name never share health.
+type Balancer struct {
+	mu       sync.Mutex
+	stats    map[string]*candidateStats
+	trackers map[string]*CooldownTracker
+	nowFn    func() time.Time
+	randFn   func() float64
+}
+
+const (
+	balancerWindow    = 50   // latency and outcome samples kept per candidate
+	balancerMaxKeys   = 1024 // candidates tracked before the least recently used is evicted
+	balancerMinFactor = 0.05 // a degraded candidate keeps this share of its base weight
+)
+
+type candidateStats struct {
+	scope     string
+	candidate ModelCandidate
+
+	latencies []time.Duration // ring of time-to-first-token (or full call) samples
+	latNext   int
+	outcomes  []bool // ring; true = error
+	outNext   int
+
+	requests, errors, hedges int64
+	rateLimit                RateLimitState
+	effectiveWeight          float64
+	lastUsed                 time.Time
+}
+
+// CandidateHealth is the admin view of one balanced candidate.
+type CandidateHealth struct {
+	Scope           string         `json:"scope"`
+	Provider        string         `json:"provider"`
+	Model           string         `json:"model"`
+	ProfileID       string         `json:"profile_id,omitempty"`
+	Weight          float64        `json:"weight"`
+	EffectiveWeight float64        `json:"effective_weight"`
+	P50Ms           int64          `json:"p50_ms"`
+	P95Ms           int64          `json:"p95_ms"`
+	ErrorRate       float64        `json:"error_rate"`
+	Samples         int            `json:"samples"`
+	Requests        int64          `json:"requests"`
+	Errors          int64          `json:"errors"`
+	Hedges          int64          `json:"hedges"`
+	InCooldown      bool           `json:"in_cooldown"`
+	RateLimit       RateLimitState `json:"rate_limit"`
+	LastUsed        time.Time      `json:"last_used"`
+}
+
+// NewBalancer creates an empty balancer.
+func NewBalancer() *Balancer {
+	return &Balancer{
+		stats:    make(map[string]*candidateStats),
+		trackers: make(map[string]*CooldownTracker),
+		nowFn:    time.Now,
+		randFn:   rand.Float64,
+	}
+}
+
+func balancerKey(scope string, c ModelCandidate) string {
+	key := scope + "/" + CooldownKey(c.Provider, c.Model)
+	if c.ProfileID != "" {
+		key += "#" + c.ProfileID
+	}
+	return key
+}
+
+// Tracker returns the cooldown tracker for scope.
+func (b *Balancer) Tracker(scope string) *CooldownTracker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.trackers[scope]
+	if !ok {
+		t = NewCooldownTracker(0)
+		b.trackers[scope] = t
+	}
+	return t
+}
+
+// statsLocked returns (creating) the stats for a candidate. Must hold mu.
+func (b *Balancer) statsLocked(scope string, c ModelCandidate) *candidateStats {
+	key := balancerKey(scope, c)
+	st, ok := b.stats[key]
+	if !ok {
+		if len(b.stats) >= balancerMaxKeys {
+			b.evictLocked()
+		}
+		st = &candidateStats{scope: scope, candidate: c, lastUsed: b.nowFn()}
+		b.stats[key] = st
+	}
+	st.candidate.Weight = c.Weight
+	return st
+}
+
+func (b *Balancer) evictLocked() {
+	var oldest string
+	var at time.Time
+	for k, st := range b.stats {
+		if oldest == "" || st.lastUsed.Before(at) {
+			oldest, at = k, st.lastUsed
+		}
+	}
+	delete(b.stats, oldest)
+}
+
+// Order returns candidates in the order they should be tried: available
+// candidates by weighted random draw (without replacement), then candidates
+// in cooldown in their configured order so RunWithFailover can still probe
+// them.
+func (b *Balancer) Order(scope string, candidates []ModelCandidate) []ModelCandidate {
+	tracker := b.Tracker(scope)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.nowFn()
+
+	var bestP50 time.Duration
+	all := make([]*candidateStats, len(candidates))
+	for i, c := range candidates {
+		all[i] = b.statsLocked(scope, c)
+		if p50 := all[i].percentile(0.5); p50 > 0 && (bestP50 == 0 || p50 < bestP50) {
+			bestP50 = p50
+		}
+	}
+
+	type weighted struct {
+		c ModelCandidate
+		w float64
+	}
+	var pool []weighted
+	var cooling []ModelCandidate
+	for i, c := range candidates {
+		st := all[i]
+		st.effectiveWeight = st.weight(bestP50, now)
+		if !tracker.IsAvailable(CooldownKey(c.Provider, c.Model)) {
+			cooling = append(cooling, c)
+			continue
+		}
+		pool = append(pool, weighted{c, st.effectiveWeight})
+	}
+
+	ordered := make([]ModelCandidate, 0, len(candidates))
+	for len(pool) > 0 {
+		total := 0.0
+		for _, p := range pool {
+			total += p.w
+		}
+		pick, r := len(pool)-1, b.randFn()*total
+		for i, p := range pool {
+			if r < p.w {
+				pick = i
+				break
+			}
+			r -= p.w
+		}
+		ordered = append(ordered, pool[pick].c)
+		pool = append(pool[:pick], pool[pick+1:]...)
+	}
+	return append(ordered, cooling...)
+}
+
+// Record adds one finished call. latency is the time to the first streamed
+// token, or the full call duration when nothing was streamed.
+func (b *Balancer) Record(scope string, c ModelCandidate, latency time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := b.statsLocked(scope, c)
+	st.lastUsed = b.nowFn()
+	st.requests++
+	if err != nil {
+		st.errors++
+	} else if latency > 0 {
+		if len(st.latencies) < balancerWindow {
+			st.latencies = append(st.latencies, latency)
+		} else {
+			st.latencies[st.latNext] = latency
+		}
+		st.latNext = (st.latNext + 1) % balancerWindow
+	}
+	if len(st.outcomes) < balancerWindow {
+		st.outcomes = append(st.outcomes, err != nil)
+	} else {
+		st.outcomes[st.outNext] = err != nil
+	}
+	st.outNext = (st.outNext + 1) % balancerWindow
+}
+
+// RecordRateLimit stores the latest quota headers of a candidate.
+func (b *Balancer) RecordRateLimit(scope string, c ModelCandidate, s RateLimitState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.statsLocked(scope, c).rateLimit = s
+}
+
+// RecordHedge counts a hedged request fired at c.
+func (b *Balancer) RecordHedge(scope string, c ModelCandidate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.statsLocked(scope, c).hedges++
+}
+
+// Snapshot returns the health of every tracked candidate in scope
+// (all scopes when scope is empty), sorted by scope, provider and model.
+func (b *Balancer) Snapshot(scope string) []CandidateHealth {
+	b.mu.Lock()
+	stats := make([]*candidateStats, 0, len(b.stats))
+	for _, st := range b.stats {
+		if scope == "" || st.scope == scope {
+			stats = append(stats, st)
+		}
+	}
+	out := make([]CandidateHealth, 0, len(stats))
+	for _, st := range stats {
+		out = append(out, CandidateHealth{
+			Scope:           st.scope,
+			Provider:        st.candidate.Provider,
+			Model:           st.candidate.Model,
+			ProfileID:       st.candidate.ProfileID,
+			Weight:          baseWeight(st.candidate.Weight),
+			EffectiveWeight: st.effectiveWeight,
+			P50Ms:           st.percentile(0.5).Milliseconds(),
+			P95Ms:           st.percentile(0.95).Milliseconds(),
+			ErrorRate:       st.errorRate(),
+			Samples:         len(st.outcomes),
+			Requests:        st.requests,
+			Errors:          st.errors,
+			Hedges:          st.hedges,
+			RateLimit:       st.rateLimit,
+			LastUsed:        st.lastUsed,
+		})
+	}
+	b.mu.Unlock()
+
+	for i := range out {
+		out[i].InCooldown = !b.Tracker(out[i].Scope).IsAvailable(CooldownKey(out[i].Provider, out[i].Model))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Scope != out[j].Scope {
+			return out[i].Scope < out[j].Scope
+		}
+		if out[i].Provider != out[j].Provider {
+			return out[i].Provider < out[j].Provider
+		}
+		return out[i].Model < out[j].Model
+	})
+	return out
+}
+
+func baseWeight(w float64) float64 {
+	if w <= 0 {
+		return 1
+	}
+	return w
+}
+
+// weight is the candidate's share relative to its peers: base weight scaled
+// by latency against the fastest peer, squared success rate and rate-limit
+// headroom below 10%. Never drops under balancerMinFactor of the base.
+func (st *candidateStats) weight(bestP50 time.Duration, now time.Time) float64 {
+	base := baseWeight(st.candidate.Weight)
+	w := base
+	if p50 := st.percentile(0.5); p50 > 0 && bestP50 > 0 {
+		w *= float64(bestP50) / float64(p50)
+	}
+	ok := 1 - st.errorRate()
+	w *= ok * ok
+	if h := st.rateLimit.headroom(now); h < 0.1 {
+		w *= h * 10
+	}
+	return max(w, base*balancerMinFactor)
+}
+
+func (st *candidateStats) percentile(p float64) time.Duration {
+	if len(st.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), st.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(p*float64(len(sorted)-1)+0.5)]
+}
+
+func (st *candidateStats) errorRate() float64 {
+	if len(st.outcomes) == 0 {
+		return 0
+	}
+	n := 0
+	for _, failed := range st.outcomes {
+		if failed {
+			n++
+		}
+	}
+	return float64(n) / float64(len(st.outcomes))
+}
@@ -0,0 +1,193 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// delayProvider answers after a fixed delay, or fails, honoring ctx.
+type delayProvider struct {
+	name    string
+	delay   time.Duration
+	err     error
+	failMid bool // stream one chunk, then fail
+}
+
+func (p *delayProvider) Name() string         { return p.name }
+func (p *delayProvider) DefaultModel() string { return "m" }
+
+func (p *delayProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &ChatResponse{Content: p.name, FinishReason: "stop"}, nil
+}
+
+func (p *delayProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	if p.failMid {
+		onChunk(StreamChunk{Content: "partial"})
+		return nil, &HTTPError{Status: 503, Body: "stream reset"}
+	}
+	resp, err := p.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	onChunk(StreamChunk{Content: resp.Content})
+	return resp, nil
+}
+
+func TestBalancer_OrderPrefersFastHealthyCandidates(t *testing.T) {
+	b := NewBalancer()
+	fast := ModelCandidate{Provider: "fast", Model: "m"}
+	slow := ModelCandidate{Provider: "slow", Model: "m"}
+	for range 20 {
+		b.Record("t", fast, 100*time.Millisecond, nil)
+		b.Record("t", slow, time.Second, nil)
+	}
+
+	first := map[string]int{}
+	for range 1000 {
+		first[b.Order("t", []ModelCandidate{slow, fast})[0].Provider]++
+	}
+	// Weights 1 vs 0.1: fast should lead roughly 90% of the time.
+	if first["fast"] < 800 {
+		t.Errorf("fast first %d/1000, want most", first["fast"])
+	}
+
+	// A candidate in cooldown always goes last.
+	for range 3 {
+		b.Tracker("t").RecordFailure(CooldownKey("fast", "m"), FailoverRateLimit)
+	}
+	if got := b.Order("t", []ModelCandidate{fast, slow}); got[0] != slow || got[1] != fast {
+		t.Errorf("order with fast in cooldown = %v", got)
+	}
+
+	// Scopes are isolated.
+	if snap := b.Snapshot("other"); len(snap) != 0 {
+		t.Errorf("other scope snapshot = %v", snap)
+	}
+	snap := b.Snapshot("t")
+	if len(snap) != 2 || snap[0].Provider != "fast" || snap[0].P50Ms != 100 || !snap[0].InCooldown {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestBalancer_RateLimitAndErrorsReduceWeight(t *testing.T) {
+	b := NewBalancer()
+	c := ModelCandidate{Provider: "p", Model: "m", Weight: 2}
+	b.Order("t", []ModelCandidate{c})
+	if w := b.Snapshot("t")[0].EffectiveWeight; w != 2 {
+		t.Fatalf("fresh weight = %v, want 2", w)
+	}
+
+	b.RecordRateLimit("t", c, RateLimitState{Known: true, RequestsRemaining: 1, RequestsLimit: 100})
+	b.Record("t", c, 0, errors.New("boom"))
+	b.Order("t", []ModelCandidate{c})
+	if w := b.Snapshot("t")[0].EffectiveWeight; w != 2*balancerMinFactor {
+		t.Errorf("degraded weight = %v, want floor %v", w, 2*balancerMinFactor)
+	}
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	h := http.Header{}
+	h.Set("x-ratelimit-remaining-requests", "10")
+	h.Set("x-ratelimit-limit-requests", "100")
+	h.Set("x-ratelimit-reset-requests", "30s")
+	s := ParseRateLimitHeaders(h, now)
+	if !s.Known || s.RequestsRemaining != 10 || !s.ResetAt.Equal(now.Add(30*time.Second)) {
+		t.Errorf("openai headers = %+v", s)
+	}
+	if got := s.headroom(now); got != 0.1 {
+		t.Errorf("headroom = %v, want 0.1", got)
+	}
+	if got := s.headroom(now.Add(time.Minute)); got != 1 {
+		t.Errorf("headroom after reset = %v, want 1", got)
+	}
+
+	h = http.Header{}
+	h.Set("anthropic-ratelimit-tokens-remaining", "500")
+	h.Set("anthropic-ratelimit-tokens-limit", "1000")
+	h.Set("anthropic-ratelimit-requests-reset", "2026-10-16T12:01:00Z")
+	if s := ParseRateLimitHeaders(h, now); s.TokensRemaining != 500 || s.ResetAt.IsZero() {
+		t.Errorf("anthropic headers = %+v", s)
+	}
+	if s := ParseRateLimitHeaders(http.Header{}, now); s.Known {
+		t.Errorf("empty headers known = %+v", s)
+	}
+}
+
+func newBalancedTestProvider(hedgeAfter time.Duration, ps ...*delayProvider) *BalancedProvider {
+	tenantID := uuid.New()
+	registry := NewRegistry(nil)
+	candidates := make([]ModelCandidate, len(ps))
+	for i, p := range ps {
+		registry.RegisterForTenant(tenantID, p)
+		candidates[i] = ModelCandidate{Provider: p.name, Model: "m"}
+	}
+	// Pin the order to the configured one.
+	registry.Balancer().randFn = func() float64 { return 0 }
+	return NewBalancedProvider(tenantID, registry, ps[0].name, candidates, hedgeAfter)
+}
+
+func TestBalancedProvider_HedgeWinsOverSlowPrimary(t *testing.T) {
+	bp := newBalancedTestProvider(20*time.Millisecond,
+		&delayProvider{name: "slow", delay: 2 * time.Second},
+		&delayProvider{name: "quick", delay: 5 * time.Millisecond},
+	)
+	start := time.Now()
+	var chunks []string
+	resp, err := bp.ChatStream(context.Background(), ChatRequest{}, func(c StreamChunk) { chunks = append(chunks, c.Content) })
+	if err != nil || resp.Content != "quick" {
+		t.Fatalf("ChatStream = %v, %v", resp, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hedge did not cancel slow primary: took %v", elapsed)
+	}
+	if len(chunks) != 1 || chunks[0] != "quick" {
+		t.Errorf("chunks = %q, want only the winner's", chunks)
+	}
+
+	snap := bp.registry.Balancer().Snapshot(bp.tenantID.String())
+	for _, c := range snap {
+		if c.Errors != 0 {
+			t.Errorf("%s counted %d errors; hedge losers must not be", c.Provider, c.Errors)
+		}
+		if c.Provider == "quick" && c.Hedges != 1 {
+			t.Errorf("quick hedges = %d, want 1", c.Hedges)
+		}
+	}
+}
+
+func TestBalancedProvider_FailoverAndStreamCommit(t *testing.T) {
+	bp := newBalancedTestProvider(0,
+		&delayProvider{name: "down", err: &HTTPError{Status: 503, Body: "overloaded"}},
+		&delayProvider{name: "up"},
+	)
+	resp, err := bp.Chat(context.Background(), ChatRequest{})
+	if err != nil || resp.Content != "up" {
+		t.Fatalf("Chat failover = %v, %v", resp, err)
+	}
+
+	// A candidate that already streamed must not be retried elsewhere.
+	bp = newBalancedTestProvider(0,
+		&delayProvider{name: "midfail", failMid: true},
+		&delayProvider{name: "up"},
+	)
+	var chunks []string
+	_, err = bp.ChatStream(context.Background(), ChatRequest{}, func(c StreamChunk) { chunks = append(chunks, c.Content) })
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || len(chunks) != 1 {
+		t.Errorf("mid-stream failure: err=%v chunks=%q", err, chunks)
+	}
+}
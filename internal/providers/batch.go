@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BatchPriceMultiplier is the fraction of the interactive price charged for
+// requests run through the Anthropic Message Batches and OpenAI Batch APIs.
+const BatchPriceMultiplier = 0.5
+
+// Batch status values reported by BatchCapable.PollBatch.
+const (
+	BatchStatusInProgress = "in_progress" // still processing
+	BatchStatusEnded      = "ended"       // results available; individual items may still have failed
+	BatchStatusFailed     = "failed"      // whole batch failed, expired or was cancelled; no results
+)
+
+// BatchRequest is one request in a provider batch submission. CustomID is
+// echoed back on the matching BatchResult.
+type BatchRequest struct {
+	CustomID string
+	Request  ChatRequest
+}
+
+// BatchResult is the outcome of one BatchRequest. Exactly one of Response
+// and Error is set.
+type BatchResult struct {
+	CustomID string
+	Response *ChatResponse
+	Error    string
+}
+
+// BatchPoll is the state of a submitted batch.
+type BatchPoll struct {
+	Status  string        // BatchStatus* constant
+	Results []BatchResult // set when Status == BatchStatusEnded
+	Error   string        // set when Status == BatchStatusFailed
+}
+
+// BatchCapable is optionally implemented by providers that can run requests
+// asynchronously through a discounted batch API. Results typically arrive
+// within minutes and at most 24 hours after submission, so only background
+// work that tolerates that latency should use it.
+type BatchCapable interface {
+	// SubmitBatch submits the requests as one provider batch and returns the
+	// provider's batch ID.
+	SubmitBatch(ctx context.Context, reqs []BatchRequest) (string, error)
+
+	// PollBatch returns the batch status, with per-request results once ended.
+	PollBatch(ctx context.Context, batchID string) (*BatchPoll, error)
+}
+
+// SupportsBatch reports whether p can run requests through a batch API.
+func SupportsBatch(p Provider) bool {
+	if _, ok := p.(BatchCapable); !ok {
+		return false
+	}
+	if ca, ok := p.(CapabilitiesAware); ok {
+		return ca.Capabilities().Batch
+	}
+	return false
+}
+
+// batchWireRequest prepares a queued request for batch submission: batch
+// requests never stream, and non-object structured-output schemas are
+// wrapped the same way ChatStructured does for native providers, so
+// DecodeStructuredResponse can unwrap the result.
+func batchWireRequest(req ChatRequest) ChatRequest {
+	if req.ResponseFormat.wantsJSON() {
+		req.ResponseFormat, _ = nativeResponseFormat(req.ResponseFormat)
+	}
+	return req
+}
+
+// parseJSONLines decodes a newline-delimited JSON body, calling fn per line.
+func parseJSONLines(data []byte, fn func(line []byte) error) error {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !json.Valid([]byte(line)) {
+			return fmt.Errorf("invalid JSONL line: %.100s", line)
+		}
+		if err := fn([]byte(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAnthropicBatchSubmitAndPoll(t *testing.T) {
+	var submitted map[string]any
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("missing api key header")
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/messages/batches":
+			_ = json.NewDecoder(r.Body).Decode(&submitted)
+			w.Write([]byte(`{"id":"msgbatch_1","processing_status":"in_progress"}`))
+		case r.URL.Path == "/messages/batches/msgbatch_1":
+			fmt.Fprintf(w, `{"id":"msgbatch_1","processing_status":"ended","results_url":"%s/results/msgbatch_1"}`, srvURL)
+		case r.URL.Path == "/results/msgbatch_1":
+			w.Write([]byte(`{"custom_id":"b","result":{"type":"errored","error":{"type":"error","error":{"type":"overloaded_error"}}}}
+{"custom_id":"a","result":{"type":"succeeded","message":{"content":[{"type":"tool_use","id":"t1","name":"structured_output","input":{"result":["x","y"]}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":4}}}}
+`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	p := newTestAnthropicProvider(srv.URL)
+	if !SupportsBatch(p) {
+		t.Fatal("anthropic provider should support batch")
+	}
+	req := ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "tags?"}},
+		ResponseFormat: JSONSchemaFormat("tags", map[string]any{"type": "array", "items": map[string]any{"type": "string"}}),
+	}
+	id, err := p.SubmitBatch(context.Background(), []BatchRequest{{CustomID: "a", Request: req}, {CustomID: "b", Request: req}})
+	if err != nil || id != "msgbatch_1" {
+		t.Fatalf("SubmitBatch = %q, %v", id, err)
+	}
+	items := submitted["requests"].([]any)
+	params := items[0].(map[string]any)["params"].(map[string]any)
+	if _, ok := params["stream"]; ok || params["tool_choice"] == nil {
+		t.Errorf("params = %v", params)
+	}
+
+	poll, err := p.PollBatch(context.Background(), id)
+	if err != nil {
+		t.Fatalf("PollBatch: %v", err)
+	}
+	if poll.Status != BatchStatusEnded || len(poll.Results) != 2 {
+		t.Fatalf("poll = %+v", poll)
+	}
+	byID := map[string]BatchResult{}
+	for _, r := range poll.Results {
+		byID[r.CustomID] = r
+	}
+	if !strings.Contains(byID["b"].Error, "errored") {
+		t.Errorf("b error = %q", byID["b"].Error)
+	}
+	resp := byID["a"].Response
+	if err := DecodeStructuredResponse(req.ResponseFormat, resp); err != nil {
+		t.Fatalf("DecodeStructuredResponse: %v", err)
+	}
+	if resp.Content != `["x","y"]` || resp.Usage.PromptTokens != 10 {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestOpenAIBatchSubmitAndPoll(t *testing.T) {
+	var uploaded string
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/files" && r.Method == http.MethodPost:
+			if r.FormValue("purpose") != "batch" {
+				t.Errorf("purpose = %q", r.FormValue("purpose"))
+			}
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("form file: %v", err)
+			}
+			data, _ := io.ReadAll(f)
+			uploaded = string(data)
+			w.Write([]byte(`{"id":"file-in"}`))
+		case r.URL.Path == "/batches" && r.Method == http.MethodPost:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["input_file_id"] != "file-in" || body["endpoint"] != openAIBatchEndpoint {
+				t.Errorf("create body = %v", body)
+			}
+			w.Write([]byte(`{"id":"batch_1","status":"validating"}`))
+		case r.URL.Path == "/batches/batch_1":
+			polls++
+			if polls == 1 {
+				w.Write([]byte(`{"id":"batch_1","status":"in_progress"}`))
+				return
+			}
+			w.Write([]byte(`{"id":"batch_1","status":"completed","output_file_id":"file-out","error_file_id":"file-err"}`))
+		case r.URL.Path == "/files/file-out/content":
+			w.Write([]byte(`{"custom_id":"a","response":{"status_code":200,"body":{"choices":[{"message":{"role":"assistant","content":"hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}},"error":null}` + "\n"))
+		case r.URL.Path == "/files/file-err/content":
+			w.Write([]byte(`{"custom_id":"b","response":{"status_code":400,"body":{"error":{"message":"bad"}}},"error":null}` + "\n"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider("openai", "k", srv.URL, "gpt-4o-mini")
+	if SupportsBatch(p) {
+		t.Error("non-OpenAI endpoint must not advertise batch support")
+	}
+	id, err := p.SubmitBatch(context.Background(), []BatchRequest{
+		{CustomID: "a", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}}},
+		{CustomID: "b", Request: ChatRequest{Messages: []Message{{Role: "user", Content: "yo"}}}},
+	})
+	if err != nil || id != "batch_1" {
+		t.Fatalf("SubmitBatch = %q, %v", id, err)
+	}
+	lines := strings.Split(strings.TrimSpace(uploaded), "\n")
+	var first map[string]any
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	if len(lines) != 2 || first["custom_id"] != "a" || first["url"] != openAIBatchEndpoint {
+		t.Fatalf("uploaded = %s", uploaded)
+	}
+	if body := first["body"].(map[string]any); body["model"] != "gpt-4o-mini" || body["stream"] != nil {
+		t.Errorf("line body = %v", body)
+	}
+
+	if poll, err := p.PollBatch(context.Background(), id); err != nil || poll.Status != BatchStatusInProgress {
+		t.Fatalf("first poll = %+v, %v", poll, err)
+	}
+	poll, err := p.PollBatch(context.Background(), id)
+	if err != nil || poll.Status != BatchStatusEnded || len(poll.Results) != 2 {
+		t.Fatalf("second poll = %+v, %v", poll, err)
+	}
+	if r := poll.Results[0]; r.CustomID != "a" || r.Response.Content != "hello" || r.Response.Usage.TotalTokens != 6 {
+		t.Errorf("result a = %+v", r)
+	}
+	if r := poll.Results[1]; r.CustomID != "b" || !strings.Contains(r.Error, "HTTP 400") {
+		t.Errorf("result b = %+v", r)
+	}
+}
@@ -0,0 +1,474 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+const (
+	bedrockDefaultRegion = "us-east-1"
+	bedrockDefaultModel  = "anthropic.claude-sonnet-4-5-20250929-v1:0"
+	bedrockSigningName   = "bedrock"
+)
+
+// BedrockProvider implements Provider using the AWS Bedrock Converse and
+// ConverseStream APIs via net/http. Requests are SigV4-signed with credentials
+// from the AWS SDK config chain, or sent with a Bedrock API key (bearer token).
+type BedrockProvider struct {
+	name         string
+	region       string
+	baseURL      string
+	defaultModel string
+	creds        aws.CredentialsProvider // SigV4 credentials (nil when bearerToken is set)
+	bearerToken  string                  // Bedrock API key (long-term or short-term)
+	signer       *v4.Signer
+	client       *http.Client
+	retryConfig  RetryConfig
+}
+
+type BedrockOption func(*BedrockProvider)
+
+// WithBedrockName overrides the provider name (default: "bedrock").
+func WithBedrockName(name string) BedrockOption {
+	return func(p *BedrockProvider) {
+		if name != "" {
+			p.name = name
+		}
+	}
+}
+
+func WithBedrockModel(model string) BedrockOption {
+	return func(p *BedrockProvider) {
+		if model != "" {
+			p.defaultModel = model
+		}
+	}
+}
+
+// WithBedrockBaseURL overrides the regional runtime endpoint (VPC endpoints, tests).
+func WithBedrockBaseURL(baseURL string) BedrockOption {
+	return func(p *BedrockProvider) {
+		if baseURL != "" {
+			p.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithBedrockCredentials sets the SigV4 credentials provider.
+func WithBedrockCredentials(creds aws.CredentialsProvider) BedrockOption {
+	return func(p *BedrockProvider) { p.creds = creds }
+}
+
+// WithBedrockBearerToken authenticates with a Bedrock API key instead of SigV4.
+func WithBedrockBearerToken(token string) BedrockOption {
+	return func(p *BedrockProvider) { p.bearerToken = token }
+}
+
+// NewBedrockProvider creates a Bedrock provider for the given region.
+func NewBedrockProvider(region string, opts ...BedrockOption) *BedrockProvider {
+	if region == "" {
+		region = bedrockDefaultRegion
+	}
+	p := &BedrockProvider{
+		name:         "bedrock",
+		region:       region,
+		baseURL:      BedrockRuntimeEndpoint(region),
+		defaultModel: bedrockDefaultModel,
+		signer:       v4.NewSigner(),
+		client:       NewDefaultHTTPClient(),
+		retryConfig:  DefaultRetryConfig(),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// NewBedrockProviderFromKey builds a provider from stored provider fields.
+// Region resolves as: explicit region → api_base host → us-east-1.
+// Auth is resolved from apiKey via BedrockAuthOptions.
+func NewBedrockProviderFromKey(ctx context.Context, name, region, apiBase, apiKey string, opts ...BedrockOption) (*BedrockProvider, error) {
+	if region == "" {
+		region = BedrockRegionFromBaseURL(apiBase)
+	}
+	authOpts, err := BedrockAuthOptions(ctx, region, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	all := append([]BedrockOption{WithBedrockName(name), WithBedrockBaseURL(apiBase)}, authOpts...)
+	return NewBedrockProvider(region, append(all, opts...)...), nil
+}
+
+// BedrockRuntimeEndpoint returns the public bedrock-runtime endpoint for a region.
+func BedrockRuntimeEndpoint(region string) string {
+	return "https://bedrock-runtime." + region + ".amazonaws.com"
+}
+
+// BedrockAuthOptions resolves auth from a stored API key:
+//   - "ACCESS_KEY_ID:SECRET_ACCESS_KEY[:SESSION_TOKEN]" → static SigV4 credentials
+//   - any other non-empty value → Bedrock API key (bearer token)
+//   - empty → AWS default credential chain (env, shared config, IMDS/IRSA)
+func BedrockAuthOptions(ctx context.Context, region, apiKey string) ([]BedrockOption, error) {
+	if apiKey != "" {
+		// Bedrock API keys are base64 and never contain ':'.
+		if parts := strings.SplitN(apiKey, ":", 3); len(parts) >= 2 {
+			session := ""
+			if len(parts) == 3 {
+				session = parts[2]
+			}
+			return []BedrockOption{WithBedrockCredentials(aws.NewCredentialsCache(
+				credentials.NewStaticCredentialsProvider(parts[0], parts[1], session),
+			))}, nil
+		}
+		return []BedrockOption{WithBedrockBearerToken(apiKey)}, nil
+	}
+	if region == "" {
+		region = bedrockDefaultRegion
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: load aws config: %w", err)
+	}
+	return []BedrockOption{WithBedrockCredentials(awsCfg.Credentials)}, nil
+}
+
+// BedrockRegionFromBaseURL extracts the region from a bedrock-runtime endpoint URL.
+// Returns "" when the URL does not follow the bedrock-runtime.{region}.amazonaws.com form.
+func BedrockRegionFromBaseURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	host := u.Hostname()
+	rest, ok := strings.CutPrefix(host, "bedrock-runtime.")
+	if !ok {
+		// VPC endpoints: vpce-xxx.bedrock-runtime.{region}.vpce.amazonaws.com
+		if _, after, found := strings.Cut(host, ".bedrock-runtime."); found {
+			rest = after
+		} else {
+			return ""
+		}
+	}
+	region, _, _ := strings.Cut(rest, ".")
+	return region
+}
+
+func (p *BedrockProvider) Name() string           { return p.name }
+func (p *BedrockProvider) DefaultModel() string   { return p.defaultModel }
+func (p *BedrockProvider) SupportsThinking() bool { return true }
+
+// Capabilities implements CapabilitiesAware for pipeline code-path selection.
+func (p *BedrockProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Streaming:        true,
+		ToolCalling:      true,
+		StreamWithTools:  true,
+		Thinking:         true,
+		Vision:           true,
+		CacheControl:     true,
+		StructuredOutput: true,
+		MaxContextWindow: 200_000,
+		TokenizerID:      "cl100k_base",
+	}
+}
+
+func (p *BedrockProvider) resolveModel(model string) string {
+	if model == "" {
+		return p.defaultModel
+	}
+	return model
+}
+
+func (p *BedrockProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	body := p.buildRequestBody(model, req)
+
+	resp, err := RetryDo(ctx, p.retryConfig, func() (*ChatResponse, error) {
+		respBody, err := p.doRequest(ctx, model, "converse", body)
+		if err != nil {
+			return nil, err
+		}
+		defer respBody.Close()
+
+		var parsed bedrockConverseResponse
+		if err := json.NewDecoder(respBody).Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("bedrock: decode response: %w", err)
+		}
+		return p.parseResponse(&parsed), nil
+	})
+	if resp != nil {
+		if strip, _ := req.Options[OptStripThinking].(bool); strip {
+			resp.Thinking = ""
+		}
+		takeStructuredToolCall(req.ResponseFormat, resp)
+	}
+	return resp, err
+}
+
+// doRequest POSTs to /model/{modelId}/{action} and returns the body on 200.
+func (p *BedrockProvider) doRequest(ctx context.Context, model, action string, body any) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: create request: %w", err)
+	}
+	// Model IDs contain ':' (e.g. "...-v1:0") and may be ARNs with '/'; both must be
+	// percent-encoded in the path so the SigV4 canonical URI matches what AWS computes.
+	basePath := strings.TrimRight(httpReq.URL.Path, "/")
+	httpReq.URL.RawPath = strings.TrimRight(httpReq.URL.EscapedPath(), "/") +
+		"/model/" + strings.ReplaceAll(url.PathEscape(model), ":", "%3A") + "/" + action
+	httpReq.URL.Path = basePath + "/model/" + model + "/" + action
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if action == "converse-stream" {
+		httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+
+	if err := p.authorize(ctx, httpReq, data); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock: request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		errType := resp.Header.Get("X-Amzn-ErrorType")
+		if i := strings.IndexByte(errType, ':'); i >= 0 {
+			errType = errType[:i]
+		}
+		msg := string(respBody)
+		if errType != "" {
+			msg = errType + ": " + msg
+		}
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       fmt.Sprintf("bedrock: %s", msg),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return resp.Body, nil
+}
+
+// authorize signs the request with SigV4, or sets the bearer token for API keys.
+func (p *BedrockProvider) authorize(ctx context.Context, req *http.Request, payload []byte) error {
+	if p.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.bearerToken)
+		return nil
+	}
+	if p.creds == nil {
+		return fmt.Errorf("bedrock: no credentials configured")
+	}
+	creds, err := p.creds.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("bedrock: retrieve credentials: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	if err := p.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), bedrockSigningName, p.region, time.Now()); err != nil {
+		return fmt.Errorf("bedrock: sign request: %w", err)
+	}
+	return nil
+}
+
+func (p *BedrockProvider) parseResponse(resp *bedrockConverseResponse) *ChatResponse {
+	result := &ChatResponse{}
+	thinkingChars := 0
+
+	for _, block := range resp.Output.Message.Content {
+		switch {
+		case block.Text != nil:
+			result.Content += *block.Text
+		case block.ReasoningContent != nil:
+			if rt := block.ReasoningContent.ReasoningText; rt != nil {
+				result.Thinking += rt.Text
+				thinkingChars += len(rt.Text)
+				if rt.Signature != "" {
+					result.ThinkingSignature = rt.Signature
+				}
+			}
+		case block.ToolUse != nil:
+			args := make(map[string]any)
+			var parseErr string
+			if err := json.Unmarshal(block.ToolUse.Input, &args); err != nil && len(block.ToolUse.Input) > 0 {
+				parseErr = fmt.Sprintf("malformed JSON (%d chars): %v", len(block.ToolUse.Input), err)
+			}
+			result.ToolCalls = append(result.ToolCalls, ToolCall{
+				ID:         block.ToolUse.ToolUseID,
+				Name:       strings.TrimSpace(block.ToolUse.Name),
+				Arguments:  args,
+				ParseError: parseErr,
+			})
+		}
+	}
+
+	result.FinishReason = bedrockFinishReason(resp.StopReason)
+	result.Usage = resp.Usage.toUsage()
+	if thinkingChars > 0 {
+		result.Usage.ThinkingTokens = thinkingChars / 4
+	}
+
+	// Preserve raw content blocks so reasoning blocks (with signatures) are replayed
+	// verbatim on the next tool-loop iteration, as Claude-on-Bedrock requires.
+	if len(result.ToolCalls) > 0 {
+		if b, err := json.Marshal(resp.Output.Message.Content); err == nil {
+			result.RawAssistantContent = b
+		}
+	}
+
+	return result
+}
+
+// bedrockFinishReason maps Converse stopReason values onto the internal vocabulary.
+func bedrockFinishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens", "model_context_window_exceeded":
+		return "length"
+	case "guardrail_intervened", "content_filtered":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+// --- Bedrock Converse API types (internal) ---
+
+type bedrockConverseResponse struct {
+	Output struct {
+		Message struct {
+			Role    string                `json:"role"`
+			Content []bedrockContentBlock `json:"content"`
+		} `json:"message"`
+	} `json:"output"`
+	StopReason string       `json:"stopReason"`
+	Usage      bedrockUsage `json:"usage"`
+}
+
+// bedrockContentBlock is a Converse content block. Exactly one field is set.
+type bedrockContentBlock struct {
+	Text             *string                  `json:"text,omitempty"`
+	Image            *bedrockImageBlock       `json:"image,omitempty"`
+	ToolUse          *bedrockToolUseBlock     `json:"toolUse,omitempty"`
+	ToolResult       *bedrockToolResultBlock  `json:"toolResult,omitempty"`
+	ReasoningContent *bedrockReasoningContent `json:"reasoningContent,omitempty"`
+	CachePoint       *bedrockCachePoint       `json:"cachePoint,omitempty"`
+}
+
+type bedrockImageBlock struct {
+	Format string `json:"format"`
+	Source struct {
+		Bytes string `json:"bytes"` // base64
+	} `json:"source"`
+}
+
+type bedrockToolUseBlock struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+}
+
+type bedrockToolResultBlock struct {
+	ToolUseID string                `json:"toolUseId"`
+	Content   []bedrockContentBlock `json:"content"`
+	Status    string                `json:"status,omitempty"` // "success" | "error"
+}
+
+type bedrockReasoningContent struct {
+	ReasoningText   *bedrockReasoningText `json:"reasoningText,omitempty"`
+	RedactedContent string                `json:"redactedContent,omitempty"` // base64
+}
+
+type bedrockReasoningText struct {
+	Text      string `json:"text"`
+	Signature string `json:"signature,omitempty"`
+}
+
+type bedrockCachePoint struct {
+	Type string `json:"type"` // "default"
+}
+
+type bedrockUsage struct {
+	InputTokens           int `json:"inputTokens"`
+	OutputTokens          int `json:"outputTokens"`
+	TotalTokens           int `json:"totalTokens"`
+	CacheReadInputTokens  int `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int `json:"cacheWriteInputTokens,omitempty"`
+}
+
+func (u bedrockUsage) toUsage() *Usage {
+	total := u.TotalTokens
+	if total == 0 {
+		total = u.InputTokens + u.OutputTokens
+	}
+	return &Usage{
+		PromptTokens:        u.InputTokens,
+		CompletionTokens:    u.OutputTokens,
+		TotalTokens:         total,
+		CacheCreationTokens: u.CacheWriteInputTokens,
+		CacheReadTokens:     u.CacheReadInputTokens,
+	}
+}
+
+// --- ConverseStream event payloads ---
+
+type bedrockContentBlockStartEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Start             struct {
+		ToolUse *struct {
+			ToolUseID string `json:"toolUseId"`
+			Name      string `json:"name"`
+		} `json:"toolUse,omitempty"`
+	} `json:"start"`
+}
+
+type bedrockContentBlockDeltaEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+	Delta             struct {
+		Text    *string `json:"text,omitempty"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse,omitempty"`
+		ReasoningContent *struct {
+			Text            *string `json:"text,omitempty"`
+			Signature       string  `json:"signature,omitempty"`
+			RedactedContent string  `json:"redactedContent,omitempty"`
+		} `json:"reasoningContent,omitempty"`
+	} `json:"delta"`
+}
+
+type bedrockMessageStopEvent struct {
+	StopReason string `json:"stopReason"`
+}
+
+type bedrockMetadataEvent struct {
+	Usage *bedrockUsage `json:"usage,omitempty"`
+}
+
+type bedrockExceptionEvent struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// bedrockMaxEventSize caps a single event-stream frame (AWS limit is 16 MB).
+const bedrockMaxEventSize = 16 << 20
+
+// bedrockEvent is one decoded frame of the AWS event-stream binary protocol
+// (application/vnd.amazon.eventstream) used by ConverseStream.
+type bedrockEvent struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// MessageType returns the ":message-type" header ("event" or "exception").
+func (e *bedrockEvent) MessageType() string { return e.Headers[":message-type"] }
+
+// EventType returns the ":event-type" header (e.g. "contentBlockDelta").
+func (e *bedrockEvent) EventType() string { return e.Headers[":event-type"] }
+
+// ExceptionType returns the ":exception-type" header for exception frames.
+func (e *bedrockEvent) ExceptionType() string { return e.Headers[":exception-type"] }
+
+// bedrockEventReader decodes event-stream frames from a response body.
+// Frame layout: total_len(4) headers_len(4) prelude_crc(4) headers payload message_crc(4).
+type bedrockEventReader struct {
+	r io.Reader
+}
+
+func newBedrockEventReader(r io.Reader) *bedrockEventReader {
+	return &bedrockEventReader{r: r}
+}
+
+// Next reads the next frame. Returns io.EOF at a clean end of stream.
+func (br *bedrockEventReader) Next() (*bedrockEvent, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(br.r, prelude[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("bedrock eventstream: truncated prelude")
+		}
+		return nil, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, fmt.Errorf("bedrock eventstream: prelude checksum mismatch")
+	}
+	if totalLen < 16 || totalLen > bedrockMaxEventSize || headersLen > totalLen-16 {
+		return nil, fmt.Errorf("bedrock eventstream: invalid frame length %d (headers %d)", totalLen, headersLen)
+	}
+
+	frame := make([]byte, totalLen)
+	copy(frame, prelude[:])
+	if _, err := io.ReadFull(br.r, frame[12:]); err != nil {
+		return nil, fmt.Errorf("bedrock eventstream: truncated frame: %w", err)
+	}
+	if crc32.ChecksumIEEE(frame[:totalLen-4]) != binary.BigEndian.Uint32(frame[totalLen-4:]) {
+		return nil, fmt.Errorf("bedrock eventstream: message checksum mismatch")
+	}
+
+	headers, err := decodeBedrockHeaders(frame[12 : 12+headersLen])
+	if err != nil {
+		return nil, err
+	}
+	return &bedrockEvent{
+		Headers: headers,
+		Payload: frame[12+headersLen : totalLen-4],
+	}, nil
+}
+
+// decodeBedrockHeaders parses event-stream headers. Only string headers (type 7)
+// carry information Converse needs; other value types are skipped by size.
+func decodeBedrockHeaders(b []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, fmt.Errorf("bedrock eventstream: truncated header name")
+		}
+		name := string(b[1 : 1+nameLen])
+		b = b[1+nameLen:]
+		valueType := b[0]
+		b = b[1:]
+
+		var skip int
+		switch valueType {
+		case 0, 1: // bool true / false
+			skip = 0
+		case 2: // byte
+			skip = 1
+		case 3: // int16
+			skip = 2
+		case 4: // int32
+			skip = 4
+		case 5, 8: // int64, timestamp
+			skip = 8
+		case 9: // uuid
+			skip = 16
+		case 6, 7: // bytes, string (2-byte length prefix)
+			if len(b) < 2 {
+				return nil, fmt.Errorf("bedrock eventstream: truncated header %q", name)
+			}
+			n := int(binary.BigEndian.Uint16(b[0:2]))
+			if len(b) < 2+n {
+				return nil, fmt.Errorf("bedrock eventstream: truncated header %q", name)
+			}
+			if valueType == 7 {
+				headers[name] = string(b[2 : 2+n])
+			}
+			b = b[2+n:]
+			continue
+		default:
+			return nil, fmt.Errorf("bedrock eventstream: unknown header type %d", valueType)
+		}
+		if len(b) < skip {
+			return nil, fmt.Errorf("bedrock eventstream: truncated header %q", name)
+		}
+		b = b[skip:]
+	}
+	return headers, nil
+}
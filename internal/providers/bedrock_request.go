@@ -0,0 +1,229 @@
+package providers
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// bedrockSupportsPromptCache reports whether the model accepts cachePoint blocks.
+// Bedrock prompt caching is available for Anthropic Claude and Amazon Nova models;
+// other families (Llama, Mistral, ...) reject the block with a ValidationException.
+func bedrockSupportsPromptCache(model string) bool {
+	m := strings.ToLower(model)
+	return strings.Contains(m, "anthropic.claude") || strings.Contains(m, "amazon.nova")
+}
+
+// bedrockSupportsThinking reports whether the model accepts the Anthropic
+// thinking block via additionalModelRequestFields.
+func bedrockSupportsThinking(model string) bool {
+	return strings.Contains(strings.ToLower(model), "anthropic.claude")
+}
+
+// ModelSupportsThinking implements ModelThinkingCapable.
+func (p *BedrockProvider) ModelSupportsThinking(model string) bool {
+	return bedrockSupportsThinking(p.resolveModel(model))
+}
+
+func bedrockText(s string) bedrockContentBlock {
+	return bedrockContentBlock{Text: &s}
+}
+
+// bedrockImageFormat maps a MIME type to a Converse image format.
+func bedrockImageFormat(mime string) string {
+	f := strings.TrimPrefix(strings.ToLower(mime), "image/")
+	if f == "jpg" {
+		return "jpeg"
+	}
+	return f
+}
+
+// bedrockRawBlocks returns RawAssistantContent as Converse content blocks when it
+// was produced by this provider. Anthropic-native blocks carry a "type" field and
+// are ignored so mixed-provider histories fall back to the structured fields.
+func bedrockRawBlocks(raw json.RawMessage) []bedrockContentBlock {
+	if len(raw) == 0 {
+		return nil
+	}
+	var probe []map[string]json.RawMessage
+	if json.Unmarshal(raw, &probe) != nil || len(probe) == 0 {
+		return nil
+	}
+	for _, b := range probe {
+		if _, hasType := b["type"]; hasType {
+			return nil
+		}
+	}
+	var blocks []bedrockContentBlock
+	if json.Unmarshal(raw, &blocks) != nil {
+		return nil
+	}
+	return blocks
+}
+
+func (p *BedrockProvider) buildRequestBody(model string, req ChatRequest) map[string]any {
+	cacheable := bedrockSupportsPromptCache(model)
+
+	var system []bedrockContentBlock
+	type turn struct {
+		Role    string                `json:"role"`
+		Content []bedrockContentBlock `json:"content"`
+	}
+	var messages []turn
+
+	// Converse requires strictly alternating roles, so consecutive same-role
+	// messages (e.g. parallel tool results) are merged into one turn.
+	appendTurn := func(role string, blocks []bedrockContentBlock) {
+		if len(blocks) == 0 {
+			return
+		}
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content = append(messages[n-1].Content, blocks...)
+			return
+		}
+		messages = append(messages, turn{Role: role, Content: blocks})
+	}
+
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case "system":
+			before, after, ok := strings.Cut(msg.Content, CacheBoundaryMarker)
+			if stable := strings.TrimSpace(before); stable != "" {
+				system = append(system, bedrockText(stable))
+			}
+			if cacheable {
+				system = append(system, bedrockContentBlock{CachePoint: &bedrockCachePoint{Type: "default"}})
+			}
+			if dynamic := strings.TrimSpace(after); ok && dynamic != "" {
+				system = append(system, bedrockText(dynamic))
+			}
+
+		case "user":
+			var blocks []bedrockContentBlock
+			for _, img := range msg.Images {
+				ib := &bedrockImageBlock{Format: bedrockImageFormat(img.MimeType)}
+				ib.Source.Bytes = img.Data
+				blocks = append(blocks, bedrockContentBlock{Image: ib})
+			}
+			if msg.Content != "" {
+				blocks = append(blocks, bedrockText(msg.Content))
+			}
+			appendTurn("user", blocks)
+
+		case "assistant":
+			if raw := bedrockRawBlocks(msg.RawAssistantContent); raw != nil {
+				appendTurn("assistant", raw)
+				continue
+			}
+			var blocks []bedrockContentBlock
+			if msg.Content != "" {
+				blocks = append(blocks, bedrockText(msg.Content))
+			}
+			for _, tc := range msg.ToolCalls {
+				input, _ := json.Marshal(tc.Arguments)
+				if tc.Arguments == nil {
+					input = []byte("{}")
+				}
+				blocks = append(blocks, bedrockContentBlock{ToolUse: &bedrockToolUseBlock{
+					ToolUseID: tc.ID,
+					Name:      tc.Name,
+					Input:     input,
+				}})
+			}
+			appendTurn("assistant", blocks)
+
+		case "tool":
+			content := msg.Content
+			if content == "" {
+				// Converse rejects empty text blocks.
+				content = "(no output)"
+			}
+			tr := &bedrockToolResultBlock{
+				ToolUseID: msg.ToolCallID,
+				Content:   []bedrockContentBlock{bedrockText(content)},
+			}
+			if msg.IsError {
+				tr.Status = "error"
+			}
+			appendTurn("user", []bedrockContentBlock{{ToolResult: tr}})
+		}
+	}
+
+	body := map[string]any{
+		"messages": messages,
+	}
+	if len(system) > 0 {
+		body["system"] = system
+	}
+
+	inference := map[string]any{"maxTokens": 4096}
+	if v, ok := req.Options[OptMaxTokens]; ok {
+		inference["maxTokens"] = v
+	}
+	if v, ok := req.Options[OptTemperature]; ok {
+		inference["temperature"] = v
+	}
+
+	if len(req.Tools) > 0 {
+		var tools []map[string]any
+		for _, t := range req.Tools {
+			tools = append(tools, map[string]any{
+				"toolSpec": map[string]any{
+					"name":        t.Function.Name,
+					"description": t.Function.Description,
+					"inputSchema": map[string]any{
+						"json": CleanSchemaForProvider("anthropic", t.Function.Parameters),
+					},
+				},
+			})
+		}
+		// Cache the tool definitions prefix (same breakpoint the Anthropic provider uses).
+		if cacheable {
+			tools = append(tools, map[string]any{"cachePoint": map[string]any{"type": "default"}})
+		}
+		body["toolConfig"] = map[string]any{"tools": tools}
+	}
+
+	// Structured output via a forced tool call, as in the Anthropic provider.
+	// toolChoice is supported by Claude, Nova and Mistral Large on Converse.
+	forceStructured := req.ResponseFormat.wantsJSON()
+	if forceStructured {
+		toolConfig, _ := body["toolConfig"].(map[string]any)
+		if toolConfig == nil {
+			toolConfig = map[string]any{}
+		}
+		tools, _ := toolConfig["tools"].([]map[string]any)
+		toolConfig["tools"] = append(tools, map[string]any{
+			"toolSpec": map[string]any{
+				"name":        structuredOutputToolName,
+				"description": structuredOutputToolDescription,
+				"inputSchema": map[string]any{
+					"json": CleanSchemaForProvider("anthropic", req.ResponseFormat.objectSchema()),
+				},
+			},
+		})
+		if len(req.Tools) == 0 {
+			toolConfig["toolChoice"] = map[string]any{"tool": map[string]any{"name": structuredOutputToolName}}
+		} else {
+			toolConfig["toolChoice"] = map[string]any{"any": map[string]any{}}
+		}
+		body["toolConfig"] = toolConfig
+	}
+
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" && level != "off" && bedrockSupportsThinking(model) && !forceStructured {
+		budget := anthropicThinkingBudget(level)
+		body["additionalModelRequestFields"] = map[string]any{
+			"thinking": map[string]any{
+				"type":          "enabled",
+				"budget_tokens": budget,
+			},
+		}
+		// Claude rejects temperature when thinking is enabled.
+		delete(inference, "temperature")
+		if maxTok, ok := inference["maxTokens"].(int); !ok || maxTok < budget+4096 {
+			inference["maxTokens"] = budget + 8192
+		}
+	}
+	body["inferenceConfig"] = inference
+
+	return body
+}
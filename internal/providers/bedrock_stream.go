@@ -0,0 +1,202 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (p *BedrockProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	stripThinking, _ := req.Options[OptStripThinking].(bool)
+	body := p.buildRequestBody(model, req)
+
+	// Retry only the connection phase; once streaming starts, no retry.
+	respBody, err := RetryDo(ctx, p.retryConfig, func() (io.ReadCloser, error) {
+		return p.doRequest(ctx, model, "converse-stream", body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cb := NewCtxBody(ctx, respBody)
+	defer cb.Close()
+
+	result := &ChatResponse{FinishReason: "stop"}
+	// Content blocks by contentBlockIndex, rebuilt for RawAssistantContent passback.
+	blocks := make(map[int]*bedrockContentBlock)
+	var order []int
+	toolCallIdx := make(map[int]int)     // contentBlockIndex → index in result.ToolCalls
+	toolCallJSON := make(map[int]string) // contentBlockIndex → accumulated input JSON
+	thinkingChars := 0
+	var thinkingSignature strings.Builder
+
+	blockAt := func(idx int) *bedrockContentBlock {
+		b, ok := blocks[idx]
+		if !ok {
+			b = &bedrockContentBlock{}
+			blocks[idx] = b
+			order = append(order, idx)
+		}
+		return b
+	}
+
+	events := newBedrockEventReader(cb)
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		ev, err := events.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bedrock stream read error: %w", err)
+		}
+
+		if ev.MessageType() == "exception" || ev.MessageType() == "error" {
+			var ex bedrockExceptionEvent
+			_ = json.Unmarshal(ev.Payload, &ex)
+			return nil, bedrockStreamError(ev.ExceptionType(), ex.Message)
+		}
+
+		switch ev.EventType() {
+		case "contentBlockStart":
+			var e bedrockContentBlockStartEvent
+			if json.Unmarshal(ev.Payload, &e) == nil && e.Start.ToolUse != nil {
+				b := blockAt(e.ContentBlockIndex)
+				b.ToolUse = &bedrockToolUseBlock{ToolUseID: e.Start.ToolUse.ToolUseID, Name: e.Start.ToolUse.Name}
+				toolCallIdx[e.ContentBlockIndex] = len(result.ToolCalls)
+				result.ToolCalls = append(result.ToolCalls, ToolCall{
+					ID:        e.Start.ToolUse.ToolUseID,
+					Name:      strings.TrimSpace(e.Start.ToolUse.Name),
+					Arguments: make(map[string]any),
+				})
+			}
+
+		case "contentBlockDelta":
+			var e bedrockContentBlockDeltaEvent
+			if json.Unmarshal(ev.Payload, &e) != nil {
+				continue
+			}
+			b := blockAt(e.ContentBlockIndex)
+			d := e.Delta
+			switch {
+			case d.Text != nil:
+				if b.Text == nil {
+					b.Text = new(string)
+				}
+				*b.Text += *d.Text
+				result.Content += *d.Text
+				if onChunk != nil {
+					onChunk(StreamChunk{Content: *d.Text})
+				}
+			case d.ToolUse != nil:
+				toolCallJSON[e.ContentBlockIndex] += d.ToolUse.Input
+			case d.ReasoningContent != nil:
+				rc := d.ReasoningContent
+				if b.ReasoningContent == nil {
+					b.ReasoningContent = &bedrockReasoningContent{}
+				}
+				if rc.RedactedContent != "" {
+					b.ReasoningContent.RedactedContent += rc.RedactedContent
+					continue
+				}
+				if b.ReasoningContent.ReasoningText == nil {
+					b.ReasoningContent.ReasoningText = &bedrockReasoningText{}
+				}
+				if rc.Signature != "" {
+					b.ReasoningContent.ReasoningText.Signature += rc.Signature
+					thinkingSignature.WriteString(rc.Signature)
+				}
+				if rc.Text != nil {
+					b.ReasoningContent.ReasoningText.Text += *rc.Text
+					thinkingChars += len(*rc.Text)
+					if !stripThinking {
+						result.Thinking += *rc.Text
+						if onChunk != nil {
+							onChunk(StreamChunk{Thinking: *rc.Text})
+						}
+					}
+				}
+			}
+
+		case "messageStop":
+			var e bedrockMessageStopEvent
+			if json.Unmarshal(ev.Payload, &e) == nil && e.StopReason != "" {
+				result.FinishReason = bedrockFinishReason(e.StopReason)
+			}
+
+		case "metadata":
+			var e bedrockMetadataEvent
+			if json.Unmarshal(ev.Payload, &e) == nil && e.Usage != nil {
+				result.Usage = e.Usage.toUsage()
+			}
+		}
+	}
+
+	// Parse accumulated tool call JSON arguments
+	for blockIdx, rawJSON := range toolCallJSON {
+		i, ok := toolCallIdx[blockIdx]
+		if !ok || rawJSON == "" {
+			continue
+		}
+		args := make(map[string]any)
+		if err := json.Unmarshal([]byte(rawJSON), &args); err != nil {
+			result.ToolCalls[i].ParseError = fmt.Sprintf("malformed JSON (%d chars): %v", len(rawJSON), err)
+		}
+		result.ToolCalls[i].Arguments = args
+		blocks[blockIdx].ToolUse.Input = json.RawMessage(rawJSON)
+	}
+
+	if result.Usage != nil && thinkingChars > 0 {
+		result.Usage.ThinkingTokens = thinkingChars / 4
+	}
+
+	if len(result.ToolCalls) > 0 {
+		raw := make([]bedrockContentBlock, 0, len(order))
+		for _, idx := range order {
+			b := blocks[idx]
+			if b.ToolUse != nil && len(b.ToolUse.Input) == 0 {
+				b.ToolUse.Input = json.RawMessage("{}")
+			}
+			raw = append(raw, *b)
+		}
+		if b, err := json.Marshal(raw); err == nil {
+			result.RawAssistantContent = b
+		}
+	}
+
+	result.ThinkingSignature = thinkingSignature.String()
+
+	if text := takeStructuredToolCall(req.ResponseFormat, result); text != "" && onChunk != nil {
+		onChunk(StreamChunk{Content: text})
+	}
+
+	if onChunk != nil {
+		onChunk(StreamChunk{Done: true})
+	}
+
+	return result, nil
+}
+
+// bedrockStreamError converts a mid-stream exception frame into an error that
+// the failover classifier understands (throttling → 429, unavailable → 503).
+func bedrockStreamError(exceptionType, message string) error {
+	status := 0
+	switch exceptionType {
+	case "throttlingException":
+		status = 429
+	case "serviceUnavailableException", "internalServerException", "modelStreamErrorException":
+		status = 503
+	case "validationException":
+		status = 400
+	}
+	body := fmt.Sprintf("bedrock: %s: %s", exceptionType, message)
+	if status == 0 {
+		return fmt.Errorf("bedrock stream error: %s: %s", exceptionType, message)
+	}
+	return &HTTPError{Status: status, Body: body}
+}
@@ -0,0 +1,306 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// encodeBedrockFrame builds one event-stream frame with string headers.
+func encodeBedrockFrame(headers map[string]string, payload string) []byte {
+	var hb bytes.Buffer
+	for k, v := range headers {
+		hb.WriteByte(byte(len(k)))
+		hb.WriteString(k)
+		hb.WriteByte(7)
+		_ = binary.Write(&hb, binary.BigEndian, uint16(len(v)))
+		hb.WriteString(v)
+	}
+	total := 12 + hb.Len() + len(payload) + 4
+	var f bytes.Buffer
+	_ = binary.Write(&f, binary.BigEndian, uint32(total))
+	_ = binary.Write(&f, binary.BigEndian, uint32(hb.Len()))
+	_ = binary.Write(&f, binary.BigEndian, crc32.ChecksumIEEE(f.Bytes()))
+	f.Write(hb.Bytes())
+	f.WriteString(payload)
+	_ = binary.Write(&f, binary.BigEndian, crc32.ChecksumIEEE(f.Bytes()))
+	return f.Bytes()
+}
+
+func bedrockEventFrame(eventType, payload string) []byte {
+	return encodeBedrockFrame(map[string]string{
+		":message-type": "event",
+		":event-type":   eventType,
+		":content-type": "application/json",
+	}, payload)
+}
+
+func newTestBedrockProvider(t *testing.T, handler http.HandlerFunc) *BedrockProvider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	creds := aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKIDTEST", "secret", ""))
+	return NewBedrockProvider("us-west-2",
+		WithBedrockBaseURL(srv.URL),
+		WithBedrockCredentials(creds),
+	)
+}
+
+func TestBedrockEventReader_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write(bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"text":"hi"}}`))
+	buf.Write(bedrockEventFrame("messageStop", `{"stopReason":"end_turn"}`))
+
+	r := newBedrockEventReader(&buf)
+	ev, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if ev.EventType() != "contentBlockDelta" || ev.MessageType() != "event" {
+		t.Errorf("headers = %v", ev.Headers)
+	}
+	if !strings.Contains(string(ev.Payload), `"hi"`) {
+		t.Errorf("payload = %s", ev.Payload)
+	}
+	if ev, err = r.Next(); err != nil || ev.EventType() != "messageStop" {
+		t.Fatalf("second frame: %v %v", ev, err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestBedrockEventReader_ChecksumMismatch(t *testing.T) {
+	frame := bedrockEventFrame("metadata", `{}`)
+	frame[len(frame)-6] ^= 0xff // corrupt payload byte
+	if _, err := newBedrockEventReader(bytes.NewReader(frame)).Next(); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+}
+
+func TestBedrockChat_SignsAndParsesToolUse(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]any
+	p := newTestBedrockProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = io.WriteString(w, `{
+			"output":{"message":{"role":"assistant","content":[
+				{"text":"Checking."},
+				{"toolUse":{"toolUseId":"tu_1","name":"get_weather","input":{"city":"Hanoi"}}}
+			]}},
+			"stopReason":"tool_use",
+			"usage":{"inputTokens":100,"outputTokens":20,"totalTokens":120,"cacheReadInputTokens":80,"cacheWriteInputTokens":10}
+		}`)
+	})
+
+	resp, err := p.Chat(context.Background(), ChatRequest{
+		Model: "anthropic.claude-sonnet-4-5-20250929-v1:0",
+		Messages: []Message{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "weather?"},
+		},
+		Tools: []ToolDefinition{{Type: "function", Function: ToolFunctionSchema{
+			Name: "get_weather", Parameters: map[string]any{"type": "object"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if gotPath != "/model/anthropic.claude-sonnet-4-5-20250929-v1%3A0/converse" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(gotAuth, "/us-west-2/bedrock/aws4_request") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if _, ok := gotBody["toolConfig"]; !ok {
+		t.Error("toolConfig missing from request body")
+	}
+
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.ToolCalls[0].ID != "tu_1" || resp.ToolCalls[0].Arguments["city"] != "Hanoi" {
+		t.Errorf("tool call = %+v", resp.ToolCalls[0])
+	}
+	if resp.Usage.PromptTokens != 100 || resp.Usage.CacheReadTokens != 80 || resp.Usage.CacheCreationTokens != 10 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+	if resp.RawAssistantContent == nil {
+		t.Error("RawAssistantContent should be preserved for tool passback")
+	}
+}
+
+func TestBedrockChatStream_AccumulatesDeltas(t *testing.T) {
+	p := newTestBedrockProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/converse-stream") {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, f := range [][]byte{
+			bedrockEventFrame("messageStart", `{"role":"assistant"}`),
+			bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"reasoningContent":{"text":"hmm"}}}`),
+			bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":0,"delta":{"reasoningContent":{"signature":"sig=="}}}`),
+			bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"text":"Hel"}}`),
+			bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":1,"delta":{"text":"lo"}}`),
+			bedrockEventFrame("contentBlockStart", `{"contentBlockIndex":2,"start":{"toolUse":{"toolUseId":"tu_9","name":"search"}}}`),
+			bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":2,"delta":{"toolUse":{"input":"{\"q\":"}}}`),
+			bedrockEventFrame("contentBlockDelta", `{"contentBlockIndex":2,"delta":{"toolUse":{"input":"\"go\"}"}}}`),
+			bedrockEventFrame("messageStop", `{"stopReason":"tool_use"}`),
+			bedrockEventFrame("metadata", `{"usage":{"inputTokens":50,"outputTokens":7,"totalTokens":57}}`),
+		} {
+			_, _ = w.Write(f)
+		}
+	})
+
+	var chunks []string
+	resp, err := p.ChatStream(context.Background(), ChatRequest{
+		Messages: []Message{{Role: "user", Content: "hi"}},
+	}, func(c StreamChunk) {
+		if c.Content != "" {
+			chunks = append(chunks, c.Content)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if resp.Content != "Hello" || strings.Join(chunks, "") != "Hello" {
+		t.Errorf("content = %q chunks = %v", resp.Content, chunks)
+	}
+	if resp.Thinking != "hmm" || resp.ThinkingSignature != "sig==" {
+		t.Errorf("thinking = %q sig = %q", resp.Thinking, resp.ThinkingSignature)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["q"] != "go" {
+		t.Fatalf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.FinishReason != "tool_calls" || resp.Usage == nil || resp.Usage.TotalTokens != 57 {
+		t.Errorf("finish = %q usage = %+v", resp.FinishReason, resp.Usage)
+	}
+
+	// Replayed raw blocks must round-trip into the next request verbatim.
+	raw := bedrockRawBlocks(resp.RawAssistantContent)
+	if len(raw) != 3 || raw[0].ReasoningContent == nil || raw[0].ReasoningContent.ReasoningText.Signature != "sig==" {
+		t.Errorf("raw blocks = %s", resp.RawAssistantContent)
+	}
+}
+
+func TestBedrockChatStream_ExceptionFrameClassified(t *testing.T) {
+	p := newTestBedrockProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(encodeBedrockFrame(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, `{"message":"Too many requests"}`))
+	})
+	_, err := p.ChatStream(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}}, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got := ClassifyHTTPError(NewDefaultClassifier(), err); got.Reason != FailoverRateLimit {
+		t.Errorf("classification = %+v, want rate_limit", got)
+	}
+}
+
+func TestBedrockBuildRequestBody_MergesToolResultsAndCaches(t *testing.T) {
+	p := NewBedrockProvider("us-east-1")
+	body := p.buildRequestBody("anthropic.claude-sonnet-4-5-20250929-v1:0", ChatRequest{
+		Messages: []Message{
+			{Role: "system", Content: "stable" + CacheBoundaryMarker + "dynamic"},
+			{Role: "user", Content: "go"},
+			{Role: "assistant", ToolCalls: []ToolCall{
+				{ID: "a", Name: "t1", Arguments: map[string]any{}},
+				{ID: "b", Name: "t2", Arguments: map[string]any{}},
+			}},
+			{Role: "tool", ToolCallID: "a", Content: "ok"},
+			{Role: "tool", ToolCallID: "b", Content: "", IsError: true},
+		},
+		Options: map[string]any{OptThinkingLevel: "low", OptTemperature: 0.3},
+	})
+
+	data, _ := json.Marshal(body)
+	var decoded struct {
+		System   []map[string]any `json:"system"`
+		Messages []struct {
+			Role    string                `json:"role"`
+			Content []bedrockContentBlock `json:"content"`
+		} `json:"messages"`
+		InferenceConfig map[string]any `json:"inferenceConfig"`
+		Additional      map[string]any `json:"additionalModelRequestFields"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.System) != 3 || decoded.System[1]["cachePoint"] == nil {
+		t.Errorf("system = %v", decoded.System)
+	}
+	if len(decoded.Messages) != 3 {
+		t.Fatalf("expected 3 alternating turns, got %d", len(decoded.Messages))
+	}
+	last := decoded.Messages[2]
+	if last.Role != "user" || len(last.Content) != 2 {
+		t.Fatalf("tool results not merged: %+v", last)
+	}
+	if last.Content[1].ToolResult.Status != "error" || *last.Content[1].ToolResult.Content[0].Text == "" {
+		t.Errorf("error tool result = %+v", last.Content[1].ToolResult)
+	}
+	if decoded.Additional["thinking"] == nil {
+		t.Error("thinking not forwarded for Claude model")
+	}
+	if _, ok := decoded.InferenceConfig["temperature"]; ok {
+		t.Error("temperature must be dropped when thinking is enabled")
+	}
+
+	// Non-Claude models get neither cache points nor thinking.
+	body = p.buildRequestBody("meta.llama3-3-70b-instruct-v1:0", ChatRequest{
+		Messages: []Message{{Role: "system", Content: "s"}, {Role: "user", Content: "u"}},
+		Options:  map[string]any{OptThinkingLevel: "high"},
+	})
+	if _, ok := body["additionalModelRequestFields"]; ok {
+		t.Error("thinking must not be sent to Llama")
+	}
+	if sys := body["system"].([]bedrockContentBlock); len(sys) != 1 {
+		t.Errorf("system for llama = %+v", sys)
+	}
+}
+
+func TestBedrockAuthOptions(t *testing.T) {
+	opts, err := BedrockAuthOptions(context.Background(), "us-east-1", "AKIDX:secretY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewBedrockProvider("us-east-1", opts...)
+	if p.creds == nil || p.bearerToken != "" {
+		t.Errorf("static keys should configure SigV4 credentials")
+	}
+
+	opts, _ = BedrockAuthOptions(context.Background(), "us-east-1", "ABSKbase64token")
+	p = NewBedrockProvider("us-east-1", opts...)
+	if p.bearerToken != "ABSKbase64token" || p.creds != nil {
+		t.Errorf("API key should configure bearer auth")
+	}
+}
+
+func TestBedrockRegionFromBaseURL(t *testing.T) {
+	cases := map[string]string{
+		"https://bedrock-runtime.eu-central-1.amazonaws.com":                  "eu-central-1",
+		"https://vpce-0abc.bedrock-runtime.ap-southeast-1.vpce.amazonaws.com": "ap-southeast-1",
+		"https://proxy.example.com":                                           "",
+	}
+	for in, want := range cases {
+		if got := BedrockRegionFromBaseURL(in); got != want {
+			t.Errorf("BedrockRegionFromBaseURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -11,6 +11,8 @@ type ProviderCapabilities struct {
 	Thinking         bool   // supports extended thinking / reasoning
 	Vision           bool   // supports image inputs
 	CacheControl     bool   // supports cache_control blocks (Anthropic)
+	StructuredOutput bool   // translates ChatRequest.ResponseFormat natively
+	Batch            bool   // implements BatchCapable against a live batch endpoint
 	MaxContextWindow int    // default context window for default model
 	TokenizerID      string // for tokencount package mapping
 }
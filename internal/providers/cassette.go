@@ -0,0 +1,303 @@
+package providers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cassettes capture provider traffic so agent runs can be replayed without
+// network access. A cassette is a directory per provider:
+//
+//	<root>/<provider>/provider.json   — capabilities of the recorded provider
+//	<root>/<provider>/<key>.json      — one file per normalized request
+//
+// Each request file holds every interaction recorded for that request, in
+// order; replay serves them in the same order and repeats the last one.
+
+// Cassette matching modes for the replay provider.
+const (
+	CassetteModeStrict = "strict" // exact normalized request (model, all messages, tools, options)
+	CassetteModeFuzzy  = "fuzzy"  // strict first, then conversation shape with volatile text masked
+)
+
+// CassetteDirEnv is the root directory for recorded cassettes. Replay
+// providers resolve their directory under it; recording writes below it.
+const CassetteDirEnv = "GOCLAW_CASSETTE_DIR"
+
+// CassetteRecordEnv enables recording of all registered providers when "true".
+const CassetteRecordEnv = "GOCLAW_CASSETTE_RECORD"
+
+const (
+	cassetteVersion  = 1
+	cassetteMetaFile = "provider.json"
+)
+
+// ErrCassetteMiss is returned by the replay provider when no recorded
+// interaction matches a request.
+var ErrCassetteMiss = errors.New("cassette: no recorded interaction")
+
+// generationOptions are the ChatRequest.Options that change model output.
+// Routing and identity options (session, tenant, cache keys) are excluded so
+// the same conversation hashes the same across sessions.
+var generationOptions = []string{
+	OptMaxTokens, OptTemperature, OptThinkingLevel, OptReasoningEffort,
+	OptEnableThinking, OptThinkingBudget,
+}
+
+type cassetteToolCall struct {
+	ID        string         `json:"id,omitempty"`
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+type cassetteMessage struct {
+	Role       string             `json:"role"`
+	Content    string             `json:"content,omitempty"`
+	ToolCalls  []cassetteToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string             `json:"tool_call_id,omitempty"`
+	IsError    bool               `json:"is_error,omitempty"`
+	Images     []string           `json:"images,omitempty"` // sha256 of each image payload
+}
+
+type cassetteTool struct {
+	Name       string         `json:"name"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+// cassetteRequest is the normalized form of a ChatRequest. It is stored next
+// to the interactions so a miss can be diffed against what was recorded.
+type cassetteRequest struct {
+	Model          string            `json:"model"`
+	Messages       []cassetteMessage `json:"messages"`
+	Tools          []cassetteTool    `json:"tools,omitempty"`
+	Options        map[string]any    `json:"options,omitempty"`
+	ResponseFormat *ResponseFormat   `json:"response_format,omitempty"`
+}
+
+// cassetteResponse adds the fields ChatResponse keeps out of JSON but that
+// replay needs (Anthropic thinking passback in tool loops).
+type cassetteResponse struct {
+	ChatResponse
+	RawAssistantContent json.RawMessage `json:"raw_assistant_content,omitempty"`
+	ThinkingSignature   string          `json:"thinking_signature,omitempty"`
+}
+
+type cassetteError struct {
+	Message string `json:"message"`
+	Status  int    `json:"status,omitempty"` // set for *HTTPError
+	Body    string `json:"body,omitempty"`
+}
+
+type cassetteInteraction struct {
+	Stream     bool              `json:"stream,omitempty"`
+	Chunks     []StreamChunk     `json:"chunks,omitempty"`
+	Response   *cassetteResponse `json:"response,omitempty"`
+	Error      *cassetteError    `json:"error,omitempty"`
+	RecordedAt time.Time         `json:"recorded_at"`
+}
+
+type cassetteEntry struct {
+	Version      int                   `json:"version"`
+	Key          string                `json:"key"`
+	FuzzyKey     string                `json:"fuzzy_key"`
+	Request      cassetteRequest       `json:"request"`
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// cassetteMeta records what the replay provider must report about itself so
+// the pipeline takes the same code paths it took while recording.
+type cassetteMeta struct {
+	Name               string               `json:"name"`
+	DefaultModel       string               `json:"default_model"`
+	Capabilities       ProviderCapabilities `json:"capabilities"`
+	SupportsThinking   bool                 `json:"supports_thinking,omitempty"`
+	PromptContribution *PromptContribution  `json:"prompt_contribution,omitempty"`
+}
+
+// normalizeCassetteRequest strips runtime-only and identity fields from req.
+func normalizeCassetteRequest(req ChatRequest) cassetteRequest {
+	n := cassetteRequest{Model: req.Model, ResponseFormat: req.ResponseFormat}
+	for _, m := range req.Messages {
+		cm := cassetteMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			ToolCallID: m.ToolCallID,
+			IsError:    m.IsError,
+		}
+		for _, tc := range m.ToolCalls {
+			cm.ToolCalls = append(cm.ToolCalls, cassetteToolCall{ID: tc.ID, Name: tc.Name, Arguments: tc.Arguments})
+		}
+		for _, img := range m.Images {
+			sum := sha256.Sum256([]byte(img.Data))
+			cm.Images = append(cm.Images, hex.EncodeToString(sum[:8]))
+		}
+		n.Messages = append(n.Messages, cm)
+	}
+	for _, t := range req.Tools {
+		n.Tools = append(n.Tools, cassetteTool{Name: t.Function.Name, Parameters: t.Function.Parameters})
+	}
+	sort.Slice(n.Tools, func(i, j int) bool { return n.Tools[i].Name < n.Tools[j].Name })
+	for _, k := range generationOptions {
+		if v, ok := req.Options[k]; ok {
+			if n.Options == nil {
+				n.Options = make(map[string]any)
+			}
+			n.Options[k] = v
+		}
+	}
+	return n
+}
+
+var (
+	volatileUUID   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	volatileDigits = regexp.MustCompile(`[0-9]+`)
+)
+
+// maskVolatile hides the parts of a message that differ between otherwise
+// identical runs: ids, timestamps, counters and whitespace.
+func maskVolatile(s string) string {
+	s = volatileUUID.ReplaceAllString(s, "<id>")
+	s = volatileDigits.ReplaceAllString(s, "#")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// keys returns the strict and fuzzy match keys for a normalized request.
+// The fuzzy key ignores the model, the system prompt (dates, workspace paths,
+// context files), tool schemas and tool call ids.
+func (n cassetteRequest) keys() (strict, fuzzy string) {
+	strict = hashJSON(n)
+
+	type shape struct {
+		Role    string   `json:"r"`
+		Content string   `json:"c,omitempty"`
+		Tools   []string `json:"t,omitempty"`
+		IsError bool     `json:"e,omitempty"`
+	}
+	var shapes []shape
+	for _, m := range n.Messages {
+		if m.Role == "system" {
+			continue
+		}
+		s := shape{Role: m.Role, Content: maskVolatile(m.Content), IsError: m.IsError}
+		for _, tc := range m.ToolCalls {
+			s.Tools = append(s.Tools, tc.Name)
+		}
+		shapes = append(shapes, s)
+	}
+	return strict, hashJSON(shapes)
+}
+
+func hashJSON(v any) string {
+	data, _ := json.Marshal(v) // map keys are sorted by encoding/json
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+func newCassetteResponse(resp *ChatResponse) *cassetteResponse {
+	if resp == nil {
+		return nil
+	}
+	return &cassetteResponse{
+		ChatResponse:        *resp,
+		RawAssistantContent: resp.RawAssistantContent,
+		ThinkingSignature:   resp.ThinkingSignature,
+	}
+}
+
+func (r *cassetteResponse) chatResponse() *ChatResponse {
+	resp := r.ChatResponse
+	if len(r.RawAssistantContent) > 0 {
+		// Cassette files are indented; hand the provider compact JSON back.
+		var buf bytes.Buffer
+		if json.Compact(&buf, r.RawAssistantContent) == nil {
+			resp.RawAssistantContent = buf.Bytes()
+		} else {
+			resp.RawAssistantContent = r.RawAssistantContent
+		}
+	}
+	resp.ThinkingSignature = r.ThinkingSignature
+	if r.Usage != nil {
+		u := *r.Usage
+		resp.Usage = &u
+	}
+	return &resp
+}
+
+func newCassetteError(err error) *cassetteError {
+	if err == nil {
+		return nil
+	}
+	ce := &cassetteError{Message: err.Error()}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		ce.Status, ce.Body = httpErr.Status, httpErr.Body
+	}
+	return ce
+}
+
+func (e *cassetteError) err() error {
+	if e.Status > 0 {
+		return &HTTPError{Status: e.Status, Body: e.Body}
+	}
+	return errors.New(e.Message)
+}
+
+// ResolveCassetteDir returns the cassette directory for sub under root.
+// sub must stay inside root; absolute paths and ".." escapes are rejected.
+func ResolveCassetteDir(root, sub string) (string, error) {
+	if root == "" {
+		return "", fmt.Errorf("cassette: %s is not set", CassetteDirEnv)
+	}
+	if filepath.IsAbs(sub) {
+		return "", fmt.Errorf("cassette: directory must be relative to %s", CassetteDirEnv)
+	}
+	dir := filepath.Join(root, sub)
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("cassette: directory %q escapes %s", sub, CassetteDirEnv)
+	}
+	return dir, nil
+}
+
+// cassetteDirName maps a provider name to a safe directory name.
+func cassetteDirName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// writeJSONFile writes v atomically (temp file + rename).
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cassette-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,158 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// liveStubProvider answers from a fixed list and counts calls.
+type liveStubProvider struct {
+	answers []string
+	calls   int
+}
+
+func (p *liveStubProvider) Name() string           { return "livestub" }
+func (p *liveStubProvider) DefaultModel() string   { return "m1" }
+func (p *liveStubProvider) SupportsThinking() bool { return true }
+func (p *liveStubProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{Streaming: true, ToolCalling: true, Batch: true}
+}
+
+func (p *liveStubProvider) Chat(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	if p.calls >= len(p.answers) {
+		return nil, &HTTPError{Status: 429, Body: "rate limited"}
+	}
+	a := p.answers[p.calls]
+	p.calls++
+	return &ChatResponse{Content: a, FinishReason: "stop", Usage: &Usage{TotalTokens: 7}, RawAssistantContent: []byte(`[{"type":"text"}]`)}, nil
+}
+
+func (p *liveStubProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	resp, err := p.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range strings.SplitAfter(resp.Content, " ") {
+		onChunk(StreamChunk{Content: part})
+	}
+	onChunk(StreamChunk{Done: true})
+	return resp, nil
+}
+
+func cassetteReq(system, user string) ChatRequest {
+	return ChatRequest{
+		Model: "m1",
+		Messages: []Message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		Options: map[string]any{OptMaxTokens: 100, OptSessionKey: "agent:a:s1"},
+	}
+}
+
+func TestCassette_RecordReplayStrict(t *testing.T) {
+	root := t.TempDir()
+	live := &liveStubProvider{answers: []string{"hello there friend", "second"}}
+	rec := RecordingMiddleware(root)(live)
+	ctx := context.Background()
+
+	var liveChunks []string
+	resp, err := rec.ChatStream(ctx, cassetteReq("sys", "hi"), func(c StreamChunk) { liveChunks = append(liveChunks, c.Content) })
+	if err != nil || resp.Content != "hello there friend" {
+		t.Fatalf("live stream: %v %v", resp, err)
+	}
+	if _, err := rec.Chat(ctx, cassetteReq("sys", "again")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.Chat(ctx, cassetteReq("sys", "fails")); err == nil {
+		t.Fatal("expected live error")
+	}
+
+	replay, err := NewReplayProvider("", filepath.Join(root, "livestub"), CassetteModeStrict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Name() != "livestub" || replay.DefaultModel() != "m1" || !replay.SupportsThinking() {
+		t.Errorf("meta not restored: %s %s %v", replay.Name(), replay.DefaultModel(), replay.SupportsThinking())
+	}
+	if caps := replay.Capabilities(); !caps.Streaming || caps.Batch {
+		t.Errorf("capabilities = %+v, want streaming without batch", caps)
+	}
+
+	// Session key differs from the recording: not a generation option, still matches.
+	req := cassetteReq("sys", "hi")
+	req.Options[OptSessionKey] = "agent:a:other"
+	var chunks []string
+	resp, err = replay.ChatStream(ctx, req, func(c StreamChunk) { chunks = append(chunks, c.Content) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(chunks, "|") != strings.Join(liveChunks, "|") {
+		t.Errorf("chunks = %q, want %q", chunks, liveChunks)
+	}
+	if string(resp.RawAssistantContent) != `[{"type":"text"}]` || resp.Usage.TotalTokens != 7 {
+		t.Errorf("response fields not restored: %+v", resp)
+	}
+
+	// Recorded via Chat, replayed via ChatStream: synthesized stream.
+	chunks = nil
+	if _, err := replay.ChatStream(ctx, cassetteReq("sys", "again"), func(c StreamChunk) { chunks = append(chunks, c.Content) }); err != nil {
+		t.Fatal(err)
+	}
+	if chunks[0] != "second" {
+		t.Errorf("synthesized chunks = %q", chunks)
+	}
+
+	var httpErr *HTTPError
+	if _, err := replay.Chat(ctx, cassetteReq("sys", "fails")); !errors.As(err, &httpErr) || httpErr.Status != 429 {
+		t.Errorf("recorded error = %v, want HTTP 429", err)
+	}
+
+	// Changed system prompt misses in strict mode.
+	if _, err := replay.Chat(ctx, cassetteReq("sys at 2026-10-16", "hi")); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("strict mismatch err = %v, want ErrCassetteMiss", err)
+	}
+}
+
+func TestCassette_ReplayFuzzy(t *testing.T) {
+	root := t.TempDir()
+	rec := RecordingMiddleware(root)(&liveStubProvider{answers: []string{"done"}})
+	if _, err := rec.Chat(context.Background(), cassetteReq("today is 2026-10-16", "run job 42")); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := NewReplayProvider("anthropic", filepath.Join(root, "livestub"), CassetteModeFuzzy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Name() != "anthropic" {
+		t.Errorf("name override = %s", replay.Name())
+	}
+	req := cassetteReq("today is 2026-10-17", "run job 43")
+	req.Model = "other-model"
+	resp, err := replay.Chat(context.Background(), req)
+	if err != nil || resp.Content != "done" {
+		t.Fatalf("fuzzy replay = %v, %v", resp, err)
+	}
+	if _, err := replay.Chat(context.Background(), cassetteReq("x", "different question")); !errors.Is(err, ErrCassetteMiss) {
+		t.Errorf("fuzzy unrelated err = %v, want ErrCassetteMiss", err)
+	}
+}
+
+func TestResolveCassetteDir(t *testing.T) {
+	root := t.TempDir()
+	if dir, err := ResolveCassetteDir(root, "anthropic"); err != nil || dir != filepath.Join(root, "anthropic") {
+		t.Errorf("ResolveCassetteDir = %q, %v", dir, err)
+	}
+	for _, bad := range []string{"../etc", "/etc", "a/../../b"} {
+		if _, err := ResolveCassetteDir(root, bad); err == nil {
+			t.Errorf("ResolveCassetteDir(%q) should fail", bad)
+		}
+	}
+	if _, err := ResolveCassetteDir("", "x"); err == nil {
+		t.Error("empty root should fail")
+	}
+}
@@ -149,6 +149,7 @@ func (p *ClaudeCLIProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           false,
 		CacheControl:     false,
+		StructuredOutput: false,
 		MaxContextWindow: 200_000,
 		TokenizerID:      "cl100k_base",
 	}
@@ -68,6 +68,7 @@ func (p *CodexProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           true,
 		CacheControl:     false,
+		StructuredOutput: true,
 		MaxContextWindow: 1_000_000,
 		TokenizerID:      "o200k_base",
 	}
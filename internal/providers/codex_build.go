@@ -125,6 +125,22 @@ func (p *CodexProvider) buildRequestBody(req ChatRequest, stream bool) map[strin
 		body["reasoning"] = map[string]any{"effort": level}
 	}
 
+	// Responses API carries structured output under text.format (flattened json_schema).
+	if f := req.ResponseFormat; f.wantsJSON() {
+		format := map[string]any{"type": ResponseFormatJSONObject}
+		if f.hasSchema() {
+			profile := profileForProvider("codex")
+			profile.StrictToolMode = f.Strict
+			format = map[string]any{
+				"type":   ResponseFormatJSONSchema,
+				"name":   f.schemaName(),
+				"schema": normalizeWithProfile(profile, f.Schema),
+				"strict": f.Strict,
+			}
+		}
+		body["text"] = map[string]any{"format": format}
+	}
+
 	return body
 }
 
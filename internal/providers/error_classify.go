@@ -18,6 +18,7 @@ const (
 	FailoverBilling       FailoverReason = "billing"
 	FailoverTimeout       FailoverReason = "timeout"
 	FailoverModelNotFound FailoverReason = "model_not_found"
+	FailoverContentFilter FailoverReason = "content_filter"
 	FailoverUnknown       FailoverReason = "unknown"
 )
 
@@ -60,6 +61,7 @@ func NewDefaultClassifier() *DefaultClassifier {
 	}
 	RegisterOpenAIPatterns(c)
 	RegisterAnthropicPatterns(c)
+	RegisterBedrockPatterns(c)
 	return c
 }
 
@@ -102,6 +104,12 @@ func (c *DefaultClassifier) Classify(err error, statusCode int, body string) Fai
 		}
 	}
 
+	// Content policy rejections (Azure content_filter) are prompt-specific:
+	// another model may accept the same input, so check before generic 400s.
+	if statusCode == 400 && isContentFiltered(lower) {
+		return classifyReason(FailoverContentFilter)
+	}
+
 	// Body pattern matching for specific error types
 	if containsAny(lower, "credit balance", "insufficient_quota", "billing") && statusCode != 0 {
 		return classifyReason(FailoverBilling)
@@ -152,12 +160,22 @@ func isContextOverflow(lower string) bool {
 		"token limit",
 		"too many tokens",
 		"prompt is too long",
+		"input is too long",
 		// Chinese patterns (Qwen/DashScope)
 		"超出最大长度限制",
 		"上下文长度",
 	)
 }
 
+// isContentFiltered detects provider-side content policy rejections.
+func isContentFiltered(lower string) bool {
+	return containsAny(lower,
+		"content_filter",
+		"responsibleaipolicyviolation",
+		"content management policy",
+	)
+}
+
 // isNetworkError checks if an error is a network-level failure.
 func isNetworkError(err error) bool {
 	if err == nil {
@@ -197,3 +215,17 @@ func RegisterAnthropicPatterns(c *DefaultClassifier) {
 		{Contains: "credit balance", Reason: FailoverBilling},
 	})
 }
+
+// RegisterBedrockPatterns adds AWS Bedrock error patterns. Bedrock reports the
+// exception name (X-Amzn-ErrorType) ahead of the message body.
+func RegisterBedrockPatterns(c *DefaultClassifier) {
+	c.RegisterPatterns("bedrock", []ErrorPattern{
+		{Contains: "ThrottlingException", Reason: FailoverRateLimit},
+		{Contains: "ServiceQuotaExceededException", Reason: FailoverRateLimit},
+		{Contains: "ModelNotReadyException", Reason: FailoverOverloaded},
+		{Contains: "ServiceUnavailableException", Reason: FailoverOverloaded},
+		{Contains: "model identifier is invalid", Reason: FailoverModelNotFound},
+		{Contains: "don't have access to the model", Reason: FailoverModelNotFound},
+		{Contains: "UnrecognizedClientException", Reason: FailoverAuthPermanent},
+	})
+}
@@ -10,7 +10,8 @@ import (
 type ModelCandidate struct {
 	Provider  string
 	Model     string
-	ProfileID string // opaque identifier (never raw API key)
+	ProfileID string  // opaque identifier (never raw API key)
+	Weight    float64 // base share for the Balancer (0 = 1); ignored by RunWithFailover
 }
 
 // FailoverConfig controls the failover behavior.
@@ -55,7 +56,7 @@ func isProfileRotatable(reason FailoverReason) bool {
 // isModelFallbackRequired returns true for permanent errors requiring a different model.
 func isModelFallbackRequired(reason FailoverReason) bool {
 	switch reason {
-	case FailoverAuthPermanent, FailoverBilling, FailoverFormat, FailoverModelNotFound:
+	case FailoverAuthPermanent, FailoverBilling, FailoverFormat, FailoverModelNotFound, FailoverContentFilter:
 		return true
 	}
 	return false
@@ -125,8 +126,9 @@ func RunWithFailover[T any](
 			Err:            err,
 		})
 
-		// Record failure in cooldown tracker
-		if cfg.Tracker != nil && classification.Kind == "reason" {
+		// Record failure in cooldown tracker. Content filter hits are tied to the
+		// prompt, not the model's health, so they don't put the model in cooldown.
+		if cfg.Tracker != nil && classification.Kind == "reason" && classification.Reason != FailoverContentFilter {
 			cfg.Tracker.RecordFailure(
 				CooldownKey(candidate.Provider, candidate.Model),
 				classification.Reason,
@@ -42,3 +42,19 @@ func ApplyMiddlewares(body map[string]any, mw RequestMiddleware, cfg MiddlewareC
 	}
 	return mw(body, cfg)
 }
+
+// ProviderMiddleware wraps a whole Provider. Unlike RequestMiddleware, which
+// rewrites the wire body inside one provider, it sees the ChatRequest and the
+// response of every provider type (e.g. RecordingMiddleware).
+type ProviderMiddleware func(Provider) Provider
+
+// WrapProvider applies provider middlewares left-to-right (the last one is
+// outermost). Nil entries skipped.
+func WrapProvider(p Provider, middlewares ...ProviderMiddleware) Provider {
+	for _, mw := range middlewares {
+		if mw != nil {
+			p = mw(p)
+		}
+	}
+	return p
+}
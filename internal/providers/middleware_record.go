@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RecordingMiddleware returns a ProviderMiddleware that records every call of
+// the wrapped provider into root/<provider name>/ (see cassette.go).
+// Replay providers are left unwrapped.
+func RecordingMiddleware(root string) ProviderMiddleware {
+	return func(p Provider) Provider {
+		if _, ok := p.(*ReplayProvider); ok {
+			return p
+		}
+		return NewRecordingProvider(p, filepath.Join(root, cassetteDirName(p.Name())))
+	}
+}
+
+// RecordingProvider forwards calls to the wrapped provider and writes each
+// request with its response, stream chunks or error to a cassette directory.
+// A request file is overwritten on its first call in this process and
+// appended to afterwards, so re-recording a scenario replaces stale answers.
+//
+// Callers that type-assert the concrete provider (Codex pool routing) see the
+// wrapper instead; record those flows through their base provider.
+type RecordingProvider struct {
+	inner Provider
+	dir   string
+
+	mu       sync.Mutex
+	entries  map[string]*cassetteEntry
+	metaDone bool
+}
+
+// NewRecordingProvider wraps inner, recording into dir.
+func NewRecordingProvider(inner Provider, dir string) *RecordingProvider {
+	return &RecordingProvider{inner: inner, dir: dir, entries: make(map[string]*cassetteEntry)}
+}
+
+func (p *RecordingProvider) Name() string         { return p.inner.Name() }
+func (p *RecordingProvider) DefaultModel() string { return p.inner.DefaultModel() }
+
+// Unwrap returns the recorded provider.
+func (p *RecordingProvider) Unwrap() Provider { return p.inner }
+
+// SupportsThinking forwards ThinkingCapable.
+func (p *RecordingProvider) SupportsThinking() bool {
+	tc, ok := p.inner.(ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+// Capabilities forwards CapabilitiesAware. Batch is cleared: the recorder
+// does not implement BatchCapable, so batch work takes the recorded sync path.
+func (p *RecordingProvider) Capabilities() ProviderCapabilities {
+	var caps ProviderCapabilities
+	if ca, ok := p.inner.(CapabilitiesAware); ok {
+		caps = ca.Capabilities()
+	}
+	caps.Batch = false
+	return caps
+}
+
+// PromptContribution forwards PromptContributor.
+func (p *RecordingProvider) PromptContribution() *PromptContribution {
+	if pc, ok := p.inner.(PromptContributor); ok {
+		return pc.PromptContribution()
+	}
+	return nil
+}
+
+// Close forwards io.Closer.
+func (p *RecordingProvider) Close() error {
+	if c, ok := p.inner.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (p *RecordingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := p.inner.Chat(ctx, req)
+	p.record(req, cassetteInteraction{Response: newCassetteResponse(resp), Error: newCassetteError(err)})
+	return resp, err
+}
+
+func (p *RecordingProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	var chunks []StreamChunk
+	var chunkMu sync.Mutex
+	resp, err := p.inner.ChatStream(ctx, req, func(c StreamChunk) {
+		chunkMu.Lock()
+		chunks = append(chunks, c)
+		chunkMu.Unlock()
+		if onChunk != nil {
+			onChunk(c)
+		}
+	})
+	chunkMu.Lock()
+	recorded := chunks
+	chunkMu.Unlock()
+	p.record(req, cassetteInteraction{Stream: true, Chunks: recorded, Response: newCassetteResponse(resp), Error: newCassetteError(err)})
+	return resp, err
+}
+
+// record appends one interaction. Recording failures are logged and never
+// affect the live call.
+func (p *RecordingProvider) record(req ChatRequest, in cassetteInteraction) {
+	in.RecordedAt = time.Now().UTC()
+	norm := normalizeCassetteRequest(req)
+	key, fuzzy := norm.keys()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		slog.Warn("cassette: create dir failed", "dir", p.dir, "error", err)
+		return
+	}
+	if !p.metaDone {
+		if err := writeJSONFile(filepath.Join(p.dir, cassetteMetaFile), p.meta()); err != nil {
+			slog.Warn("cassette: write provider meta failed", "dir", p.dir, "error", err)
+		}
+		p.metaDone = true
+	}
+	entry, ok := p.entries[key]
+	if !ok {
+		entry = &cassetteEntry{Version: cassetteVersion, Key: key, FuzzyKey: fuzzy, Request: norm}
+		p.entries[key] = entry
+	}
+	entry.Interactions = append(entry.Interactions, in)
+	if err := writeJSONFile(filepath.Join(p.dir, key+".json"), entry); err != nil {
+		slog.Warn("cassette: write interaction failed", "provider", p.Name(), "key", key, "error", err)
+	}
+}
+
+func (p *RecordingProvider) meta() cassetteMeta {
+	return cassetteMeta{
+		Name:               p.inner.Name(),
+		DefaultModel:       p.inner.DefaultModel(),
+		Capabilities:       p.Capabilities(),
+		SupportsThinking:   p.SupportsThinking(),
+		PromptContribution: p.PromptContribution(),
+	}
+}
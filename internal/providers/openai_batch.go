@@ -0,0 +1,220 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// OpenAI Batch API: upload a JSONL input file (purpose=batch), create the batch
+// against /v1/chat/completions, poll GET /batches/{id}, then download the
+// output (and error) files once the batch reaches a terminal state.
+
+const openAIBatchEndpoint = "/v1/chat/completions"
+
+type openAIBatchObject struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"` // validating, in_progress, finalizing, completed, failed, expired, cancelling, cancelled
+	OutputFileID string `json:"output_file_id"`
+	ErrorFileID  string `json:"error_file_id"`
+	Errors       *struct {
+		Data []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"errors,omitempty"`
+}
+
+type openAIBatchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// batchEndpoint reports whether this provider talks to OpenAI's own API, the
+// only OpenAI-compatible backend whose /files + /batches endpoints we target.
+func (p *OpenAIProvider) batchEndpoint() bool {
+	return isOpenAINativeEndpoint(p.apiBase) && p.endpointFn == nil && p.chatPath == "/chat/completions"
+}
+
+// SubmitBatch implements BatchCapable via the OpenAI Batch API.
+func (p *OpenAIProvider) SubmitBatch(ctx context.Context, reqs []BatchRequest) (string, error) {
+	var input bytes.Buffer
+	for _, r := range reqs {
+		req := batchWireRequest(r.Request)
+		model := p.resolveModel(req.Model)
+		body := p.buildRequestBody(model, req, false)
+		body = ApplyMiddlewares(body, p.middlewares, p.middlewareConfig(model, req))
+		delete(body, "stream")
+		line, err := json.Marshal(map[string]any{
+			"custom_id": r.CustomID,
+			"method":    http.MethodPost,
+			"url":       openAIBatchEndpoint,
+			"body":      body,
+		})
+		if err != nil {
+			return "", fmt.Errorf("%s: marshal batch line: %w", p.name, err)
+		}
+		input.Write(line)
+		input.WriteByte('\n')
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	_ = mw.WriteField("purpose", "batch")
+	fw, err := mw.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("%s: build batch upload: %w", p.name, err)
+	}
+	_, _ = fw.Write(input.Bytes())
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("%s: build batch upload: %w", p.name, err)
+	}
+
+	data, err := p.batchCall(ctx, http.MethodPost, "/files", mw.FormDataContentType(), form.Bytes())
+	if err != nil {
+		return "", err
+	}
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil || file.ID == "" {
+		return "", fmt.Errorf("%s: batch input upload returned no file id", p.name)
+	}
+
+	create, _ := json.Marshal(map[string]any{
+		"input_file_id":     file.ID,
+		"endpoint":          openAIBatchEndpoint,
+		"completion_window": "24h",
+	})
+	data, err = p.batchCall(ctx, http.MethodPost, "/batches", "application/json", create)
+	if err != nil {
+		return "", err
+	}
+	var batch openAIBatchObject
+	if err := json.Unmarshal(data, &batch); err != nil || batch.ID == "" {
+		return "", fmt.Errorf("%s: batch create returned no id", p.name)
+	}
+	return batch.ID, nil
+}
+
+// PollBatch implements BatchCapable.
+func (p *OpenAIProvider) PollBatch(ctx context.Context, batchID string) (*BatchPoll, error) {
+	data, err := p.batchCall(ctx, http.MethodGet, "/batches/"+batchID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var batch openAIBatchObject
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, fmt.Errorf("%s: decode batch: %w", p.name, err)
+	}
+
+	switch batch.Status {
+	case "completed", "expired", "cancelled":
+		// Terminal with (possibly partial) output; requests without an output
+		// line are reported as failed below.
+	case "failed":
+		msg := "batch failed"
+		if batch.Errors != nil && len(batch.Errors.Data) > 0 {
+			msg = batch.Errors.Data[0].Code + ": " + batch.Errors.Data[0].Message
+		}
+		return &BatchPoll{Status: BatchStatusFailed, Error: msg}, nil
+	default:
+		return &BatchPoll{Status: BatchStatusInProgress}, nil
+	}
+	if batch.OutputFileID == "" && batch.ErrorFileID == "" {
+		return &BatchPoll{Status: BatchStatusFailed, Error: "batch " + batch.Status + " without output"}, nil
+	}
+
+	poll := &BatchPoll{Status: BatchStatusEnded}
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		content, err := p.batchCall(ctx, http.MethodGet, "/files/"+fileID+"/content", "", nil)
+		if err != nil {
+			return nil, err
+		}
+		err = parseJSONLines(content, func(line []byte) error {
+			var ol openAIBatchOutputLine
+			if err := json.Unmarshal(line, &ol); err != nil {
+				return err
+			}
+			poll.Results = append(poll.Results, p.batchResult(ol))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: parse batch output: %w", p.name, err)
+		}
+	}
+	return poll, nil
+}
+
+// batchResult converts one output-file line into a BatchResult.
+func (p *OpenAIProvider) batchResult(ol openAIBatchOutputLine) BatchResult {
+	res := BatchResult{CustomID: ol.CustomID}
+	switch {
+	case ol.Error != nil:
+		res.Error = ol.Error.Code + ": " + ol.Error.Message
+	case ol.Response == nil:
+		res.Error = "missing response"
+	case ol.Response.StatusCode != http.StatusOK:
+		res.Error = fmt.Sprintf("HTTP %d: %s", ol.Response.StatusCode, ol.Response.Body)
+	default:
+		var oaiResp openAIResponse
+		if err := json.Unmarshal(ol.Response.Body, &oaiResp); err != nil {
+			res.Error = "decode response: " + err.Error()
+			break
+		}
+		res.Response = p.parseResponse(&oaiResp)
+	}
+	return res
+}
+
+// batchCall performs a request against the Files/Batches API and returns the body.
+func (p *OpenAIProvider) batchCall(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	return RetryDo(ctx, p.retryConfig, func() ([]byte, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, method, p.apiBase+path, reader)
+		if err != nil {
+			return nil, fmt.Errorf("%s: create batch request: %w", p.name, err)
+		}
+		if contentType != "" {
+			httpReq.Header.Set("Content-Type", contentType)
+		}
+		if err := p.setAuthHeader(httpReq.Header); err != nil {
+			return nil, err
+		}
+
+		resp, err := p.client.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("%s: batch request failed: %w", p.name, err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: read batch response: %w", p.name, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, &HTTPError{
+				Status:     resp.StatusCode,
+				Body:       fmt.Sprintf("%s: %s", p.name, string(data)),
+				RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+			}
+		}
+		return data, nil
+	})
+}
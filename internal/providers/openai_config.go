@@ -19,8 +19,10 @@ type OpenAIProvider struct {
 	siteTitle    string // optional site title for provider identification (e.g. OpenRouter X-Title)
 	client       *http.Client
 	retryConfig  RetryConfig
-	middlewares  RequestMiddleware // composed middleware chain (nil = no-op)
-	registry     ModelRegistry    // model resolution registry (nil = skip)
+	middlewares  RequestMiddleware         // composed middleware chain (nil = no-op)
+	registry     ModelRegistry             // model resolution registry (nil = skip)
+	tokenSource  TokenSource               // optional bearer token source; takes precedence over apiKey (e.g. Entra ID)
+	endpointFn   func(model string) string // optional per-model chat URL (e.g. Azure deployment routing)
 }
 
 func NewOpenAIProvider(name, apiKey, apiBase, defaultModel string) *OpenAIProvider {
@@ -74,6 +76,19 @@ func (p *OpenAIProvider) WithMiddlewares(mws ...RequestMiddleware) *OpenAIProvid
 	return p
 }
 
+// WithTokenSource sets a token source used for the Authorization header instead of the static API key.
+func (p *OpenAIProvider) WithTokenSource(ts TokenSource) *OpenAIProvider {
+	p.tokenSource = ts
+	return p
+}
+
+// WithEndpointResolver sets a function that builds the full chat URL for a model,
+// overriding apiBase+chatPath. Used by providers that route per deployment.
+func (p *OpenAIProvider) WithEndpointResolver(fn func(model string) string) *OpenAIProvider {
+	p.endpointFn = fn
+	return p
+}
+
 // WithProviderType sets the DB provider_type for correct API endpoint routing in media tools.
 func (p *OpenAIProvider) WithProviderType(pt string) *OpenAIProvider {
 	p.providerType = pt
@@ -97,6 +112,8 @@ func (p *OpenAIProvider) Capabilities() ProviderCapabilities {
 		Thinking:         true,
 		Vision:           true,
 		CacheControl:     false,
+		StructuredOutput: true,
+		Batch:            p.batchEndpoint(),
 		MaxContextWindow: 128_000,
 		TokenizerID:      "o200k_base",
 	}
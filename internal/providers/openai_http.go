@@ -20,21 +20,22 @@ func (p *OpenAIProvider) doRequest(ctx context.Context, body any) (io.ReadCloser
 		return nil, fmt.Errorf("%s: marshal request: %w", p.name, err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.apiBase+p.chatPath, bytes.NewReader(data))
+	url := p.apiBase + p.chatPath
+	if p.endpointFn != nil {
+		if m, ok := body.(map[string]any); ok {
+			model, _ := m["model"].(string)
+			url = p.endpointFn(model)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 	if err != nil {
 		return nil, fmt.Errorf("%s: create request: %w", p.name, err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	// Azure OpenAI/Foundry support for now atleast
-	if strings.Contains(strings.ToLower(p.apiBase), "azure.com") {
-		httpReq.Header.Set("api-key", p.apiKey)
-	} else {
-		prefix := p.authPrefix
-		if prefix == "" {
-			prefix = "Bearer "
-		}
-		httpReq.Header.Set("Authorization", prefix+p.apiKey)
+	if err := p.setAuthHeader(httpReq.Header); err != nil {
+		return nil, err
 	}
 	// OpenRouter identification headers for rankings/analytics
 	if p.siteURL != "" {
@@ -48,6 +49,7 @@ func (p *OpenAIProvider) doRequest(ctx context.Context, body any) (io.ReadCloser
 	if err != nil {
 		return nil, fmt.Errorf("%s: request failed: %w", p.name, err)
 	}
+	observeRateLimitHeaders(ctx, resp.Header)
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
@@ -63,6 +65,30 @@ func (p *OpenAIProvider) doRequest(ctx context.Context, body any) (io.ReadCloser
 	return resp.Body, nil
 }
 
+// setAuthHeader applies request authentication: token source (Bearer), Azure api-key,
+// or the static key with the configured prefix.
+func (p *OpenAIProvider) setAuthHeader(h http.Header) error {
+	if p.tokenSource != nil {
+		token, err := p.tokenSource.Token()
+		if err != nil {
+			return fmt.Errorf("%s: get auth token: %w", p.name, err)
+		}
+		h.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+	// Azure OpenAI/Foundry support for now atleast
+	if p.providerType == "azure_openai" || strings.Contains(strings.ToLower(p.apiBase), "azure.com") {
+		h.Set("api-key", p.apiKey)
+		return nil
+	}
+	prefix := p.authPrefix
+	if prefix == "" {
+		prefix = "Bearer "
+	}
+	h.Set("Authorization", prefix+p.apiKey)
+	return nil
+}
+
 func (p *OpenAIProvider) parseResponse(resp *openAIResponse) *ChatResponse {
 	result := &ChatResponse{FinishReason: "stop"}
 
@@ -151,6 +151,12 @@ func (p *OpenAIProvider) buildRequestBody(model string, req ChatRequest, stream
 		body["tool_choice"] = "auto"
 	}
 
+	// Structured output. Ollama's OpenAI-compat endpoint maps response_format
+	// onto its native "format" field, so the same shape covers local models.
+	if req.ResponseFormat.wantsJSON() {
+		body["response_format"] = openAIResponseFormat(p.schemaProviderName(), req.ResponseFormat)
+	}
+
 	// Together returns HTTP 400 on some requests when stream_options is present.
 	if stream && !p.isTogetherEndpoint() {
 		body["stream_options"] = map[string]any{
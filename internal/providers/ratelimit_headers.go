@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitState is the remaining quota a provider reported in its response
+// headers. Zero counts with Known=false mean the provider sent nothing.
+type RateLimitState struct {
+	Known             bool      `json:"known"`
+	RequestsRemaining int       `json:"requests_remaining,omitempty"`
+	RequestsLimit     int       `json:"requests_limit,omitempty"`
+	TokensRemaining   int       `json:"tokens_remaining,omitempty"`
+	TokensLimit       int       `json:"tokens_limit,omitempty"`
+	ResetAt           time.Time `json:"reset_at,omitempty"`
+}
+
+// headroom returns the smallest remaining fraction of the reported limits
+// (1 = untouched, 0 = exhausted). Returns 1 when nothing is known.
+func (s RateLimitState) headroom(now time.Time) float64 {
+	if !s.Known || (!s.ResetAt.IsZero() && now.After(s.ResetAt)) {
+		return 1
+	}
+	h := 1.0
+	if s.RequestsLimit > 0 {
+		h = min(h, float64(s.RequestsRemaining)/float64(s.RequestsLimit))
+	}
+	if s.TokensLimit > 0 {
+		h = min(h, float64(s.TokensRemaining)/float64(s.TokensLimit))
+	}
+	return max(h, 0)
+}
+
+// ParseRateLimitHeaders reads OpenAI-style (x-ratelimit-*, also sent by
+// OpenRouter, Groq, Azure) and Anthropic (anthropic-ratelimit-*) quota headers.
+func ParseRateLimitHeaders(h http.Header, now time.Time) RateLimitState {
+	var s RateLimitState
+	num := func(key string) (int, bool) {
+		v := h.Get(key)
+		if v == "" {
+			return 0, false
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	}
+	set := func(dst *int, keys ...string) {
+		for _, k := range keys {
+			if n, ok := num(k); ok {
+				*dst = n
+				s.Known = true
+				return
+			}
+		}
+	}
+	set(&s.RequestsRemaining, "x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining")
+	set(&s.RequestsLimit, "x-ratelimit-limit-requests", "anthropic-ratelimit-requests-limit")
+	set(&s.TokensRemaining, "x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining")
+	set(&s.TokensLimit, "x-ratelimit-limit-tokens", "anthropic-ratelimit-tokens-limit")
+
+	// OpenAI sends a duration ("6m0s", "1.5s"); Anthropic an RFC 3339 time.
+	if v := h.Get("x-ratelimit-reset-requests"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			s.ResetAt = now.Add(d)
+		}
+	} else if v := h.Get("anthropic-ratelimit-requests-reset"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			s.ResetAt = t
+		}
+	}
+	return s
+}
+
+type rateLimitObserverKey struct{}
+
+// withRateLimitObserver asks providers to report quota headers of the
+// response served under ctx to fn.
+func withRateLimitObserver(ctx context.Context, fn func(RateLimitState)) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, fn)
+}
+
+// observeRateLimitHeaders is called by HTTP providers after every response
+// (success or error). No-op unless a balancer is observing.
+func observeRateLimitHeaders(ctx context.Context, h http.Header) {
+	fn, _ := ctx.Value(rateLimitObserverKey{}).(func(RateLimitState))
+	if fn == nil {
+		return
+	}
+	if s := ParseRateLimitHeaders(h, time.Now()); s.Known {
+		fn(s)
+	}
+}
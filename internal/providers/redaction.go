@@ -0,0 +1,486 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Built-in PII classes for outbound redaction.
+const (
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIICard       = "card"        // payment card numbers (Luhn-checked)
+	PIINationalID = "national_id" // US SSN, UK NINO; add others as custom patterns
+)
+
+// DefaultPIIClasses is used when a policy enables redaction without listing classes.
+var DefaultPIIClasses = []string{PIIEmail, PIIPhone, PIICard, PIINationalID}
+
+// PIIPattern is a tenant- or agent-defined PII class matched by regex.
+type PIIPattern struct {
+	Class   string `json:"class"`
+	Pattern string `json:"pattern"`
+}
+
+type piiRule struct {
+	class string
+	re    *regexp.Regexp
+	valid func(string) bool // optional post-match check
+}
+
+// Built-in rules in match order: cards and national ids before phones, whose
+// looser digit pattern would otherwise claim them.
+var builtinPIIRules = []piiRule{
+	{class: PIIEmail, re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{class: PIICard, re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhnValid},
+	{class: PIINationalID, re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b|\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`)},
+	{class: PIIPhone, re: regexp.MustCompile(`\+?\(?\d[\d ().-]{6,18}\d`), valid: phoneValid},
+}
+
+var isoDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// phoneValid accepts 8–15 digits (E.164) and rejects ISO dates.
+func phoneValid(s string) bool {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n >= 8 && n <= 15 && !isoDate.MatchString(s)
+}
+
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// PIIRedactor replaces configured PII classes with reversible placeholders
+// such as "[PII_EMAIL_1]". It is immutable and shared by an agent's runs; the
+// placeholder mapping lives in a RedactionVault.
+type PIIRedactor struct {
+	rules []piiRule
+}
+
+// NewPIIRedactor compiles a redactor for classes (built-in names) plus custom
+// patterns. Returns nil when nothing is enabled.
+func NewPIIRedactor(classes []string, custom []PIIPattern) (*PIIRedactor, error) {
+	want := make(map[string]bool, len(classes))
+	for _, c := range classes {
+		want[c] = true
+	}
+	r := &PIIRedactor{}
+	for _, rule := range builtinPIIRules {
+		if want[rule.class] {
+			r.rules = append(r.rules, rule)
+			delete(want, rule.class)
+		}
+	}
+	for _, p := range custom {
+		if p.Class == "" || p.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pii pattern %q: %w", p.Class, err)
+		}
+		delete(want, p.Class)
+		r.rules = append(r.rules, piiRule{class: p.Class, re: re})
+	}
+	for c := range want {
+		return nil, fmt.Errorf("unknown pii class %q", c)
+	}
+	if len(r.rules) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// Redact replaces PII in s with vault placeholders and adds per-class counts.
+func (r *PIIRedactor) Redact(v *RedactionVault, s string, counts map[string]int) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllStringFunc(s, func(m string) string {
+			if rule.valid != nil && !rule.valid(m) {
+				return m
+			}
+			counts[rule.class]++
+			return v.placeholder(rule.class, m)
+		})
+	}
+	return s
+}
+
+// redactArgs returns a copy of tool-call arguments with string values redacted.
+func (r *PIIRedactor) redactArgs(v *RedactionVault, args map[string]any, counts map[string]int) map[string]any {
+	if len(args) == 0 {
+		return args
+	}
+	out, _ := mapStrings(args, func(s string) string { return r.Redact(v, s, counts) }).(map[string]any)
+	return out
+}
+
+// RedactionVault holds the placeholder ↔ original mapping for one run, so the
+// same value gets the same placeholder across every LLM call of the run and
+// responses can be restored. Only batched background requests persist a
+// vault (see MarshalJSON), next to the request it restores.
+type RedactionVault struct {
+	mu      sync.Mutex
+	byValue map[string]string
+	byToken map[string]string
+	next    map[string]int
+}
+
+// NewRedactionVault creates an empty vault.
+func NewRedactionVault() *RedactionVault {
+	return &RedactionVault{
+		byValue: make(map[string]string),
+		byToken: make(map[string]string),
+		next:    make(map[string]int),
+	}
+}
+
+var piiPlaceholderRe = regexp.MustCompile(`\[PII_[A-Z0-9_]+_\d+\]`)
+
+const piiPlaceholderPrefix = "[PII_"
+
+func (v *RedactionVault) placeholder(class, value string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := class + "\x00" + value
+	if tok, ok := v.byValue[key]; ok {
+		return tok
+	}
+	v.next[class]++
+	tok := fmt.Sprintf("%s%s_%d]", piiPlaceholderPrefix, placeholderClass(class), v.next[class])
+	v.byValue[key] = tok
+	v.byToken[tok] = value
+	return tok
+}
+
+func placeholderClass(class string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return '_'
+	}, class)
+}
+
+// Restore replaces known placeholders in s with their original values.
+// Unknown placeholders (e.g. invented by the model) are left as-is.
+func (v *RedactionVault) Restore(s string) string {
+	if v == nil || !strings.Contains(s, piiPlaceholderPrefix) {
+		return s
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return piiPlaceholderRe.ReplaceAllStringFunc(s, func(tok string) string {
+		if orig, ok := v.byToken[tok]; ok {
+			return orig
+		}
+		return tok
+	})
+}
+
+// RestoreResponse restores placeholders in a response's content, thinking and
+// tool-call arguments.
+func (v *RedactionVault) RestoreResponse(resp *ChatResponse) {
+	if resp == nil {
+		return
+	}
+	resp.Content = v.Restore(resp.Content)
+	resp.Thinking = v.Restore(resp.Thinking)
+	for i := range resp.ToolCalls {
+		resp.ToolCalls[i].Arguments = v.restoreArgs(resp.ToolCalls[i].Arguments)
+	}
+}
+
+// MarshalJSON encodes the placeholder → original mapping.
+func (v *RedactionVault) MarshalJSON() ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return json.Marshal(v.byToken)
+}
+
+// UnmarshalJSON decodes a mapping written by MarshalJSON. The decoded vault
+// restores responses; it is not meant to redact further requests.
+func (v *RedactionVault) UnmarshalJSON(data []byte) error {
+	byToken := make(map[string]string)
+	if err := json.Unmarshal(data, &byToken); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.byToken = byToken
+	v.byValue = make(map[string]string)
+	v.next = make(map[string]int)
+	return nil
+}
+
+func (v *RedactionVault) restoreArgs(args map[string]any) map[string]any {
+	if len(args) == 0 {
+		return args
+	}
+	out, _ := mapStrings(args, v.Restore).(map[string]any)
+	return out
+}
+
+// mapStrings returns a deep copy of a JSON-like value with fn applied to
+// every string.
+func mapStrings(val any, fn func(string) string) any {
+	switch t := val.(type) {
+	case string:
+		return fn(t)
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = mapStrings(e, fn)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = mapStrings(e, fn)
+		}
+		return out
+	}
+	return val
+}
+
+// streamRestorer restores placeholders in streamed text. A placeholder can be
+// split across chunks, so a trailing partial "[PII_…" is held back until the
+// next chunk completes or rules it out.
+type streamRestorer struct {
+	vault   *RedactionVault
+	pending string
+}
+
+func (s *streamRestorer) push(text string) string {
+	s.pending += text
+	cut := len(s.pending)
+	if i := strings.LastIndexByte(s.pending, '['); i >= 0 && isPlaceholderPrefix(s.pending[i:]) {
+		cut = i
+	}
+	out := s.vault.Restore(s.pending[:cut])
+	s.pending = s.pending[cut:]
+	return out
+}
+
+func (s *streamRestorer) flush() string {
+	out := s.vault.Restore(s.pending)
+	s.pending = ""
+	return out
+}
+
+// isPlaceholderPrefix reports whether s could still grow into a placeholder.
+func isPlaceholderPrefix(s string) bool {
+	if len(s) > 64 || strings.Contains(s, "]") {
+		return false
+	}
+	if len(s) <= len(piiPlaceholderPrefix) {
+		return strings.HasPrefix(piiPlaceholderPrefix, s)
+	}
+	if !strings.HasPrefix(s, piiPlaceholderPrefix) {
+		return false
+	}
+	for _, r := range s[len(piiPlaceholderPrefix):] {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// RedactingProvider tokenizes PII in every outbound message (all roles, tool
+// call arguments included) and restores the original values in the response
+// content, thinking, streamed chunks and tool-call arguments, so tools and
+// channels only ever see real values. RawAssistantContent is passed back
+// untouched: it stays in placeholder form, which is what the provider saw.
+// Images are not inspected.
+type RedactingProvider struct {
+	inner    Provider
+	redactor *PIIRedactor
+	vault    *RedactionVault
+}
+
+// NewRedactingProvider wraps p. A nil redactor returns p unchanged; a nil
+// vault gets a fresh one (one-off calls such as compaction or titles).
+func NewRedactingProvider(p Provider, redactor *PIIRedactor, vault *RedactionVault) Provider {
+	if redactor == nil || p == nil {
+		return p
+	}
+	if vault == nil {
+		vault = NewRedactionVault()
+	}
+	return &RedactingProvider{inner: p, redactor: redactor, vault: vault}
+}
+
+func (p *RedactingProvider) Name() string         { return p.inner.Name() }
+func (p *RedactingProvider) DefaultModel() string { return p.inner.DefaultModel() }
+
+// Unwrap returns the wrapped provider.
+func (p *RedactingProvider) Unwrap() Provider { return p.inner }
+
+func (p *RedactingProvider) SupportsThinking() bool {
+	tc, ok := p.inner.(ThinkingCapable)
+	return ok && tc.SupportsThinking()
+}
+
+func (p *RedactingProvider) Capabilities() ProviderCapabilities {
+	if ca, ok := p.inner.(CapabilitiesAware); ok {
+		return ca.Capabilities()
+	}
+	return ProviderCapabilities{Streaming: true, ToolCalling: true}
+}
+
+func (p *RedactingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	req = p.redactRequest(ctx, req)
+	resp, err := p.inner.Chat(ctx, req)
+	p.vault.RestoreResponse(resp)
+	return resp, err
+}
+
+func (p *RedactingProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	req = p.redactRequest(ctx, req)
+	content := &streamRestorer{vault: p.vault}
+	thinking := &streamRestorer{vault: p.vault}
+	emit := func(ch StreamChunk) {
+		if onChunk != nil && (ch.Content != "" || ch.Thinking != "" || ch.Done) {
+			onChunk(ch)
+		}
+	}
+	resp, err := p.inner.ChatStream(ctx, req, func(ch StreamChunk) {
+		out := StreamChunk{Content: content.push(ch.Content), Thinking: thinking.push(ch.Thinking)}
+		if ch.Done {
+			out.Content += content.flush()
+			out.Thinking += thinking.flush()
+			out.Done = true
+		}
+		emit(out)
+	})
+	emit(StreamChunk{Content: content.flush(), Thinking: thinking.flush()})
+	p.vault.RestoreResponse(resp)
+	return resp, err
+}
+
+func (p *RedactingProvider) redactRequest(ctx context.Context, req ChatRequest) ChatRequest {
+	counts := map[string]int{}
+	msgs := make([]Message, len(req.Messages))
+	for i, m := range req.Messages {
+		m.Content = p.redactor.Redact(p.vault, m.Content, counts)
+		m.Thinking = p.redactor.Redact(p.vault, m.Thinking, counts)
+		if len(m.ToolCalls) > 0 {
+			calls := make([]ToolCall, len(m.ToolCalls))
+			for j, tc := range m.ToolCalls {
+				tc.Arguments = p.redactor.redactArgs(p.vault, tc.Arguments, counts)
+				calls[j] = tc
+			}
+			m.ToolCalls = calls
+		}
+		msgs[i] = m
+	}
+	req.Messages = msgs
+	if obs := RedactionObservationFromContext(ctx); obs != nil {
+		obs.add(counts)
+	}
+	return req
+}
+
+// RedactBatchRequest tokenizes req with a fresh vault for a request that is
+// stored and answered later (llmbatch). The returned vault restores the
+// response and only holds this request's values.
+func (p *RedactingProvider) RedactBatchRequest(ctx context.Context, req ChatRequest) (ChatRequest, *RedactionVault) {
+	batch := &RedactingProvider{inner: p.inner, redactor: p.redactor, vault: NewRedactionVault()}
+	return batch.redactRequest(ctx, req), batch.vault
+}
+
+// RedactionMetadataKey is the span metadata key for per-class redaction counts.
+const RedactionMetadataKey = "pii_redactions"
+
+type redactionObservationKey struct{}
+
+// RedactionObservation collects redaction counts of the LLM call made under
+// its context, for span metadata. Nil-safe.
+type RedactionObservation struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// WithRedactionObservation attaches a fresh observation to ctx.
+func WithRedactionObservation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, redactionObservationKey{}, &RedactionObservation{})
+}
+
+// RedactionObservationFromContext returns the observation on ctx, or nil.
+func RedactionObservationFromContext(ctx context.Context) *RedactionObservation {
+	obs, _ := ctx.Value(redactionObservationKey{}).(*RedactionObservation)
+	return obs
+}
+
+func (o *RedactionObservation) add(counts map[string]int) {
+	if o == nil || len(counts) == 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.counts == nil {
+		o.counts = make(map[string]int, len(counts))
+	}
+	for k, n := range counts {
+		o.counts[k] += n
+	}
+}
+
+// Counts returns a copy of the per-class redaction counts.
+func (o *RedactionObservation) Counts() map[string]int {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return maps.Clone(o.counts)
+}
+
+// MergeRedactionMetadata adds counts to existing span metadata under
+// RedactionMetadataKey. No-op when nothing was redacted.
+func MergeRedactionMetadata(existing json.RawMessage, counts map[string]int) json.RawMessage {
+	if len(counts) == 0 {
+		return existing
+	}
+	payload := map[string]any{}
+	if len(existing) > 0 {
+		_ = json.Unmarshal(existing, &payload)
+	}
+	payload[RedactionMetadataKey] = counts
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return existing
+	}
+	return json.RawMessage(data)
+}
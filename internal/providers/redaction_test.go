@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// echoProvider replies with the last message and a tool call carrying it, and
+// keeps the request it saw.
+type echoProvider struct {
+	seen   ChatRequest
+	chunks []string // streamed as-is when set
+}
+
+func (p *echoProvider) Name() string         { return "echo" }
+func (p *echoProvider) DefaultModel() string { return "m" }
+
+func (p *echoProvider) Chat(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.seen = req
+	last := req.Messages[len(req.Messages)-1].Content
+	return &ChatResponse{
+		Content:   "you said " + last,
+		ToolCalls: []ToolCall{{ID: "1", Name: "send", Arguments: map[string]any{"to": last, "n": 1.0}}},
+	}, nil
+}
+
+func (p *echoProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	resp, _ := p.Chat(ctx, req)
+	resp.Content = strings.Join(p.chunks, "")
+	for _, c := range p.chunks {
+		onChunk(StreamChunk{Content: c})
+	}
+	onChunk(StreamChunk{Done: true})
+	return resp, nil
+}
+
+func TestPIIRedactor_Classes(t *testing.T) {
+	r, err := NewPIIRedactor(DefaultPIIClasses, []PIIPattern{{Class: "employee_id", Pattern: `EMP-\d{5}`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewRedactionVault()
+	counts := map[string]int{}
+	in := "Mail jane.doe@example.com or a@b.io, call +1 (555) 123-4567, card 4111 1111 1111 1111, " +
+		"SSN 123-45-6789, staff EMP-00042, again jane.doe@example.com. Meeting 2026-10-16, order 12345."
+	out := r.Redact(v, in, counts)
+
+	for _, leaked := range []string{"jane.doe", "a@b.io", "555", "4111", "6789", "EMP-00042"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("%q leaked in %q", leaked, out)
+		}
+	}
+	for _, kept := range []string{"2026-10-16", "12345"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("%q should not be redacted: %q", kept, out)
+		}
+	}
+	want := map[string]int{PIIEmail: 3, PIIPhone: 1, PIICard: 1, PIINationalID: 1, "employee_id": 1}
+	for class, n := range want {
+		if counts[class] != n {
+			t.Errorf("counts[%s] = %d, want %d (all: %v)", class, counts[class], n, counts)
+		}
+	}
+	if strings.Count(out, "[PII_EMAIL_1]") != 2 {
+		t.Errorf("repeated value should reuse its placeholder: %q", out)
+	}
+	if got := v.Restore(out); got != in {
+		t.Errorf("Restore = %q, want original", got)
+	}
+
+	// A non-Luhn 16-digit number is not a card.
+	if out := r.Redact(NewRedactionVault(), "ref 1234 5678 9012 3456", map[string]int{}); strings.Contains(out, "CARD") {
+		t.Errorf("non-Luhn number redacted as card: %q", out)
+	}
+	if _, err := NewPIIRedactor([]string{"passport"}, nil); err == nil {
+		t.Error("unknown class should fail")
+	}
+	if r, err := NewPIIRedactor(nil, nil); r != nil || err != nil {
+		t.Errorf("empty policy = %v, %v; want nil, nil", r, err)
+	}
+}
+
+func TestRedactingProvider_RoundTrip(t *testing.T) {
+	redactor, _ := NewPIIRedactor([]string{PIIEmail}, nil)
+	inner := &echoProvider{}
+	vault := NewRedactionVault()
+	p := NewRedactingProvider(inner, redactor, vault)
+
+	ctx := WithRedactionObservation(context.Background())
+	req := ChatRequest{Messages: []Message{
+		{Role: "system", Content: "owner is boss@corp.com"},
+		{Role: "assistant", ToolCalls: []ToolCall{{Name: "lookup", Arguments: map[string]any{"q": []any{"x@corp.com"}}}}},
+		{Role: "user", Content: "email bob@corp.com"},
+	}}
+	resp, err := p.Chat(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := inner.seen.Messages
+	if strings.Contains(sent[0].Content, "@") || strings.Contains(sent[2].Content, "@") {
+		t.Errorf("provider saw PII: %+v", sent)
+	}
+	if q := sent[1].ToolCalls[0].Arguments["q"].([]any)[0]; q != "[PII_EMAIL_2]" {
+		t.Errorf("tool call arg sent as %v", q)
+	}
+	if req.Messages[2].Content != "email bob@corp.com" {
+		t.Error("caller's request was mutated")
+	}
+	if resp.Content != "you said email bob@corp.com" || resp.ToolCalls[0].Arguments["to"] != "email bob@corp.com" {
+		t.Errorf("response not restored: %q %v", resp.Content, resp.ToolCalls[0].Arguments)
+	}
+	if got := RedactionObservationFromContext(ctx).Counts()[PIIEmail]; got != 3 {
+		t.Errorf("observed email count = %d, want 3", got)
+	}
+}
+
+func TestRedactingProvider_StreamSplitPlaceholder(t *testing.T) {
+	redactor, _ := NewPIIRedactor([]string{PIIEmail}, nil)
+	vault := NewRedactionVault()
+	inner := &echoProvider{chunks: []string{"Sent to [PI", "I_EMA", "IL_1] and [note]", " [PII_EMAIL_9]"}}
+	p := NewRedactingProvider(inner, redactor, vault)
+
+	var got strings.Builder
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "to me@x.org"}}},
+		func(c StreamChunk) {
+			if strings.Contains(c.Content, "[PI") && !strings.Contains(c.Content, "]") {
+				t.Errorf("partial placeholder leaked to caller: %q", c.Content)
+			}
+			got.WriteString(c.Content)
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Sent to me@x.org and [note] [PII_EMAIL_9]" // unknown placeholders pass through
+	if got.String() != want || resp.Content != want {
+		t.Errorf("stream = %q, resp = %q, want %q", got.String(), resp.Content, want)
+	}
+}
@@ -25,6 +25,14 @@ type Registry struct {
 	// so that ChatGPTOAuthRouter instances (created per-request) share rotation state.
 	roundRobinMu       sync.Mutex
 	roundRobinCounters map[string]int
+
+	// middlewares wrap every provider at registration (e.g. cassette recording).
+	middlewares []ProviderMiddleware
+
+	// balancer holds latency/error stats shared by per-request BalancedProvider
+	// instances, like roundRobinCounters does for ChatGPTOAuthRouter.
+	balancerOnce sync.Once
+	balancer     *Balancer
 }
 
 // NewRegistry creates a provider registry.
@@ -50,6 +58,12 @@ func (r *Registry) RoundRobinNext(key string, poolSize int, advance bool) int {
 	return idx
 }
 
+// Balancer returns the registry-wide load balancer.
+func (r *Registry) Balancer() *Balancer {
+	r.balancerOnce.Do(func() { r.balancer = NewBalancer() })
+	return r.balancer
+}
+
 // compoundKey returns "tenantID/name" for registry lookup.
 func compoundKey(tenantID uuid.UUID, name string) string {
 	return tenantID.String() + "/" + name
@@ -65,6 +79,14 @@ func (r *Registry) tenantFromContext(ctx context.Context) uuid.UUID {
 	return MasterTenantID
 }
 
+// Use adds provider middlewares applied to every provider registered after
+// this call. Call before registering providers.
+func (r *Registry) Use(middlewares ...ProviderMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
 // Register adds a provider to the registry under the master tenant.
 func (r *Registry) Register(provider Provider) {
 	r.RegisterForTenant(MasterTenantID, provider)
@@ -81,7 +103,7 @@ func (r *Registry) RegisterForTenant(tenantID uuid.UUID, provider Provider) {
 			c.Close()
 		}
 	}
-	r.providers[key] = provider
+	r.providers[key] = WrapProvider(provider, r.middlewares...)
 }
 
 // Unregister removes a provider from the master tenant.
@@ -0,0 +1,221 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ReplayProvider serves recorded cassettes instead of calling an LLM.
+// Strict mode only answers byte-identical normalized requests; fuzzy mode
+// falls back to the conversation shape (see cassetteRequest.keys) so runs
+// whose system prompt or ids differ from the recording still replay.
+type ReplayProvider struct {
+	name string
+	dir  string
+	mode string
+	meta cassetteMeta
+
+	byKey   map[string]*cassetteEntry
+	byFuzzy map[string]*cassetteEntry
+
+	mu     sync.Mutex
+	served map[*cassetteEntry]int
+}
+
+// NewReplayProvider loads the cassette in dir. name overrides the recorded
+// provider name (empty = recorded name); mode is CassetteModeStrict or
+// CassetteModeFuzzy (empty = strict).
+func NewReplayProvider(name, dir, mode string) (*ReplayProvider, error) {
+	switch mode {
+	case "":
+		mode = CassetteModeStrict
+	case CassetteModeStrict, CassetteModeFuzzy:
+	default:
+		return nil, fmt.Errorf("cassette: unknown replay mode %q", mode)
+	}
+	p := &ReplayProvider{
+		dir:     dir,
+		mode:    mode,
+		byKey:   make(map[string]*cassetteEntry),
+		byFuzzy: make(map[string]*cassetteEntry),
+		served:  make(map[*cassetteEntry]int),
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, cassetteMetaFile)); err == nil {
+		if err := json.Unmarshal(data, &p.meta); err != nil {
+			return nil, fmt.Errorf("cassette: parse %s: %w", cassetteMetaFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("cassette: %w", err)
+	}
+	sort.Strings(files) // deterministic fuzzy collisions
+	for _, f := range files {
+		if filepath.Base(f) == cassetteMetaFile {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		var e cassetteEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("cassette: parse %s: %w", filepath.Base(f), err)
+		}
+		if e.Version != cassetteVersion || len(e.Interactions) == 0 {
+			continue
+		}
+		// Recompute keys so hand-edited request bodies still match.
+		e.Key, e.FuzzyKey = e.Request.keys()
+		entry := &e
+		p.byKey[e.Key] = entry
+		if _, dup := p.byFuzzy[e.FuzzyKey]; !dup {
+			p.byFuzzy[e.FuzzyKey] = entry
+		}
+	}
+	if len(p.byKey) == 0 {
+		return nil, fmt.Errorf("cassette: no recordings in %s", dir)
+	}
+
+	p.name = name
+	if p.name == "" {
+		p.name = p.meta.Name
+	}
+	if p.name == "" {
+		p.name = "replay"
+	}
+	return p, nil
+}
+
+// NewReplayProviderFromEnv loads the cassette at sub under GOCLAW_CASSETTE_DIR.
+// An empty sub uses the provider name, which is where recording writes it.
+func NewReplayProviderFromEnv(name, sub, mode string) (*ReplayProvider, error) {
+	if sub == "" {
+		sub = cassetteDirName(name)
+	}
+	dir, err := ResolveCassetteDir(os.Getenv(CassetteDirEnv), sub)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayProvider(name, dir, mode)
+}
+
+func (p *ReplayProvider) Name() string         { return p.name }
+func (p *ReplayProvider) DefaultModel() string { return p.meta.DefaultModel }
+
+// SupportsThinking reports what the recorded provider reported.
+func (p *ReplayProvider) SupportsThinking() bool { return p.meta.SupportsThinking }
+
+// Capabilities reports the recorded provider's capabilities, so the pipeline
+// takes the same streaming/caching paths it took while recording.
+func (p *ReplayProvider) Capabilities() ProviderCapabilities { return p.meta.Capabilities }
+
+// PromptContribution reports the recorded provider's prompt contribution.
+func (p *ReplayProvider) PromptContribution() *PromptContribution { return p.meta.PromptContribution }
+
+// Models returns the models seen in the cassette, sorted.
+func (p *ReplayProvider) Models() []string {
+	seen := map[string]bool{}
+	for _, e := range p.byKey {
+		if e.Request.Model != "" {
+			seen[e.Request.Model] = true
+		}
+	}
+	models := make([]string, 0, len(seen))
+	for m := range seen {
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	return models
+}
+
+func (p *ReplayProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	in, err := p.next(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if in.Error != nil {
+		return nil, in.Error.err()
+	}
+	return in.Response.chatResponse(), nil
+}
+
+func (p *ReplayProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	in, err := p.next(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if onChunk != nil {
+		if in.Stream {
+			for _, c := range in.Chunks {
+				onChunk(c)
+			}
+		} else if in.Response != nil {
+			// Recorded via Chat: synthesize a single-chunk stream.
+			if in.Response.Thinking != "" {
+				onChunk(StreamChunk{Thinking: in.Response.Thinking})
+			}
+			if in.Response.Content != "" {
+				onChunk(StreamChunk{Content: in.Response.Content})
+			}
+			onChunk(StreamChunk{Done: true})
+		}
+	}
+	if in.Error != nil {
+		return nil, in.Error.err()
+	}
+	return in.Response.chatResponse(), nil
+}
+
+// next returns the next recorded interaction for req. Interactions are
+// served in recorded order; the last one repeats once exhausted.
+func (p *ReplayProvider) next(ctx context.Context, req ChatRequest) (*cassetteInteraction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	norm := normalizeCassetteRequest(req)
+	key, fuzzy := norm.keys()
+	entry := p.byKey[key]
+	if entry == nil && p.mode == CassetteModeFuzzy {
+		entry = p.byFuzzy[fuzzy]
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("%w: provider=%s mode=%s key=%s last=%q",
+			ErrCassetteMiss, p.name, p.mode, key, lastMessagePreview(norm.Messages))
+	}
+
+	p.mu.Lock()
+	i := p.served[entry]
+	p.served[entry] = i + 1
+	p.mu.Unlock()
+	if i >= len(entry.Interactions) {
+		i = len(entry.Interactions) - 1
+	}
+	in := entry.Interactions[i]
+	if in.Response == nil && in.Error == nil {
+		return nil, fmt.Errorf("%w: empty interaction %s[%d]", ErrCassetteMiss, entry.Key, i)
+	}
+	return &in, nil
+}
+
+// lastMessagePreview helps locate the diverging turn in a miss error.
+func lastMessagePreview(msgs []cassetteMessage) string {
+	if len(msgs) == 0 {
+		return ""
+	}
+	m := msgs[len(msgs)-1]
+	s := m.Role + ": " + strings.TrimSpace(m.Content)
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:80]) + "…"
+	}
+	return s
+}
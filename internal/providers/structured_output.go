@@ -0,0 +1,403 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Response format types for ChatRequest.ResponseFormat. Mirrors the OpenAI
+// response_format vocabulary so HTTP callers can pass it through unchanged.
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// structuredOutputToolName is the synthetic tool used to force JSON output on
+// providers whose only schema-constrained mechanism is tool use (Anthropic, Bedrock).
+const structuredOutputToolName = "structured_output"
+
+// structuredMaxAttempts bounds ChatStructured calls (initial + validation retries).
+const structuredMaxAttempts = 2
+
+// ErrStructuredOutput is returned by ChatStructured when the model output still
+// fails JSON/schema validation after all retries.
+var ErrStructuredOutput = errors.New("structured output: response does not match schema")
+
+// ResponseFormat constrains the shape of the assistant's final message.
+// Each provider translates it to its native mechanism: OpenAI-compatible
+// response_format (Ollama maps it to its native "format"), Codex text.format,
+// Gemini responseSchema, and a forced tool call on Anthropic / Bedrock.
+type ResponseFormat struct {
+	Type   string         `json:"type"`             // "json_schema", "json_object" or "text"
+	Name   string         `json:"name,omitempty"`   // schema name (OpenAI requires ^[a-zA-Z0-9_-]{1,64}$)
+	Schema map[string]any `json:"schema,omitempty"` // JSON Schema; required for json_schema
+	Strict bool           `json:"strict,omitempty"` // OpenAI strict mode — constrained decoding
+}
+
+// JSONSchemaFormat returns a json_schema response format for the given schema.
+func JSONSchemaFormat(name string, schema map[string]any) *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatJSONSchema, Name: name, Schema: schema}
+}
+
+// wantsJSON reports whether the format constrains output to JSON.
+func (f *ResponseFormat) wantsJSON() bool {
+	return f != nil && (f.Type == ResponseFormatJSONSchema || f.Type == ResponseFormatJSONObject)
+}
+
+// hasSchema reports whether the format carries a JSON Schema.
+func (f *ResponseFormat) hasSchema() bool {
+	return f != nil && f.Type == ResponseFormatJSONSchema && len(f.Schema) > 0
+}
+
+// schemaName returns a wire-safe schema name (default "response").
+func (f *ResponseFormat) schemaName() string {
+	var b strings.Builder
+	for _, r := range f.Name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		}
+		if b.Len() == 64 {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "response"
+	}
+	return b.String()
+}
+
+// objectSchema returns the schema used for tool-based translations, which
+// require an object root. json_object maps to an unconstrained object.
+func (f *ResponseFormat) objectSchema() map[string]any {
+	if f.hasSchema() {
+		return f.Schema
+	}
+	return map[string]any{"type": "object", "additionalProperties": true}
+}
+
+// openAIResponseFormat builds the Chat Completions response_format value.
+func openAIResponseFormat(providerName string, f *ResponseFormat) map[string]any {
+	if !f.hasSchema() {
+		return map[string]any{"type": ResponseFormatJSONObject}
+	}
+	profile := profileForProvider(providerName)
+	profile.StrictToolMode = f.Strict
+	return map[string]any{
+		"type": ResponseFormatJSONSchema,
+		"json_schema": map[string]any{
+			"name":   f.schemaName(),
+			"schema": normalizeWithProfile(profile, f.Schema),
+			"strict": f.Strict,
+		},
+	}
+}
+
+// structuredOutputToolDescription tells tool-forced providers what the synthetic tool is for.
+const structuredOutputToolDescription = "Return the final answer. The input must be the complete response object; it is delivered to the user verbatim."
+
+// takeStructuredToolCall moves the forced structured-output tool call into
+// Content so callers see the same shape as providers with native JSON mode.
+// The synthetic tool never reaches tool dispatch: when the model also called
+// real tools, the premature answer is dropped and the tool loop continues
+// with the real calls (the model must answer again once it has their
+// results). Returns the JSON text moved into Content.
+func takeStructuredToolCall(f *ResponseFormat, resp *ChatResponse) string {
+	if !f.wantsJSON() || resp == nil {
+		return ""
+	}
+	if len(resp.ToolCalls) > 1 {
+		calls := resp.ToolCalls[:0:0]
+		for _, tc := range resp.ToolCalls {
+			if tc.Name != structuredOutputToolName {
+				calls = append(calls, tc)
+			}
+		}
+		if len(calls) < len(resp.ToolCalls) {
+			// Raw blocks would replay the dropped call; thinking (the only
+			// reason to pass them back) is off while the tool is forced.
+			resp.ToolCalls = calls
+			resp.RawAssistantContent = nil
+		}
+		return ""
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != structuredOutputToolName {
+		return ""
+	}
+	data, err := json.Marshal(resp.ToolCalls[0].Arguments)
+	if err != nil {
+		return ""
+	}
+	resp.Content += string(data)
+	resp.ToolCalls = nil
+	resp.RawAssistantContent = nil
+	if resp.FinishReason == "tool_calls" {
+		resp.FinishReason = "stop"
+	}
+	return string(data)
+}
+
+// SupportsStructuredOutput reports whether the provider translates
+// ChatRequest.ResponseFormat natively.
+func SupportsStructuredOutput(p Provider) bool {
+	if ca, ok := p.(CapabilitiesAware); ok {
+		return ca.Capabilities().StructuredOutput
+	}
+	return false
+}
+
+// StructuredOutputPrompt returns a copy of req for providers without native
+// structured output: the schema is described in the system prompt and
+// ResponseFormat is cleared.
+func StructuredOutputPrompt(req ChatRequest) ChatRequest {
+	f := req.ResponseFormat
+	req.ResponseFormat = nil
+	if !f.wantsJSON() {
+		return req
+	}
+
+	instruction := "Respond with a single JSON object only — no prose, no markdown code fences."
+	if f.hasSchema() {
+		schema, _ := json.MarshalIndent(f.Schema, "", "  ")
+		instruction = "Respond with a single JSON value that validates against this JSON Schema — no prose, no markdown code fences.\n\n" + string(schema)
+	}
+
+	msgs := make([]Message, len(req.Messages), len(req.Messages)+1)
+	copy(msgs, req.Messages)
+	if len(msgs) > 0 && msgs[0].Role == "system" {
+		msgs[0].Content += "\n\n" + instruction
+	} else {
+		msgs = append([]Message{{Role: "system", Content: instruction}}, msgs...)
+	}
+	req.Messages = msgs
+	return req
+}
+
+// ChatStructured sends req with req.ResponseFormat and returns a response whose
+// Content is JSON that validates against the schema. Providers with native
+// support get the schema on the wire; others (or natives that reject it with a
+// 400) get prompt instructions instead. Invalid output is retried once with the
+// validation error fed back. For native providers, non-object root schemas
+// (arrays, enums) are wrapped in {"result": ...} on the wire, since most
+// providers require an object root, and unwrapped transparently.
+//
+// On persistent validation failure the last response is returned together with
+// an error wrapping ErrStructuredOutput, so callers may still attempt lenient parsing.
+func ChatStructured(ctx context.Context, p Provider, req ChatRequest) (*ChatResponse, error) {
+	f := req.ResponseFormat
+	if !f.wantsJSON() {
+		return p.Chat(ctx, req)
+	}
+
+	native := SupportsStructuredOutput(p)
+	schema, wrapped := f.Schema, false
+	if native {
+		req.ResponseFormat, wrapped = nativeResponseFormat(f)
+		schema = req.ResponseFormat.Schema
+	}
+	toPrompt := func() {
+		native, wrapped, schema = false, false, f.Schema
+		req.ResponseFormat = f
+		req = StructuredOutputPrompt(req)
+	}
+	if !native {
+		toPrompt()
+	}
+
+	var (
+		resp   *ChatResponse
+		usage  Usage
+		verr   error
+		result string
+	)
+	for attempt := 0; attempt < structuredMaxAttempts; attempt++ {
+		var err error
+		resp, err = p.Chat(ctx, req)
+		if err != nil && native && isResponseFormatRejected(err) {
+			slog.Warn("structured output: native response format rejected, falling back to prompt mode",
+				"provider", p.Name(), "error", err)
+			toPrompt()
+			resp, err = p.Chat(ctx, req)
+		}
+		if err != nil {
+			return nil, err
+		}
+		addUsage(&usage, resp.Usage)
+
+		result, verr = decodeStructured(resp.Content, schema, wrapped)
+		if verr == nil {
+			break
+		}
+		req.Messages = structuredRetryMessages(req.Messages, resp.Content, verr)
+	}
+
+	if resp.Usage != nil {
+		resp.Usage = &usage
+	}
+	if verr != nil {
+		return resp, fmt.Errorf("%w: %v", ErrStructuredOutput, verr)
+	}
+	resp.Content = result
+	return resp, nil
+}
+
+// ValidateStructuredReply applies ChatStructured's validation to a response
+// obtained in prompt mode (see StructuredOutputPrompt), for callers that run
+// their own request loop: invalid output is re-asked once with the validation
+// error fed back, and valid output replaces Content with the canonical JSON.
+// req is the request that produced resp. Responses with tool calls are
+// returned untouched since the tool loop has not finished. On persistent
+// failure the last response is returned with an error wrapping ErrStructuredOutput.
+func ValidateStructuredReply(ctx context.Context, p Provider, req ChatRequest, f *ResponseFormat, resp *ChatResponse) (*ChatResponse, error) {
+	if !f.wantsJSON() || resp == nil || len(resp.ToolCalls) > 0 {
+		return resp, nil
+	}
+	var usage Usage
+	addUsage(&usage, resp.Usage)
+	result, verr := decodeStructured(resp.Content, f.Schema, false)
+	for attempt := 1; verr != nil && attempt < structuredMaxAttempts; attempt++ {
+		req.Messages = structuredRetryMessages(req.Messages, resp.Content, verr)
+		next, err := p.Chat(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resp = next
+		addUsage(&usage, resp.Usage)
+		result, verr = decodeStructured(resp.Content, f.Schema, false)
+	}
+
+	if resp.Usage != nil {
+		resp.Usage = &usage
+	}
+	if verr != nil {
+		return resp, fmt.Errorf("%w: %v", ErrStructuredOutput, verr)
+	}
+	resp.Content = result
+	return resp, nil
+}
+
+// structuredRetryMessages appends the invalid answer and a correction request.
+func structuredRetryMessages(msgs []Message, content string, verr error) []Message {
+	return append(append([]Message(nil), msgs...),
+		Message{Role: "assistant", Content: content},
+		Message{Role: "user", Content: fmt.Sprintf(
+			"Your previous response was not valid: %v. Reply again with only the corrected JSON.", verr)},
+	)
+}
+
+// addUsage accumulates u into dst.
+func addUsage(dst *Usage, u *Usage) {
+	if u == nil {
+		return
+	}
+	dst.PromptTokens += u.PromptTokens
+	dst.CompletionTokens += u.CompletionTokens
+	dst.TotalTokens += u.TotalTokens
+	dst.CacheCreationTokens += u.CacheCreationTokens
+	dst.CacheReadTokens += u.CacheReadTokens
+	dst.ThinkingTokens += u.ThinkingTokens
+}
+
+// nativeResponseFormat returns the format sent on the wire to providers with
+// native structured output. Non-object root schemas are wrapped in
+// {"result": ...}; the second return value reports whether that happened.
+func nativeResponseFormat(f *ResponseFormat) (*ResponseFormat, bool) {
+	if !f.hasSchema() {
+		return f, false
+	}
+	if t, _ := f.Schema["type"].(string); t == "object" {
+		return f, false
+	}
+	wf := *f
+	wf.Schema = map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{"result": f.Schema},
+		"required":             []any{"result"},
+		"additionalProperties": false,
+	}
+	return &wf, true
+}
+
+// DecodeStructuredResponse validates resp.Content against f the same way
+// ChatStructured does for a native provider (no retry) and replaces Content
+// with the canonical, unwrapped JSON. Used for responses that did not go
+// through ChatStructured, e.g. provider batch results. No-op when f does not
+// request JSON. On failure resp is left untouched and the error wraps
+// ErrStructuredOutput.
+func DecodeStructuredResponse(f *ResponseFormat, resp *ChatResponse) error {
+	if !f.wantsJSON() || resp == nil {
+		return nil
+	}
+	wire, wrapped := nativeResponseFormat(f)
+	result, err := decodeStructured(resp.Content, wire.Schema, wrapped)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrStructuredOutput, err)
+	}
+	resp.Content = result
+	return nil
+}
+
+// decodeStructured strips code fences, parses and validates the content, and
+// returns the canonical JSON text (unwrapping "result" when the schema was wrapped).
+func decodeStructured(content string, schema map[string]any, wrapped bool) (string, error) {
+	text := StripJSONFences(content)
+	if text == "" {
+		return "", fmt.Errorf("empty response")
+	}
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return "", fmt.Errorf("invalid JSON: %v", err)
+	}
+	if schema != nil {
+		if err := ValidateJSONSchema(schema, v); err != nil {
+			return "", err
+		}
+	} else if _, ok := v.(map[string]any); !ok {
+		return "", fmt.Errorf("expected a JSON object")
+	}
+	if wrapped {
+		v = v.(map[string]any)["result"]
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// StripJSONFences trims whitespace and a surrounding markdown code fence
+// (```json ... ```) from model output.
+func StripJSONFences(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	if nl := strings.IndexByte(s, '\n'); nl >= 0 {
+		s = s[nl+1:]
+	} else {
+		s = strings.TrimPrefix(s, "```")
+	}
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "```")
+	return strings.TrimSpace(s)
+}
+
+// isResponseFormatRejected reports whether a native structured-output request
+// failed because the endpoint/model does not accept the schema (HTTP 400/422
+// that is not a context-overflow or content-policy rejection).
+func isResponseFormatRejected(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	if httpErr.Status != 400 && httpErr.Status != 422 {
+		return false
+	}
+	lower := strings.ToLower(httpErr.Body)
+	return !isContextOverflow(lower) && !isContentFiltered(lower)
+}
@@ -0,0 +1,306 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testPersonSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"name": map[string]any{"type": "string"},
+		"age":  map[string]any{"type": "integer"},
+	},
+	"required":             []any{"name", "age"},
+	"additionalProperties": false,
+}
+
+// scriptedProvider returns canned contents in order and records requests.
+// It does not implement CapabilitiesAware, so ChatStructured uses prompt mode.
+type scriptedProvider struct {
+	contents []string
+	reqs     []ChatRequest
+}
+
+func (s *scriptedProvider) Chat(_ context.Context, req ChatRequest) (*ChatResponse, error) {
+	s.reqs = append(s.reqs, req)
+	c := s.contents[min(len(s.reqs)-1, len(s.contents)-1)]
+	return &ChatResponse{Content: c, FinishReason: "stop", Usage: &Usage{TotalTokens: 10}}, nil
+}
+
+func (s *scriptedProvider) ChatStream(ctx context.Context, req ChatRequest, _ func(StreamChunk)) (*ChatResponse, error) {
+	return s.Chat(ctx, req)
+}
+func (s *scriptedProvider) DefaultModel() string { return "m" }
+func (s *scriptedProvider) Name() string         { return "scripted" }
+
+func TestChatStructuredPromptFallbackRetriesOnInvalidOutput(t *testing.T) {
+	p := &scriptedProvider{contents: []string{
+		`{"name":"Ada"}`, // missing age → retry with feedback
+		"```json\n{\"name\":\"Ada\",\"age\":36}\n```",
+	}}
+	resp, err := ChatStructured(context.Background(), p, ChatRequest{
+		Messages:       []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "who?"}},
+		ResponseFormat: JSONSchemaFormat("person", testPersonSchema),
+	})
+	if err != nil {
+		t.Fatalf("ChatStructured: %v", err)
+	}
+	if resp.Content != `{"age":36,"name":"Ada"}` {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if resp.Usage.TotalTokens != 20 {
+		t.Errorf("usage not aggregated: %+v", resp.Usage)
+	}
+	if len(p.reqs) != 2 {
+		t.Fatalf("calls = %d, want 2", len(p.reqs))
+	}
+	first := p.reqs[0]
+	if first.ResponseFormat != nil || !strings.Contains(first.Messages[0].Content, `"required"`) {
+		t.Errorf("prompt mode should clear ResponseFormat and inline the schema: %+v", first.Messages[0])
+	}
+	retry := p.reqs[1].Messages
+	if last := retry[len(retry)-1]; last.Role != "user" || !strings.Contains(last.Content, `missing required property "age"`) {
+		t.Errorf("retry feedback = %+v", last)
+	}
+}
+
+func TestChatStructuredReturnsLastResponseOnPersistentFailure(t *testing.T) {
+	p := &scriptedProvider{contents: []string{"not json"}}
+	resp, err := ChatStructured(context.Background(), p, ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "q"}},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if !errors.Is(err, ErrStructuredOutput) {
+		t.Fatalf("err = %v, want ErrStructuredOutput", err)
+	}
+	if resp == nil || resp.Content != "not json" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestValidateStructuredReplyReasksFinalTurn(t *testing.T) {
+	f := JSONSchemaFormat("person", testPersonSchema)
+	req := StructuredOutputPrompt(ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "who?"}},
+		ResponseFormat: f,
+	})
+	p := &scriptedProvider{contents: []string{`{"name":"Ada","age":36}`}}
+
+	// A tool-calling turn is left for the tool loop.
+	toolTurn := &ChatResponse{Content: "looking up", ToolCalls: []ToolCall{{ID: "c1", Name: "search"}}}
+	if resp, err := ValidateStructuredReply(context.Background(), p, req, f, toolTurn); err != nil || resp != toolTurn || len(p.reqs) != 0 {
+		t.Fatalf("tool turn: resp=%+v err=%v calls=%d", resp, err, len(p.reqs))
+	}
+
+	final := &ChatResponse{Content: "Ada is 36.", FinishReason: "stop", Usage: &Usage{TotalTokens: 5}}
+	resp, err := ValidateStructuredReply(context.Background(), p, req, f, final)
+	if err != nil {
+		t.Fatalf("ValidateStructuredReply: %v", err)
+	}
+	if resp.Content != `{"age":36,"name":"Ada"}` || resp.Usage.TotalTokens != 15 {
+		t.Errorf("resp = %+v usage = %+v", resp, resp.Usage)
+	}
+	if len(p.reqs) != 1 {
+		t.Fatalf("calls = %d, want 1", len(p.reqs))
+	}
+	retry := p.reqs[0].Messages
+	if last := retry[len(retry)-1]; last.Role != "user" || !strings.Contains(last.Content, "invalid JSON") {
+		t.Errorf("retry feedback = %+v", last)
+	}
+
+	p = &scriptedProvider{contents: []string{"still prose"}}
+	if _, err := ValidateStructuredReply(context.Background(), p, req, f, final); !errors.Is(err, ErrStructuredOutput) {
+		t.Errorf("err = %v, want ErrStructuredOutput", err)
+	}
+}
+
+func TestChatStructuredOpenAINativeWrapsArraySchema(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"result\":[\"a\",\"b\"]}"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider("openai", "k", srv.URL, "gpt-4o")
+	resp, err := ChatStructured(context.Background(), p, ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "list"}},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONSchema, Name: "tags list!", Schema: map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, Strict: true},
+	})
+	if err != nil {
+		t.Fatalf("ChatStructured: %v", err)
+	}
+	if resp.Content != `["a","b"]` {
+		t.Errorf("content = %q", resp.Content)
+	}
+	rf, _ := gotBody["response_format"].(map[string]any)
+	js, _ := rf["json_schema"].(map[string]any)
+	if rf["type"] != "json_schema" || js["name"] != "tagslist" || js["strict"] != true {
+		t.Fatalf("response_format = %v", gotBody["response_format"])
+	}
+	schema := js["schema"].(map[string]any)
+	if schema["type"] != "object" || schema["properties"].(map[string]any)["result"] == nil {
+		t.Errorf("array root should be wrapped: %v", schema)
+	}
+}
+
+func TestChatStructuredFallsBackWhenNativeFormatRejected(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["response_format"]; ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"response_format json_schema is not supported"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"name\":\"Bo\",\"age\":3}"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAIProvider("deepseek", "k", srv.URL, "deepseek-chat")
+	p.retryConfig.Attempts = 1
+	resp, err := ChatStructured(context.Background(), p, ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "q"}},
+		ResponseFormat: JSONSchemaFormat("person", testPersonSchema),
+	})
+	if err != nil {
+		t.Fatalf("ChatStructured: %v", err)
+	}
+	if calls != 2 || resp.Content != `{"age":3,"name":"Bo"}` {
+		t.Errorf("calls=%d content=%q", calls, resp.Content)
+	}
+}
+
+func TestAnthropicStructuredOutputForcesTool(t *testing.T) {
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"content":[{"type":"tool_use","id":"tu_1","name":"structured_output","input":{"name":"Ada","age":36}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	p := newTestAnthropicProvider(srv.URL)
+	resp, err := p.Chat(context.Background(), ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "who?"}},
+		ResponseFormat: JSONSchemaFormat("person", testPersonSchema),
+		Options:        map[string]any{OptThinkingLevel: "high"},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	choice, _ := gotBody["tool_choice"].(map[string]any)
+	if choice["type"] != "tool" || choice["name"] != structuredOutputToolName {
+		t.Errorf("tool_choice = %v", gotBody["tool_choice"])
+	}
+	if _, ok := gotBody["thinking"]; ok {
+		t.Error("thinking must be disabled with a forced tool_choice")
+	}
+	if resp.Content != `{"age":36,"name":"Ada"}` || len(resp.ToolCalls) != 0 || resp.FinishReason != "stop" {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestAnthropicStructuredOutputNotDispatchedWithRealTools(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[
+			{"type":"tool_use","id":"tu_1","name":"search","input":{"q":"ada"}},
+			{"type":"tool_use","id":"tu_2","name":"structured_output","input":{"name":"Ada","age":0}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	p := newTestAnthropicProvider(srv.URL)
+	resp, err := p.Chat(context.Background(), ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "who?"}},
+		Tools:          []ToolDefinition{{Type: "function", Function: ToolFunctionSchema{Name: "search", Parameters: map[string]any{"type": "object"}}}},
+		ResponseFormat: JSONSchemaFormat("person", testPersonSchema),
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "search" || resp.RawAssistantContent != nil {
+		t.Errorf("tool calls = %+v raw = %s", resp.ToolCalls, resp.RawAssistantContent)
+	}
+	if resp.Content != "" {
+		t.Errorf("premature structured answer surfaced: %q", resp.Content)
+	}
+}
+
+func TestStructuredOutputRequestBodies(t *testing.T) {
+	req := ChatRequest{
+		Messages:       []Message{{Role: "user", Content: "q"}},
+		ResponseFormat: JSONSchemaFormat("person", testPersonSchema),
+	}
+
+	vertex := NewVertexProvider("proj", "", nil).buildRequestBody("gemini-2.5-flash", req)
+	gen := vertex["generationConfig"].(map[string]any)
+	if gen["responseMimeType"] != "application/json" || gen["responseSchema"] == nil {
+		t.Errorf("vertex generationConfig = %v", gen)
+	}
+
+	// With tools, Gemini gets the schema as a prompt instruction instead.
+	withTools := req
+	withTools.Tools = []ToolDefinition{{Type: "function", Function: ToolFunctionSchema{Name: "t", Parameters: map[string]any{"type": "object"}}}}
+	vertex = NewVertexProvider("proj", "", nil).buildRequestBody("gemini-2.5-flash", withTools)
+	if _, ok := vertex["generationConfig"]; ok {
+		t.Errorf("vertex with tools should not set responseMimeType: %v", vertex["generationConfig"])
+	}
+	if _, ok := vertex["systemInstruction"]; !ok {
+		t.Error("vertex with tools should carry the schema in systemInstruction")
+	}
+
+	codex := NewCodexProvider("codex", &staticTokenSource{token: "t"}, "", "").buildRequestBody(req, false)
+	format := codex["text"].(map[string]any)["format"].(map[string]any)
+	if format["type"] != "json_schema" || format["name"] != "person" {
+		t.Errorf("codex text.format = %v", format)
+	}
+
+	bedrock := (&BedrockProvider{}).buildRequestBody("anthropic.claude-sonnet-4", req)
+	tc := bedrock["toolConfig"].(map[string]any)
+	if choice := tc["toolChoice"].(map[string]any)["tool"].(map[string]any); choice["name"] != structuredOutputToolName {
+		t.Errorf("bedrock toolChoice = %v", tc["toolChoice"])
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"kind":  map[string]any{"type": "string", "enum": []string{"a", "b"}},
+			"items": map[string]any{"type": "array", "items": map[string]any{"anyOf": []any{map[string]any{"type": "integer"}, map[string]any{"type": "null"}}}},
+		},
+		"required": []string{"kind"},
+	}
+	cases := []struct {
+		in      string
+		wantErr string
+	}{
+		{`{"kind":"a","items":[1,null,3]}`, ""},
+		{`{"kind":"c"}`, "$.kind"},
+		{`{"items":[]}`, `missing required property "kind"`},
+		{`{"kind":"a","items":[1.5]}`, "$.items[0]"},
+		{`[]`, "expected object"},
+	}
+	for _, c := range cases {
+		var v any
+		if err := json.Unmarshal([]byte(c.in), &v); err != nil {
+			t.Fatal(err)
+		}
+		err := ValidateJSONSchema(schema, v)
+		switch {
+		case c.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", c.in, err)
+		case c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)):
+			t.Errorf("%s: err = %v, want containing %q", c.in, err, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package providers
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ValidateJSONSchema checks a decoded JSON value (from encoding/json into any)
+// against the subset of JSON Schema used for structured output: type, enum,
+// const, properties, required, additionalProperties:false, items, anyOf/oneOf,
+// minItems/maxItems. Unknown keywords are ignored. Returns the first violation
+// with a JSON-pointer-like path so it can be fed back to the model.
+func ValidateJSONSchema(schema map[string]any, v any) error {
+	return validateSchemaAt(schema, v, "$", 0)
+}
+
+func validateSchemaAt(schema map[string]any, v any, path string, depth int) error {
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+
+	for _, key := range []string{"anyOf", "oneOf"} {
+		variants, ok := schema[key].([]any)
+		if !ok || len(variants) == 0 {
+			continue
+		}
+		matched := false
+		for _, variant := range variants {
+			if vm, ok := variant.(map[string]any); ok && validateSchemaAt(vm, v, path, depth+1) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: value does not match any allowed schema", path)
+		}
+	}
+
+	if err := checkSchemaType(schema["type"], v, path); err != nil {
+		return err
+	}
+
+	if enum, ok := schemaEnum(schema); ok && !slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(e, v) }) {
+		return fmt.Errorf("%s: %v is not one of %v", path, v, enum)
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, v) {
+		return fmt.Errorf("%s: must equal %v", path, c)
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, name := range schemaRequired(schema) {
+			if _, ok := val[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		for name, pv := range val {
+			ps, known := props[name].(map[string]any)
+			if !known {
+				if ap, ok := schema["additionalProperties"].(bool); ok && !ap {
+					return fmt.Errorf("%s: unexpected property %q", path, name)
+				}
+				continue
+			}
+			if err := validateSchemaAt(ps, pv, path+"."+name, depth+1); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if n, ok := schemaNumber(schema["minItems"]); ok && float64(len(val)) < n {
+			return fmt.Errorf("%s: expected at least %v items, got %d", path, n, len(val))
+		}
+		if n, ok := schemaNumber(schema["maxItems"]); ok && float64(len(val)) > n {
+			return fmt.Errorf("%s: expected at most %v items, got %d", path, n, len(val))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				if err := validateSchemaAt(items, item, fmt.Sprintf("%s[%d]", path, i), depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkSchemaType validates the "type" keyword (a string or a list of strings).
+func checkSchemaType(t any, v any, path string) error {
+	var types []string
+	switch tv := t.(type) {
+	case string:
+		types = []string{tv}
+	case []any:
+		for _, x := range tv {
+			if s, ok := x.(string); ok {
+				types = append(types, s)
+			}
+		}
+	case []string:
+		types = tv
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	got := jsonTypeOf(v)
+	for _, want := range types {
+		if want == got || (want == "number" && got == "integer") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), got)
+}
+
+// jsonTypeOf names the JSON Schema type of a decoded value.
+func jsonTypeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return inferType(v)
+	}
+}
+
+func schemaRequired(schema map[string]any) []string {
+	switch r := schema["required"].(type) {
+	case []string:
+		return r
+	case []any:
+		out := make([]string, 0, len(r))
+		for _, x := range r {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// schemaEnum returns the "enum" keyword, accepting []string from Go-built schemas.
+func schemaEnum(schema map[string]any) ([]any, bool) {
+	switch e := schema["enum"].(type) {
+	case []any:
+		return e, true
+	case []string:
+		out := make([]any, len(e))
+		for i, s := range e {
+			out[i] = s
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// jsonEqual compares scalar enum/const values, treating Go ints in Go-built
+// schemas as equal to the float64s produced by encoding/json.
+func jsonEqual(a, b any) bool {
+	if an, ok := schemaNumber(a); ok {
+		bn, ok := schemaNumber(b)
+		return ok && an == bn
+	}
+	return reflect.DeepEqual(a, b)
+}
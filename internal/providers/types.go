@@ -77,6 +77,10 @@ type ChatRequest struct {
 	Tools    []ToolDefinition `json:"tools,omitempty"`
 	Model    string           `json:"model,omitempty"`
 	Options  map[string]any   `json:"options,omitempty"`
+
+	// ResponseFormat constrains the final assistant message to JSON (optionally
+	// schema-validated). Nil means free-form text. See ChatStructured.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
 }
 
 // ChatResponse is the result from an LLM call.
@@ -0,0 +1,354 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	vertexDefaultLocation = "us-central1"
+	vertexDefaultModel    = "gemini-2.5-flash"
+)
+
+// VertexProvider implements Provider for Gemini models on Google Cloud Vertex AI
+// using the native generateContent / streamGenerateContent endpoints. Requests carry
+// an OAuth access token from a TokenSource (service-account JWT exchange).
+type VertexProvider struct {
+	name         string
+	projectID    string
+	location     string
+	baseURL      string
+	defaultModel string
+	tokenSource  TokenSource
+	client       *http.Client
+	retryConfig  RetryConfig
+}
+
+type VertexOption func(*VertexProvider)
+
+// WithVertexName overrides the provider name (default: "vertex_ai").
+func WithVertexName(name string) VertexOption {
+	return func(p *VertexProvider) {
+		if name != "" {
+			p.name = name
+		}
+	}
+}
+
+func WithVertexModel(model string) VertexOption {
+	return func(p *VertexProvider) {
+		if model != "" {
+			p.defaultModel = model
+		}
+	}
+}
+
+// WithVertexBaseURL overrides the regional endpoint (Private Service Connect, tests).
+func WithVertexBaseURL(baseURL string) VertexOption {
+	return func(p *VertexProvider) {
+		if baseURL != "" {
+			p.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// NewVertexProvider creates a Vertex AI provider for a project and location.
+func NewVertexProvider(projectID, location string, ts TokenSource, opts ...VertexOption) *VertexProvider {
+	if location == "" {
+		location = vertexDefaultLocation
+	}
+	p := &VertexProvider{
+		name:         "vertex_ai",
+		projectID:    projectID,
+		location:     location,
+		baseURL:      VertexEndpoint(location),
+		defaultModel: vertexDefaultModel,
+		tokenSource:  ts,
+		client:       NewDefaultHTTPClient(),
+		retryConfig:  DefaultRetryConfig(),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// NewVertexProviderFromKey builds a provider from stored provider fields.
+// apiKey holds the service-account JSON key; projectID falls back to the key's project_id.
+func NewVertexProviderFromKey(name, projectID, location, apiBase, apiKey string, opts ...VertexOption) (*VertexProvider, error) {
+	key, err := ParseServiceAccountKey([]byte(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	ts, err := NewServiceAccountTokenSource(key)
+	if err != nil {
+		return nil, err
+	}
+	if projectID == "" {
+		projectID = key.ProjectID
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("vertex: project_id is required")
+	}
+	all := append([]VertexOption{WithVertexName(name), WithVertexBaseURL(apiBase)}, opts...)
+	return NewVertexProvider(projectID, location, ts, all...), nil
+}
+
+// VertexEndpoint returns the Vertex AI API endpoint for a location.
+// The "global" location uses the non-regional host.
+func VertexEndpoint(location string) string {
+	if location == "" || location == "global" {
+		return "https://aiplatform.googleapis.com"
+	}
+	return "https://" + location + "-aiplatform.googleapis.com"
+}
+
+func (p *VertexProvider) Name() string           { return p.name }
+func (p *VertexProvider) DefaultModel() string   { return p.defaultModel }
+func (p *VertexProvider) SupportsThinking() bool { return true }
+
+// Capabilities implements CapabilitiesAware for pipeline code-path selection.
+func (p *VertexProvider) Capabilities() ProviderCapabilities {
+	return ProviderCapabilities{
+		Streaming:        true,
+		ToolCalling:      true,
+		StreamWithTools:  true,
+		Thinking:         true,
+		Vision:           true,
+		CacheControl:     false,
+		StructuredOutput: true,
+		MaxContextWindow: 1_048_576,
+		TokenizerID:      "cl100k_base",
+	}
+}
+
+func (p *VertexProvider) resolveModel(model string) string {
+	if model == "" {
+		return p.defaultModel
+	}
+	return model
+}
+
+func (p *VertexProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	body := p.buildRequestBody(model, req)
+
+	resp, err := RetryDo(ctx, p.retryConfig, func() (*ChatResponse, error) {
+		respBody, err := p.doRequest(ctx, model, "generateContent", body)
+		if err != nil {
+			return nil, err
+		}
+		defer respBody.Close()
+
+		var parsed vertexResponse
+		if err := json.NewDecoder(respBody).Decode(&parsed); err != nil {
+			return nil, fmt.Errorf("vertex: decode response: %w", err)
+		}
+		return p.parseResponse(&parsed), nil
+	})
+	if resp != nil {
+		if strip, _ := req.Options[OptStripThinking].(bool); strip {
+			resp.Thinking = ""
+		}
+	}
+	return resp, err
+}
+
+// modelURL builds the publisher model URL for an action ("generateContent",
+// "streamGenerateContent"). Full resource names ("projects/...") are used as-is
+// so tuned-model endpoints work too.
+func (p *VertexProvider) modelURL(model, action string) string {
+	resource := model
+	if !strings.HasPrefix(model, "projects/") {
+		resource = "projects/" + url.PathEscape(p.projectID) +
+			"/locations/" + url.PathEscape(p.location) +
+			"/publishers/google/models/" + url.PathEscape(model)
+	}
+	u := p.baseURL + "/v1/" + resource + ":" + action
+	if action == "streamGenerateContent" {
+		u += "?alt=sse"
+	}
+	return u
+}
+
+// doRequest POSTs the body to the model action URL and returns the body on 200.
+func (p *VertexProvider) doRequest(ctx context.Context, model, action string, body any) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.modelURL(model, action), bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("vertex: create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	if p.tokenSource == nil {
+		return nil, fmt.Errorf("vertex: no credentials configured")
+	}
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, &HTTPError{
+			Status:     resp.StatusCode,
+			Body:       fmt.Sprintf("vertex: %s", string(respBody)),
+			RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	return resp.Body, nil
+}
+
+func (p *VertexProvider) parseResponse(resp *vertexResponse) *ChatResponse {
+	result := &ChatResponse{FinishReason: "stop"}
+
+	if len(resp.Candidates) > 0 {
+		cand := resp.Candidates[0]
+		for _, part := range cand.Content.Parts {
+			appendVertexPart(result, part)
+		}
+		result.FinishReason = vertexFinishReason(cand.FinishReason)
+	} else if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		result.FinishReason = "content_filter"
+	}
+	if len(result.ToolCalls) > 0 && result.FinishReason == "stop" {
+		result.FinishReason = "tool_calls"
+	}
+	if resp.UsageMetadata != nil {
+		result.Usage = resp.UsageMetadata.toUsage()
+	}
+	return result
+}
+
+// appendVertexPart folds one response part into the result: thought parts into
+// Thinking, function calls into ToolCalls (thoughtSignature → Metadata), text into Content.
+// Returns the visible text and thinking deltas for streaming callbacks.
+func appendVertexPart(result *ChatResponse, part vertexPart) (text, thinking string) {
+	switch {
+	case part.FunctionCall != nil:
+		fc := part.FunctionCall
+		id := fc.ID
+		if id == "" {
+			// Gemini does not always return call IDs; tool results are matched by name
+			// on the wire, but the agent loop needs unique IDs.
+			id = "call_" + uuid.NewString()
+		}
+		args := fc.Args
+		if args == nil {
+			args = make(map[string]any)
+		}
+		tc := ToolCall{ID: id, Name: strings.TrimSpace(fc.Name), Arguments: args}
+		if part.ThoughtSignature != "" {
+			tc.Metadata = map[string]string{"thought_signature": part.ThoughtSignature}
+		}
+		result.ToolCalls = append(result.ToolCalls, tc)
+	case part.Thought:
+		result.Thinking += part.Text
+		thinking = part.Text
+	default:
+		result.Content += part.Text
+		text = part.Text
+	}
+	if part.ThoughtSignature != "" {
+		result.ThinkingSignature = part.ThoughtSignature
+	}
+	return text, thinking
+}
+
+// vertexFinishReason maps Gemini finishReason values onto the internal vocabulary.
+func vertexFinishReason(reason string) string {
+	switch reason {
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "content_filter"
+	default:
+		return "stop"
+	}
+}
+
+// --- Vertex AI generateContent types (internal) ---
+
+type vertexResponse struct {
+	Candidates []struct {
+		Content      vertexContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+	UsageMetadata *vertexUsage `json:"usageMetadata,omitempty"`
+}
+
+type vertexContent struct {
+	Role  string       `json:"role,omitempty"` // "user" | "model"
+	Parts []vertexPart `json:"parts"`
+}
+
+// vertexPart is a Gemini content part. Exactly one data field is set;
+// ThoughtSignature may accompany any of them.
+type vertexPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *vertexInlineData       `json:"inlineData,omitempty"`
+	FunctionCall     *vertexFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *vertexFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type vertexInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // base64
+}
+
+type vertexFunctionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+}
+
+type vertexFunctionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type vertexUsage struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount"`
+}
+
+// toUsage maps Gemini usage; thought tokens are billed as output, so they are
+// included in CompletionTokens and reported separately as ThinkingTokens.
+func (u *vertexUsage) toUsage() *Usage {
+	return &Usage{
+		PromptTokens:     u.PromptTokenCount,
+		CompletionTokens: u.CandidatesTokenCount + u.ThoughtsTokenCount,
+		TotalTokens:      u.TotalTokenCount,
+		CacheReadTokens:  u.CachedContentTokenCount,
+		ThinkingTokens:   u.ThoughtsTokenCount,
+	}
+}
@@ -0,0 +1,184 @@
+package providers
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// GoogleCloudPlatformScope is the OAuth scope for Vertex AI data-plane calls.
+	GoogleCloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	googleDefaultTokenURI = "https://oauth2.googleapis.com/token"
+	googleJWTBearerGrant  = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	googleJWTLifetime     = time.Hour
+)
+
+// ServiceAccountKey is the subset of a Google service-account JSON key used for auth.
+type ServiceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// ParseServiceAccountKey parses a service-account JSON key file.
+func ParseServiceAccountKey(data []byte) (*ServiceAccountKey, error) {
+	var key ServiceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("vertex: parse service account key: %w", err)
+	}
+	if key.Type != "" && key.Type != "service_account" {
+		return nil, fmt.Errorf("vertex: unsupported credential type %q (want service_account)", key.Type)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("vertex: service account key missing client_email or private_key")
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = googleDefaultTokenURI
+	}
+	return &key, nil
+}
+
+// ServiceAccountTokenSource exchanges a self-signed RS256 JWT for a Google OAuth
+// access token (JWT bearer grant) and caches it until shortly before expiry.
+// Implements TokenSource. Thread-safe.
+type ServiceAccountTokenSource struct {
+	key      *ServiceAccountKey
+	signer   *rsa.PrivateKey
+	scope    string
+	client   *http.Client
+	nowFn    func() time.Time
+	tokenURL string
+
+	mu          sync.Mutex
+	cachedToken string
+	expiresAt   time.Time
+}
+
+// NewServiceAccountTokenSource creates a token source for the cloud-platform scope.
+func NewServiceAccountTokenSource(key *ServiceAccountKey) (*ServiceAccountTokenSource, error) {
+	signer, err := parseRSAPrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceAccountTokenSource{
+		key:      key,
+		signer:   signer,
+		scope:    GoogleCloudPlatformScope,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		nowFn:    time.Now,
+		tokenURL: key.TokenURI,
+	}, nil
+}
+
+// Token returns a cached access token, refreshing it when it is within
+// 5 minutes of expiry.
+func (s *ServiceAccountTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.nowFn()
+	if s.cachedToken != "" && now.Add(tokenRefreshSkew).Before(s.expiresAt) {
+		return s.cachedToken, nil
+	}
+
+	assertion, err := s.signJWT(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {googleJWTBearerGrant},
+		"assertion":  {assertion},
+	}
+	resp, err := s.client.Post(s.tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("vertex: token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		// invalid_grant / invalid_client come back as 400; surface as auth errors.
+		status := resp.StatusCode
+		if status == http.StatusBadRequest {
+			status = http.StatusUnauthorized
+		}
+		return "", &HTTPError{Status: status, Body: "vertex token: " + string(body)}
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return "", fmt.Errorf("vertex: decode token response: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("vertex: empty access token in response")
+	}
+
+	s.cachedToken = tok.AccessToken
+	s.expiresAt = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return s.cachedToken, nil
+}
+
+// signJWT builds the RS256-signed assertion for the JWT bearer grant.
+func (s *ServiceAccountTokenSource) signJWT(now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if s.key.PrivateKeyID != "" {
+		header["kid"] = s.key.PrivateKeyID
+	}
+	claims := map[string]any{
+		"iss":   s.key.ClientEmail,
+		"scope": s.scope,
+		"aud":   s.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(googleJWTLifetime).Unix(),
+	}
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+
+	sum := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.signer, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("vertex: sign jwt: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey decodes a PEM private key in PKCS#8 (Google's format) or PKCS#1.
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("vertex: private_key is not PEM encoded")
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rk, ok := k.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("vertex: private_key is not an RSA key")
+		}
+		return rk, nil
+	}
+	k, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("vertex: parse private_key: %w", err)
+	}
+	return k, nil
+}
@@ -0,0 +1,154 @@
+package providers
+
+import (
+	"strings"
+)
+
+// vertexSupportsThinking reports whether the model accepts thinkingConfig
+// (Gemini 2.5 and later).
+func vertexSupportsThinking(model string) bool {
+	m := strings.ToLower(model)
+	return strings.Contains(m, "gemini-2.5") || strings.Contains(m, "gemini-3")
+}
+
+// ModelSupportsThinking implements ModelThinkingCapable.
+func (p *VertexProvider) ModelSupportsThinking(model string) bool {
+	return vertexSupportsThinking(p.resolveModel(model))
+}
+
+// vertexThinkingBudget maps a thinking level to a Gemini thinkingBudget.
+func vertexThinkingBudget(level string) int {
+	switch level {
+	case "low":
+		return 1024
+	case "high":
+		return 24576
+	default:
+		return 8192
+	}
+}
+
+func (p *VertexProvider) buildRequestBody(model string, req ChatRequest) map[string]any {
+	// Gemini rejects responseMimeType alongside function declarations, so with
+	// tools the schema is described in the system prompt instead.
+	if req.ResponseFormat.wantsJSON() && len(req.Tools) > 0 {
+		req = StructuredOutputPrompt(req)
+	}
+
+	// Gemini requires thoughtSignature echoed back on function calls (2.5+, enforced
+	// on Gemini 3). Histories from other providers lack it, so fold those tool cycles
+	// into plain text exactly as the OpenAI-compat Gemini path does.
+	inputMessages := collapseToolCallsWithoutSig(req.Messages)
+	toolNameByID := buildToolNameIndex(inputMessages)
+
+	var system []vertexPart
+	var contents []vertexContent
+
+	// Gemini expects alternating user/model turns; consecutive same-role messages
+	// (e.g. parallel tool results) are merged into one turn.
+	appendTurn := func(role string, parts []vertexPart) {
+		if len(parts) == 0 {
+			return
+		}
+		if n := len(contents); n > 0 && contents[n-1].Role == role {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+			return
+		}
+		contents = append(contents, vertexContent{Role: role, Parts: parts})
+	}
+
+	for _, msg := range inputMessages {
+		switch msg.Role {
+		case "system":
+			// Implicit caching keys on the prefix; the boundary marker has no
+			// explicit counterpart here, so just drop it.
+			text := strings.TrimSpace(strings.Replace(msg.Content, CacheBoundaryMarker, "", 1))
+			if text != "" {
+				system = append(system, vertexPart{Text: text})
+			}
+
+		case "user":
+			var parts []vertexPart
+			for _, img := range msg.Images {
+				parts = append(parts, vertexPart{InlineData: &vertexInlineData{MimeType: img.MimeType, Data: img.Data}})
+			}
+			if msg.Content != "" {
+				parts = append(parts, vertexPart{Text: msg.Content})
+			}
+			appendTurn("user", parts)
+
+		case "assistant":
+			var parts []vertexPart
+			if msg.Content != "" {
+				parts = append(parts, vertexPart{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				args := tc.Arguments
+				if args == nil {
+					args = map[string]any{}
+				}
+				parts = append(parts, vertexPart{
+					FunctionCall:     &vertexFunctionCall{Name: tc.Name, Args: args},
+					ThoughtSignature: tc.Metadata["thought_signature"],
+				})
+			}
+			appendTurn("model", parts)
+
+		case "tool":
+			response := map[string]any{"content": msg.Content}
+			if msg.IsError {
+				response = map[string]any{"error": msg.Content}
+			}
+			appendTurn("user", []vertexPart{{FunctionResponse: &vertexFunctionResponse{
+				Name:     toolNameByID[msg.ToolCallID],
+				Response: response,
+			}}})
+		}
+	}
+
+	body := map[string]any{
+		"contents": contents,
+	}
+	if len(system) > 0 {
+		body["systemInstruction"] = vertexContent{Parts: system}
+	}
+
+	genConfig := map[string]any{}
+	if v, ok := req.Options[OptMaxTokens]; ok {
+		genConfig["maxOutputTokens"] = v
+	}
+	if v, ok := req.Options[OptTemperature]; ok {
+		genConfig["temperature"] = v
+	}
+
+	if len(req.Tools) > 0 {
+		decls := make([]map[string]any, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			decls = append(decls, map[string]any{
+				"name":        t.Function.Name,
+				"description": t.Function.Description,
+				"parameters":  CleanSchemaForProvider("gemini", t.Function.Parameters),
+			})
+		}
+		body["tools"] = []map[string]any{{"functionDeclarations": decls}}
+	}
+
+	if f := req.ResponseFormat; f.wantsJSON() {
+		genConfig["responseMimeType"] = "application/json"
+		if f.hasSchema() {
+			genConfig["responseSchema"] = CleanSchemaForProvider("gemini", f.Schema)
+		}
+	}
+
+	if level, ok := req.Options[OptThinkingLevel].(string); ok && level != "" && level != "off" && vertexSupportsThinking(model) {
+		genConfig["thinkingConfig"] = map[string]any{
+			"includeThoughts": true,
+			"thinkingBudget":  vertexThinkingBudget(level),
+		}
+	}
+	if len(genConfig) > 0 {
+		body["generationConfig"] = genConfig
+	}
+
+	return body
+}
@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func (p *VertexProvider) ChatStream(ctx context.Context, req ChatRequest, onChunk func(StreamChunk)) (*ChatResponse, error) {
+	model := p.resolveModel(req.Model)
+	stripThinking, _ := req.Options[OptStripThinking].(bool)
+	body := p.buildRequestBody(model, req)
+
+	// Retry only the connection phase; once streaming starts, no retry.
+	respBody, err := RetryDo(ctx, p.retryConfig, func() (io.ReadCloser, error) {
+		return p.doRequest(ctx, model, "streamGenerateContent", body)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cb := NewCtxBody(ctx, respBody)
+	defer cb.Close()
+
+	result := &ChatResponse{FinishReason: "stop"}
+	finishReason := ""
+	blocked := false
+
+	// Each SSE event is a complete GenerateContentResponse carrying the next parts;
+	// function calls arrive whole, never split across events.
+	sse := NewSSEScanner(cb)
+	for sse.Next() {
+		var chunk vertexResponse
+		if err := json.Unmarshal([]byte(sse.Data()), &chunk); err != nil {
+			continue
+		}
+		if chunk.UsageMetadata != nil {
+			result.Usage = chunk.UsageMetadata.toUsage()
+		}
+		if len(chunk.Candidates) == 0 {
+			if chunk.PromptFeedback != nil && chunk.PromptFeedback.BlockReason != "" {
+				blocked = true
+			}
+			continue
+		}
+		cand := chunk.Candidates[0]
+		for _, part := range cand.Content.Parts {
+			if part.Thought && stripThinking {
+				continue
+			}
+			text, thinking := appendVertexPart(result, part)
+			if onChunk == nil {
+				continue
+			}
+			if text != "" {
+				onChunk(StreamChunk{Content: text})
+			}
+			if thinking != "" {
+				onChunk(StreamChunk{Thinking: thinking})
+			}
+		}
+		if cand.FinishReason != "" {
+			finishReason = cand.FinishReason
+		}
+	}
+	if err := sse.Err(); err != nil {
+		return nil, fmt.Errorf("vertex stream read error: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	switch {
+	case blocked:
+		result.FinishReason = "content_filter"
+	case finishReason != "":
+		result.FinishReason = vertexFinishReason(finishReason)
+	}
+	if len(result.ToolCalls) > 0 && result.FinishReason == "stop" {
+		result.FinishReason = "tool_calls"
+	}
+
+	if onChunk != nil {
+		onChunk(StreamChunk{Done: true})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package providers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newVertexTestKey returns a service-account JSON key whose token_uri points at a
+// local token endpoint that verifies the JWT assertion and issues "tok-N".
+func newVertexTestKey(t *testing.T) (keyJSON string, tokenCalls *atomic.Int32) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	tokenCalls = new(atomic.Int32)
+	var tokenURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != googleJWTBearerGrant {
+			t.Errorf("grant_type = %q", r.Form.Get("grant_type"))
+		}
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion is not a JWT: %q", r.Form.Get("assertion"))
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+			t.Errorf("jwt signature: %v", err)
+		}
+		var claims map[string]any
+		raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		_ = json.Unmarshal(raw, &claims)
+		if claims["iss"] != "sa@proj.iam.gserviceaccount.com" || claims["aud"] != tokenURL || claims["scope"] != GoogleCloudPlatformScope {
+			t.Errorf("unexpected claims: %v", claims)
+		}
+		n := tokenCalls.Add(1)
+		fmt.Fprintf(w, `{"access_token":"tok-%d","expires_in":3600,"token_type":"Bearer"}`, n)
+	}))
+	t.Cleanup(srv.Close)
+	tokenURL = srv.URL
+
+	b, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "proj",
+		"private_key_id": "kid1",
+		"private_key":    pemKey,
+		"client_email":   "sa@proj.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	return string(b), tokenCalls
+}
+
+func TestVertexChatToolCallRoundTrip(t *testing.T) {
+	keyJSON, tokenCalls := newVertexTestKey(t)
+
+	var gotPath, gotAuth string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[
+			{"text":"let me think","thought":true},
+			{"functionCall":{"name":"get_weather","args":{"city":"Paris"}},"thoughtSignature":"sig-abc"}
+		]},"finishReason":"STOP"}],
+		"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"thoughtsTokenCount":7,"totalTokenCount":22}}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewVertexProviderFromKey("vertex", "", "europe-west4", srv.URL, keyJSON)
+	if err != nil {
+		t.Fatalf("NewVertexProviderFromKey: %v", err)
+	}
+
+	req := ChatRequest{
+		Model:    "gemini-2.5-pro",
+		Messages: []Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: "weather?"}},
+		Tools: []ToolDefinition{{Type: "function", Function: ToolFunctionSchema{
+			Name: "get_weather", Parameters: map[string]any{"type": "object", "properties": map[string]any{"city": map[string]any{"type": "string"}}},
+		}}},
+		Options: map[string]any{OptThinkingLevel: "low"},
+	}
+	resp, err := p.Chat(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if want := "/v1/projects/proj/locations/europe-west4/publishers/google/models/gemini-2.5-pro:generateContent"; gotPath != want {
+		t.Errorf("path = %q, want %q", gotPath, want)
+	}
+	if gotAuth != "Bearer tok-1" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if _, ok := gotBody["systemInstruction"]; !ok {
+		t.Error("missing systemInstruction")
+	}
+	gen, _ := gotBody["generationConfig"].(map[string]any)
+	if tc, _ := gen["thinkingConfig"].(map[string]any); tc["thinkingBudget"] != float64(1024) {
+		t.Errorf("thinkingConfig = %v", gen["thinkingConfig"])
+	}
+
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("finish=%q toolCalls=%v", resp.FinishReason, resp.ToolCalls)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.Name != "get_weather" || tc.Arguments["city"] != "Paris" || tc.ID == "" {
+		t.Errorf("tool call = %+v", tc)
+	}
+	if tc.Metadata["thought_signature"] != "sig-abc" {
+		t.Errorf("thought_signature = %q", tc.Metadata["thought_signature"])
+	}
+	if resp.Thinking != "let me think" {
+		t.Errorf("thinking = %q", resp.Thinking)
+	}
+	if resp.Usage.CompletionTokens != 12 || resp.Usage.ThinkingTokens != 7 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	// Second turn: the signature is echoed on the functionCall part, the tool
+	// result is sent as a functionResponse, and the cached token is reused.
+	req.Messages = append(req.Messages,
+		Message{Role: "assistant", ToolCalls: resp.ToolCalls},
+		Message{Role: "tool", ToolCallID: tc.ID, Content: "sunny"},
+	)
+	if _, err := p.Chat(context.Background(), req); err != nil {
+		t.Fatalf("Chat (turn 2): %v", err)
+	}
+	if tokenCalls.Load() != 1 {
+		t.Errorf("token endpoint called %d times, want 1", tokenCalls.Load())
+	}
+	contents, _ := gotBody["contents"].([]any)
+	if len(contents) != 3 {
+		t.Fatalf("contents = %v", contents)
+	}
+	model := contents[1].(map[string]any)
+	part := model["parts"].([]any)[0].(map[string]any)
+	if model["role"] != "model" || part["thoughtSignature"] != "sig-abc" {
+		t.Errorf("model turn = %v", model)
+	}
+	toolTurn := contents[2].(map[string]any)
+	fr := toolTurn["parts"].([]any)[0].(map[string]any)["functionResponse"].(map[string]any)
+	if toolTurn["role"] != "user" || fr["name"] != "get_weather" {
+		t.Errorf("tool turn = %v", toolTurn)
+	}
+}
+
+func TestVertexChatStream(t *testing.T) {
+	keyJSON, _ := newVertexTestKey(t)
+
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range []string{
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"hmm","thought":true}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":" world"}]},"finishReason":"MAX_TOKENS"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", ev)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewVertexProviderFromKey("vertex", "other-proj", "global", srv.URL, keyJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content, thinking strings.Builder
+	done := false
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}}, func(c StreamChunk) {
+		content.WriteString(c.Content)
+		thinking.WriteString(c.Thinking)
+		done = done || c.Done
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if want := "/v1/projects/other-proj/locations/global/publishers/google/models/gemini-2.5-flash:streamGenerateContent"; gotPath != want || gotQuery != "alt=sse" {
+		t.Errorf("url = %s?%s", gotPath, gotQuery)
+	}
+	if resp.Content != "Hello world" || content.String() != "Hello world" {
+		t.Errorf("content = %q / streamed %q", resp.Content, content.String())
+	}
+	if resp.Thinking != "hmm" || thinking.String() != "hmm" {
+		t.Errorf("thinking = %q / streamed %q", resp.Thinking, thinking.String())
+	}
+	if resp.FinishReason != "length" || !done {
+		t.Errorf("finish = %q, done = %v", resp.FinishReason, done)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 5 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestVertexCollapsesToolCallsWithoutSignature(t *testing.T) {
+	p := NewVertexProvider("proj", "", nil)
+	body := p.buildRequestBody("gemini-2.5-flash", ChatRequest{Messages: []Message{
+		{Role: "user", Content: "q"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "search", Arguments: map[string]any{}}}},
+		{Role: "tool", ToolCallID: "c1", Content: "result text"},
+	}})
+	contents := body["contents"].([]vertexContent)
+	for _, c := range contents {
+		for _, part := range c.Parts {
+			if part.FunctionCall != nil || part.FunctionResponse != nil {
+				t.Fatalf("unsigned tool cycle should be collapsed, got %+v", contents)
+			}
+		}
+	}
+	// user "q" and the folded tool output merge into one user turn.
+	if len(contents) != 1 || len(contents[0].Parts) != 2 || contents[0].Parts[1].Text != "result text" {
+		t.Errorf("contents = %+v", contents)
+	}
+}
+
+func TestVertexEndpoint(t *testing.T) {
+	if got := VertexEndpoint("us-central1"); got != "https://us-central1-aiplatform.googleapis.com" {
+		t.Errorf("regional = %q", got)
+	}
+	if got := VertexEndpoint("global"); got != "https://aiplatform.googleapis.com" {
+		t.Errorf("global = %q", got)
+	}
+}
+
+func TestParseServiceAccountKeyRejectsOtherTypes(t *testing.T) {
+	if _, err := ParseServiceAccountKey([]byte(`{"type":"authorized_user","client_email":"x","private_key":"y"}`)); err == nil {
+		t.Error("expected error for authorized_user credentials")
+	}
+	if _, err := NewVertexProviderFromKey("v", "", "", "", "not json"); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
@@ -0,0 +1,174 @@
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// kindDefaults holds the endpoint and model used when Config leaves them empty.
+var kindDefaults = map[string]struct{ apiBase, model string }{
+	KindCohere: {"https://api.cohere.com/v2", "rerank-v3.5"},
+	KindJina:   {"https://api.jina.ai/v1", "jina-reranker-v2-base-multilingual"},
+	KindVoyage: {"https://api.voyageai.com/v1", "rerank-2"},
+	KindCompat: {"", ""},
+}
+
+// HTTPReranker calls a hosted or self-hosted rerank API. Cohere, Jina and
+// Cohere-style servers share one wire format; Voyage names its fields
+// top_k/data. Compat requests also carry TEI's "texts" field and responses
+// may be a bare TEI array.
+type HTTPReranker struct {
+	kind    string
+	apiKey  string
+	apiBase string
+	model   string
+	client  *http.Client
+}
+
+// NewHTTPReranker creates a reranker of the given kind. Empty apiBase and
+// model use the kind's defaults; openai_compat requires apiBase.
+func NewHTTPReranker(kind, apiKey, apiBase, model string) (*HTTPReranker, error) {
+	def, ok := kindDefaults[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown rerank kind %q", kind)
+	}
+	if apiBase == "" {
+		apiBase = def.apiBase
+	}
+	if apiBase == "" {
+		return nil, fmt.Errorf("rerank kind %s requires api_base", kind)
+	}
+	if model == "" {
+		model = def.model
+	}
+	return &HTTPReranker{
+		kind:    kind,
+		apiKey:  apiKey,
+		apiBase: strings.TrimRight(apiBase, "/"),
+		model:   model,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (r *HTTPReranker) Name() string  { return r.kind }
+func (r *HTTPReranker) Model() string { return r.model }
+
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, docs []string, topN int) ([]Result, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	if topN <= 0 || topN > len(docs) {
+		topN = len(docs)
+	}
+
+	body := map[string]any{"query": query, "documents": docs}
+	if r.model != "" {
+		body["model"] = r.model
+	}
+	switch r.kind {
+	case KindVoyage:
+		body["top_k"] = topN
+	case KindCompat:
+		body["top_n"] = topN
+		body["texts"] = docs
+	default:
+		body["top_n"] = topN
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.apiBase+"/rerank", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read rerank response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank %s: HTTP %d: %s", r.kind, resp.StatusCode, truncate(string(raw), 300))
+	}
+	results, err := parseRerankResponse(raw, len(docs))
+	if err != nil {
+		return nil, fmt.Errorf("decode rerank response: %w", err)
+	}
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results, nil
+}
+
+type rerankItem struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"` // TEI
+}
+
+// parseRerankResponse accepts {"results":[...]} (Cohere, Jina, vLLM),
+// {"data":[...]} (Voyage) and a bare array (TEI). Results are sorted by
+// descending score; out-of-range indexes are dropped.
+func parseRerankResponse(raw []byte, n int) ([]Result, error) {
+	var items []rerankItem
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+	} else {
+		var env struct {
+			Results []rerankItem `json:"results"`
+			Data    []rerankItem `json:"data"`
+		}
+		if err := json.Unmarshal(trimmed, &env); err != nil {
+			return nil, err
+		}
+		items = env.Results
+		if len(items) == 0 {
+			items = env.Data
+		}
+	}
+
+	results := make([]Result, 0, len(items))
+	for _, it := range items {
+		if it.Index < 0 || it.Index >= n {
+			continue
+		}
+		res := Result{Index: it.Index}
+		switch {
+		case it.RelevanceScore != nil:
+			res.Score = *it.RelevanceScore
+		case it.Score != nil:
+			res.Score = *it.Score
+		}
+		results = append(results, res)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,86 @@
+// Package rerank reorders hybrid search candidates with a cross-encoder
+// reranking model (Cohere, Jina, Voyage, or any server exposing a Cohere-style
+// /rerank endpoint such as a local TEI, vLLM or Infinity instance).
+//
+// Retrieval stays with the stores: callers fetch Candidates() results, then
+// Rerank reorders them by query relevance and trims to the requested count.
+// Reranking is configured per tenant in system_configs and fails open — on
+// error or when the latency budget runs out the retrieval order is kept.
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Reranker scores documents against a query.
+type Reranker interface {
+	Name() string
+	Model() string
+	// Rerank returns up to topN results ordered by descending relevance.
+	// topN <= 0 returns all documents.
+	Rerank(ctx context.Context, query string, docs []string, topN int) ([]Result, error)
+}
+
+// Result is the relevance of one input document.
+type Result struct {
+	Index int     // position in the docs slice passed to Rerank
+	Score float64 // provider relevance score, higher is better
+}
+
+// SystemConfigKey is the system_configs key holding the tenant's Config as
+// JSON. Tenants without their own value inherit the master tenant's.
+const SystemConfigKey = "rerank"
+
+// Reranker kinds (Config.Kind).
+const (
+	KindCohere = "cohere"
+	KindJina   = "jina"
+	KindVoyage = "voyage"
+	KindCompat = "openai_compat" // Cohere-style /rerank servers (TEI, vLLM, Infinity)
+)
+
+// Defaults applied by ParseConfig.
+const (
+	DefaultTopK      = 20
+	DefaultTimeoutMS = 1500
+)
+
+// Config is a tenant's reranking setup.
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Kind    string `json:"kind,omitempty"`     // cohere, jina, voyage, openai_compat; default from the provider type
+	Model   string `json:"model,omitempty"`    // default per kind
+	APIBase string `json:"api_base,omitempty"` // overrides the kind's default endpoint (required for openai_compat)
+	// Provider names the llm_providers row whose API key is used. Optional for
+	// local servers without auth.
+	Provider string `json:"provider,omitempty"`
+	// TopK is how many retrieved candidates are sent to the reranker.
+	TopK int `json:"top_k,omitempty"`
+	// TimeoutMS is the latency budget per rerank call; on expiry the
+	// retrieval order is used.
+	TimeoutMS int `json:"timeout_ms,omitempty"`
+	// MinScore drops reranked results below this relevance (0 = keep all).
+	MinScore float64 `json:"min_score,omitempty"`
+}
+
+// ParseConfig parses a Config from JSON, returning nil when absent,
+// malformed or disabled.
+func ParseConfig(raw []byte) *Config {
+	if len(raw) <= 2 {
+		return nil
+	}
+	var cfg Config
+	if json.Unmarshal(raw, &cfg) != nil || !cfg.Enabled {
+		return nil
+	}
+	cfg.Kind = strings.ToLower(strings.TrimSpace(cfg.Kind))
+	if cfg.TopK <= 0 {
+		cfg.TopK = DefaultTopK
+	}
+	if cfg.TimeoutMS <= 0 {
+		cfg.TimeoutMS = DefaultTimeoutMS
+	}
+	return &cfg
+}
@@ -0,0 +1,149 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPReranker_WireFormats(t *testing.T) {
+	tests := []struct {
+		kind     string
+		response string
+		topField string
+	}{
+		{KindCohere, `{"results":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.2}]}`, "top_n"},
+		{KindJina, `{"results":[{"index":0,"relevance_score":0.2},{"index":1,"relevance_score":0.9}]}`, "top_n"},
+		{KindVoyage, `{"data":[{"index":1,"relevance_score":0.9},{"index":0,"relevance_score":0.2}]}`, "top_k"},
+		{KindCompat, `[{"index":1,"score":0.9},{"index":0,"score":0.2},{"index":7,"score":1}]`, "top_n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			var body map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/rerank" || r.Header.Get("Authorization") != "Bearer k" {
+					t.Errorf("request %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			r, err := NewHTTPReranker(tt.kind, "k", srv.URL, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := r.Rerank(context.Background(), "q", []string{"a", "b"}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) != 2 || res[0].Index != 1 || res[0].Score != 0.9 {
+				t.Errorf("results = %+v", res)
+			}
+			if body[tt.topField] != float64(2) || body["query"] != "q" {
+				t.Errorf("request body = %v", body)
+			}
+			if _, ok := body["texts"]; ok != (tt.kind == KindCompat) {
+				t.Errorf("texts field present=%v for %s", ok, tt.kind)
+			}
+		})
+	}
+}
+
+func TestNewHTTPReranker_CompatRequiresBase(t *testing.T) {
+	if _, err := NewHTTPReranker(KindCompat, "", "", ""); err == nil {
+		t.Error("expected error without api_base")
+	}
+	if _, err := NewHTTPReranker("bogus", "", "http://x", ""); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
+
+type staticConfigs struct{ raw string }
+
+func (c staticConfigs) Get(context.Context, string) (string, error)     { return c.raw, nil }
+func (c staticConfigs) Set(context.Context, string, string) error       { return nil }
+func (c staticConfigs) Delete(context.Context, string) error            { return nil }
+func (c staticConfigs) List(context.Context) (map[string]string, error) { return nil, nil }
+
+type fakeReranker struct {
+	results []Result
+	err     error
+	delay   time.Duration
+}
+
+func (f *fakeReranker) Name() string  { return "fake" }
+func (f *fakeReranker) Model() string { return "m" }
+func (f *fakeReranker) Rerank(ctx context.Context, _ string, _ []string, _ int) ([]Result, error) {
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return f.results, f.err
+}
+
+func newTestService(cfg string, fr *fakeReranker) *Service {
+	s := NewService(staticConfigs{raw: cfg}, nil)
+	s.newReranker = func(kind, _, _, _ string) (Reranker, error) {
+		if kind != KindCompat {
+			return nil, errors.New("unexpected kind " + kind)
+		}
+		return fr, nil
+	}
+	return s
+}
+
+type item struct {
+	text  string
+	score float64
+}
+
+func rerankItems(s *Service, items []item, limit int) []item {
+	return Rerank(context.Background(), s, "q", items, limit,
+		func(it item) string { return it.text },
+		func(it *item, score float64) { it.score = score })
+}
+
+func TestRerank_ReordersAndTrims(t *testing.T) {
+	fr := &fakeReranker{results: []Result{{Index: 2, Score: 0.95}, {Index: 0, Score: 0.5}, {Index: 1, Score: 0.01}}}
+	s := newTestService(`{"enabled":true,"api_base":"http://tei","top_k":30,"min_score":0.1}`, fr)
+
+	if n := s.Candidates(context.Background(), 5); n != 30 {
+		t.Errorf("Candidates = %d, want 30", n)
+	}
+	got := rerankItems(s, []item{{"a", 1}, {"b", 0.9}, {"c", 0.1}}, 1)
+	if len(got) != 1 || got[0].text != "c" || got[0].score != 0.95 {
+		t.Errorf("reranked = %+v", got)
+	}
+	got = rerankItems(s, []item{{"a", 1}, {"b", 0.9}, {"c", 0.1}}, 0)
+	if len(got) != 2 {
+		t.Errorf("min_score should drop the last item: %+v", got)
+	}
+}
+
+func TestRerank_FailsOpen(t *testing.T) {
+	items := []item{{"a", 1}, {"b", 0.5}}
+	cases := map[string]*Service{
+		"nil service": nil,
+		"disabled":    newTestService(`{"enabled":false,"api_base":"http://tei"}`, &fakeReranker{}),
+		"error":       newTestService(`{"enabled":true,"api_base":"http://tei"}`, &fakeReranker{err: errors.New("boom")}),
+		"over budget": newTestService(`{"enabled":true,"api_base":"http://tei","timeout_ms":10}`,
+			&fakeReranker{delay: time.Second, results: []Result{{Index: 1, Score: 1}}}),
+	}
+	for name, s := range cases {
+		got := rerankItems(s, items, 1)
+		if len(got) != 2 || got[0].text != "a" || got[0].score != 1 {
+			t.Errorf("%s: got %+v, want original order", name, got)
+		}
+	}
+	for _, name := range []string{"nil service", "disabled"} {
+		if n := cases[name].Candidates(context.Background(), 5); n != 5 {
+			t.Errorf("%s: Candidates = %d, want 5", name, n)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package rerank
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nextlevelbuilder/goclaw/internal/cache"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// configTTL bounds how long a tenant's resolved reranker is reused after its
+// system_configs entry changes.
+const configTTL = time.Minute
+
+// tenantReranker is a tenant's resolved setup; r is nil when reranking is off.
+type tenantReranker struct {
+	cfg *Config
+	r   Reranker
+}
+
+// Service resolves the tenant's reranker from system_configs and applies it.
+// A nil *Service is valid and never reranks.
+type Service struct {
+	sysConfigs store.SystemConfigStore
+	providers  store.ProviderStore // nil = configs without a provider only
+	cache      *cache.InMemoryCache[*tenantReranker]
+
+	// newReranker builds a Reranker; replaced in tests.
+	newReranker func(kind, apiKey, apiBase, model string) (Reranker, error)
+}
+
+// NewService creates a Service. providerStore resolves Config.Provider API keys.
+func NewService(sysConfigs store.SystemConfigStore, providerStore store.ProviderStore) *Service {
+	return &Service{
+		sysConfigs: sysConfigs,
+		providers:  providerStore,
+		cache:      cache.NewInMemoryCache[*tenantReranker](),
+		newReranker: func(kind, apiKey, apiBase, model string) (Reranker, error) {
+			return NewHTTPReranker(kind, apiKey, apiBase, model)
+		},
+	}
+}
+
+// Candidates returns how many results a search should retrieve before
+// reranking: n when reranking is off for the tenant, otherwise at least the
+// tenant's top_k.
+func (s *Service) Candidates(ctx context.Context, n int) int {
+	if s == nil {
+		return n
+	}
+	tr := s.resolve(ctx)
+	if tr.r == nil {
+		return n
+	}
+	return max(n, tr.cfg.TopK)
+}
+
+// Rerank reorders items by relevance to query and trims them to limit
+// (limit <= 0 keeps all). text extracts the document text sent to the
+// reranker; setScore (optional) replaces an item's score with the reranker's.
+// Items are returned unchanged when reranking is off, fails, or exceeds the
+// tenant's latency budget.
+func Rerank[T any](ctx context.Context, s *Service, query string, items []T, limit int,
+	text func(T) string, setScore func(*T, float64)) []T {
+	if s == nil || len(items) == 0 || query == "" {
+		return items
+	}
+	tr := s.resolve(ctx)
+	if tr.r == nil {
+		return items
+	}
+
+	docs := make([]string, len(items))
+	for i, it := range items {
+		docs[i] = text(it)
+	}
+	rctx, cancel := context.WithTimeout(ctx, time.Duration(tr.cfg.TimeoutMS)*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results, err := tr.r.Rerank(rctx, query, docs, 0)
+	if err != nil {
+		slog.Warn("rerank.failed", "provider", tr.r.Name(), "model", tr.r.Model(),
+			"candidates", len(docs), "elapsed_ms", time.Since(start).Milliseconds(), "error", err)
+		return items
+	}
+	slog.Debug("rerank.applied", "provider", tr.r.Name(), "candidates", len(docs),
+		"elapsed_ms", time.Since(start).Milliseconds())
+
+	out := make([]T, 0, len(results))
+	for _, res := range results {
+		if tr.cfg.MinScore > 0 && res.Score < tr.cfg.MinScore {
+			continue
+		}
+		it := items[res.Index]
+		if setScore != nil {
+			setScore(&it, res.Score)
+		}
+		out = append(out, it)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// resolve returns the tenant's reranker, cached for configTTL.
+func (s *Service) resolve(ctx context.Context) *tenantReranker {
+	key := store.TenantIDFromContext(ctx).String()
+	if tr, ok := s.cache.Get(ctx, key); ok {
+		return tr
+	}
+	tr := s.build(ctx)
+	s.cache.Set(ctx, key, tr, configTTL)
+	return tr
+}
+
+func (s *Service) build(ctx context.Context) *tenantReranker {
+	off := &tenantReranker{}
+	if s.sysConfigs == nil {
+		return off
+	}
+	raw, err := s.sysConfigs.Get(ctx, SystemConfigKey)
+	if err != nil || raw == "" {
+		return off
+	}
+	cfg := ParseConfig([]byte(raw))
+	if cfg == nil {
+		return off
+	}
+
+	kind, apiKey := cfg.Kind, ""
+	if cfg.Provider != "" {
+		if s.providers == nil {
+			return off
+		}
+		p, err := s.providers.GetProviderByName(ctx, cfg.Provider)
+		if err != nil || !p.Enabled {
+			slog.Warn("rerank: provider unavailable, reranking disabled", "provider", cfg.Provider, "error", err)
+			return off
+		}
+		apiKey = p.APIKey
+		if kind == "" && p.ProviderType == store.ProviderCohere {
+			kind = KindCohere
+		}
+	}
+	if kind == "" && cfg.APIBase != "" {
+		kind = KindCompat
+	}
+
+	r, err := s.newReranker(kind, apiKey, cfg.APIBase, cfg.Model)
+	if err != nil {
+		slog.Warn("rerank: invalid config, reranking disabled", "error", err)
+		return off
+	}
+	return &tenantReranker{cfg: cfg, r: r}
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// ChatCommandsSystemConfigKey is the system_configs key holding the tenant's
+// custom chat commands as a JSON array of ChatCommand.
+const ChatCommandsSystemConfigKey = "chat_commands"
+
+// chatCommandName matches names every platform accepts as a slash command
+// (Telegram allows at most 32 lowercase letters, digits and underscores).
+var chatCommandName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// ChatCommand is a tenant-defined slash command that expands into an agent
+// prompt. "{args}" in Prompt is replaced by the text typed after the command.
+type ChatCommand struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Prompt      string `json:"prompt"`
+}
+
+// Expand returns the prompt for an invocation with the given arguments.
+// Without an "{args}" placeholder, arguments are appended on a new paragraph.
+func (c ChatCommand) Expand(args string) string {
+	args = strings.TrimSpace(args)
+	if strings.Contains(c.Prompt, "{args}") {
+		return strings.TrimSpace(strings.ReplaceAll(c.Prompt, "{args}", args))
+	}
+	if args == "" {
+		return c.Prompt
+	}
+	return c.Prompt + "\n\n" + args
+}
+
+// ParseChatCommands parses the tenant's custom commands from JSON. Entries
+// with an invalid name or an empty prompt are skipped; a leading slash and
+// upper case in names are tolerated. Returns nil when absent or malformed.
+func ParseChatCommands(raw string) []ChatCommand {
+	if len(raw) <= 2 {
+		return nil
+	}
+	var list []ChatCommand
+	if json.Unmarshal([]byte(raw), &list) != nil {
+		return nil
+	}
+	out := list[:0]
+	for _, c := range list {
+		c.Name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(c.Name), "/"))
+		if !chatCommandName.MatchString(c.Name) || strings.TrimSpace(c.Prompt) == "" {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
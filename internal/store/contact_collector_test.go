@@ -73,6 +73,18 @@ func (m *mockContactStore) MergeContacts(_ context.Context, _ []uuid.UUID, _ uui
 	return nil
 }
 func (m *mockContactStore) UnmergeContacts(_ context.Context, _ []uuid.UUID) error { return nil }
+func (m *mockContactStore) GetContactTags(_ context.Context, _, _ string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockContactStore) SetContactTags(_ context.Context, _ uuid.UUID, _ []string) error {
+	return nil
+}
+func (m *mockContactStore) GetContactRoute(_ context.Context, _ ContactRouteKey) (*ContactRoute, error) {
+	return nil, nil
+}
+func (m *mockContactStore) SetContactRoute(_ context.Context, _ ContactRouteKey, _ *ContactRoute) error {
+	return nil
+}
 func (m *mockContactStore) GetContactsByMergedID(_ context.Context, _ uuid.UUID) ([]ChannelContact, error) {
 	return nil, nil
 }
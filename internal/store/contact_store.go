@@ -76,4 +76,41 @@ type ContactStore interface {
 	// the contact has been merged, returns the linked tenant_user's user_id.
 	// Returns ("", nil) when the contact is not found or not merged.
 	ResolveTenantUserID(ctx context.Context, channelType, senderID string) (string, error)
+
+	// GetContactTags returns the tags of the base contact (channelType, senderID),
+	// used by channel routing rules. Returns (nil, nil) when the contact is unknown.
+	// Tenant-scoped via context.
+	GetContactTags(ctx context.Context, channelType, senderID string) ([]string, error)
+
+	// SetContactTags replaces a contact's tags (stored in metadata.tags).
+	// Returns sql.ErrNoRows when the contact does not exist. Tenant-scoped via context.
+	SetContactTags(ctx context.Context, id uuid.UUID, tags []string) error
+
+	// GetContactRoute returns the agent a channel instance last routed the
+	// conversation contact to (stored in metadata.routing), or nil when the
+	// conversation has no route. Tenant-scoped via context.
+	GetContactRoute(ctx context.Context, key ContactRouteKey) (*ContactRoute, error)
+
+	// SetContactRoute stores the routed agent on the conversation contact,
+	// creating the contact row when it does not exist yet. A nil route clears
+	// it. Tenant-scoped via context.
+	SetContactRoute(ctx context.Context, key ContactRouteKey, route *ContactRoute) error
+}
+
+// ContactRouteKey identifies the contact row of a conversation (a direct
+// chat's sender, a group, or a group topic/thread) and the channel instance
+// whose agent routing is stored on it.
+type ContactRouteKey struct {
+	ChannelType     string
+	ChannelInstance string
+	SenderID        string // sender for direct chats, chat ID for groups
+	ThreadID        string // topic or thread; "" for the base contact
+	PeerKind        string // used when the row is created
+	ContactType     string // used when the row is created: "user", "group" or "topic"
+}
+
+// ContactRoute is a sticky agent routing decision for a conversation.
+type ContactRoute struct {
+	Agent    string    `json:"agent"`
+	RoutedAt time.Time `json:"at"`
 }
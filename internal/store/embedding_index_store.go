@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// System config keys (master tenant) for embedding model tracking.
+const (
+	// EmbeddingActiveModelKey holds the EmbeddingModelRecord that every stored
+	// vector is expected to match. Updated when a re-embedding run completes.
+	EmbeddingActiveModelKey = "embedding.active_model"
+	// EmbeddingMigrationKey holds the persisted EmbeddingMigrationState.
+	EmbeddingMigrationKey = "embedding.migration"
+)
+
+// Embedding migration states.
+const (
+	EmbeddingMigrationPending   = "pending"   // stored vectors use another model; awaiting a run
+	EmbeddingMigrationRunning   = "running"   // re-embedding in progress (resumed after restart)
+	EmbeddingMigrationCompleted = "completed" // all vectors use the active model
+	EmbeddingMigrationFailed    = "failed"    // stopped on error; can be restarted
+)
+
+// EmbeddingModelTag identifies the model that produced a vector, as stored in
+// the embedding_model column: "model@dims".
+func EmbeddingModelTag(model string, dims int) string {
+	return fmt.Sprintf("%s@%d", model, dims)
+}
+
+// EmbeddingModelRecord is the embedding model stored vectors were produced by.
+type EmbeddingModelRecord struct {
+	Provider string `json:"provider" db:"-"`
+	Model    string `json:"model" db:"-"`
+	Dims     int    `json:"dims" db:"-"`
+}
+
+// Tag returns the record's embedding_model tag.
+func (r EmbeddingModelRecord) Tag() string { return EmbeddingModelTag(r.Model, r.Dims) }
+
+// EmbeddingIndexStats counts one table's vectors against a model tag.
+type EmbeddingIndexStats struct {
+	Table    string `json:"table" db:"-"`
+	Embedded int    `json:"embedded" db:"-"` // rows with a vector
+	Current  int    `json:"current" db:"-"`  // rows embedded with the target model
+	Stale    int    `json:"stale" db:"-"`    // rows embedded with another model
+}
+
+// EmbeddingMigrationState is the persisted progress of a re-embedding run.
+// Cursor is the last re-embedded row ID in Table, so a restarted run resumes
+// where it stopped instead of rescanning migrated rows.
+type EmbeddingMigrationState struct {
+	State     string                `json:"state" db:"-"`
+	From      string                `json:"from,omitempty" db:"-"` // previous model tag
+	To        string                `json:"to" db:"-"`             // target model tag
+	Tables    []EmbeddingIndexStats `json:"tables,omitempty" db:"-"`
+	Table     string                `json:"table,omitempty" db:"-"`
+	Cursor    string                `json:"cursor,omitempty" db:"-"`
+	Done      int                   `json:"done" db:"-"`
+	Total     int                   `json:"total" db:"-"`
+	Error     string                `json:"error,omitempty" db:"-"`
+	StartedAt int64                 `json:"started_at,omitempty" db:"-"`
+	UpdatedAt int64                 `json:"updated_at,omitempty" db:"-"`
+}
+
+// EmbeddingIndexStore re-indexes the vector columns of every
+// EmbeddingProvider-backed store. Only Postgres stores vectors.
+type EmbeddingIndexStore interface {
+	// Tables lists the indexed tables in re-embedding order.
+	Tables() []string
+	// Stats counts each table's vectors against tag.
+	Stats(ctx context.Context, tag string) ([]EmbeddingIndexStats, error)
+	// AdoptUntagged tags vectors written before model tracking existed.
+	AdoptUntagged(ctx context.Context, tag string) (int, error)
+	// ReembedBatch re-embeds up to limit rows of table with IDs after cursor
+	// whose vectors were not produced by p's model. Returns the number of rows
+	// processed and the new cursor ("" when the table is exhausted).
+	ReembedBatch(ctx context.Context, table, cursor string, p EmbeddingProvider, limit int) (int, string, error)
+}
+
+// MigratingEmbeddingProvider embeds with the active model while vectors from a
+// previous model are still stored. Stores that see it search both models
+// (dual-read) until Finish is called.
+type MigratingEmbeddingProvider struct {
+	EmbeddingProvider
+	previous    EmbeddingProvider // nil when the previous model cannot be built
+	previousTag string
+	finished    atomic.Bool
+}
+
+// NewMigratingEmbeddingProvider wraps current for a migration away from the
+// model tagged previousTag. previous may be nil.
+func NewMigratingEmbeddingProvider(current, previous EmbeddingProvider, previousTag string) *MigratingEmbeddingProvider {
+	return &MigratingEmbeddingProvider{EmbeddingProvider: current, previous: previous, previousTag: previousTag}
+}
+
+// Migrating reports whether vectors from the previous model may still exist.
+func (p *MigratingEmbeddingProvider) Migrating() bool { return !p.finished.Load() }
+
+// Previous returns the previous model's provider and tag, or nil once
+// finished or when it is unavailable.
+func (p *MigratingEmbeddingProvider) Previous() (EmbeddingProvider, string) {
+	if p.finished.Load() {
+		return nil, ""
+	}
+	return p.previous, p.previousTag
+}
+
+// Finish ends the dual-read period.
+func (p *MigratingEmbeddingProvider) Finish() { p.finished.Store(true) }
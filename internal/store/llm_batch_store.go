@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LLM batch item status values. Items move queued → submitted → completed →
+// delivered, or to failed when the provider batch could not produce a result.
+const (
+	LLMBatchItemQueued    = "queued"    // waiting to be grouped into a provider batch
+	LLMBatchItemSubmitted = "submitted" // part of an in-flight provider batch
+	LLMBatchItemCompleted = "completed" // result stored, not yet handed back to its worker
+	LLMBatchItemFailed    = "failed"    // batch produced no result, not yet handed back
+	LLMBatchItemDelivered = "delivered" // worker handler ran (see Error for handler failures)
+)
+
+// LLM batch job status values. A job is recorded as submitting before the
+// provider batch is created and moves to in_progress once its provider batch
+// ID is stored, so a failure in between never loses track of a paid batch.
+const (
+	LLMBatchJobSubmitting = "submitting"
+	LLMBatchJobInProgress = "in_progress"
+	LLMBatchJobEnded      = "ended"
+	LLMBatchJobFailed     = "failed"
+)
+
+// LLMBatchJob is one provider-side batch (Anthropic Message Batch, OpenAI Batch).
+type LLMBatchJob struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	TenantID        uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	Provider        string     `json:"provider" db:"provider"`
+	ProviderBatchID string     `json:"provider_batch_id" db:"provider_batch_id"`
+	Status          string     `json:"status" db:"status"`
+	ItemCount       int        `json:"item_count" db:"item_count"`
+	Error           string     `json:"error,omitempty" db:"error"`
+	SubmittedAt     time.Time  `json:"submitted_at" db:"submitted_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// LLMBatchItem is one queued background ChatRequest. Request and Response hold
+// JSON-encoded providers.ChatRequest / providers.ChatResponse; Payload is opaque
+// worker state needed to resume processing when the result is delivered.
+// PIIVault is set when Request was PII-redacted at enqueue: it holds the
+// JSON-encoded providers.RedactionVault that restores the response.
+type LLMBatchItem struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	TenantID  uuid.UUID       `json:"tenant_id" db:"tenant_id"`
+	JobID     *uuid.UUID      `json:"job_id,omitempty" db:"job_id"`
+	Kind      string          `json:"kind" db:"kind"` // handler key, e.g. "episodic.summarize"
+	Provider  string          `json:"provider" db:"provider"`
+	Model     string          `json:"model" db:"model"`
+	Request   json.RawMessage `json:"request" db:"request"`
+	Payload   json.RawMessage `json:"payload,omitempty" db:"payload"`
+	Status    string          `json:"status" db:"status"`
+	Response  json.RawMessage `json:"response,omitempty" db:"response"`
+	Error     string          `json:"error,omitempty" db:"error"`
+	PIIVault  json.RawMessage `json:"-" db:"pii_vault"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// LLMBatchStore persists batch-mode LLM requests for background workers so
+// in-flight provider batches survive restarts. Methods are not tenant-scoped:
+// the dispatcher runs system-wide and each row carries its own tenant_id.
+type LLMBatchStore interface {
+	// EnqueueItem inserts a queued item. ID, Status and timestamps are set when empty.
+	EnqueueItem(ctx context.Context, item *LLMBatchItem) error
+
+	// ListItemsByStatus returns items in the given status across all tenants, oldest first.
+	ListItemsByStatus(ctx context.Context, status string, limit int) ([]LLMBatchItem, error)
+
+	// CreateJob inserts a job and moves its items to submitted in one transaction.
+	// Status defaults to in_progress.
+	CreateJob(ctx context.Context, job *LLMBatchJob, itemIDs []uuid.UUID) error
+
+	// MarkJobSubmitted records the provider batch ID of a submitting job and
+	// moves it to in_progress.
+	MarkJobSubmitted(ctx context.Context, jobID uuid.UUID, providerBatchID string) error
+
+	// ListActiveJobs returns submitting and in-progress jobs across all tenants.
+	ListActiveJobs(ctx context.Context) ([]LLMBatchJob, error)
+
+	// ListJobItems returns the items belonging to a job.
+	ListJobItems(ctx context.Context, jobID uuid.UUID) ([]LLMBatchItem, error)
+
+	// FinishJob records the terminal status of a job.
+	FinishJob(ctx context.Context, jobID uuid.UUID, status, errMsg string) error
+
+	// UpdateItem sets an item's status, response and error.
+	UpdateItem(ctx context.Context, id uuid.UUID, status string, response json.RawMessage, errMsg string) error
+
+	// PruneFinished deletes delivered items and finished jobs older than olderThan.
+	PruneFinished(ctx context.Context, olderThan time.Duration) (int64, error)
+}
@@ -0,0 +1,54 @@
+package store
+
+import "encoding/json"
+
+// LoadBalancingConfig is the per-agent policy stored in other_config JSONB
+// under "load_balancing". It spreads requests across equivalent endpoints of
+// the agent's model; the agent's own provider/model is always the implicit
+// first candidate.
+type LoadBalancingConfig struct {
+	Enabled    bool                     `json:"enabled" db:"-"`
+	Candidates []LoadBalancingCandidate `json:"candidates,omitempty" db:"-"`
+	// PrimaryWeight is the base weight of the agent's own provider (0 = 1).
+	PrimaryWeight float64 `json:"primary_weight,omitempty" db:"-"`
+	// HedgeAfterMs starts the next candidate when the first has not produced
+	// a token after this many milliseconds (0 = no hedging).
+	HedgeAfterMs int `json:"hedge_after_ms,omitempty" db:"-"`
+}
+
+// LoadBalancingCandidate is one extra endpoint serving the same model.
+type LoadBalancingCandidate struct {
+	Provider string  `json:"provider" db:"-"`
+	Model    string  `json:"model,omitempty" db:"-"`  // empty = agent's model
+	Weight   float64 `json:"weight,omitempty" db:"-"` // base share (0 = 1)
+}
+
+// ParseLoadBalancing returns the agent's load-balancing policy from
+// other_config, or nil when absent, disabled, or without extra candidates.
+func (a *AgentData) ParseLoadBalancing() *LoadBalancingConfig {
+	if len(a.OtherConfig) <= 2 {
+		return nil
+	}
+	var bag struct {
+		LoadBalancing *LoadBalancingConfig `json:"load_balancing"`
+	}
+	if json.Unmarshal(a.OtherConfig, &bag) != nil || bag.LoadBalancing == nil {
+		return nil
+	}
+	cfg := bag.LoadBalancing
+	if !cfg.Enabled {
+		return nil
+	}
+	candidates := cfg.Candidates[:0]
+	for _, c := range cfg.Candidates {
+		if c.Provider != "" && c.Weight >= 0 {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	cfg.Candidates = candidates
+	cfg.HedgeAfterMs = max(cfg.HedgeAfterMs, 0)
+	return cfg
+}
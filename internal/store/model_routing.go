@@ -0,0 +1,60 @@
+package store
+
+import "encoding/json"
+
+// ModelRoutingConfig is the per-agent cascading router policy stored in
+// other_config JSONB under "model_routing". Tiers are listed cheapest first;
+// the agent's own provider/model is always the implicit strongest tier.
+type ModelRoutingConfig struct {
+	Enabled bool               `json:"enabled" db:"-"`
+	Tiers   []ModelRoutingTier `json:"tiers,omitempty" db:"-"`
+	// Escalate re-runs a turn on the next stronger tier when the cheaper
+	// answer fails the confidence check. Nil means enabled.
+	Escalate *bool `json:"escalate,omitempty" db:"-"`
+}
+
+// ModelRoutingTier is one cheaper step of the cascade.
+type ModelRoutingTier struct {
+	Name     string `json:"name,omitempty" db:"-"`
+	Provider string `json:"provider,omitempty" db:"-"` // empty = agent's provider
+	Model    string `json:"model" db:"-"`
+	// MaxScore is the highest turn complexity score this tier accepts.
+	MaxScore int `json:"max_score" db:"-"`
+	// MaxToolIterations caps iterations on this tier (0 = agent default).
+	// Hitting the cap counts as a failed attempt and triggers escalation.
+	MaxToolIterations int `json:"max_tool_iterations,omitempty" db:"-"`
+}
+
+// EscalationEnabled reports whether failed cheap attempts are re-run.
+func (c *ModelRoutingConfig) EscalationEnabled() bool {
+	return c.Escalate == nil || *c.Escalate
+}
+
+// ParseModelRouting returns the agent's routing policy from other_config,
+// or nil when routing is absent, disabled, or has no usable tiers.
+func (a *AgentData) ParseModelRouting() *ModelRoutingConfig {
+	if len(a.OtherConfig) <= 2 {
+		return nil
+	}
+	var bag struct {
+		ModelRouting *ModelRoutingConfig `json:"model_routing"`
+	}
+	if json.Unmarshal(a.OtherConfig, &bag) != nil || bag.ModelRouting == nil {
+		return nil
+	}
+	cfg := bag.ModelRouting
+	if !cfg.Enabled {
+		return nil
+	}
+	tiers := cfg.Tiers[:0]
+	for _, t := range cfg.Tiers {
+		if t.Model != "" {
+			tiers = append(tiers, t)
+		}
+	}
+	if len(tiers) == 0 {
+		return nil
+	}
+	cfg.Tiers = tiers
+	return cfg
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage is an outbound channel message persisted before dispatch.
+// Rows are deleted once delivered; failed deliveries are rescheduled until
+// they succeed or are moved to the dead-letter table.
+type OutboxMessage struct {
+	ID            uuid.UUID       `json:"id" db:"id"` // also the delivery idempotency key
+	TenantID      uuid.UUID       `json:"tenant_id" db:"tenant_id"`
+	Channel       string          `json:"channel" db:"channel"`
+	ChatID        string          `json:"chat_id" db:"chat_id"`
+	Payload       json.RawMessage `json:"payload" db:"payload"` // JSON-encoded bus.OutboundMessage
+	Attempts      int             `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// OutboxDeadLetter is an outbound message that failed permanently or
+// exhausted its delivery attempts. It can be replayed into the outbox.
+type OutboxDeadLetter struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	TenantID  uuid.UUID       `json:"tenant_id" db:"tenant_id"`
+	Channel   string          `json:"channel" db:"channel"`
+	ChatID    string          `json:"chat_id" db:"chat_id"`
+	Payload   json.RawMessage `json:"payload" db:"payload"`
+	Attempts  int             `json:"attempts" db:"attempts"`
+	LastError string          `json:"last_error" db:"last_error"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"` // when the message was first enqueued
+	FailedAt  time.Time       `json:"failed_at" db:"failed_at"`
+}
+
+// OutboxStore persists outbound channel messages so replies survive send
+// failures and gateway restarts. Methods are not tenant-scoped: the channel
+// dispatcher runs system-wide and each row carries its own tenant_id.
+type OutboxStore interface {
+	// Enqueue inserts a message due at NextAttemptAt (now when zero).
+	// ID and timestamps are set when empty.
+	Enqueue(ctx context.Context, msg *OutboxMessage) error
+
+	// ClaimDue returns up to limit messages due at or before now, oldest first,
+	// and pushes their next_attempt_at to now+lease so a delivery interrupted
+	// by a crash is retried once the lease expires.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error)
+
+	// Delete removes a delivered message.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Reschedule records a failed attempt and when to retry.
+	Reschedule(ctx context.Context, id uuid.UUID, attempts int, next time.Time, lastErr string) error
+
+	// MoveToDeadLetter moves a message to the dead-letter table in one transaction.
+	MoveToDeadLetter(ctx context.Context, id uuid.UUID, attempts int, lastErr string) error
+
+	// ListDeadLetters returns dead letters newest first. A nil tenantID lists
+	// all tenants; an empty channel lists all channels.
+	ListDeadLetters(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]OutboxDeadLetter, error)
+
+	// GetDeadLetter returns one dead letter, or sql.ErrNoRows.
+	GetDeadLetter(ctx context.Context, id uuid.UUID) (*OutboxDeadLetter, error)
+
+	// ReplayDeadLetter moves a dead letter back into the outbox, due now with
+	// its attempts reset. Returns sql.ErrNoRows when it does not exist.
+	ReplayDeadLetter(ctx context.Context, id uuid.UUID) error
+
+	// DeleteDeadLetter discards a dead letter. Returns sql.ErrNoRows when it does not exist.
+	DeleteDeadLetter(ctx context.Context, id uuid.UUID) error
+
+	// PruneDeadLetters deletes dead letters that failed more than olderThan ago.
+	PruneDeadLetters(ctx context.Context, olderThan time.Duration) (int64, error)
+}
@@ -43,7 +43,8 @@ func (s *PGAgentStore) generateAgentEmbedding(ctx context.Context, agentID uuid.
 		return
 	}
 	vecStr := vectorToString(embeddings[0])
-	if _, err := s.db.ExecContext(ctx, `UPDATE agents SET embedding = $1::vector WHERE id = $2`, vecStr, agentID); err != nil {
+	if _, err := s.db.ExecContext(ctx, `UPDATE agents SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+		vecStr, embeddingTag(s.embProvider, embeddings[0]), agentID); err != nil {
 		slog.Warn("agent embedding update failed", "agent", agentID, "error", err)
 	}
 }
@@ -76,7 +77,8 @@ func (s *PGAgentStore) BackfillAgentEmbeddings(ctx context.Context) (int, error)
 			continue
 		}
 		vecStr := vectorToString(embeddings[0])
-		if _, err := s.db.ExecContext(ctx, `UPDATE agents SET embedding = $1::vector WHERE id = $2`, vecStr, ag.ID); err != nil {
+		if _, err := s.db.ExecContext(ctx, `UPDATE agents SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+			vecStr, embeddingTag(s.embProvider, embeddings[0]), ag.ID); err != nil {
 			continue
 		}
 		updated++
@@ -3,6 +3,8 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -314,3 +316,95 @@ func (s *PGContactStore) GetContactsByMergedID(ctx context.Context, mergedID uui
 	}
 	return contacts, rows.Err()
 }
+
+func (s *PGContactStore) GetContactTags(ctx context.Context, channelType, senderID string) ([]string, error) {
+	tid := store.TenantIDFromContext(ctx)
+	var raw []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT metadata->'tags' FROM channel_contacts
+		 WHERE tenant_id = $1 AND channel_type = $2 AND sender_id = $3 AND COALESCE(thread_id, '') = ''`,
+		tid, channelType, senderID,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || len(raw) == 0 {
+		return nil, err
+	}
+	var tags []string
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return nil, fmt.Errorf("decode contact tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *PGContactStore) SetContactTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE channel_contacts SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{tags}', $1::jsonb)
+		 WHERE id = $2 AND tenant_id = $3`,
+		string(data), id, store.TenantIDFromContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *PGContactStore) GetContactRoute(ctx context.Context, key store.ContactRouteKey) (*store.ContactRoute, error) {
+	var raw []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT metadata->'routing'->$5 FROM channel_contacts
+		 WHERE tenant_id = $1 AND channel_type = $2 AND sender_id = $3 AND COALESCE(thread_id, '') = $4`,
+		store.TenantIDFromContext(ctx), key.ChannelType, key.SenderID, key.ThreadID, key.ChannelInstance,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || len(raw) == 0 {
+		return nil, err
+	}
+	var route store.ContactRoute
+	if err := json.Unmarshal(raw, &route); err != nil {
+		return nil, fmt.Errorf("decode contact route: %w", err)
+	}
+	if route.Agent == "" {
+		return nil, nil
+	}
+	return &route, nil
+}
+
+func (s *PGContactStore) SetContactRoute(ctx context.Context, key store.ContactRouteKey, route *store.ContactRoute) error {
+	tid := store.TenantIDFromContext(ctx)
+	if route == nil {
+		_, err := s.db.ExecContext(ctx,
+			`UPDATE channel_contacts SET metadata = jsonb_set(metadata, '{routing}', (metadata->'routing') - $5)
+			 WHERE tenant_id = $1 AND channel_type = $2 AND sender_id = $3 AND COALESCE(thread_id, '') = $4
+			   AND metadata ? 'routing'`,
+			tid, key.ChannelType, key.SenderID, key.ThreadID, key.ChannelInstance,
+		)
+		return err
+	}
+	data, err := json.Marshal(route)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO channel_contacts (channel_type, channel_instance, sender_id, peer_kind, contact_type, thread_id, tenant_id, metadata)
+		VALUES ($1, NULLIF($2,''), $3, NULLIF($4,''), $5, NULLIF($6,''), $7, jsonb_build_object('routing', jsonb_build_object($2::text, $8::jsonb)))
+		ON CONFLICT (tenant_id, channel_type, sender_id, COALESCE(thread_id, '')) DO UPDATE SET
+			metadata = COALESCE(channel_contacts.metadata, '{}'::jsonb) || jsonb_build_object('routing',
+				COALESCE(channel_contacts.metadata->'routing', '{}'::jsonb) || jsonb_build_object($2::text, $8::jsonb))`,
+		key.ChannelType, key.ChannelInstance, key.SenderID, key.PeerKind, key.ContactType, key.ThreadID, tid, string(data),
+	)
+	return err
+}
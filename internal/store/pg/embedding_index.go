@@ -0,0 +1,219 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// embeddingTable describes one vector column and the text its vectors embed.
+// text must match what the store's write path embeds.
+type embeddingTable struct {
+	name string
+	text string // SQL expression
+}
+
+// embeddingTables lists the EmbeddingProvider-backed tables in re-embedding
+// order. Tables searched with caller-supplied vectors (skills, agents, team
+// tasks) come first: they cannot dual-read, so their unmigrated rows are
+// hidden from semantic search until re-embedded.
+var embeddingTables = []embeddingTable{
+	{"skills", `name || CASE WHEN COALESCE(description, '') <> '' THEN ': ' || description ELSE '' END`},
+	{"agents", `COALESCE(display_name, '') || ': ' || COALESCE(frontmatter, '')`},
+	{"team_tasks", `subject`},
+	{"kg_entities", `name || ' ' || COALESCE(description, '')`},
+	{"episodic_summaries", `summary`},
+	{"vault_documents", `COALESCE(title, '') || ' ' || path || ' ' || COALESCE(summary, '')`},
+	{"memory_chunks", `text`},
+}
+
+// PGEmbeddingIndexStore implements store.EmbeddingIndexStore backed by Postgres.
+type PGEmbeddingIndexStore struct {
+	db *sql.DB
+}
+
+func NewPGEmbeddingIndexStore(db *sql.DB) *PGEmbeddingIndexStore {
+	return &PGEmbeddingIndexStore{db: db}
+}
+
+func (s *PGEmbeddingIndexStore) Tables() []string {
+	names := make([]string, len(embeddingTables))
+	for i, t := range embeddingTables {
+		names[i] = t.name
+	}
+	return names
+}
+
+func (s *PGEmbeddingIndexStore) Stats(ctx context.Context, tag string) ([]store.EmbeddingIndexStats, error) {
+	stats := make([]store.EmbeddingIndexStats, 0, len(embeddingTables))
+	for _, t := range embeddingTables {
+		st := store.EmbeddingIndexStats{Table: t.name}
+		if err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT
+				COUNT(*) FILTER (WHERE embedding IS NOT NULL),
+				COUNT(*) FILTER (WHERE embedding IS NOT NULL AND embedding_model = $1),
+				COUNT(*) FILTER (WHERE embedding IS NOT NULL AND embedding_model IS DISTINCT FROM $1)
+			FROM %s`, t.name), tag,
+		).Scan(&st.Embedded, &st.Current, &st.Stale); err != nil {
+			return nil, fmt.Errorf("embedding stats %s: %w", t.name, err)
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+func (s *PGEmbeddingIndexStore) AdoptUntagged(ctx context.Context, tag string) (int, error) {
+	total := 0
+	for _, t := range embeddingTables {
+		res, err := s.db.ExecContext(ctx, fmt.Sprintf(
+			`UPDATE %s SET embedding_model = $1 WHERE embedding IS NOT NULL AND embedding_model IS NULL`, t.name), tag)
+		if err != nil {
+			return total, fmt.Errorf("adopt embeddings %s: %w", t.name, err)
+		}
+		n, _ := res.RowsAffected()
+		total += int(n)
+	}
+	return total, nil
+}
+
+func (s *PGEmbeddingIndexStore) ReembedBatch(ctx context.Context, table, cursor string, p store.EmbeddingProvider, limit int) (int, string, error) {
+	var t *embeddingTable
+	for i := range embeddingTables {
+		if embeddingTables[i].name == table {
+			t = &embeddingTables[i]
+		}
+	}
+	if t == nil {
+		return 0, "", fmt.Errorf("unknown embedding table %q", table)
+	}
+	after := uuid.Nil
+	if cursor != "" {
+		var err error
+		if after, err = parseUUID(cursor); err != nil {
+			return 0, "", fmt.Errorf("reembed %s: cursor: %w", table, err)
+		}
+	}
+	tag := store.EmbeddingModelTag(p.Model(), store.RequiredMemoryEmbeddingDimensions)
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s FROM %s
+		WHERE embedding IS NOT NULL AND embedding_model IS DISTINCT FROM $1 AND id > $2
+		ORDER BY id LIMIT $3`, t.text, t.name), tag, after, limit)
+	if err != nil {
+		return 0, "", fmt.Errorf("reembed %s: %w", table, err)
+	}
+	type pendingRow struct {
+		id   uuid.UUID
+		text string
+	}
+	var pending []pendingRow
+	for rows.Next() {
+		var r pendingRow
+		if err := rows.Scan(&r.id, &r.text); err != nil {
+			rows.Close()
+			return 0, "", fmt.Errorf("reembed %s: scan: %w", table, err)
+		}
+		pending = append(pending, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, "", fmt.Errorf("reembed %s: %w", table, err)
+	}
+	if len(pending) == 0 {
+		return 0, "", nil
+	}
+
+	// Rows with nothing to embed lose their stale vector instead of being
+	// re-selected forever.
+	var texts []string
+	var embedIdx []int
+	for i, r := range pending {
+		if strings.TrimSpace(r.text) == "" {
+			if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+				`UPDATE %s SET embedding = NULL, embedding_model = NULL WHERE id = $1`, t.name), r.id); err != nil {
+				return 0, "", fmt.Errorf("reembed %s: clear id=%s: %w", table, r.id, err)
+			}
+			continue
+		}
+		texts = append(texts, r.text)
+		embedIdx = append(embedIdx, i)
+	}
+	if len(texts) > 0 {
+		vecs, err := p.Embed(ctx, texts)
+		if err != nil {
+			return 0, "", fmt.Errorf("reembed %s: embed: %w", table, err)
+		}
+		if len(vecs) != len(texts) {
+			return 0, "", fmt.Errorf("reembed %s: got %d embeddings for %d texts", table, len(vecs), len(texts))
+		}
+		for j, vec := range vecs {
+			id := pending[embedIdx[j]].id
+			if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+				`UPDATE %s SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`, t.name),
+				vectorToString(vec), embeddingTag(p, vec), id,
+			); err != nil {
+				return 0, "", fmt.Errorf("reembed %s: update id=%s: %w", table, id, err)
+			}
+		}
+	}
+
+	next := pending[len(pending)-1].id.String()
+	if len(pending) < limit {
+		next = ""
+	}
+	return len(pending), next, nil
+}
+
+// embeddingTag is the embedding_model value stored with a vector from p.
+func embeddingTag(p store.EmbeddingProvider, vec []float32) string {
+	return store.EmbeddingModelTag(p.Model(), len(vec))
+}
+
+// taggedVector is a query embedding and the embedding_model tag it may be
+// compared against ("" = any).
+type taggedVector struct {
+	vec []float32
+	tag string
+}
+
+// queryVectors embeds a search query. While a model migration is in progress
+// it returns one vector per model, each restricted to rows of that model, so
+// search keeps working over both re-embedded and not-yet-migrated rows.
+func queryVectors(ctx context.Context, p store.EmbeddingProvider, query string) []taggedVector {
+	vecs, err := p.Embed(ctx, []string{query})
+	if err != nil || len(vecs) == 0 || len(vecs[0]) == 0 {
+		return nil
+	}
+	mp, ok := p.(*store.MigratingEmbeddingProvider)
+	if !ok || !mp.Migrating() {
+		return []taggedVector{{vec: vecs[0]}}
+	}
+	out := []taggedVector{{vec: vecs[0], tag: embeddingTag(p, vecs[0])}}
+	if prev, prevTag := mp.Previous(); prev != nil {
+		if pv, err := prev.Embed(ctx, []string{query}); err == nil && len(pv) > 0 && len(pv[0]) > 0 {
+			out = append(out, taggedVector{vec: pv[0], tag: prevTag})
+		}
+	}
+	return out
+}
+
+// searchModelTag returns the tag a caller-supplied query vector (embedded with
+// p) must be compared against, or "" when no migration is in progress.
+func searchModelTag(p store.EmbeddingProvider) string {
+	if mp, ok := p.(*store.MigratingEmbeddingProvider); ok && mp.Migrating() {
+		return store.EmbeddingModelTag(p.Model(), store.RequiredMemoryEmbeddingDimensions)
+	}
+	return ""
+}
+
+// embeddingModelClause restricts a vector search to one model's rows, using
+// placeholder $n. Returns no clause for an empty tag.
+func embeddingModelClause(col, tag string, n int) (string, []any) {
+	if tag == "" {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND %s = $%d", col, n), []any{tag}
+}
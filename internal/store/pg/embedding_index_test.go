@@ -0,0 +1,49 @@
+package pg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+type fixedEmbedder struct {
+	model string
+	calls int
+}
+
+func (f *fixedEmbedder) Name() string  { return "fixed" }
+func (f *fixedEmbedder) Model() string { return f.model }
+func (f *fixedEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	f.calls++
+	out := make([][]float32, len(texts))
+	for i := range out {
+		out[i] = make([]float32, store.RequiredMemoryEmbeddingDimensions)
+	}
+	return out, nil
+}
+
+func TestQueryVectors_DualReadDuringMigration(t *testing.T) {
+	cur, prev := &fixedEmbedder{model: "new"}, &fixedEmbedder{model: "old"}
+
+	if qv := queryVectors(context.Background(), cur, "q"); len(qv) != 1 || qv[0].tag != "" {
+		t.Errorf("plain provider = %+v, want one unfiltered vector", qv)
+	}
+
+	mp := store.NewMigratingEmbeddingProvider(cur, prev, "old@1536")
+	qv := queryVectors(context.Background(), mp, "q")
+	if len(qv) != 2 || qv[0].tag != "new@1536" || qv[1].tag != "old@1536" || prev.calls != 1 {
+		t.Fatalf("migrating = %+v (prev calls %d)", qv, prev.calls)
+	}
+	if tag := searchModelTag(mp); tag != "new@1536" {
+		t.Errorf("searchModelTag = %q", tag)
+	}
+
+	mp.Finish()
+	if qv := queryVectors(context.Background(), mp, "q"); len(qv) != 1 || qv[0].tag != "" || searchModelTag(mp) != "" {
+		t.Errorf("finished = %+v, want one unfiltered vector", qv)
+	}
+	if clause, args := embeddingModelClause("t.embedding_model", "m@1", 6); clause != " AND t.embedding_model = $6" || args[0] != "m@1" {
+		t.Errorf("clause = %q %v", clause, args)
+	}
+}
@@ -59,7 +59,8 @@ func (s *PGEpisodicStore) ftsSearch(ctx context.Context, query, agentID, userID
 
 // vectorSearch performs cosine similarity search on episodic embeddings.
 // When userID is empty, returns results across all users (admin view).
-func (s *PGEpisodicStore) vectorSearch(ctx context.Context, embedding []float32, agentID, userID string, limit int) []episodicScored {
+// A non-empty modelTag restricts it to summaries embedded with that model.
+func (s *PGEpisodicStore) vectorSearch(ctx context.Context, embedding []float32, modelTag, agentID, userID string, limit int) []episodicScored {
 	vecStr := vectorToString(embedding)
 	q := `SELECT id, session_key, l0_abstract, 1 - (embedding <=> $1) AS score, created_at
 		FROM episodic_summaries
@@ -76,6 +77,11 @@ func (s *PGEpisodicStore) vectorSearch(ctx context.Context, embedding []float32,
 	q += fmt.Sprintf(" AND tenant_id = $%d", p)
 	args = append(args, tenantFromCtx(ctx))
 	p++
+	if mc, mcArgs := embeddingModelClause("embedding_model", modelTag, p); mc != "" {
+		q += mc
+		args = append(args, mcArgs...)
+		p++
+	}
 	q += fmt.Sprintf(" ORDER BY embedding <=> $1 LIMIT $%d", p)
 	args = append(args, limit)
 
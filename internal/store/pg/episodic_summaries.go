@@ -35,12 +35,12 @@ func (s *PGEpisodicStore) Create(ctx context.Context, ep *store.EpisodicSummary)
 	topics := pq.Array(ep.KeyTopics)
 	now := time.Now().UTC()
 
-	var embStr *string
+	var embStr, embModel *string
 	if s.embProvider != nil && ep.Summary != "" {
 		vecs, err := s.embProvider.Embed(ctx, []string{ep.Summary})
 		if err == nil && len(vecs) > 0 {
-			v := vectorToString(vecs[0])
-			embStr = &v
+			v, m := vectorToString(vecs[0]), embeddingTag(s.embProvider, vecs[0])
+			embStr, embModel = &v, &m
 		} else if err != nil {
 			slog.Warn("episodic: embedding failed", "err", err)
 		}
@@ -49,13 +49,13 @@ func (s *PGEpisodicStore) Create(ctx context.Context, ep *store.EpisodicSummary)
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO episodic_summaries
 			(id, tenant_id, agent_id, user_id, session_key, summary, key_topics,
-			 turn_count, token_count, embedding, l0_abstract, source_id,
+			 turn_count, token_count, embedding, embedding_model, l0_abstract, source_id,
 			 source_type, created_at, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		ON CONFLICT (agent_id, user_id, source_id) WHERE source_id IS NOT NULL DO NOTHING`,
 		id, ep.TenantID, ep.AgentID, ep.UserID, ep.SessionKey,
 		ep.Summary, topics, ep.TurnCount, ep.TokenCount,
-		embStr, ep.L0Abstract, ep.SourceID, ep.SourceType, now, ep.ExpiresAt)
+		embStr, embModel, ep.L0Abstract, ep.SourceID, ep.SourceType, now, ep.ExpiresAt)
 	if err != nil {
 		return fmt.Errorf("episodic create: %w", err)
 	}
@@ -140,12 +140,11 @@ func (s *PGEpisodicStore) Search(ctx context.Context, query, agentID, userID str
 	// FTS search
 	ftsResults := s.ftsSearch(ctx, query, agentID, userID, maxResults*2)
 
-	// Vector search (if embedding provider available)
+	// Vector search (if embedding provider available; one pass per model mid-migration)
 	var vecResults []episodicScored
 	if s.embProvider != nil {
-		vecs, err := s.embProvider.Embed(ctx, []string{query})
-		if err == nil && len(vecs) > 0 {
-			vecResults = s.vectorSearch(ctx, vecs[0], agentID, userID, maxResults*2)
+		for _, qv := range queryVectors(ctx, s.embProvider, query) {
+			vecResults = append(vecResults, s.vectorSearch(ctx, qv.vec, qv.tag, agentID, userID, maxResults*2)...)
 		}
 	}
 
@@ -58,6 +58,9 @@ func NewPGStores(cfg store.StoreConfig) (*store.Stores, error) {
 		Episodic:              NewPGEpisodicStore(db),
 		EvolutionMetrics:      NewPGEvolutionMetricsStore(db),
 		EvolutionSuggestions:  NewPGEvolutionSuggestionStore(db),
+		LLMBatches:            NewPGLLMBatchStore(db),
+		Outbox:                NewPGOutboxStore(db),
+		EmbeddingIndex:        NewPGEmbeddingIndexStore(db),
 		Hooks:                 NewPGHookStore(db),
 	}, nil
 }
@@ -211,14 +211,12 @@ func (s *PGKnowledgeGraphStore) SearchEntities(ctx context.Context, agentID, use
 		return nil, err
 	}
 
-	// Vector search if provider available
+	// Vector search if provider available (one pass per model mid-migration)
 	var vecResults []scoredEntity
 	if s.embProvider != nil {
-		embeddings, embErr := s.embProvider.Embed(ctx, []string{query})
-		if embErr == nil && len(embeddings) > 0 {
-			vecResults, err = s.vectorSearchEntities(ctx, embeddings[0], aid, userID, limit*2, shared)
-			if err != nil {
-				vecResults = nil
+		for _, qv := range queryVectors(ctx, s.embProvider, query) {
+			if res, vecErr := s.vectorSearchEntities(ctx, qv.vec, qv.tag, aid, userID, limit*2, shared); vecErr == nil {
+				vecResults = append(vecResults, res...)
 			}
 		}
 	}
@@ -291,7 +289,9 @@ func (s *PGKnowledgeGraphStore) ftsSearchEntities(ctx context.Context, agentID u
 	return results, nil
 }
 
-func (s *PGKnowledgeGraphStore) vectorSearchEntities(ctx context.Context, embedding []float32, agentID uuid.UUID, userID string, limit int, shared bool) ([]scoredEntity, error) {
+// vectorSearchEntities ranks current entities by cosine similarity. A non-empty
+// modelTag restricts it to entities embedded with that model.
+func (s *PGKnowledgeGraphStore) vectorSearchEntities(ctx context.Context, embedding []float32, modelTag string, agentID uuid.UUID, userID string, limit int, shared bool) ([]scoredEntity, error) {
 	vecStr := vectorToString(embedding)
 
 	where := "agent_id = $1 AND valid_until IS NULL AND embedding IS NOT NULL"
@@ -311,6 +311,11 @@ func (s *PGKnowledgeGraphStore) vectorSearchEntities(ctx context.Context, embedd
 		args = append(args, tcArgs...)
 		idx++
 	}
+	if mc, mcArgs := embeddingModelClause("embedding_model", modelTag, idx); mc != "" {
+		where += mc
+		args = append(args, mcArgs...)
+		idx++
+	}
 	args = append(args, vecStr, limit)
 	q := fmt.Sprintf(`
 		SELECT id, agent_id, user_id, external_id, name, entity_type, description,
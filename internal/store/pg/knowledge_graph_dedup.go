@@ -106,7 +106,9 @@ type knnNeighbor struct {
 // knnNeighbors finds the top-K nearest entities of the same type using HNSW index.
 // embeddingStr is the PG vector text format (e.g. "[0.1,0.2,...]").
 func (s *PGKnowledgeGraphStore) knnNeighbors(ctx context.Context, agentID uuid.UUID, userID string, excludeID uuid.UUID, entityType, embeddingStr string, shared bool, limit int) ([]knnNeighbor, error) {
-	where := "agent_id = $1 AND entity_type = $2 AND id != $3 AND embedding IS NOT NULL"
+	// Vectors are only comparable within one embedding model (see excludeID's row).
+	where := "agent_id = $1 AND entity_type = $2 AND id != $3 AND embedding IS NOT NULL" +
+		" AND embedding_model IS NOT DISTINCT FROM (SELECT embedding_model FROM kg_entities WHERE id = $3)"
 	args := []any{agentID, entityType, excludeID}
 	idx := 4
 	if !shared && userID != "" {
@@ -211,6 +213,7 @@ func (s *PGKnowledgeGraphStore) ScanDuplicates(ctx context.Context, agentID, use
 		  AND b.entity_type = a.entity_type
 		  AND b.id > a.id
 		  AND b.embedding IS NOT NULL
+		  AND b.embedding_model IS NOT DISTINCT FROM a.embedding_model
 		WHERE %s
 		  AND a.embedding IS NOT NULL
 		  AND 1 - (a.embedding <=> b.embedding) > $%d
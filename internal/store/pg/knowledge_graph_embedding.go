@@ -87,8 +87,8 @@ func (s *PGKnowledgeGraphStore) BackfillKGEmbeddings(ctx context.Context) (int,
 			}
 			vecStr := vectorToString(emb)
 			if _, err := s.db.ExecContext(ctx,
-				`UPDATE kg_entities SET embedding = $1::vector WHERE id = $2`,
-				vecStr, pending[i].id,
+				`UPDATE kg_entities SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+				vecStr, embeddingTag(s.embProvider, emb), pending[i].id,
 			); err != nil {
 				slog.Warn("kg entity embedding update failed", "entity_id", pending[i].id, "error", err)
 				continue
@@ -128,8 +128,8 @@ func (s *PGKnowledgeGraphStore) EmbedEntity(ctx context.Context, entityID, name,
 	}
 	vecStr := vectorToString(embeddings[0])
 	if _, err := s.db.ExecContext(ctx,
-		`UPDATE kg_entities SET embedding = $1::vector WHERE id = $2`,
-		vecStr, eid,
+		`UPDATE kg_entities SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+		vecStr, embeddingTag(s.embProvider, embeddings[0]), eid,
 	); err != nil {
 		slog.Warn("kg entity embedding failed", "entity_id", entityID, "error", err)
 	}
@@ -231,8 +231,8 @@ func (s *PGKnowledgeGraphStore) IngestExtraction(ctx context.Context, agentID, u
 				}
 				vecStr := vectorToString(emb)
 				if _, err := tx.ExecContext(ctx,
-					`UPDATE kg_entities SET embedding = $1::vector WHERE id = $2`,
-					vecStr, ids[i],
+					`UPDATE kg_entities SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+					vecStr, embeddingTag(s.embProvider, emb), ids[i],
 				); err != nil {
 					slog.Warn("kg entity embedding update failed", "entity_id", ids[i], "error", err)
 				}
@@ -0,0 +1,185 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// PGLLMBatchStore implements store.LLMBatchStore backed by PostgreSQL.
+type PGLLMBatchStore struct {
+	db *sql.DB
+}
+
+// NewPGLLMBatchStore creates a new PG-backed LLM batch store.
+func NewPGLLMBatchStore(db *sql.DB) *PGLLMBatchStore {
+	return &PGLLMBatchStore{db: db}
+}
+
+const llmBatchItemColumns = `id, tenant_id, job_id, kind, provider, model, request, payload,
+	status, response, error, pii_vault, created_at, updated_at`
+
+func (s *PGLLMBatchStore) EnqueueItem(ctx context.Context, item *store.LLMBatchItem) error {
+	if item.TenantID == uuid.Nil {
+		return fmt.Errorf("llm_batch.EnqueueItem: tenant_id required")
+	}
+	if item.ID == uuid.Nil {
+		item.ID = store.GenNewID()
+	}
+	if item.Status == "" {
+		item.Status = store.LLMBatchItemQueued
+	}
+	now := time.Now().UTC()
+	item.CreatedAt, item.UpdatedAt = now, now
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO llm_batch_items (id, tenant_id, kind, provider, model, request, payload, status, pii_vault, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		item.ID, item.TenantID, item.Kind, item.Provider, item.Model,
+		[]byte(item.Request), nullJSON(item.Payload), item.Status, nullJSON(item.PIIVault), now, now)
+	return err
+}
+
+func (s *PGLLMBatchStore) ListItemsByStatus(ctx context.Context, status string, limit int) ([]store.LLMBatchItem, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+llmBatchItemColumns+` FROM llm_batch_items
+		 WHERE status = $1 ORDER BY created_at LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLLMBatchItems(rows)
+}
+
+func (s *PGLLMBatchStore) CreateJob(ctx context.Context, job *store.LLMBatchJob, itemIDs []uuid.UUID) error {
+	if job.ID == uuid.Nil {
+		job.ID = store.GenNewID()
+	}
+	if job.Status == "" {
+		job.Status = store.LLMBatchJobInProgress
+	}
+	if job.SubmittedAt.IsZero() {
+		job.SubmittedAt = time.Now().UTC()
+	}
+	job.ItemCount = len(itemIDs)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO llm_batch_jobs (id, tenant_id, provider, provider_batch_id, status, item_count, submitted_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		job.ID, job.TenantID, job.Provider, job.ProviderBatchID, job.Status, job.ItemCount, job.SubmittedAt); err != nil {
+		return err
+	}
+	for _, id := range itemIDs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE llm_batch_items SET job_id = $1, status = $2, updated_at = $3 WHERE id = $4`,
+			job.ID, store.LLMBatchItemSubmitted, job.SubmittedAt, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PGLLMBatchStore) MarkJobSubmitted(ctx context.Context, jobID uuid.UUID, providerBatchID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE llm_batch_jobs SET provider_batch_id = $1, status = $2, updated_at = $3 WHERE id = $4`,
+		providerBatchID, store.LLMBatchJobInProgress, time.Now().UTC(), jobID)
+	return err
+}
+
+func (s *PGLLMBatchStore) ListActiveJobs(ctx context.Context) ([]store.LLMBatchJob, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, tenant_id, provider, provider_batch_id, status, item_count, error, submitted_at, completed_at
+		 FROM llm_batch_jobs WHERE status IN ($1, $2) ORDER BY submitted_at`,
+		store.LLMBatchJobSubmitting, store.LLMBatchJobInProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []store.LLMBatchJob
+	for rows.Next() {
+		var j store.LLMBatchJob
+		if err := rows.Scan(&j.ID, &j.TenantID, &j.Provider, &j.ProviderBatchID, &j.Status,
+			&j.ItemCount, &j.Error, &j.SubmittedAt, &j.CompletedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *PGLLMBatchStore) ListJobItems(ctx context.Context, jobID uuid.UUID) ([]store.LLMBatchItem, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+llmBatchItemColumns+` FROM llm_batch_items WHERE job_id = $1 ORDER BY created_at`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return scanLLMBatchItems(rows)
+}
+
+func (s *PGLLMBatchStore) FinishJob(ctx context.Context, jobID uuid.UUID, status, errMsg string) error {
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE llm_batch_jobs SET status = $1, error = $2, completed_at = $3, updated_at = $3 WHERE id = $4`,
+		status, errMsg, now, jobID)
+	return err
+}
+
+func (s *PGLLMBatchStore) UpdateItem(ctx context.Context, id uuid.UUID, status string, response json.RawMessage, errMsg string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE llm_batch_items SET status = $1, response = COALESCE($2, response), error = $3, updated_at = $4 WHERE id = $5`,
+		status, nullJSON(response), errMsg, time.Now().UTC(), id)
+	return err
+}
+
+func (s *PGLLMBatchStore) PruneFinished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().UTC().Add(-olderThan)
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM llm_batch_items WHERE status = $1 AND updated_at < $2`,
+		store.LLMBatchItemDelivered, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	_, err = s.db.ExecContext(ctx,
+		`DELETE FROM llm_batch_jobs j WHERE j.status NOT IN ($1, $2) AND j.updated_at < $3
+		 AND NOT EXISTS (SELECT 1 FROM llm_batch_items i WHERE i.job_id = j.id)`,
+		store.LLMBatchJobSubmitting, store.LLMBatchJobInProgress, cutoff)
+	return n, err
+}
+
+func scanLLMBatchItems(rows *sql.Rows) ([]store.LLMBatchItem, error) {
+	defer rows.Close()
+	var items []store.LLMBatchItem
+	for rows.Next() {
+		var it store.LLMBatchItem
+		var request, payload, response, vault []byte
+		if err := rows.Scan(&it.ID, &it.TenantID, &it.JobID, &it.Kind, &it.Provider, &it.Model,
+			&request, &payload, &it.Status, &response, &it.Error, &vault, &it.CreatedAt, &it.UpdatedAt); err != nil {
+			return nil, err
+		}
+		it.Request, it.Payload, it.Response, it.PIIVault = request, payload, response, vault
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// nullJSON maps an empty raw JSON value to SQL NULL for nullable JSONB columns.
+func nullJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
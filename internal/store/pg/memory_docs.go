@@ -364,10 +364,10 @@ func (s *PGMemoryStore) IndexDocument(ctx context.Context, agentID, userID, path
 		if embeddings != nil && i < len(embeddings) && embeddings[i] != nil {
 			// Insert with embedding via raw SQL (pgvector)
 			s.db.ExecContext(ctx,
-				`INSERT INTO memory_chunks (id, agent_id, document_id, user_id, path, start_line, end_line, hash, text, embedding, tenant_id, updated_at)
-				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::vector, $11, $12)`,
+				`INSERT INTO memory_chunks (id, agent_id, document_id, user_id, path, start_line, end_line, hash, text, embedding, embedding_model, tenant_id, updated_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::vector, $11, $12, $13)`,
 				chunkID, aid, docID, uid, path, tc.StartLine, tc.EndLine, hash, tc.Text,
-				vectorToString(embeddings[i]), tid, now,
+				vectorToString(embeddings[i]), embeddingTag(s.provider, embeddings[i]), tid, now,
 			)
 		} else {
 			s.db.ExecContext(ctx,
@@ -457,8 +457,8 @@ func (s *PGMemoryStore) BackfillEmbeddings(ctx context.Context) (int, error) {
 			}
 			vecStr := vectorToString(embeddings[i])
 			if _, err := s.db.ExecContext(ctx,
-				"UPDATE memory_chunks SET embedding = $1::vector WHERE id = $2",
-				vecStr, chunk.ID,
+				"UPDATE memory_chunks SET embedding = $1::vector, embedding_model = $2 WHERE id = $3",
+				vecStr, embeddingTag(s.provider, embeddings[i]), chunk.ID,
 			); err != nil {
 				return total, fmt.Errorf("update chunk embedding id=%s: %w", chunk.ID, err)
 			}
@@ -27,14 +27,12 @@ func (s *PGMemoryStore) Search(ctx context.Context, query string, agentID, userI
 		return nil, err
 	}
 
-	// Vector search if provider available
+	// Vector search if provider available (one pass per model mid-migration)
 	var vecResults []scoredChunk
 	if s.provider != nil {
-		embeddings, err := s.provider.Embed(ctx, []string{query})
-		if err == nil && len(embeddings) > 0 {
-			vecResults, err = s.vectorSearch(ctx, embeddings[0], aid, userID, maxResults*2)
-			if err != nil {
-				vecResults = nil
+		for _, qv := range queryVectors(ctx, s.provider, query) {
+			if res, err := s.vectorSearch(ctx, qv.vec, qv.tag, aid, userID, maxResults*2); err == nil {
+				vecResults = append(vecResults, res...)
 			}
 		}
 	}
@@ -142,7 +140,9 @@ func (s *PGMemoryStore) ftsSearch(ctx context.Context, query string, agentID any
 	return results, nil
 }
 
-func (s *PGMemoryStore) vectorSearch(ctx context.Context, embedding []float32, agentID any, userID string, limit int) ([]scoredChunk, error) {
+// vectorSearch ranks chunks by cosine similarity. A non-empty modelTag
+// restricts it to chunks embedded with that model.
+func (s *PGMemoryStore) vectorSearch(ctx context.Context, embedding []float32, modelTag string, agentID any, userID string, limit int) ([]scoredChunk, error) {
 	vecStr := vectorToString(embedding)
 
 	var q string
@@ -156,13 +156,15 @@ func (s *PGMemoryStore) vectorSearch(ctx context.Context, embedding []float32, a
 		}
 		orderN := 3 + len(tcArgs)
 		limitN := orderN + 1
+		mc, mcArgs := embeddingModelClause("embedding_model", modelTag, limitN+1)
 		q = fmt.Sprintf(`SELECT path, start_line, end_line, text, user_id,
 				1 - (embedding <=> $1::vector) AS score
 			FROM memory_chunks
-			WHERE agent_id = $2 AND embedding IS NOT NULL%s
-			ORDER BY embedding <=> $%d::vector LIMIT $%d`, tc, orderN, limitN)
+			WHERE agent_id = $2 AND embedding IS NOT NULL%s%s
+			ORDER BY embedding <=> $%d::vector LIMIT $%d`, tc, mc, orderN, limitN)
 		args = append([]any{vecStr, agentID}, tcArgs...)
 		args = append(args, vecStr, limit)
+		args = append(args, mcArgs...)
 	} else if userID != "" {
 		// fixed params: $1=vec, $2=agentID, $3=userID
 		tc, tcArgs, _, err := scopeClause(ctx, 4)
@@ -171,14 +173,16 @@ func (s *PGMemoryStore) vectorSearch(ctx context.Context, embedding []float32, a
 		}
 		orderN := 4 + len(tcArgs)
 		limitN := orderN + 1
+		mc, mcArgs := embeddingModelClause("embedding_model", modelTag, limitN+1)
 		q = fmt.Sprintf(`SELECT path, start_line, end_line, text, user_id,
 				1 - (embedding <=> $1::vector) AS score
 			FROM memory_chunks
 			WHERE agent_id = $2 AND embedding IS NOT NULL
-			AND (user_id IS NULL OR user_id = $3)%s
-			ORDER BY embedding <=> $%d::vector LIMIT $%d`, tc, orderN, limitN)
+			AND (user_id IS NULL OR user_id = $3)%s%s
+			ORDER BY embedding <=> $%d::vector LIMIT $%d`, tc, mc, orderN, limitN)
 		args = append([]any{vecStr, agentID, userID}, tcArgs...)
 		args = append(args, vecStr, limit)
+		args = append(args, mcArgs...)
 	} else {
 		// fixed params: $1=vec, $2=agentID
 		tc, tcArgs, _, err := scopeClause(ctx, 3)
@@ -187,14 +191,16 @@ func (s *PGMemoryStore) vectorSearch(ctx context.Context, embedding []float32, a
 		}
 		orderN := 3 + len(tcArgs)
 		limitN := orderN + 1
+		mc, mcArgs := embeddingModelClause("embedding_model", modelTag, limitN+1)
 		q = fmt.Sprintf(`SELECT path, start_line, end_line, text, user_id,
 				1 - (embedding <=> $1::vector) AS score
 			FROM memory_chunks
 			WHERE agent_id = $2 AND embedding IS NOT NULL
-			AND user_id IS NULL%s
-			ORDER BY embedding <=> $%d::vector LIMIT $%d`, tc, orderN, limitN)
+			AND user_id IS NULL%s%s
+			ORDER BY embedding <=> $%d::vector LIMIT $%d`, tc, mc, orderN, limitN)
 		args = append([]any{vecStr, agentID}, tcArgs...)
 		args = append(args, vecStr, limit)
+		args = append(args, mcArgs...)
 	}
 
 	var rows []scoredChunkRow
@@ -0,0 +1,204 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// PGOutboxStore implements store.OutboxStore backed by PostgreSQL.
+type PGOutboxStore struct {
+	db *sql.DB
+}
+
+// NewPGOutboxStore creates a new PG-backed outbox store.
+func NewPGOutboxStore(db *sql.DB) *PGOutboxStore {
+	return &PGOutboxStore{db: db}
+}
+
+const outboxColumns = `id, tenant_id, channel, chat_id, payload, attempts, next_attempt_at, last_error, created_at, updated_at`
+
+const deadLetterColumns = `id, tenant_id, channel, chat_id, payload, attempts, last_error, created_at, failed_at`
+
+func (s *PGOutboxStore) Enqueue(ctx context.Context, msg *store.OutboxMessage) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = store.GenNewID()
+	}
+	if msg.TenantID == uuid.Nil {
+		msg.TenantID = store.MasterTenantID
+	}
+	now := time.Now().UTC()
+	msg.CreatedAt, msg.UpdatedAt = now, now
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = now
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO channel_outbox (id, tenant_id, channel, chat_id, payload, attempts, next_attempt_at, last_error, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		msg.ID, msg.TenantID, msg.Channel, msg.ChatID, []byte(msg.Payload),
+		msg.Attempts, msg.NextAttemptAt, msg.LastError, now, now)
+	return err
+}
+
+func (s *PGOutboxStore) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]store.OutboxMessage, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	// SKIP LOCKED keeps concurrent gateways from claiming the same rows.
+	rows, err := s.db.QueryContext(ctx,
+		`UPDATE channel_outbox SET next_attempt_at = $1, updated_at = $2
+		 WHERE id IN (
+		     SELECT id FROM channel_outbox WHERE next_attempt_at <= $2
+		     ORDER BY next_attempt_at LIMIT $3 FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+outboxColumns,
+		now.Add(lease).UTC(), now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []store.OutboxMessage
+	for rows.Next() {
+		var m store.OutboxMessage
+		var payload []byte
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.Channel, &m.ChatID, &payload, &m.Attempts,
+			&m.NextAttemptAt, &m.LastError, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		m.Payload = payload
+		msgs = append(msgs, m)
+	}
+	return msgs, rows.Err()
+}
+
+func (s *PGOutboxStore) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM channel_outbox WHERE id = $1`, id)
+	return err
+}
+
+func (s *PGOutboxStore) Reschedule(ctx context.Context, id uuid.UUID, attempts int, next time.Time, lastErr string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE channel_outbox SET attempts = $1, next_attempt_at = $2, last_error = $3, updated_at = $4 WHERE id = $5`,
+		attempts, next.UTC(), lastErr, time.Now().UTC(), id)
+	return err
+}
+
+func (s *PGOutboxStore) MoveToDeadLetter(ctx context.Context, id uuid.UUID, attempts int, lastErr string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO channel_dead_letters (id, tenant_id, channel, chat_id, payload, attempts, last_error, created_at, failed_at)
+		 SELECT id, tenant_id, channel, chat_id, payload, $1, $2, created_at, $3 FROM channel_outbox WHERE id = $4
+		 ON CONFLICT (id) DO NOTHING`,
+		attempts, lastErr, time.Now().UTC(), id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_outbox WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PGOutboxStore) ListDeadLetters(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]store.OutboxDeadLetter, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	q := `SELECT ` + deadLetterColumns + ` FROM channel_dead_letters WHERE 1=1`
+	var args []any
+	if tenantID != uuid.Nil {
+		args = append(args, tenantID)
+		q += ` AND tenant_id = $1`
+	}
+	if channel != "" {
+		args = append(args, channel)
+		q += fmt.Sprintf(" AND channel = $%d", len(args))
+	}
+	args = append(args, limit, offset)
+	q += fmt.Sprintf(" ORDER BY failed_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []store.OutboxDeadLetter
+	for rows.Next() {
+		d, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
+
+func (s *PGOutboxStore) GetDeadLetter(ctx context.Context, id uuid.UUID) (*store.OutboxDeadLetter, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+deadLetterColumns+` FROM channel_dead_letters WHERE id = $1`, id)
+	return scanDeadLetter(row)
+}
+
+func (s *PGOutboxStore) ReplayDeadLetter(ctx context.Context, id uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO channel_outbox (id, tenant_id, channel, chat_id, payload, attempts, next_attempt_at, last_error, created_at, updated_at)
+		 SELECT id, tenant_id, channel, chat_id, payload, 0, $1, last_error, created_at, $1 FROM channel_dead_letters WHERE id = $2`,
+		now, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_dead_letters WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PGOutboxStore) DeleteDeadLetter(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM channel_dead_letters WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *PGOutboxStore) PruneDeadLetters(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM channel_dead_letters WHERE failed_at < $1`, time.Now().UTC().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanDeadLetter(row interface{ Scan(...any) error }) (*store.OutboxDeadLetter, error) {
+	var d store.OutboxDeadLetter
+	var payload []byte
+	if err := row.Scan(&d.ID, &d.TenantID, &d.Channel, &d.ChatID, &payload, &d.Attempts,
+		&d.LastError, &d.CreatedAt, &d.FailedAt); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	return &d, nil
+}
@@ -29,16 +29,19 @@ func (s *PGSkillStore) SearchByEmbedding(ctx context.Context, embedding []float3
 	tenantCond := buildSkillEmbeddingTenantCond(tc)
 	orderN := nextParam
 	limitN := orderN + 1
+	// Mid-migration, compare only against skills embedded with the query's model.
+	modelCond, modelArgs := embeddingModelClause("embedding_model", searchModelTag(s.embProvider), limitN+1)
 	q := fmt.Sprintf(`SELECT name, slug, COALESCE(description, '') AS description, version, file_path,
 			1 - (embedding <=> $1::vector) AS score
 		FROM skills
 		WHERE status = 'active' AND enabled = true AND embedding IS NOT NULL
-		  AND visibility != 'private'%s
+		  AND visibility != 'private'%s%s
 		ORDER BY embedding <=> $%d::vector
-		LIMIT $%d`, tenantCond, orderN, limitN)
+		LIMIT $%d`, tenantCond, modelCond, orderN, limitN)
 
 	args := append([]any{vecStr}, tcArgs...)
 	args = append(args, vecStr, limit)
+	args = append(args, modelArgs...)
 
 	var scanned []skillEmbeddingSearchRow
 	if err := pkgSqlxDB.SelectContext(ctx, &scanned, q, args...); err != nil {
@@ -107,7 +110,8 @@ func (s *PGSkillStore) BackfillSkillEmbeddings(ctx context.Context) (int, error)
 		}
 		vecStr := vectorToString(embeddings[0])
 		_, err = s.db.ExecContext(ctx,
-			`UPDATE skills SET embedding = $1::vector WHERE id = $2`, vecStr, sk.ID)
+			`UPDATE skills SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+			vecStr, embeddingTag(s.embProvider, embeddings[0]), sk.ID)
 		if err != nil {
 			slog.Warn("skill embedding update failed", "skill", sk.Name, "error", err)
 			continue
@@ -138,7 +142,8 @@ func (s *PGSkillStore) generateEmbedding(ctx context.Context, slug, name, descri
 	}
 	vecStr := vectorToString(embeddings[0])
 	_, err = s.db.ExecContext(ctx,
-		`UPDATE skills SET embedding = $1::vector WHERE slug = $2 AND status = 'active'`, vecStr, slug)
+		`UPDATE skills SET embedding = $1::vector, embedding_model = $2 WHERE slug = $3 AND status = 'active'`,
+		vecStr, embeddingTag(s.embProvider, embeddings[0]), slug)
 	if err != nil {
 		slog.Warn("skill embedding store failed", "skill", name, "error", err)
 	}
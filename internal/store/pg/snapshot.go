@@ -21,10 +21,10 @@ func NewPGSnapshotStore(db *sql.DB) *PGSnapshotStore {
 	return &PGSnapshotStore{db: db}
 }
 
-const snapshotFieldCount = 22
+const snapshotFieldCount = 23
 
-// maxBatchRows limits each INSERT to stay under PG's 65535 param limit (65535 / 21 ≈ 3120).
-const maxBatchRows = 3000
+// maxBatchRows limits each INSERT to stay under PG's 65535 param limit (65535 / 23 ≈ 2849).
+const maxBatchRows = 2800
 
 func (s *PGSnapshotStore) UpsertSnapshots(ctx context.Context, snapshots []store.UsageSnapshot) error {
 	if len(snapshots) == 0 {
@@ -60,7 +60,7 @@ func (s *PGSnapshotStore) upsertBatch(ctx context.Context, snapshots []store.Usa
 			snap.TotalCost, snap.RequestCount, snap.LLMCallCount, snap.ToolCallCount,
 			snap.ErrorCount, snap.UniqueUsers, snap.AvgDurationMS,
 			snap.MemoryDocs, snap.MemoryChunks, snap.KGEntities, snap.KGRelations,
-			tenantID,
+			snap.BaselineCost, tenantID,
 		)
 	}
 
@@ -70,7 +70,7 @@ func (s *PGSnapshotStore) upsertBatch(ctx context.Context, snapshots []store.Usa
 		total_cost, request_count, llm_call_count, tool_call_count,
 		error_count, unique_users, avg_duration_ms,
 		memory_docs, memory_chunks, kg_entities, kg_relations,
-		tenant_id
+		baseline_cost, tenant_id
 	) VALUES ` + strings.Join(vals, ", ") + `
 	ON CONFLICT (bucket_hour, COALESCE(agent_id, '00000000-0000-0000-0000-000000000000'), provider, model, channel, tenant_id)
 	DO UPDATE SET
@@ -89,7 +89,8 @@ func (s *PGSnapshotStore) upsertBatch(ctx context.Context, snapshots []store.Usa
 		memory_docs = EXCLUDED.memory_docs,
 		memory_chunks = EXCLUDED.memory_chunks,
 		kg_entities = EXCLUDED.kg_entities,
-		kg_relations = EXCLUDED.kg_relations`
+		kg_relations = EXCLUDED.kg_relations,
+		baseline_cost = EXCLUDED.baseline_cost`
 
 	_, err := s.db.ExecContext(ctx, query, args...)
 	return err
@@ -216,7 +217,8 @@ func (s *PGSnapshotStore) GetBreakdown(ctx context.Context, q store.SnapshotQuer
 		CASE WHEN SUM(CASE WHEN provider = '' AND model = '' THEN request_count ELSE 0 END) > 0
 			THEN SUM(CASE WHEN provider = '' AND model = '' THEN avg_duration_ms * request_count ELSE 0 END) /
 				SUM(CASE WHEN provider = '' AND model = '' THEN request_count ELSE 0 END)
-			ELSE 0 END
+			ELSE 0 END,
+		SUM(CASE WHEN provider != '' THEN baseline_cost ELSE 0 END)
 	FROM usage_snapshots
 	%s
 	GROUP BY %s
@@ -238,9 +240,11 @@ func (s *PGSnapshotStore) GetBreakdown(ctx context.Context, q store.SnapshotQuer
 			&b.TotalCost,
 			&b.RequestCount, &b.LLMCallCount, &b.ToolCallCount,
 			&b.ErrorCount, &b.AvgDurationMS,
+			&b.BaselineCost,
 		); err != nil {
 			return nil, fmt.Errorf("scan breakdown: %w", err)
 		}
+		b.RoutingSavings = b.BaselineCost - b.TotalCost
 		result = append(result, b)
 	}
 	return result, rows.Err()
@@ -27,7 +27,8 @@ func (s *PGTeamStore) generateTaskEmbedding(ctx context.Context, taskID uuid.UUI
 	}
 	vecStr := vectorToString(embeddings[0])
 	if _, err := s.db.ExecContext(ctx,
-		`UPDATE team_tasks SET embedding = $1::vector WHERE id = $2`, vecStr, taskID,
+		`UPDATE team_tasks SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+		vecStr, embeddingTag(s.embProvider, embeddings[0]), taskID,
 	); err != nil {
 		slog.Warn("task embedding store failed", "task_id", taskID, "error", err)
 	}
@@ -89,8 +90,8 @@ func (s *PGTeamStore) BackfillTaskEmbeddings(ctx context.Context) (int, error) {
 			}
 			vecStr := vectorToString(emb)
 			if _, err := s.db.ExecContext(ctx,
-				`UPDATE team_tasks SET embedding = $1::vector WHERE id = $2`,
-				vecStr, pending[i].id,
+				`UPDATE team_tasks SET embedding = $1::vector, embedding_model = $2 WHERE id = $3`,
+				vecStr, embeddingTag(s.embProvider, emb), pending[i].id,
 			); err != nil {
 				slog.Warn("task embedding update failed", "task_id", pending[i].id, "error", err)
 				continue
@@ -116,16 +117,18 @@ func (s *PGTeamStore) SearchTasksByEmbedding(ctx context.Context, teamID uuid.UU
 	}
 	vecStr := vectorToString(embedding)
 	tid := tenantIDForInsert(ctx)
+	// Mid-migration, compare only against tasks embedded with the query's model.
+	modelCond, modelArgs := embeddingModelClause("t.embedding_model", searchModelTag(s.embProvider), 6)
 
 	rows, err := s.db.QueryContext(ctx,
 		`SELECT `+taskSelectCols+`
 		 `+taskJoinClause+`
 		 WHERE t.team_id = $1 AND t.embedding IS NOT NULL
 		   AND ($4 = '' OR t.user_id = $4)
-		   AND t.tenant_id = $5
+		   AND t.tenant_id = $5`+modelCond+`
 		 ORDER BY t.embedding <=> $2::vector
 		 LIMIT $3`,
-		teamID, vecStr, limit, userID, tid)
+		append([]any{teamID, vecStr, limit, userID, tid}, modelArgs...)...)
 	if err != nil {
 		return nil, err
 	}
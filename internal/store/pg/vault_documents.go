@@ -88,7 +88,7 @@ func (s *PGVaultStore) UpsertDocument(ctx context.Context, doc *store.VaultDocum
 	}
 
 	id := uuid.Must(uuid.NewV7())
-	var embStr *string
+	var embStr, embModel *string
 	if s.embProvider != nil && doc.Summary != "" {
 		// Embed title + path + summary for richer vector search.
 		embedText := doc.Title + " " + doc.Path
@@ -97,8 +97,8 @@ func (s *PGVaultStore) UpsertDocument(ctx context.Context, doc *store.VaultDocum
 		}
 		vecs, embErr := s.embProvider.Embed(ctx, []string{embedText})
 		if embErr == nil && len(vecs) > 0 {
-			v := vectorToString(vecs[0])
-			embStr = &v
+			v, m := vectorToString(vecs[0]), embeddingTag(s.embProvider, vecs[0])
+			embStr, embModel = &v, &m
 		}
 	}
 
@@ -114,20 +114,21 @@ func (s *PGVaultStore) UpsertDocument(ctx context.Context, doc *store.VaultDocum
 	var actualID uuid.UUID
 	err = s.db.QueryRowContext(ctx, `
 		INSERT INTO vault_documents
-			(id, tenant_id, agent_id, team_id, scope, custom_scope, path, title, doc_type, content_hash, summary, embedding, metadata, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+			(id, tenant_id, agent_id, team_id, scope, custom_scope, path, title, doc_type, content_hash, summary, embedding, embedding_model, metadata, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $15, $13, $14, $14)
 		ON CONFLICT (tenant_id, COALESCE(agent_id, '00000000-0000-0000-0000-000000000000'::uuid), COALESCE(team_id, '00000000-0000-0000-0000-000000000000'::uuid), scope, path) DO UPDATE SET
 			title        = EXCLUDED.title,
 			doc_type     = EXCLUDED.doc_type,
 			content_hash = EXCLUDED.content_hash,
 			summary      = EXCLUDED.summary,
 			embedding    = COALESCE(EXCLUDED.embedding, vault_documents.embedding),
+			embedding_model = CASE WHEN EXCLUDED.embedding IS NULL THEN vault_documents.embedding_model ELSE EXCLUDED.embedding_model END,
 			metadata     = EXCLUDED.metadata,
 			tenant_id    = EXCLUDED.tenant_id,
 			updated_at   = EXCLUDED.updated_at
 		RETURNING id`,
 		id, tid, aid, teamID, doc.Scope, doc.CustomScope, doc.Path, doc.Title, doc.DocType,
-		doc.ContentHash, doc.Summary, embStr, meta, now,
+		doc.ContentHash, doc.Summary, embStr, meta, now, embModel,
 	).Scan(&actualID)
 	if err != nil {
 		return fmt.Errorf("vault upsert document: %w", err)
@@ -457,17 +458,16 @@ func (s *PGVaultStore) Search(ctx context.Context, opts store.VaultSearchOptions
 		return nil, err
 	}
 
-	// Vector search if provider available
+	// Vector search if provider available (one pass per model mid-migration)
 	var vecResults []store.VaultSearchResult
 	if s.embProvider != nil {
-		vecs, embErr := s.embProvider.Embed(ctx, []string{opts.Query})
-		if embErr == nil && len(vecs) > 0 {
-			var vecErr error
-			vecResults, vecErr = s.vectorSearch(ctx, vecs[0], tid, aid, tf, opts.Scope, opts.DocTypes, maxResults*2)
+		for _, qv := range queryVectors(ctx, s.embProvider, opts.Query) {
+			res, vecErr := s.vectorSearch(ctx, qv.vec, qv.tag, tid, aid, tf, opts.Scope, opts.DocTypes, maxResults*2)
 			if vecErr != nil {
 				slog.Debug("vault.vector_search_fallback", "err", vecErr)
-				vecResults = nil
+				continue
 			}
+			vecResults = append(vecResults, res...)
 		}
 	}
 
@@ -572,7 +572,9 @@ func (s *PGVaultStore) ftsSearch(ctx context.Context, query string, tenantID uui
 	return vaultSearchRowsToResults(scanned, "vault"), nil
 }
 
-func (s *PGVaultStore) vectorSearch(ctx context.Context, embedding []float32, tenantID uuid.UUID, agentID *uuid.UUID, tf searchTeamFilter, scope string, docTypes []string, limit int) ([]store.VaultSearchResult, error) {
+// vectorSearch ranks documents by cosine similarity. A non-empty modelTag
+// restricts it to documents embedded with that model.
+func (s *PGVaultStore) vectorSearch(ctx context.Context, embedding []float32, modelTag string, tenantID uuid.UUID, agentID *uuid.UUID, tf searchTeamFilter, scope string, docTypes []string, limit int) ([]store.VaultSearchResult, error) {
 	vecStr := vectorToString(embedding)
 	q := `SELECT id, tenant_id, agent_id, team_id, scope, custom_scope, path, path_basename, title, doc_type, content_hash, summary, metadata, created_at, updated_at,
 			1 - (embedding <=> $1) AS score
@@ -599,6 +601,11 @@ func (s *PGVaultStore) vectorSearch(ctx context.Context, embedding []float32, te
 		args = append(args, pqStringArray(docTypes))
 		p++
 	}
+	if mc, mcArgs := embeddingModelClause("embedding_model", modelTag, p); mc != "" {
+		q += mc
+		args = append(args, mcArgs...)
+		p++
+	}
 
 	q += fmt.Sprintf(" ORDER BY embedding <=> $1 LIMIT $%d", p)
 	args = append(args, limit)
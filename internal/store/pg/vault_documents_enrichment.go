@@ -56,21 +56,22 @@ func (s *PGVaultStore) UpdateSummaryAndReembed(ctx context.Context, tenantID, do
 		return fmt.Errorf("vault.update_summary: fetch doc: %w", err)
 	}
 
-	var embStr *string
+	var embStr, embModel *string
 	if s.embProvider != nil {
 		embedText := title + " " + path + " " + summary
 		vecs, embErr := s.embProvider.Embed(ctx, []string{embedText})
 		if embErr == nil && len(vecs) > 0 {
-			v := vectorToString(vecs[0])
-			embStr = &v
+			v, m := vectorToString(vecs[0]), embeddingTag(s.embProvider, vecs[0])
+			embStr, embModel = &v, &m
 		}
 	}
 
 	_, err = s.db.ExecContext(ctx, `
 		UPDATE vault_documents
-		SET summary = $1, embedding = COALESCE($2, embedding), updated_at = $3
+		SET summary = $1, embedding = COALESCE($2, embedding),
+		    embedding_model = COALESCE($6, embedding_model), updated_at = $3
 		WHERE id = $4 AND tenant_id = $5`,
-		summary, embStr, time.Now().UTC(), did, tid,
+		summary, embStr, time.Now().UTC(), did, tid, embModel,
 	)
 	return err
 }
@@ -106,7 +107,8 @@ func (s *PGVaultStore) FindSimilarDocs(ctx context.Context, tenantID, agentID, d
 			content_hash, summary, metadata, created_at, updated_at,
 			1 - (embedding <=> $1::vector) AS score
 		FROM vault_documents
-		WHERE tenant_id = $2 AND id != $3 AND embedding IS NOT NULL`
+		WHERE tenant_id = $2 AND id != $3 AND embedding IS NOT NULL
+		  AND embedding_model IS NOT DISTINCT FROM (SELECT embedding_model FROM vault_documents WHERE id = $3)`
 	args := []any{*embStr, tid, did}
 	p := 4
 
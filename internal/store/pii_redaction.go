@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+)
+
+// PIIRedactionSystemConfigKey is the system_configs key holding the tenant's
+// outbound PII redaction policy as JSON (same shape as PIIRedactionConfig).
+const PIIRedactionSystemConfigKey = "pii_redaction"
+
+// PIIRedactionConfig is an outbound PII redaction policy. Agents store it in
+// other_config JSONB under "pii_redaction"; tenants in system_configs.
+type PIIRedactionConfig struct {
+	Enabled bool `json:"enabled" db:"-"`
+	// Classes are built-in PII classes (email, phone, card, national_id).
+	// Empty with no patterns means all built-in classes.
+	Classes  []string               `json:"classes,omitempty" db:"-"`
+	Patterns []providers.PIIPattern `json:"patterns,omitempty" db:"-"`
+}
+
+// ParsePIIRedactionConfig parses a policy from JSON, returning nil when
+// absent or disabled. Malformed JSON is an error: redaction is a compliance
+// control, so callers must not treat an unreadable policy as "off".
+func ParsePIIRedactionConfig(raw []byte) (*PIIRedactionConfig, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var cfg PIIRedactionConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse pii_redaction: %w", err)
+	}
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return &cfg, nil
+}
+
+// ParsePIIRedaction returns the agent's redaction policy from other_config,
+// or nil when absent or disabled. Malformed other_config is an error.
+func (a *AgentData) ParsePIIRedaction() (*PIIRedactionConfig, error) {
+	if len(a.OtherConfig) == 0 {
+		return nil, nil
+	}
+	var bag struct {
+		PIIRedaction json.RawMessage `json:"pii_redaction"`
+	}
+	if err := json.Unmarshal(a.OtherConfig, &bag); err != nil {
+		return nil, fmt.Errorf("parse other_config: %w", err)
+	}
+	return ParsePIIRedactionConfig(bag.PIIRedaction)
+}
+
+// ResolveEffectivePIIRedaction merges the tenant and agent policies. A tenant
+// policy is a floor: agents can add classes and patterns but cannot turn it
+// off. Returns nil when neither is enabled.
+func ResolveEffectivePIIRedaction(tenant, agent *PIIRedactionConfig) *PIIRedactionConfig {
+	if tenant == nil && agent == nil {
+		return nil
+	}
+	effective := &PIIRedactionConfig{Enabled: true}
+	for _, cfg := range []*PIIRedactionConfig{tenant, agent} {
+		if cfg == nil {
+			continue
+		}
+		classes := cfg.Classes
+		if len(classes) == 0 && len(cfg.Patterns) == 0 {
+			classes = providers.DefaultPIIClasses
+		}
+		for _, c := range classes {
+			if !slices.Contains(effective.Classes, c) {
+				effective.Classes = append(effective.Classes, c)
+			}
+		}
+		effective.Patterns = append(effective.Patterns, cfg.Patterns...)
+	}
+	return effective
+}
+
+// ResolvePIIRedactor builds the effective redactor for an agent from the
+// tenant policy in system_configs and the agent's own policy. Returns nil
+// when neither is enabled. Fails closed: a system config read error or a
+// malformed or invalid policy is returned as an error, never treated as off.
+func ResolvePIIRedactor(ctx context.Context, sysConfigs SystemConfigStore, ag *AgentData) (*providers.PIIRedactor, error) {
+	var tenant *PIIRedactionConfig
+	if sysConfigs != nil && ag.TenantID != uuid.Nil {
+		raw, err := sysConfigs.Get(WithTenantID(ctx, ag.TenantID), PIIRedactionSystemConfigKey)
+		switch {
+		case errors.Is(err, ErrSystemConfigNotFound):
+		case err != nil:
+			return nil, fmt.Errorf("load tenant pii_redaction policy: %w", err)
+		default:
+			if tenant, err = ParsePIIRedactionConfig([]byte(raw)); err != nil {
+				return nil, fmt.Errorf("tenant %w", err)
+			}
+		}
+	}
+	agent, err := ag.ParsePIIRedaction()
+	if err != nil {
+		return nil, fmt.Errorf("agent pii_redaction policy: %w", err)
+	}
+	policy := ResolveEffectivePIIRedaction(tenant, agent)
+	if policy == nil {
+		return nil, nil
+	}
+	r, err := providers.NewPIIRedactor(policy.Classes, policy.Patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pii_redaction policy: %w", err)
+	}
+	return r, nil
+}
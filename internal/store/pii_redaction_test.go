@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// piiSysConfigs is a SystemConfigStore serving a single pii_redaction value.
+type piiSysConfigs struct {
+	SystemConfigStore
+	value string
+	err   error
+}
+
+func (s *piiSysConfigs) Get(_ context.Context, key string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	if s.value == "" {
+		return "", fmt.Errorf("%w: %s", ErrSystemConfigNotFound, key)
+	}
+	return s.value, nil
+}
+
+func TestResolvePIIRedactor(t *testing.T) {
+	tests := []struct {
+		name        string
+		sys         *piiSysConfigs
+		otherConfig string
+		wantOn      bool
+		wantErr     bool
+	}{
+		{"absent", &piiSysConfigs{}, `{}`, false, false},
+		{"disabled", &piiSysConfigs{value: `{"enabled":false}`}, `{"pii_redaction":{"enabled":false}}`, false, false},
+		{"tenant enabled", &piiSysConfigs{value: `{"enabled":true}`}, `{}`, true, false},
+		{"agent enabled", &piiSysConfigs{}, `{"pii_redaction":{"enabled":true,"classes":["email"]}}`, true, false},
+		{"config store error", &piiSysConfigs{err: errors.New("db down")}, `{}`, false, true},
+		{"malformed tenant policy", &piiSysConfigs{value: `{"enabled":`}, `{}`, false, true},
+		{"malformed agent policy", &piiSysConfigs{}, `{"pii_redaction":{"enabled":"yes"}}`, false, true},
+		{"malformed other_config", &piiSysConfigs{}, `{"pii_redaction":`, false, true},
+		{"invalid pattern", &piiSysConfigs{}, `{"pii_redaction":{"enabled":true,"patterns":[{"class":"x","pattern":"("}]}}`, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ag := &AgentData{TenantID: uuid.New(), OtherConfig: []byte(tt.otherConfig)}
+			r, err := ResolvePIIRedactor(context.Background(), tt.sys, ag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (r != nil) != tt.wantOn {
+				t.Errorf("redactor = %v, want enabled=%v", r, tt.wantOn)
+			}
+		})
+	}
+}
@@ -33,6 +33,10 @@ const (
 	ProviderNovita          = "novita"          // Novita AI (OpenAI-compatible endpoint)
 	ProviderBytePlus        = "byteplus"        // BytePlus ModelArk (Seed 2.0 models)
 	ProviderBytePlusCoding  = "byteplus_coding" // BytePlus ModelArk Coding Plan
+	ProviderBedrock         = "bedrock"         // AWS Bedrock Converse API (SigV4 or Bedrock API key)
+	ProviderAzureOpenAI     = "azure_openai"    // Azure OpenAI / AI Foundry deployments (api-key or Entra ID)
+	ProviderVertexAI        = "vertex_ai"       // Google Vertex AI Gemini (service-account JSON key)
+	ProviderReplay          = "replay"          // serves recorded cassettes (offline tests, trace replay)
 
 	// Novita AI defaults.
 	NovitaDefaultAPIBase = "https://api.novita.ai/openai"
@@ -70,6 +74,10 @@ var ValidProviderTypes = map[string]bool{
 	ProviderNovita:          true,
 	ProviderBytePlus:        true,
 	ProviderBytePlusCoding:  true,
+	ProviderBedrock:         true,
+	ProviderAzureOpenAI:     true,
+	ProviderVertexAI:        true,
+	ProviderReplay:          true,
 }
 
 // LLMProviderData represents an LLM provider configuration.
@@ -109,6 +117,104 @@ type ChatGPTOAuthProviderSettings struct {
 	CodexPool *ChatGPTOAuthRoutingConfig `json:"codex_pool,omitempty" db:"-"`
 }
 
+// BedrockSettings holds AWS Bedrock configuration stored in provider settings JSONB.
+// Credentials live in the encrypted api_key column ("ACCESS_KEY_ID:SECRET" or a
+// Bedrock API key); an empty key uses the AWS default credential chain.
+type BedrockSettings struct {
+	Region string `json:"region,omitempty" db:"-"` // e.g. "us-east-1"; falls back to api_base host
+}
+
+// ParseBedrockSettings extracts Bedrock config from a provider's settings JSONB.
+// Returns nil if not configured.
+func ParseBedrockSettings(settings json.RawMessage) *BedrockSettings {
+	if len(settings) == 0 {
+		return nil
+	}
+	var s struct {
+		Bedrock *BedrockSettings `json:"bedrock"`
+	}
+	if json.Unmarshal(settings, &s) != nil || s.Bedrock == nil {
+		return nil
+	}
+	return s.Bedrock
+}
+
+// Azure OpenAI auth modes.
+const (
+	AzureAuthAPIKey = "api_key" // api-key header with the resource key (default)
+	AzureAuthEntra  = "entra"   // Entra ID client credentials; api_key column holds the client secret
+)
+
+// AzureOpenAISettings holds Azure OpenAI configuration stored in provider settings JSONB.
+// The resource endpoint lives in api_base; the api_key column holds the resource key
+// or, with auth_mode "entra", the service principal's client secret.
+type AzureOpenAISettings struct {
+	APIVersion  string            `json:"api_version,omitempty" db:"-"` // default "2024-10-21"
+	Deployments map[string]string `json:"deployments,omitempty" db:"-"` // model → deployment name
+	AuthMode    string            `json:"auth_mode,omitempty" db:"-"`   // "api_key" (default) or "entra"
+	TenantID    string            `json:"tenant_id,omitempty" db:"-"`
+	ClientID    string            `json:"client_id,omitempty" db:"-"`
+}
+
+// ParseAzureOpenAISettings extracts Azure OpenAI config from a provider's settings JSONB.
+// Returns nil if not configured.
+func ParseAzureOpenAISettings(settings json.RawMessage) *AzureOpenAISettings {
+	if len(settings) == 0 {
+		return nil
+	}
+	var s struct {
+		Azure *AzureOpenAISettings `json:"azure"`
+	}
+	if json.Unmarshal(settings, &s) != nil || s.Azure == nil {
+		return nil
+	}
+	return s.Azure
+}
+
+// VertexSettings holds Google Vertex AI configuration stored in provider settings JSONB.
+// The api_key column holds the service-account JSON key.
+type VertexSettings struct {
+	ProjectID string `json:"project_id,omitempty" db:"-"` // falls back to the key's project_id
+	Location  string `json:"location,omitempty" db:"-"`   // e.g. "us-central1" or "global"
+}
+
+// ParseVertexSettings extracts Vertex AI config from a provider's settings JSONB.
+// Returns nil if not configured.
+func ParseVertexSettings(settings json.RawMessage) *VertexSettings {
+	if len(settings) == 0 {
+		return nil
+	}
+	var s struct {
+		Vertex *VertexSettings `json:"vertex"`
+	}
+	if json.Unmarshal(settings, &s) != nil || s.Vertex == nil {
+		return nil
+	}
+	return s.Vertex
+}
+
+// ReplaySettings holds replay provider configuration stored in provider settings JSONB.
+// The api_base column holds the cassette directory relative to GOCLAW_CASSETTE_DIR
+// (empty = the provider name, matching where recording writes it).
+type ReplaySettings struct {
+	Mode string `json:"mode,omitempty" db:"-"` // "strict" (default) or "fuzzy"
+}
+
+// ParseReplaySettings extracts replay config from a provider's settings JSONB.
+// Returns nil if not configured.
+func ParseReplaySettings(settings json.RawMessage) *ReplaySettings {
+	if len(settings) == 0 {
+		return nil
+	}
+	var s struct {
+		Replay *ReplaySettings `json:"replay"`
+	}
+	if json.Unmarshal(settings, &s) != nil || s.Replay == nil {
+		return nil
+	}
+	return s.Replay
+}
+
 // ParseEmbeddingSettings extracts embedding config from a provider's settings JSONB.
 // Returns nil if not configured.
 func ParseEmbeddingSettings(settings json.RawMessage) *EmbeddingSettings {
@@ -179,6 +285,10 @@ var NoEmbeddingTypes = map[string]bool{
 	ProviderACP:             true,
 	ProviderClaudeCLI:       true,
 	ProviderChatGPTOAuth:    true,
+	ProviderBedrock:         true, // SigV4 auth; Titan/Cohere embeddings use InvokeModel, not /embeddings
+	ProviderAzureOpenAI:     true, // embeddings are deployment-scoped, not served at {api_base}/embeddings
+	ProviderVertexAI:        true, // OAuth bearer from a service-account key; no OpenAI-style /embeddings
+	ProviderReplay:          true,
 }
 
 // ProviderStore manages LLM providers.
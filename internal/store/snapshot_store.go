@@ -31,6 +31,7 @@ type UsageSnapshot struct {
 	MemoryChunks      int        `json:"memory_chunks" db:"memory_chunks"`
 	KGEntities        int        `json:"kg_entities" db:"kg_entities"`
 	KGRelations       int        `json:"kg_relations" db:"kg_relations"`
+	BaselineCost      float64    `json:"baseline_cost" db:"baseline_cost"` // cost on each agent's own model (≠ total_cost only for routed turns)
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
 }
 
@@ -79,6 +80,10 @@ type SnapshotBreakdown struct {
 	ToolCallCount     int     `json:"tool_call_count" db:"tool_call_count"`
 	ErrorCount        int     `json:"error_count" db:"error_count"`
 	AvgDurationMS     int     `json:"avg_duration_ms" db:"avg_duration_ms"`
+	BaselineCost      float64 `json:"baseline_cost" db:"baseline_cost"`
+	// RoutingSavings is BaselineCost - TotalCost: what model routing saved
+	// (negative when escalations cost more than cheap tiers saved).
+	RoutingSavings float64 `json:"routing_savings" db:"-"`
 }
 
 // SnapshotStore manages pre-computed usage snapshots.
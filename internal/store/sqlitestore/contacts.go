@@ -5,6 +5,7 @@ package sqlitestore
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -306,3 +307,102 @@ func (s *SQLiteContactStore) ResolveTenantUserID(ctx context.Context, channelTyp
 	}
 	return tenantUserID, err
 }
+
+func (s *SQLiteContactStore) GetContactTags(ctx context.Context, channelType, senderID string) ([]string, error) {
+	tid := store.TenantIDFromContext(ctx)
+	var raw sql.NullString
+	err := s.db.QueryRowContext(ctx,
+		`SELECT json_extract(metadata, '$.tags') FROM channel_contacts
+		 WHERE tenant_id = ? AND channel_type = ? AND sender_id = ? AND COALESCE(thread_id, '') = ''`,
+		tid, channelType, senderID,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || !raw.Valid || raw.String == "" {
+		return nil, err
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(raw.String), &tags); err != nil {
+		return nil, fmt.Errorf("decode contact tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *SQLiteContactStore) SetContactTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE channel_contacts SET metadata = json_set(COALESCE(metadata, '{}'), '$.tags', json(?))
+		 WHERE id = ? AND tenant_id = ?`,
+		string(data), id, store.TenantIDFromContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// contactRoutePath is the JSON path of an instance's entry in metadata.routing.
+func contactRoutePath(instance string) string {
+	return `$."` + strings.ReplaceAll(instance, `"`, `\"`) + `"`
+}
+
+func (s *SQLiteContactStore) GetContactRoute(ctx context.Context, key store.ContactRouteKey) (*store.ContactRoute, error) {
+	var raw sql.NullString
+	err := s.db.QueryRowContext(ctx,
+		`SELECT json_extract(json_extract(metadata, '$.routing'), ?) FROM channel_contacts
+		 WHERE tenant_id = ? AND channel_type = ? AND sender_id = ? AND COALESCE(thread_id, '') = ?`,
+		contactRoutePath(key.ChannelInstance), store.TenantIDFromContext(ctx), key.ChannelType, key.SenderID, key.ThreadID,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || !raw.Valid || raw.String == "" {
+		return nil, err
+	}
+	var route store.ContactRoute
+	if err := json.Unmarshal([]byte(raw.String), &route); err != nil {
+		return nil, fmt.Errorf("decode contact route: %w", err)
+	}
+	if route.Agent == "" {
+		return nil, nil
+	}
+	return &route, nil
+}
+
+func (s *SQLiteContactStore) SetContactRoute(ctx context.Context, key store.ContactRouteKey, route *store.ContactRoute) error {
+	tid := store.TenantIDFromContext(ctx)
+	path := contactRoutePath(key.ChannelInstance)
+	if route == nil {
+		_, err := s.db.ExecContext(ctx,
+			`UPDATE channel_contacts SET metadata = json_set(metadata, '$.routing', json_remove(json_extract(metadata, '$.routing'), ?))
+			 WHERE tenant_id = ? AND channel_type = ? AND sender_id = ? AND COALESCE(thread_id, '') = ?
+			   AND json_extract(metadata, '$.routing') IS NOT NULL`,
+			path, tid, key.ChannelType, key.SenderID, key.ThreadID,
+		)
+		return err
+	}
+	data, err := json.Marshal(route)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO channel_contacts (id, channel_type, channel_instance, sender_id, peer_kind, contact_type, thread_id, tenant_id, metadata)
+		VALUES (?, ?, NULLIF(?,''), ?, NULLIF(?,''), ?, NULLIF(?,''), ?, json_object('routing', json_set('{}', ?, json(?))))
+		ON CONFLICT (tenant_id, channel_type, sender_id, COALESCE(thread_id, '')) DO UPDATE SET
+			metadata = json_set(COALESCE(channel_contacts.metadata, '{}'), '$.routing',
+				json_set(COALESCE(json_extract(channel_contacts.metadata, '$.routing'), '{}'), ?, json(?)))`,
+		store.GenNewID(), key.ChannelType, key.ChannelInstance, key.SenderID, key.PeerKind, key.ContactType, key.ThreadID, tid,
+		path, string(data), path, string(data),
+	)
+	return err
+}
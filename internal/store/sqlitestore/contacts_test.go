@@ -0,0 +1,102 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestContactStore_Tags(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	s := NewSQLiteContactStore(db)
+	ctx := store.WithTenantID(context.Background(), store.MasterTenantID)
+
+	id := uuid.New()
+	if _, err := db.Exec(`INSERT INTO channel_contacts (id, channel_type, sender_id, contact_type, tenant_id) VALUES (?, 'telegram', '42', 'user', ?)`,
+		id, store.MasterTenantID); err != nil {
+		t.Fatalf("insert contact: %v", err)
+	}
+	if tags, err := s.GetContactTags(ctx, "telegram", "42"); err != nil || tags != nil {
+		t.Fatalf("GetContactTags before set = %v, %v; want nil", tags, err)
+	}
+
+	if err := s.SetContactTags(ctx, id, []string{"vip", "beta"}); err != nil {
+		t.Fatalf("SetContactTags: %v", err)
+	}
+	tags, err := s.GetContactTags(ctx, "telegram", "42")
+	if err != nil || !slices.Equal(tags, []string{"vip", "beta"}) {
+		t.Fatalf("GetContactTags = %v, %v", tags, err)
+	}
+
+	other := store.WithTenantID(context.Background(), uuid.New())
+	if tags, _ := s.GetContactTags(other, "telegram", "42"); tags != nil {
+		t.Errorf("tags leaked to another tenant: %v", tags)
+	}
+
+	if err := s.SetContactTags(ctx, uuid.New(), []string{"x"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("SetContactTags(missing) = %v; want ErrNoRows", err)
+	}
+}
+
+func TestContactStore_Route(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	s := NewSQLiteContactStore(db)
+	ctx := store.WithTenantID(context.Background(), store.MasterTenantID)
+
+	id := uuid.New()
+	if _, err := db.Exec(`INSERT INTO channel_contacts (id, channel_type, sender_id, contact_type, tenant_id, metadata) VALUES (?, 'telegram', '42', 'user', ?, '{"tags":["vip"]}')`,
+		id, store.MasterTenantID); err != nil {
+		t.Fatalf("insert contact: %v", err)
+	}
+	dm := store.ContactRouteKey{ChannelType: "telegram", ChannelInstance: "support-bot", SenderID: "42", PeerKind: "direct", ContactType: "user"}
+	if r, err := s.GetContactRoute(ctx, dm); err != nil || r != nil {
+		t.Fatalf("GetContactRoute before set = %v, %v; want nil", r, err)
+	}
+
+	at := time.Now().UTC().Truncate(time.Second)
+	if err := s.SetContactRoute(ctx, dm, &store.ContactRoute{Agent: "billing", RoutedAt: at}); err != nil {
+		t.Fatalf("SetContactRoute: %v", err)
+	}
+	if r, err := s.GetContactRoute(ctx, dm); err != nil || r == nil || r.Agent != "billing" || !r.RoutedAt.Equal(at) {
+		t.Fatalf("GetContactRoute = %+v, %v; want billing at %v", r, err, at)
+	}
+	if tags, _ := s.GetContactTags(ctx, "telegram", "42"); !slices.Equal(tags, []string{"vip"}) {
+		t.Errorf("tags after SetContactRoute = %v; want [vip]", tags)
+	}
+	other := dm
+	other.ChannelInstance = "sales-bot"
+	if r, _ := s.GetContactRoute(ctx, other); r != nil {
+		t.Errorf("route leaked to another instance: %+v", r)
+	}
+
+	// A group topic without a contact row gets one.
+	topic := store.ContactRouteKey{ChannelType: "telegram", ChannelInstance: "support-bot", SenderID: "-100", ThreadID: "3", PeerKind: "group", ContactType: "topic"}
+	if err := s.SetContactRoute(ctx, topic, &store.ContactRoute{Agent: "designer", RoutedAt: at}); err != nil {
+		t.Fatalf("SetContactRoute(topic): %v", err)
+	}
+	if r, _ := s.GetContactRoute(ctx, topic); r == nil || r.Agent != "designer" {
+		t.Errorf("topic route = %+v; want designer", r)
+	}
+
+	if err := s.SetContactRoute(ctx, dm, nil); err != nil {
+		t.Fatalf("clear route: %v", err)
+	}
+	if r, _ := s.GetContactRoute(ctx, dm); r != nil {
+		t.Errorf("route after clear = %+v; want nil", r)
+	}
+}
@@ -71,5 +71,7 @@ func NewSQLiteStores(cfg store.StoreConfig) (*store.Stores, error) {
 		KnowledgeGraph:       NewSQLiteKnowledgeGraphStore(db),
 		Vault:                NewSQLiteVaultStore(db),
 		Hooks:                NewSQLiteHookStore(db),
+		LLMBatches:           NewSQLiteLLMBatchStore(db),
+		Outbox:               NewSQLiteOutboxStore(db),
 	}, nil
 }
@@ -0,0 +1,221 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// SQLiteLLMBatchStore implements store.LLMBatchStore backed by SQLite.
+type SQLiteLLMBatchStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteLLMBatchStore creates a new SQLite-backed LLM batch store.
+func NewSQLiteLLMBatchStore(db *sql.DB) *SQLiteLLMBatchStore {
+	return &SQLiteLLMBatchStore{db: db}
+}
+
+const llmBatchItemColumns = `id, tenant_id, job_id, kind, provider, model, request, payload,
+	status, response, error, pii_vault, created_at, updated_at`
+
+func (s *SQLiteLLMBatchStore) EnqueueItem(ctx context.Context, item *store.LLMBatchItem) error {
+	if item.TenantID == uuid.Nil {
+		return fmt.Errorf("llm_batch.EnqueueItem: tenant_id required")
+	}
+	if item.ID == uuid.Nil {
+		item.ID = store.GenNewID()
+	}
+	if item.Status == "" {
+		item.Status = store.LLMBatchItemQueued
+	}
+	now := time.Now().UTC()
+	item.CreatedAt, item.UpdatedAt = now, now
+	ts := now.Format(time.RFC3339Nano)
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO llm_batch_items (id, tenant_id, kind, provider, model, request, payload, status, pii_vault, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.ID.String(), item.TenantID.String(), item.Kind, item.Provider, item.Model,
+		string(item.Request), nullJSONText(item.Payload), item.Status, nullJSONText(item.PIIVault), ts, ts)
+	return err
+}
+
+func (s *SQLiteLLMBatchStore) ListItemsByStatus(ctx context.Context, status string, limit int) ([]store.LLMBatchItem, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+llmBatchItemColumns+` FROM llm_batch_items
+		 WHERE status = ? ORDER BY created_at LIMIT ?`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanLLMBatchItems(rows)
+}
+
+func (s *SQLiteLLMBatchStore) CreateJob(ctx context.Context, job *store.LLMBatchJob, itemIDs []uuid.UUID) error {
+	if job.ID == uuid.Nil {
+		job.ID = store.GenNewID()
+	}
+	if job.Status == "" {
+		job.Status = store.LLMBatchJobInProgress
+	}
+	if job.SubmittedAt.IsZero() {
+		job.SubmittedAt = time.Now().UTC()
+	}
+	job.ItemCount = len(itemIDs)
+	ts := job.SubmittedAt.UTC().Format(time.RFC3339Nano)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO llm_batch_jobs (id, tenant_id, provider, provider_batch_id, status, item_count, submitted_at, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID.String(), job.TenantID.String(), job.Provider, job.ProviderBatchID,
+		job.Status, job.ItemCount, ts, ts, ts); err != nil {
+		return err
+	}
+	for _, id := range itemIDs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE llm_batch_items SET job_id = ?, status = ?, updated_at = ? WHERE id = ?`,
+			job.ID.String(), store.LLMBatchItemSubmitted, ts, id.String()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteLLMBatchStore) MarkJobSubmitted(ctx context.Context, jobID uuid.UUID, providerBatchID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE llm_batch_jobs SET provider_batch_id = ?, status = ?, updated_at = ? WHERE id = ?`,
+		providerBatchID, store.LLMBatchJobInProgress, time.Now().UTC().Format(time.RFC3339Nano), jobID.String())
+	return err
+}
+
+func (s *SQLiteLLMBatchStore) ListActiveJobs(ctx context.Context) ([]store.LLMBatchJob, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, tenant_id, provider, provider_batch_id, status, item_count, error, submitted_at, completed_at
+		 FROM llm_batch_jobs WHERE status IN (?, ?) ORDER BY submitted_at`,
+		store.LLMBatchJobSubmitting, store.LLMBatchJobInProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []store.LLMBatchJob
+	for rows.Next() {
+		var j store.LLMBatchJob
+		var idStr, tenantStr string
+		var submittedAt sqliteTime
+		var completedAt nullSqliteTime
+		if err := rows.Scan(&idStr, &tenantStr, &j.Provider, &j.ProviderBatchID, &j.Status,
+			&j.ItemCount, &j.Error, &submittedAt, &completedAt); err != nil {
+			return nil, err
+		}
+		j.ID, _ = uuid.Parse(idStr)
+		j.TenantID, _ = uuid.Parse(tenantStr)
+		j.SubmittedAt = submittedAt.Time
+		if completedAt.Valid {
+			t := completedAt.Time
+			j.CompletedAt = &t
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *SQLiteLLMBatchStore) ListJobItems(ctx context.Context, jobID uuid.UUID) ([]store.LLMBatchItem, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+llmBatchItemColumns+` FROM llm_batch_items WHERE job_id = ? ORDER BY created_at`, jobID.String())
+	if err != nil {
+		return nil, err
+	}
+	return scanLLMBatchItems(rows)
+}
+
+func (s *SQLiteLLMBatchStore) FinishJob(ctx context.Context, jobID uuid.UUID, status, errMsg string) error {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE llm_batch_jobs SET status = ?, error = ?, completed_at = ?, updated_at = ? WHERE id = ?`,
+		status, errMsg, now, now, jobID.String())
+	return err
+}
+
+func (s *SQLiteLLMBatchStore) UpdateItem(ctx context.Context, id uuid.UUID, status string, response json.RawMessage, errMsg string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE llm_batch_items SET status = ?, response = COALESCE(?, response), error = ?, updated_at = ? WHERE id = ?`,
+		status, nullJSONText(response), errMsg, time.Now().UTC().Format(time.RFC3339Nano), id.String())
+	return err
+}
+
+func (s *SQLiteLLMBatchStore) PruneFinished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339Nano)
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM llm_batch_items WHERE status = ? AND updated_at < ?`,
+		store.LLMBatchItemDelivered, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	_, err = s.db.ExecContext(ctx,
+		`DELETE FROM llm_batch_jobs WHERE status NOT IN (?, ?) AND updated_at < ?
+		 AND NOT EXISTS (SELECT 1 FROM llm_batch_items i WHERE i.job_id = llm_batch_jobs.id)`,
+		store.LLMBatchJobSubmitting, store.LLMBatchJobInProgress, cutoff)
+	return n, err
+}
+
+func scanLLMBatchItems(rows *sql.Rows) ([]store.LLMBatchItem, error) {
+	defer rows.Close()
+	var items []store.LLMBatchItem
+	for rows.Next() {
+		var it store.LLMBatchItem
+		var idStr, tenantStr string
+		var jobStr, payload, response, vault sql.NullString
+		var request string
+		var createdAt, updatedAt sqliteTime
+		if err := rows.Scan(&idStr, &tenantStr, &jobStr, &it.Kind, &it.Provider, &it.Model,
+			&request, &payload, &it.Status, &response, &it.Error, &vault, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		it.ID, _ = uuid.Parse(idStr)
+		it.TenantID, _ = uuid.Parse(tenantStr)
+		if jobStr.Valid {
+			if jid, err := uuid.Parse(jobStr.String); err == nil {
+				it.JobID = &jid
+			}
+		}
+		it.Request = json.RawMessage(request)
+		if payload.Valid {
+			it.Payload = json.RawMessage(payload.String)
+		}
+		if response.Valid {
+			it.Response = json.RawMessage(response.String)
+		}
+		if vault.Valid {
+			it.PIIVault = json.RawMessage(vault.String)
+		}
+		it.CreatedAt = createdAt.Time
+		it.UpdatedAt = updatedAt.Time
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// nullJSONText maps an empty raw JSON value to SQL NULL for nullable TEXT JSON columns.
+func nullJSONText(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,101 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestLLMBatchStore_Lifecycle(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	s := NewSQLiteLLMBatchStore(db)
+	ctx := context.Background()
+
+	var ids []uuid.UUID
+	for _, kind := range []string{"episodic.summarize", "dreaming.synthesize"} {
+		item := &store.LLMBatchItem{
+			TenantID: store.MasterTenantID,
+			Kind:     kind,
+			Provider: "anthropic",
+			Model:    "claude-haiku",
+			Request:  json.RawMessage(`{"messages":[]}`),
+		}
+		if err := s.EnqueueItem(ctx, item); err != nil {
+			t.Fatalf("EnqueueItem: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+
+	queued, err := s.ListItemsByStatus(ctx, store.LLMBatchItemQueued, 10)
+	if err != nil || len(queued) != 2 {
+		t.Fatalf("ListItemsByStatus(queued) = %d, %v", len(queued), err)
+	}
+	if queued[0].Payload != nil || string(queued[0].Request) != `{"messages":[]}` {
+		t.Errorf("queued[0] = %+v", queued[0])
+	}
+
+	job := &store.LLMBatchJob{TenantID: store.MasterTenantID, Provider: "anthropic", Status: store.LLMBatchJobSubmitting}
+	if err := s.CreateJob(ctx, job, ids); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	active, err := s.ListActiveJobs(ctx)
+	if err != nil || len(active) != 1 || active[0].Status != store.LLMBatchJobSubmitting {
+		t.Fatalf("ListActiveJobs(submitting) = %+v, %v", active, err)
+	}
+	if err := s.MarkJobSubmitted(ctx, job.ID, "msgbatch_1"); err != nil {
+		t.Fatalf("MarkJobSubmitted: %v", err)
+	}
+	active, err = s.ListActiveJobs(ctx)
+	if err != nil || len(active) != 1 || active[0].ItemCount != 2 || active[0].ProviderBatchID != "msgbatch_1" ||
+		active[0].Status != store.LLMBatchJobInProgress {
+		t.Fatalf("ListActiveJobs = %+v, %v", active, err)
+	}
+	items, err := s.ListJobItems(ctx, job.ID)
+	if err != nil || len(items) != 2 || items[0].Status != store.LLMBatchItemSubmitted || *items[0].JobID != job.ID {
+		t.Fatalf("ListJobItems = %+v, %v", items, err)
+	}
+
+	if err := s.UpdateItem(ctx, ids[0], store.LLMBatchItemCompleted, json.RawMessage(`{"content":"ok"}`), ""); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if err := s.UpdateItem(ctx, ids[1], store.LLMBatchItemFailed, nil, "expired"); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if err := s.FinishJob(ctx, job.ID, store.LLMBatchJobEnded, ""); err != nil {
+		t.Fatalf("FinishJob: %v", err)
+	}
+	if active, _ := s.ListActiveJobs(ctx); len(active) != 0 {
+		t.Errorf("active jobs after finish = %d", len(active))
+	}
+	completed, _ := s.ListItemsByStatus(ctx, store.LLMBatchItemCompleted, 10)
+	if len(completed) != 1 || string(completed[0].Response) != `{"content":"ok"}` {
+		t.Fatalf("completed = %+v", completed)
+	}
+
+	// Delivering keeps the stored response (COALESCE on nil).
+	if err := s.UpdateItem(ctx, ids[0], store.LLMBatchItemDelivered, nil, ""); err != nil {
+		t.Fatalf("UpdateItem(delivered): %v", err)
+	}
+	delivered, _ := s.ListItemsByStatus(ctx, store.LLMBatchItemDelivered, 10)
+	if len(delivered) != 1 || string(delivered[0].Response) != `{"content":"ok"}` {
+		t.Fatalf("delivered = %+v", delivered)
+	}
+
+	n, err := s.PruneFinished(ctx, -time.Minute)
+	if err != nil || n != 1 {
+		t.Fatalf("PruneFinished = %d, %v", n, err)
+	}
+	if failed, _ := s.ListItemsByStatus(ctx, store.LLMBatchItemFailed, 10); len(failed) != 1 {
+		t.Errorf("undelivered failed item must survive prune, got %d", len(failed))
+	}
+}
@@ -0,0 +1,231 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// SQLiteOutboxStore implements store.OutboxStore backed by SQLite.
+type SQLiteOutboxStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteOutboxStore creates a new SQLite-backed outbox store.
+func NewSQLiteOutboxStore(db *sql.DB) *SQLiteOutboxStore {
+	return &SQLiteOutboxStore{db: db}
+}
+
+const outboxColumns = `id, tenant_id, channel, chat_id, payload, attempts, next_attempt_at, last_error, created_at, updated_at`
+
+const deadLetterColumns = `id, tenant_id, channel, chat_id, payload, attempts, last_error, created_at, failed_at`
+
+func (s *SQLiteOutboxStore) Enqueue(ctx context.Context, msg *store.OutboxMessage) error {
+	if msg.ID == uuid.Nil {
+		msg.ID = store.GenNewID()
+	}
+	if msg.TenantID == uuid.Nil {
+		msg.TenantID = store.MasterTenantID
+	}
+	now := time.Now().UTC()
+	msg.CreatedAt, msg.UpdatedAt = now, now
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = now
+	}
+	ts := now.Format(time.RFC3339Nano)
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO channel_outbox (id, tenant_id, channel, chat_id, payload, attempts, next_attempt_at, last_error, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID.String(), msg.TenantID.String(), msg.Channel, msg.ChatID, string(msg.Payload),
+		msg.Attempts, msg.NextAttemptAt.UTC().Format(time.RFC3339Nano), msg.LastError, ts, ts)
+	return err
+}
+
+func (s *SQLiteOutboxStore) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]store.OutboxMessage, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	nowTS := now.UTC().Format(time.RFC3339Nano)
+	leaseTS := now.Add(lease).UTC().Format(time.RFC3339Nano)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+outboxColumns+` FROM channel_outbox
+		 WHERE next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?`, nowTS, limit)
+	if err != nil {
+		return nil, err
+	}
+	var msgs []store.OutboxMessage
+	for rows.Next() {
+		var m store.OutboxMessage
+		var idStr, tenantStr, payload string
+		var nextAt, createdAt, updatedAt sqliteTime
+		if err := rows.Scan(&idStr, &tenantStr, &m.Channel, &m.ChatID, &payload, &m.Attempts,
+			&nextAt, &m.LastError, &createdAt, &updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m.ID, _ = uuid.Parse(idStr)
+		m.TenantID, _ = uuid.Parse(tenantStr)
+		m.Payload = json.RawMessage(payload)
+		m.NextAttemptAt = now.Add(lease).UTC()
+		m.CreatedAt = createdAt.Time
+		m.UpdatedAt = now.UTC()
+		msgs = append(msgs, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range msgs {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE channel_outbox SET next_attempt_at = ?, updated_at = ? WHERE id = ?`,
+			leaseTS, nowTS, m.ID.String()); err != nil {
+			return nil, err
+		}
+	}
+	return msgs, tx.Commit()
+}
+
+func (s *SQLiteOutboxStore) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM channel_outbox WHERE id = ?`, id.String())
+	return err
+}
+
+func (s *SQLiteOutboxStore) Reschedule(ctx context.Context, id uuid.UUID, attempts int, next time.Time, lastErr string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE channel_outbox SET attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		attempts, next.UTC().Format(time.RFC3339Nano), lastErr, time.Now().UTC().Format(time.RFC3339Nano), id.String())
+	return err
+}
+
+func (s *SQLiteOutboxStore) MoveToDeadLetter(ctx context.Context, id uuid.UUID, attempts int, lastErr string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO channel_dead_letters (id, tenant_id, channel, chat_id, payload, attempts, last_error, created_at, failed_at)
+		 SELECT id, tenant_id, channel, chat_id, payload, ?, ?, created_at, ? FROM channel_outbox WHERE id = ?`,
+		attempts, lastErr, time.Now().UTC().Format(time.RFC3339Nano), id.String()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_outbox WHERE id = ?`, id.String()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteOutboxStore) ListDeadLetters(ctx context.Context, tenantID uuid.UUID, channel string, limit, offset int) ([]store.OutboxDeadLetter, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	q := `SELECT ` + deadLetterColumns + ` FROM channel_dead_letters WHERE 1=1`
+	var args []any
+	if tenantID != uuid.Nil {
+		q += ` AND tenant_id = ?`
+		args = append(args, tenantID.String())
+	}
+	if channel != "" {
+		q += ` AND channel = ?`
+		args = append(args, channel)
+	}
+	q += ` ORDER BY failed_at DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []store.OutboxDeadLetter
+	for rows.Next() {
+		d, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLiteOutboxStore) GetDeadLetter(ctx context.Context, id uuid.UUID) (*store.OutboxDeadLetter, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT `+deadLetterColumns+` FROM channel_dead_letters WHERE id = ?`, id.String())
+	return scanDeadLetter(row)
+}
+
+func (s *SQLiteOutboxStore) ReplayDeadLetter(ctx context.Context, id uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO channel_outbox (id, tenant_id, channel, chat_id, payload, attempts, next_attempt_at, last_error, created_at, updated_at)
+		 SELECT id, tenant_id, channel, chat_id, payload, 0, ?, last_error, created_at, ? FROM channel_dead_letters WHERE id = ?`,
+		now, now, id.String())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_dead_letters WHERE id = ?`, id.String()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteOutboxStore) DeleteDeadLetter(ctx context.Context, id uuid.UUID) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM channel_dead_letters WHERE id = ?`, id.String())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (s *SQLiteOutboxStore) PruneDeadLetters(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().UTC().Add(-olderThan).Format(time.RFC3339Nano)
+	res, err := s.db.ExecContext(ctx, `DELETE FROM channel_dead_letters WHERE failed_at < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanDeadLetter(row interface{ Scan(...any) error }) (*store.OutboxDeadLetter, error) {
+	var d store.OutboxDeadLetter
+	var idStr, tenantStr, payload string
+	var createdAt, failedAt sqliteTime
+	if err := row.Scan(&idStr, &tenantStr, &d.Channel, &d.ChatID, &payload, &d.Attempts,
+		&d.LastError, &createdAt, &failedAt); err != nil {
+		return nil, err
+	}
+	d.ID, _ = uuid.Parse(idStr)
+	d.TenantID, _ = uuid.Parse(tenantStr)
+	d.Payload = json.RawMessage(payload)
+	d.CreatedAt = createdAt.Time
+	d.FailedAt = failedAt.Time
+	return &d, nil
+}
@@ -0,0 +1,86 @@
+//go:build sqlite || sqliteonly
+
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+func TestOutboxStore_RetryAndDeadLetter(t *testing.T) {
+	db := openTestDB(t)
+	if err := EnsureSchema(db); err != nil {
+		t.Fatalf("EnsureSchema: %v", err)
+	}
+	s := NewSQLiteOutboxStore(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	due := &store.OutboxMessage{Channel: "telegram", ChatID: "42", Payload: json.RawMessage(`{"content":"hi"}`)}
+	later := &store.OutboxMessage{Channel: "slack", ChatID: "C1", Payload: json.RawMessage(`{}`), NextAttemptAt: now.Add(time.Hour)}
+	for _, m := range []*store.OutboxMessage{due, later} {
+		if err := s.Enqueue(ctx, m); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	if due.TenantID != store.MasterTenantID {
+		t.Errorf("tenant = %s; want master", due.TenantID)
+	}
+
+	claimed, err := s.ClaimDue(ctx, now.Add(time.Second), time.Minute, 10)
+	if err != nil || len(claimed) != 1 || claimed[0].ID != due.ID || string(claimed[0].Payload) != `{"content":"hi"}` {
+		t.Fatalf("ClaimDue = %+v, %v", claimed, err)
+	}
+	// Claimed rows are leased and not handed out again.
+	if again, _ := s.ClaimDue(ctx, now.Add(2*time.Second), time.Minute, 10); len(again) != 0 {
+		t.Fatalf("leased row claimed again: %+v", again)
+	}
+
+	if err := s.Reschedule(ctx, due.ID, 1, now, "429 too many requests"); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	claimed, _ = s.ClaimDue(ctx, now.Add(time.Second), time.Minute, 10)
+	if len(claimed) != 1 || claimed[0].Attempts != 1 || claimed[0].LastError != "429 too many requests" {
+		t.Fatalf("rescheduled row = %+v", claimed)
+	}
+
+	if err := s.MoveToDeadLetter(ctx, due.ID, 2, "chat not found"); err != nil {
+		t.Fatalf("MoveToDeadLetter: %v", err)
+	}
+	dead, err := s.ListDeadLetters(ctx, store.MasterTenantID, "telegram", 10, 0)
+	if err != nil || len(dead) != 1 || dead[0].Attempts != 2 || dead[0].LastError != "chat not found" {
+		t.Fatalf("ListDeadLetters = %+v, %v", dead, err)
+	}
+	if other, _ := s.ListDeadLetters(ctx, uuid.New(), "", 10, 0); len(other) != 0 {
+		t.Errorf("dead letters leaked to another tenant: %+v", other)
+	}
+
+	if err := s.ReplayDeadLetter(ctx, due.ID); err != nil {
+		t.Fatalf("ReplayDeadLetter: %v", err)
+	}
+	if _, err := s.GetDeadLetter(ctx, due.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetDeadLetter after replay = %v; want ErrNoRows", err)
+	}
+	claimed, _ = s.ClaimDue(ctx, time.Now().Add(time.Second), time.Minute, 10)
+	if len(claimed) != 1 || claimed[0].ID != due.ID || claimed[0].Attempts != 0 {
+		t.Fatalf("replayed row = %+v", claimed)
+	}
+
+	if err := s.Delete(ctx, due.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.ReplayDeadLetter(ctx, due.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ReplayDeadLetter(missing) = %v; want ErrNoRows", err)
+	}
+	if err := s.DeleteDeadLetter(ctx, due.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteDeadLetter(missing) = %v; want ErrNoRows", err)
+	}
+}
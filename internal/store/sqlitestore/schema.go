@@ -16,7 +16,7 @@ var schemaSQL string
 
 // SchemaVersion is the current SQLite schema version.
 // Bump this when adding new migration steps below.
-const SchemaVersion = 24
+const SchemaVersion = 28
 
 // migrations maps version → SQL to apply when upgrading FROM that version.
 // schema.sql always represents the LATEST full schema (for fresh DBs).
@@ -496,8 +496,95 @@ CREATE TRIGGER IF NOT EXISTS trg_vault_docs_scope_consistency_upd
   BEGIN
     SELECT RAISE(ABORT, 'vault_documents_scope_consistency violation');
   END;`,
+
+	// Version 24 → 25: durable batch-API job state for background LLM workers.
+	// Mirrors PG migration 000056.
+	24: addLLMBatchTables,
+
+	// Version 25 → 26: usage_snapshots.baseline_cost for model-routing savings.
+	// Mirrors PG migration 000057.
+	25: `ALTER TABLE usage_snapshots ADD COLUMN baseline_cost NUMERIC(12,6) NOT NULL DEFAULT 0;
+UPDATE usage_snapshots SET baseline_cost = total_cost;`,
+
+	// Version 26 → 27: durable outbound outbox and dead letters for channels.
+	// Mirrors PG migration 000059.
+	26: addOutboxTables,
+
+	// Version 27 → 28: llm_batch_items.pii_vault for redacted batch requests.
+	// Mirrors PG migration 000060.
+	27: `ALTER TABLE llm_batch_items ADD COLUMN pii_vault TEXT;`,
 }
 
+// addOutboxTables is the SQLite incremental migration for schema v26 → v27.
+const addOutboxTables = `
+CREATE TABLE IF NOT EXISTS channel_outbox (
+    id              TEXT NOT NULL PRIMARY KEY,
+    tenant_id       TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel         TEXT NOT NULL,
+    chat_id         TEXT NOT NULL,
+    payload         TEXT NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_outbox_due ON channel_outbox(next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS channel_dead_letters (
+    id         TEXT NOT NULL PRIMARY KEY,
+    tenant_id  TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel    TEXT NOT NULL,
+    chat_id    TEXT NOT NULL,
+    payload    TEXT NOT NULL,
+    attempts   INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    failed_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_dead_letters_tenant ON channel_dead_letters(tenant_id, failed_at);
+`
+
+// addLLMBatchTables is the SQLite incremental migration for schema v24 → v25.
+const addLLMBatchTables = `
+CREATE TABLE IF NOT EXISTS llm_batch_jobs (
+    id                TEXT NOT NULL PRIMARY KEY,
+    tenant_id         TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    provider          TEXT NOT NULL,
+    provider_batch_id TEXT NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'in_progress',
+    item_count        INTEGER NOT NULL DEFAULT 0,
+    error             TEXT NOT NULL DEFAULT '',
+    submitted_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    completed_at      TEXT,
+    created_at        TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at        TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_batch_jobs_status ON llm_batch_jobs(status);
+
+CREATE TABLE IF NOT EXISTS llm_batch_items (
+    id         TEXT NOT NULL PRIMARY KEY,
+    tenant_id  TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    job_id     TEXT REFERENCES llm_batch_jobs(id) ON DELETE SET NULL,
+    kind       TEXT NOT NULL,
+    provider   TEXT NOT NULL,
+    model      TEXT NOT NULL DEFAULT '',
+    request    TEXT NOT NULL,
+    payload    TEXT,
+    status     TEXT NOT NULL DEFAULT 'queued',
+    response   TEXT,
+    error      TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_batch_items_status ON llm_batch_items(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_llm_batch_items_job ON llm_batch_items(job_id);
+`
+
 // addHooksTables is the SQLite incremental migration for schema v19 → v20.
 // Mirrors PG migrations 000052–000055 (consolidated — desktop never shipped
 // with intermediate agent_hooks / agent_hook_agents names).
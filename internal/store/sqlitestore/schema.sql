@@ -1135,6 +1135,7 @@ CREATE TABLE IF NOT EXISTS usage_snapshots (
     memory_chunks       INTEGER NOT NULL DEFAULT 0,
     kg_entities         INTEGER NOT NULL DEFAULT 0,
     kg_relations        INTEGER NOT NULL DEFAULT 0,
+    baseline_cost       NUMERIC(12,6) NOT NULL DEFAULT 0,
     tenant_id           TEXT NOT NULL REFERENCES tenants(id),
     created_at          TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
 );
@@ -1661,3 +1662,78 @@ CREATE TABLE IF NOT EXISTS tenant_hook_budget (
     metadata       TEXT NOT NULL DEFAULT '{}',
     updated_at     TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
 );
+
+-- ============================================================
+-- Tables: llm_batch_jobs, llm_batch_items (migration 000056)
+-- Batch-API execution path for background LLM workers.
+-- ============================================================
+
+CREATE TABLE IF NOT EXISTS llm_batch_jobs (
+    id                TEXT NOT NULL PRIMARY KEY,
+    tenant_id         TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    provider          TEXT NOT NULL,
+    provider_batch_id TEXT NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'in_progress',
+    item_count        INTEGER NOT NULL DEFAULT 0,
+    error             TEXT NOT NULL DEFAULT '',
+    submitted_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    completed_at      TEXT,
+    created_at        TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at        TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_batch_jobs_status ON llm_batch_jobs(status);
+
+CREATE TABLE IF NOT EXISTS llm_batch_items (
+    id         TEXT NOT NULL PRIMARY KEY,
+    tenant_id  TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    job_id     TEXT REFERENCES llm_batch_jobs(id) ON DELETE SET NULL,
+    kind       TEXT NOT NULL,
+    provider   TEXT NOT NULL,
+    model      TEXT NOT NULL DEFAULT '',
+    request    TEXT NOT NULL,
+    payload    TEXT,
+    status     TEXT NOT NULL DEFAULT 'queued',
+    response   TEXT,
+    error      TEXT NOT NULL DEFAULT '',
+    pii_vault  TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_batch_items_status ON llm_batch_items(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_llm_batch_items_job ON llm_batch_items(job_id);
+
+-- ============================================================
+-- Tables: channel_outbox, channel_dead_letters (migration 000059)
+-- Durable outbound delivery with retries and a dead-letter queue.
+-- ============================================================
+
+CREATE TABLE IF NOT EXISTS channel_outbox (
+    id              TEXT NOT NULL PRIMARY KEY,
+    tenant_id       TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel         TEXT NOT NULL,
+    chat_id         TEXT NOT NULL,
+    payload         TEXT NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at      TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_outbox_due ON channel_outbox(next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS channel_dead_letters (
+    id         TEXT NOT NULL PRIMARY KEY,
+    tenant_id  TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel    TEXT NOT NULL,
+    chat_id    TEXT NOT NULL,
+    payload    TEXT NOT NULL,
+    attempts   INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL,
+    failed_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_dead_letters_tenant ON channel_dead_letters(tenant_id, failed_at);
@@ -229,6 +229,17 @@ func openTestDBAtVersion(t *testing.T, targetVersion int) *sql.DB {
 	// Undo columns added by migrations after targetVersion.
 	// SQLite DROP COLUMN support varies, so recreate affected tables.
 
+	// v27 → v28 adds llm_batch_items.pii_vault.
+	if targetVersion < 28 {
+		db.Exec(`ALTER TABLE llm_batch_items DROP COLUMN pii_vault`)
+	}
+
+	// v25 → v26 adds usage_snapshots.baseline_cost (no index or constraint
+	// references it, so a plain DROP COLUMN works).
+	if targetVersion < 26 {
+		db.Exec(`ALTER TABLE usage_snapshots DROP COLUMN baseline_cost`)
+	}
+
 	// Phase 03 (v15 → v16) adds:
 	//   - team_task_attachments.base_name
 	//   - vault_documents.path_basename
@@ -23,9 +23,9 @@ func NewSQLiteSnapshotStore(db *sql.DB) *SQLiteSnapshotStore {
 	return &SQLiteSnapshotStore{db: db}
 }
 
-const snapshotFieldCount = 22
+const snapshotFieldCount = 23
 
-// sqliteSnapshotBatchSize limits each INSERT to stay under SQLite's 999-variable limit (999 / 22 ≈ 45 → use 40).
+// sqliteSnapshotBatchSize limits each INSERT to stay under SQLite's 999-variable limit (999 / 23 ≈ 43 → use 40).
 const sqliteSnapshotBatchSize = 40
 
 func (s *SQLiteSnapshotStore) UpsertSnapshots(ctx context.Context, snapshots []store.UsageSnapshot) error {
@@ -62,7 +62,7 @@ func (s *SQLiteSnapshotStore) upsertBatch(ctx context.Context, snapshots []store
 			snap.TotalCost, snap.RequestCount, snap.LLMCallCount, snap.ToolCallCount,
 			snap.ErrorCount, snap.UniqueUsers, snap.AvgDurationMS,
 			snap.MemoryDocs, snap.MemoryChunks, snap.KGEntities, snap.KGRelations,
-			tenantID,
+			snap.BaselineCost, tenantID,
 		)
 	}
 
@@ -72,7 +72,7 @@ func (s *SQLiteSnapshotStore) upsertBatch(ctx context.Context, snapshots []store
 		total_cost, request_count, llm_call_count, tool_call_count,
 		error_count, unique_users, avg_duration_ms,
 		memory_docs, memory_chunks, kg_entities, kg_relations,
-		tenant_id
+		baseline_cost, tenant_id
 	) VALUES ` + strings.Join(vals, ", ") + `
 	ON CONFLICT (bucket_hour, COALESCE(agent_id, '00000000-0000-0000-0000-000000000000'), COALESCE(provider, ''), COALESCE(model, ''), COALESCE(channel, ''), tenant_id)
 	DO UPDATE SET
@@ -91,7 +91,8 @@ func (s *SQLiteSnapshotStore) upsertBatch(ctx context.Context, snapshots []store
 		memory_docs         = excluded.memory_docs,
 		memory_chunks       = excluded.memory_chunks,
 		kg_entities         = excluded.kg_entities,
-		kg_relations        = excluded.kg_relations`
+		kg_relations        = excluded.kg_relations,
+		baseline_cost       = excluded.baseline_cost`
 
 	_, err := s.db.ExecContext(ctx, query, args...)
 	return err
@@ -218,7 +219,8 @@ func (s *SQLiteSnapshotStore) GetBreakdown(ctx context.Context, q store.Snapshot
 		CASE WHEN SUM(CASE WHEN provider = '' AND model = '' THEN request_count ELSE 0 END) > 0
 			THEN SUM(CASE WHEN provider = '' AND model = '' THEN avg_duration_ms * request_count ELSE 0 END) /
 				SUM(CASE WHEN provider = '' AND model = '' THEN request_count ELSE 0 END)
-			ELSE 0 END
+			ELSE 0 END,
+		SUM(CASE WHEN provider != '' THEN baseline_cost ELSE 0 END)
 	FROM usage_snapshots
 	%s
 	GROUP BY %s
@@ -240,9 +242,11 @@ func (s *SQLiteSnapshotStore) GetBreakdown(ctx context.Context, q store.Snapshot
 			&b.TotalCost,
 			&b.RequestCount, &b.LLMCallCount, &b.ToolCallCount,
 			&b.ErrorCount, &b.AvgDurationMS,
+			&b.BaselineCost,
 		); err != nil {
 			return nil, fmt.Errorf("scan breakdown: %w", err)
 		}
+		b.RoutingSavings = b.BaselineCost - b.TotalCost
 		result = append(result, b)
 	}
 	return result, rows.Err()
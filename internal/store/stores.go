@@ -38,6 +38,9 @@ type Stores struct {
 	Episodic               EpisodicStore
 	EvolutionMetrics       EvolutionMetricsStore
 	EvolutionSuggestions   EvolutionSuggestionStore
+	LLMBatches             LLMBatchStore
+	Outbox                 OutboxStore
+	EmbeddingIndex         EmbeddingIndexStore // nil on SQLite (no vector columns)
 	// Hooks is hooks.HookStore — typed as any to avoid import cycle
 	// (hooks package imports store for context helpers).
 	// Callers: type-assert to hooks.HookStore before use.
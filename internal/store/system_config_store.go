@@ -1,6 +1,13 @@
 package store
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrSystemConfigNotFound is returned (wrapped) by SystemConfigStore.Get when
+// the key is not set.
+var ErrSystemConfigNotFound = errors.New("system config not found")
 
 // SystemConfigStore manages per-tenant configuration settings.
 // Non-secret, plain-text key-value pairs. Use ConfigSecretsStore for secrets.
@@ -1,8 +1,10 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -85,22 +87,62 @@ const (
 	ApprovalDeny        ApprovalDecision = "deny"
 )
 
+// Approval request kinds.
+const (
+	ApprovalKindExec = "exec" // exec tool command
+	ApprovalKindHook = "hook" // hook returned an "ask" decision
+)
+
+// approvalCodeSpace is the number of distinct 4-digit approval codes.
+const approvalCodeSpace = 10000
+
+// ApprovalOrigin is the chat an approval request came from. Requests with an
+// origin are announced to the notifier so the chat can approve or deny them.
+type ApprovalOrigin struct {
+	Channel  string `json:"channel"`
+	ChatID   string `json:"chatId"`
+	PeerKind string `json:"peerKind,omitempty"`
+	LocalKey string `json:"localKey,omitempty"` // topic/thread routing
+}
+
+// ApprovalOriginFromCtx returns the chat of the current tool call, or nil when
+// the call did not come from a channel.
+func ApprovalOriginFromCtx(ctx context.Context) *ApprovalOrigin {
+	channel, chatID := ToolChannelFromCtx(ctx), ToolChatIDFromCtx(ctx)
+	if channel == "" || chatID == "" {
+		return nil
+	}
+	return &ApprovalOrigin{
+		Channel:  channel,
+		ChatID:   chatID,
+		PeerKind: ToolPeerKindFromCtx(ctx),
+		LocalKey: ToolLocalKeyFromCtx(ctx),
+	}
+}
+
 // PendingApproval is an in-flight approval request.
 type PendingApproval struct {
-	ID        string    `json:"id"`
-	Command   string    `json:"command"`
-	AgentID   string    `json:"agentId"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string          `json:"id"`
+	Code      string          `json:"code"` // short code for chat replies ("APPROVE 1234")
+	Kind      string          `json:"kind"`
+	Command   string          `json:"command"`
+	AgentID   string          `json:"agentId"`
+	Origin    *ApprovalOrigin `json:"origin,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
 	resultCh  chan ApprovalDecision
 }
 
+// ApprovalNotifier is called when a request with an origin is created.
+type ApprovalNotifier func(pa PendingApproval)
+
 // ExecApprovalManager manages pending approval requests and the dynamic allowlist.
 type ExecApprovalManager struct {
-	config       ExecApprovalConfig
-	pending      map[string]*PendingApproval
-	alwaysAllow  map[string]bool // patterns added via "allow-always" decisions
-	mu           sync.Mutex
-	nextID       int
+	config      ExecApprovalConfig
+	pending     map[string]*PendingApproval
+	alwaysAllow map[string]bool // patterns added via "allow-always" decisions
+	mu          sync.Mutex
+	nextID      int
+	notifier    ApprovalNotifier
 }
 
 // NewExecApprovalManager creates an approval manager with the given config.
@@ -148,22 +190,48 @@ func (m *ExecApprovalManager) CheckCommand(command string) string {
 	return "allow"
 }
 
+// SetNotifier sets the callback that delivers approval requests to their
+// originating chat.
+func (m *ExecApprovalManager) SetNotifier(fn ApprovalNotifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifier = fn
+}
+
 // RequestApproval creates a pending approval and blocks until resolved or timeout.
 func (m *ExecApprovalManager) RequestApproval(command, agentID string, timeout time.Duration) (ApprovalDecision, error) {
+	return m.RequestApprovalFrom(nil, ApprovalKindExec, command, agentID, timeout)
+}
+
+// RequestApprovalFrom is RequestApproval for a request raised in a chat. The
+// notifier delivers it to the chat, where it can be resolved by code.
+func (m *ExecApprovalManager) RequestApprovalFrom(origin *ApprovalOrigin, kind, command, agentID string, timeout time.Duration) (ApprovalDecision, error) {
 	m.mu.Lock()
+	code, err := m.newCodeLocked()
+	if err != nil {
+		m.mu.Unlock()
+		return ApprovalDeny, err
+	}
 	m.nextID++
 	id := fmt.Sprintf("exec-%d", m.nextID)
 	pa := &PendingApproval{
 		ID:        id,
+		Code:      code,
+		Kind:      kind,
 		Command:   command,
 		AgentID:   agentID,
+		Origin:    origin,
 		CreatedAt: time.Now(),
 		resultCh:  make(chan ApprovalDecision, 1),
 	}
 	m.pending[id] = pa
+	notify := m.notifier
 	m.mu.Unlock()
 
-	slog.Info("exec approval requested", "id", id, "command", truncateCmd(command, 100))
+	slog.Info("exec approval requested", "id", id, "kind", kind, "command", truncateCmd(command, 100))
+	if notify != nil && origin != nil {
+		go notify(*pa)
+	}
 
 	// Wait for resolution or timeout
 	select {
@@ -172,8 +240,9 @@ func (m *ExecApprovalManager) RequestApproval(command, agentID string, timeout t
 		delete(m.pending, id)
 		m.mu.Unlock()
 
-		// If allow-always, add the command's base binary to the dynamic allowlist
-		if decision == ApprovalAllowAlways {
+		// If allow-always, add the command's base binary to the dynamic allowlist.
+		// Hook approvals have no binary to allow; they are approved once.
+		if decision == ApprovalAllowAlways && kind == ApprovalKindExec {
 			bin := extractBin(command)
 			if bin != "" {
 				m.mu.Lock()
@@ -203,10 +272,42 @@ func (m *ExecApprovalManager) Resolve(id string, decision ApprovalDecision) erro
 		return fmt.Errorf("approval %q not found or already resolved", id)
 	}
 
+	// Removed here too so a second click on a chat button finds nothing.
+	delete(m.pending, id)
 	pa.resultCh <- decision
 	return nil
 }
 
+// PendingByCode returns the pending request with the given short code.
+func (m *ExecApprovalManager) PendingByCode(code string) (PendingApproval, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, pa := range m.pending {
+		if pa.Code == code {
+			return *pa, true
+		}
+	}
+	return PendingApproval{}, false
+}
+
+// newCodeLocked returns a 4-digit code not used by another pending request,
+// or an error when all 10,000 are taken. The search starts at a random code
+// and visits each code at most once. Caller must hold m.mu.
+func (m *ExecApprovalManager) newCodeLocked() (string, error) {
+	used := make(map[string]bool, len(m.pending))
+	for _, pa := range m.pending {
+		used[pa.Code] = true
+	}
+	start := rand.IntN(approvalCodeSpace)
+	for i := range approvalCodeSpace {
+		code := fmt.Sprintf("%04d", (start+i)%approvalCodeSpace)
+		if !used[code] {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("too many pending approvals (%d)", len(m.pending))
+}
+
 // ListPending returns all pending approval requests.
 func (m *ExecApprovalManager) ListPending() []*PendingApproval {
 	m.mu.Lock()
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRequestApprovalFrom_NotifiesAndResolvesByCode(t *testing.T) {
+	mgr := NewExecApprovalManager(ExecApprovalConfig{Security: ExecSecurityFull, Ask: ExecAskOnMiss})
+	notified := make(chan PendingApproval, 1)
+	mgr.SetNotifier(func(pa PendingApproval) { notified <- pa })
+
+	origin := &ApprovalOrigin{Channel: "tg", ChatID: "42", PeerKind: "direct"}
+	done := make(chan ApprovalDecision, 1)
+	go func() {
+		d, _ := mgr.RequestApprovalFrom(origin, ApprovalKindExec, "docker ps", "agent-1", time.Second)
+		done <- d
+	}()
+
+	var pa PendingApproval
+	select {
+	case pa = <-notified:
+	case <-time.After(time.Second):
+		t.Fatal("notifier not called")
+	}
+	if len(pa.Code) != 4 || pa.Origin == nil || pa.Origin.ChatID != "42" {
+		t.Fatalf("unexpected pending approval: %+v", pa)
+	}
+	got, ok := mgr.PendingByCode(pa.Code)
+	if !ok || got.ID != pa.ID {
+		t.Fatalf("PendingByCode(%q) = %+v, %v", pa.Code, got, ok)
+	}
+
+	if err := mgr.Resolve(pa.ID, ApprovalAllowAlways); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if err := mgr.Resolve(pa.ID, ApprovalDeny); err == nil {
+		t.Error("second Resolve succeeded; want not found")
+	}
+	if d := <-done; d != ApprovalAllowAlways {
+		t.Errorf("decision = %q; want allow-always", d)
+	}
+	if mgr.CheckCommand("docker images") != "allow" {
+		t.Error("allow-always did not add docker to the allowlist")
+	}
+}
+
+func TestRequestApprovalFrom_HookNeverAlwaysAllows(t *testing.T) {
+	mgr := NewExecApprovalManager(ExecApprovalConfig{Security: ExecSecurityFull, Ask: ExecAskOnMiss})
+	notified := make(chan PendingApproval, 1)
+	mgr.SetNotifier(func(pa PendingApproval) { notified <- pa })
+
+	go func() {
+		pa := <-notified
+		mgr.Resolve(pa.ID, ApprovalAllowAlways)
+	}()
+	origin := &ApprovalOrigin{Channel: "tg", ChatID: "42"}
+	if _, err := mgr.RequestApprovalFrom(origin, ApprovalKindHook, "docker ps", "agent-1", time.Second); err != nil {
+		t.Fatalf("RequestApprovalFrom: %v", err)
+	}
+	if mgr.CheckCommand("docker ps") != "ask" {
+		t.Error("hook approval added to the exec allowlist")
+	}
+}
+
+func TestRequestApproval_NoOriginSkipsNotifier(t *testing.T) {
+	mgr := NewExecApprovalManager(DefaultExecApprovalConfig())
+	mgr.SetNotifier(func(PendingApproval) { t.Error("notifier called without origin") })
+	if d, err := mgr.RequestApproval("ls", "agent-1", 20*time.Millisecond); err == nil || d != ApprovalDeny {
+		t.Errorf("RequestApproval = %q, %v; want deny on timeout", d, err)
+	}
+}
+
+func TestRequestApprovalFrom_AllCodesPending(t *testing.T) {
+	mgr := NewExecApprovalManager(DefaultExecApprovalConfig())
+	for i := range approvalCodeSpace - 1 {
+		id := fmt.Sprintf("held-%d", i)
+		mgr.pending[id] = &PendingApproval{ID: id, Code: fmt.Sprintf("%04d", i)}
+	}
+
+	// The one free code is found.
+	mgr.mu.Lock()
+	code, err := mgr.newCodeLocked()
+	mgr.mu.Unlock()
+	if err != nil || code != "9999" {
+		t.Fatalf("newCodeLocked = %q, %v; want the last free code", code, err)
+	}
+
+	mgr.pending["held-last"] = &PendingApproval{ID: "held-last", Code: "9999"}
+	d, err := mgr.RequestApprovalFrom(&ApprovalOrigin{Channel: "tg", ChatID: "42"}, ApprovalKindExec, "ls", "agent-1", time.Second)
+	if err == nil || d != ApprovalDeny {
+		t.Errorf("RequestApprovalFrom = %q, %v; want deny with an error", d, err)
+	}
+	if len(mgr.ListPending()) != approvalCodeSpace {
+		t.Error("request without a code was left pending")
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/nextlevelbuilder/goclaw/internal/rerank"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -19,8 +20,12 @@ type MemorySearchTool struct {
 	episodicStore store.EpisodicStore             // v3 episodic memory (nil = v2 fallback)
 	metricsStore  store.EvolutionMetricsStore     // evolution metrics (nil = disabled)
 	hasKG         bool                           // knowledge_graph_search tool is available
+	reranker      *rerank.Service                // cross-encoder reranking (nil = disabled)
 }
 
+// defaultMemorySearchResults mirrors the maxResults default advertised to the model.
+const defaultMemorySearchResults = 6
+
 func NewMemorySearchTool() *MemorySearchTool {
 	return &MemorySearchTool{}
 }
@@ -40,6 +45,11 @@ func (t *MemorySearchTool) SetEvolutionMetricsStore(ms store.EvolutionMetricsSto
 	t.metricsStore = ms
 }
 
+// SetReranker enables cross-encoder reranking of merged results.
+func (t *MemorySearchTool) SetReranker(r *rerank.Service) {
+	t.reranker = r
+}
+
 // SetHasKG enables the KG hint in search results.
 func (t *MemorySearchTool) SetHasKG(has bool) {
 	t.hasKG = has
@@ -117,6 +127,16 @@ func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) *Re
 			searchOpts.MinScore = mc.MinScore
 		}
 	}
+	// With reranking on, retrieve a wider candidate pool and trim after reranking.
+	wantResults := searchOpts.MaxResults
+	if wantResults <= 0 {
+		wantResults = defaultMemorySearchResults
+	}
+	reranking := false
+	if n := t.reranker.Candidates(ctx, wantResults); n > wantResults {
+		searchOpts.MaxResults = n
+		reranking = true
+	}
 	agentStr := agentID.String()
 	results, err := t.memStore.Search(ctx, query, agentStr, userID, searchOpts)
 	if err != nil {
@@ -136,6 +156,9 @@ func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) *Re
 		epOpts := store.EpisodicSearchOptions{
 			MaxResults: maxResults, MinScore: minScore, VectorWeight: 0.3, TextWeight: 0.7,
 		}
+		if reranking {
+			epOpts.MaxResults = searchOpts.MaxResults
+		}
 		epResults, epErr := t.episodicStore.Search(ctx, query, agentStr, userID, epOpts)
 		if epErr == nil {
 			episodicResults = epResults
@@ -166,6 +189,15 @@ func (t *MemorySearchTool) Execute(ctx context.Context, args map[string]any) *Re
 		})
 	}
 
+	if reranking {
+		combined = rerank.Rerank(ctx, t.reranker, query, combined, wantResults,
+			func(r taggedResult) string { return r.Snippet },
+			func(r *taggedResult, score float64) { r.Score = score })
+		if len(combined) == 0 {
+			return NewResult("No memory results found for query: " + query)
+		}
+	}
+
 	output := map[string]any{
 		"results": combined,
 		"count":   len(combined),
@@ -204,7 +204,7 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *Result {
 			// This lets agents "request permission" from admin to install packages.
 			if t.approvalMgr != nil && matchesAny(normalizedCommand, pkgInstallPatterns) {
 				slog.Info("exec: package install requires approval", "command", truncateCmd(command, 100), "agent", t.agentID)
-				decision, err := t.approvalMgr.RequestApproval(command, t.agentID, 2*time.Minute)
+				decision, err := t.approvalMgr.RequestApprovalFrom(ApprovalOriginFromCtx(ctx), ApprovalKindExec, command, t.agentID, 2*time.Minute)
 				if err != nil {
 					return ErrorResult(fmt.Sprintf("package install approval: %v", err))
 				}
@@ -250,7 +250,7 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *Result {
 		case "deny":
 			return ErrorResult("command denied by exec approval policy")
 		case "ask":
-			decision, err := t.approvalMgr.RequestApproval(command, t.agentID, 2*time.Minute)
+			decision, err := t.approvalMgr.RequestApprovalFrom(ApprovalOriginFromCtx(ctx), ApprovalKindExec, command, t.agentID, 2*time.Minute)
 			if err != nil {
 				return ErrorResult(fmt.Sprintf("exec approval: %v", err))
 			}
@@ -48,6 +48,20 @@ func CalculateCost(pricing *config.ModelPricing, usage *providers.Usage) float64
 	return cost
 }
 
+// CalculateBatchCost is CalculateCost for requests run through a provider
+// batch API, scaled by pricing.BatchMultiplier (providers.BatchPriceMultiplier
+// when unset).
+func CalculateBatchCost(pricing *config.ModelPricing, usage *providers.Usage) float64 {
+	cost := CalculateCost(pricing, usage)
+	if cost == 0 {
+		return 0
+	}
+	if pricing.BatchMultiplier > 0 {
+		return cost * pricing.BatchMultiplier
+	}
+	return cost * providers.BatchPriceMultiplier
+}
+
 // LookupPricing finds the model pricing from config.
 // Tries "provider/model" first, then just "model".
 func LookupPricing(pricingMap map[string]*config.ModelPricing, provider, model string) *config.ModelPricing {
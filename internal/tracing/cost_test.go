@@ -194,3 +194,19 @@ func TestLookupPricing_ProviderQualified(t *testing.T) {
 		t.Errorf("expected nil for nil map, got %+v", p)
 	}
 }
+
+func TestCalculateBatchCost(t *testing.T) {
+	usage := &providers.Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}
+	pricing := &config.ModelPricing{InputPerMillion: 3.0, OutputPerMillion: 15.0}
+	// (3 + 15) * 0.5 default batch discount
+	if got := CalculateBatchCost(pricing, usage); !floatEquals(got, 9.0) {
+		t.Errorf("default multiplier: got %v, want 9", got)
+	}
+	pricing.BatchMultiplier = 0.25
+	if got := CalculateBatchCost(pricing, usage); !floatEquals(got, 4.5) {
+		t.Errorf("custom multiplier: got %v, want 4.5", got)
+	}
+	if got := CalculateBatchCost(nil, usage); got != 0 {
+		t.Errorf("nil pricing: got %v, want 0", got)
+	}
+}
@@ -236,6 +236,7 @@ type spanAggregate struct {
 	CacheReadTokens   int64
 	CacheCreateTokens int64
 	ThinkingTokens    int64
+	BaselineCost      float64
 }
 
 func querySpanAggregates(ctx context.Context, db *sql.DB, from, to time.Time) ([]spanAggregate, error) {
@@ -251,7 +252,8 @@ func querySpanAggregates(ctx context.Context, db *sql.DB, from, to time.Time) ([
 			COALESCE(SUM(s.total_cost), 0) as span_cost,
 			COALESCE(SUM(CAST(s.metadata->>'cache_read_tokens' AS INTEGER)), 0) as cache_read_tokens,
 			COALESCE(SUM(CAST(s.metadata->>'cache_creation_tokens' AS INTEGER)), 0) as cache_create_tokens,
-			COALESCE(SUM(CAST(s.metadata->>'thinking_tokens' AS INTEGER)), 0) as thinking_tokens
+			COALESCE(SUM(CAST(s.metadata->>'thinking_tokens' AS INTEGER)), 0) as thinking_tokens,
+			COALESCE(SUM(COALESCE(CAST(s.metadata->'model_routing'->>'baseline_cost' AS DOUBLE PRECISION), s.total_cost)), 0) as baseline_cost
 		FROM traces t
 		JOIN spans s ON s.trace_id = t.id AND s.span_type = 'llm_call'
 		WHERE t.start_time >= $1 AND t.start_time < $2
@@ -271,6 +273,7 @@ func querySpanAggregates(ctx context.Context, db *sql.DB, from, to time.Time) ([
 			&sa.LLMCallCount,
 			&sa.InputTokens, &sa.OutputTokens, &sa.TotalCost,
 			&sa.CacheReadTokens, &sa.CacheCreateTokens, &sa.ThinkingTokens,
+			&sa.BaselineCost,
 		); err != nil {
 			return nil, err
 		}
@@ -446,6 +449,7 @@ func mergeTraceAndSpanRows(
 			CacheReadTokens:   sp.CacheReadTokens,
 			CacheCreateTokens: sp.CacheCreateTokens,
 			ThinkingTokens:    sp.ThinkingTokens,
+			BaselineCost:      sp.BaselineCost,
 		})
 	}
 
@@ -0,0 +1,90 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/providers"
+	"github.com/nextlevelbuilder/goclaw/internal/store"
+)
+
+// enrichBatchKind is the llmbatch handler key for Phase 1 batch summarize.
+const enrichBatchKind = "vault.batch_summarize"
+
+// enrichBatchItem is one chunk doc carried through a provider batch.
+type enrichBatchItem struct {
+	Payload eventbus.VaultDocUpsertedPayload `json:"payload"`
+	Summary string                           `json:"summary,omitempty"` // already known (existing or synthesized)
+	Title   string                           `json:"title,omitempty"`
+}
+
+// enrichBatchPayload is the llmbatch payload for a queued chunk summarize.
+// NeedLLM indexes Items in the order their files appear in the prompt.
+type enrichBatchPayload struct {
+	Model   string            `json:"model"`
+	Items   []enrichBatchItem `json:"items"`
+	NeedLLM []int             `json:"need_llm"`
+}
+
+// handleBatchSummaries is the llmbatch handler for enrichBatchKind: it applies
+// the batched summaries and runs phases 2-4 for the chunk.
+func (w *EnrichWorker) handleBatchSummaries(ctx context.Context, raw json.RawMessage, resp *providers.ChatResponse) error {
+	var bp enrichBatchPayload
+	if err := json.Unmarshal(raw, &bp); err != nil {
+		return fmt.Errorf("vault.enrich: decode batch payload: %w", err)
+	}
+	if len(bp.Items) == 0 {
+		return nil
+	}
+
+	summaries := parseBatchSummaries(strings.TrimSpace(resp.Content), len(bp.NeedLLM))
+	for i, idx := range bp.NeedLLM {
+		if idx >= 0 && idx < len(bp.Items) && i < len(summaries) && summaries[i] != "" {
+			bp.Items[idx].Summary = summaries[i]
+		}
+	}
+
+	tenantID := bp.Items[0].Payload.TenantID
+	provider, model := w.resolveProviderForTenant(ctx, tenantID)
+	if provider == nil {
+		return fmt.Errorf("vault.enrich: no provider available for tenant %s", tenantID)
+	}
+	if bp.Model != "" {
+		model = bp.Model
+	}
+
+	// Re-fetch docs: they may have changed while the batch ran.
+	docIDs := make([]string, len(bp.Items))
+	for i, it := range bp.Items {
+		docIDs[i] = it.Payload.DocID
+	}
+	existingDocs, err := w.vault.GetDocumentsByIDs(ctx, tenantID, docIDs)
+	if err != nil {
+		return fmt.Errorf("vault.enrich: batch_fetch_docs: %w", err)
+	}
+	docMap := make(map[string]*store.VaultDocument, len(existingDocs))
+	for i := range existingDocs {
+		docMap[existingDocs[i].ID] = &existingDocs[i]
+	}
+
+	var results []enriched
+	for _, it := range bp.Items {
+		doc := docMap[it.Payload.DocID]
+		if doc == nil || doc.ContentHash != it.Payload.ContentHash {
+			// Deleted or rewritten since enqueue; the newer upsert event re-enriches it.
+			slog.Debug("vault.enrich: skip stale batch result", "doc", it.Payload.DocID)
+			continue
+		}
+		results = append(results, enriched{payload: it.Payload, summary: it.Summary, title: it.Title})
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	w.finishChunk(ctx, provider, model, results, docMap)
+	slog.Info("vault.enrich: batch summaries applied", "tenant", tenantID, "count", len(results))
+	return nil
+}
@@ -78,20 +78,12 @@ func (w *EnrichWorker) classifyLinks(ctx context.Context, provider providers.Pro
 				continue
 			}
 
+			// Schema validation + one corrective re-ask happen in ChatStructured;
+			// a parse failure here means the model never produced valid output.
 			parsed, err := parseClassifyResponse(raw, len(chunk))
 			if err != nil {
-				slog.Warn("vault.classify: parse_failed_first", "doc", sourceDocID, "err", err, "raw_len", len(raw), "raw", raw)
-				hint := fmt.Sprintf("\n\nPrevious response was invalid JSON (error: %s). Output ONLY a valid JSON array.", err.Error())
-				raw2, err2 := w.callClassifyWithRetry(ctx, provider, model, system, user+hint)
-				if err2 != nil {
-					slog.Warn("vault.classify: retry_parse_failed", "doc", sourceDocID, "err", err2)
-					continue
-				}
-				parsed, err = parseClassifyResponse(raw2, len(chunk))
-				if err != nil {
-					slog.Warn("vault.classify: parse_still_failed", "doc", sourceDocID, "err", err, "raw_len", len(raw2), "raw", raw2)
-					continue
-				}
+				slog.Warn("vault.classify: parse_failed", "doc", sourceDocID, "err", err, "raw_len", len(raw), "raw", raw)
+				continue
 			}
 
 			for _, r := range parsed {
@@ -189,7 +181,8 @@ func (w *EnrichWorker) callClassifyWithRetry(ctx context.Context, provider provi
 			{Role: "system", Content: system},
 			{Role: "user", Content: user},
 		},
-		Model:   model,
-		Options: map[string]any{"max_tokens": classifyMaxTokens, "temperature": classifyTemperature},
+		Model:          model,
+		Options:        map[string]any{"max_tokens": classifyMaxTokens, "temperature": classifyTemperature},
+		ResponseFormat: providers.JSONSchemaFormat("vault_link_classification", classifySchema),
 	})
 }
@@ -33,6 +33,21 @@ const classifySystemPrompt = `You classify relationships between documents in a
 ## Output Format
 [{"idx":1,"type":"reference","ctx":"cites OAuth spec"},{"idx":2,"type":"SKIP"},{"idx":3,"type":"extends","ctx":"adds error handling"},{"idx":4,"type":"SKIP"},{"idx":5,"type":"depends_on","ctx":"needs auth module"}]`
 
+// classifySchema is the structured-output schema for classify responses:
+// one entry per candidate, ctx optional for SKIP.
+var classifySchema = map[string]any{
+	"type": "array",
+	"items": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"idx":  map[string]any{"type": "integer"},
+			"type": map[string]any{"type": "string", "enum": []any{"reference", "depends_on", "extends", "related", "supersedes", "contradicts", "SKIP"}},
+			"ctx":  map[string]any{"type": "string"},
+		},
+		"required": []any{"idx", "type"},
+	},
+}
+
 // buildClassifyPrompt formats the system and user prompts for classify LLM call.
 func buildClassifyPrompt(source classifyDoc, candidates []classifyDoc) (system, user string) {
 	var b strings.Builder
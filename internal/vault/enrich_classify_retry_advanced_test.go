@@ -70,7 +70,7 @@ func TestCallClassifyWithRetry_SecondAttemptSucceeds(t *testing.T) {
 	}
 }
 
-// TestCallClassifyWithRetry_EmptyResponse returns error for empty response on all attempts.
+// TestCallClassifyWithRetry_EmptyResponse returns the empty content after the structured-output re-ask.
 func TestCallClassifyWithRetry_EmptyResponse(t *testing.T) {
 	provider := &mockClassifyProvider{
 		responses: []string{"", "", ""},
@@ -91,7 +91,9 @@ func TestCallClassifyWithRetry_EmptyResponse(t *testing.T) {
 		t.Errorf("Expected empty response, got %q", resp)
 	}
 
-	if provider.calls != 1 {
-		t.Errorf("Expected 1 call (succeeded immediately), got %d", provider.calls)
+	// One transport attempt, plus one structured-output re-ask for the
+	// empty (schema-invalid) content.
+	if provider.calls != 2 {
+		t.Errorf("Expected 2 calls (structured output re-ask), got %d", provider.calls)
 	}
 }
@@ -3,6 +3,7 @@ package vault
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"github.com/nextlevelbuilder/goclaw/internal/bgalert"
 	"github.com/nextlevelbuilder/goclaw/internal/bus"
 	"github.com/nextlevelbuilder/goclaw/internal/eventbus"
+	"github.com/nextlevelbuilder/goclaw/internal/llmbatch"
 	"github.com/nextlevelbuilder/goclaw/internal/providers"
 	"github.com/nextlevelbuilder/goclaw/internal/providerresolve"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
@@ -47,6 +49,7 @@ type EnrichWorkerDeps struct {
 	MsgBus        bus.EventPublisher        // for WS event broadcast
 	TeamStore     store.TaskCommentStore    // for Phase 2.5 task-based auto-linking (nil-safe)
 	AlertDeps     bgalert.AlertDeps         // for reporting non-retryable LLM errors
+	Batch         *llmbatch.Dispatcher      // optional: Phase 1 summarize via batch API for opted-in tenants
 }
 
 // RegisterEnrichWorker subscribes the enrichment worker to vault doc events.
@@ -64,6 +67,10 @@ func RegisterEnrichWorker(deps EnrichWorkerDeps) (func(), *EnrichProgress, *Enri
 		sem:           semaphore.NewWeighted(enrichMaxConcurrent),
 		progress:      progress,
 		cancelFuncs:   &sync.Map{},
+		batch:         deps.Batch,
+	}
+	if deps.Batch != nil {
+		deps.Batch.Register(enrichBatchKind, w.handleBatchSummaries)
 	}
 	unsub := deps.EventBus.Subscribe(eventbus.EventVaultDocUpserted, w.Handle)
 	return unsub, progress, w
@@ -79,6 +86,7 @@ type EnrichWorker struct {
 	registry      *providers.Registry         // provider resolution
 	msgBus        bus.EventPublisher          // for error event broadcast
 	alertDeps     bgalert.AlertDeps           // for reporting non-retryable LLM errors
+	batch         *llmbatch.Dispatcher        // nil = always summarize inline
 	queue         enrichBatchQueue
 	progress      *EnrichProgress
 
@@ -375,6 +383,22 @@ func (w *EnrichWorker) processChunk(ctx context.Context, items []eventbus.VaultD
 			paths[i] = all[idx].payload.Path
 			contents[i] = all[idx].content
 		}
+
+		// Batch mode: phases 2-4 resume in handleBatchSummaries once the
+		// provider batch returns. Record dedup now so rescans don't requeue.
+		tid, _ := uuid.Parse(tenantID)
+		bp := enrichBatchPayload{Model: model, NeedLLM: needLLM}
+		for _, p := range all {
+			bp.Items = append(bp.Items, enrichBatchItem{Payload: p.payload, Summary: p.summary, Title: p.title})
+		}
+		if w.batch.TryEnqueue(ctx, tid, enrichBatchKind, provider, batchSummarizeRequest(model, paths, contents), bp) {
+			for _, p := range all {
+				w.recordDedup(p.payload.DocID, p.payload.ContentHash)
+			}
+			slog.Debug("vault.enrich: summarize queued for batch", "tenant", tenantID, "count", len(needLLM))
+			return
+		}
+
 		summaries := w.batchSummarize(ctx, provider, model, paths, contents)
 		for i, idx := range needLLM {
 			if i < len(summaries) && summaries[i] != "" {
@@ -388,7 +412,12 @@ func (w *EnrichWorker) processChunk(ctx context.Context, items []eventbus.VaultD
 	for _, p := range all {
 		results = append(results, enriched{payload: p.payload, summary: p.summary, title: p.title})
 	}
+	w.finishChunk(ctx, provider, model, results, docMap)
+}
 
+// finishChunk runs phases 2-4 (embed, auto-link, classify, dedup+wikilinks)
+// for summarized docs. docMap holds the docs fetched in Phase 0.
+func (w *EnrichWorker) finishChunk(ctx context.Context, provider providers.Provider, model string, results []enriched, docMap map[string]*store.VaultDocument) {
 	// Phase 2 — Embed: update summary + embed per doc.
 	// Idempotent for media/document: if content hash and synthesized summary
 	// are unchanged AND non-empty, skip the DB write + embedding call. Text
@@ -443,21 +472,40 @@ const batchSummarizePrompt = `Summarize each document in 2-3 sentences. Focus on
 Output a JSON array: [{"idx":1,"summary":"..."},{"idx":2,"summary":"..."}]
 idx is 1-based matching the document number. Output ONLY valid JSON, no preamble.`
 
-// batchSummarize sends multiple files in a single LLM call and parses JSON summaries.
-func (w *EnrichWorker) batchSummarize(ctx context.Context, provider providers.Provider, model string, paths, contents []string) []string {
+// batchSummarySchema is the structured-output schema for batchSummarizePrompt.
+var batchSummarySchema = map[string]any{
+	"type": "array",
+	"items": map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"idx":     map[string]any{"type": "integer"},
+			"summary": map[string]any{"type": "string"},
+		},
+		"required":             []any{"idx", "summary"},
+		"additionalProperties": false,
+	},
+}
+
+// batchSummarizeRequest builds the single LLM request summarizing a chunk of files.
+func batchSummarizeRequest(model string, paths, contents []string) providers.ChatRequest {
 	var b strings.Builder
 	for i := range paths {
 		fmt.Fprintf(&b, "[%d] File: %s\n%s\n\n", i+1, paths[i], contents[i])
 	}
-
-	raw, err := w.chatWithRetry(ctx, provider, "vault.batch_summarize", providers.ChatRequest{
+	return providers.ChatRequest{
 		Messages: []providers.Message{
 			{Role: "system", Content: batchSummarizePrompt},
 			{Role: "user", Content: b.String()},
 		},
-		Model:   model,
-		Options: map[string]any{"max_tokens": 4096, "temperature": 0.2},
-	})
+		Model:          model,
+		Options:        map[string]any{"max_tokens": 4096, "temperature": 0.2},
+		ResponseFormat: providers.JSONSchemaFormat("vault_batch_summaries", batchSummarySchema),
+	}
+}
+
+// batchSummarize sends multiple files in a single LLM call and parses JSON summaries.
+func (w *EnrichWorker) batchSummarize(ctx context.Context, provider providers.Provider, model string, paths, contents []string) []string {
+	raw, err := w.chatWithRetry(ctx, provider, "vault.batch_summarize", batchSummarizeRequest(model, paths, contents))
 	if err != nil {
 		slog.Warn("vault.enrich: batch_summarize", "count", len(paths), "err", err)
 		// Don't report context cancellation as enrichment error — expected during stop.
@@ -503,7 +551,9 @@ func parseBatchSummaries(raw string, expected int) []string {
 
 // chatWithRetry is the shared retry loop for all enrichment LLM calls.
 // Escalating timeouts and backoffs prevent transient provider failures
-// (e.g. 529 overloaded) from permanently skipping documents.
+// (e.g. 529 overloaded) from permanently skipping documents. Requests with a
+// ResponseFormat go through providers.ChatStructured; output that still fails
+// schema validation is returned as-is for the caller's lenient parser.
 func (w *EnrichWorker) chatWithRetry(ctx context.Context, provider providers.Provider, logPrefix string, req providers.ChatRequest) (string, error) {
 	var lastErr error
 	for attempt := range enrichMaxRetries {
@@ -515,8 +565,12 @@ func (w *EnrichWorker) chatWithRetry(ctx context.Context, provider providers.Pro
 			}
 		}
 		cctx, cancel := context.WithTimeout(ctx, enrichRetryTimeouts[attempt])
-		resp, err := provider.Chat(cctx, req)
+		resp, err := providers.ChatStructured(cctx, provider, req)
 		cancel()
+		if errors.Is(err, providers.ErrStructuredOutput) {
+			slog.Debug(logPrefix+": structured output invalid", "err", err)
+			err = nil
+		}
 		if err != nil {
 			lastErr = err
 			slog.Warn(logPrefix+": retry", "attempt", attempt+1, "err", err)
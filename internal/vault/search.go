@@ -5,8 +5,10 @@ package vault
 import (
 	"context"
 	"sort"
+	"strings"
 	"sync"
 
+	"github.com/nextlevelbuilder/goclaw/internal/rerank"
 	"github.com/nextlevelbuilder/goclaw/internal/store"
 )
 
@@ -45,6 +47,8 @@ type UnifiedSearchResult struct {
 	Score   float64
 	DocType string
 	Snippet string
+
+	rerankText string // document text sent to the reranker
 }
 
 // VaultSearchService coordinates fan-out search across all registered stores.
@@ -52,6 +56,7 @@ type VaultSearchService struct {
 	vaultStore    store.VaultStore          // may be nil if vault disabled
 	episodicStore store.EpisodicStore       // may be nil
 	kgStore       store.KnowledgeGraphStore // may be nil
+	reranker      *rerank.Service           // may be nil (no reranking)
 }
 
 // NewVaultSearchService creates a search service. Any store may be nil (skipped).
@@ -59,6 +64,11 @@ func NewVaultSearchService(vs store.VaultStore, es store.EpisodicStore, kg store
 	return &VaultSearchService{vaultStore: vs, episodicStore: es, kgStore: kg}
 }
 
+// SetReranker enables cross-encoder reranking of merged results.
+func (s *VaultSearchService) SetReranker(r *rerank.Service) {
+	s.reranker = r
+}
+
 // Search executes parallel fan-out search, normalizes scores, applies weights, and deduplicates.
 func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptions) ([]UnifiedSearchResult, error) {
 	if opts.MaxResults <= 0 {
@@ -67,6 +77,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 	if opts.Weights == (SearchWeights{}) {
 		opts.Weights = DefaultSearchWeights()
 	}
+	// With reranking on, retrieve a wider candidate pool for the reranker.
+	candidates := s.reranker.Candidates(ctx, opts.MaxResults)
 
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -86,7 +98,7 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 				TeamID:     opts.TeamID,
 				Scope:      opts.Scope,
 				DocTypes:   opts.DocTypes,
-				MaxResults: opts.MaxResults * 2,
+				MaxResults: candidates * 2,
 				MinScore:   opts.MinScore,
 			})
 			if err != nil {
@@ -102,6 +114,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 					Score:   r.Score,
 					DocType: r.Document.DocType,
 					Snippet: r.Document.Path, // path as snippet fallback
+
+					rerankText: joinNonEmpty(r.Document.Title, r.Document.Path, r.Document.Summary),
 				})
 			}
 			mu.Lock()
@@ -114,7 +128,7 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 	if s.episodicStore != nil {
 		wg.Go(func() {
 			results, err := s.episodicStore.Search(ctx, opts.Query, opts.AgentID, opts.UserID, store.EpisodicSearchOptions{
-				MaxResults: opts.MaxResults * 2,
+				MaxResults: candidates * 2,
 				MinScore:   opts.MinScore,
 			})
 			if err != nil {
@@ -130,6 +144,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 					Score:   r.Score,
 					DocType: "episodic",
 					Snippet: r.L0Abstract,
+
+					rerankText: r.L0Abstract,
 				})
 			}
 			mu.Lock()
@@ -141,7 +157,7 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 	// Fan-out: knowledge graph
 	if s.kgStore != nil {
 		wg.Go(func() {
-			entities, err := s.kgStore.SearchEntities(ctx, opts.AgentID, opts.UserID, opts.Query, opts.MaxResults*2)
+			entities, err := s.kgStore.SearchEntities(ctx, opts.AgentID, opts.UserID, opts.Query, candidates*2)
 			if err != nil {
 				return
 			}
@@ -155,6 +171,8 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 					Score:   e.Confidence,
 					DocType: e.EntityType,
 					Snippet: e.Description,
+
+					rerankText: joinNonEmpty(e.Name, e.Description),
 				})
 			}
 			mu.Lock()
@@ -189,6 +207,15 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 		return all[i].Score > all[j].Score
 	})
 
+	if candidates > opts.MaxResults {
+		if len(all) > candidates {
+			all = all[:candidates]
+		}
+		all = rerank.Rerank(ctx, s.reranker, opts.Query, all, opts.MaxResults,
+			func(r UnifiedSearchResult) string { return r.rerankText },
+			func(r *UnifiedSearchResult, score float64) { r.Score = score })
+	}
+
 	// Cap at maxResults
 	if len(all) > opts.MaxResults {
 		all = all[:opts.MaxResults]
@@ -197,6 +224,21 @@ func (s *VaultSearchService) Search(ctx context.Context, opts UnifiedSearchOptio
 	return all, nil
 }
 
+// joinNonEmpty joins the non-empty parts with newlines.
+func joinNonEmpty(parts ...string) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
 // normalizeAndWeight normalizes scores by max score, then multiplies by weight.
 func normalizeAndWeight(results []UnifiedSearchResult, weight float64) {
 	if len(results) == 0 || weight == 0 {
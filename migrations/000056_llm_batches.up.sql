@@ -0,0 +1,39 @@
+-- Batch-API execution path for background LLM workers.
+-- Items are queued background ChatRequests; jobs are provider-side batches
+-- (Anthropic Message Batches, OpenAI Batch). Both survive restarts so
+-- in-flight batches are polled and delivered after a gateway restart.
+
+CREATE TABLE llm_batch_jobs (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id         UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    provider          VARCHAR(255) NOT NULL,
+    provider_batch_id TEXT NOT NULL,
+    status            VARCHAR(20) NOT NULL DEFAULT 'in_progress',
+    item_count        INT NOT NULL DEFAULT 0,
+    error             TEXT NOT NULL DEFAULT '',
+    submitted_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at      TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_batch_jobs_active ON llm_batch_jobs(status) WHERE status = 'in_progress';
+
+CREATE TABLE llm_batch_items (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id  UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    job_id     UUID REFERENCES llm_batch_jobs(id) ON DELETE SET NULL,
+    kind       VARCHAR(100) NOT NULL,
+    provider   VARCHAR(255) NOT NULL,
+    model      VARCHAR(255) NOT NULL DEFAULT '',
+    request    JSONB NOT NULL,
+    payload    JSONB,
+    status     VARCHAR(20) NOT NULL DEFAULT 'queued',
+    response   JSONB,
+    error      TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_batch_items_status ON llm_batch_items(status, created_at);
+CREATE INDEX idx_llm_batch_items_job ON llm_batch_items(job_id) WHERE job_id IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE usage_snapshots DROP COLUMN IF EXISTS baseline_cost;
@@ -0,0 +1,7 @@
+-- Baseline cost: what the hour's LLM calls would have cost on each agent's
+-- own model. Differs from total_cost only for turns served by a cheaper
+-- model-routing tier; the difference is the routing savings.
+ALTER TABLE usage_snapshots ADD COLUMN IF NOT EXISTS baseline_cost NUMERIC(12,6) NOT NULL DEFAULT 0;
+
+-- Existing rows predate routing: baseline equals actual cost.
+UPDATE usage_snapshots SET baseline_cost = total_cost;
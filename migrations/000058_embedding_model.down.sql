@@ -0,0 +1,7 @@
+ALTER TABLE vault_documents    DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE episodic_summaries DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE kg_entities        DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE team_tasks         DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE agents             DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE skills             DROP COLUMN IF EXISTS embedding_model;
+ALTER TABLE memory_chunks      DROP COLUMN IF EXISTS embedding_model;
@@ -0,0 +1,11 @@
+-- Embedding model tag ("model@dims") recorded next to every stored vector so
+-- a change of embedding model can be detected and re-embedded in place.
+-- NULL on rows embedded before this migration; the gateway adopts them as the
+-- recorded active model at startup.
+ALTER TABLE memory_chunks      ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
+ALTER TABLE skills             ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
+ALTER TABLE agents             ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
+ALTER TABLE team_tasks         ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
+ALTER TABLE kg_entities        ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
+ALTER TABLE episodic_summaries ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
+ALTER TABLE vault_documents    ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(255);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS channel_dead_letters;
+DROP TABLE IF EXISTS channel_outbox;
@@ -0,0 +1,34 @@
+-- Durable outbound delivery for channel messages.
+-- Every agent reply is written to channel_outbox before it is sent and
+-- deleted once the channel accepts it. Failed sends are retried with backoff;
+-- messages that fail permanently or exhaust their attempts move to
+-- channel_dead_letters, where operators can inspect, replay or discard them.
+
+CREATE TABLE channel_outbox (
+    id              UUID PRIMARY KEY,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel         VARCHAR(255) NOT NULL,
+    chat_id         TEXT NOT NULL,
+    payload         JSONB NOT NULL,
+    attempts        INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_channel_outbox_due ON channel_outbox(next_attempt_at);
+
+CREATE TABLE channel_dead_letters (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel    VARCHAR(255) NOT NULL,
+    chat_id    TEXT NOT NULL,
+    payload    JSONB NOT NULL,
+    attempts   INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    failed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_channel_dead_letters_tenant ON channel_dead_letters(tenant_id, failed_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE llm_batch_items DROP COLUMN IF EXISTS pii_vault;
@@ -0,0 +1,5 @@
+-- Placeholder → original mapping for batch items whose request was
+-- PII-redacted at enqueue. The dispatcher restores the provider's response
+-- with it before handing the result back to the worker. NULL when the owning
+-- agent has no redaction policy.
+ALTER TABLE llm_batch_items ADD COLUMN IF NOT EXISTS pii_vault JSONB;
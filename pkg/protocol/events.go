@@ -105,12 +105,19 @@ const (
 	EventWhatsAppQRCode = "whatsapp.qr.code"
 	EventWhatsAppQRDone = "whatsapp.qr.done"
 
+	// Outbound message delivery status from channels that report it
+	// (WhatsApp Cloud API: sent, delivered, read, failed). Admin-only.
+	EventChannelMessageStatus = "channel.message.status"
+
 	// Tenant access revocation — forces affected user's UI to logout.
 	EventTenantAccessRevoked = "tenant.access.revoked"
 
 	// Vault enrichment pipeline progress.
 	EventVaultEnrichProgress = "vault.enrich.progress"
 
+	// Embedding model migration progress (re-embedding run state, owner-only).
+	EventEmbeddingMigration = "embedding.migration.progress"
+
 	// Background worker alerts (non-retryable LLM errors).
 	EventBackgroundError = "background.error"
 )
@@ -166,6 +166,13 @@ const (
 	MethodAPIKeysRevoke = "api_keys.revoke"
 )
 
+// Outbound dead-letter queue (admin)
+const (
+	MethodOutboxDeadList   = "outbox.dead.list"
+	MethodOutboxDeadReplay = "outbox.dead.replay"
+	MethodOutboxDeadDelete = "outbox.dead.delete"
+)
+
 // Voices (ElevenLabs voice picker)
 const (
 	MethodVoicesList    = "voices.list"
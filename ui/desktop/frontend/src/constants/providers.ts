@@ -25,8 +25,12 @@ export const PROVIDER_TYPES: ProviderTypeInfo[] = [
   { value: 'zai_coding', label: 'Z.ai Coding Plan', apiBase: 'https://api.z.ai/api/coding/paas/v4', needsKey: true },
   { value: 'byteplus', label: 'BytePlus ModelArk', apiBase: 'https://ark.ap-southeast.bytepluses.com/api/v3', needsKey: true },
   { value: 'byteplus_coding', label: 'BytePlus Coding Plan', apiBase: 'https://ark.ap-southeast.bytepluses.com/api/coding/v3', needsKey: true },
+  { value: 'azure_openai', label: 'Azure OpenAI', apiBase: 'https://YOUR-RESOURCE.openai.azure.com', needsKey: true },
+  { value: 'vertex_ai', label: 'Google Vertex AI', apiBase: 'https://us-central1-aiplatform.googleapis.com', needsKey: true },
+  { value: 'bedrock', label: 'AWS Bedrock', apiBase: 'https://bedrock-runtime.us-east-1.amazonaws.com', needsKey: false },
   { value: 'ollama', label: 'Ollama (Local)', apiBase: 'http://localhost:11434/v1', needsKey: false },
   { value: 'ollama_cloud', label: 'Ollama Cloud', apiBase: 'https://ollama.com/v1', needsKey: true },
   { value: 'claude_cli', label: 'Claude CLI (Local)', apiBase: '', needsKey: false },
   { value: 'acp', label: 'ACP Agent (Subprocess)', apiBase: '', needsKey: false },
+  { value: 'replay', label: 'Replay (Recorded Cassettes)', apiBase: '', needsKey: false },
 ]
@@ -1,11 +1,21 @@
 export const CHANNEL_TYPES = [
   { value: "discord", label: "Discord" },
+  { value: "email", label: "Email (IMAP/SMTP)" },
   { value: "facebook", label: "Facebook" },
   { value: "feishu", label: "Feishu / Lark" },
+  { value: "instagram", label: "Instagram" },
+  { value: "line", label: "LINE" },
+  { value: "matrix", label: "Matrix" },
+  { value: "mattermost", label: "Mattermost" },
+  { value: "msteams", label: "Microsoft Teams" },
   { value: "pancake", label: "Pancake (pages.fm)" },
+  { value: "signal", label: "Signal" },
   { value: "slack", label: "Slack" },
   { value: "telegram", label: "Telegram" },
+  { value: "webchat", label: "Website Chat Widget" },
+  { value: "webhook", label: "Webhook (custom app)" },
   { value: "whatsapp", label: "WhatsApp" },
+  { value: "whatsapp_cloud", label: "WhatsApp Cloud API" },
   { value: "zalo_oa", label: "Zalo OA" },
   { value: "zalo_personal", label: "Zalo Personal" },
 ] as const;
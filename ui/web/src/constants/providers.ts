@@ -32,10 +32,14 @@ export const PROVIDER_TYPES: ProviderTypeInfo[] = [
   { value: "zai_coding", label: "Z.ai Coding Plan", apiBase: "https://api.z.ai/api/coding/paas/v4", placeholder: "" },
   { value: "byteplus", label: "BytePlus ModelArk", apiBase: "https://ark.ap-southeast.bytepluses.com/api/v3", placeholder: "" },
   { value: "byteplus_coding", label: "BytePlus Coding Plan", apiBase: "https://ark.ap-southeast.bytepluses.com/api/coding/v3", placeholder: "" },
+  { value: "azure_openai", label: "Azure OpenAI", apiBase: "https://YOUR-RESOURCE.openai.azure.com", placeholder: "" },
+  { value: "vertex_ai", label: "Google Vertex AI", apiBase: "https://us-central1-aiplatform.googleapis.com", placeholder: "" },
+  { value: "bedrock", label: "AWS Bedrock", apiBase: "https://bedrock-runtime.us-east-1.amazonaws.com", placeholder: "" },
   { value: "ollama", label: "Ollama (Local)", apiBase: "http://localhost:11434/v1", placeholder: "" },
   { value: "ollama_cloud", label: "Ollama Cloud", apiBase: "https://ollama.com/v1", placeholder: "" },
   { value: "claude_cli", label: "Claude CLI (Local)", apiBase: "", placeholder: "" },
   { value: "acp", label: "ACP Agent (Subprocess)", apiBase: "", placeholder: "claude" },
+  { value: "replay", label: "Replay (Recorded Cassettes)", apiBase: "", placeholder: "" },
 ];
 
 function providerAliasName(value: ProviderAliasSource): string {
@@ -71,16 +71,55 @@ export const credentialsSchema: Record<string, FieldDef[]> = {
   ],
   zalo_personal: [],
   whatsapp: [],
+  signal: [],
+  webchat: [
+    { key: "session_secret", label: "Session Secret", type: "password", required: true, help: "Random string used to sign visitor sessions. Changing it starts new conversations for all visitors." },
+    { key: "identity_secret", label: "Identity Secret", type: "password", help: "Shared with your site's backend to sign user tokens for logged-in visitors" },
+  ],
+  webhook: [
+    { key: "secret", label: "Signing Secret", type: "password", required: true, help: "Shared secret the application uses to sign requests (HMAC-SHA256)" },
+    { key: "callback_secret", label: "Callback Signing Secret", type: "password", help: "Signs replies sent to the callback URL. Defaults to the signing secret." },
+  ],
+  whatsapp_cloud: [
+    { key: "access_token", label: "Access Token", type: "password", required: true, help: "System User token with whatsapp_business_messaging permission (Business Settings → System Users)" },
+    { key: "app_secret", label: "App Secret", type: "password", required: true, help: "From Meta Developer Console → Your App → Settings → Basic" },
+    { key: "verify_token", label: "Webhook Verify Token", type: "password", required: true, help: "A secret string you choose, used to verify the webhook URL (/channels/facebook/webhook)" },
+  ],
   facebook: [
     { key: "page_access_token", label: "Page Access Token", type: "password", required: true, help: "From Facebook Developer Console → Your App → Messenger → Page Access Token" },
     { key: "app_secret", label: "App Secret", type: "password", required: true, help: "From Facebook Developer Console → Your App → Settings → Basic" },
     { key: "verify_token", label: "Webhook Verify Token", type: "password", required: true, help: "A secret string you choose, used to verify the webhook URL" },
   ],
+  instagram: [
+    { key: "page_access_token", label: "Page Access Token", type: "password", required: true, help: "Token of the Facebook Page linked to the Instagram account, with instagram_manage_messages and instagram_manage_comments" },
+    { key: "app_secret", label: "App Secret", type: "password", required: true, help: "From Meta Developer Console → Your App → Settings → Basic" },
+    { key: "verify_token", label: "Webhook Verify Token", type: "password", required: true, help: "A secret string you choose, used to verify the webhook URL (/channels/facebook/webhook)" },
+  ],
   pancake: [
     { key: "api_key", label: "API Key", type: "password", required: true, help: "Pancake user-level API key from pages.fm account settings" },
     { key: "page_access_token", label: "Page Access Token", type: "password", required: true, help: "Page-level token from Pancake dashboard → Page Settings" },
     { key: "webhook_secret", label: "Webhook Secret (Optional)", type: "password", help: "HMAC-SHA256 secret for webhook signature verification. Leave empty to skip verification." },
   ],
+  email: [
+    { key: "password", label: "Password", type: "password", required: true, help: "IMAP password or app password (also used for SMTP unless set below)" },
+    { key: "smtp_password", label: "SMTP Password (Optional)", type: "password", help: "Only needed when the SMTP server uses a different password" },
+  ],
+  matrix: [
+    { key: "access_token", label: "Access Token", type: "password", required: true, help: "Access token of the bot account (Element → Settings → Help & About → Access Token, or from /login)" },
+  ],
+  mattermost: [
+    { key: "token", label: "Bot Access Token", type: "password", required: true, help: "System Console → Integrations → Bot Accounts → Create token" },
+    { key: "command_token", label: "Slash Command Token (Optional)", type: "password", help: "Only for a slash command created manually in Mattermost; auto-registered commands need no token" },
+  ],
+  msteams: [
+    { key: "app_id", label: "Microsoft App ID", type: "text", required: true, help: "Azure Bot → Configuration → Microsoft App ID" },
+    { key: "app_password", label: "Client Secret", type: "password", required: true, help: "Secret of the bot's app registration (Certificates & secrets)" },
+    { key: "tenant_id", label: "Tenant ID", type: "text", help: "Required for single-tenant bots; leave empty for multi-tenant" },
+  ],
+  line: [
+    { key: "channel_secret", label: "Channel Secret", type: "password", required: true, help: "LINE Developers Console → Basic settings → Channel secret" },
+    { key: "channel_access_token", label: "Channel Access Token", type: "password", required: true, help: "Messaging API → Channel access token (long-lived)" },
+  ],
 };
 
 // --- Pancake platform options ---
@@ -194,6 +233,16 @@ export const configSchema: Record<string, FieldDef[]> = {
     { key: "allow_from", label: "Allowed Users", type: "tags", help: "Facebook user IDs" },
     { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit" },
   ],
+  instagram: [
+    { key: "page_id", label: "Page ID", type: "text", required: true, help: "Facebook Page linked to the Instagram professional account" },
+    { key: "instagram_account_id", label: "Instagram Account ID", type: "text", help: "Resolved from the page when empty", advanced: true },
+    { key: "features.dm_auto_reply", label: "DM Auto-Reply", type: "boolean", defaultValue: false, help: "Reply to direct messages, story replies and story mentions" },
+    { key: "features.comment_reply", label: "Comment Auto-Reply", type: "boolean", defaultValue: false },
+    { key: "features.comment_to_dm", label: "Reply to Comments by DM", type: "boolean", defaultValue: false, help: "Answer comments with a private message instead of a public reply" },
+    { key: "dm_options.session_timeout", label: "DM Session Timeout", type: "text", placeholder: "e.g. 30m" },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Instagram-scoped user IDs" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit" },
+  ],
   pancake: [
     { key: "page_id", label: "Page ID", type: "text", required: true, help: "Pancake internal page ID (numeric, from Pancake dashboard)" },
     { key: "webhook_page_id", label: "Webhook Page ID", type: "text", help: "Only needed when the native platform page ID in webhooks differs from the Pancake page ID above (rare). Leave empty if both are the same.", advanced: true },
@@ -204,6 +253,139 @@ export const configSchema: Record<string, FieldDef[]> = {
     { key: "allow_from", label: "Allowed Users", type: "tags", help: "Sender IDs to whitelist. Empty = accept all." },
     { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit" },
   ],
+  email: [
+    { key: "address", label: "Email Address", type: "text", required: true, placeholder: "agent@example.com", help: "Mailbox the agent reads and replies from" },
+    { key: "from_name", label: "From Name", type: "text", placeholder: "Support Bot" },
+    { key: "imap.host", label: "IMAP Host", type: "text", required: true, placeholder: "imap.example.com" },
+    { key: "imap.port", label: "IMAP Port", type: "number", help: "Default: 993 (TLS) or 143 (STARTTLS)" },
+    { key: "imap.security", label: "IMAP Security", type: "select", options: [{ value: "tls", label: "TLS" }, { value: "starttls", label: "STARTTLS" }, { value: "none", label: "None (plaintext)" }], defaultValue: "tls" },
+    { key: "imap.username", label: "IMAP Username", type: "text", help: "Defaults to the email address", advanced: true },
+    { key: "smtp.host", label: "SMTP Host", type: "text", required: true, placeholder: "smtp.example.com" },
+    { key: "smtp.port", label: "SMTP Port", type: "number", help: "Default: 465 (TLS) or 587 (STARTTLS)" },
+    { key: "smtp.security", label: "SMTP Security", type: "select", options: [{ value: "tls", label: "TLS" }, { value: "starttls", label: "STARTTLS" }, { value: "none", label: "None (plaintext)" }], defaultValue: "tls" },
+    { key: "smtp.username", label: "SMTP Username", type: "text", help: "Defaults to the email address", advanced: true },
+    { key: "mailboxes", label: "Mailboxes", type: "tags", help: "IMAP folders to watch. Default: INBOX" },
+    { key: "subject", label: "Default Subject", type: "text", help: "Subject for messages that are not replies" },
+    { key: "signature", label: "Signature", type: "textarea", help: "Appended to replies. Template fields: {{.FromName}}, {{.Address}}, {{.Subject}}, {{.Date}}" },
+    { key: "poll_interval", label: "Poll Interval (seconds)", type: "number", defaultValue: 60, help: "Used only when the server does not support IMAP IDLE", advanced: true },
+    { key: "backfill_days", label: "Backfill Days", type: "number", defaultValue: 1, help: "Unread mail older than this is ignored on connect", advanced: true },
+    { key: "media_max_mb", label: "Max Attachment Size (MB)", type: "number", defaultValue: 20 },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "allow_from", label: "Allowed Senders", type: "tags", help: "Sender email addresses" },
+    { key: "trusted_authserv_ids", label: "Trusted Authserv-IDs", type: "tags", help: "Authserv-ids of your receiving MTAs. Mail needs a DMARC or aligned DKIM pass in their Authentication-Results header" },
+    { key: "trusted_relay", label: "Trusted Relay", type: "boolean", defaultValue: false, help: "Skip sender authentication. Only for mailboxes fed by a relay that rejects unauthenticated mail", advanced: true },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "false", help: "Send intermediate text as separate emails (off by default)" },
+  ],
+  matrix: [
+    { key: "homeserver", label: "Homeserver URL", type: "text", required: true, placeholder: "https://matrix.example.com" },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Room Policy", type: "select", options: groupPolicyOptions, defaultValue: "open" },
+    { key: "require_mention", label: "Require @mention in rooms", type: "boolean", defaultValue: true },
+    { key: "history_limit", label: "Room History Limit", type: "number", defaultValue: 50, help: "Max pending room messages for context (0 = disabled)" },
+    { key: "auto_join", label: "Auto-Join Invites", type: "boolean", defaultValue: true, help: "Accept room invites automatically. Policies still apply to messages." },
+    { key: "dm_stream", label: "DM Streaming", type: "boolean", defaultValue: false, help: "Edit the reply in place as it is generated" },
+    { key: "group_stream", label: "Room Streaming", type: "boolean", defaultValue: false, help: "Edit the reply in place as it is generated" },
+    { key: "reaction_level", label: "Reaction Level", type: "select", options: [{ value: "off", label: "Off" }, { value: "minimal", label: "Minimal" }, { value: "full", label: "Full" }], defaultValue: "minimal", help: "Status reactions on user messages while the bot is processing" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Matrix user IDs (@user:server) or room IDs (!room:server)" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  mattermost: [
+    { key: "server_url", label: "Server URL", type: "text", required: true, placeholder: "https://chat.example.com" },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Channel Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in channels", type: "boolean", defaultValue: true },
+    { key: "thread_ttl", label: "Thread Auto-Reply (hours)", type: "number", defaultValue: 24, help: "Reply without @mention in threads the bot joined (0 = disabled)" },
+    { key: "history_limit", label: "Channel History Limit", type: "number", help: "Max pending channel messages for context (0 = disabled)" },
+    { key: "dm_stream", label: "DM Streaming", type: "boolean", defaultValue: false, help: "Edit the reply post as it is generated" },
+    { key: "group_stream", label: "Channel Streaming", type: "boolean", defaultValue: false, help: "Edit the reply post as it is generated" },
+    { key: "reaction_level", label: "Reaction Level", type: "select", options: [{ value: "off", label: "Off" }, { value: "minimal", label: "Minimal" }, { value: "full", label: "Full" }], defaultValue: "off", help: "Status emoji reactions on user posts while the bot is processing" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "slash_command.trigger", label: "Slash Command Trigger", type: "text", placeholder: "ask", help: "Registered in every team the bot belongs to. Leave empty to disable." },
+    { key: "slash_command.callback_url", label: "Slash Command Callback URL", type: "text", placeholder: "https://goclaw.example.com/channels/mattermost/commands", help: "Public URL of this gateway's command endpoint" },
+    { key: "slash_command.description", label: "Slash Command Description", type: "text", advanced: true },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Mattermost user IDs or channel IDs" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  msteams: [
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Group / Channel Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in groups", type: "boolean", defaultValue: true },
+    { key: "history_limit", label: "Group History Limit", type: "number", help: "Max pending group messages for context (0 = disabled)" },
+    { key: "render_mode", label: "Render Mode", type: "select", options: [{ value: "auto", label: "Auto" }, { value: "raw", label: "Raw" }, { value: "card", label: "Card" }], defaultValue: "auto", help: "Auto uses Adaptive Cards for code blocks, tables and headings" },
+    { key: "typing", label: "Typing Indicator", type: "boolean", defaultValue: true },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "service_url", label: "Default Service URL", type: "text", placeholder: "https://smba.trafficmanager.net/teams/", help: "Used for proactive messages to conversations not seen since the last restart", advanced: true },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Azure AD object IDs or conversation IDs" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  line: [
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in groups", type: "boolean", defaultValue: true },
+    { key: "history_limit", label: "Group History Limit", type: "number", help: "Max pending group messages for context (0 = disabled)" },
+    { key: "render_mode", label: "Render Mode", type: "select", options: [{ value: "auto", label: "Auto" }, { value: "raw", label: "Raw" }, { value: "flex", label: "Flex" }], defaultValue: "auto", help: "Auto uses Flex messages for code blocks, tables and headings" },
+    { key: "suggest_quick_replies", label: "Suggest Quick Replies", type: "boolean", defaultValue: true, help: "Offer the options of a trailing question as quick reply buttons" },
+    { key: "quick_replies", label: "Quick Replies", type: "tags", help: "Buttons added to every reply (max 20 characters each)", advanced: true },
+    { key: "loading_animation", label: "Loading Animation", type: "boolean", defaultValue: true, help: "Shown in 1:1 chats while the bot is processing" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "LINE user IDs (U…) or group/room IDs (C…/R…)" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  signal: [
+    { key: "account", label: "Account Number", type: "text", required: true, placeholder: "+15551234567", help: "Phone number registered with signal-cli" },
+    { key: "address", label: "signal-cli Address", type: "text", placeholder: "127.0.0.1:7583", help: "JSON-RPC endpoint of signal-cli daemon (--tcp host:port or unix:///path for --socket)" },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "pairing" },
+    { key: "require_mention", label: "Require @mention in groups", type: "boolean", defaultValue: true },
+    { key: "history_limit", label: "Group History Limit", type: "number", help: "Max pending group messages for context (0 = disabled)" },
+    { key: "reaction_level", label: "Reaction Level", type: "select", options: [{ value: "off", label: "Off" }, { value: "minimal", label: "Minimal" }, { value: "full", label: "Full" }], defaultValue: "minimal", help: "Status emoji reactions on user messages while the bot is processing" },
+    { key: "typing", label: "Typing Indicator", type: "boolean", defaultValue: true },
+    { key: "read_receipts", label: "Read Receipts", type: "boolean", defaultValue: true, help: "Mark messages read when the bot handles them" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Signal account UUIDs or group IDs" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  webhook: [
+    { key: "callback_url", label: "Callback URL", type: "text", required: true, placeholder: "https://app.example.com/goclaw/events", help: "Replies are POSTed here, signed like inbound requests. Inbound requests go to /channels/webhook/<instance name>." },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "open", help: "Requests are signed by your application, which authenticates its own users" },
+    { key: "group_policy", label: "Group Policy", type: "select", options: groupPolicyOptions, defaultValue: "open" },
+    { key: "require_mention", label: "Require mention in groups", type: "boolean", defaultValue: true, help: "Group messages reach the agent only when sent with mentioned: true" },
+    { key: "history_limit", label: "Group History Limit", type: "number", help: "Max pending group messages for context (0 = disabled)" },
+    { key: "stream", label: "Streaming", type: "boolean", defaultValue: false, help: "Send partial replies while the agent writes" },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 20 },
+    { key: "max_retries", label: "Callback Retries", type: "number", defaultValue: 3, help: "Retries on network errors, 429 and 5xx responses", advanced: true },
+    { key: "timeout_seconds", label: "Callback Timeout (seconds)", type: "number", defaultValue: 10, advanced: true },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Sender IDs or chat IDs from your application" },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  webchat: [
+    { key: "public_key", label: "Public Key", type: "text", required: true, placeholder: "pk_live_support", help: "Embedded in your site: <script src=\"https://<gateway>/channels/webchat/widget.js\" data-key=\"<public key>\" async></script>" },
+    { key: "allowed_origins", label: "Allowed Origins", type: "tags", required: true, help: "Sites that may embed the widget, e.g. https://example.com or https://*.example.com" },
+    { key: "title", label: "Title", type: "text", placeholder: "Chat with us" },
+    { key: "greeting", label: "Greeting", type: "text", placeholder: "Hi! How can we help?" },
+    { key: "primary_color", label: "Primary Color", type: "text", placeholder: "#2563eb" },
+    { key: "require_identity", label: "Require Signed-in Users", type: "boolean", defaultValue: false, help: "Only visitors with a user token signed by your site may chat" },
+    { key: "rate_limit", label: "Messages per Minute", type: "number", defaultValue: 10, help: "Per visitor, with a burst of 5" },
+    { key: "stream", label: "Streaming", type: "boolean", defaultValue: true, help: "Show replies as they are written" },
+    { key: "media_max_mb", label: "Max Upload Size (MB)", type: "number", defaultValue: 10 },
+    { key: "dm_policy", label: "Visitor Policy", type: "select", options: dmPolicyOptions, defaultValue: "open", advanced: true },
+    { key: "allow_from", label: "Allowed Visitors", type: "tags", help: "Visitor IDs (v_…) or signed-in user IDs (u_…)", advanced: true },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
+  whatsapp_cloud: [
+    { key: "phone_number_id", label: "Phone Number ID", type: "text", required: true, help: "From Meta Developer Console → WhatsApp → API Setup" },
+    { key: "waba_id", label: "WhatsApp Business Account ID", type: "text", required: true },
+    { key: "dm_policy", label: "DM Policy", type: "select", options: dmPolicyOptions, defaultValue: "pairing" },
+    { key: "allow_from", label: "Allowed Users", type: "tags", help: "Phone numbers in international format, digits only (e.g. 4917612345678)" },
+    { key: "template_name", label: "Re-engagement Template", type: "text", placeholder: "follow_up", help: "Approved template sent when a reply falls outside the 24-hour window; the reply is delivered once the user answers" },
+    { key: "template_language", label: "Template Language", type: "text", placeholder: "en_US" },
+    { key: "template_params", label: "Template Parameters", type: "tags", help: "Values for the template body's {{1}}, {{2}}…; {name} and {preview} expand to the contact name and the start of the reply" },
+    { key: "interactive", label: "Interactive Buttons & Lists", type: "boolean", defaultValue: true, help: "Send a question followed by short options as reply buttons or a list" },
+    { key: "mark_read", label: "Read Receipts & Typing", type: "boolean", defaultValue: true },
+    { key: "media_max_mb", label: "Max Media Size (MB)", type: "number", defaultValue: 16, advanced: true },
+    { key: "block_reply", label: "Block Reply", type: "select", options: blockReplyOptions, defaultValue: "inherit", help: "Deliver intermediate text during tool iterations" },
+  ],
 };
 
 // --- Group override schema (Telegram per-group/topic overrides) ---
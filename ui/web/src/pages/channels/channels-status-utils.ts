@@ -172,6 +172,8 @@ export function getChannelFailureKindLabel(
       return t("failureKind.config", { defaultValue: "Config" });
     case "network":
       return t("failureKind.network", { defaultValue: "Network" });
+    case "quota":
+      return t("failureKind.quota", { defaultValue: "Quota" });
     case "unknown":
       return t("failureKind.unknown", { defaultValue: "Attention" });
     default:
@@ -25,7 +25,7 @@ export interface ChannelRuntimeStatus {
     | "stopped";
   summary?: string;
   detail?: string;
-  failure_kind?: "auth" | "config" | "network" | "unknown";
+  failure_kind?: "auth" | "config" | "network" | "quota" | "unknown";
   retryable?: boolean;
   checked_at?: string;
   failure_count?: number;
@@ -34,7 +34,7 @@ export interface ChannelRuntimeStatus {
   last_failed_at?: string;
   last_healthy_at?: string;
   remediation?: {
-    code: "reauth" | "open_credentials" | "open_advanced" | "check_network";
+    code: "reauth" | "open_credentials" | "open_advanced" | "check_network" | "check_quota";
     headline: string;
     hint?: string;
     target?: "credentials" | "advanced" | "reauth" | "details";